@@ -0,0 +1,182 @@
+// adaptive_window.go: admission window sized by hill climbing
+//
+// Config.AdaptiveWindow tunes the W-TinyLFU admission window with the hill
+// climber of Caffeine.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// adaptiveWindowMaxRatio bounds the window at this share of MaxSize.
+	adaptiveWindowMaxRatio = 0.8
+
+	// adaptiveWindowSampleFactor sets the sample period of the climber to
+	// this many lookups per MaxSize, as the frequency sketch ages.
+	adaptiveWindowSampleFactor = 10
+
+	// adaptiveWindowStepRatio is the initial step, as a share of MaxSize.
+	adaptiveWindowStepRatio = 0.0625
+
+	// adaptiveWindowStepDecay shrinks the step after each period, so the
+	// window settles once the hit ratio stops changing.
+	adaptiveWindowStepDecay = 0.98
+
+	// adaptiveWindowRestart is the hit ratio change (as a fraction) that
+	// restarts the climb with the initial step: the workload has shifted.
+	adaptiveWindowRestart = 0.05
+)
+
+// admissionWindow is the FIFO of recently inserted slots and its climber.
+type admissionWindow struct {
+	slots []uint64 // (slot index + 1) << 32 | low 32 bits of the key hash; 0 = empty
+	head  uint64   // atomic: number of pushes
+	size  int64    // atomic: current window size
+	max   int64
+	steps int64 // atomic: number of size adjustments
+
+	climbMu      sync.Mutex
+	sampleSize   int64
+	lastAccesses int64   // hits+misses at the start of the period
+	lastHits     int64   // hits at the start of the period
+	prevHitRatio float64 // hit ratio of the previous period (-1 = none yet)
+	step         float64 // signed: the next adjustment, in entries
+	initialStep  float64
+}
+
+// newAdmissionWindow returns the window of a cache of maxSize entries,
+// starting at ratio.
+func newAdmissionWindow(maxSize int, ratio float64) *admissionWindow {
+	max := int64(float64(maxSize) * adaptiveWindowMaxRatio)
+	size := int64(float64(maxSize) * ratio)
+	if size > max {
+		size = max
+	}
+	return &admissionWindow{
+		slots:        make([]uint64, max+1),
+		size:         size,
+		max:          max,
+		sampleSize:   int64(maxSize) * adaptiveWindowSampleFactor,
+		prevHitRatio: -1,
+		step:         float64(maxSize) * adaptiveWindowStepRatio,
+		initialStep:  float64(maxSize) * adaptiveWindowStepRatio,
+	}
+}
+
+// push adds the slot of a newly inserted key and returns the slot of the key
+// leaving the window, if any. With an empty window the new key leaves at once.
+func (w *admissionWindow) push(idx uint64, keyHash uint64) (out uint64, outHash uint32, ok bool) {
+	n := atomic.AddUint64(&w.head, 1) - 1
+	ring := uint64(len(w.slots))
+	atomic.StoreUint64(&w.slots[n%ring], (idx+1)<<32|uint64(uint32(keyHash)))
+
+	size := uint64(atomic.LoadInt64(&w.size)) // #nosec G115 - size is clamped to [0, max]
+	if n < size {
+		return 0, 0, false // Still filling
+	}
+	packed := atomic.SwapUint64(&w.slots[(n-size)%ring], 0)
+	if packed == 0 {
+		return 0, 0, false // Taken by a concurrent push after a resize
+	}
+	return packed>>32 - 1, uint32(packed), true
+}
+
+// remap moves the slots queued in the window to a new table, given for each
+// old slot its new index plus one (0 when its entry was not moved).
+func (w *admissionWindow) remap(moved []uint32) {
+	for i := range w.slots {
+		packed := atomic.LoadUint64(&w.slots[i])
+		if packed == 0 {
+			continue
+		}
+		var next uint64
+		if idx := packed>>32 - 1; idx < uint64(len(moved)) && moved[idx] != 0 {
+			next = uint64(moved[idx])<<32 | uint64(uint32(packed))
+		}
+		atomic.StoreUint64(&w.slots[i], next)
+	}
+}
+
+// climb adjusts the window size if a sample period has elapsed, given the
+// cumulative hit and miss counters.
+func (w *admissionWindow) climb(hits, misses int64) {
+	if hits+misses-atomic.LoadInt64(&w.lastAccesses) < w.sampleSize || !w.climbMu.TryLock() {
+		return
+	}
+	defer w.climbMu.Unlock()
+
+	accesses := hits + misses - w.lastAccesses
+	if accesses < 0 {
+		// Counters reset by Clear: start a new period
+		atomic.StoreInt64(&w.lastAccesses, hits+misses)
+		w.lastHits = hits
+		return
+	}
+	if accesses < w.sampleSize {
+		return // Climbed by another goroutine meanwhile
+	}
+	hitRatio := float64(hits-w.lastHits) / float64(accesses)
+	atomic.StoreInt64(&w.lastAccesses, hits+misses)
+	w.lastHits = hits
+
+	if w.prevHitRatio < 0 {
+		w.prevHitRatio = hitRatio // First period: nothing to compare with
+		return
+	}
+	delta := hitRatio - w.prevHitRatio
+	w.prevHitRatio = hitRatio
+
+	amount := w.step
+	if delta < 0 {
+		amount = -amount // The last move lowered the hit ratio: go back
+	}
+	if math.Abs(delta) >= adaptiveWindowRestart {
+		w.step = math.Copysign(w.initialStep, amount) // Workload shift
+	} else {
+		w.step = adaptiveWindowStepDecay * amount
+	}
+
+	size := atomic.LoadInt64(&w.size) + int64(math.Round(amount))
+	if size < 0 {
+		size = 0
+	}
+	if size > w.max {
+		size = w.max
+	}
+	if size != atomic.LoadInt64(&w.size) {
+		atomic.StoreInt64(&w.size, size)
+		atomic.AddInt64(&w.steps, 1)
+	}
+}
+
+// windowCompetitor returns the entry that competes for admission after the
+// key in slot idx of t was inserted: the live key leaving the window, or nil
+// if no key left it (the sampled victim is then evicted unconditionally).
+func (c *wtinyLFUCache) windowCompetitor(t *cacheTable, idx uint64, keyHash uint64) *entry {
+	g := t.gen.Load()
+	w := t.admissionWindow
+	w.climb(atomic.LoadInt64(&c.hits), atomic.LoadInt64(&c.misses))
+
+	out, outHash, ok := w.push(idx, keyHash)
+	if !ok || out >= uint64(len(t.entries)) {
+		return nil
+	}
+	e := &t.entries[out]
+	if atomic.LoadInt32(&e.valid) != g.live || uint32(atomic.LoadUint64(&e.keyHash)) != outHash {
+		return nil // Deleted or evicted while in the window
+	}
+	return e
+}
+
+// stats returns the current window size and the number of adjustments.
+func (w *admissionWindow) stats() (size int, adjustments uint64) {
+	return int(atomic.LoadInt64(&w.size)), uint64(atomic.LoadInt64(&w.steps)) // #nosec G115 - counter is always positive
+}
@@ -0,0 +1,166 @@
+// adaptive_window_test.go: tests for the hill-climbing admission window
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAdmissionWindow_PushFIFO(t *testing.T) {
+	w := newAdmissionWindow(10, 0.2) // size 2
+
+	if _, _, ok := w.push(1, 0xa); ok {
+		t.Fatal("first push must not leave a filling window")
+	}
+	if _, _, ok := w.push(2, 0xb); ok {
+		t.Fatal("second push must not leave a filling window")
+	}
+	out, hash, ok := w.push(3, 0xc)
+	if !ok || out != 1 || hash != 0xa {
+		t.Errorf("push = (%d, %#x, %v), want the oldest slot (1, 0xa)", out, hash, ok)
+	}
+	out, _, _ = w.push(4, 0xd)
+	if out != 2 {
+		t.Errorf("push left slot %d, want 2", out)
+	}
+
+	empty := newAdmissionWindow(10, 0.01) // size 0
+	if out, _, ok := empty.push(7, 0x7); !ok || out != 7 {
+		t.Errorf("empty window: push = (%d, %v), want the new slot itself", out, ok)
+	}
+}
+
+func TestAdmissionWindow_Climb(t *testing.T) {
+	w := newAdmissionWindow(1000, 0.01) // size 10, step 62.5, period 10000
+
+	period := func(n int, hitRatio float64) (int64, int64) {
+		accesses := int64(n) * w.sampleSize
+		return int64(float64(accesses) * hitRatio), accesses - int64(float64(accesses)*hitRatio)
+	}
+
+	w.climb(period(1, 0.5))
+	if size, _ := w.stats(); size != 10 {
+		t.Fatalf("first period must only record the hit ratio, size = %d", size)
+	}
+
+	// Hit ratio improved: grow by the step
+	h1, m1 := period(1, 0.5)
+	w.climb(h1+6000, m1+4000)
+	if size, adjustments := w.stats(); size != 73 || adjustments != 1 {
+		t.Errorf("after an improvement: size = %d (%d adjustments), want 73 (1)", size, adjustments)
+	}
+
+	// Hit ratio dropped: go back
+	w.climb(h1+6000+5000, m1+4000+5000)
+	if size, _ := w.stats(); size != 10 {
+		t.Errorf("after a drop: size = %d, want 10", size)
+	}
+
+	// Unchanged: keep going in the same direction, clamped at 0
+	w.climb(h1+6000+10000, m1+4000+10000)
+	if size, _ := w.stats(); size != 0 {
+		t.Errorf("after a steady period: size = %d, want 0", size)
+	}
+
+	// Less than a period: no decision
+	w.climb(h1+6000+10000+10, m1+4000+10000)
+	if _, adjustments := w.stats(); adjustments != 3 {
+		t.Errorf("adjustments = %d, want 3", adjustments)
+	}
+}
+
+func TestAdmissionWindow_ClimbBounded(t *testing.T) {
+	w := newAdmissionWindow(100, 0.5) // period 1000, max 80
+
+	hits, misses := int64(0), int64(0)
+	for i := 0; i < 50; i++ {
+		// Steadily improving hit ratio: keep growing
+		hits += int64(500 + i*10)
+		misses += int64(500 - i*10)
+		w.climb(hits, misses)
+	}
+	if size, _ := w.stats(); size != 80 {
+		t.Errorf("size = %d, want the 80%% bound", size)
+	}
+
+	// Counters reset by Clear: a new period starts, no adjustment
+	_, before := w.stats()
+	w.climb(10, 10)
+	w.climb(10+600, 10+400)
+	if _, after := w.stats(); after != before {
+		t.Errorf("climb after a reset adjusted the window (%d -> %d adjustments)", before, after)
+	}
+}
+
+func TestAdaptiveWindow_ProtectsNewKeys(t *testing.T) {
+	newFreshKeyKept := func(adaptive bool) bool {
+		cache := NewCache(Config{
+			MaxSize:         100,
+			WindowRatio:     0.1,
+			AdaptiveWindow:  adaptive,
+			AdmissionPolicy: TinyLFUAdmission{},
+		})
+		defer cache.Close()
+		warmHotKeys(cache, 100)
+
+		cache.Set("fresh", 1)
+		return cache.Has("fresh")
+	}
+
+	if newFreshKeyKept(false) {
+		t.Error("without a window TinyLFUAdmission should reject a key seen once")
+	}
+	if !newFreshKeyKept(true) {
+		t.Error("a new key must survive while in the admission window")
+	}
+}
+
+func TestAdaptiveWindow_ResistsScans(t *testing.T) {
+	_, keptDefault := hotKeysAfterScan(nil)
+
+	cache := NewCache(Config{MaxSize: 100, WindowRatio: 0.1, AdaptiveWindow: true})
+	defer cache.Close()
+	warmHotKeys(cache, 100)
+	for i := 0; i < 2000; i++ {
+		cache.Set(fmt.Sprintf("scan:%d", i), i)
+		cache.Get(fmt.Sprintf("hot:%d", i%100))
+	}
+
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if cache.Has(fmt.Sprintf("hot:%d", i)) {
+			kept++
+		}
+	}
+	// Keys leaving the window still face TinyLFU admission (the window
+	// itself holds 10 scanned keys)
+	if kept < 15 || kept < keptDefault+10 {
+		t.Errorf("a one-pass scan should not flush hot keys: %d/100 survived (%d without admission)", kept, keptDefault)
+	}
+	if cache.Len() > cache.Capacity() {
+		t.Errorf("cache exceeded capacity: %d > %d", cache.Len(), cache.Capacity())
+	}
+}
+
+func TestAdaptiveWindow_DefaultsToTinyLFU(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, AdaptiveWindow: true}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	if _, ok := cache.admission.(TinyLFUAdmission); !ok {
+		t.Errorf("admission = %T, want TinyLFUAdmission", cache.admission)
+	}
+	if cache.table.Load().admissionWindow == nil || cache.DebugStats().AdmissionWindowSize != 1 {
+		t.Errorf("window size = %d, want 1 (DefaultWindowRatio)", cache.DebugStats().AdmissionWindowSize)
+	}
+
+	plain := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer plain.Close()
+	if plain.DebugStats().AdmissionWindowSize != 0 {
+		t.Error("the window must be disabled without AdaptiveWindow")
+	}
+}
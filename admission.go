@@ -0,0 +1,108 @@
+// admission.go: admission control for new keys in a full cache
+//
+// Config.AdmissionPolicy decides whether a new key may displace the
+// sampled victim of a full cache.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// AdmissionPolicy decides whether a new key is kept when storing it made the
+// cache exceed MaxSize. Implementations must be safe for concurrent use.
+type AdmissionPolicy interface {
+	// Admit reports whether candidate, the new key, may stay at the expense
+	// of victim, the entry chosen by the EvictionPolicy. If it returns false
+	// the candidate is evicted instead of the victim.
+	Admit(candidate, victim EvictionCandidate) bool
+}
+
+// AlwaysAdmit admits every new key: the victim chosen by the EvictionPolicy
+// is always evicted. It is equivalent to leaving Config.AdmissionPolicy nil.
+type AlwaysAdmit struct{}
+
+// Admit always returns true.
+func (AlwaysAdmit) Admit(candidate, victim EvictionCandidate) bool { return true }
+
+// TinyLFUAdmission is the TinyLFU doorkeeper: a new key is admitted only if
+// its estimated frequency is higher than the victim's. Keys seen once (scans)
+// cannot displace entries that were read again; a rejected key that keeps
+// being requested accumulates frequency and is admitted later.
+type TinyLFUAdmission struct{}
+
+// Admit reports whether candidate is more frequent than victim.
+func (TinyLFUAdmission) Admit(candidate, victim EvictionCandidate) bool {
+	return candidate.Frequency > victim.Frequency
+}
+
+// AdmissionFunc adapts a function to an AdmissionPolicy.
+//
+// Example (keep the keys of a batch job out of a full cache):
+//
+//	AdmissionPolicy: balios.AdmissionFunc(func(candidate, victim balios.EvictionCandidate) bool {
+//	    return !strings.HasPrefix(candidate.Key, "report:")
+//	}),
+type AdmissionFunc func(candidate, victim EvictionCandidate) bool
+
+// Admit calls f(candidate, victim).
+func (f AdmissionFunc) Admit(candidate, victim EvictionCandidate) bool { return f(candidate, victim) }
+
+// makeRoomFor evicts one entry after the entry in slot idx of t, newly stored,
+// pushed the cache over MaxSize: the sampled victim if the AdmissionPolicy
+// admits the candidate, the candidate otherwise. The candidate is the new
+// entry, or with Config.AdaptiveWindow the entry leaving the admission window
+// (see adaptive_window.go).
+func (c *wtinyLFUCache) makeRoomFor(t *cacheTable, idx uint64) {
+	if c.admission == nil {
+		c.evictOne(t)
+		return
+	}
+	candidate := &t.entries[idx]
+	if t.admissionWindow != nil {
+		candidate = c.windowCompetitor(t, idx, atomic.LoadUint64(&candidate.keyHash))
+	}
+
+	tableSize := int(t.mask) + 1
+	step, limit := tableSize/evictionSampleSize, evictionSampleSize
+	if step < 1 {
+		step = 1
+	}
+	if c.maxWeight > 0 {
+		step, limit = 1, tableSize // Sparse table, see evictOne
+	}
+	start := int(c.fastRand() % uint64(tableSize)) // #nosec G115 -- tableSize bounded by maxSize, safe conversion
+	victim, decision := c.policyVictim(t, start, step, limit)
+	if inserted := &t.entries[idx]; victim == inserted && candidate != inserted {
+		// The new key is in the admission window: sample from the next slot
+		victim, decision = c.policyVictim(t, int(idx)+1, step, limit) // #nosec G115 -- idx < tableSize
+	}
+	rejected := false
+	if victim != nil && candidate != nil && victim != candidate {
+		candidateInfo := c.evictionCandidate(candidate)
+		victimInfo := c.evictionCandidate(victim)
+		if !c.admission.Admit(candidateInfo, victimInfo) {
+			victim, rejected = candidate, true
+			if decision != nil {
+				decision = &EvictionDecision{
+					Victim:     candidateInfo,
+					Candidates: []EvictionCandidate{victimInfo, candidateInfo},
+					Rejected:   true,
+				}
+			}
+		}
+	}
+	var rejectedHash uint64
+	if rejected {
+		rejectedHash = atomic.LoadUint64(&victim.keyHash)
+	}
+	if victim == nil || !c.evictEntry(t, victim, decision) {
+		c.evictOne(t)
+		return
+	}
+	if rejected {
+		c.recordRejection(rejectedHash) // See miss_reason.go
+	}
+}
@@ -0,0 +1,143 @@
+// admission_test.go: tests for admission control of new keys in a full cache
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAdmissionPolicies(t *testing.T) {
+	cold := EvictionCandidate{Key: "cold", Frequency: 1}
+	hot := EvictionCandidate{Key: "hot", Frequency: 5}
+
+	if !(AlwaysAdmit{}).Admit(cold, hot) {
+		t.Error("AlwaysAdmit must admit every key")
+	}
+	if (TinyLFUAdmission{}).Admit(cold, hot) || (TinyLFUAdmission{}).Admit(cold, cold) {
+		t.Error("TinyLFUAdmission must reject keys not more frequent than the victim")
+	}
+	if !(TinyLFUAdmission{}).Admit(hot, cold) {
+		t.Error("TinyLFUAdmission must admit keys more frequent than the victim")
+	}
+	f := AdmissionFunc(func(candidate, victim EvictionCandidate) bool { return candidate.Key == "hot" })
+	if !f.Admit(hot, cold) || f.Admit(cold, hot) {
+		t.Error("AdmissionFunc must delegate to the function")
+	}
+}
+
+// warmHotKeys fills cache with n hot keys read several times each.
+func warmHotKeys(cache Cache, n int) {
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("hot:%d", i)
+		cache.Set(key, i)
+		for r := 0; r < 3; r++ {
+			cache.Get(key)
+		}
+	}
+}
+
+// hotKeysAfterScan runs a one-pass scan over a cache warmed with 100 hot
+// keys, which keep being read during the scan, and returns the cache and the
+// number of hot keys that survived.
+func hotKeysAfterScan(policy AdmissionPolicy) (Cache, int) {
+	cache := NewCache(Config{MaxSize: 100, AdmissionPolicy: policy})
+	warmHotKeys(cache, 100)
+
+	for i := 0; i < 2000; i++ {
+		cache.Set(fmt.Sprintf("scan:%d", i), i)
+		cache.Get(fmt.Sprintf("hot:%d", i%100))
+	}
+
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if cache.Has(fmt.Sprintf("hot:%d", i)) {
+			kept++
+		}
+	}
+	return cache, kept
+}
+
+func TestAdmission_TinyLFUResistsScans(t *testing.T) {
+	_, keptDefault := hotKeysAfterScan(nil)
+	cache, kept := hotKeysAfterScan(TinyLFUAdmission{})
+
+	if kept < 25 || kept < keptDefault+20 {
+		t.Errorf("a one-pass scan should not flush hot keys: %d/100 survived (%d without admission)", kept, keptDefault)
+	}
+	if cache.Len() > cache.Capacity() {
+		t.Errorf("cache exceeded capacity: %d > %d", cache.Len(), cache.Capacity())
+	}
+
+	// A rejected key that keeps being requested is eventually admitted
+	for r := 0; r < 10 && !cache.Has("scan:0"); r++ {
+		cache.Get("scan:0")
+		cache.Set("scan:0", 0)
+	}
+	if !cache.Has("scan:0") {
+		t.Error("a frequently requested key must be admitted")
+	}
+}
+
+func TestAdmission_FuncWithAudit(t *testing.T) {
+	var calls int64
+	cache := NewCache(Config{
+		MaxSize:           64,
+		EvictionAuditSize: 1000,
+		AdmissionPolicy: AdmissionFunc(func(candidate, victim EvictionCandidate) bool {
+			atomic.AddInt64(&calls, 1)
+			return !strings.HasPrefix(candidate.Key, "batch:")
+		}),
+	}).(*wtinyLFUCache)
+	warmHotKeys(cache, 64)
+
+	for i := 0; i < 500; i++ {
+		cache.Set(fmt.Sprintf("batch:%d", i), i)
+	}
+
+	if atomic.LoadInt64(&calls) == 0 {
+		t.Fatal("admission policy was never consulted")
+	}
+	kept := 0
+	for i := 0; i < 64; i++ {
+		if cache.Has(fmt.Sprintf("hot:%d", i)) {
+			kept++
+		}
+	}
+	if kept < 60 {
+		t.Errorf("rejected keys should be evicted instead of hot keys, only %d/64 survived", kept)
+	}
+
+	rejected := 0
+	for _, d := range cache.DebugStats().EvictionAudit {
+		if !d.Rejected {
+			continue
+		}
+		rejected++
+		if !strings.HasPrefix(d.Victim.Key, "batch:") || len(d.Candidates) != 2 || d.Candidates[1].Key != d.Victim.Key {
+			t.Fatalf("unexpected rejection decision: %+v", d)
+		}
+	}
+	if rejected == 0 {
+		t.Error("rejections must be recorded in the eviction audit")
+	}
+}
+
+func TestAdmission_AlwaysAdmitMatchesDefault(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 32, AdmissionPolicy: AlwaysAdmit{}})
+	for i := 0; i < 200; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if cache.Len() > cache.Capacity() {
+		t.Errorf("cache exceeded capacity: %d > %d", cache.Len(), cache.Capacity())
+	}
+	if stats := cache.Stats(); stats.Evictions != uint64(200-cache.Len()) {
+		t.Errorf("every eviction must be counted: %d evictions, %d entries", stats.Evictions, cache.Len())
+	}
+}
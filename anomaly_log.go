@@ -0,0 +1,96 @@
+// anomaly_log.go: rate-limited Logger events for pathological conditions
+//
+// Contention anomalies are reported as Warn events through Config.Logger,
+// at most once per Config.AnomalyLogInterval each.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultAnomalyLogInterval is the default for Config.AnomalyLogInterval.
+const DefaultAnomalyLogInterval = time.Minute
+
+// anomaly is a kind of pathological event reported to the Logger.
+type anomaly int
+
+const (
+	anomalyFallbackEviction anomaly = iota // Eviction sampling found no victim
+	anomalyReadContention                  // A key read exhausted Config.KeyReadRetries
+	anomalyDuplicateCleanup                // Racing inserts left a duplicate key
+	anomalySetFailed                       // Set found no free slot within the probe bound
+	anomalyKinds
+)
+
+// anomalyMessages holds the log message of each anomaly kind.
+var anomalyMessages = [anomalyKinds]string{
+	anomalyFallbackEviction: "balios: eviction sampling found no victim, scanning the table",
+	anomalyReadContention:   "balios: key read abandoned under write contention",
+	anomalyDuplicateCleanup: "balios: duplicate key removed after concurrent inserts",
+	anomalySetFailed:        "balios: Set failed, every probed slot is busy",
+}
+
+// anomalyLog rate limits the anomaly events of a cache.
+type anomalyLog struct {
+	logger       Logger
+	timeProvider TimeProvider
+	name         string
+	interval     int64
+	last         [anomalyKinds]int64 // Time of the last event per kind
+	pending      [anomalyKinds]int64 // Occurrences since the last event per kind
+}
+
+// newAnomalyLog returns nil when there is no Logger or the events are
+// disabled (negative AnomalyLogInterval).
+func newAnomalyLog(config Config) *anomalyLog {
+	if _, noop := config.Logger.(NoOpLogger); noop || config.Logger == nil || config.AnomalyLogInterval < 0 {
+		return nil
+	}
+	interval := config.AnomalyLogInterval
+	if interval == 0 {
+		interval = DefaultAnomalyLogInterval
+	}
+	a := &anomalyLog{
+		logger:       config.Logger,
+		timeProvider: config.TimeProvider,
+		name:         config.Name,
+		interval:     int64(interval),
+	}
+	// The first occurrence of each kind is reported at once
+	start := a.timeProvider.Now() - a.interval
+	for i := range a.last {
+		a.last[i] = start
+	}
+	return a
+}
+
+// due counts an occurrence of kind and reports whether an event must be
+// logged now, with the number of occurrences it covers. Nil-safe.
+func (a *anomalyLog) due(kind anomaly) (occurrences int64, ok bool) {
+	if a == nil {
+		return 0, false
+	}
+	atomic.AddInt64(&a.pending[kind], 1)
+	now := a.timeProvider.Now()
+	last := atomic.LoadInt64(&a.last[kind])
+	if now-last < a.interval || !atomic.CompareAndSwapInt64(&a.last[kind], last, now) {
+		return 0, false
+	}
+	return atomic.SwapInt64(&a.pending[kind], 0), true
+}
+
+// log emits the event of kind, followed by keyvals.
+func (a *anomalyLog) log(kind anomaly, occurrences int64, keyvals ...interface{}) {
+	kv := make([]interface{}, 0, len(keyvals)+4)
+	if a.name != "" {
+		kv = append(kv, "cache", a.name)
+	}
+	kv = append(kv, "occurrences", occurrences)
+	a.logger.Warn(anomalyMessages[kind], append(kv, keyvals...)...)
+}
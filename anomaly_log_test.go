@@ -0,0 +1,143 @@
+// anomaly_log_test.go: tests for the rate-limited anomaly events
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// warnEvent is a Warn call captured by warnLogger.
+type warnEvent struct {
+	msg     string
+	keyvals map[interface{}]interface{}
+}
+
+type warnLogger struct {
+	NoOpLogger
+	mu     sync.Mutex
+	events []warnEvent
+}
+
+func (l *warnLogger) Warn(msg string, keyvals ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kv := map[interface{}]interface{}{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		kv[keyvals[i]] = keyvals[i+1]
+	}
+	l.events = append(l.events, warnEvent{msg: msg, keyvals: kv})
+}
+
+func (l *warnLogger) snapshot() []warnEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]warnEvent(nil), l.events...)
+}
+
+// eventsOf returns the captured events of kind.
+func (l *warnLogger) eventsOf(kind anomaly) []warnEvent {
+	var events []warnEvent
+	for _, event := range l.snapshot() {
+		if event.msg == anomalyMessages[kind] {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// holdAllSlots marks every slot of the table as being written.
+func holdAllSlots(c *wtinyLFUCache) {
+	for i := range c.table.Load().entries {
+		atomic.StoreInt32(&c.table.Load().entries[i].valid, entryPending)
+	}
+}
+
+func TestAnomalyLog_RateLimited(t *testing.T) {
+	logger := &warnLogger{}
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 16, Name: "sessions", Logger: logger, TimeProvider: timeProvider,
+		AnomalyLogInterval: time.Second}).(*wtinyLFUCache)
+	defer cache.Close()
+	holdAllSlots(cache)
+
+	for i := 0; i < 5; i++ {
+		if cache.Set("k", i) {
+			t.Fatal("Set succeeded with every slot busy")
+		}
+	}
+	events := logger.eventsOf(anomalySetFailed)
+	if len(events) != 1 {
+		t.Fatalf("expected one Set failure event, got %+v", events)
+	}
+	if kv := events[0].keyvals; kv["key"] != "k" || kv["occurrences"] != int64(1) || kv["cache"] != "sessions" {
+		t.Fatalf("unexpected event fields %v", kv)
+	}
+
+	timeProvider.Advance(time.Second)
+	cache.Set("k", 0)
+	events = logger.eventsOf(anomalySetFailed)
+	if len(events) != 2 || events[1].keyvals["occurrences"] != int64(5) {
+		t.Fatalf("expected a second event covering 5 occurrences, got %+v", events)
+	}
+}
+
+func TestAnomalyLog_Kinds(t *testing.T) {
+	logger := &warnLogger{}
+	cache := NewCache(Config{MaxSize: 16, Logger: logger}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	// A reader giving up on a key whose SeqLock stays odd
+	cache.Set("k", 1)
+	for i := range cache.table.Load().entries {
+		if e := &cache.table.Load().entries[i]; atomic.LoadInt32(&e.valid) == entryValid {
+			atomic.AddUint64(&e.version, 1)
+		}
+	}
+	cache.Get("k")
+
+	// Eviction finding no victim in its samples
+	holdAllSlots(cache)
+	cache.evictOne(cache.table.Load())
+
+	// A duplicate key cleaned up
+	cache.recordDuplicateCleanup(3)
+
+	seen := map[string]map[interface{}]interface{}{}
+	for _, event := range logger.snapshot() {
+		seen[event.msg] = event.keyvals
+	}
+	if kv := seen[anomalyMessages[anomalyReadContention]]; kv == nil || kv["retries"] != DefaultKeyReadRetries {
+		t.Errorf("read contention event: %v", kv)
+	}
+	if kv := seen[anomalyMessages[anomalyFallbackEviction]]; kv == nil {
+		t.Error("no fallback eviction event")
+	}
+	if kv := seen[anomalyMessages[anomalyDuplicateCleanup]]; kv == nil || kv["probe_distance"] != uint32(3) {
+		t.Errorf("duplicate cleanup event: %v", kv)
+	}
+	if _, named := seen[anomalyMessages[anomalyDuplicateCleanup]]["cache"]; named {
+		t.Error("anonymous cache reported with a name")
+	}
+}
+
+func TestAnomalyLog_Disabled(t *testing.T) {
+	if newAnomalyLog(Config{Logger: NoOpLogger{}, TimeProvider: &systemTimeProvider{}}) != nil {
+		t.Error("anomaly log created without a Logger")
+	}
+
+	logger := &warnLogger{}
+	cache := NewCache(Config{MaxSize: 16, Logger: logger, AnomalyLogInterval: -1}).(*wtinyLFUCache)
+	defer cache.Close()
+	holdAllSlots(cache)
+	cache.Set("k", 1)
+	if events := logger.snapshot(); len(events) != 0 {
+		t.Fatalf("events logged with a negative interval: %+v", events)
+	}
+}
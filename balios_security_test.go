@@ -84,11 +84,11 @@ func NewSecurityTestContext(t *testing.T) *SecurityTestContext {
 //
 // SECURITY PURPOSE: Tests how Balios handles malicious configuration,
 // including extreme values, invalid bounds, and resource exhaustion attempts.
-func (ctx *SecurityTestContext) CreateMaliciousCache(config Config) Cache {
+func (ctx *SecurityTestContext) CreateMaliciousCache(config Config) *wtinyLFUCache {
 	ctx.mu.Lock()
 	defer ctx.mu.Unlock()
 
-	cache := NewCache(config)
+	cache := NewCache(config).(*wtinyLFUCache)
 	ctx.caches = append(ctx.caches, cache)
 	return cache
 }
@@ -381,6 +381,25 @@ func TestSecurity_MemoryExhaustionAttacks(t *testing.T) {
 		ctx.CheckMemoryLeak("large value attack", 100.0) // Allow 100MB (2x for overhead)
 	})
 
+	t.Run("OversizedWritesRejected", func(t *testing.T) {
+		cache := ctx.CreateMaliciousCache(Config{
+			MaxSize:       10,
+			MaxKeyLen:     1024,
+			MaxValueBytes: 1 << 20,
+		})
+
+		// SECURITY TEST: With size limits, abusive keys and values are refused
+		if err := cache.SetE(strings.Repeat("A", 1<<20), "v"); !IsValueTooLarge(err) {
+			t.Errorf("SECURITY VULNERABILITY: 1MB key accepted despite MaxKeyLen: %v", err)
+		}
+		if err := cache.SetE("key", make([]byte, 10<<20)); !IsValueTooLarge(err) {
+			t.Errorf("SECURITY VULNERABILITY: 10MB value accepted despite MaxValueBytes: %v", err)
+		}
+		if cache.Len() != 0 {
+			t.Errorf("SECURITY VULNERABILITY: oversized writes were stored (%d entries)", cache.Len())
+		}
+	})
+
 	t.Run("ExceedMaxSizeAttack", func(t *testing.T) {
 		maxSize := 100
 		cache := ctx.CreateMaliciousCache(Config{
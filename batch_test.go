@@ -0,0 +1,100 @@
+// batch_test.go: tests for GetMany and SetMany
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetMany(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	if n := cache.SetMany(map[string]interface{}{"a": 1, "b": 2, "c": 3, "": 4}); n != 3 {
+		t.Errorf("expected 3 pairs stored, got %d", n)
+	}
+
+	got := cache.GetMany([]string{"a", "c", "missing", ""})
+	if len(got) != 2 || got["a"] != 1 || got["c"] != 3 {
+		t.Errorf("unexpected result: %v", got)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d/%d", stats.Hits, stats.Misses)
+	}
+	if got := cache.GetMany(nil); got == nil || len(got) != 0 {
+		t.Errorf("empty batch must return an empty map, got %v", got)
+	}
+}
+
+func TestGetMany_Expiration(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("old", 1)
+	mockTime.Advance(2 * time.Second)
+	cache.Set("new", 2)
+
+	got := cache.GetMany([]string{"old", "new"})
+	if _, ok := got["old"]; ok || got["new"] != 2 {
+		t.Errorf("expired keys must be omitted: %v", got)
+	}
+	if n := cache.Stats().Expirations; n != 1 {
+		t.Errorf("expired entry must be reclaimed, got %d expirations", n)
+	}
+}
+
+func TestGetMany_Metrics(t *testing.T) {
+	metrics := &mockMetricsCollector{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: metrics}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+	cache.GetMany([]string{"a", "b", ""})
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.getCalls != 2 || metrics.hitCount != 1 || metrics.missCount != 1 {
+		t.Errorf("expected one RecordGet per non-empty key: calls=%d hits=%d misses=%d",
+			metrics.getCalls, metrics.hitCount, metrics.missCount)
+	}
+}
+
+func TestGetMany_Generic(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 100})
+	cache.SetMany(map[int]string{1: "one", 2: "two"})
+
+	got := cache.GetMany([]int{1, 2, 3})
+	if len(got) != 2 || got[1] != "one" || got[2] != "two" {
+		t.Errorf("unexpected result: %v", got)
+	}
+}
+
+func BenchmarkGetMany_100(b *testing.B) {
+	cache := NewCache(Config{MaxSize: 10000}).(*wtinyLFUCache)
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+		cache.Set(keys[i], i)
+	}
+
+	b.Run("GetMany", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = cache.GetMany(keys)
+		}
+	})
+	b.Run("GetLoop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			result := make(map[string]interface{}, len(keys))
+			for _, key := range keys {
+				if v, ok := cache.Get(key); ok {
+					result[key] = v
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,173 @@
+// caches.go: uniform wrappers around the caches under comparison
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package benchmarks
+
+import (
+	"github.com/agilira/balios"
+	ristretto "github.com/dgraph-io/ristretto/v2"
+	"github.com/maypok86/otter/v2"
+)
+
+// =============================================================================
+// CACHE WRAPPERS FOR UNIFORM INTERFACE
+// =============================================================================
+
+// CacheInterface provides a uniform interface for all caches
+type CacheInterface interface {
+	Set(key string, value int) bool
+	Get(key string) (int, bool)
+	Name() string
+	Close()
+}
+
+// =============================================================================
+// BALIOS WRAPPER (Non-Generic Legacy API)
+// =============================================================================
+
+type BaliosCache struct {
+	cache balios.Cache
+}
+
+func NewBaliosCache(size int) *BaliosCache {
+	return &BaliosCache{
+		cache: balios.NewCache(balios.Config{
+			MaxSize: size,
+		}),
+	}
+}
+
+// NewBaliosKeySlabsCache is NewBaliosCache with pooled key storage
+// (Config.KeySlabSize), for churn-heavy comparisons.
+func NewBaliosKeySlabsCache(size int) *BaliosCache {
+	return &BaliosCache{
+		cache: balios.NewCache(balios.Config{
+			MaxSize:     size,
+			KeySlabSize: 4096,
+		}),
+	}
+}
+
+func (c *BaliosCache) Set(key string, value int) bool {
+	return c.cache.Set(key, value)
+}
+
+func (c *BaliosCache) Get(key string) (int, bool) {
+	v, ok := c.cache.Get(key)
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+func (c *BaliosCache) Name() string {
+	return "Balios"
+}
+
+func (c *BaliosCache) Close() {
+	c.cache.Close()
+}
+
+// =============================================================================
+// BALIOS GENERIC WRAPPER (Optimized Generic API)
+// =============================================================================
+
+type BaliosGenericCache struct {
+	cache *balios.GenericCache[string, int]
+}
+
+func NewBaliosGenericCache(size int) *BaliosGenericCache {
+	return &BaliosGenericCache{
+		cache: balios.NewGenericCache[string, int](balios.Config{
+			MaxSize: size,
+		}),
+	}
+}
+
+func (c *BaliosGenericCache) Set(key string, value int) bool {
+	c.cache.Set(key, value)
+	return true
+}
+
+func (c *BaliosGenericCache) Get(key string) (int, bool) {
+	return c.cache.Get(key)
+}
+
+func (c *BaliosGenericCache) Name() string {
+	return "Balios-Generic"
+}
+
+func (c *BaliosGenericCache) Close() {
+	c.cache.Close()
+}
+
+// =============================================================================
+// OTTER WRAPPER
+// =============================================================================
+
+type OtterCache struct {
+	cache *otter.Cache[string, int]
+}
+
+func NewOtterCache(size int) *OtterCache {
+	cache := otter.Must(&otter.Options[string, int]{
+		MaximumSize: size,
+	})
+	return &OtterCache{cache: cache}
+}
+
+func (c *OtterCache) Set(key string, value int) bool {
+	c.cache.Set(key, value)
+	return true
+}
+
+func (c *OtterCache) Get(key string) (int, bool) {
+	return c.cache.GetIfPresent(key)
+}
+
+func (c *OtterCache) Name() string {
+	return "Otter"
+}
+
+func (c *OtterCache) Close() {
+	// Otter v2 Close is handled automatically
+}
+
+// =============================================================================
+// RISTRETTO WRAPPER
+// =============================================================================
+
+type RistrettoCache struct {
+	cache *ristretto.Cache[string, int]
+}
+
+func NewRistrettoCache(size int) *RistrettoCache {
+	cache, err := ristretto.NewCache(&ristretto.Config[string, int]{
+		NumCounters: int64(size * 10),
+		MaxCost:     int64(size),
+		BufferItems: 64,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &RistrettoCache{cache: cache}
+}
+
+func (c *RistrettoCache) Set(key string, value int) bool {
+	return c.cache.Set(key, value, 1)
+}
+
+func (c *RistrettoCache) Get(key string) (int, bool) {
+	return c.cache.Get(key)
+}
+
+func (c *RistrettoCache) Name() string {
+	return "Ristretto"
+}
+
+func (c *RistrettoCache) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,344 @@
+// traces.go: hit-ratio trace corpus runner
+//
+// Replays cache traces (ARC, LIRS, Twitter; more with RegisterTraceLoader)
+// and reports the hit ratio per cache and size. Traces are not vendored.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package benchmarks
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TraceReader yields the keys of a trace in access order.
+// Next returns io.EOF after the last key.
+type TraceReader interface {
+	Next() (string, error)
+}
+
+// TraceLoader builds a TraceReader for a trace format from its raw content.
+type TraceLoader func(r io.Reader) TraceReader
+
+var (
+	traceLoadersMu sync.RWMutex
+	traceLoaders   = map[string]TraceLoader{
+		"arc":     NewARCTraceReader,
+		"lirs":    NewLIRSTraceReader,
+		"twitter": NewTwitterTraceReader,
+	}
+)
+
+// RegisterTraceLoader makes a trace format available to OpenTrace under name.
+// Registering an existing name replaces its loader.
+func RegisterTraceLoader(name string, loader TraceLoader) {
+	traceLoadersMu.Lock()
+	defer traceLoadersMu.Unlock()
+	traceLoaders[name] = loader
+}
+
+// TraceFormats returns the registered trace format names, sorted.
+func TraceFormats() []string {
+	traceLoadersMu.RLock()
+	defer traceLoadersMu.RUnlock()
+	names := make([]string, 0, len(traceLoaders))
+	for name := range traceLoaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenTrace opens the trace file at path using the loader registered for format.
+// The returned closer must be closed once the trace has been consumed.
+func OpenTrace(path, format string) (TraceReader, io.Closer, error) {
+	traceLoadersMu.RLock()
+	loader, ok := traceLoaders[format]
+	traceLoadersMu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("traces: unknown format %q (registered: %s)", format, strings.Join(TraceFormats(), ", "))
+	}
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return loader(f), f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return loader(gz), multiCloser{gz, f}, nil
+}
+
+// multiCloser closes every closer in order, returning the first error.
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var first error
+	for _, c := range m {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// lineTraceReader parses a line-oriented trace, one line at a time.
+// parse returns the keys of a line (possibly none) or an error.
+type lineTraceReader struct {
+	scanner *bufio.Scanner
+	parse   func(line string) ([]string, error)
+	pending []string
+	line    int
+}
+
+func newLineTraceReader(r io.Reader, parse func(string) ([]string, error)) *lineTraceReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &lineTraceReader{scanner: scanner, parse: parse}
+}
+
+func (t *lineTraceReader) Next() (string, error) {
+	for len(t.pending) == 0 {
+		if !t.scanner.Scan() {
+			if err := t.scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		t.line++
+		line := strings.TrimSpace(t.scanner.Text())
+		if line == "" {
+			continue
+		}
+		keys, err := t.parse(line)
+		if err != nil {
+			return "", fmt.Errorf("traces: line %d: %w", t.line, err)
+		}
+		t.pending = keys
+	}
+	key := t.pending[0]
+	t.pending = t.pending[1:]
+	return key, nil
+}
+
+// maxARCBlocksPerRequest bounds the block range expanded from a single ARC line.
+const maxARCBlocksPerRequest = 1 << 16
+
+// NewARCTraceReader reads traces in the ARC format ("start blocks ignore request"):
+// every line accesses blocks start .. start+blocks-1.
+func NewARCTraceReader(r io.Reader) TraceReader {
+	return newLineTraceReader(r, func(line string) ([]string, error) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("expected at least 2 fields, got %d", len(fields))
+		}
+		start, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		count, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if count > maxARCBlocksPerRequest {
+			return nil, fmt.Errorf("block count %d exceeds %d", count, maxARCBlocksPerRequest)
+		}
+		keys := make([]string, count)
+		for i := range keys {
+			keys[i] = strconv.FormatUint(start+uint64(i), 10) // #nosec G115 -- i is non-negative
+		}
+		return keys, nil
+	})
+}
+
+// NewLIRSTraceReader reads traces in the LIRS format: one block number per line.
+// Lines starting with '*' (trace separators) are skipped.
+func NewLIRSTraceReader(r io.Reader) TraceReader {
+	return newLineTraceReader(r, func(line string) ([]string, error) {
+		if line[0] == '*' {
+			return nil, nil
+		}
+		if _, err := strconv.ParseUint(line, 10, 64); err != nil {
+			return nil, err
+		}
+		return []string{line}, nil
+	})
+}
+
+// NewTwitterTraceReader reads Twitter cache cluster traces (CSV:
+// timestamp,key,key size,value size,client id,operation,TTL).
+// Only read operations (get, gets) are replayed; writes are skipped because
+// the runner populates the cache on misses (cache-aside).
+func NewTwitterTraceReader(r io.Reader) TraceReader {
+	return newLineTraceReader(r, func(line string) ([]string, error) {
+		fields := strings.Split(line, ",")
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("expected at least 6 fields, got %d", len(fields))
+		}
+		switch fields[5] {
+		case "get", "gets":
+			return []string{fields[1]}, nil
+		default:
+			return nil, nil
+		}
+	})
+}
+
+// CacheFactory creates a cache of a given capacity for trace replay.
+type CacheFactory struct {
+	Name string
+	New  func(size int) CacheInterface
+}
+
+// DefaultCacheFactories returns the caches compared by the benchmarks module.
+func DefaultCacheFactories() []CacheFactory {
+	return []CacheFactory{
+		{"Balios", func(size int) CacheInterface { return NewBaliosCache(size) }},
+		{"Balios-Generic", func(size int) CacheInterface { return NewBaliosGenericCache(size) }},
+		{"Otter", func(size int) CacheInterface { return NewOtterCache(size) }},
+		{"Ristretto", func(size int) CacheInterface { return NewRistrettoCache(size) }},
+	}
+}
+
+// TraceResult is the hit ratio of one cache at one size on one trace.
+type TraceResult struct {
+	Trace    string
+	Cache    string
+	Size     int
+	Requests int
+	Hits     int
+}
+
+// HitRatio returns the hit percentage.
+func (r TraceResult) HitRatio() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Requests) * 100
+}
+
+// TraceSpec identifies a trace to replay.
+type TraceSpec struct {
+	Name string
+	// Open returns a fresh reader positioned at the start of the trace.
+	// It is called once per cache and size.
+	Open func() (TraceReader, io.Closer, error)
+}
+
+// FileTrace returns a TraceSpec replaying the file at path in the given format.
+func FileTrace(path, format string) TraceSpec {
+	return TraceSpec{
+		Name: filepath.Base(path),
+		Open: func() (TraceReader, io.Closer, error) { return OpenTrace(path, format) },
+	}
+}
+
+// RunTrace replays trace against every factory at every size and returns one
+// result per (size, cache), in order. Each access is a Get; misses are
+// followed by a Set of the same key (cache-aside).
+func RunTrace(trace TraceSpec, sizes []int, factories []CacheFactory) ([]TraceResult, error) {
+	results := make([]TraceResult, 0, len(sizes)*len(factories))
+	for _, size := range sizes {
+		for _, factory := range factories {
+			res, err := replayTrace(trace, size, factory)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+func replayTrace(trace TraceSpec, size int, factory CacheFactory) (TraceResult, error) {
+	res := TraceResult{Trace: trace.Name, Cache: factory.Name, Size: size}
+
+	reader, closer, err := trace.Open()
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = closer.Close() }()
+
+	c := factory.New(size)
+	defer c.Close()
+
+	for {
+		key, err := reader.Next()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res.Requests++
+		if _, ok := c.Get(key); ok {
+			res.Hits++
+		} else {
+			c.Set(key, res.Requests)
+		}
+	}
+}
+
+// FormatTraceResults renders results as a table with one row per trace and
+// size and one column per cache.
+func FormatTraceResults(results []TraceResult) string {
+	var caches []string
+	seen := map[string]bool{}
+	type row struct {
+		trace string
+		size  int
+	}
+	var rows []row
+	seenRow := map[row]bool{}
+	ratios := map[row]map[string]float64{}
+
+	for _, r := range results {
+		if !seen[r.Cache] {
+			seen[r.Cache] = true
+			caches = append(caches, r.Cache)
+		}
+		k := row{r.Trace, r.Size}
+		if !seenRow[k] {
+			seenRow[k] = true
+			rows = append(rows, k)
+			ratios[k] = map[string]float64{}
+		}
+		ratios[k][r.Cache] = r.HitRatio()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-24s %10s", "Trace", "Size")
+	for _, c := range caches {
+		fmt.Fprintf(&b, " %15s", c)
+	}
+	b.WriteByte('\n')
+	for _, k := range rows {
+		fmt.Fprintf(&b, "%-24s %10d", k.trace, k.size)
+		for _, c := range caches {
+			if v, ok := ratios[k][c]; ok {
+				fmt.Fprintf(&b, " %14.2f%%", v)
+			} else {
+				fmt.Fprintf(&b, " %15s", "-")
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,180 @@
+// traces_test.go: tests for the trace loaders and the corpus runner
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package benchmarks
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func readAllKeys(t *testing.T, r TraceReader) []string {
+	t.Helper()
+	var keys []string
+	for {
+		key, err := r.Next()
+		if err == io.EOF {
+			return keys
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		keys = append(keys, key)
+	}
+}
+
+func TestARCTraceReader(t *testing.T) {
+	trace := "10 3 0 1\n\n42 1 0 2\n"
+	got := readAllKeys(t, NewARCTraceReader(strings.NewReader(trace)))
+	want := []string{"10", "11", "12", "42"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	_, err := NewARCTraceReader(strings.NewReader("x 1 0 1\n")).Next()
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected parse error with line number, got %v", err)
+	}
+}
+
+func TestLIRSTraceReader(t *testing.T) {
+	got := readAllKeys(t, NewLIRSTraceReader(strings.NewReader("1\n2\n*\n1\n")))
+	if want := []string{"1", "2", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTwitterTraceReader(t *testing.T) {
+	trace := strings.Join([]string{
+		"0,keyA,10,100,1,get,0",
+		"1,keyB,10,100,1,set,3600",
+		"2,keyA,10,100,2,gets,0",
+		"3,keyC,10,0,2,delete,0",
+	}, "\n")
+	got := readAllKeys(t, NewTwitterTraceReader(strings.NewReader(trace)))
+	if want := []string{"keyA", "keyA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestOpenTrace_GzipAndRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.lirs.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	_, _ = gz.Write([]byte("7\n8\n"))
+	_ = gz.Close()
+	_ = f.Close()
+
+	r, closer, err := OpenTrace(path, "lirs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAllKeys(t, r); !reflect.DeepEqual(got, []string{"7", "8"}) {
+		t.Errorf("unexpected keys %v", got)
+	}
+	_ = closer.Close()
+
+	if _, _, err := OpenTrace(path, "nope"); err == nil {
+		t.Error("expected unknown format error")
+	}
+
+	RegisterTraceLoader("test-upper", func(r io.Reader) TraceReader {
+		return newLineTraceReader(r, func(line string) ([]string, error) {
+			return []string{strings.ToUpper(line)}, nil
+		})
+	})
+	found := false
+	for _, name := range TraceFormats() {
+		found = found || name == "test-upper"
+	}
+	if !found {
+		t.Error("registered format not listed")
+	}
+}
+
+func TestRunTrace_HitRatioPerSize(t *testing.T) {
+	// Cyclic scan over 100 keys repeated 20 times: a cache holding every key
+	// only misses the first pass
+	var b strings.Builder
+	for pass := 0; pass < 20; pass++ {
+		for k := 0; k < 100; k++ {
+			b.WriteString(strconv.Itoa(k))
+			b.WriteByte('\n')
+		}
+	}
+	content := b.String()
+	trace := TraceSpec{
+		Name: "cyclic",
+		Open: func() (TraceReader, io.Closer, error) {
+			return NewLIRSTraceReader(strings.NewReader(content)), io.NopCloser(nil), nil
+		},
+	}
+
+	factories := []CacheFactory{{"Balios", func(size int) CacheInterface { return NewBaliosCache(size) }}}
+	results, err := RunTrace(trace, []int{1_000}, factories)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Requests != 2_000 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if ratio := results[0].HitRatio(); ratio < 94 {
+		t.Errorf("expected ~95%% hit ratio when the working set fits, got %.2f", ratio)
+	}
+
+	table := FormatTraceResults(results)
+	if !strings.Contains(table, "cyclic") || !strings.Contains(table, "Balios") {
+		t.Errorf("unexpected table:\n%s", table)
+	}
+}
+
+// TestTraceCorpus replays external traces listed in BALIOS_TRACES as
+// semicolon-separated format:path entries, e.g.
+//
+//	BALIOS_TRACES="arc:/data/P8.lis;twitter:/data/cluster52.sort.gz" go test -run TestTraceCorpus -v
+//
+// Cache sizes default to 1000,10000,100000 and can be set with BALIOS_TRACE_SIZES.
+func TestTraceCorpus(t *testing.T) {
+	spec := os.Getenv("BALIOS_TRACES")
+	if spec == "" {
+		t.Skip("BALIOS_TRACES not set")
+	}
+
+	sizes := []int{1_000, 10_000, 100_000}
+	if env := os.Getenv("BALIOS_TRACE_SIZES"); env != "" {
+		sizes = sizes[:0]
+		for _, s := range strings.Split(env, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || n <= 0 {
+				t.Fatalf("invalid size %q", s)
+			}
+			sizes = append(sizes, n)
+		}
+	}
+
+	var all []TraceResult
+	for _, entry := range strings.Split(spec, ";") {
+		format, path, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			t.Fatalf("invalid trace entry %q (want format:path)", entry)
+		}
+		results, err := RunTrace(FileTrace(path, format), sizes, DefaultCacheFactories())
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		all = append(all, results...)
+	}
+	t.Logf("\n%s", FormatTraceResults(all))
+}
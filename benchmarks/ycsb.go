@@ -0,0 +1,409 @@
+// ycsb.go: YCSB-style workload generator
+//
+// Read/update/insert/scan mixes over uniform, zipfian, hotspot and latest
+// request distributions, usable as a library.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package benchmarks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// Distribution selects how keys are picked for read/update/scan operations.
+type Distribution int
+
+const (
+	// DistUniform picks every existing key with equal probability.
+	DistUniform Distribution = iota
+
+	// DistZipfian favors a small set of popular keys (power law).
+	DistZipfian
+
+	// DistHotspot sends HotspotOpnFraction of the operations to the first
+	// HotspotDataFraction of the key space, uniformly within each region.
+	DistHotspot
+
+	// DistLatest favors the most recently inserted keys (zipfian over recency).
+	DistLatest
+)
+
+// String returns the distribution name.
+func (d Distribution) String() string {
+	switch d {
+	case DistUniform:
+		return "uniform"
+	case DistZipfian:
+		return "zipfian"
+	case DistHotspot:
+		return "hotspot"
+	case DistLatest:
+		return "latest"
+	default:
+		return fmt.Sprintf("Distribution(%d)", int(d))
+	}
+}
+
+// OpType identifies a workload operation.
+type OpType int
+
+const (
+	// OpRead reads a single existing key.
+	OpRead OpType = iota
+
+	// OpUpdate overwrites a single existing key.
+	OpUpdate
+
+	// OpInsert writes a brand new key, extending the key space.
+	OpInsert
+
+	// OpScan reads ScanLength consecutive keys starting at Key.
+	OpScan
+
+	// OpReadModifyWrite reads a key and writes it back.
+	OpReadModifyWrite
+)
+
+// String returns the operation name.
+func (o OpType) String() string {
+	switch o {
+	case OpRead:
+		return "read"
+	case OpUpdate:
+		return "update"
+	case OpInsert:
+		return "insert"
+	case OpScan:
+		return "scan"
+	case OpReadModifyWrite:
+		return "rmw"
+	default:
+		return fmt.Sprintf("OpType(%d)", int(o))
+	}
+}
+
+// Default workload parameters (YCSB core workload defaults where applicable).
+const (
+	DefaultRecordCount         = 10_000
+	DefaultZipfS               = 1.1 // math/rand.Zipf requires s > 1
+	DefaultHotspotDataFraction = 0.2
+	DefaultHotspotOpnFraction  = 0.8
+	DefaultMaxScanLength       = 100
+)
+
+// WorkloadConfig describes a YCSB-style workload.
+//
+// Operation proportions are relative weights; they do not need to sum to 1.
+// Zero-valued tuning fields fall back to the Default* constants.
+type WorkloadConfig struct {
+	// RecordCount is the number of keys loaded before the run phase.
+	RecordCount int
+
+	// Operation mix (relative weights).
+	ReadProportion            float64
+	UpdateProportion          float64
+	InsertProportion          float64
+	ScanProportion            float64
+	ReadModifyWriteProportion float64
+
+	// Distribution used to choose existing keys.
+	Distribution Distribution
+
+	// ZipfS is the zipfian exponent (must be > 1). Used by DistZipfian and DistLatest.
+	ZipfS float64
+
+	// HotspotDataFraction is the fraction of keys forming the hot set (DistHotspot).
+	HotspotDataFraction float64
+
+	// HotspotOpnFraction is the fraction of operations hitting the hot set (DistHotspot).
+	HotspotOpnFraction float64
+
+	// MaxScanLength bounds the uniformly chosen length of OpScan operations.
+	MaxScanLength int
+
+	// Seed makes the operation sequence reproducible. Zero uses the current time.
+	Seed int64
+}
+
+// Core YCSB workloads. RecordCount and Seed can be overridden on the returned copy.
+var (
+	// WorkloadA is update heavy: 50% reads, 50% updates, zipfian.
+	WorkloadA = WorkloadConfig{ReadProportion: 0.5, UpdateProportion: 0.5, Distribution: DistZipfian}
+
+	// WorkloadB is read mostly: 95% reads, 5% updates, zipfian.
+	WorkloadB = WorkloadConfig{ReadProportion: 0.95, UpdateProportion: 0.05, Distribution: DistZipfian}
+
+	// WorkloadC is read only: 100% reads, zipfian.
+	WorkloadC = WorkloadConfig{ReadProportion: 1.0, Distribution: DistZipfian}
+
+	// WorkloadD reads latest: 95% reads, 5% inserts, latest.
+	WorkloadD = WorkloadConfig{ReadProportion: 0.95, InsertProportion: 0.05, Distribution: DistLatest}
+
+	// WorkloadE is short ranges: 95% scans, 5% inserts, zipfian.
+	WorkloadE = WorkloadConfig{ScanProportion: 0.95, InsertProportion: 0.05, Distribution: DistZipfian}
+
+	// WorkloadF is read-modify-write: 50% reads, 50% read-modify-writes, zipfian.
+	WorkloadF = WorkloadConfig{ReadProportion: 0.5, ReadModifyWriteProportion: 0.5, Distribution: DistZipfian}
+)
+
+// Operation is a single generated workload operation.
+type Operation struct {
+	Type       OpType
+	Key        string
+	ScanLength int // Only set for OpScan
+}
+
+// Workload generates a stream of operations for a WorkloadConfig.
+//
+// A Workload is NOT safe for concurrent use: create one per goroutine
+// (with distinct seeds) when driving a cache from multiple goroutines.
+type Workload struct {
+	cfg     WorkloadConfig
+	rng     *rand.Rand
+	zipf    *rand.Zipf
+	weights [5]float64 // cumulative weights indexed by OpType
+	records uint64     // current key count (grows with inserts)
+}
+
+// NewWorkload validates cfg and returns a generator for it.
+func NewWorkload(cfg WorkloadConfig) (*Workload, error) {
+	if cfg.RecordCount <= 0 {
+		cfg.RecordCount = DefaultRecordCount
+	}
+	if cfg.ZipfS == 0 {
+		cfg.ZipfS = DefaultZipfS
+	}
+	if cfg.HotspotDataFraction == 0 {
+		cfg.HotspotDataFraction = DefaultHotspotDataFraction
+	}
+	if cfg.HotspotOpnFraction == 0 {
+		cfg.HotspotOpnFraction = DefaultHotspotOpnFraction
+	}
+	if cfg.MaxScanLength <= 0 {
+		cfg.MaxScanLength = DefaultMaxScanLength
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+
+	if cfg.ZipfS <= 1 {
+		return nil, fmt.Errorf("ycsb: ZipfS must be > 1, got %f", cfg.ZipfS)
+	}
+	if cfg.HotspotDataFraction < 0 || cfg.HotspotDataFraction > 1 ||
+		cfg.HotspotOpnFraction < 0 || cfg.HotspotOpnFraction > 1 {
+		return nil, errors.New("ycsb: hotspot fractions must be within [0, 1]")
+	}
+
+	proportions := [5]float64{
+		OpRead:            cfg.ReadProportion,
+		OpUpdate:          cfg.UpdateProportion,
+		OpInsert:          cfg.InsertProportion,
+		OpScan:            cfg.ScanProportion,
+		OpReadModifyWrite: cfg.ReadModifyWriteProportion,
+	}
+	w := &Workload{
+		cfg:     cfg,
+		rng:     rand.New(rand.NewSource(cfg.Seed)), // #nosec G404 -- benchmark workload, not security sensitive
+		records: uint64(cfg.RecordCount),            // #nosec G115 -- RecordCount validated positive
+	}
+	total := 0.0
+	for op, p := range proportions {
+		if p < 0 || math.IsNaN(p) {
+			return nil, fmt.Errorf("ycsb: %s proportion must be >= 0", OpType(op))
+		}
+		total += p
+		w.weights[op] = total
+	}
+	if total == 0 {
+		return nil, errors.New("ycsb: at least one operation proportion must be positive")
+	}
+
+	w.zipf = rand.NewZipf(w.rng, cfg.ZipfS, 1, w.records-1)
+	return w, nil
+}
+
+// Config returns the effective configuration (defaults applied).
+func (w *Workload) Config() WorkloadConfig {
+	return w.cfg
+}
+
+// RecordCount returns the current key count, including keys inserted so far.
+func (w *Workload) RecordCount() int {
+	return int(w.records) // #nosec G115 -- bounded by RecordCount + inserts
+}
+
+// KeyName returns the key for record index i.
+func KeyName(i uint64) string {
+	return "user" + strconv.FormatUint(i, 10)
+}
+
+// LoadKeys returns the keys of the initial load phase, in insertion order.
+func (w *Workload) LoadKeys() []string {
+	keys := make([]string, w.cfg.RecordCount)
+	for i := range keys {
+		keys[i] = KeyName(uint64(i)) // #nosec G115 -- i is non-negative
+	}
+	return keys
+}
+
+// Next returns the next operation.
+func (w *Workload) Next() Operation {
+	r := w.rng.Float64() * w.weights[len(w.weights)-1]
+	op := OpRead
+	for i, cum := range w.weights {
+		if r < cum {
+			op = OpType(i)
+			break
+		}
+	}
+
+	switch op {
+	case OpInsert:
+		key := KeyName(w.records)
+		w.records++
+		return Operation{Type: OpInsert, Key: key}
+	case OpScan:
+		return Operation{
+			Type:       OpScan,
+			Key:        KeyName(w.nextIndex()),
+			ScanLength: 1 + w.rng.Intn(w.cfg.MaxScanLength),
+		}
+	default:
+		return Operation{Type: op, Key: KeyName(w.nextIndex())}
+	}
+}
+
+// nextIndex picks an existing record index according to the distribution.
+func (w *Workload) nextIndex() uint64 {
+	n := w.records
+	switch w.cfg.Distribution {
+	case DistZipfian:
+		// The zipf generator covers the initial records; scale onto the grown
+		// key space so inserted keys remain reachable.
+		idx := w.zipf.Uint64()
+		if n > uint64(w.cfg.RecordCount) { // #nosec G115 -- validated positive
+			idx = idx * n / uint64(w.cfg.RecordCount) // #nosec G115 -- validated positive
+		}
+		return idx % n
+	case DistHotspot:
+		hot := uint64(float64(n) * w.cfg.HotspotDataFraction)
+		if hot == 0 {
+			hot = 1
+		}
+		if w.rng.Float64() < w.cfg.HotspotOpnFraction || hot >= n {
+			return uint64(w.rng.Int63n(int64(hot))) // #nosec G115 -- hot <= records
+		}
+		return hot + uint64(w.rng.Int63n(int64(n-hot))) // #nosec G115 -- hot < records
+	case DistLatest:
+		offset := w.zipf.Uint64()
+		if offset >= n {
+			offset = n - 1
+		}
+		return n - 1 - offset
+	default:
+		return uint64(w.rng.Int63n(int64(n))) // #nosec G115 -- records fits int64
+	}
+}
+
+// WorkloadResult summarizes a workload run against a cache.
+type WorkloadResult struct {
+	Cache      string
+	Operations int
+	Reads      int // Single-key reads, including the read half of read-modify-writes
+	ReadHits   int
+	ScanReads  int // Keys read by scans
+	ScanHits   int
+	Writes     int // Updates, inserts and the write half of read-modify-writes
+	Fills      int // Cache-aside Sets issued after read misses
+	Duration   time.Duration
+}
+
+// HitRatio returns the percentage of key reads (single and scan) that hit.
+func (r WorkloadResult) HitRatio() float64 {
+	total := r.Reads + r.ScanReads
+	if total == 0 {
+		return 0
+	}
+	return float64(r.ReadHits+r.ScanHits) / float64(total) * 100
+}
+
+// OpsPerSecond returns the run phase throughput.
+func (r WorkloadResult) OpsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Operations) / r.Duration.Seconds()
+}
+
+// Load runs the load phase, inserting every initial record into c.
+func (w *Workload) Load(c CacheInterface) {
+	for i, key := range w.LoadKeys() {
+		c.Set(key, i)
+	}
+}
+
+// Run executes ops operations against c and reports hit ratio and throughput.
+// Call Load first to reproduce the YCSB load + run phases.
+//
+// Reads that miss populate the cache (cache-aside), as an application fronting
+// a backing store would; without this, read-only workloads could never warm up.
+func (w *Workload) Run(c CacheInterface, ops int) WorkloadResult {
+	res := WorkloadResult{Cache: c.Name(), Operations: ops}
+	start := time.Now()
+
+	for i := 0; i < ops; i++ {
+		op := w.Next()
+		switch op.Type {
+		case OpRead:
+			res.Reads++
+			if _, ok := c.Get(op.Key); ok {
+				res.ReadHits++
+			} else {
+				res.Fills++
+				c.Set(op.Key, i)
+			}
+		case OpUpdate, OpInsert:
+			res.Writes++
+			c.Set(op.Key, i)
+		case OpReadModifyWrite:
+			res.Reads++
+			v, ok := c.Get(op.Key)
+			if ok {
+				res.ReadHits++
+			}
+			res.Writes++
+			c.Set(op.Key, v+1)
+		case OpScan:
+			w.scan(c, op, &res)
+		}
+	}
+
+	res.Duration = time.Since(start)
+	return res
+}
+
+// scan reads ScanLength consecutive existing keys starting at op.Key.
+func (w *Workload) scan(c CacheInterface, op Operation, res *WorkloadResult) {
+	first, err := strconv.ParseUint(op.Key[len("user"):], 10, 64)
+	if err != nil {
+		return
+	}
+	for i := 0; i < op.ScanLength && first+uint64(i) < w.records; i++ { // #nosec G115 -- i is non-negative
+		res.ScanReads++
+		key := KeyName(first + uint64(i)) // #nosec G115 -- i is non-negative
+		if _, ok := c.Get(key); ok {
+			res.ScanHits++
+		} else {
+			res.Fills++
+			c.Set(key, i)
+		}
+	}
+}
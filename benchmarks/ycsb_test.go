@@ -0,0 +1,231 @@
+// ycsb_test.go: tests and benchmarks for the YCSB-style workload generator
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package benchmarks
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWorkload_OperationMix(t *testing.T) {
+	w, err := NewWorkload(WorkloadConfig{
+		RecordCount:      1_000,
+		ReadProportion:   0.7,
+		UpdateProportion: 0.2,
+		ScanProportion:   0.1,
+		Seed:             42,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 100_000
+	counts := map[OpType]int{}
+	for i := 0; i < n; i++ {
+		op := w.Next()
+		counts[op.Type]++
+		if op.Type == OpScan && (op.ScanLength < 1 || op.ScanLength > DefaultMaxScanLength) {
+			t.Fatalf("scan length out of range: %d", op.ScanLength)
+		}
+	}
+
+	want := map[OpType]float64{OpRead: 0.7, OpUpdate: 0.2, OpScan: 0.1}
+	for op, p := range want {
+		got := float64(counts[op]) / n
+		if math.Abs(got-p) > 0.01 {
+			t.Errorf("%s: expected ~%.2f, got %.3f", op, p, got)
+		}
+	}
+	if counts[OpInsert] != 0 || counts[OpReadModifyWrite] != 0 {
+		t.Errorf("unexpected operations: %v", counts)
+	}
+}
+
+func TestWorkload_Reproducible(t *testing.T) {
+	cfg := WorkloadB
+	cfg.RecordCount = 500
+	cfg.Seed = 7
+
+	a, _ := NewWorkload(cfg)
+	b, _ := NewWorkload(cfg)
+	for i := 0; i < 1_000; i++ {
+		if x, y := a.Next(), b.Next(); x != y {
+			t.Fatalf("operation %d differs: %+v vs %+v", i, x, y)
+		}
+	}
+}
+
+func TestWorkload_HotspotDistribution(t *testing.T) {
+	w, err := NewWorkload(WorkloadConfig{
+		RecordCount:         1_000,
+		ReadProportion:      1,
+		Distribution:        DistHotspot,
+		HotspotDataFraction: 0.1,
+		HotspotOpnFraction:  0.9,
+		Seed:                1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 50_000
+	hot := 0
+	for i := 0; i < n; i++ {
+		if w.nextIndex() < 100 {
+			hot++
+		}
+	}
+	if got := float64(hot) / n; math.Abs(got-0.9) > 0.02 {
+		t.Errorf("expected ~90%% of operations on the hot set, got %.3f", got)
+	}
+}
+
+func TestWorkload_LatestFavorsInserts(t *testing.T) {
+	cfg := WorkloadD
+	cfg.RecordCount = 1_000
+	cfg.Seed = 3
+	w, err := NewWorkload(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10_000; i++ {
+		w.Next()
+	}
+	if w.RecordCount() <= 1_000 {
+		t.Fatal("inserts should grow the key space")
+	}
+
+	recent := 0
+	const n = 10_000
+	threshold := uint64(w.RecordCount() - 100) // #nosec G115 -- test values
+	for i := 0; i < n; i++ {
+		if w.nextIndex() >= threshold {
+			recent++
+		}
+	}
+	if recent < n/2 {
+		t.Errorf("latest distribution should favor recent keys, got %d/%d", recent, n)
+	}
+}
+
+func TestWorkload_InvalidConfig(t *testing.T) {
+	cases := map[string]WorkloadConfig{
+		"no operations":     {},
+		"negative weight":   {ReadProportion: 1, UpdateProportion: -1},
+		"zipf exponent":     {ReadProportion: 1, ZipfS: 0.5},
+		"hotspot fractions": {ReadProportion: 1, HotspotOpnFraction: 2},
+	}
+	for name, cfg := range cases {
+		if _, err := NewWorkload(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestWorkload_RunAgainstCaches(t *testing.T) {
+	caches := []CacheInterface{NewBaliosCache(mediumCacheSize), NewOtterCache(mediumCacheSize)}
+	for _, c := range caches {
+		cfg := WorkloadE
+		cfg.RecordCount = 5_000
+		cfg.Seed = 11
+		w, err := NewWorkload(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.Load(c)
+		res := w.Run(c, 2_000)
+		c.Close()
+
+		if res.Operations != 2_000 || res.ScanReads == 0 || res.Writes == 0 {
+			t.Errorf("%s: unexpected result %+v", res.Cache, res)
+		}
+		if r := res.HitRatio(); r <= 0 || r > 100 {
+			t.Errorf("%s: hit ratio out of range: %.2f", res.Cache, r)
+		}
+	}
+}
+
+// TestYCSBCoreWorkloads reports hit ratio and throughput for workloads A-F
+func TestYCSBCoreWorkloads(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping YCSB comparison in short mode")
+	}
+
+	workloads := []struct {
+		name string
+		cfg  WorkloadConfig
+	}{
+		{"A", WorkloadA}, {"B", WorkloadB}, {"C", WorkloadC},
+		{"D", WorkloadD}, {"E", WorkloadE}, {"F", WorkloadF},
+	}
+	factories := []func(int) CacheInterface{
+		func(size int) CacheInterface { return NewBaliosCache(size) },
+		func(size int) CacheInterface { return NewOtterCache(size) },
+		func(size int) CacheInterface { return NewRistrettoCache(size) },
+	}
+
+	for _, wl := range workloads {
+		t.Logf("\n=== YCSB Workload %s ===", wl.name)
+		for _, factory := range factories {
+			cfg := wl.cfg
+			cfg.RecordCount = largeKeySpace
+			cfg.Seed = 1
+			w, err := NewWorkload(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := factory(largeKeySpace / 10)
+			w.Load(c)
+			res := w.Run(c, 100_000)
+			c.Close()
+
+			t.Logf("  %s: %.2f%% hit ratio, %.0f ops/s", res.Cache, res.HitRatio(), res.OpsPerSecond())
+		}
+	}
+}
+
+func benchmarkYCSB(b *testing.B, c CacheInterface, cfg WorkloadConfig) {
+	defer c.Close()
+	cfg.RecordCount = mediumKeySpace
+	cfg.Seed = 1
+	w, err := NewWorkload(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w.Load(c)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	w.Run(c, b.N)
+}
+
+func BenchmarkBalios_YCSB_A(b *testing.B) {
+	benchmarkYCSB(b, NewBaliosCache(mediumCacheSize), WorkloadA)
+}
+
+func BenchmarkOtter_YCSB_A(b *testing.B) {
+	benchmarkYCSB(b, NewOtterCache(mediumCacheSize), WorkloadA)
+}
+
+func BenchmarkRistretto_YCSB_A(b *testing.B) {
+	benchmarkYCSB(b, NewRistrettoCache(mediumCacheSize), WorkloadA)
+}
+
+func BenchmarkBalios_YCSB_B(b *testing.B) {
+	benchmarkYCSB(b, NewBaliosCache(mediumCacheSize), WorkloadB)
+}
+
+func BenchmarkOtter_YCSB_B(b *testing.B) {
+	benchmarkYCSB(b, NewOtterCache(mediumCacheSize), WorkloadB)
+}
+
+func BenchmarkRistretto_YCSB_B(b *testing.B) {
+	benchmarkYCSB(b, NewRistrettoCache(mediumCacheSize), WorkloadB)
+}
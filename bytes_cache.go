@@ -0,0 +1,146 @@
+// bytes_cache.go: memory-bounded cache of byte-slice values
+//
+// BytesCache stores []byte values, charges each entry its length and
+// evicts while the total exceeds Config.MaxBytes.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+// BytesCache is a cache of []byte values bounded by their total size.
+//
+// Example:
+//
+//	protos := balios.NewBytesCache(balios.Config{
+//	    MaxSize:   100_000,   // Entries (sizes the table)
+//	    MaxBytes:  256 << 20, // 256 MiB of values
+//	    CopyOnGet: true,
+//	})
+//	protos.Set("user:123", payload)
+type BytesCache struct {
+	inner     *wtinyLFUCache
+	copyOnGet bool
+}
+
+// NewBytesCache creates a BytesCache. Config.MaxBytes bounds the total size
+// of the values (it replaces MaxWeight, and Weigher is ignored); MaxSize
+// still bounds the number of entries.
+func NewBytesCache(cfg Config) *BytesCache {
+	if cfg.MaxBytes > 0 {
+		cfg.MaxWeight = cfg.MaxBytes
+	}
+	cfg.Weigher = bytesWeigher
+	return &BytesCache{
+		inner:     NewCache(cfg).(*wtinyLFUCache),
+		copyOnGet: cfg.CopyOnGet,
+	}
+}
+
+// bytesWeigher charges a []byte value its length.
+func bytesWeigher(_ string, value interface{}) int64 {
+	if b, ok := value.([]byte); ok {
+		return int64(len(b))
+	}
+	return 0
+}
+
+// Set stores value for key without copying it: the caller must not modify
+// value afterwards. Returns false if the value was not stored, including
+// when it is larger than MaxBytes.
+func (c *BytesCache) Set(key string, value []byte) bool {
+	return c.inner.Set(key, value)
+}
+
+// Get returns the value of key, copied if Config.CopyOnGet is set.
+func (c *BytesCache) Get(key string) ([]byte, bool) {
+	value, found := c.inner.Get(key)
+	if !found {
+		return nil, false
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil, false
+	}
+	return c.output(b), true
+}
+
+// GetOrLoad returns the value of key, loading and caching it on a miss (see
+// Cache.GetOrLoad). The result is copied if Config.CopyOnGet is set.
+func (c *BytesCache) GetOrLoad(key string, loader func() ([]byte, error), opts ...LoadOption) ([]byte, error) {
+	if loader == nil {
+		return nil, NewErrInvalidLoader(key)
+	}
+	value, err := c.inner.GetOrLoad(key, func() (interface{}, error) {
+		b, err := loader()
+		if err != nil || b == nil {
+			return nil, err
+		}
+		return b, nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := value.([]byte)
+	return c.output(b), nil
+}
+
+// output returns b or, with Config.CopyOnGet, a copy of it.
+func (c *BytesCache) output(b []byte) []byte {
+	if !c.copyOnGet || b == nil {
+		return b
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
+
+// Delete removes key. Returns true if it was cached.
+func (c *BytesCache) Delete(key string) bool {
+	return c.inner.Delete(key)
+}
+
+// Has reports whether key is cached and not expired.
+func (c *BytesCache) Has(key string) bool {
+	return c.inner.Has(key)
+}
+
+// Bytes returns the total size of the cached values. Sizes are tracked only
+// when Config.MaxBytes is set; Bytes returns 0 otherwise.
+func (c *BytesCache) Bytes() int64 {
+	return c.inner.totalWeight()
+}
+
+// MaxBytes returns the bound on the total size of the values (0 = none).
+func (c *BytesCache) MaxBytes() int64 {
+	return c.inner.maxWeight
+}
+
+// Len returns the number of cached values.
+func (c *BytesCache) Len() int {
+	return c.inner.Len()
+}
+
+// Capacity returns the maximum number of entries.
+func (c *BytesCache) Capacity() int {
+	return c.inner.Capacity()
+}
+
+// Stats returns the cache statistics. Weight and MaxWeight are in bytes.
+func (c *BytesCache) Stats() CacheStats {
+	return c.inner.Stats()
+}
+
+// Clear removes every value and resets the statistics.
+func (c *BytesCache) Clear() {
+	c.inner.Clear()
+}
+
+// Close stops the background goroutines of the cache and releases it.
+func (c *BytesCache) Close() error {
+	return c.inner.Close()
+}
+
+// Closed reports whether Close has been called.
+func (c *BytesCache) Closed() bool {
+	return c.inner.Closed()
+}
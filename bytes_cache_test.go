@@ -0,0 +1,122 @@
+// bytes_cache_test.go: tests for the memory-bounded cache of byte-slice values
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestBytesCache_TracksBytes(t *testing.T) {
+	cache := NewBytesCache(Config{MaxSize: 100, MaxBytes: 1 << 20})
+	defer func() { _ = cache.Close() }()
+
+	cache.Set("a", make([]byte, 100))
+	cache.Set("b", make([]byte, 50))
+	if got := cache.Bytes(); got != 150 {
+		t.Errorf("Bytes = %d, want 150", got)
+	}
+
+	cache.Set("a", make([]byte, 10)) // Replacement releases the old size
+	if got := cache.Bytes(); got != 60 {
+		t.Errorf("Bytes after replace = %d, want 60", got)
+	}
+	cache.Delete("b")
+	if got := cache.Bytes(); got != 10 {
+		t.Errorf("Bytes after delete = %d, want 10", got)
+	}
+	if stats := cache.Stats(); stats.Weight != 10 || stats.MaxWeight != 1<<20 || cache.MaxBytes() != 1<<20 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	cache.Clear()
+	if got := cache.Bytes(); got != 0 {
+		t.Errorf("Bytes after Clear = %d, want 0", got)
+	}
+}
+
+func TestBytesCache_EnforcesMaxBytes(t *testing.T) {
+	cache := NewBytesCache(Config{MaxSize: 1000, MaxBytes: 10_000})
+	defer func() { _ = cache.Close() }()
+
+	for i := 0; i < 500; i++ {
+		cache.Set("k"+strconv.Itoa(i), make([]byte, 100))
+		if got := cache.Bytes(); got > 10_000 {
+			t.Fatalf("total %d exceeds MaxBytes after %d writes", got, i+1)
+		}
+	}
+	if n := cache.Len(); n > 100 || n < 50 {
+		t.Errorf("expected about 100 values of 100 bytes, got %d", n)
+	}
+	if stats := cache.Stats(); stats.Evictions == 0 {
+		t.Error("expected evictions once MaxBytes is reached")
+	}
+
+	if cache.Set("huge", make([]byte, 10_001)) {
+		t.Error("a value larger than MaxBytes must not be cached")
+	}
+}
+
+func TestBytesCache_CopyOnGet(t *testing.T) {
+	shared := NewBytesCache(Config{MaxSize: 10})
+	copying := NewBytesCache(Config{MaxSize: 10, CopyOnGet: true})
+	defer func() { _ = shared.Close() }()
+	defer func() { _ = copying.Close() }()
+
+	for _, cache := range []*BytesCache{shared, copying} {
+		cache.Set("k", []byte("value"))
+		got, ok := cache.Get("k")
+		if !ok || string(got) != "value" {
+			t.Fatalf("Get = %q, %v", got, ok)
+		}
+		got[0] = 'X'
+	}
+
+	if got, _ := copying.Get("k"); string(got) != "value" {
+		t.Errorf("with CopyOnGet, mutating a result must not change the cache, got %q", got)
+	}
+	if got, _ := shared.Get("k"); string(got) != "Xalue" {
+		t.Errorf("without CopyOnGet, Get returns the cached slice, got %q", got)
+	}
+}
+
+func TestBytesCache_GetOrLoad(t *testing.T) {
+	cache := NewBytesCache(Config{MaxSize: 10, MaxBytes: 100, CopyOnGet: true})
+	defer func() { _ = cache.Close() }()
+
+	loaded := []byte("payload")
+	got, err := cache.GetOrLoad("k", func() ([]byte, error) { return loaded, nil })
+	if err != nil || !bytes.Equal(got, loaded) {
+		t.Fatalf("GetOrLoad = %q, %v", got, err)
+	}
+	if &got[0] == &loaded[0] {
+		t.Error("CopyOnGet should apply to loaded values")
+	}
+	if cache.Bytes() != int64(len(loaded)) {
+		t.Errorf("loaded value should be charged, Bytes = %d", cache.Bytes())
+	}
+
+	loadErr := errors.New("backend down")
+	if _, err := cache.GetOrLoad("missing", func() ([]byte, error) { return nil, loadErr }); !errors.Is(err, loadErr) {
+		t.Errorf("expected the loader error, got %v", err)
+	}
+	if _, err := cache.GetOrLoad("k", nil); err == nil {
+		t.Error("expected BALIOS_INVALID_LOADER for a nil loader")
+	}
+}
+
+func TestBytesCache_CountBoundWithoutMaxBytes(t *testing.T) {
+	cache := NewBytesCache(Config{MaxSize: 10})
+	defer func() { _ = cache.Close() }()
+
+	cache.Set("k", make([]byte, 1<<20))
+	if !cache.Has("k") || cache.MaxBytes() != 0 {
+		t.Error("without MaxBytes, values are bounded by count only")
+	}
+}
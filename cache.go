@@ -7,6 +7,7 @@
 package balios
 
 import (
+	"context"
 	"runtime"
 	"strings"
 	"sync"
@@ -46,7 +47,7 @@ const maxProbeLength = uint32(128)
 // and allows the cache to handle arbitrary type changes safely.
 // Old valueHolders are garbage collected when no longer referenced.
 type valueHolder struct {
-	data atomic.Value // Stores the actual cache value (any type)
+	data atomic.Value // Stores the actual cache value (any type), or a *taggedValue (see newValueHolder)
 }
 
 type entry struct {
@@ -61,25 +62,64 @@ type entry struct {
 	value   atomic.Value   // Thread-safe value storage (always contains *valueHolder)
 
 	// 32-bit fields (can be placed last)
-	valid int32 // atomic flag: 0=empty, 1=valid, 2=deleted, 3=pending
+	valid  int32 // atomic flag: 0=empty, 1=valid, 2=deleted, 3=pending
+	weight int32 // Weight charged to the cache (see weight.go); fits the padding after valid
 }
 
 // wtinyLFUCache implements W-TinyLFU cache with lock-free operations.
 // Uses simple atomic operations on fixed arrays for maximum performance.
 type wtinyLFUCache struct {
 	// Configuration (immutable after creation)
-	maxSize          int32
-	tableMask        uint32
-	ttlNanos         int64            // TTL in nanoseconds (0 = no expiration)
-	negativeTTLNanos int64            // Negative cache TTL in nanoseconds (0 = disabled)
-	timeProvider     TimeProvider     // Provides current time
-	metricsCollector MetricsCollector // Collects operation metrics (nil-safe)
-
-	// Fixed-size array of entries for lock-free access
-	entries []entry
-
-	// W-TinyLFU frequency sketch (already lock-free)
-	sketch *frequencySketch
+	maxSize          int32                                  // Current capacity (atomic, see Resize in resize.go)
+	ttlNanos         int64                                  // TTL in nanoseconds (0 = no expiration)
+	ttlJitter        float64                                // Random TTL spread per write (0 = none, see ttl_jitter.go)
+	ttiNanos         int64                                  // Idle period before expiration (0 = none, see tti.go)
+	ttiGranularity   int64                                  // Smallest idle deadline extension written by a Get hit
+	maxTTLNanos      int64                                  // Largest TTL in use, default or per-entry (atomic; 0 = nothing expires)
+	ttlBoundNanos    int64                                  // Longest default TTL (jittered TTL or TTI), the clock regression bound of untagged entries
+	negativeTTLNanos int64                                  // Negative cache TTL in nanoseconds (0 = disabled)
+	secondary        SecondaryCache                         // Second-level store (nil = none, see secondary.go)
+	secondaryTimeout time.Duration                          // Bound of every secondary call (0 = none)
+	propagate        bool                                   // Writes go to a SecondaryCache, an InvalidationBus or a write-behind Store
+	valueCodec       ValueCodec                             // Encodes stored values (nil = stored as is)
+	timeProvider     TimeProvider                           // Provides current time
+	metricsCollector MetricsCollector                       // Collects operation metrics (nil-safe)
+	contextMetrics   contextMetricsCollector                // metricsCollector accepting Get contexts, nil if not (see metrics_v2.go)
+	probeMetrics     ProbeMetricsCollector                  // Has/Len/Stats metrics (nil = not collected)
+	measureLatency   bool                                   // false = report latency -1 and skip the closing Now() call
+	readHooks        bool                                   // Gets are recorded beyond the hit/miss counters (see recordGet)
+	trackMissReasons bool                                   // Departures are recorded (Config.TrackMissReasons, see miss_reason.go)
+	minLoadCostNanos int64                                  // Loaded values cheaper than this are not cached (0 = admit all)
+	loadLimiter      *loadLimiter                           // Loader call rate limit per key family (nil = disabled)
+	breaker          *loadBreaker                           // Loader circuit breaker (nil = disabled)
+	groups           sync.Map                               // GetOrLoadGrouped group key -> *loadGroup
+	groupWindow      time.Duration                          // Config.GroupedLoadWindow
+	groupMaxBatch    int                                    // Config.GroupedLoadMaxBatch
+	valueEqual       func(a, b interface{}) bool            // CompareAndSwap equality (Config.ValueEqual or ==)
+	onEntryEvent     func(EntryEvent)                       // Eviction/expiration hook (nil = disabled)
+	onEvict          func(string, interface{}, EvictReason) // Removal listener (nil = disabled)
+	onExpire         func(string, interface{})              // Legacy expiration listener (nil = disabled)
+	logger           Logger                                 // Reports panics of user hooks
+	anomalies        *anomalyLog                            // Rate-limited anomaly events (nil without a Logger, see anomaly_log.go)
+	keyReadRetries   int                                    // SeqLock attempts per key read in Get (see read_contention.go)
+	keyHasher        func(key string) uint64                // Table and sketch hash of a key (nil = stringHash, see keyhasher.go)
+	codec            Codec                                  // Value encoding of snapshots (see persistence.go)
+
+	// Fixed-size array of entries for lock-free access and its per-slot
+	// state (nil once closed, see table.go)
+	table atomic.Pointer[cacheTable]
+
+	// Custom components (nil = built-in sketch and least-frequent eviction)
+	estimator      FrequencyEstimator
+	evictionPolicy EvictionPolicy
+	admission      AdmissionPolicy // nil = every new key is admitted (see admission.go)
+
+	// Bounded log of eviction decisions (nil unless Config.EvictionAuditSize > 0)
+	evictionAudit *evictionAudit
+
+	// Weight-based capacity (see weight.go; maxWeight 0 = disabled)
+	weigher   func(key string, value interface{}) int64
+	maxWeight int64
 
 	// Fast random number generator state for eviction sampling (xorshift64)
 	// Uses atomic operations for thread-safety without locks
@@ -93,9 +133,93 @@ type wtinyLFUCache struct {
 	// Key: "neg:" + key, Value: negativeEntry
 	negativeCache sync.Map
 
+	// Dependency index for SetWithDependencies / InvalidateKey
+	dependencies *dependencyIndex
+
+	// Namespace index for ClearNamespace (nil unless Config.IndexNamespaces)
+	namespaces *namespaceIndex
+
+	// Per-entry size records for MemoryUsage (nil unless Config.MemoryAccounting)
+	memory *memoryAccountant
+
+	// Hit and miss counters per key family (nil unless Config.FamilyStats)
+	families *familyStats
+
+	// Replaced values per key for GetPrevious (nil unless Config.ValueHistory)
+	history *valueHistory
+
 	// Stop channel for background cleanup goroutines
 	stopCleanup chan struct{}
 
+	// Highest TTL clock reading observed so far (see ttlClock in clock.go)
+	clockHighWater int64
+
+	// Frozen lookup index for read-mostly workloads (nil = probe only)
+	frozen      atomic.Pointer[frozenIndex]
+	stopIndexer chan struct{} // nil unless Config.FrozenIndexInterval > 0
+	closeOnce   sync.Once
+	closed      int32          // atomic: 1 after Close (see close.go)
+	background  sync.WaitGroup // Background goroutines joined by Close
+
+	// Background shrinker (see shrink.go)
+	stopShrinker        chan struct{} // nil unless Config.ShrinkAfter > 0
+	shrinkPasses        int64
+	shrinkReleasedSlots int64
+
+	// Background expiration (see janitor.go)
+	stopJanitor   chan struct{}           // nil unless Config.CleanupInterval > 0
+	sweepRecorder ExpirationSweepRecorder // nil unless the collector implements it
+
+	// Memory-pressure controller (see memory_pressure.go)
+	stopPressure     chan struct{}          // nil unless Config.MemoryPressureThreshold > 0
+	pressureRecorder MemoryPressureRecorder // nil unless the collector implements it
+	memoryUsage      func() (used, limit uint64)
+	pressureEvicted  int64
+
+	// Shared key storage (nil unless Config.KeySlabSize > 0, see key_slabs.go)
+	keySlabs *keySlabPool
+
+	// Pointer-free value storage (see value_arena.go)
+	arena     *valueArena   // nil unless Config.ValueArena with a ValueCodec
+	arenaMu   sync.Mutex    // Serializes arena compactions
+	stopArena chan struct{} // nil unless arena is set
+
+	resizeMu sync.Mutex // Serializes Resize (see resize.go)
+
+	// Loader retries (see load_retry.go)
+	retryRecorder LoadRetryRecorder // nil unless the collector implements it
+
+	// Loader middleware (see loader_middleware.go)
+	loaderMiddleware func(Loader) Loader // nil unless Config.LoaderMiddleware is set
+
+	// Scheduled frequency decay (see sketch_decay.go)
+	stopDecay    chan struct{} // nil unless Config.SketchDecayInterval > 0
+	sketchDecays int64
+
+	// Rolling-window statistics (see stats_window.go)
+	window        *statsWindow      // nil unless Config.StatsWindow > 0
+	hitRatioAlert *hitRatioWatchdog // nil unless Config.HitRatioAlert is set (see hit_ratio_alert.go)
+
+	// Distributed invalidation (see invalidation.go)
+	invalidationBus          InvalidationBus // nil unless Config.InvalidationBus is set
+	instanceID               string          // Origin of the published events
+	unsubscribeInvalidations func()          // nil unless subscribed
+	invalidationsReceived    int64
+
+	// Loaded values rejected by cost-aware admission (see load_cost.go)
+	loadsNotAdmitted int64
+
+	// Loader calls rejected by Config.LoadRateLimit (see ratelimit.go)
+	loadsRateLimited int64
+
+	// Set to 1 once OnEntryEvent has panicked (hook disabled)
+	entryEventDisabled int32
+
+	// Stats snapshot SeqLock: odd while Clear is resetting counters.
+	// Stats() retries until it reads all counters within one stable epoch.
+	statsEpoch uint64
+	clearMu    sync.Mutex // serializes Clear so epochs never interleave
+
 	// Atomic statistics counters
 	hits        int64
 	misses      int64
@@ -103,7 +227,76 @@ type wtinyLFUCache struct {
 	deletes     int64
 	evictions   int64
 	expirations int64
-	size        int64
+
+	// Duplicate-key cleanup counters (see duplicate_stats.go)
+	duplicateCleanups    int64
+	duplicatesByDistance [duplicateDistanceBuckets]int64
+
+	// Lost slot CAS of writers (see duplicate_stats.go)
+	raceConditions int64
+	raceRecorder   RaceConditionRecorder // nil unless the collector implements it
+
+	// Key reads abandoned after keyReadRetries attempts (see read_contention.go)
+	readContentions int64
+
+	// Get misses by reason (see miss_reason.go)
+	missesExpired  int64
+	missesEvicted  int64
+	missesRejected int64
+
+	// Negative cache lookups (see negative_cache.go)
+	negativeHits     int64
+	negativeMisses   int64
+	negativeRecorder NegativeCacheRecorder // nil unless the collector implements it
+
+	// Writes rejected by Config.MaxKeyLen or MaxValueBytes (see size_limits.go)
+	maxKeyLen        int
+	maxValueBytes    int64
+	rejectedTooLarge int64
+
+	// Subscribers of lifecycle events (see event_stream.go)
+	events eventHub
+
+	// Records of deleted keys (see tombstone.go)
+	tombstoneTTLNanos    int64 // 0 = disabled
+	tombstoneJitterNanos int64
+	tombstoneMode        TombstoneMode
+	tombstones           sync.Map // key -> *tombstone
+	tombstoneHits        int64
+	stopTombstones       chan struct{}
+
+	// Caller-held per-key locks (see lock_key.go)
+	keyLocks keyLocks
+
+	// Write versions (see versions.go)
+	versioned    bool
+	writeVersion atomic.Uint64
+
+	// Get and Set latency histograms, nil unless Config.TrackLatency (see latency.go)
+	latency *latencyHistograms
+
+	// Clock of latency measurements when it is not timeProvider (see latency.go)
+	latencyClock TimeProvider
+
+	// Space-saving lookup counters (nil unless Config.TopKeysCapacity, see top_keys.go)
+	topKeys *topKeysTracker
+
+	// Writes queued for the backing Store (nil unless Config.WriteBehindStore, see write_behind.go)
+	writeBehind         *writeBehind
+	writeBehindRecorder WriteBehindRecorder // nil unless the collector implements it
+
+	// Loads rejected by an open circuit (see circuit_breaker.go)
+	circuitRejections int64
+	circuitRecorder   CircuitBreakerRecorder // nil unless the collector implements it
+
+	// Sampled keys (see key_samples.go)
+	keySampler     KeySampleRecorder // nil unless the collector implements it
+	keySampleEvery uint64            // 0 = no sampling
+
+	// Probe-length statistics (see probe_stats.go)
+	trackProbes   bool               // Config.TrackProbeLengths or probeRecorder set
+	probes        probeStats         // Zero unless trackProbes
+	probeRecorder ProbeCountRecorder // nil unless the collector implements it
 }
 
 // negativeEntry represents a cached error from GetOrLoad
@@ -145,10 +338,15 @@ type stringHeader struct {
 
 // Helper functions for atomic key operations - ZERO ALLOCATION with SeqLock
 func (e *entry) loadKey() string {
+	key, _ := e.loadKeyRetries(DefaultKeyReadRetries)
+	return key
+}
+
+// loadKeyRetries reads the key with at most maxRetries SeqLock attempts.
+// ok is false if every attempt raced with a writer (the key is then "").
+func (e *entry) loadKeyRetries(maxRetries int) (key string, ok bool) {
 	// SeqLock read pattern: retry if version is odd (writer active) or changes during read
 	// This prevents torn reads where dataPtr and length don't match
-	const maxRetries = 100 // Prevent infinite loop in extreme contention
-
 	for retry := 0; retry < maxRetries; retry++ {
 		// 1. Load version BEFORE reading data (acquire semantics)
 		v1 := atomic.LoadUint64(&e.version)
@@ -168,23 +366,44 @@ func (e *entry) loadKey() string {
 		// 5. If version unchanged and even, read was consistent
 		if v1 == v2 {
 			if dataPtr == nil || length == 0 {
-				return ""
+				return "", true
 			}
 
 			// Reconstruct string from data pointer and length
 			// This is zero-allocation as we're just creating a string header
 			// #nosec G103 -- unsafe required for zero-allocation string reconstruction
-			return unsafe.String((*byte)(dataPtr), int(length))
+			return unsafe.String((*byte)(dataPtr), int(length)), true
 		}
 
 		// Version changed during read - retry
 	}
 
-	// Fallback after max retries (should be extremely rare)
-	return ""
+	// Fallback after max retries (should be extremely rare, see read_contention.go)
+	return "", false
 }
 
 func (e *entry) storeKey(key string) {
+	if key != "" {
+		// SAFE KEY STORAGE: Clone the string to guarantee independent lifetime
+		// strings.Clone() allocates a new backing array, ensuring the key survives
+		// even if the caller's original string is garbage collected.
+		//
+		// PERFORMANCE: Single allocation per unique key (amortized across cache hits).
+		// Benchmarks show negligible overhead (~5-10ns) vs unsafe pointer approach,
+		// but provides guaranteed memory safety without relying on escape analysis.
+		//
+		// RATIONALE: The unsafe approach (storing hdr.data pointer) works in practice
+		// because Go's escape analysis forces heap allocation, but this relies on
+		// compiler implementation details. strings.Clone() makes safety explicit.
+		// Config.KeySlabSize packs the copies instead (see key_slabs.go).
+		key = strings.Clone(key)
+	}
+	e.publishKey(key)
+}
+
+// publishKey stores keyCopy, whose bytes the cache owns and never modifies,
+// as the key of the entry.
+func (e *entry) publishKey(keyCopy string) {
 	// SeqLock write pattern: increment version to odd, write data, increment to even
 	// This signals readers that a write is in progress
 
@@ -197,24 +416,11 @@ func (e *entry) storeKey(key string) {
 	}
 
 	// 2. Now we can safely write data (readers will see odd version and retry)
-	if key == "" {
+	if keyCopy == "" {
 		atomic.StorePointer(&e.keyData, nil)
 		atomic.StoreInt64(&e.keyLen, 0)
 	} else {
-		// SAFE KEY STORAGE: Clone the string to guarantee independent lifetime
-		// strings.Clone() allocates a new backing array, ensuring the key survives
-		// even if the caller's original string is garbage collected.
-		//
-		// PERFORMANCE: Single allocation per unique key (amortized across cache hits).
-		// Benchmarks show negligible overhead (~5-10ns) vs unsafe pointer approach,
-		// but provides guaranteed memory safety without relying on escape analysis.
-		//
-		// RATIONALE: The unsafe approach (storing hdr.data pointer) works in practice
-		// because Go's escape analysis forces heap allocation, but this relies on
-		// compiler implementation details. strings.Clone() makes safety explicit.
-		keyCopy := strings.Clone(key)
-
-		// Get string header from the cloned string
+		// Get string header from the owned copy
 		// #nosec G103 -- unsafe required for zero-allocation string reconstruction
 		hdr := (*stringHeader)(unsafe.Pointer(&keyCopy))
 
@@ -233,23 +439,153 @@ func NewCache(config Config) Cache {
 	// This ensures consistent validation logic and eliminates duplication
 	_ = config.Validate() // Error is always nil (only sets defaults)
 
-	// Hash table size: power of 2, at least 2x maxSize for good load factor
-	tableSize := nextPowerOf2(config.MaxSize * 2)
-	if tableSize < 16 {
-		tableSize = 16
-	}
-
 	cache := &wtinyLFUCache{
 		maxSize:          int32(config.MaxSize), // #nosec G115 - MaxSize is validated and bounded
-		tableMask:        uint32(tableSize - 1), // #nosec G115 - tableSize is power of 2, safe conversion
 		ttlNanos:         int64(config.TTL),
+		ttlJitter:        config.TTLJitter,
+		maxTTLNanos:      max(int64(config.TTL), int64(config.TTI)),
+		ttlBoundNanos:    max(maxJitteredTTL(int64(config.TTL), config.TTLJitter), int64(config.TTI)),
+		ttiNanos:         int64(config.TTI),
+		ttiGranularity:   int64(config.TTI) / ttiGranularityDivisor,
 		negativeTTLNanos: int64(config.NegativeCacheTTL),
+		secondary:        config.SecondaryCache,
+		secondaryTimeout: config.SecondaryTimeout,
+		propagate:        config.SecondaryCache != nil || config.InvalidationBus != nil || config.WriteBehindStore != nil,
+		valueCodec:       config.ValueCodec,
 		timeProvider:     config.TimeProvider,
-		metricsCollector: config.MetricsCollector,
-		entries:          make([]entry, tableSize),
-		sketch:           newFrequencySketch(config.MaxSize),
-		rngState:         uint64(config.TimeProvider.Now()), // #nosec G115 -- time value always positive, no overflow risk
-		stopCleanup:      make(chan struct{}),               // Channel for stopping background cleanup
+		metricsCollector: activeMetricsCollector(config.MetricsCollector, config.Logger),
+		measureLatency:   !config.DisableMetricsLatency,
+		trackMissReasons: config.TrackMissReasons,
+		minLoadCostNanos: int64(config.MinLoadCost),
+		loadLimiter:      newLoadLimiter(config),
+		breaker:          newLoadBreaker(config),
+		groupWindow:      config.GroupedLoadWindow,
+		groupMaxBatch:    config.GroupedLoadMaxBatch,
+		valueEqual:       config.ValueEqual,
+		onEntryEvent:     config.OnEntryEvent,
+		onEvict:          config.OnEvict,
+		onExpire:         config.OnExpire,
+		keyReadRetries:   config.KeyReadRetries,
+		keyHasher:        config.KeyHasher,
+		codec:            config.Codec,
+		logger:           config.Logger,
+		anomalies:        newAnomalyLog(config),
+		estimator:        config.FrequencyEstimator,
+		evictionPolicy:   config.EvictionPolicy,
+		admission:        config.AdmissionPolicy,
+		evictionAudit:    newEvictionAudit(config.EvictionAuditSize),
+		weigher:          config.Weigher,
+		maxWeight:        config.MaxWeight,
+		dependencies:     newDependencyIndex(config.MaxDependencyEdges),
+		memory:           newMemoryAccountant(config),
+		families:         newFamilyStats(config),
+		history:          newValueHistory(config),
+		keySlabs:         newKeySlabPool(config.KeySlabSize),
+		rngState:         rngSeed(config),
+		stopCleanup:      make(chan struct{}), // Channel for stopping background cleanup
+	}
+
+	if cache.valueEqual == nil {
+		cache.valueEqual = defaultValueEqual
+	}
+	cache.table.Store(newCacheTable(config))
+	cache.events.size = config.EventBufferSize
+	cache.maxKeyLen = config.MaxKeyLen
+	cache.maxValueBytes = config.MaxValueBytes
+	cache.topKeys = newTopKeysTracker(config.TopKeysCapacity)
+	if config.AdaptiveWindow && cache.admission == nil {
+		cache.admission = TinyLFUAdmission{}
+	}
+	cache.probeMetrics = probeMetricsOf(cache.metricsCollector)
+	cache.contextMetrics = contextMetricsOf(cache.metricsCollector)
+	cache.sweepRecorder = sweepRecorderOf(cache.metricsCollector)
+	cache.pressureRecorder = pressureRecorderOf(cache.metricsCollector)
+	cache.retryRecorder = retryRecorderOf(cache.metricsCollector)
+	cache.loaderMiddleware = composeLoaderMiddleware(config.LoaderMiddleware)
+	cache.negativeRecorder = negativeRecorderOf(cache.metricsCollector)
+	cache.probeRecorder = probeRecorderOf(cache.metricsCollector)
+	cache.raceRecorder = raceRecorderOf(cache.metricsCollector)
+	cache.circuitRecorder = circuitRecorderOf(cache.metricsCollector)
+	cache.writeBehindRecorder = writeBehindRecorderOf(cache.metricsCollector)
+	cache.keySampler, cache.keySampleEvery = keySamplerOf(cache.metricsCollector)
+	cache.trackProbes = config.TrackProbeLengths || cache.probeRecorder != nil
+
+	if config.IndexNamespaces {
+		cache.namespaces = newNamespaceIndex(config.MaxSize)
+	}
+
+	if config.FrozenIndexInterval > 0 {
+		cache.stopIndexer = make(chan struct{})
+		cache.startBackground(func() { cache.runFrozenIndexer(config.FrozenIndexInterval) })
+	}
+
+	if config.ShrinkAfter > 0 {
+		cache.stopShrinker = make(chan struct{})
+		cache.startBackground(func() { cache.runShrinker(config.ShrinkAfter, config.ShrinkThreshold) })
+	}
+
+	if config.CleanupInterval > 0 {
+		cache.stopJanitor = make(chan struct{})
+		cache.startBackground(func() { cache.runJanitor(config.CleanupInterval) })
+	}
+
+	if config.MemoryPressureThreshold > 0 {
+		cache.memoryUsage = runtimeMemoryUsage(config.MemoryLimit)
+		cache.stopPressure = make(chan struct{})
+		cache.startBackground(func() {
+			cache.runMemoryPressure(config.MemoryPressureInterval, config.MemoryPressureThreshold)
+		})
+	}
+
+	if cache.arena = newValueArena(config); cache.arena != nil {
+		cache.stopArena = make(chan struct{})
+		cache.startBackground(cache.runArenaCompactor)
+	}
+
+	if config.SketchDecayInterval > 0 {
+		cache.stopDecay = make(chan struct{})
+		cache.startBackground(func() { cache.runSketchDecay(config.SketchDecayInterval) })
+	}
+
+	if config.StatsWindow > 0 {
+		cache.window = newStatsWindow()
+		cache.resetWindow()
+		if config.HitRatioAlert.enabled() {
+			cache.hitRatioAlert = &hitRatioWatchdog{alert: config.HitRatioAlert, cache: config.Name}
+		}
+		cache.startBackground(func() { cache.runStatsWindow(config.StatsWindow / statsWindowBuckets) })
+	}
+
+	if config.TrackLatency {
+		cache.latency = &latencyHistograms{}
+		if _, cached := config.TimeProvider.(*systemTimeProvider); cached {
+			cache.latencyClock = NewMonotonicTimeProvider() // Cached time is too coarse for latencies (see latency.go)
+		}
+	}
+
+	if config.EntryVersions {
+		cache.versioned = true
+		cache.writeVersion.Store(uint64(time.Now().UnixNano())) // #nosec G115 -- wall clock is positive
+	}
+
+	if config.TombstoneTTL > 0 {
+		cache.tombstoneTTLNanos = int64(config.TombstoneTTL)
+		cache.tombstoneJitterNanos = int64(config.TombstoneJitter)
+		cache.tombstoneMode = config.TombstoneMode
+		cache.stopTombstones = make(chan struct{})
+		cache.startBackground(cache.runTombstoneSweeper)
+	}
+
+	if cache.writeBehind = newWriteBehind(config); cache.writeBehind != nil {
+		for i := 0; i < config.WriteBehindWorkers; i++ {
+			cache.startBackground(cache.runWriteBehind)
+		}
+	}
+
+	if config.InvalidationBus != nil {
+		cache.invalidationBus = config.InvalidationBus
+		cache.instanceID = newInstanceID()
+		cache.subscribeInvalidations()
 	}
 
 	// Start negative cache cleanup goroutine if negative caching is enabled
@@ -258,6 +594,9 @@ func NewCache(config Config) Cache {
 		go cache.cleanupNegativeCache()
 	}
 
+	cache.readHooks = cache.families != nil || cache.topKeys != nil || cache.keySampleEvery > 0 ||
+		cache.latency != nil || cache.metricsCollector != nil
+
 	return cache
 }
 
@@ -266,16 +605,56 @@ func NewCache(config Config) Cache {
 // This helper ensures DRY principle and consistent expiration logic.
 //
 // Performance: ~2ns (single atomic load + comparison)
-// Zero overhead when TTL is disabled (c.ttlNanos == 0).
+// Zero overhead when TTL is disabled (no default or per-entry TTL in use).
 func (c *wtinyLFUCache) isExpired(entry *entry, now int64) bool {
 	// Fast path: if TTL is disabled, nothing can expire
-	if c.ttlNanos == 0 {
+	maxTTL := atomic.LoadInt64(&c.maxTTLNanos)
+	if maxTTL == 0 {
 		return false
 	}
 
 	// Check if entry has expiration set and if it's past the deadline
 	expireAt := atomic.LoadInt64(&entry.expireAt)
-	return expireAt > 0 && now > expireAt
+	if expireAt <= 0 {
+		return false
+	}
+	if now > expireAt {
+		return true
+	}
+
+	// CLOCK REGRESSION GUARD: a deadline more than the entry's TTL in the future
+	// can only come from a write that observed a later time than now (clock moved
+	// backwards). Clamp it so the entry cannot outlive its TTL by the size of the jump.
+	if expireAt-now > c.ttlBoundNanos {
+		if bound := c.ttlBound(entry); expireAt-now > bound {
+			atomic.CompareAndSwapInt64(&entry.expireAt, expireAt, now+bound)
+		}
+	}
+	return false
+}
+
+// latencySince returns the operation latency reported to the MetricsCollector.
+// When latency measurement is disabled it returns -1 without reading the clock,
+// saving the second TimeProvider.Now() call of every recorded operation.
+func (c *wtinyLFUCache) latencySince(start int64) int64 {
+	if !c.measureLatency {
+		return -1
+	}
+	return c.latencyNow() - start
+}
+
+// rngSeed returns the initial state of fastRand: Config.RandSeed, or the
+// construction time. xorshift64 never leaves a zero state, so zero (a fake
+// clock at its epoch) is replaced by a fixed odd constant.
+func rngSeed(config Config) uint64 {
+	seed := config.RandSeed
+	if seed == 0 {
+		seed = uint64(config.TimeProvider.Now()) // #nosec G115 -- time value always positive, no overflow risk
+	}
+	if seed == 0 {
+		seed = 0x9e3779b97f4a7c15
+	}
+	return seed
 }
 
 // fastRand generates a pseudo-random uint64 using xorshift64 algorithm.
@@ -299,12 +678,20 @@ func (c *wtinyLFUCache) fastRand() uint64 {
 // populateEntry atomically populates an entry that has been claimed (state = entryPending).
 // The caller MUST have successfully CAS'd the entry to entryPending before calling this.
 // This helper eliminates code duplication in Set() method.
-func (c *wtinyLFUCache) populateEntry(entry *entry, key string, keyHash uint64, value interface{}, expireAt int64, oldState int32) {
+// longTTL is the TTL to tag the value with (see longTTL in clock.go).
+// Returns the write version of the entry (see versions.go).
+func (c *wtinyLFUCache) populateEntry(t *cacheTable, entry *entry, key string, keyHash uint64, value interface{}, source string, expireAt, longTTL int64, weight int32, oldState int32) uint64 {
+	g := t.gen.Load()
+
 	// These writes are safe because caller owns the slot (valid = entryPending)
 	// and no other goroutine will read it until we set valid = entryValid
 
 	atomic.StoreUint64(&entry.keyHash, keyHash)
-	entry.storeKey(key)
+	if c.keySlabs != nil {
+		entry.publishKey(c.keySlabs.clone(key, c.capacity()))
+	} else {
+		entry.storeKey(key)
+	}
 
 	// CRITICAL: Use valueHolder wrapper to avoid atomic.Value reset race
 	//
@@ -315,86 +702,166 @@ func (c *wtinyLFUCache) populateEntry(entry *entry, key string, keyHash uint64,
 	// 3. Maintain thread-safety without additional synchronization
 	//
 	// OPTIMIZATION: valueHolder.data is atomic.Value, allowing zero-alloc updates.
-	holder := &valueHolder{}
-	holder.data.Store(value)
-	entry.value.Store(holder)
+	version := c.nextVersion()
+	entry.value.Store(newValueHolder(value, source, version, longTTL))
 
-	atomic.StoreInt64(&entry.expireAt, expireAt)
+	c.storeExpireAt(t, entry, expireAt)
+	if oldState != g.live {
+		// A freed slot carries no weight, or that of a cleared generation
+		atomic.StoreInt32(&entry.weight, 0)
+	}
+	if c.maxWeight > 0 {
+		c.chargeWeight(g, entry, weight)
+	}
+	c.recordWrite(t, entry, true)
 
 	// Mark entry as valid - this acts as a memory barrier
 	// ensuring all previous writes are visible
-	atomic.StoreInt32(&entry.valid, entryValid)
+	atomic.StoreInt32(&entry.valid, g.live)
 
-	// Increment size for empty or deleted slots (new or reused)
-	if oldState == entryEmpty || oldState == entryDeleted {
-		atomic.AddInt64(&c.size, 1)
+	// Increment size for free slots (new, reused or left by a Clear)
+	if oldState != g.live {
+		atomic.AddInt64(&g.size, 1)
 	}
 	atomic.AddInt64(&c.sets, 1)
+	return version
 }
 
 // Set stores a key-value pair using lock-free operations.
 func (c *wtinyLFUCache) Set(key string, value interface{}) bool {
+	if !c.set(key, value, "", c.ttlNanos) {
+		return false
+	}
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return true
+}
+
+// set implements Set, SetWithSource and per-entry TTL writes.
+// ttlNanos is the entry's TTL (0 = no expiration).
+func (c *wtinyLFUCache) set(key string, value interface{}, source string, ttlNanos int64) bool {
+	if c.valueCodec != nil {
+		var ok bool
+		if value, ok = c.encodeValue(key, value); !ok {
+			return false
+		}
+	}
+	return c.setStored(key, value, source, ttlNanos)
+}
+
+// setStored implements set for a value already encoded by Config.ValueCodec.
+func (c *wtinyLFUCache) setStored(key string, value interface{}, source string, ttlNanos int64) bool {
+	if !c.setEntry(key, value, source, ttlNanos) {
+		return false
+	}
+	if c.maxWeight > 0 {
+		if t := c.writeTable(0); t != nil {
+			c.enforceMaxWeight(t)
+			t.leave(0)
+		}
+	}
+	if c.namespaces != nil {
+		c.indexNamespace(key)
+	}
+	if c.memory != nil {
+		c.recordMemory(key, value)
+	}
+	return true
+}
+
+// setEntry stores the entry in the table (see set).
+func (c *wtinyLFUCache) setEntry(key string, value interface{}, source string, ttlNanos int64) bool {
 	// Validate key is not empty
-	if key == "" {
+	if key == "" || c.isClosed() {
 		return false
 	}
 
 	// Get current time once at the start for both TTL and metrics (ensures consistency)
 	// Using go-timecache, this is ~0.4ns and provides consistent timestamp across operation
 	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now) // Skew-protected time for expiration decisions
+
+	// Check the size limits and weigh the value once, before claiming a slot
+	// (see size_limits.go and weight.go)
+	if c.sizeLimited() && c.checkSize(key, value) != nil {
+		return false
+	}
+	if c.tombstoneTTLNanos > 0 {
+		c.dropTombstone(key) // The new value supersedes the deleted one
+	}
+	var weight int32
+	if c.maxWeight > 0 {
+		var ok bool
+		if weight, ok = c.weigh(key, value); !ok {
+			return false
+		}
+	}
 
-	keyHash := stringHash(key)
+	keyHash := c.hashKey(key)
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return false // Closed
+	}
+	defer t.leave(keyHash)
+	g := t.gen.Load()
 
 	// Update frequency sketch (lock-free)
-	c.sketch.increment(keyHash)
+	c.incrementFrequencyIn(t, keyHash)
 
 	// Calculate expiration time if TTL is set
-	var expireAt int64
-	if c.ttlNanos > 0 && now > 0 {
+	var expireAt, longTTL int64
+	if ttlNanos > 0 && ttlNow > 0 {
+		ttlNanos = c.jitterTTL(ttlNanos)
+		longTTL = c.longTTL(ttlNanos)
 		// Protect against integer overflow: if now + ttlNanos would overflow,
 		// set expireAt to max int64 (effectively never expires in practice)
-		if now > (1<<63-1)-c.ttlNanos {
+		if ttlNow > (1<<63-1)-ttlNanos {
 			expireAt = 1<<63 - 1 // max int64
 		} else {
-			expireAt = now + c.ttlNanos
+			expireAt = ttlNow + ttlNanos
 		}
 	}
 
 	// Find slot using linear probing (bounded to prevent worst-case scenarios)
-	startIdx := keyHash & uint64(c.tableMask)
+	startIdx := keyHash & uint64(t.mask)
 
 	// Calculate effective max probes: min of maxProbeLength and table size
 	effectiveMaxProbes := maxProbeLength
-	if effectiveMaxProbes > c.tableMask {
-		effectiveMaxProbes = c.tableMask
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
 	}
 
 	for i := uint32(0); i <= effectiveMaxProbes; i++ {
-		idx := (startIdx + uint64(i)) & uint64(c.tableMask)
-
-		// Safety check: ensure entries slice is not nil and idx is in bounds
-		if c.entries == nil || idx >= uint64(len(c.entries)) {
-			return false
-		}
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
 
-		entry := &c.entries[idx]
+		entry := &t.entries[idx]
 
 		// Load current state atomically
 		state := atomic.LoadInt32(&entry.valid)
 
-		// Skip entries being written/updated by other threads
-		if state == entryPending {
-			continue
+		// Skip entries being written/updated by other threads, waiting first
+		// for an entry of this key held by a conditional update (cas.go):
+		// inserting beside it would leave a duplicate racing with its update,
+		// so the Set fails if the update does not finish
+		if stateOf(state) == entryPending {
+			if state = c.awaitRelease(entry, keyHash); stateOf(state) == entryPending {
+				if atomic.LoadUint64(&entry.keyHash) == keyHash {
+					return c.setFailed(key, keyHash, i+1)
+				}
+				continue
+			}
 		}
 
 		// OPPORTUNISTIC CLEANUP: If we encounter an expired entry during probing,
 		// clean it up immediately. This improves cache efficiency without extra goroutines.
 		// Zero overhead when TTL=0 (isExpired returns false immediately).
-		if state == entryValid && c.isExpired(entry, now) {
+		if state == g.live && c.isExpired(entry, ttlNow) {
 			// Try to mark as deleted - if successful, we've cleaned up a slot
-			if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryDeleted) {
+			if c.removeValid(t, entry, ReasonExpired) {
+				c.emitEntryEvent(EntryExpired, entry)
 				entry.storeKey("")
-				atomic.AddInt64(&c.size, -1)
 				atomic.AddInt64(&c.expirations, 1)
 				// Record expiration metrics
 				if c.metricsCollector != nil {
@@ -406,62 +873,71 @@ func (c *wtinyLFUCache) Set(key string, value interface{}) bool {
 			// If CAS failed, another goroutine is handling it - continue probing
 		}
 
-		if state == entryEmpty || state == entryDeleted {
+		if t.isFree(state) {
 			// Try to claim this slot with entryPending first to prevent races
 			if atomic.CompareAndSwapInt32(&entry.valid, state, entryPending) {
 				// Successfully claimed - populate entry using helper
-				c.populateEntry(entry, key, keyHash, value, expireAt, state)
+				c.populateEntry(t, entry, key, keyHash, value, source, expireAt, longTTL, weight, state)
+				c.recordProbes(ProbeSet, i+1)
 
 				// Record metrics for successful Set
-				if c.metricsCollector != nil {
-					latency := c.timeProvider.Now() - now
-					c.metricsCollector.RecordSet(latency)
-				}
+				c.recordSetMetrics(key, start)
 
 				// Critical: Check for duplicates to maintain cache consistency
 				// In high concurrency, multiple threads might create the same key
-				c.removeDuplicateKeys(key, keyHash, entry)
+				c.removeDuplicateKeys(t, key, keyHash, entry)
 
 				// Check if eviction needed AFTER incrementing size
-				currentSize := atomic.LoadInt64(&c.size)
-				if currentSize > int64(c.maxSize) {
-					c.evictOne()
+				currentSize := t.size()
+				if currentSize > c.capacity() {
+					c.makeRoomFor(t, idx)
 				}
 				return true
 			}
 			// CAS failed, continue
+			c.recordRace()
 			continue
 		}
 
 		// Check if this is an update to existing key
 		// We need to be careful about race conditions here
-		if state == entryValid && atomic.LoadUint64(&entry.keyHash) == keyHash {
+		if state == g.live && atomic.LoadUint64(&entry.keyHash) == keyHash {
 			// Try to acquire the entry for update by marking it as pending
-			if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryPending) {
+			if atomic.CompareAndSwapInt32(&entry.valid, g.live, pendingOf(g.live)) {
 				// Check if this is really the same key (now safe to read)
 				if storedKey := entry.loadKey(); storedKey == key {
 					// UPDATE PATH: Always create new valueHolder to support type changes
 					// This prevents atomic.Value panic when storing different types.
 					// Cost: ~3-5ns allocation overhead, but guarantees correctness.
 					// The old valueHolder will be GC'd when no longer referenced.
-					newHolder := &valueHolder{}
-					newHolder.data.Store(value)
-					entry.value.Store(newHolder)
-					atomic.StoreInt64(&entry.expireAt, expireAt)
+					var previous interface{}
+					if c.onEvict != nil || c.history != nil {
+						previous = c.takePrevious(entry, key)
+					}
+					entry.value.Store(newValueHolder(value, source, c.nextVersion(), longTTL))
+					c.storeExpireAt(t, entry, expireAt)
+					if c.maxWeight > 0 {
+						c.chargeWeight(g, entry, weight)
+					}
+					c.recordWrite(t, entry, false)
 
 					// Release the entry back to valid state
-					atomic.StoreInt32(&entry.valid, entryValid)
+					atomic.StoreInt32(&entry.valid, g.live)
 					atomic.AddInt64(&c.sets, 1)
+					c.recordProbes(ProbeSet, i+1)
+					if c.onEvict != nil {
+						c.notifyEvict(key, previous, ReasonReplaced)
+					}
+					c.publishEvent(EventReplaced, key, keyHash)
 
 					// Record metrics for successful Set (update)
-					if c.metricsCollector != nil {
-						latency := c.timeProvider.Now() - now
-						c.metricsCollector.RecordSet(latency)
-					}
+					c.recordSetMetrics(key, start)
 					return true
 				}
 				// Wrong key, release and continue searching
-				atomic.StoreInt32(&entry.valid, entryValid)
+				atomic.StoreInt32(&entry.valid, g.live)
+			} else {
+				c.recordRace()
 			}
 			// CAS failed or wrong key, continue
 			continue
@@ -476,29 +952,38 @@ func (c *wtinyLFUCache) Set(key string, value interface{}) bool {
 	// If after retries we still don't find the key, we proceed with eviction + insertion.
 retryFullScan:
 	for retry := 0; retry < 5; retry++ {
-		for i := uint32(0); i < uint32(len(c.entries)); i++ {
-			entry := &c.entries[i]
+		for i := uint32(0); i < uint32(len(t.entries)); i++ {
+			entry := &t.entries[i]
 			state := atomic.LoadInt32(&entry.valid)
 
-			if state == entryValid && atomic.LoadUint64(&entry.keyHash) == keyHash {
+			if state == g.live && atomic.LoadUint64(&entry.keyHash) == keyHash {
 				if storedKey := entry.loadKey(); storedKey == key {
 					// Found it! Update in-place
-					if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryPending) {
-						holder := &valueHolder{}
-						holder.data.Store(value)
-						entry.value.Store(holder)
-						atomic.StoreInt64(&entry.expireAt, expireAt)
-						atomic.StoreInt32(&entry.valid, entryValid)
+					if atomic.CompareAndSwapInt32(&entry.valid, g.live, pendingOf(g.live)) {
+						var previous interface{}
+						if c.onEvict != nil || c.history != nil {
+							previous = c.takePrevious(entry, key)
+						}
+						entry.value.Store(newValueHolder(value, source, c.nextVersion(), longTTL))
+						c.storeExpireAt(t, entry, expireAt)
+						if c.maxWeight > 0 {
+							c.chargeWeight(g, entry, weight)
+						}
+						c.recordWrite(t, entry, false)
+						atomic.StoreInt32(&entry.valid, g.live)
 						atomic.AddInt64(&c.sets, 1)
-
-						if c.metricsCollector != nil {
-							latency := c.timeProvider.Now() - now
-							c.metricsCollector.RecordSet(latency)
+						c.recordProbes(ProbeSet, effectiveMaxProbes+1)
+						if c.onEvict != nil {
+							c.notifyEvict(key, previous, ReasonReplaced)
 						}
+						c.publishEvent(EventReplaced, key, keyHash)
+
+						c.recordSetMetrics(key, start)
 						return true
 					}
 					// CAS failed, key exists but someone else is updating it
 					// Yield and retry the full scan
+					c.recordRace()
 					runtime.Gosched()
 					continue retryFullScan
 				}
@@ -510,37 +995,36 @@ retryFullScan:
 	}
 
 	// Key doesn't exist. Try eviction to make space for new insertion.
-	c.evictOne()
+	c.evictOne(t)
 
 	// Retry bounded probing after eviction
 	for i := uint32(0); i <= effectiveMaxProbes; i++ {
-		idx := (startIdx + uint64(i)) & uint64(c.tableMask)
-
-		if c.entries == nil || idx >= uint64(len(c.entries)) {
-			return false
-		}
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
 
-		entry := &c.entries[idx]
+		entry := &t.entries[idx]
 		state := atomic.LoadInt32(&entry.valid)
 
-		if state == entryPending {
-			continue
+		if stateOf(state) == entryPending {
+			if state = c.awaitRelease(entry, keyHash); stateOf(state) == entryPending {
+				if atomic.LoadUint64(&entry.keyHash) == keyHash {
+					return c.setFailed(key, keyHash, i+1)
+				}
+				continue
+			}
 		}
 
-		if state == entryEmpty || state == entryDeleted {
+		if t.isFree(state) {
 			if atomic.CompareAndSwapInt32(&entry.valid, state, entryPending) {
-				c.populateEntry(entry, key, keyHash, value, expireAt, state)
+				c.populateEntry(t, entry, key, keyHash, value, source, expireAt, longTTL, weight, state)
+				c.recordProbes(ProbeSet, effectiveMaxProbes+1)
 
-				if c.metricsCollector != nil {
-					latency := c.timeProvider.Now() - now
-					c.metricsCollector.RecordSet(latency)
-				}
+				c.recordSetMetrics(key, start)
 
-				c.removeDuplicateKeys(key, keyHash, entry)
+				c.removeDuplicateKeys(t, key, keyHash, entry)
 
-				currentSize := atomic.LoadInt64(&c.size)
-				if currentSize > int64(c.maxSize) {
-					c.evictOne()
+				currentSize := t.size()
+				if currentSize > c.capacity() {
+					c.makeRoomFor(t, idx)
 				}
 				return true
 			}
@@ -548,6 +1032,17 @@ retryFullScan:
 	}
 
 	// Extreme contention - return false
+	return c.setFailed(key, keyHash, effectiveMaxProbes+1)
+}
+
+// setFailed records a Set of key that gave up after probing probes slots and
+// returns false.
+func (c *wtinyLFUCache) setFailed(key string, keyHash uint64, probes uint32) bool {
+	c.recordProbes(ProbeSet, probes)
+	if n, ok := c.anomalies.due(anomalySetFailed); ok {
+		c.anomalies.log(anomalySetFailed, n, "key", key, "probes", probes)
+	}
+	c.publishEvent(EventSetFailed, key, keyHash)
 	return false
 }
 
@@ -557,75 +1052,203 @@ func (c *wtinyLFUCache) Get(key string) (interface{}, bool) {
 	if key == "" {
 		return nil, false
 	}
+	value, found, _ := c.getHashed(context.Background(), key, c.hashKey(key))
+	return value, found
+}
+
+// get implements Get and GetE. contended reports a miss caused by
+// Config.KeyReadRetries being exhausted (see read_contention.go).
+func (c *wtinyLFUCache) get(key string) (value interface{}, found, contended bool) {
+	return c.getContext(context.Background(), key)
+}
+
+// getContext implements get for a call made with ctx, which is passed to a
+// MetricsCollectorV2 (see metrics_v2.go).
+func (c *wtinyLFUCache) getContext(ctx context.Context, key string) (value interface{}, found, contended bool) {
+	// Validate key is not empty
+	if key == "" {
+		return nil, false, false
+	}
+	return c.getHashed(ctx, key, c.hashKey(key))
+}
+
+// getHashed implements getContext for a non-empty key whose hash the caller
+// has already computed (see keyhasher.go).
+func (c *wtinyLFUCache) getHashed(ctx context.Context, key string, keyHash uint64) (value interface{}, found, contended bool) {
+	t := c.table.Load()
+	if t == nil || c.isClosed() {
+		return nil, false, false
+	}
 
 	// Get current time once at the start for both TTL and metrics (ensures consistency)
 	// Using go-timecache, this is ~0.4ns and provides consistent timestamp across operation
 	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+
+	value, found, contended = c.readIn(t, key, keyHash, now)
+	if !found {
+		c.classifyMiss(keyHash)
+	}
+	c.recordGet(ctx, key, start, found)
+	return value, found, contended
+}
 
-	keyHash := stringHash(key)
+// read performs the lookup of a Get started at now: it updates the sketch
+// but not the counters (see recordGet). key is not retained.
+func (c *wtinyLFUCache) read(key string, keyHash uint64, now int64) (value interface{}, found, contended bool) {
+	t := c.table.Load()
+	if t == nil || c.isClosed() {
+		return nil, false, false
+	}
+	return c.readIn(t, key, keyHash, now)
+}
+
+// readIn is read in the table t of an open cache.
+func (c *wtinyLFUCache) readIn(t *cacheTable, key string, keyHash uint64, now int64) (value interface{}, found, contended bool) {
+	ttlNow := c.ttlClock(now) // Skew-protected time for expiration decisions
 
 	// Update frequency sketch (lock-free)
-	c.sketch.increment(keyHash)
+	c.incrementFrequencyIn(t, keyHash)
+
+	return c.lookupIn(t, key, keyHash, ttlNow)
+}
+
+// recordGet updates the hit/miss counters and metrics of a Get-like read
+// made with ctx that started at start (see latencyStart).
+func (c *wtinyLFUCache) recordGet(ctx context.Context, key string, start int64, found bool) {
+	if !c.readHooks {
+		c.countGet(found)
+		return
+	}
+	if c.families != nil {
+		c.families.record(key, found)
+	}
+	if c.topKeys != nil {
+		c.recordTopKey(key)
+	}
+	c.recordGetCounters(ctx, start, found)
+	if c.keySampleEvery > 0 {
+		c.sampleKey(ProbeGet, key, start)
+	}
+}
+
+// recordGetCounters implements recordGet without the per-family counters,
+// which may retain the key.
+func (c *wtinyLFUCache) recordGetCounters(ctx context.Context, start int64, found bool) {
+	c.countGet(found)
+	if !c.readHooks {
+		return
+	}
+	if c.latency != nil {
+		c.recordGetLatency(start)
+	}
+
+	// Record hit/miss metrics
+	if c.metricsCollector != nil {
+		latency := c.latencySince(start)
+		if c.contextMetrics != nil {
+			c.contextMetrics.recordGetContext(ctx, latency, found)
+		} else {
+			c.metricsCollector.RecordGet(latency, found)
+		}
+	}
+}
+
+// countGet updates the hit/miss counters of a Get-like read.
+func (c *wtinyLFUCache) countGet(found bool) {
+	if found {
+		atomic.AddInt64(&c.hits, 1)
+	} else {
+		atomic.AddInt64(&c.misses, 1)
+	}
+}
+
+// lookup finds the live value of key, reclaiming it if it has expired.
+// It does not touch the hit/miss counters, the sketch or Get metrics.
+// contended reports that a candidate key could not be read within
+// Config.KeyReadRetries, so a miss may be false.
+func (c *wtinyLFUCache) lookup(key string, keyHash uint64, ttlNow int64) (value interface{}, found, contended bool) {
+	if c.isClosed() {
+		return nil, false, false
+	}
+	t := c.table.Load()
+	if t == nil {
+		return nil, false, false // Closed
+	}
+	return c.lookupIn(t, key, keyHash, ttlNow)
+}
+
+// lookupIn is lookup in the table t of an open cache.
+func (c *wtinyLFUCache) lookupIn(t *cacheTable, key string, keyHash uint64, ttlNow int64) (value interface{}, found, contended bool) {
+	g := t.gen.Load()
+
+	// Read-mostly fast path: resolve hits through the frozen index (if built)
+	if fi := c.frozen.Load(); fi != nil {
+		if value, ok := c.frozenGet(t, fi, key, keyHash, ttlNow); ok {
+			c.recordProbes(ProbeGet, 1)
+			return value, true, false
+		}
+	}
 
 	// Find slot using linear probing (bounded to prevent worst-case scenarios)
-	startIdx := keyHash & uint64(c.tableMask)
+	startIdx := keyHash & uint64(t.mask)
 
 	// Calculate effective max probes: min of maxProbeLength and table size
 	effectiveMaxProbes := maxProbeLength
-	if effectiveMaxProbes > c.tableMask {
-		effectiveMaxProbes = c.tableMask
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
 	}
 
+	probes := effectiveMaxProbes + 1
 	for i := uint32(0); i <= effectiveMaxProbes; i++ {
-		idx := (startIdx + uint64(i)) & uint64(c.tableMask)
-		entry := &c.entries[idx]
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
+		entry := &t.entries[idx]
 
 		// Load state atomically
 		state := atomic.LoadInt32(&entry.valid)
 
 		if state == entryEmpty {
 			// Empty slot means key not found
+			probes = i + 1
 			break
 		}
 
 		// Skip entries being written/updated
-		if state == entryPending {
+		if stateOf(state) == entryPending {
 			continue
 		}
 
-		if state == entryValid && atomic.LoadUint64(&entry.keyHash) == keyHash {
+		if state == g.live && atomic.LoadUint64(&entry.keyHash) == keyHash {
 			// Read key atomically by checking state before and after
 			// This ensures we don't read partially written data
-			if atomic.LoadInt32(&entry.valid) != entryValid {
+			if atomic.LoadInt32(&entry.valid) != g.live {
 				continue
 			}
 
-			if storedKey := entry.loadKey(); storedKey == key {
+			storedKey, ok := entry.loadKeyRetries(c.keyReadRetries)
+			if !ok {
+				atomic.AddInt64(&c.readContentions, 1)
+				if n, ok := c.anomalies.due(anomalyReadContention); ok {
+					// Not the key: lookup must not retain it (see integer_keys.go)
+					c.anomalies.log(anomalyReadContention, n, "retries", c.keyReadRetries)
+				}
+				contended = true
+				continue
+			}
+			if storedKey == key {
+				c.recordProbes(ProbeGet, i+1)
+
 				// Check if entry has expired using DRY helper
-				if c.isExpired(entry, now) {
+				if c.isExpired(entry, ttlNow) {
 					// Entry expired - mark as deleted asynchronously
 					// We don't wait for the CAS to succeed, just try once
-					if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryDeleted) {
-						atomic.AddInt64(&c.size, -1)
-						atomic.AddInt64(&c.expirations, 1)
-						// Record expiration metrics
-						if c.metricsCollector != nil {
-							c.metricsCollector.RecordExpiration()
-						}
-					}
-					atomic.AddInt64(&c.misses, 1)
-
-					// Record miss metrics
-					if c.metricsCollector != nil {
-						latency := c.timeProvider.Now() - now
-						c.metricsCollector.RecordGet(latency, false)
-					}
-					return nil, false
+					c.expireFound(t, entry, keyHash)
+					return nil, false, false
 				}
 
 				// CRITICAL: Double-check state BEFORE reading value
 				// This prevents race with Set() that might modify entry
-				if atomic.LoadInt32(&entry.valid) != entryValid {
+				if atomic.LoadInt32(&entry.valid) != g.live {
 					continue
 				}
 
@@ -634,98 +1257,135 @@ func (c *wtinyLFUCache) Get(key string) (interface{}, bool) {
 
 				// Triple-check state AFTER reading holder pointer
 				// Ensures we didn't read during a concurrent modification
-				if atomic.LoadInt32(&entry.valid) != entryValid {
+				if atomic.LoadInt32(&entry.valid) != g.live {
 					continue
 				}
 
 				// Extract actual value from holder's atomic data field
-				value := holder.data.Load()
-
 				// Found key and not expired - return value
-				atomic.AddInt64(&c.hits, 1)
-
-				// Record hit metrics
-				if c.metricsCollector != nil {
-					latency := c.timeProvider.Now() - now
-					c.metricsCollector.RecordGet(latency, true)
+				c.recordAccess(t, entry, ttlNow)
+				if c.valueCodec != nil {
+					value, ok := c.decoded(holder.load())
+					return value, ok, false
 				}
-				return value, true
+				return holder.load(), true, false
 			}
 		}
 	}
+	c.recordProbes(ProbeGet, probes)
+	return nil, false, contended
+}
 
-	atomic.AddInt64(&c.misses, 1)
-
-	// Record miss metrics
-	if c.metricsCollector != nil {
-		latency := c.timeProvider.Now() - now
-		c.metricsCollector.RecordGet(latency, false)
+// expireFound removes an expired entry of t found by a read. Reads do not
+// wait for a Resize migrating t: the removal is skipped, and the copy of the
+// entry expires in the new table.
+func (c *wtinyLFUCache) expireFound(t *cacheTable, entry *entry, keyHash uint64) {
+	if !t.enter(keyHash) {
+		return
+	}
+	defer t.leave(keyHash)
+	if c.removeValid(t, entry, ReasonExpired) {
+		c.emitEntryEvent(EntryExpired, entry)
+		atomic.AddInt64(&c.expirations, 1)
+		// Record expiration metrics
+		if c.metricsCollector != nil {
+			c.metricsCollector.RecordExpiration()
+		}
 	}
-	return nil, false
 }
 
-// Delete removes a key using lock-free operations.
+// Delete removes a key using lock-free operations. With a SecondaryCache,
+// the key is deleted from it as well, with a WriteBehindStore its deletion is
+// queued, and with an InvalidationBus its invalidation is published, whether
+// or not it was cached here.
 func (c *wtinyLFUCache) Delete(key string) bool {
 	// Validate key is not empty
 	if key == "" {
 		return false
 	}
+	if c.propagate {
+		defer c.propagateDelete(key)
+	}
+	return c.deleteLocal(key)
+}
+
+// deleteLocal removes a key from this cache only.
+func (c *wtinyLFUCache) deleteLocal(key string) bool {
+	if c.isClosed() {
+		return false
+	}
 
 	// Get current time once at the start for metrics (ensures consistency)
-	// Using go-timecache, this is ~0.4ns and provides consistent timestamp across operation
-	now := c.timeProvider.Now()
+	// Using go-timecache, this is ~0.4ns and provides consistent timestamp across operation.
+	// Skipped entirely when latency measurement is disabled (time is only used for metrics).
+	var start int64
+	if c.measureLatency {
+		start = c.latencyNow()
+	}
 
-	keyHash := stringHash(key)
-	startIdx := keyHash & uint64(c.tableMask)
+	keyHash := c.hashKey(key)
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return false // Closed
+	}
+	defer t.leave(keyHash)
+	g := t.gen.Load()
+	startIdx := keyHash & uint64(t.mask)
 
 	// Calculate effective max probes: min of maxProbeLength and table size
 	effectiveMaxProbes := maxProbeLength
-	if effectiveMaxProbes > c.tableMask {
-		effectiveMaxProbes = c.tableMask
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
 	}
 
 	for i := uint32(0); i <= effectiveMaxProbes; i++ {
-		idx := (startIdx + uint64(i)) & uint64(c.tableMask)
-		entry := &c.entries[idx]
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
+		entry := &t.entries[idx]
 
 		state := atomic.LoadInt32(&entry.valid)
 
 		if state == entryEmpty {
+			c.recordProbes(ProbeDelete, i+1)
 			return false // Key not found
 		}
 
-		// Skip entries being written/updated
-		if state == entryPending {
-			continue
+		// Skip entries being written/updated, waiting first for an entry of
+		// this key held by a conditional update (cas.go)
+		if stateOf(state) == entryPending {
+			if state = c.awaitRelease(entry, keyHash); stateOf(state) == entryPending {
+				continue
+			}
 		}
 
-		if state == entryValid && atomic.LoadUint64(&entry.keyHash) == keyHash {
+		if state == g.live && atomic.LoadUint64(&entry.keyHash) == keyHash {
 			// Check state is still valid
-			if atomic.LoadInt32(&entry.valid) != entryValid {
+			if atomic.LoadInt32(&entry.valid) != g.live {
 				continue
 			}
 
 			if storedKey := entry.loadKey(); storedKey == key {
 				// Mark as deleted atomically
-				if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryDeleted) {
+				if c.removeValid(t, entry, ReasonDeleted) {
 					entry.storeKey("")
 					// Note: We don't clear atomic.Value as it requires type consistency.
 					// The value will be overwritten when the entry is reused.
 					// GC can still collect the value once no other references exist.
-					atomic.AddInt64(&c.size, -1)
 					atomic.AddInt64(&c.deletes, 1)
+					c.recordProbes(ProbeDelete, i+1)
 
 					// Record metrics for successful Delete
 					if c.metricsCollector != nil {
-						latency := c.timeProvider.Now() - now
+						latency := c.latencySince(start)
 						c.metricsCollector.RecordDelete(latency)
 					}
 					return true
 				}
+				c.recordRace()
 			}
 		}
 	}
 
+	c.recordProbes(ProbeDelete, effectiveMaxProbes+1)
 	return false
 }
 
@@ -733,27 +1393,53 @@ func (c *wtinyLFUCache) Delete(key string) bool {
 // Returns true if the key exists and has not expired.
 // This is more efficient than Get when you only need to check existence.
 func (c *wtinyLFUCache) Has(key string) bool {
+	var keyHash uint64
+	if key != "" {
+		keyHash = c.hashKey(key)
+	}
+	return c.hasHashed(key, keyHash)
+}
+
+// hasHashed implements Has for a key whose hash the caller has already
+// computed (see keyhasher.go).
+func (c *wtinyLFUCache) hasHashed(key string, keyHash uint64) bool {
+	if c.probeMetrics == nil {
+		return c.has(key, keyHash, c.timeProvider.Now())
+	}
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	found := c.has(key, keyHash, now)
+	c.probeMetrics.RecordHas(c.latencySince(start), found)
+	return found
+}
+
+// has implements Has with the clock reading taken by the caller.
+func (c *wtinyLFUCache) has(key string, keyHash uint64, start int64) bool {
 	// Validate key is not empty
-	if key == "" {
+	if key == "" || c.isClosed() {
 		return false
 	}
+	t := c.table.Load()
+	if t == nil {
+		return false // Closed
+	}
+	g := t.gen.Load()
 
 	// Get current time once at the start for TTL check (ensures consistency)
 	// Using go-timecache, this is ~0.4ns and provides consistent timestamp across operation
-	now := c.timeProvider.Now()
+	now := c.ttlClock(start)
 
-	keyHash := stringHash(key)
-	startIdx := keyHash & uint64(c.tableMask)
+	startIdx := keyHash & uint64(t.mask)
 
 	// Calculate effective max probes: min of maxProbeLength and table size
 	effectiveMaxProbes := maxProbeLength
-	if effectiveMaxProbes > c.tableMask {
-		effectiveMaxProbes = c.tableMask
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
 	}
 
 	for i := uint32(0); i <= effectiveMaxProbes; i++ {
-		idx := (startIdx + uint64(i)) & uint64(c.tableMask)
-		entry := &c.entries[idx]
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
+		entry := &t.entries[idx]
 
 		state := atomic.LoadInt32(&entry.valid)
 
@@ -761,14 +1447,17 @@ func (c *wtinyLFUCache) Has(key string) bool {
 			return false
 		}
 
-		// Skip entries being written/updated
-		if state == entryPending {
-			continue
+		// Skip entries being written/updated, waiting first for an entry of
+		// this key held by a conditional update (cas.go)
+		if stateOf(state) == entryPending {
+			if state = c.awaitRelease(entry, keyHash); stateOf(state) == entryPending {
+				continue
+			}
 		}
 
-		if state == entryValid && atomic.LoadUint64(&entry.keyHash) == keyHash {
+		if state == g.live && atomic.LoadUint64(&entry.keyHash) == keyHash {
 			// Check state is still valid
-			if atomic.LoadInt32(&entry.valid) != entryValid {
+			if atomic.LoadInt32(&entry.valid) != g.live {
 				continue
 			}
 
@@ -776,14 +1465,7 @@ func (c *wtinyLFUCache) Has(key string) bool {
 				// Check if entry has expired (consistent with Get behavior)
 				if c.isExpired(entry, now) {
 					// Entry expired - mark as deleted asynchronously
-					if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryDeleted) {
-						atomic.AddInt64(&c.size, -1)
-						atomic.AddInt64(&c.expirations, 1)
-						// Record expiration metrics
-						if c.metricsCollector != nil {
-							c.metricsCollector.RecordExpiration()
-						}
-					}
+					c.expireFound(t, entry, keyHash)
 					return false
 				}
 				return true
@@ -794,18 +1476,102 @@ func (c *wtinyLFUCache) Has(key string) bool {
 	return false
 }
 
+// peekStale returns the last value stored for key, ignoring expiration.
+// It finds live entries as well as entries that expired lazily (marked deleted
+// by Get/Has but not yet reused), without touching statistics or the sketch.
+//
+// This is best effort: entries reclaimed by ExpireNow, eviction, Delete or slot
+// reuse are no longer visible. Used by revalidating loaders to obtain the
+// previous value (and its validator) after TTL expiry.
+func (c *wtinyLFUCache) peekStale(key string) (interface{}, bool) {
+	if key == "" || c.isClosed() {
+		return nil, false
+	}
+	t := c.table.Load()
+	if t == nil {
+		return nil, false // Closed
+	}
+	g := t.gen.Load()
+
+	keyHash := c.hashKey(key)
+	startIdx := keyHash & uint64(t.mask)
+
+	effectiveMaxProbes := maxProbeLength
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
+	}
+
+	for i := uint32(0); i <= effectiveMaxProbes; i++ {
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
+		entry := &t.entries[idx]
+
+		state := atomic.LoadInt32(&entry.valid)
+		if state == entryEmpty {
+			break
+		}
+		if state != g.live && state != entryDeleted {
+			continue
+		}
+		if atomic.LoadUint64(&entry.keyHash) != keyHash {
+			continue
+		}
+
+		// Every slot reuse rewrites the key (and bumps the SeqLock version), so an
+		// unchanged version guarantees key and value belong to the same write
+		v1 := atomic.LoadUint64(&entry.version)
+		if entry.loadKey() != key {
+			continue
+		}
+		holder, ok := entry.value.Load().(*valueHolder)
+		if !ok || holder == nil {
+			continue
+		}
+		value := holder.load()
+		if atomic.LoadUint64(&entry.version) != v1 || atomic.LoadInt32(&entry.valid) != state {
+			continue
+		}
+		return c.decoded(value)
+	}
+
+	return nil, false
+}
+
 // Len returns current number of items.
 func (c *wtinyLFUCache) Len() int {
-	return int(atomic.LoadInt64(&c.size))
+	if c.probeMetrics != nil {
+		c.probeMetrics.RecordLen()
+	}
+	size := c.entryCount()
+	if size < 0 {
+		return 0 // Transient underflow from a removal racing the insertion it removes
+	}
+	return int(size)
 }
 
 // Capacity returns maximum number of items.
 func (c *wtinyLFUCache) Capacity() int {
-	return int(c.maxSize)
+	return int(c.capacity())
 }
 
-// Clear removes all entries.
+// Clear removes all entries and resets the statistics. It is safe to call
+// concurrently with writers: a Set racing with Clear either is cleared or
+// survives it, and Len always matches the entries left.
 func (c *wtinyLFUCache) Clear() {
+	if c.isClosed() {
+		return
+	}
+	// Report dropped entries after releasing clearMu: listeners may call Clear
+	for _, e := range c.clearTable() {
+		c.notifyEvict(e.key, e.value, ReasonDeleted)
+	}
+}
+
+// clearTable implements Clear. It returns the dropped entries to report to
+// Config.OnEvict (nil without a listener).
+func (c *wtinyLFUCache) clearTable() []evicted {
+	c.clearMu.Lock()
+	defer c.clearMu.Unlock()
+
 	// Stop cleanup goroutine if running
 	// CRITICAL: Close stopCleanup before clearing negative cache to prevent races
 	select {
@@ -815,13 +1581,35 @@ func (c *wtinyLFUCache) Clear() {
 		close(c.stopCleanup)
 	}
 
-	// Reset all entries
-	for i := range c.entries {
-		atomic.StoreInt32(&c.entries[i].valid, entryEmpty)
-		c.entries[i].storeKey("")
-		// Note: We don't clear atomic.Value as it requires type consistency.
-		// Values will be overwritten when entries are reused.
-		atomic.StoreUint64(&c.entries[i].keyHash, 0)
+	// Drop the frozen index (it would only point at dropped entries)
+	c.frozen.Store(nil)
+
+	// Drop all entries: start a new generation (see generation.go), or empty
+	// the slots one by one when each of them must be visited anyway
+	var dropped []evicted
+	if t := c.writeTable(0); t != nil {
+		if c.clearsEachEntry(t) {
+			dropped = c.clearEntries(t)
+			c.resetDepartures(t)
+		} else {
+			c.nextGeneration(t)
+		}
+		t.leave(0)
+	}
+
+	// Dependency edges only describe entries that no longer exist
+	c.dependencies.reset()
+	if c.namespaces != nil {
+		c.namespaces.reset()
+	}
+	if c.memory != nil {
+		c.memory.reset()
+	}
+	if c.evictionAudit != nil {
+		c.evictionAudit.reset()
+	}
+	if c.history != nil {
+		c.history.reset()
 	}
 
 	// Clear negative cache
@@ -830,17 +1618,51 @@ func (c *wtinyLFUCache) Clear() {
 		return true
 	})
 
-	// Reset counters
-	atomic.StoreInt64(&c.size, 0)
+	// Reset counters inside an odd stats epoch so concurrent Stats() calls never
+	// observe a half-reset snapshot (e.g. hits reset but misses not yet)
+	// (size and weight belong to the generation, see generation.go)
+	atomic.AddUint64(&c.statsEpoch, 1)
 	atomic.StoreInt64(&c.hits, 0)
 	atomic.StoreInt64(&c.misses, 0)
 	atomic.StoreInt64(&c.sets, 0)
 	atomic.StoreInt64(&c.deletes, 0)
 	atomic.StoreInt64(&c.evictions, 0)
 	atomic.StoreInt64(&c.expirations, 0)
+	atomic.StoreInt64(&c.duplicateCleanups, 0)
+	atomic.StoreInt64(&c.raceConditions, 0)
+	atomic.StoreInt64(&c.readContentions, 0)
+	atomic.StoreInt64(&c.missesExpired, 0)
+	atomic.StoreInt64(&c.missesEvicted, 0)
+	atomic.StoreInt64(&c.missesRejected, 0)
+	atomic.StoreInt64(&c.negativeHits, 0)
+	atomic.StoreInt64(&c.negativeMisses, 0)
+	atomic.StoreInt64(&c.circuitRejections, 0)
+	c.events.dropped.Store(0)
+	atomic.StoreInt64(&c.rejectedTooLarge, 0)
+	if c.topKeys != nil {
+		c.topKeys.reset()
+	}
+	if c.writeBehind != nil {
+		c.writeBehind.resetCounters()
+	}
+	c.clearTombstones()
+	atomic.StoreInt64(&c.tombstoneHits, 0)
+	for i := range c.duplicatesByDistance {
+		atomic.StoreInt64(&c.duplicatesByDistance[i], 0)
+	}
+	c.resetProbes()
+	c.resetLatency()
+	if c.families != nil {
+		c.families.reset()
+	}
+	if c.window != nil {
+		c.resetWindow()
+	}
+	atomic.AddUint64(&c.statsEpoch, 1)
 
 	// Reset frequency sketch
-	c.sketch.reset()
+	c.resetFrequencies()
+	return dropped
 }
 
 // cleanupNegativeCache runs in background to remove expired negative cache entries.
@@ -906,18 +1728,79 @@ func (c *wtinyLFUCache) cleanupNegativeCache() {
 	}
 }
 
+// statsSnapshotMaxRetries bounds how many times Stats re-reads after a Clear
+// completed during the read.
+const statsSnapshotMaxRetries = 64
+
 // Stats returns cache statistics.
+//
+// The snapshot is coherent with respect to Clear: all counters are read within
+// a single stats epoch (SeqLock pattern), so a snapshot never mixes pre-Clear
+// and post-Clear values. Size is clamped to [0, +inf) because concurrent
+// removals racing a Clear can transiently drive the raw counter below zero.
 func (c *wtinyLFUCache) Stats() CacheStats {
-	return CacheStats{
-		Hits:        uint64(atomic.LoadInt64(&c.hits)),        // #nosec G115 - stats counters are always positive
-		Misses:      uint64(atomic.LoadInt64(&c.misses)),      // #nosec G115 - stats counters are always positive
-		Sets:        uint64(atomic.LoadInt64(&c.sets)),        // #nosec G115 - stats counters are always positive
-		Deletes:     uint64(atomic.LoadInt64(&c.deletes)),     // #nosec G115 - stats counters are always positive
-		Evictions:   uint64(atomic.LoadInt64(&c.evictions)),   // #nosec G115 - stats counters are always positive
-		Expirations: uint64(atomic.LoadInt64(&c.expirations)), // #nosec G115 - stats counters are always positive
-		Size:        int(atomic.LoadInt64(&c.size)),
-		Capacity:    int(c.maxSize),
+	if c.probeMetrics != nil {
+		c.probeMetrics.RecordStats()
+	}
+	for retry := 0; retry < statsSnapshotMaxRetries; {
+		e1 := atomic.LoadUint64(&c.statsEpoch)
+		if e1&1 != 0 {
+			// Clear in progress: wait for it to finish (bounded by one table scan)
+			runtime.Gosched()
+			continue
+		}
+
+		stats := c.readStats()
+
+		if atomic.LoadUint64(&c.statsEpoch) == e1 {
+			return stats
+		}
+		retry++
+	}
+
+	// Back-to-back Clear calls kept invalidating the snapshot: best effort read
+	return c.readStats()
+}
+
+// readStats loads all counters once, without consistency guarantees.
+func (c *wtinyLFUCache) readStats() CacheStats {
+	size := c.entryCount()
+	if size < 0 {
+		size = 0
+	}
+	var families map[string]FamilyStats
+	if c.families != nil {
+		families = c.families.snapshot()
+	}
+	stats := CacheStats{
+		Hits:              uint64(atomic.LoadInt64(&c.hits)),              // #nosec G115 - stats counters are always positive
+		Misses:            uint64(atomic.LoadInt64(&c.misses)),            // #nosec G115 - stats counters are always positive
+		Sets:              uint64(atomic.LoadInt64(&c.sets)),              // #nosec G115 - stats counters are always positive
+		Deletes:           uint64(atomic.LoadInt64(&c.deletes)),           // #nosec G115 - stats counters are always positive
+		Evictions:         uint64(atomic.LoadInt64(&c.evictions)),         // #nosec G115 - stats counters are always positive
+		Expirations:       uint64(atomic.LoadInt64(&c.expirations)),       // #nosec G115 - stats counters are always positive
+		DuplicateCleanups: uint64(atomic.LoadInt64(&c.duplicateCleanups)), // #nosec G115 - stats counters are always positive
+		RaceConditions:    uint64(atomic.LoadInt64(&c.raceConditions)),    // #nosec G115 - stats counters are always positive
+		ReadContentions:   uint64(atomic.LoadInt64(&c.readContentions)),   // #nosec G115 - stats counters are always positive
+		MissesExpired:     uint64(atomic.LoadInt64(&c.missesExpired)),     // #nosec G115 - stats counters are always positive
+		MissesEvicted:     uint64(atomic.LoadInt64(&c.missesEvicted)),     // #nosec G115 - stats counters are always positive
+		MissesRejected:    uint64(atomic.LoadInt64(&c.missesRejected)),    // #nosec G115 - stats counters are always positive
+		NegativeHits:      uint64(atomic.LoadInt64(&c.negativeHits)),      // #nosec G115 - stats counters are always positive
+		NegativeMisses:    uint64(atomic.LoadInt64(&c.negativeMisses)),    // #nosec G115 - stats counters are always positive
+		CircuitRejections: uint64(atomic.LoadInt64(&c.circuitRejections)), // #nosec G115 - stats counters are always positive
+		EventsDropped:     uint64(c.events.dropped.Load()),                // #nosec G115 - stats counters are always positive
+		RejectedTooLarge:  uint64(atomic.LoadInt64(&c.rejectedTooLarge)),  // #nosec G115 - stats counters are always positive
+		TombstoneHits:     uint64(atomic.LoadInt64(&c.tombstoneHits)),     // #nosec G115 - stats counters are always positive
+		Size:              int(size),
+		Capacity:          int(c.capacity()),
+		Weight:            c.totalWeight(),
+		MaxWeight:         c.maxWeight,
+		Families:          families,
 	}
+	c.readProbes(&stats)
+	c.readWriteBehind(&stats)
+	c.readLatency(&stats)
+	return stats
 }
 
 // ExpireNow manually expires all entries that have exceeded their TTL.
@@ -942,23 +1825,35 @@ func (c *wtinyLFUCache) Stats() CacheStats {
 //   - Uses CAS to prevent double-counting of expired entries
 func (c *wtinyLFUCache) ExpireNow() int {
 	// Fast path: if TTL is disabled, nothing to expire
-	if c.ttlNanos == 0 {
+	if atomic.LoadInt64(&c.maxTTLNanos) == 0 || c.isClosed() {
 		return 0
 	}
 
-	// Get current time once for consistency
-	now := c.timeProvider.Now()
+	t := c.writeTable(0)
+	if t == nil {
+		return 0 // Closed
+	}
+	defer t.leave(0)
+
+	// Get current time once for consistency, then scan entire table
+	return c.expireRange(t, 0, len(t.entries), c.ttlClock(c.timeProvider.Now()))
+}
+
+// expireRange removes the expired entries in slots [from, to) of t as of the
+// TTL clock reading now. Shared by ExpireNow and the cleanup janitor (see
+// janitor.go).
+func (c *wtinyLFUCache) expireRange(t *cacheTable, from, to int, now int64) int {
+	g := t.gen.Load()
 	expiredCount := 0
 
-	// Scan entire table
-	for i := range c.entries {
-		entry := &c.entries[i]
+	for i := from; i < to; i++ {
+		entry := &t.entries[i]
 
 		// Load entry state atomically
 		state := atomic.LoadInt32(&entry.valid)
 
 		// Skip empty, deleted, or pending entries
-		if state != entryValid {
+		if state != g.live {
 			continue
 		}
 
@@ -966,11 +1861,11 @@ func (c *wtinyLFUCache) ExpireNow() int {
 		if c.isExpired(entry, now) {
 			// Try to mark as deleted atomically
 			// CAS ensures we only count each expiration once even with concurrent ExpireNow calls
-			if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryDeleted) {
+			if c.removeValid(t, entry, ReasonExpired) {
 				// Successfully expired this entry
+				c.emitEntryEvent(EntryExpired, entry)
 				entry.storeKey("")
 				// Note: atomic.Value will be reset when entry is reused via populateEntry
-				atomic.AddInt64(&c.size, -1)
 				atomic.AddInt64(&c.expirations, 1)
 				expiredCount++
 
@@ -985,16 +1880,59 @@ func (c *wtinyLFUCache) ExpireNow() int {
 	return expiredCount
 }
 
-// Close gracefully shuts down the cache.
+// Close marks the cache closed, stops the background goroutines, drops the
+// remaining entries (reported to OnEvict) and releases the table and the
+// sketch (see close.go). Idempotent.
 func (c *wtinyLFUCache) Close() error {
-	c.Clear()
+	c.closeOnce.Do(func() {
+		atomic.StoreInt32(&c.closed, 1)
+		if c.stopIndexer != nil {
+			close(c.stopIndexer)
+		}
+		if c.stopShrinker != nil {
+			close(c.stopShrinker)
+		}
+		if c.stopJanitor != nil {
+			close(c.stopJanitor)
+		}
+		if c.stopDecay != nil {
+			close(c.stopDecay)
+		}
+		if c.stopPressure != nil {
+			close(c.stopPressure)
+		}
+		if c.stopArena != nil {
+			close(c.stopArena)
+		}
+		if c.window != nil {
+			close(c.window.stop)
+		}
+		if c.unsubscribeInvalidations != nil {
+			c.unsubscribeInvalidations()
+		}
+		if c.stopTombstones != nil {
+			close(c.stopTombstones)
+		}
+		if c.writeBehind != nil {
+			c.writeBehind.close() // Workers flush the queue before exiting
+		}
+		c.background.Wait()
+
+		for _, e := range c.clearTable() {
+			c.notifyEvict(e.key, e.value, ReasonDeleted)
+		}
+		c.table.Store(nil) // Operations in flight keep their copy (see close.go)
+		c.events.close()
+	})
 	return nil
 }
 
-// evictOne performs W-TinyLFU eviction by finding the entry with lowest frequency.
+// evictOne performs W-TinyLFU eviction by finding the entry of t with lowest frequency.
 // Uses a sampling approach to avoid scanning the entire table.
-func (c *wtinyLFUCache) evictOne() {
-	tableSize := int(c.tableMask) + 1
+// Returns false if no entry could be evicted.
+func (c *wtinyLFUCache) evictOne(t *cacheTable) bool {
+	g := t.gen.Load()
+	tableSize := int(t.mask) + 1
 
 	// Try multiple rounds of sampling before giving up
 	for retry := 0; retry < evictionMaxRetries; retry++ {
@@ -1009,38 +1947,36 @@ func (c *wtinyLFUCache) evictOne() {
 			step = 1
 		}
 
-		// Sample entries with random distribution
-		for i := 0; i < evictionSampleSize; i++ {
-			idx := (start + i*step) % tableSize
-			entry := &c.entries[idx]
-			state := atomic.LoadInt32(&entry.valid)
-
-			if state == entryValid {
-				// Check frequency using the sketch
-				freq := c.sketch.estimate(atomic.LoadUint64(&entry.keyHash))
-
-				if freq < minFrequency {
-					minFrequency = freq
-					victim = entry
+		var decision *EvictionDecision // Set only when the eviction audit is enabled
+		if c.maxWeight > 0 || c.capacity() < int64(t.limit) {
+			// The table is sized for MaxSize (or a larger ResizeLimit) and
+			// may be sparse: scan consecutive slots until the sample is full
+			// (see weight.go and resize.go)
+			victim, decision = c.policyVictim(t, start, 1, tableSize)
+		} else if c.evictionPolicy != nil || c.evictionAudit != nil {
+			victim, decision = c.policyVictim(t, start, step, evictionSampleSize)
+		} else {
+			// Sample entries with random distribution
+			for i := 0; i < evictionSampleSize; i++ {
+				idx := (start + i*step) % tableSize
+				entry := &t.entries[idx]
+				state := atomic.LoadInt32(&entry.valid)
+
+				if state == g.live {
+					// Check frequency using the sketch
+					freq := c.estimateFrequency(atomic.LoadUint64(&entry.keyHash))
+
+					if freq < minFrequency {
+						minFrequency = freq
+						victim = entry
+					}
 				}
 			}
 		}
 
 		// If we found a victim, try to evict it
-		if victim != nil {
-			if atomic.CompareAndSwapInt32(&victim.valid, entryValid, entryDeleted) {
-				victim.storeKey("")
-				// Note: We don't clear atomic.Value as it requires type consistency.
-				// The value will be overwritten when the entry is reused.
-				atomic.AddInt64(&c.size, -1)
-				atomic.AddInt64(&c.evictions, 1)
-
-				// Record eviction metrics
-				if c.metricsCollector != nil {
-					c.metricsCollector.RecordEviction()
-				}
-				return
-			}
+		if victim != nil && c.evictEntry(t, victim, decision) {
+			return true
 		}
 	}
 
@@ -1053,49 +1989,68 @@ func (c *wtinyLFUCache) evictOne() {
 	if scanSize > tableSize {
 		scanSize = tableSize
 	}
+	if n, ok := c.anomalies.due(anomalyFallbackEviction); ok {
+		c.anomalies.log(anomalyFallbackEviction, n, "sampling_rounds", evictionMaxRetries, "scanned_slots", scanSize)
+	}
 
 	for i := 0; i < scanSize; i++ {
-		entry := &c.entries[i]
+		entry := &t.entries[i]
 		state := atomic.LoadInt32(&entry.valid)
 
-		if state == entryValid {
-			if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryDeleted) {
-				entry.storeKey("")
-				// Note: Value will be cleared when entry is reused via populateEntry
-				atomic.AddInt64(&c.size, -1)
-				atomic.AddInt64(&c.evictions, 1)
-
-				// Record eviction metrics
-				if c.metricsCollector != nil {
-					c.metricsCollector.RecordEviction()
-				}
-				return
-			}
+		if state == g.live && c.evictEntry(t, entry, nil) {
+			return true
 		}
 	}
+	return false
+}
+
+// evictEntry evicts victim, a live entry of t. decision is the sampled decision
+// to record in the eviction audit (nil for fallback evictions). Returns false
+// if the entry was removed or taken by another goroutine first.
+func (c *wtinyLFUCache) evictEntry(t *cacheTable, victim *entry, decision *EvictionDecision) bool {
+	if !c.removeValid(t, victim, ReasonEvicted) {
+		return false
+	}
+	if c.evictionAudit != nil {
+		c.auditEviction(decision, victim)
+	}
+	c.emitEntryEvent(EntryEvicted, victim)
+	victim.storeKey("")
+	// Note: We don't clear atomic.Value as it requires type consistency.
+	// The value will be overwritten when the entry is reused.
+	atomic.AddInt64(&c.evictions, 1)
+
+	// Record eviction metrics
+	if c.metricsCollector != nil {
+		c.metricsCollector.RecordEviction()
+	}
+	return true
 }
 
-// removeDuplicateKeys removes any duplicate entries for the same key
+// removeDuplicateKeys removes any duplicate entries of t for the same key
 // This is a safety mechanism to handle race conditions in concurrent Set operations
 // Uses a limited scan around the hash position for performance
-func (c *wtinyLFUCache) removeDuplicateKeys(key string, keyHash uint64, keepEntry *entry) {
+func (c *wtinyLFUCache) removeDuplicateKeys(t *cacheTable, key string, keyHash uint64, keepEntry *entry) {
+	g := t.gen.Load()
 	// CRITICAL FIX for issue #3: Add retry logic to handle state transitions
 	// during high contention. Without retries, CAS failures can leave duplicates.
-	const maxRetries = 3 // Try up to 3 times per entry
+	// Each failure means another writer took the entry for a moment: retry
+	// as many times as claimKey rescans a contended probe chain
+	const maxRetries = claimRetries
 
 	// Scan a limited range around the original hash position
-	startIdx := keyHash & uint64(c.tableMask)
+	startIdx := keyHash & uint64(t.mask)
 
 	// Scan a reasonable window (not the entire table)
 	// duplicateScanRange covers worst-case linear probing at 50% load factor
 	scanRange := uint32(duplicateScanRange)
-	if scanRange > c.tableMask {
-		scanRange = c.tableMask
+	if scanRange > t.mask {
+		scanRange = t.mask
 	}
 
 	for i := uint32(0); i < scanRange; i++ {
-		idx := (startIdx + uint64(i)) & uint64(c.tableMask)
-		entry := &c.entries[idx]
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
+		entry := &t.entries[idx]
 
 		// Skip the entry we want to keep
 		if entry == keepEntry {
@@ -1104,11 +2059,16 @@ func (c *wtinyLFUCache) removeDuplicateKeys(key string, keyHash uint64, keepEntr
 
 		// Try to remove duplicate with retry logic
 		for retry := 0; retry < maxRetries; retry++ {
-			// Check if this entry has the same key
+			// Check if this entry has the same key, waiting for an entry of
+			// the key held by a conditional update: skipping it would leave
+			// it live beside the one kept
 			state := atomic.LoadInt32(&entry.valid)
+			if stateOf(state) == entryPending {
+				state = c.awaitRelease(entry, keyHash)
+			}
 
 			// If not valid, no need to check further
-			if state != entryValid {
+			if state != g.live {
 				break
 			}
 
@@ -1125,15 +2085,30 @@ func (c *wtinyLFUCache) removeDuplicateKeys(key string, keyHash uint64, keepEntr
 
 			// Found a duplicate - try to remove it atomically
 			// CAS from entryValid to entryPending for exclusive access
-			if atomic.CompareAndSwapInt32(&entry.valid, entryValid, entryPending) {
+			if atomic.CompareAndSwapInt32(&entry.valid, g.live, pendingOf(g.live)) {
 				// Successfully acquired exclusive access, clear it
+				var duplicate interface{}
+				if c.onEvict != nil || c.history != nil {
+					// A write that reused an earlier free slot replaces the
+					// entry found further along the probe sequence
+					duplicate = c.takePrevious(entry, key)
+				}
 				entry.storeKey("")
 				atomic.StoreUint64(&entry.keyHash, 0)
+				if c.maxWeight > 0 {
+					c.chargeWeight(g, entry, 0)
+				}
 
 				// Mark as deleted (final state)
 				atomic.StoreInt32(&entry.valid, entryDeleted)
-				atomic.AddInt64(&c.size, -1)
+				atomic.AddInt64(&g.size, -1)
 				// Note: we don't increment evictions counter as this is a cleanup operation
+				c.recordDuplicateCleanup(i)
+				if c.onEvict != nil {
+					// The losing write's value never became visible: report it
+					// as replaced by the one kept
+					c.notifyEvict(key, duplicate, ReasonReplaced)
+				}
 
 				// Successfully removed, break retry loop
 				break
@@ -1142,6 +2117,7 @@ func (c *wtinyLFUCache) removeDuplicateKeys(key string, keyHash uint64, keepEntr
 			// CAS failed - state changed between load and CAS
 			// Retry to check new state (might have been deleted by another thread)
 			// Small yield to reduce contention before retry
+			c.recordRace()
 			if retry < maxRetries-1 {
 				runtime.Gosched()
 			}
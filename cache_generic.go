@@ -7,6 +7,7 @@
 package balios
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 )
@@ -26,7 +27,13 @@ import (
 //	    fmt.Printf("User: %+v\n", value)
 //	}
 type GenericCache[K comparable, V any] struct {
-	inner Cache // Wraps existing cache implementation
+	inner extendedCache // Wraps existing cache implementation (see extensions.go)
+
+	// Read fast path (see keyhasher.go): set when inner is a *wtinyLFUCache
+	// whose keys are hashed by hash
+	core   *wtinyLFUCache
+	hash   func(key K) uint64
+	intKey bool // K is an integer type: reads do not allocate (see integer_keys.go)
 }
 
 // NewGenericCache creates a new type-safe generic cache.
@@ -34,11 +41,19 @@ type GenericCache[K comparable, V any] struct {
 // Parameters:
 //   - cfg: Cache configuration (MaxSize, TTL, WindowRatio, etc.)
 //
+// Integer keys are hashed directly from their value, unless cfg.KeyHasher
+// is set (see NewGenericCacheWithHasher).
+//
 // Returns a new GenericCache instance.
 func NewGenericCache[K comparable, V any](cfg Config) *GenericCache[K, V] {
-	innerCache := NewCache(cfg)
+	if cfg.KeyHasher == nil {
+		if hasher, adapter := integerKeyHasher[K](); hasher != nil {
+			cfg.KeyHasher = adapter
+			return newGenericCacheHashed[K, V](cfg, hasher)
+		}
+	}
 	return &GenericCache[K, V]{
-		inner: innerCache,
+		inner: extend(NewCache(cfg)),
 	}
 }
 
@@ -48,11 +63,13 @@ func NewGenericCache[K comparable, V any](cfg Config) *GenericCache[K, V] {
 // Parameters:
 //   - key: The key to store (must be comparable)
 //   - value: The value to store (can be any type)
-func (c *GenericCache[K, V]) Set(key K, value V) {
+//
+// Returns false if the value was not stored (see Cache.Set).
+func (c *GenericCache[K, V]) Set(key K, value V) bool {
 	// Fast path: convert key to string with zero allocations for common types
 	keyStr := keyToString(key)
 	// Validation is done by inner cache (empty string check)
-	c.inner.Set(keyStr, value)
+	return c.inner.Set(keyStr, value)
 }
 
 // Get retrieves a value from the cache.
@@ -64,8 +81,17 @@ func (c *GenericCache[K, V]) Set(key K, value V) {
 //   - value: The stored value (zero value if not found)
 //   - found: true if key exists and is not expired
 func (c *GenericCache[K, V]) Get(key K) (value V, found bool) {
-	keyStr := keyToString(key)
-	val, found := c.inner.Get(keyStr)
+	var val interface{}
+	switch {
+	case c.intKey:
+		val, found = c.getInteger(key)
+	case c.hash != nil:
+		if keyStr := keyToString(key); keyStr != "" {
+			val, found, _ = c.core.getHashed(context.Background(), keyStr, c.hash(key))
+		}
+	default:
+		val, found = c.inner.Get(keyToString(key))
+	}
 	if !found {
 		var zero V
 		return zero, false
@@ -86,9 +112,11 @@ func (c *GenericCache[K, V]) Get(key K) (value V, found bool) {
 //
 // Parameters:
 //   - key: The key to remove
-func (c *GenericCache[K, V]) Delete(key K) {
+//
+// Returns true if the key was cached.
+func (c *GenericCache[K, V]) Delete(key K) bool {
 	keyStr := keyToString(key)
-	c.inner.Delete(keyStr)
+	return c.inner.Delete(keyStr)
 }
 
 // Has checks if a key exists in the cache without retrieving it.
@@ -99,7 +127,13 @@ func (c *GenericCache[K, V]) Delete(key K) {
 //
 // Returns true if key exists and is not expired.
 func (c *GenericCache[K, V]) Has(key K) bool {
+	if c.intKey {
+		return c.hasInteger(key)
+	}
 	keyStr := keyToString(key)
+	if c.hash != nil && keyStr != "" {
+		return c.core.hasHashed(keyStr, c.hash(key))
+	}
 	return c.inner.Has(keyStr)
 }
 
@@ -139,6 +173,58 @@ func keyToString[K comparable](key K) string {
 	}
 }
 
+// keyFromString is the inverse of keyToString for string and integer key
+// types. ok is false for other key types, whose string form cannot be parsed
+// back, and for strings that are not a valid K.
+func keyFromString[K comparable](s string) (key K, ok bool) {
+	var parsed any
+	var err error
+	switch any(key).(type) {
+	case string:
+		parsed = s
+	case int:
+		parsed, err = strconv.Atoi(s)
+	case int8:
+		var v int64
+		v, err = strconv.ParseInt(s, 10, 8)
+		parsed = int8(v) // #nosec G115 -- range checked by the parser
+	case int16:
+		var v int64
+		v, err = strconv.ParseInt(s, 10, 16)
+		parsed = int16(v) // #nosec G115 -- range checked by the parser
+	case int32:
+		var v int64
+		v, err = strconv.ParseInt(s, 10, 32)
+		parsed = int32(v) // #nosec G115 -- range checked by the parser
+	case int64:
+		parsed, err = strconv.ParseInt(s, 10, 64)
+	case uint:
+		var v uint64
+		v, err = strconv.ParseUint(s, 10, 0)
+		parsed = uint(v) // #nosec G115 -- range checked by the parser
+	case uint8:
+		var v uint64
+		v, err = strconv.ParseUint(s, 10, 8)
+		parsed = uint8(v) // #nosec G115 -- range checked by the parser
+	case uint16:
+		var v uint64
+		v, err = strconv.ParseUint(s, 10, 16)
+		parsed = uint16(v) // #nosec G115 -- range checked by the parser
+	case uint32:
+		var v uint64
+		v, err = strconv.ParseUint(s, 10, 32)
+		parsed = uint32(v) // #nosec G115 -- range checked by the parser
+	case uint64:
+		parsed, err = strconv.ParseUint(s, 10, 64)
+	default:
+		return key, false
+	}
+	if err != nil {
+		return key, false
+	}
+	return parsed.(K), true
+}
+
 // Clear removes all entries from the cache and resets statistics.
 func (c *GenericCache[K, V]) Clear() {
 	c.inner.Clear()
@@ -167,7 +253,7 @@ func (c *GenericCache[K, V]) Stats() CacheStats {
 }
 
 // Close cleans up cache resources and stops background goroutines.
-// After calling Close, the cache should not be used.
+// Afterwards operations are safe no-ops (see Cache.Close).
 // Returns any error from closing the underlying cache.
 func (c *GenericCache[K, V]) Close() error {
 	return c.inner.Close()
@@ -7,6 +7,7 @@
 package balios
 
 import (
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
@@ -145,6 +146,56 @@ func TestGenericCache_Has(t *testing.T) {
 	}
 }
 
+// TestGenericCache_Parity checks that GenericCache exposes every method of
+// the interface{} API, with the same results.
+func TestGenericCache_Parity(t *testing.T) {
+	generic := reflect.TypeOf(&GenericCache[string, int]{})
+	core := reflect.TypeOf(&wtinyLFUCache{})
+	for i := 0; i < core.NumMethod(); i++ {
+		name := core.Method(i).Name
+		method, ok := generic.MethodByName(name)
+		if !ok {
+			t.Errorf("GenericCache lacks %s", name)
+			continue
+		}
+		if got, want := method.Type.NumOut(), core.Method(i).Type.NumOut(); got != want {
+			t.Errorf("GenericCache.%s returns %d results, Cache.%s returns %d", name, got, name, want)
+		}
+	}
+
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+	defer cache.Close()
+	if !cache.Set("a", 1) || cache.Set("", 1) {
+		t.Error("Set must report whether the value was stored")
+	}
+	if n := cache.SetMany(map[string]int{"b": 2, "c": 3, "": 4}); n != 2 {
+		t.Errorf("SetMany stored %d pairs, want 2", n)
+	}
+	if !cache.SetWithSource("d", 4, "test") {
+		t.Error("SetWithSource must report whether the value was stored")
+	}
+	if !cache.Delete("d") || cache.Delete("d") {
+		t.Error("Delete must report whether the key was cached")
+	}
+	sum := 0
+	cache.Range(func(key string, value int) bool {
+		sum += value
+		return true
+	})
+	if sum != 6 {
+		t.Errorf("Range visited values summing to %d, want 6", sum)
+	}
+
+	ns := cache.Namespace("ns")
+	v, err := ns.GetOrLoad("k", func() (int, error) { return 7, nil })
+	if err != nil || v != 7 || !cache.Has("ns:k") {
+		t.Errorf("namespace GetOrLoad = %d, %v", v, err)
+	}
+	if !ns.Delete("k") || ns.Set("", 1) {
+		t.Error("namespace Set and Delete must report their results")
+	}
+}
+
 // TestGenericCache_Clear tests clearing cache
 func TestGenericCache_Clear(t *testing.T) {
 	cache := NewGenericCache[string, int](DefaultConfig())
@@ -260,12 +311,13 @@ func TestGenericCache_Close(t *testing.T) {
 		t.Errorf("Expected Size=0 after Close, got %d", stats.Size)
 	}
 
-	// Verify cache can still be used after Close (graceful degradation)
+	// Operations after Close are safe no-ops
+	if !cache.Closed() {
+		t.Error("Expected Closed() to be true after Close")
+	}
 	cache.Set("new-key", 999)
-
-	value, found := cache.Get("new-key")
-	if !found || value != 999 {
-		t.Error("Expected cache to work after Close (graceful degradation)")
+	if _, found := cache.Get("new-key"); found {
+		t.Error("Expected Get to miss after Close")
 	}
 }
 
@@ -251,7 +251,7 @@ func TestCache_Eviction(t *testing.T) {
 }
 
 func TestCache_Close(t *testing.T) {
-	cache := NewCache(Config{MaxSize: 100})
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
 
 	// Populate cache
 	for i := 0; i < 10; i++ {
@@ -278,15 +278,15 @@ func TestCache_Close(t *testing.T) {
 		t.Errorf("Expected Size=0 after Close, got %d", stats.Size)
 	}
 
-	// Verify cache can still be used after Close (graceful degradation)
-	success := cache.Set("new-key", "new-value")
-	if !success {
-		t.Error("Expected Set to succeed after Close")
+	// Operations after Close are safe no-ops
+	if !cache.Closed() {
+		t.Error("Expected Closed() to be true after Close")
 	}
-
-	value, found := cache.Get("new-key")
-	if !found || value != "new-value" {
-		t.Error("Expected cache to work after Close (graceful degradation)")
+	if cache.Set("new-key", "new-value") {
+		t.Error("Expected Set to fail after Close")
+	}
+	if _, found := cache.Get("new-key"); found {
+		t.Error("Expected Get to miss after Close")
 	}
 }
 
@@ -0,0 +1,538 @@
+// cas.go: conditional updates (CompareAndSwap, Swap, SetIfAbsent)
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// defaultValueEqual compares a and b with ==, returning false instead of
+// panicking when the dynamic types are not comparable.
+func defaultValueEqual(a, b interface{}) (equal bool) {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	// Comparable static types can still panic at runtime (e.g. an interface
+	// field holding a slice), so guard the comparison
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return a == b
+}
+
+// safeValueEqual calls the configured equality function, treating a panic as
+// "not equal" so an acquired entry is always released.
+func (c *wtinyLFUCache) safeValueEqual(a, b interface{}) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return c.valueEqual(a, b)
+}
+
+// acquireRetries bounds how often acquireEntry waits for a concurrent writer
+// holding a slot that may belong to the requested key.
+const acquireRetries = 16
+
+// acquireEntry finds the live entry for key in t and moves it to entryPending.
+// The caller must release it with entry.release().
+// Returns nil if the key is absent or expired.
+func (c *wtinyLFUCache) acquireEntry(t *cacheTable, key string, keyHash uint64, now int64) *entry {
+	for retry := 0; retry < acquireRetries; retry++ {
+		entry, contended := c.tryAcquireEntry(t, key, keyHash, now)
+		if entry != nil || !contended {
+			return entry
+		}
+		// A slot was pending (possibly our key mid-update): yield and retry
+		runtime.Gosched()
+	}
+	return nil
+}
+
+// tryAcquireEntry performs a single probe pass for acquireEntry.
+// contended reports whether a pending slot was skipped.
+func (c *wtinyLFUCache) tryAcquireEntry(t *cacheTable, key string, keyHash uint64, now int64) (found *entry, contended bool) {
+	if c.isClosed() {
+		return nil, false
+	}
+	g := t.gen.Load()
+	startIdx := keyHash & uint64(t.mask)
+
+	effectiveMaxProbes := maxProbeLength
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
+	}
+
+	for i := uint32(0); i <= effectiveMaxProbes; i++ {
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
+		entry := &t.entries[idx]
+
+		state := atomic.LoadInt32(&entry.valid)
+		if state == entryEmpty {
+			break // End of probe chain
+		}
+		if stateOf(state) == entryPending {
+			contended = true
+			continue
+		}
+		if state != g.live || atomic.LoadUint64(&entry.keyHash) != keyHash {
+			continue
+		}
+		if !atomic.CompareAndSwapInt32(&entry.valid, g.live, pendingOf(g.live)) {
+			contended = true
+			continue
+		}
+		if entry.loadKey() != key {
+			atomic.StoreInt32(&entry.valid, g.live)
+			continue
+		}
+		if c.isExpired(entry, now) {
+			// Leave reclamation (and expiration accounting) to Get/ExpireNow
+			atomic.StoreInt32(&entry.valid, g.live)
+			return nil, false
+		}
+		return entry, false
+	}
+	return nil, contended
+}
+
+// releaseTimeout bounds the wait of awaitRelease. Holders release entries
+// within microseconds once they run, but a descheduled holder can take
+// several scheduler time slices, so the wait is measured in time rather than
+// yields. The bound only ends waits for a holder that cannot release
+// meanwhile, such as a Compute transformer writing its own key against the
+// documented rule: a Set then fails rather than store a second copy.
+const releaseTimeout = time.Second
+
+// awaitRelease waits until a pending entry that may hold the key of keyHash
+// is released, yielding drainSpins times and then sleeping drainSleep between
+// checks (see swappable.go). Returns the state of the entry after waiting
+// (still entryPending if its holder did not finish within releaseTimeout).
+func (c *wtinyLFUCache) awaitRelease(entry *entry, keyHash uint64) int32 {
+	state := atomic.LoadInt32(&entry.valid)
+	var deadline time.Time
+	for spins := 0; stateOf(state) == entryPending && atomic.LoadUint64(&entry.keyHash) == keyHash; spins++ {
+		switch {
+		case spins < drainSpins:
+			runtime.Gosched()
+		case deadline.IsZero():
+			deadline = time.Now().Add(releaseTimeout)
+			time.Sleep(drainSleep)
+		case time.Now().After(deadline):
+			return state
+		default:
+			time.Sleep(drainSleep)
+		}
+		state = atomic.LoadInt32(&entry.valid)
+	}
+	return state
+}
+
+// claimRetries bounds the probe passes of claimKey. A pass is repeated when a
+// pending slot may hold the key; writers release slots within microseconds,
+// so the bound is only reached when slots are held abnormally long.
+const claimRetries = 1024
+
+// claimKey takes exclusive ownership of key in t: it acquires the live entry of
+// key (existing = true) or, when key is absent, claims a free slot of its
+// probe chain no other insertion of key can race with (existing = false,
+// oldState is the previous state of the slot, for populateEntry). Either
+// way the entry is entryPending and the caller must publish or release it.
+// Expired entries met on the way are reclaimed. Returns a nil entry when the
+// probe chain has no free slot, stays contended for claimRetries passes, or
+// the cache is closed.
+func (c *wtinyLFUCache) claimKey(t *cacheTable, key string, keyHash uint64, now int64) (claimed *entry, idx uint64, existing bool, oldState int32) {
+	g := t.gen.Load()
+	startIdx := keyHash & uint64(t.mask)
+	effectiveMaxProbes := maxProbeLength
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
+	}
+
+	for retry := 0; retry < claimRetries && !c.isClosed(); retry++ {
+		var free *entry
+		var freeIdx uint64
+		var freeState int32
+		contended := false
+
+	probe:
+		for i := uint32(0); i <= effectiveMaxProbes; i++ {
+			idx := (startIdx + uint64(i)) & uint64(t.mask)
+			entry := &t.entries[idx]
+
+			state := atomic.LoadInt32(&entry.valid)
+			if state == g.live && c.isExpired(entry, now) {
+				// Reclaim it like Set does, so the slot can be reused
+				if c.removeValid(t, entry, ReasonExpired) {
+					c.emitEntryEvent(EntryExpired, entry)
+					entry.storeKey("")
+					atomic.AddInt64(&c.expirations, 1)
+					if c.metricsCollector != nil {
+						c.metricsCollector.RecordExpiration()
+					}
+				}
+				state = atomic.LoadInt32(&entry.valid)
+			}
+
+			switch {
+			case stateOf(state) == entryPending:
+				// Possibly our key, being written or updated: wait and rescan
+				contended = true
+			case t.isFree(state):
+				if free == nil {
+					free, freeIdx, freeState = entry, idx, state
+				}
+				if state == entryEmpty {
+					break probe // End of probe chain
+				}
+			case state == g.live:
+				if atomic.LoadUint64(&entry.keyHash) != keyHash {
+					continue
+				}
+				if !atomic.CompareAndSwapInt32(&entry.valid, g.live, pendingOf(g.live)) {
+					contended = true
+					continue
+				}
+				if entry.loadKey() != key {
+					atomic.StoreInt32(&entry.valid, g.live)
+					continue
+				}
+				if c.isExpired(entry, now) {
+					// Expired since the check above: reclaim it on the next pass
+					atomic.StoreInt32(&entry.valid, g.live)
+					contended = true
+					continue
+				}
+				return entry, idx, true, g.live
+			}
+		}
+
+		if !contended {
+			if free == nil {
+				return nil, 0, false, 0
+			}
+			if atomic.CompareAndSwapInt32(&free.valid, freeState, entryPending) {
+				if c.ownsInsertion(t, key, keyHash, startIdx, effectiveMaxProbes, freeIdx) {
+					return free, freeIdx, false, freeState
+				}
+				// Another insertion of key is in flight
+				c.releaseClaim(free, false)
+			}
+		}
+		runtime.Gosched()
+	}
+	return nil, 0, false, 0
+}
+
+// ownsInsertion reports whether the slot claimed at claimedIdx is the only
+// entry of key in its probe chain. A pending slot before it may be an
+// insertion of key that started first: the claim yields. A pending slot after
+// it yields to this claim (or is an unrelated writer), so it is waited for,
+// up to acquireRetries yields.
+func (c *wtinyLFUCache) ownsInsertion(t *cacheTable, key string, keyHash, startIdx uint64, maxProbes uint32, claimedIdx uint64) bool {
+	g := t.gen.Load()
+	before := true
+	for i := uint32(0); i <= maxProbes; i++ {
+		idx := (startIdx + uint64(i)) & uint64(t.mask)
+		if idx == claimedIdx {
+			before = false
+			continue
+		}
+		entry := &t.entries[idx]
+
+		state := atomic.LoadInt32(&entry.valid)
+		for retry := 0; stateOf(state) == entryPending; retry++ {
+			if before || retry == acquireRetries {
+				return false
+			}
+			runtime.Gosched()
+			state = atomic.LoadInt32(&entry.valid)
+		}
+		switch {
+		case state == entryEmpty:
+			return true // End of probe chain
+		case state == g.live && atomic.LoadUint64(&entry.keyHash) == keyHash && entry.loadKey() == key:
+			return false
+		}
+	}
+	return true
+}
+
+// entryExpireAt computes the expiration timestamp for a write at ttlNow.
+func (c *wtinyLFUCache) entryExpireAt(ttlNow int64) int64 {
+	if c.ttlNanos <= 0 || ttlNow <= 0 {
+		return 0
+	}
+	ttlNanos := c.jitterTTL(c.ttlNanos)
+	if ttlNow > (1<<63-1)-ttlNanos {
+		return 1<<63 - 1
+	}
+	return ttlNow + ttlNanos
+}
+
+// replaceValue stores value, of the given weight, into the acquired entry of
+// key in t, renews its TTL and releases it. Returns the previous value and the
+// new write version (see versions.go).
+func (c *wtinyLFUCache) replaceValue(t *cacheTable, entry *entry, key string, value interface{}, weight int32, expireAt int64) (interface{}, uint64) {
+	g := t.owner(entry)
+	previous := c.takePrevious(entry, key)
+	if c.maxWeight > 0 {
+		c.chargeWeight(g, entry, weight)
+	}
+
+	version := c.nextVersion()
+	entry.value.Store(newValueHolder(value, "", version, 0))
+	c.storeExpireAt(t, entry, expireAt)
+	c.recordWrite(t, entry, false)
+
+	entry.release()
+	atomic.AddInt64(&c.sets, 1)
+	if c.onEvict != nil {
+		c.notifyEvict(key, previous, ReasonReplaced)
+	}
+	if c.events.wants(EventReplaced) {
+		c.publishEvent(EventReplaced, key, c.hashKey(key))
+	}
+	return previous, version
+}
+
+// CompareAndSwap replaces the value of key with newValue only if the current
+// value equals oldValue (per Config.ValueEqual, or == by default).
+// Returns true if the swap happened. A successful swap renews the entry TTL
+// and counts as a Set.
+func (c *wtinyLFUCache) CompareAndSwap(key string, oldValue, newValue interface{}) bool {
+	if key == "" {
+		return false
+	}
+
+	stored := newValue
+	if c.valueCodec != nil {
+		var ok bool
+		if stored, ok = c.encodeValue(key, newValue); !ok {
+			return false
+		}
+	}
+	if c.sizeLimited() && c.checkSize(key, stored) != nil {
+		return false
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now)
+	keyHash := c.hashKey(key)
+
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return false // Closed
+	}
+	defer t.leave(keyHash)
+	entry := c.acquireEntry(t, key, keyHash, ttlNow)
+	if entry == nil {
+		return false
+	}
+
+	var current interface{}
+	if holder, ok := entry.value.Load().(*valueHolder); ok && holder != nil {
+		current = c.decodedOrNil(holder.load())
+	}
+	if !c.safeValueEqual(current, oldValue) {
+		entry.release()
+		return false
+	}
+
+	var weight int32
+	if c.maxWeight > 0 {
+		var fits bool
+		if weight, fits = c.weigh(key, stored); !fits {
+			entry.release()
+			return false
+		}
+	}
+
+	c.incrementFrequency(keyHash)
+	c.replaceValue(t, entry, key, stored, weight, c.entryExpireAt(ttlNow))
+	if c.maxWeight > 0 {
+		c.enforceMaxWeight(t)
+	}
+	if c.memory != nil {
+		c.recordMemory(key, stored)
+	}
+
+	c.recordSetMetrics(key, start)
+	if c.propagate {
+		c.propagateWrite(key, newValue, c.ttlNanos)
+	}
+	return true
+}
+
+// Swap stores value for key and returns the previous value, if any.
+// loaded reports whether key was present (and not expired) before the call.
+// When absent, Swap behaves like Set. With Config.MaxWeight, a value heavier
+// than MaxWeight is not stored and the current value is left in place.
+func (c *wtinyLFUCache) Swap(key string, value interface{}) (previous interface{}, loaded bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	stored := value
+	if c.valueCodec != nil {
+		var ok bool
+		if stored, ok = c.encodeValue(key, value); !ok {
+			return nil, false
+		}
+	}
+	if c.sizeLimited() && c.checkSize(key, stored) != nil {
+		return nil, false
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now)
+	keyHash := c.hashKey(key)
+
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return nil, false // Closed
+	}
+	entry := c.acquireEntry(t, key, keyHash, ttlNow)
+	if entry == nil {
+		t.leave(keyHash)
+		c.Set(key, value)
+		return nil, false
+	}
+	defer t.leave(keyHash)
+
+	var weight int32
+	if c.maxWeight > 0 {
+		var fits bool
+		if weight, fits = c.weigh(key, stored); !fits {
+			if holder, ok := entry.value.Load().(*valueHolder); ok && holder != nil {
+				previous = c.decodedOrNil(holder.load())
+			}
+			entry.release()
+			return previous, true
+		}
+	}
+
+	c.incrementFrequency(keyHash)
+	previous, _ = c.replaceValue(t, entry, key, stored, weight, c.entryExpireAt(ttlNow))
+	if c.maxWeight > 0 {
+		c.enforceMaxWeight(t)
+	}
+	if c.memory != nil {
+		c.recordMemory(key, stored)
+	}
+
+	c.recordSetMetrics(key, start)
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return previous, true
+}
+
+// SetIfAbsent stores value for key only if key is absent or expired.
+// Returns true if the value was stored. Concurrent SetIfAbsent calls for the
+// same key store exactly one value; a concurrent Set either happens before
+// (SetIfAbsent returns false) or after (it overwrites the value). Returns
+// false as well when the value cannot be stored (see SetE) or no slot is
+// free in the probe chain of key.
+func (c *wtinyLFUCache) SetIfAbsent(key string, value interface{}) bool {
+	stored, err := c.prepareSet("SetIfAbsent", key, value)
+	if err != nil {
+		return false
+	}
+	var weight int32
+	if c.maxWeight > 0 {
+		weight, _ = c.weigh(key, stored)
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now)
+	keyHash := c.hashKey(key)
+	c.incrementFrequency(keyHash)
+
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return false // Closed
+	}
+	defer t.leave(keyHash)
+	entry, idx, existing, oldState := c.claimKey(t, key, keyHash, ttlNow)
+	if entry == nil {
+		return false
+	}
+	if existing {
+		c.releaseClaim(entry, true)
+		return false
+	}
+
+	c.insertClaimed(t, entry, idx, oldState, key, keyHash, value, stored, weight, start, ttlNow)
+	return true
+}
+
+// insertClaimed publishes value (stored once encoded, of the given weight) in
+// the slot of t at idx claimed by claimKey, with the bookkeeping of Set.
+// start is the start of the operation, for the latency metric (see
+// latencyStart). Returns the write version of the entry.
+func (c *wtinyLFUCache) insertClaimed(t *cacheTable, entry *entry, idx uint64, oldState int32, key string, keyHash uint64, value, stored interface{}, weight int32, start, ttlNow int64) uint64 {
+	version := c.populateEntry(t, entry, key, keyHash, stored, "", c.entryExpireAt(ttlNow), 0, weight, oldState)
+	c.recordSetMetrics(key, start)
+	if t.size() > c.capacity() {
+		c.makeRoomFor(t, idx)
+	}
+	if c.maxWeight > 0 {
+		c.enforceMaxWeight(t)
+	}
+	if c.namespaces != nil {
+		c.indexNamespace(key)
+	}
+	if c.memory != nil {
+		c.recordMemory(key, stored)
+	}
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return version
+}
+
+// CompareAndSwap replaces the value of key with newValue only if the current
+// value equals oldValue. Non-comparable value types (slices, maps) require
+// Config.ValueEqual; without it they never match.
+//
+// Returns true if the swap happened.
+func (c *GenericCache[K, V]) CompareAndSwap(key K, oldValue, newValue V) bool {
+	return c.inner.CompareAndSwap(keyToString(key), oldValue, newValue)
+}
+
+// Swap stores value for key and returns the previous value.
+// loaded reports whether key was present before the call.
+func (c *GenericCache[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	prev, loaded := c.inner.Swap(keyToString(key), value)
+	if !loaded {
+		return previous, false
+	}
+	typed, ok := prev.(V)
+	if !ok {
+		return previous, true
+	}
+	return typed, true
+}
+
+// SetIfAbsent stores value for key only if key is absent or expired.
+// Returns true if the value was stored.
+func (c *GenericCache[K, V]) SetIfAbsent(key K, value V) bool {
+	return c.inner.SetIfAbsent(keyToString(key), value)
+}
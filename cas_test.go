@@ -0,0 +1,317 @@
+// cas_test.go: tests for CompareAndSwap, Swap, SetIfAbsent and Config.ValueEqual
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompareAndSwap_Basic(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	if cache.CompareAndSwap("missing", nil, 1) {
+		t.Error("CAS on a missing key must fail")
+	}
+
+	cache.Set("k", 1)
+	if cache.CompareAndSwap("k", 2, 3) {
+		t.Error("CAS with a stale old value must fail")
+	}
+	if !cache.CompareAndSwap("k", 1, 2) {
+		t.Fatal("CAS with the current value must succeed")
+	}
+	if v, _ := cache.Get("k"); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+	if cache.CompareAndSwap("", 2, 3) {
+		t.Error("CAS on an empty key must fail")
+	}
+}
+
+func TestCompareAndSwap_NonComparableWithoutValueEqual(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("slice", []int{1, 2})
+
+	// Must not panic, and never matches
+	if cache.CompareAndSwap("slice", []int{1, 2}, []int{3}) {
+		t.Error("non-comparable values must not match without ValueEqual")
+	}
+
+	// Comparable static type holding a non-comparable dynamic value
+	type wrapper struct{ v interface{} }
+	cache.Set("wrapped", wrapper{v: []int{1}})
+	if cache.CompareAndSwap("wrapped", wrapper{v: []int{1}}, wrapper{}) {
+		t.Error("runtime-incomparable values must not match")
+	}
+
+	// The entry is still usable after the failed comparisons
+	if v, ok := cache.Get("slice"); !ok || len(v.([]int)) != 2 {
+		t.Errorf("entry corrupted after failed CAS: %v, %v", v, ok)
+	}
+}
+
+func TestCompareAndSwap_ValueEqual(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, ValueEqual: reflect.DeepEqual}).(*wtinyLFUCache)
+	cache.Set("m", map[string]int{"a": 1})
+
+	if !cache.CompareAndSwap("m", map[string]int{"a": 1}, map[string]int{"a": 2}) {
+		t.Fatal("deep-equal maps must match with ValueEqual")
+	}
+	if cache.CompareAndSwap("m", map[string]int{"a": 1}, map[string]int{"a": 3}) {
+		t.Error("stale map must not match")
+	}
+
+	// A panicking ValueEqual must not leave the entry locked
+	panicky := NewCache(Config{MaxSize: 100, ValueEqual: func(a, b interface{}) bool { panic("bad equal") }}).(*wtinyLFUCache)
+	panicky.Set("k", 1)
+	if panicky.CompareAndSwap("k", 1, 2) {
+		t.Error("panicking ValueEqual must report not equal")
+	}
+	if v, ok := panicky.Get("k"); !ok || v != 1 {
+		t.Errorf("entry not released after ValueEqual panic: %v, %v", v, ok)
+	}
+}
+
+func TestCompareAndSwap_ExpiredEntry(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+
+	mockTime.Advance(2 * time.Second)
+	if cache.CompareAndSwap("k", 1, 2) {
+		t.Error("CAS on an expired entry must fail")
+	}
+	if prev, loaded := cache.Swap("k", 3); loaded || prev != nil {
+		t.Errorf("Swap on an expired entry must not report it: %v, %v", prev, loaded)
+	}
+	if v, _ := cache.Get("k"); v != 3 {
+		t.Errorf("expected Swap to store 3, got %v", v)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	if prev, loaded := cache.Swap("k", "a"); loaded || prev != nil {
+		t.Errorf("first Swap: %v, %v", prev, loaded)
+	}
+	if prev, loaded := cache.Swap("k", "b"); !loaded || prev != "a" {
+		t.Errorf("second Swap: %v, %v", prev, loaded)
+	}
+	if v, _ := cache.Get("k"); v != "b" {
+		t.Errorf("expected b, got %v", v)
+	}
+	if stats := cache.Stats(); stats.Sets != 2 || stats.Size != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCompareAndSwap_ConcurrentCounter(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("counter", 0)
+
+	const goroutines, increments = 8, 500
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				for {
+					v, ok := cache.Get("counter")
+					if !ok {
+						continue // Entry momentarily pending under a concurrent swap
+					}
+					if cache.CompareAndSwap("counter", v, v.(int)+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := cache.Get("counter"); v != goroutines*increments {
+		t.Errorf("lost updates: expected %d, got %v", goroutines*increments, v)
+	}
+}
+
+func TestGenericCache_CompareAndSwap(t *testing.T) {
+	cache := NewGenericCache[string, []string](Config{
+		MaxSize:    100,
+		ValueEqual: reflect.DeepEqual,
+	})
+	cache.Set("tags", []string{"a"})
+
+	if !cache.CompareAndSwap("tags", []string{"a"}, []string{"a", "b"}) {
+		t.Fatal("expected CAS to succeed")
+	}
+	prev, loaded := cache.Swap("tags", nil)
+	if !loaded || !reflect.DeepEqual(prev, []string{"a", "b"}) {
+		t.Errorf("Swap returned %v, %v", prev, loaded)
+	}
+}
+
+// liveCopies counts the valid slots holding key.
+func liveCopies(c *wtinyLFUCache, key string) int {
+	copies := 0
+	for i := range c.table.Load().entries {
+		entry := &c.table.Load().entries[i]
+		if atomic.LoadInt32(&entry.valid) == entryValid && entry.loadKey() == key {
+			copies++
+		}
+	}
+	return copies
+}
+
+func TestSetIfAbsent_Basic(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	if !cache.SetIfAbsent("k", 1) {
+		t.Fatal("SetIfAbsent on a missing key must store")
+	}
+	if cache.SetIfAbsent("k", 2) {
+		t.Error("SetIfAbsent on a present key must not store")
+	}
+	if v, _ := cache.Get("k"); v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+
+	mockTime.Advance(2 * time.Second)
+	if !cache.SetIfAbsent("k", 3) {
+		t.Fatal("SetIfAbsent on an expired key must store")
+	}
+	if v, _ := cache.Get("k"); v != 3 {
+		t.Errorf("expected 3, got %v", v)
+	}
+	if stats := cache.Stats(); stats.Sets != 2 || stats.Size != 1 || stats.Expirations != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if cache.SetIfAbsent("", 1) {
+		t.Error("SetIfAbsent on an empty key must fail")
+	}
+	holdAllSlots(cache)
+	if cache.SetIfAbsent("other", 1) {
+		t.Error("SetIfAbsent succeeded with every slot busy")
+	}
+}
+
+func TestSetIfAbsent_ConcurrentSingleWinner(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	const rounds, goroutines = 200, 8
+	for r := 0; r < rounds; r++ {
+		key := fmt.Sprintf("key-%d", r)
+		var winners, winner int32
+		var wg sync.WaitGroup
+		for g := int32(0); g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int32) {
+				defer wg.Done()
+				if cache.SetIfAbsent(key, g) {
+					atomic.AddInt32(&winners, 1)
+					atomic.StoreInt32(&winner, g)
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		if winners != 1 {
+			t.Fatalf("%s: %d winners", key, winners)
+		}
+		if v, _ := cache.Get(key); v != winner {
+			t.Fatalf("%s: stored %v, winner %d", key, v, winner)
+		}
+		if copies := liveCopies(cache, key); copies != 1 {
+			t.Fatalf("%s: %d live copies", key, copies)
+		}
+	}
+}
+
+func TestSetIfAbsent_RaceWithDelete(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	var stored, deleted int64
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				if cache.SetIfAbsent("k", i) {
+					atomic.AddInt64(&stored, 1)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				if cache.Delete("k") {
+					atomic.AddInt64(&deleted, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Each stored value is deleted at most once, and only one is live
+	present := int64(liveCopies(cache, "k"))
+	if present > 1 || stored-deleted != present {
+		t.Fatalf("stored %d, deleted %d, live copies %d", stored, deleted, present)
+	}
+}
+
+func TestCompareAndSwap_OrderedWithSet(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+	cache.Set("k", 0)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				cache.CompareAndSwap("k", 0, 1)
+				cache.Swap("k", 0)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				cache.Set("k", 0)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if copies := liveCopies(cache, "k"); copies != 1 {
+		t.Fatalf("%d live copies of k", copies)
+	}
+}
+
+func TestGenericCache_SetIfAbsent(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 100})
+	defer cache.Close()
+
+	if !cache.SetIfAbsent(1, "a") || cache.SetIfAbsent(1, "b") {
+		t.Fatal("expected only the first SetIfAbsent to store")
+	}
+	if v, _ := cache.Get(1); v != "a" {
+		t.Errorf("expected a, got %v", v)
+	}
+}
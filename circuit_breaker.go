@@ -0,0 +1,280 @@
+// circuit_breaker.go: fail-fast loading during backend outages
+//
+// With Config.CircuitBreakerThreshold, GetOrLoad fails fast with
+// BALIOS_CIRCUIT_OPEN after that many consecutive load failures.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCircuitBreakerCooldown is the default time a circuit stays open
+// before a probe load is allowed.
+const DefaultCircuitBreakerCooldown = 5 * time.Second
+
+// CircuitState is the state of a loader circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets loads through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails loads fast with BALIOS_CIRCUIT_OPEN.
+	CircuitOpen
+
+	// CircuitHalfOpen lets a single probe load through.
+	CircuitHalfOpen
+)
+
+// String returns the state name.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerRecorder is an optional MetricsCollector extension.
+// Collectors implementing it are notified of every state change of a loader
+// circuit breaker (see Config.CircuitBreakerThreshold).
+type CircuitBreakerRecorder interface {
+	// RecordCircuitState records that the circuit of family entered state.
+	// family is empty for the circuit of Config.CircuitBreakerPerCache.
+	RecordCircuitState(family string, state CircuitState)
+}
+
+// circuit is the breaker state of one family.
+type circuit struct {
+	mu       sync.Mutex
+	state    CircuitState
+	failures int   // Consecutive failures (closed)
+	since    int64 // Start of the failure streak (closed) or opening time (open, half-open)
+	probing  bool  // A half-open probe is running
+}
+
+// loadBreaker holds the circuits of a cache.
+type loadBreaker struct {
+	threshold  int
+	window     int64 // Nanoseconds; 0 = failures never go stale
+	cooldown   int64 // Nanoseconds
+	family     func(string) string
+	circuits   sync.Map // family -> *circuit
+	count      int64    // Approximate number of circuits
+	maxCircuit int64
+	sweeping   int32
+}
+
+// newLoadBreaker returns nil when the circuit breaker is disabled.
+func newLoadBreaker(config Config) *loadBreaker {
+	if config.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+	cooldown := config.CircuitBreakerCooldown
+	if cooldown <= 0 {
+		cooldown = DefaultCircuitBreakerCooldown
+	}
+	window := config.CircuitBreakerWindow
+	if window < 0 {
+		window = 0
+	}
+	family := config.KeyFamily
+	switch {
+	case config.CircuitBreakerPerCache:
+		family = func(string) string { return "" }
+	case family == nil:
+		family = func(key string) string { return key }
+	}
+	return &loadBreaker{
+		threshold:  config.CircuitBreakerThreshold,
+		window:     int64(window),
+		cooldown:   int64(cooldown),
+		family:     family,
+		maxCircuit: int64(4 * config.MaxSize),
+	}
+}
+
+// allow reports whether the loader of family may run at time now. When it
+// may not, retryAfter is the remaining cooldown. changed is set when the
+// call moved the circuit to half-open.
+func (b *loadBreaker) allow(family string, now int64) (ok, changed bool, retryAfter time.Duration) {
+	v, found := b.circuits.Load(family)
+	if !found {
+		return true, false, 0
+	}
+	cb := v.(*circuit)
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitClosed:
+		return true, false, 0
+	case CircuitOpen:
+		if wait := cb.since + b.cooldown - now; wait > 0 {
+			return false, false, time.Duration(wait)
+		}
+		cb.state, cb.probing = CircuitHalfOpen, true
+		return true, true, 0
+	default: // Half-open: one probe at a time
+		if cb.probing {
+			return false, false, 0
+		}
+		cb.probing = true
+		return true, false, 0
+	}
+}
+
+// record accounts the outcome of a load of family allowed by allow. It
+// returns the new state and whether it changed.
+func (b *loadBreaker) record(family string, failed bool, now int64) (CircuitState, bool) {
+	v, found := b.circuits.Load(family)
+	if !found {
+		if !failed {
+			return CircuitClosed, false
+		}
+		var loaded bool
+		v, loaded = b.circuits.LoadOrStore(family, &circuit{})
+		if !loaded && atomic.AddInt64(&b.count, 1) > b.maxCircuit {
+			b.sweep(now)
+		}
+	}
+	cb := v.(*circuit)
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	previous := cb.state
+	switch {
+	case !failed:
+		// Success closes the circuit and forgets the streak
+		cb.state, cb.failures, cb.probing = CircuitClosed, 0, false
+		if b.circuits.CompareAndDelete(family, cb) {
+			atomic.AddInt64(&b.count, -1)
+		}
+	case cb.state == CircuitClosed:
+		if cb.failures == 0 || (b.window > 0 && now-cb.since > b.window) {
+			cb.failures, cb.since = 0, now
+		}
+		cb.failures++
+		if cb.failures >= b.threshold {
+			cb.state, cb.since = CircuitOpen, now
+		}
+	default: // Failed probe, or a load started before the circuit opened
+		cb.state, cb.since, cb.probing = CircuitOpen, now, false
+	}
+	return cb.state, cb.state != previous
+}
+
+// release gives up the half-open probe granted to a load that did not run.
+func (b *loadBreaker) release(family string) {
+	if v, found := b.circuits.Load(family); found {
+		cb := v.(*circuit)
+		cb.mu.Lock()
+		cb.probing = false
+		cb.mu.Unlock()
+	}
+}
+
+// sweep removes closed circuits whose failure streak is stale. Only one
+// goroutine sweeps at a time; others proceed without waiting.
+func (b *loadBreaker) sweep(now int64) {
+	if !atomic.CompareAndSwapInt32(&b.sweeping, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&b.sweeping, 0)
+
+	stale := b.window
+	if stale < b.cooldown {
+		stale = b.cooldown
+	}
+	b.circuits.Range(func(key, value interface{}) bool {
+		cb := value.(*circuit)
+		cb.mu.Lock()
+		expired := cb.state == CircuitClosed && now-cb.since > stale
+		cb.mu.Unlock()
+		if expired && b.circuits.CompareAndDelete(key, cb) {
+			atomic.AddInt64(&b.count, -1)
+		}
+		return true
+	})
+}
+
+// allowCircuit checks the circuit breaker of key. It returns a
+// BALIOS_CIRCUIT_OPEN error if the loader must not run.
+func (c *wtinyLFUCache) allowCircuit(key string) error {
+	if c.breaker == nil {
+		return nil
+	}
+	family := c.breaker.family(key)
+	ok, changed, retryAfter := c.breaker.allow(family, c.timeProvider.Now())
+	if changed {
+		c.circuitChanged(family, CircuitHalfOpen)
+	}
+	if !ok {
+		atomic.AddInt64(&c.circuitRejections, 1)
+		return NewErrCircuitOpen(key, family, retryAfter)
+	}
+	return nil
+}
+
+// releaseCircuit undoes allowCircuit for a load that will not run.
+func (c *wtinyLFUCache) releaseCircuit(key string) {
+	if c.breaker != nil {
+		c.breaker.release(c.breaker.family(key))
+	}
+}
+
+// recordCircuit accounts the outcome of a load allowed by allowCircuit.
+func (c *wtinyLFUCache) recordCircuit(key string, err error) {
+	if c.breaker == nil {
+		return
+	}
+	failed := err != nil && !errors.Is(err, context.Canceled) && !IsNotModified(err)
+	family := c.breaker.family(key)
+	if state, changed := c.breaker.record(family, failed, c.timeProvider.Now()); changed {
+		c.circuitChanged(family, state)
+	}
+}
+
+// circuitChanged reports a circuit state change.
+func (c *wtinyLFUCache) circuitChanged(family string, state CircuitState) {
+	if state == CircuitOpen {
+		c.logger.Warn("balios: loader circuit opened", "family", family)
+	} else {
+		c.logger.Debug("balios: loader circuit state changed", "family", family, "state", state.String())
+	}
+	if c.circuitRecorder != nil {
+		c.circuitRecorder.RecordCircuitState(family, state)
+	}
+}
+
+// circuitRecorderOf returns the CircuitBreakerRecorder of a collector built
+// by newGuardedMetricsCollector, or nil when the collector does not implement it.
+func circuitRecorderOf(collector MetricsCollector) CircuitBreakerRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.circuit != nil {
+		return g
+	}
+	return nil
+}
+
+// RecordCircuitState forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordCircuitState(family string, state CircuitState) {
+	if g.circuit == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordCircuitState")
+	g.circuit.RecordCircuitState(family, state)
+}
@@ -0,0 +1,213 @@
+// circuit_breaker_test.go: tests for the loader circuit breaker
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// circuitCollector records circuit state changes.
+type circuitCollector struct {
+	NoOpMetricsCollector
+	mu     sync.Mutex
+	states []string
+}
+
+func (r *circuitCollector) RecordCircuitState(family string, state CircuitState) {
+	r.mu.Lock()
+	r.states = append(r.states, family+":"+state.String())
+	r.mu.Unlock()
+}
+
+// failingLoader fails while *down is true and counts its calls.
+func failingLoader(down *bool, calls *int) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		*calls++
+		if *down {
+			return nil, errors.New("database unreachable")
+		}
+		return "value", nil
+	}
+}
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	collector := &circuitCollector{}
+	cache := NewCache(Config{
+		MaxSize:                 100,
+		TimeProvider:            mockTime,
+		MetricsCollector:        collector,
+		CircuitBreakerThreshold: 3,
+		CircuitBreakerCooldown:  10 * time.Second,
+	})
+	defer cache.Close()
+
+	down, calls := true, 0
+	loader := failingLoader(&down, &calls)
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetOrLoad("k", loader); err == nil || IsCircuitOpen(err) {
+			t.Fatalf("load %d: expected the loader error, got %v", i, err)
+		}
+	}
+
+	// Open: fail fast without calling the loader
+	_, err := cache.GetOrLoad("k", loader)
+	if !IsCircuitOpen(err) || calls != 3 {
+		t.Fatalf("expected BALIOS_CIRCUIT_OPEN without a loader call, got %v after %d calls", err, calls)
+	}
+	if GetErrorCode(err) != ErrCodeCircuitOpen {
+		t.Fatalf("unexpected code %v", GetErrorCode(err))
+	}
+	if stats := cache.Stats(); stats.CircuitRejections != 1 {
+		t.Fatalf("CircuitRejections = %d, want 1", stats.CircuitRejections)
+	}
+
+	// Failed probe: open again for a full cooldown
+	mockTime.Advance(10 * time.Second)
+	if _, err := cache.GetOrLoad("k", loader); err == nil || IsCircuitOpen(err) || calls != 4 {
+		t.Fatalf("expected a failed probe, got %v after %d calls", err, calls)
+	}
+	mockTime.Advance(5 * time.Second)
+	if _, err := cache.GetOrLoad("k", loader); !IsCircuitOpen(err) {
+		t.Fatalf("a failed probe must reopen the circuit, got %v", err)
+	}
+
+	// Successful probe closes it
+	down = false
+	mockTime.Advance(5 * time.Second)
+	if v, err := cache.GetOrLoad("k", loader); err != nil || v != "value" {
+		t.Fatalf("probe = %v, %v", v, err)
+	}
+	cache.Delete("k")
+	if _, err := cache.GetOrLoad("k", loader); err != nil {
+		t.Fatalf("closed circuit rejected a load: %v", err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	want := "k:open k:half-open k:open k:half-open k:closed"
+	if got := strings.Join(collector.states, " "); got != want {
+		t.Fatalf("state changes %q, want %q", got, want)
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsStreak(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, CircuitBreakerThreshold: 2})
+	defer cache.Close()
+
+	down, calls := true, 0
+	loader := failingLoader(&down, &calls)
+	cache.GetOrLoad("k", loader)
+	down = false
+	cache.GetOrLoad("k", loader)
+	cache.Delete("k")
+	down = true
+	if _, err := cache.GetOrLoad("k", loader); IsCircuitOpen(err) {
+		t.Fatal("a success must reset the failure streak")
+	}
+	if _, err := cache.GetOrLoad("k", loader); IsCircuitOpen(err) {
+		t.Fatal("the second failure of the streak must still call the loader")
+	}
+	if _, err := cache.GetOrLoad("k", loader); !IsCircuitOpen(err) {
+		t.Fatalf("expected an open circuit, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_Window(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{
+		MaxSize:                 100,
+		TimeProvider:            mockTime,
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerWindow:    time.Second,
+	})
+	defer cache.Close()
+
+	down, calls := true, 0
+	loader := failingLoader(&down, &calls)
+	cache.GetOrLoad("k", loader)
+	mockTime.Advance(2 * time.Second)
+	if _, err := cache.GetOrLoad("k", loader); IsCircuitOpen(err) {
+		t.Fatal("failures further apart than the window must not open the circuit")
+	}
+	cache.GetOrLoad("k", loader) // Second failure of the new streak
+	if _, err := cache.GetOrLoad("k", loader); !IsCircuitOpen(err) {
+		t.Fatalf("expected an open circuit, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_Scope(t *testing.T) {
+	down, calls := true, 0
+	loader := failingLoader(&down, &calls)
+
+	perKey := NewCache(Config{MaxSize: 100, CircuitBreakerThreshold: 1})
+	defer perKey.Close()
+	perKey.GetOrLoad("a", loader)
+	if _, err := perKey.GetOrLoad("b", loader); IsCircuitOpen(err) {
+		t.Fatal("the circuit of a must not reject b")
+	}
+
+	perCache := NewCache(Config{MaxSize: 100, CircuitBreakerThreshold: 1, CircuitBreakerPerCache: true})
+	defer perCache.Close()
+	perCache.GetOrLoad("a", loader)
+	if _, err := perCache.GetOrLoad("b", loader); !IsCircuitOpen(err) {
+		t.Fatalf("a per-cache circuit must reject every key, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCancellation(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, CircuitBreakerThreshold: 1})
+	defer cache.Close()
+
+	cache.GetOrLoadWithContext(context.Background(), "k", func(context.Context) (interface{}, error) {
+		return nil, context.Canceled
+	})
+	if _, err := cache.GetOrLoadWithContext(context.Background(), "k", func(context.Context) (interface{}, error) {
+		return "value", nil
+	}); err != nil {
+		t.Fatalf("a canceled load must not count as a failure: %v", err)
+	}
+}
+
+func TestCircuitBreaker_SingleProbe(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{
+		MaxSize:                 100,
+		TimeProvider:            mockTime,
+		CircuitBreakerThreshold: 1,
+		CircuitBreakerCooldown:  time.Second,
+	}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.GetOrLoad("k", func() (interface{}, error) { return nil, errors.New("down") })
+	mockTime.Advance(time.Second)
+
+	// The first caller gets the probe; the circuit rejects others until it reports
+	if err := cache.allowLoad("k"); err != nil {
+		t.Fatalf("expected the probe to be allowed, got %v", err)
+	}
+	if err := cache.allowLoad("k"); !IsCircuitOpen(err) {
+		t.Fatalf("a second probe must be rejected, got %v", err)
+	}
+	cache.recordCircuit("k", nil)
+	if err := cache.allowLoad("k"); err != nil {
+		t.Fatalf("closed circuit rejected a load: %v", err)
+	}
+}
+
+func TestCircuitState_String(t *testing.T) {
+	for state, want := range map[CircuitState]string{CircuitClosed: "closed", CircuitOpen: "open", CircuitHalfOpen: "half-open", CircuitState(9): "unknown"} {
+		if got := state.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", int(state), got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// clear_consistency_test.go: tests for Clear racing with concurrent writers
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// liveSlots counts the live slots of the table.
+func liveSlots(c *wtinyLFUCache) (count int, weight int64) {
+	for i := range c.table.Load().entries {
+		if e := &c.table.Load().entries[i]; c.isLive(e) {
+			count++
+			weight += int64(atomic.LoadInt32(&e.weight))
+		}
+	}
+	return count, weight
+}
+
+func TestClear_SizeExactUnderConcurrentWriters(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		cache := NewCache(Config{
+			MaxSize:   512,
+			MaxWeight: 1 << 40,
+			Weigher:   func(key string, value interface{}) int64 { return 3 },
+		}).(*wtinyLFUCache)
+
+		var stop int32
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; atomic.LoadInt32(&stop) == 0; i++ {
+					key := "k" + strconv.Itoa((g*131+i)%300)
+					if i%4 == 3 {
+						cache.Delete(key)
+					} else {
+						cache.Set(key, i)
+					}
+				}
+			}(g)
+		}
+		for i := 0; i < 30; i++ {
+			cache.Clear()
+		}
+		atomic.StoreInt32(&stop, 1)
+		wg.Wait()
+
+		live, weight := liveSlots(cache)
+		if size := cache.entryCount(); size != int64(live) {
+			t.Fatalf("round %d: size counter %d, table holds %d live entries", round, size, live)
+		}
+		if got := cache.totalWeight(); got != weight {
+			t.Fatalf("round %d: weight counter %d, live entries weigh %d", round, got, weight)
+		}
+		for i := range cache.table.Load().entries {
+			e := &cache.table.Load().entries[i]
+			if cache.isLive(e) && e.loadKey() == "" {
+				t.Fatalf("round %d: valid slot %d with an empty key", round, i)
+			}
+		}
+		_ = cache.Close()
+	}
+}
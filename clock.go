@@ -0,0 +1,124 @@
+// clock.go: time sources and clock-skew protection for TTL handling
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// maxClockRegression is the largest backwards clock jump the cache absorbs by
+// freezing its TTL clock at the highest time observed so far.
+//
+// DESIGN RATIONALE:
+//   - Small regressions (NTP slews, VM migrations) are hidden completely: TTL time
+//     simply stands still until the time source catches up again
+//   - Larger regressions (manual clock changes, NTP steps) rebase the TTL clock
+//     instead of freezing expiration for an unbounded period
+//   - After a rebase, isExpired clamps any entry whose deadline lies more than one
+//     TTL in the future, so no entry can outlive its TTL by more than one period
+//   - The TTL of the clamp is the entry's own (see ttlBound): a long per-call TTL
+//     does not loosen the clamp of the other entries
+const maxClockRegression = int64(time.Second)
+
+// MonotonicTimeProvider is a TimeProvider that never goes backwards.
+//
+// It is anchored to the wall clock once at construction and then advances using
+// Go's monotonic clock, so system clock adjustments (NTP steps, manual changes,
+// leap-second smearing) cannot instantly expire entries or extend their lifetime.
+//
+// Use it when the host clock is known to be unstable:
+//
+//	cache := balios.NewCache(balios.Config{
+//	    TTL:          time.Minute,
+//	    TimeProvider: balios.NewMonotonicTimeProvider(),
+//	})
+//
+// Thread-safety: Safe for concurrent use.
+// Performance: ~20ns per call (runtime monotonic clock read), zero allocations.
+type MonotonicTimeProvider struct {
+	start time.Time // carries the monotonic clock reading
+	base  int64     // wall clock in nanoseconds at construction
+}
+
+// NewMonotonicTimeProvider creates a MonotonicTimeProvider anchored at the current wall time.
+func NewMonotonicTimeProvider() *MonotonicTimeProvider {
+	now := time.Now()
+	return &MonotonicTimeProvider{
+		start: now,
+		base:  now.UnixNano(),
+	}
+}
+
+// Now returns the anchor wall time plus the monotonic time elapsed since construction.
+func (m *MonotonicTimeProvider) Now() int64 {
+	return m.base + int64(time.Since(m.start))
+}
+
+// ttlClock returns the time used for expiration decisions, protected against
+// backwards jumps of the configured TimeProvider.
+//
+// The cache keeps a high-water mark of observed time. Readings slightly behind it
+// (within maxClockRegression) are replaced by the high-water mark so expiration
+// never moves backwards; larger regressions rebase the mark to the new reading.
+//
+// Zero overhead when TTL is disabled (no default or per-entry TTL in use).
+func (c *wtinyLFUCache) ttlClock(now int64) int64 {
+	if atomic.LoadInt64(&c.maxTTLNanos) == 0 {
+		return now
+	}
+
+	last := atomic.LoadInt64(&c.clockHighWater)
+	if now > last {
+		// Single CAS attempt: losing the race means another goroutine published
+		// an equal or newer reading, which is just as good for our purposes
+		atomic.CompareAndSwapInt64(&c.clockHighWater, last, now)
+		return now
+	}
+
+	if last-now <= maxClockRegression {
+		// Small regression: freeze TTL time at the high-water mark
+		return last
+	}
+
+	// Large regression: rebase instead of freezing expiration indefinitely.
+	// Entries written before the jump are clamped lazily by isExpired.
+	atomic.CompareAndSwapInt64(&c.clockHighWater, last, now)
+	return now
+}
+
+// longTTL returns ttlNanos if it exceeds ttlBoundNanos, the clock regression
+// bound of every default write, and 0 otherwise. Values written with a long
+// TTL are tagged with it (see newValueHolder).
+func (c *wtinyLFUCache) longTTL(ttlNanos int64) int64 {
+	if ttlNanos > c.ttlBoundNanos {
+		return ttlNanos
+	}
+	return 0
+}
+
+// ttlBound returns the longest TTL entry can have been written with: its long
+// TTL if tagged with one, ttlBoundNanos otherwise. Without a default TTL an
+// untagged deadline (its value replaced meanwhile) falls back to the largest
+// TTL in use.
+func (c *wtinyLFUCache) ttlBound(entry *entry) int64 {
+	if ttl := holderTTL(entry); ttl > 0 {
+		return ttl
+	}
+	if c.ttlBoundNanos > 0 {
+		return c.ttlBoundNanos
+	}
+	return atomic.LoadInt64(&c.maxTTLNanos)
+}
+
+// holderTTL returns the long TTL of the value of entry (0 if none).
+func holderTTL(entry *entry) int64 {
+	if holder, ok := entry.value.Load().(*valueHolder); ok && holder != nil {
+		return holder.ttl()
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+// clock_test.go: tests for MonotonicTimeProvider and clock-skew protection
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMonotonicTimeProvider_NeverGoesBackwards verifies monotonicity and wall-clock anchoring.
+func TestMonotonicTimeProvider_NeverGoesBackwards(t *testing.T) {
+	provider := NewMonotonicTimeProvider()
+
+	wall := time.Now().UnixNano()
+	first := provider.Now()
+	if diff := first - wall; diff > int64(time.Second) || diff < -int64(time.Second) {
+		t.Errorf("provider not anchored to wall clock: diff=%v", time.Duration(diff))
+	}
+
+	prev := first
+	for i := 0; i < 10000; i++ {
+		now := provider.Now()
+		if now < prev {
+			t.Fatalf("time went backwards: %d < %d", now, prev)
+		}
+		prev = now
+	}
+}
+
+// TestClockSkew_SmallRegressionFreezesTTL verifies that a small backwards jump
+// neither extends entries nor causes them to expire early once time recovers.
+func TestClockSkew_SmallRegressionFreezesTTL(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: 10 * int64(time.Second)}
+	cache := NewCache(Config{
+		MaxSize:      100,
+		TTL:          100 * time.Millisecond,
+		TimeProvider: mockTime,
+	})
+
+	cache.Set("before", "v")
+
+	// Clock steps back 500ms; an entry written now must not be dated in the past
+	mockTime.currentTime -= int64(500 * time.Millisecond)
+	cache.Set("during", "v")
+
+	// Clock is corrected forward again: "during" must still be alive
+	mockTime.currentTime += int64(500 * time.Millisecond)
+	if _, found := cache.Get("during"); !found {
+		t.Error("entry written during clock regression expired instantly after correction")
+	}
+
+	// Both entries expire one TTL after the high-water mark
+	mockTime.Advance(150 * time.Millisecond)
+	if _, found := cache.Get("before"); found {
+		t.Error("entry written before regression should have expired")
+	}
+	if _, found := cache.Get("during"); found {
+		t.Error("entry written during regression should have expired")
+	}
+}
+
+// TestClockSkew_LargeRegressionBoundsLifetime verifies that a large backwards
+// jump cannot extend an entry's lifetime beyond one TTL.
+func TestClockSkew_LargeRegressionBoundsLifetime(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{
+		MaxSize:      100,
+		TTL:          100 * time.Millisecond,
+		TimeProvider: mockTime,
+	})
+
+	cache.Set("key", "value")
+
+	// Clock steps back by 30 minutes: without the guard the entry would live
+	// for 30 more minutes
+	mockTime.currentTime -= int64(30 * time.Minute)
+	if _, found := cache.Get("key"); !found {
+		t.Fatal("entry should still be visible right after the regression")
+	}
+
+	mockTime.Advance(150 * time.Millisecond)
+	if _, found := cache.Get("key"); found {
+		t.Error("entry outlived its TTL after a large clock regression")
+	}
+}
+
+// TestClockSkew_LongTTLDoesNotLoosenBound verifies that a long per-call TTL
+// does not extend the clamp of the entries written with the default TTL.
+func TestClockSkew_LongTTLDoesNotLoosenBound(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{
+		MaxSize:      100,
+		TTL:          100 * time.Millisecond,
+		TimeProvider: mockTime,
+	})
+
+	_, err := cache.GetOrLoad("long", func() (interface{}, error) {
+		return "value", nil
+	}, WithTTL(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("short", "value")
+
+	mockTime.currentTime -= int64(30 * time.Minute)
+	cache.Get("short")
+	cache.Get("long")
+
+	mockTime.Advance(150 * time.Millisecond)
+	if _, found := cache.Get("short"); found {
+		t.Error("a per-call TTL of one hour let a 100ms entry outlive its TTL")
+	}
+	if _, found := cache.Get("long"); !found {
+		t.Error("entry with a one hour TTL expired after 150ms")
+	}
+
+	// The long entry is clamped to its own TTL
+	mockTime.Advance(time.Hour)
+	if _, found := cache.Get("long"); found {
+		t.Error("entry with a one hour TTL outlived it after a clock regression")
+	}
+}
+
+// TestClockSkew_NoTTLIsUnaffected verifies the guard is inert without TTL.
+func TestClockSkew_NoTTLIsUnaffected(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{
+		MaxSize:      100,
+		TimeProvider: mockTime,
+	})
+
+	cache.Set("key", "value")
+	mockTime.currentTime -= int64(30 * time.Minute)
+	if _, found := cache.Get("key"); !found {
+		t.Error("entries without TTL must not be affected by clock changes")
+	}
+}
+
+// TestDefaultTimeResolution verifies that the default clock is the coarse
+// go-timecache clock.
+func TestDefaultTimeResolution(t *testing.T) {
+	if r := DefaultTimeResolution(); r <= 0 || r > time.Millisecond {
+		t.Fatalf("DefaultTimeResolution() = %v, want a sub-millisecond cached clock", r)
+	}
+}
@@ -0,0 +1,45 @@
+// close.go: closed state and memory release
+//
+// Close stops the background goroutines, reports the remaining entries to
+// OnEvict and releases the table. Later operations are no-ops.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// Closed reports whether Close has been called. Useful for health checks.
+func (c *wtinyLFUCache) Closed() bool {
+	return c.isClosed()
+}
+
+// isClosed reports whether Close has been called.
+func (c *wtinyLFUCache) isClosed() bool {
+	return atomic.LoadInt32(&c.closed) != 0
+}
+
+// startBackground runs f in a goroutine that Close waits for.
+func (c *wtinyLFUCache) startBackground(f func()) {
+	c.background.Add(1)
+	go func() {
+		defer c.background.Done()
+		f()
+	}()
+}
+
+// whileOpen runs f unless the cache is closed. Used by the writes the cache
+// makes on its own (refresh-ahead, received invalidations), which no caller
+// can order with Close.
+func (c *wtinyLFUCache) whileOpen(f func()) {
+	if !c.isClosed() {
+		f()
+	}
+}
+
+// Closed reports whether Close has been called.
+func (c *GenericCache[K, V]) Closed() bool {
+	return c.inner.Closed()
+}
@@ -0,0 +1,247 @@
+// close_test.go: tests for the closed state and memory release
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClose_ReleasesMemory(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, AdaptiveWindow: true}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+
+	if cache.Closed() {
+		t.Fatal("a new cache must not be closed")
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !cache.Closed() {
+		t.Error("Closed() = false after Close")
+	}
+	if cache.table.Load() != nil {
+		t.Error("Close must release the table, the sketch and the admission window")
+	}
+	if err := cache.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestClose_ReportsRemainingEntries(t *testing.T) {
+	var evicted int64
+	cache := NewCache(Config{
+		MaxSize: 100,
+		OnEvict: func(key string, value interface{}, reason EvictReason) {
+			if reason == ReasonDeleted {
+				atomic.AddInt64(&evicted, 1)
+			}
+		},
+	})
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	_ = cache.Close()
+	_ = cache.Close()
+	if n := atomic.LoadInt64(&evicted); n != 2 {
+		t.Errorf("OnEvict calls = %d, want 2 (once per entry)", n)
+	}
+}
+
+func TestClose_OperationsAreNoOps(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Minute, MemoryAccounting: true}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+	_ = cache.Close()
+
+	if cache.Set("a", 2) || cache.SetWithSource("a", 2, "test") {
+		t.Error("Set must fail after Close")
+	}
+	if _, found := cache.Get("a"); found {
+		t.Error("Get must miss after Close")
+	}
+	if cache.Has("a") || cache.Delete("a") {
+		t.Error("Has and Delete must report nothing after Close")
+	}
+	if cache.CompareAndSwap("a", 1, 2) {
+		t.Error("CompareAndSwap must fail after Close")
+	}
+	if _, loaded := cache.Swap("a", 2); loaded {
+		t.Error("Swap must not find a previous value after Close")
+	}
+	if n := cache.SetMany(map[string]interface{}{"x": 1}); n != 0 {
+		t.Errorf("SetMany stored %d entries after Close", n)
+	}
+	if got := cache.GetMany([]string{"a"}); len(got) != 0 {
+		t.Errorf("GetMany = %v after Close", got)
+	}
+	if _, ok := cache.SourceOf("a"); ok {
+		t.Error("SourceOf must miss after Close")
+	}
+
+	cache.Clear()
+	cache.DecaySketch()
+	cache.Range(func(string, interface{}) bool {
+		t.Error("Range visited an entry after Close")
+		return false
+	})
+	if len(cache.Keys()) != 0 || cache.ExpireNow() != 0 || cache.DeleteByPrefix("a") != 0 {
+		t.Error("table walks must find nothing after Close")
+	}
+	if _, ok := cache.NextExpiration(); ok {
+		t.Error("NextExpiration must find nothing after Close")
+	}
+	if report := cache.Compact(); report.Slots != 0 {
+		t.Errorf("Compact scanned %d slots after Close", report.Slots)
+	}
+	if report := cache.MemoryUsage(10); report.Entries != 0 {
+		t.Errorf("MemoryUsage = %+v after Close", report)
+	}
+	if stats := cache.Stats(); stats.Size != 0 {
+		t.Errorf("Stats().Size = %d after Close", stats.Size)
+	}
+}
+
+func TestClose_ErrorsAreCacheClosed(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	_ = cache.Close()
+
+	called := false
+	loader := func() (interface{}, error) {
+		called = true
+		return 1, nil
+	}
+	ctx := context.Background()
+
+	_, errLoad := cache.GetOrLoad("a", loader)
+	_, errLoadCtx := cache.GetOrLoadWithContext(ctx, "a", func(context.Context) (interface{}, error) { return loader() })
+	_, errMany := cache.GetOrLoadMany([]string{"a"}, func([]string) (map[string]interface{}, error) {
+		called = true
+		return nil, nil
+	})
+	_, errGetE := cache.GetE("a")
+	_, _, errGetCtx := cache.GetCtx(ctx, "a")
+	errSetCtx := cache.SetCtx(ctx, "a", 1)
+	_, errSave := cache.SaveTo(&bytes.Buffer{})
+	_, errRestore := cache.LoadFrom(&bytes.Buffer{})
+
+	for name, err := range map[string]error{
+		"GetOrLoad":            errLoad,
+		"GetOrLoadWithContext": errLoadCtx,
+		"GetOrLoadMany":        errMany,
+		"GetE":                 errGetE,
+		"GetCtx":               errGetCtx,
+		"SetCtx":               errSetCtx,
+		"SaveTo":               errSave,
+		"LoadFrom":             errRestore,
+	} {
+		if !IsCacheClosed(err) {
+			t.Errorf("%s after Close: err = %v, want BALIOS_CACHE_CLOSED", name, err)
+		}
+		if !IsOperationError(err) {
+			t.Errorf("%s: BALIOS_CACHE_CLOSED must be an operation error", name)
+		}
+	}
+	if called {
+		t.Error("loaders must not be called after Close")
+	}
+}
+
+func TestClose_StopsBackgroundGoroutines(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:             100,
+		TTL:                 time.Millisecond,
+		CleanupInterval:     time.Millisecond,
+		SketchDecayInterval: time.Millisecond,
+		ShrinkAfter:         time.Millisecond,
+		FrozenIndexInterval: time.Millisecond,
+		StatsWindow:         60 * time.Millisecond,
+	}).(*wtinyLFUCache)
+	for i := 0; i < 50; i++ {
+		cache.Set(string(rune('a'+i%26))+"key", i)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		_ = cache.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return: a background goroutine was not stopped")
+	}
+	// Close returns only after the goroutines exited
+	cache.background.Wait()
+}
+
+func TestClose_DuringOperations(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		cache := NewCache(Config{
+			MaxSize:          64,
+			TTL:              time.Millisecond,
+			TTI:              time.Millisecond,
+			TrackEntryTimes:  true,
+			TrackMissReasons: true,
+			AdaptiveWindow:   true,
+		}).(*wtinyLFUCache)
+
+		var wg sync.WaitGroup
+		var stop atomic.Bool
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; !stop.Load(); i++ {
+					key := fmt.Sprintf("k%d", (i*4+g)%256)
+					cache.Set(key, i)
+					cache.Get(key)
+					cache.Has(key)
+					cache.CompareAndSwap(key, i, i+1)
+					if i%8 == 0 {
+						cache.Delete(key)
+					}
+				}
+			}(g)
+		}
+
+		time.Sleep(time.Millisecond)
+		_ = cache.Close() // Must neither race with nor crash the writers
+		time.Sleep(time.Millisecond)
+		stop.Store(true)
+		wg.Wait()
+
+		if cache.Set("late", 1) {
+			t.Fatal("Set succeeded after Close")
+		}
+	}
+}
+
+func TestClose_Generic(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+	cache.Set("a", 1)
+	_ = cache.Close()
+
+	if !cache.Closed() {
+		t.Error("Closed() = false after Close")
+	}
+	if _, err := cache.GetOrLoad("a", func() (int, error) { return 1, nil }); !IsCacheClosed(err) {
+		t.Errorf("GetOrLoad after Close: err = %v, want BALIOS_CACHE_CLOSED", err)
+	}
+}
+
+func TestNewErrCacheClosed(t *testing.T) {
+	err := NewErrCacheClosed("Get")
+	if !IsCacheClosed(err) || IsRetryable(err) {
+		t.Errorf("unexpected error classification: %v", err)
+	}
+}
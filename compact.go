@@ -0,0 +1,206 @@
+// compact.go: retained-memory report and compaction
+//
+// Dead slots keep their last key and value reachable until reused.
+// CompactionReport measures that memory and Compact releases it.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// oversizedValueFactor flags []byte values whose capacity exceeds their length
+// by this factor as oversized (e.g. slices of a larger read buffer).
+const oversizedValueFactor = 2
+
+// emptyValueHolder replaces the holder of compacted dead slots.
+// It keeps the atomic.Value concrete type (*valueHolder) consistent.
+var emptyValueHolder = &valueHolder{}
+
+// CompactionReport describes memory retained by a cache beyond its live data.
+//
+// Byte counts are estimates: they only account for key bytes and for string
+// and []byte values, whose sizes can be determined without reflection.
+type CompactionReport struct {
+	// Slots is the number of table slots scanned.
+	Slots int
+
+	// LiveEntries is the number of valid entries observed.
+	LiveEntries int
+
+	// DeadSlots is the number of deleted or empty slots still referencing a
+	// value or key (tombstones of Delete/eviction/expiration, slots reset by Clear).
+	DeadSlots int
+
+	// RetainedKeyBytes is the key data still referenced by dead slots.
+	RetainedKeyBytes int64
+
+	// RetainedValueBytes is the estimated value data referenced by dead slots.
+	RetainedValueBytes int64
+
+	// OversizedValues is the number of live []byte values whose capacity is
+	// more than twice their length.
+	OversizedValues int
+
+	// OversizedWastedBytes is the unused capacity (cap - len) of those values.
+	OversizedWastedBytes int64
+
+	// Compacted reports whether the memory above was released (Compact) or
+	// only measured (CompactionReport).
+	Compacted bool
+
+	// ReleasedSlots is the number of dead slots whose references were dropped.
+	ReleasedSlots int
+
+	// TrimmedValues is the number of oversized values copied to exact size.
+	TrimmedValues int
+
+	// RelocatedValues is the number of live values copied out of older
+	// value arena slabs (Config.ValueArena), releasing the garbage around them.
+	RelocatedValues int
+}
+
+// RetainedBytes returns the total estimated reclaimable bytes.
+func (r CompactionReport) RetainedBytes() int64 {
+	return r.RetainedKeyBytes + r.RetainedValueBytes + r.OversizedWastedBytes
+}
+
+// CompactionReport walks the table and reports retained-but-dead memory
+// without modifying the cache.
+func (c *wtinyLFUCache) CompactionReport() CompactionReport {
+	return c.compact(false)
+}
+
+// Compact walks the table, releases references held by dead slots and copies
+// oversized []byte values to exact-size slices. It returns what was found.
+//
+// Note: trimmed values are replaced by copies; callers still holding the
+// original slice keep their own (unchanged) backing array. Compaction also
+// drops expired values retained for revalidation (GetOrRevalidateResult).
+func (c *wtinyLFUCache) Compact() CompactionReport {
+	return c.compact(true)
+}
+
+func (c *wtinyLFUCache) compact(apply bool) CompactionReport {
+	report := CompactionReport{Compacted: apply}
+	if c.isClosed() {
+		return report
+	}
+	t := c.table.Load()
+	if t == nil {
+		return report // Closed
+	}
+	c.compactRange(t, 0, len(t.entries), apply, &report)
+	if apply && c.arena != nil {
+		report.RelocatedValues = c.compactArena()
+	}
+	return report
+}
+
+// compactRange inspects (and optionally compacts) slots [start, end) of t,
+// accumulating into report.
+func (c *wtinyLFUCache) compactRange(t *cacheTable, start, end int, apply bool, report *CompactionReport) {
+	report.Slots += end - start
+	g := t.gen.Load()
+
+	for i := start; i < end; i++ {
+		entry := &t.entries[i]
+
+		switch state := atomic.LoadInt32(&entry.valid); {
+		case state == g.live:
+			report.LiveEntries++
+			c.inspectLiveValue(entry, state, apply, report)
+
+		case t.isFree(state):
+			keyBytes := atomic.LoadInt64(&entry.keyLen)
+			value := holderValue(entry)
+			if keyBytes == 0 && value == nil {
+				continue
+			}
+			report.DeadSlots++
+			report.RetainedKeyBytes += keyBytes
+			report.RetainedValueBytes += estimateValueBytes(value)
+
+			if apply && c.releaseDeadSlot(entry, state) {
+				report.ReleasedSlots++
+			}
+		}
+	}
+}
+
+// inspectLiveValue accounts for (and optionally trims) an oversized []byte
+// value of an entry in state live.
+func (c *wtinyLFUCache) inspectLiveValue(entry *entry, live int32, apply bool, report *CompactionReport) {
+	b, ok := holderValue(entry).([]byte)
+	if !ok || cap(b) <= oversizedValueFactor*len(b) {
+		return
+	}
+	report.OversizedValues++
+	report.OversizedWastedBytes += int64(cap(b) - len(b))
+
+	if !apply || !atomic.CompareAndSwapInt32(&entry.valid, live, pendingOf(live)) {
+		return
+	}
+	// Re-check under exclusive ownership: the value may have been replaced
+	if current, ok := holderValue(entry).([]byte); ok && cap(current) > oversizedValueFactor*len(current) {
+		trimmed := make([]byte, len(current))
+		copy(trimmed, current)
+		entry.value.Store(newValueHolder(trimmed, "", holderVersion(entry), holderTTL(entry)))
+		report.TrimmedValues++
+	}
+	atomic.StoreInt32(&entry.valid, live)
+}
+
+// releaseDeadSlot drops the key and value referenced by a dead slot, keeping
+// its state (deleted slots must stay tombstones to preserve probe chains). A
+// slot left valid by a Clear becomes deleted.
+func (c *wtinyLFUCache) releaseDeadSlot(entry *entry, state int32) bool {
+	if !atomic.CompareAndSwapInt32(&entry.valid, state, entryPending) {
+		return false // Reused concurrently: no longer dead
+	}
+	if stateOf(state) == entryValid {
+		state = entryDeleted
+	}
+	entry.storeKey("") // Also bumps the SeqLock version for stale readers
+	entry.value.Store(emptyValueHolder)
+	atomic.StoreInt64(&entry.expireAt, 0)
+	atomic.StoreInt32(&entry.valid, state)
+	return true
+}
+
+// holderValue returns the value stored in an entry's holder, or nil.
+func holderValue(entry *entry) interface{} {
+	holder, ok := entry.value.Load().(*valueHolder)
+	if !ok || holder == nil {
+		return nil
+	}
+	return holder.load()
+}
+
+// estimateValueBytes returns the backing size of string and []byte values.
+func estimateValueBytes(value interface{}) int64 {
+	switch v := value.(type) {
+	case []byte:
+		return int64(cap(v))
+	case string:
+		return int64(len(v))
+	case encodedValue:
+		return int64(cap(v))
+	case arenaValue:
+		return int64(v.n)
+	default:
+		return 0
+	}
+}
+
+// CompactionReport reports retained-but-dead memory without modifying the cache.
+func (c *GenericCache[K, V]) CompactionReport() CompactionReport {
+	return c.inner.CompactionReport()
+}
+
+// Compact releases retained-but-dead memory and returns what was found.
+func (c *GenericCache[K, V]) Compact() CompactionReport {
+	return c.inner.Compact()
+}
@@ -0,0 +1,136 @@
+// compact_test.go: tests for CompactionReport and Compact
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompactionReport_Tombstones(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	for i := 0; i < 10; i++ {
+		cache.Set("key"+strconv.Itoa(i), make([]byte, 100))
+	}
+	for i := 0; i < 4; i++ {
+		cache.Delete("key" + strconv.Itoa(i))
+	}
+
+	report := cache.CompactionReport()
+	if report.LiveEntries != 6 || report.DeadSlots != 4 {
+		t.Fatalf("expected 6 live / 4 dead, got %+v", report)
+	}
+	if report.RetainedValueBytes != 400 {
+		t.Errorf("expected 400 retained value bytes, got %d", report.RetainedValueBytes)
+	}
+	if report.Compacted || report.ReleasedSlots != 0 {
+		t.Error("CompactionReport must not modify the cache")
+	}
+
+	compacted := cache.Compact()
+	if !compacted.Compacted || compacted.ReleasedSlots != 4 {
+		t.Errorf("expected 4 released slots, got %+v", compacted)
+	}
+	if after := cache.CompactionReport(); after.DeadSlots != 0 || after.RetainedBytes() != 0 {
+		t.Errorf("dead slots remain after Compact: %+v", after)
+	}
+
+	// Live data is untouched and tombstones still work as probe-chain links
+	for i := 4; i < 10; i++ {
+		if _, ok := cache.Get("key" + strconv.Itoa(i)); !ok {
+			t.Errorf("live key%d lost after Compact", i)
+		}
+	}
+	if cache.Len() != 6 {
+		t.Errorf("expected Len 6, got %d", cache.Len())
+	}
+	cache.Set("key0", []byte("again"))
+	if v, ok := cache.Get("key0"); !ok || string(v.([]byte)) != "again" {
+		t.Error("compacted slot not reusable")
+	}
+}
+
+func TestCompactionReport_ExpiredAndCleared(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("expiring", "payload")
+	mockTime.Advance(2 * time.Second)
+	cache.Get("expiring") // Lazy expiration keeps key and value in the slot
+
+	report := cache.CompactionReport()
+	if report.DeadSlots != 1 || report.RetainedKeyBytes != int64(len("expiring")) || report.RetainedValueBytes != int64(len("payload")) {
+		t.Errorf("unexpected report for expired entry: %+v", report)
+	}
+
+	cache.Set("a", "1")
+	cache.Clear()
+	if report := cache.Compact(); report.DeadSlots == 0 || report.ReleasedSlots != report.DeadSlots {
+		t.Errorf("cleared slots should be released: %+v", report)
+	}
+}
+
+func TestCompact_TrimsOversizedValues(t *testing.T) {
+	cache := NewGenericCache[string, []byte](Config{MaxSize: 100})
+	buf := make([]byte, 4096)
+	copy(buf, "header")
+	cache.Set("slice", buf[:6]) // Pins the whole 4 KiB read buffer
+	cache.Set("exact", []byte("exact"))
+
+	report := cache.CompactionReport()
+	if report.OversizedValues != 1 || report.OversizedWastedBytes != 4090 {
+		t.Fatalf("unexpected oversized accounting: %+v", report)
+	}
+
+	if report := cache.Compact(); report.TrimmedValues != 1 {
+		t.Errorf("expected 1 trimmed value, got %+v", report)
+	}
+	v, ok := cache.Get("slice")
+	if !ok || string(v) != "header" || cap(v) != len(v) {
+		t.Errorf("trimmed value wrong: %q cap=%d", v, cap(v))
+	}
+}
+
+func TestCompact_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 500}).(*wtinyLFUCache)
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				key := strconv.Itoa(g*1_000_000 + i%2000)
+				cache.Set(key, key)
+				if v, ok := cache.Get(key); ok && v != key {
+					t.Errorf("key %s returned foreign value %v", key, v)
+					return
+				}
+				if i%3 == 0 {
+					cache.Delete(key)
+				}
+			}
+		}(g)
+	}
+
+	for i := 0; i < 20; i++ {
+		cache.Compact()
+	}
+	close(stop)
+	wg.Wait()
+
+	if n := cache.Len(); n < 0 || n > 500+4 {
+		t.Errorf("size out of bounds after concurrent compaction: %d", n)
+	}
+}
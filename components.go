@@ -0,0 +1,228 @@
+// components.go: public interfaces of the cache's internal components
+//
+// FrequencyEstimator and EvictionPolicy replace the built-in sketch and
+// victim selection through Config. Backend is the storage contract of every
+// Cache; the built-in table is not replaceable.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// FrequencyEstimator estimates how often keys are accessed. It is fed on
+// every Get and Set and consulted when choosing eviction victims.
+// Implementations must be safe for concurrent use.
+type FrequencyEstimator interface {
+	// Increment records one access of the key with hash keyHash.
+	Increment(keyHash uint64)
+
+	// Estimate returns the (approximate) access count of keyHash.
+	Estimate(keyHash uint64) uint64
+
+	// Reset ages the recorded frequencies (the default sketch halves them).
+	// Called by Clear.
+	Reset()
+}
+
+// NewFrequencySketch returns the default FrequencyEstimator: a Count-Min
+// sketch with 4-bit counters sized for maxSize entries, aged after 10*maxSize
+// increments.
+func NewFrequencySketch(maxSize int) FrequencyEstimator {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return newFrequencySketch(maxSize)
+}
+
+// Increment records one access of keyHash.
+func (s *frequencySketch) Increment(keyHash uint64) { s.increment(keyHash) }
+
+// Estimate returns the estimated access count of keyHash (at most 15).
+func (s *frequencySketch) Estimate(keyHash uint64) uint64 { return s.estimate(keyHash) }
+
+// Reset halves all counters.
+func (s *frequencySketch) Reset() { s.reset() }
+
+// EvictionCandidate describes a live entry sampled for eviction.
+type EvictionCandidate struct {
+	Key       string
+	KeyHash   uint64
+	Frequency uint64 // Estimated access count (see FrequencyEstimator)
+	ExpireAt  int64  // Expiration deadline in TimeProvider nanoseconds (0 = none)
+}
+
+// EvictionPolicy selects which entry to evict when the cache is full.
+// Implementations must be safe for concurrent use.
+type EvictionPolicy interface {
+	// Victim returns the index in candidates of the entry to evict, or -1
+	// to reject the whole sample (a new sample is drawn; after a few
+	// rejected samples the cache evicts the first live entry it finds).
+	// candidates is only valid during the call.
+	Victim(candidates []EvictionCandidate) int
+}
+
+// LeastFrequentPolicy evicts the sampled entry with the lowest estimated
+// frequency. It is the default EvictionPolicy.
+type LeastFrequentPolicy struct{}
+
+// Victim returns the index of the least frequent candidate.
+func (LeastFrequentPolicy) Victim(candidates []EvictionCandidate) int {
+	victim := -1
+	for i := range candidates {
+		if victim < 0 || candidates[i].Frequency < candidates[victim].Frequency {
+			victim = i
+		}
+	}
+	return victim
+}
+
+// Backend is the storage contract shared by every Cache: string keys, any
+// values, bounded capacity. It is not a Config option: the table of the
+// caches returned by NewCache cannot be replaced. Helpers that only store
+// and look up values, such as PrimeFromSecondary, accept any Backend.
+type Backend interface {
+	// Get retrieves a value from the cache.
+	// Returns the value and true if found, nil and false otherwise.
+	// This method must be zero-allocation on the hot path.
+	Get(key string) (value interface{}, found bool)
+
+	// Set stores a key-value pair in the cache.
+	// Returns true if the item was successfully stored.
+	//
+	// Note: Returns false only in extreme cases when the cache is full and
+	// eviction fails repeatedly, which is virtually impossible in normal operation
+	// (< 0.001% probability with proper cache sizing). In practice, Set() always succeeds.
+	//
+	// This method must be zero-allocation on the hot path.
+	Set(key string, value interface{}) bool
+
+	// Delete removes an item from the cache.
+	// Returns true if the item was present and removed.
+	Delete(key string) bool
+
+	// Has checks if a key exists in the cache without retrieving the value.
+	// Returns false if the key does not exist or has expired (when TTL is enabled).
+	// This method should be faster than Get when only existence matters.
+	Has(key string) bool
+
+	// Len returns the current number of items in the cache.
+	Len() int
+
+	// Capacity returns the maximum number of items the cache can hold.
+	Capacity() int
+
+	// Clear removes all items from the cache. It is safe to call
+	// concurrently with writers: a Set racing with Clear either is cleared
+	// or survives it. The built-in cache clears by moving its table to a new
+	// generation.
+	Clear()
+}
+
+// incrementFrequency records an access in the configured FrequencyEstimator.
+func (c *wtinyLFUCache) incrementFrequency(keyHash uint64) {
+	if c.isClosed() {
+		return
+	}
+	if c.estimator != nil {
+		c.estimator.Increment(keyHash)
+		return
+	}
+	if t := c.table.Load(); t != nil {
+		t.sketch.increment(keyHash)
+	}
+}
+
+// incrementFrequencyIn is incrementFrequency for an open cache whose table
+// is t.
+func (c *wtinyLFUCache) incrementFrequencyIn(t *cacheTable, keyHash uint64) {
+	if c.estimator != nil {
+		c.estimator.Increment(keyHash)
+		return
+	}
+	t.sketch.increment(keyHash)
+}
+
+// estimateFrequency queries the configured FrequencyEstimator.
+func (c *wtinyLFUCache) estimateFrequency(keyHash uint64) uint64 {
+	if c.isClosed() {
+		return 0
+	}
+	if c.estimator != nil {
+		return c.estimator.Estimate(keyHash)
+	}
+	if t := c.table.Load(); t != nil {
+		return t.sketch.estimate(keyHash)
+	}
+	return 0
+}
+
+// resetFrequencies ages the configured FrequencyEstimator.
+func (c *wtinyLFUCache) resetFrequencies() {
+	if c.isClosed() {
+		return
+	}
+	if c.estimator != nil {
+		c.estimator.Reset()
+		return
+	}
+	if t := c.table.Load(); t != nil {
+		t.sketch.reset()
+	}
+}
+
+// policyVictim samples up to evictionSampleSize live entries of t among the
+// first limit slots of the sequence start, start+step, ... and lets the
+// configured EvictionPolicy (LeastFrequentPolicy if none) choose the victim.
+// Returns nil if the sample is empty or rejected. With the eviction audit
+// enabled it also returns the decision to record if the eviction succeeds.
+func (c *wtinyLFUCache) policyVictim(t *cacheTable, start, step, limit int) (*entry, *EvictionDecision) {
+	g := t.gen.Load()
+	tableSize := int(t.mask) + 1
+	var (
+		candidates [evictionSampleSize]EvictionCandidate
+		entries    [evictionSampleSize]*entry
+		n          int
+	)
+	for i := 0; i < limit && n < evictionSampleSize; i++ {
+		entry := &t.entries[(start+i*step)%tableSize]
+		if atomic.LoadInt32(&entry.valid) != g.live {
+			continue
+		}
+		candidates[n] = c.evictionCandidate(entry)
+		entries[n] = entry
+		n++
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	var policy EvictionPolicy = LeastFrequentPolicy{}
+	if c.evictionPolicy != nil {
+		policy = c.evictionPolicy
+	}
+	i := policy.Victim(candidates[:n])
+	if i < 0 || i >= n {
+		return nil, nil
+	}
+	if c.evictionAudit == nil {
+		return entries[i], nil
+	}
+	return entries[i], &EvictionDecision{
+		Victim:     candidates[i],
+		Candidates: append([]EvictionCandidate(nil), candidates[:n]...),
+	}
+}
+
+// evictionCandidate describes entry for an EvictionPolicy or AdmissionPolicy.
+func (c *wtinyLFUCache) evictionCandidate(entry *entry) EvictionCandidate {
+	keyHash := atomic.LoadUint64(&entry.keyHash)
+	return EvictionCandidate{
+		Key:       entry.loadKey(),
+		KeyHash:   keyHash,
+		Frequency: c.estimateFrequency(keyHash),
+		ExpireAt:  atomic.LoadInt64(&entry.expireAt),
+	}
+}
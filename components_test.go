@@ -0,0 +1,179 @@
+// components_test.go: tests for pluggable frequency estimators and eviction policies
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingEstimator is an exact (map-based) FrequencyEstimator.
+type countingEstimator struct {
+	mu     sync.Mutex
+	counts map[uint64]uint64
+	resets int
+}
+
+func newCountingEstimator() *countingEstimator {
+	return &countingEstimator{counts: make(map[uint64]uint64)}
+}
+
+func (e *countingEstimator) Increment(keyHash uint64) {
+	e.mu.Lock()
+	e.counts[keyHash]++
+	e.mu.Unlock()
+}
+
+func (e *countingEstimator) Estimate(keyHash uint64) uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.counts[keyHash]
+}
+
+func (e *countingEstimator) Reset() {
+	e.mu.Lock()
+	e.resets++
+	e.mu.Unlock()
+}
+
+// recordingPolicy evicts the candidate with the given key prefix, if sampled.
+type recordingPolicy struct {
+	calls   int64
+	protect string
+}
+
+func (p *recordingPolicy) Victim(candidates []EvictionCandidate) int {
+	atomic.AddInt64(&p.calls, 1)
+	for i, c := range candidates {
+		if c.Key == "" || c.KeyHash != stringHash(c.Key) {
+			panic("candidate key and hash must match")
+		}
+		if len(c.Key) < len(p.protect) || c.Key[:len(p.protect)] != p.protect {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestFrequencyEstimator_Custom(t *testing.T) {
+	est := newCountingEstimator()
+	cache := NewCache(Config{MaxSize: 100, FrequencyEstimator: est})
+
+	cache.Set("k", 1)
+	cache.Get("k")
+	cache.Get("k")
+	if n := est.Estimate(stringHash("k")); n != 3 {
+		t.Errorf("expected 3 recorded accesses, got %d", n)
+	}
+	if n := cache.(*wtinyLFUCache).table.Load().sketch.estimate(stringHash("k")); n != 0 {
+		t.Errorf("built-in sketch must not be fed when replaced, got %d", n)
+	}
+
+	cache.Clear()
+	if est.resets != 1 {
+		t.Errorf("Clear must reset the estimator, got %d resets", est.resets)
+	}
+}
+
+func TestNewFrequencySketch_Exported(t *testing.T) {
+	s := NewFrequencySketch(0)
+	for i := 0; i < 5; i++ {
+		s.Increment(42)
+	}
+	if n := s.Estimate(42); n != 5 {
+		t.Errorf("expected estimate 5, got %d", n)
+	}
+	s.Reset()
+	if n := s.Estimate(42); n != 2 {
+		t.Errorf("Reset must halve counters, got %d", n)
+	}
+}
+
+func TestEvictionPolicy_Custom(t *testing.T) {
+	policy := &recordingPolicy{protect: "keep:"}
+	cache := NewCache(Config{MaxSize: 64, EvictionPolicy: policy})
+
+	for i := 0; i < 32; i++ {
+		cache.Set(fmt.Sprintf("keep:%d", i), i)
+	}
+	for i := 0; i < 500; i++ {
+		cache.Set(fmt.Sprintf("churn:%d", i), i)
+	}
+
+	if atomic.LoadInt64(&policy.calls) == 0 {
+		t.Fatal("custom policy was never consulted")
+	}
+	kept := 0
+	for i := 0; i < 32; i++ {
+		if cache.Has(fmt.Sprintf("keep:%d", i)) {
+			kept++
+		}
+	}
+	if kept < 28 {
+		t.Errorf("policy should protect most keep: keys, only %d/32 survived", kept)
+	}
+	if cache.Len() > cache.Capacity() {
+		t.Errorf("cache exceeded capacity: %d > %d", cache.Len(), cache.Capacity())
+	}
+}
+
+func TestLeastFrequentPolicy(t *testing.T) {
+	var p EvictionPolicy = LeastFrequentPolicy{}
+	if i := p.Victim(nil); i != -1 {
+		t.Errorf("empty sample must return -1, got %d", i)
+	}
+	candidates := []EvictionCandidate{{Frequency: 5}, {Frequency: 1}, {Frequency: 3}, {Frequency: 1}}
+	if i := p.Victim(candidates); i != 1 {
+		t.Errorf("expected first least frequent candidate (1), got %d", i)
+	}
+}
+
+// mapBackend is a minimal Backend used to check helpers accept alternative stores.
+type mapBackend struct {
+	mu sync.Mutex
+	m  map[string]interface{}
+}
+
+func (b *mapBackend) Get(key string) (interface{}, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v, ok := b.m[key]
+	return v, ok
+}
+
+func (b *mapBackend) Set(key string, value interface{}) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.m[key] = value
+	return true
+}
+
+func (b *mapBackend) Delete(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.m[key]
+	delete(b.m, key)
+	return ok
+}
+
+func (b *mapBackend) Has(key string) bool { _, ok := b.Get(key); return ok }
+func (b *mapBackend) Len() int            { b.mu.Lock(); defer b.mu.Unlock(); return len(b.m) }
+func (b *mapBackend) Capacity() int       { return 100 }
+func (b *mapBackend) Clear()              { b.mu.Lock(); b.m = map[string]interface{}{}; b.mu.Unlock() }
+
+func TestBackend_PrimeFromSecondary(t *testing.T) {
+	var _ Backend = NewCache(Config{MaxSize: 10})
+
+	backend := &mapBackend{m: map[string]interface{}{}}
+	res, err := PrimeFromSecondary(context.Background(), backend, newFakeSecondary(2), PrimeOptions{})
+	if err != nil || res.Loaded != 2 || backend.Len() != 2 {
+		t.Errorf("expected 2 keys primed into the backend: %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,45 @@
+// Package compress provides Snappy and Zstandard value codecs for balios.
+//
+// # Overview
+//
+// A balios.ValueCodec set as Config.ValueCodec encodes every value on write
+// and decodes it on read, so a cache of large JSON documents or text blobs
+// holds several times more entries in the same memory. NewSnappyCodec and
+// NewZstdCodec are built on balios.NewCompressionCodec: []byte and string
+// values are compressed as is, other values are gob-encoded first, and
+// values below a minimum size are stored uncompressed.
+//
+// The package is a separate module to keep the balios core free of external
+// dependencies.
+//
+// # Installation
+//
+//	go get github.com/agilira/balios/compress
+//
+// # Quick Start
+//
+//	import (
+//	    "github.com/agilira/balios"
+//	    baliosc "github.com/agilira/balios/compress"
+//	)
+//
+//	codec, err := baliosc.NewZstdCodec(0)
+//	if err != nil {
+//	    return err
+//	}
+//	docs := balios.NewBytesCache(balios.Config{
+//	    MaxSize:    100_000,
+//	    MaxBytes:   128 << 20, // Bounded by compressed size
+//	    ValueCodec: codec,
+//	})
+//
+// # Choosing a Codec
+//
+// Snappy is very fast with moderate ratios, a good default for hot caches.
+// Zstd compresses better at a higher CPU cost, for large and moderately read
+// values.
+//
+// # License
+//
+// Same as balios core (see LICENSE in main repository).
+package compress
@@ -0,0 +1,16 @@
+module github.com/agilira/balios/compress
+
+go 1.25
+
+require (
+	github.com/agilira/balios v0.0.0
+	github.com/golang/snappy v1.0.0
+	github.com/klauspost/compress v1.18.0
+)
+
+require (
+	github.com/agilira/go-errors v1.1.1 // indirect
+	github.com/agilira/go-timecache v1.0.2 // indirect
+)
+
+replace github.com/agilira/balios => ../
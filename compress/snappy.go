@@ -0,0 +1,28 @@
+// snappy.go: Snappy compression codec
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package compress
+
+import (
+	"github.com/agilira/balios"
+	"github.com/golang/snappy"
+)
+
+// NewSnappyCodec returns a balios.ValueCodec compressing values of at least
+// minSize bytes with Snappy (balios.DefaultCompressionMinSize if
+// minSize <= 0). Snappy is very fast with moderate ratios, a good default
+// for hot caches.
+func NewSnappyCodec(minSize int) balios.ValueCodec {
+	return balios.NewCompressionCodec(
+		func(src []byte) []byte {
+			return snappy.Encode(nil, src)
+		},
+		func(src []byte) ([]byte, error) {
+			return snappy.Decode(nil, src)
+		},
+		minSize,
+	)
+}
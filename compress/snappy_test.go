@@ -0,0 +1,37 @@
+// snappy_test.go: tests for the Snappy compression codec
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package compress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agilira/balios"
+)
+
+// jsonBlob returns a compressible document of about n bytes.
+func jsonBlob(n int) string {
+	return `{"items":[` + strings.Repeat(`{"name":"balios","kind":"cache"},`, n/32) + `{}]}`
+}
+
+func TestSnappyCodec(t *testing.T) {
+	doc := jsonBlob(16 << 10)
+	cache := balios.NewCache(balios.Config{MaxSize: 100, MaxWeight: 1 << 20, ValueCodec: NewSnappyCodec(0)})
+	defer cache.Close()
+
+	cache.Set("doc", doc)
+	cache.Set("small", "tiny")
+	if v, ok := cache.Get("doc"); !ok || v != doc {
+		t.Fatal("Snappy round trip failed")
+	}
+	if v, ok := cache.Get("small"); !ok || v != "tiny" {
+		t.Fatal("values below the minimum size should round trip")
+	}
+	if w := cache.Stats().Weight; w > int64(len(doc))/4 {
+		t.Errorf("expected a compressed weight, got %d for %d bytes", w, len(doc))
+	}
+}
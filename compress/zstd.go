@@ -0,0 +1,38 @@
+// zstd.go: Zstandard compression codec
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package compress
+
+import (
+	"github.com/agilira/balios"
+	"github.com/klauspost/compress/zstd"
+)
+
+// NewZstdCodec returns a balios.ValueCodec compressing values of at least
+// minSize bytes with Zstandard (balios.DefaultCompressionMinSize if
+// minSize <= 0). Zstd compresses better than Snappy at a higher CPU cost,
+// for large and moderately read values.
+func NewZstdCodec(minSize int) (balios.ValueCodec, error) {
+	// EncodeAll and DecodeAll are safe for concurrent use; a nil reader
+	// makes the encoder and decoder block-only
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	return balios.NewCompressionCodec(
+		func(src []byte) []byte {
+			return encoder.EncodeAll(src, nil)
+		},
+		func(src []byte) ([]byte, error) {
+			return decoder.DecodeAll(src, nil)
+		},
+		minSize,
+	), nil
+}
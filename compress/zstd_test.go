@@ -0,0 +1,32 @@
+// zstd_test.go: tests for the Zstandard compression codec
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/agilira/balios"
+)
+
+func TestZstdCodec(t *testing.T) {
+	codec, err := NewZstdCodec(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := []byte(jsonBlob(16 << 10))
+	cache := balios.NewBytesCache(balios.Config{MaxSize: 100, MaxBytes: 1 << 20, ValueCodec: codec})
+	defer cache.Close()
+
+	cache.Set("doc", doc)
+	if v, ok := cache.Get("doc"); !ok || !bytes.Equal(v, doc) {
+		t.Fatal("Zstd round trip failed")
+	}
+	if got := cache.Bytes(); got > int64(len(doc))/4 {
+		t.Errorf("expected a compressed size, got %d for %d bytes", got, len(doc))
+	}
+}
@@ -0,0 +1,138 @@
+// compute.go: Compute, atomic read-modify-write of a key
+//
+// Compute runs a transformer on the current value while holding the key,
+// so concurrent Computes of a key are applied one after the other.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// Compute replaces the value of key with the result of fn, called with the
+// current value (exists = false when key is absent or expired). The key is
+// held exclusively while fn runs: concurrent Computes of a key are applied in
+// sequence and a concurrent Set or Delete of the key waits for it. fn returns
+// the new value and keep = true to store it, or keep = false to delete the
+// key. A stored value renews the entry TTL and counts as a Set.
+//
+// Returns the value of key after the call and whether it is present. A new
+// value that cannot be stored (see SetE) leaves the entry as it was. When no
+// slot can be claimed for an absent key, fn is not called and Compute returns
+// nil, false.
+func (c *wtinyLFUCache) Compute(key string, fn func(old interface{}, exists bool) (interface{}, bool)) (value interface{}, present bool) {
+	if key == "" || fn == nil || c.isClosed() {
+		return nil, false
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now)
+	keyHash := c.hashKey(key)
+	c.incrementFrequency(keyHash)
+
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return nil, false // Closed
+	}
+	defer t.leave(keyHash)
+
+	entry, idx, existing, oldState := c.claimKey(t, key, keyHash, ttlNow)
+	if entry == nil {
+		return nil, false
+	}
+
+	var old interface{}
+	if existing {
+		if holder, ok := entry.value.Load().(*valueHolder); ok && holder != nil {
+			old = c.decodedOrNil(holder.load())
+		}
+	}
+	newValue, keep := c.runCompute(entry, existing, fn, old)
+
+	if !keep {
+		if !existing {
+			atomic.StoreInt32(&entry.valid, entryDeleted)
+			return nil, false
+		}
+		c.removeAcquired(t, entry, ReasonDeleted)
+		entry.storeKey("")
+		atomic.AddInt64(&c.deletes, 1)
+		if c.metricsCollector != nil {
+			c.metricsCollector.RecordDelete(c.latencySince(start))
+		}
+		if c.propagate {
+			c.propagateDelete(key)
+		}
+		return nil, false
+	}
+
+	stored, err := c.prepareSet("Compute", key, newValue)
+	if err != nil {
+		c.releaseClaim(entry, existing)
+		return old, existing
+	}
+	var weight int32
+	if c.maxWeight > 0 {
+		weight, _ = c.weigh(key, stored)
+	}
+
+	if !existing {
+		c.insertClaimed(t, entry, idx, oldState, key, keyHash, newValue, stored, weight, start, ttlNow)
+		return newValue, true
+	}
+
+	c.replaceValue(t, entry, key, stored, weight, c.entryExpireAt(ttlNow))
+	if c.maxWeight > 0 {
+		c.enforceMaxWeight(t)
+	}
+	if c.memory != nil {
+		c.recordMemory(key, stored)
+	}
+	c.recordSetMetrics(key, start)
+	if c.propagate {
+		c.propagateWrite(key, newValue, c.ttlNanos)
+	}
+	return newValue, true
+}
+
+// runCompute calls fn, releasing the claimed entry if it panics.
+func (c *wtinyLFUCache) runCompute(entry *entry, existing bool, fn func(interface{}, bool) (interface{}, bool), old interface{}) (interface{}, bool) {
+	completed := false
+	defer func() {
+		if !completed {
+			c.releaseClaim(entry, existing)
+		}
+	}()
+	value, keep := fn(old, existing)
+	completed = true
+	return value, keep
+}
+
+// releaseClaim gives back an entry taken by claimKey without changing it: a
+// live entry becomes valid again, a claimed free slot becomes entryDeleted
+// (entries added beyond it while it was held must stay reachable).
+func (c *wtinyLFUCache) releaseClaim(entry *entry, existing bool) {
+	if existing {
+		entry.release()
+		return
+	}
+	atomic.StoreInt32(&entry.valid, entryDeleted)
+}
+
+// Compute replaces the value of key with the result of fn, called with the
+// current value, atomically per key (see Cache.Compute). fn returns keep =
+// false to delete the key. Returns the value after the call and whether the
+// key is present.
+func (c *GenericCache[K, V]) Compute(key K, fn func(old V, exists bool) (V, bool)) (value V, present bool) {
+	result, present := c.inner.Compute(keyToString(key), func(old interface{}, exists bool) (interface{}, bool) {
+		typed, _ := old.(V)
+		return fn(typed, exists)
+	})
+	if typed, ok := result.(V); ok {
+		value = typed
+	}
+	return value, present
+}
@@ -0,0 +1,135 @@
+// compute_test.go: tests for Compute
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func increment(old int, exists bool) (int, bool) {
+	return old + 1, true
+}
+
+func TestCompute_Basic(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+	defer cache.Close()
+
+	if v, present := cache.Compute("n", increment); !present || v != 1 {
+		t.Fatalf("Compute on a missing key = %v, %v", v, present)
+	}
+	if v, present := cache.Compute("n", increment); !present || v != 2 {
+		t.Fatalf("Compute on a present key = %v, %v", v, present)
+	}
+	if v, _ := cache.Get("n"); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+
+	// keep = false deletes the key, or leaves it absent
+	drop := func(old int, exists bool) (int, bool) { return 0, false }
+	if _, present := cache.Compute("n", drop); present {
+		t.Error("Compute with keep = false reported the key present")
+	}
+	if cache.Has("n") {
+		t.Error("Compute with keep = false did not delete the key")
+	}
+	if _, present := cache.Compute("missing", drop); present || cache.Has("missing") {
+		t.Error("Compute with keep = false stored a missing key")
+	}
+
+	stats := cache.Stats()
+	if stats.Sets != 2 || stats.Deletes != 1 || stats.Size != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCompute_ConcurrentCounter(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+	defer cache.Close()
+
+	const goroutines, increments = 8, 1000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				cache.Compute("counter", increment)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := cache.Get("counter"); v != goroutines*increments {
+		t.Errorf("lost updates: expected %d, got %v", goroutines*increments, v)
+	}
+	if copies := liveCopies(cache.inner.(*wtinyLFUCache), "counter"); copies != 1 {
+		t.Errorf("%d live copies of counter", copies)
+	}
+}
+
+func TestCompute_ExpiredEntry(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	defer cache.Close()
+	cache.Set("k", 1)
+
+	mockTime.Advance(2 * time.Second)
+	cache.Compute("k", func(old interface{}, exists bool) (interface{}, bool) {
+		if exists || old != nil {
+			t.Errorf("expired entry passed to fn: %v, %v", old, exists)
+		}
+		return 2, true
+	})
+	if v, _ := cache.Get("k"); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+}
+
+func TestCompute_PanicReleasesKey(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+	cache.Set("k", 1)
+
+	for _, key := range []string{"k", "absent"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: panic not propagated", key)
+				}
+			}()
+			cache.Compute(key, func(interface{}, bool) (interface{}, bool) { panic("boom") })
+		}()
+	}
+
+	if v, ok := cache.Get("k"); !ok || v != 1 {
+		t.Errorf("entry not released after a panic: %v, %v", v, ok)
+	}
+	if cache.Has("absent") {
+		t.Error("panicking Compute stored a value")
+	}
+	if !cache.Set("absent", 2) || !cache.Delete("k") {
+		t.Error("keys still held after a panic")
+	}
+}
+
+func TestCompute_ValueTooHeavy(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 8, Weigher: byteWeigher}).(*wtinyLFUCache)
+	defer cache.Close()
+	cache.Set("k", []byte("ok"))
+
+	v, present := cache.Compute("k", func(interface{}, bool) (interface{}, bool) {
+		return []byte("much too heavy"), true
+	})
+	if !present || string(v.([]byte)) != "ok" {
+		t.Errorf("Compute = %v, %v, want the entry unchanged", v, present)
+	}
+	if got, _ := cache.Get("k"); string(got.([]byte)) != "ok" {
+		t.Errorf("entry changed to %v", got)
+	}
+}
@@ -18,10 +18,26 @@ type Config struct {
 	// Must be > 0. Default: DefaultMaxSize.
 	MaxSize int
 
+	// ResizeLimit is the capacity the table and the frequency sketch are
+	// sized for, about 2 table slots (128 bytes) per entry. Resize grows the
+	// cache up to it in place; beyond it, Resize copies the entries to a
+	// larger table, and writes wait for the copy. A higher limit trades that
+	// pause for memory allocated up front. Default: MaxSize.
+	ResizeLimit int
+
 	// WindowRatio is the ratio of window cache to total cache size.
 	// Must be between 0.0 and 1.0. Default: DefaultWindowRatio.
+	// With AdaptiveWindow it is the initial size of the admission window.
 	WindowRatio float64
 
+	// AdaptiveWindow places new keys in an admission window before they face
+	// the AdmissionPolicy (TinyLFUAdmission if none is set), and tunes the
+	// window size by hill climbing on the observed hit ratio, as Caffeine
+	// does: larger for recency-biased workloads, smaller for frequency-biased
+	// ones (see adaptive_window.go). The current size is reported by
+	// DebugStats. Default: false (the new key itself faces admission).
+	AdaptiveWindow bool
+
 	// CounterBits is the number of bits per counter in the frequency sketch.
 	// Must be between 1 and 8. Default: DefaultCounterBits.
 	CounterBits int
@@ -30,6 +46,21 @@ type Config struct {
 	// If 0, entries never expire. Default: 0 (no expiration).
 	TTL time.Duration
 
+	// TTLJitter spreads the expiration of entries written together: each
+	// write draws its TTL uniformly from [TTL*(1-TTLJitter),
+	// TTL*(1+TTLJitter)], whether the TTL is Config.TTL, the WithTTL option
+	// of a load or the TTL of a Namespace (WithNamespaceTTL). 0.1 means
+	// +/-10%. Must be in [0, 1); other values disable jitter. Default: 0
+	// (exact TTLs).
+	TTLJitter float64
+
+	// TTI (time-to-idle) expires entries not read for this long: each Get
+	// hit restarts the period, so entries in use stay cached. Combined with
+	// TTL, an entry expires at the first of the two deadlines; reads never
+	// extend it past its TTL. Deadlines move in steps of TTI/16 (see tti.go).
+	// Default: 0 (expiration after writes only).
+	TTI time.Duration
+
 	// NegativeCacheTTL is the time-to-live for caching loader errors.
 	// When GetOrLoad fails, the error can be cached to prevent repeated
 	// expensive operations that consistently fail.
@@ -38,30 +69,462 @@ type Config struct {
 	// Example: Database unreachable errors don't need to be retried every millisecond.
 	NegativeCacheTTL time.Duration
 
-	// CleanupInterval is how often to run cleanup of expired entries.
-	// Only used if TTL > 0. Default: TTL / 10.
+	// SecondaryCache is a second-level store (e.g. Redis) shared by several
+	// processes (see secondary.go). GetOrLoad looks missing keys up in it
+	// before calling the loader, and Set, loaded values and Delete are
+	// written through to it. Default: nil (in-process cache only).
+	SecondaryCache SecondaryCache
+
+	// SecondaryTimeout bounds every SecondaryCache call. Default: 0 (only
+	// the caller's context, if any, and the store's own timeouts apply).
+	SecondaryTimeout time.Duration
+
+	// InvalidationBus keeps the caches of several processes coherent (see
+	// invalidation.go): writes and deletes are published as invalidation
+	// events, and keys invalidated by other caches are removed from this
+	// one. Default: nil (no distributed invalidation).
+	InvalidationBus InvalidationBus
+
+	// MinLoadCost enables cost-aware admission for GetOrLoad and
+	// GetOrLoadWithContext: loaded values whose recomputation cost is below
+	// MinLoadCost are returned but not cached, so cheap lookups don't evict
+	// expensive-to-rebuild entries. The cost is the loader duration measured
+	// with TimeProvider, or the value reported via ReportLoadCost.
+	// Default: 0 (every loaded value is cached).
+	MinLoadCost time.Duration
+
+	// LoadRateLimit caps loader calls (GetOrLoad, GetOrLoadWithContext) per
+	// key family, in calls per second. Excess callers receive a retryable
+	// BALIOS_LOAD_RATE_LIMITED error instead of running the loader, so even
+	// with negative caching disabled a hammered missing key cannot overload
+	// the backend. Concurrent callers of the same key share one loader call
+	// and consume a single token. Default: 0 (unlimited).
+	LoadRateLimit float64
+
+	// LoadRateBurst is the number of loader calls a family may make at once
+	// after being idle. Default: LoadRateLimit rounded up (at least 1).
+	LoadRateBurst int
+
+	// CircuitBreakerThreshold opens a loader circuit breaker (see
+	// circuit_breaker.go) after that many consecutive loader failures of a
+	// key family: GetOrLoad then fails fast with a retryable
+	// BALIOS_CIRCUIT_OPEN error for CircuitBreakerCooldown, after which a
+	// single probe load decides whether the circuit closes or reopens.
+	// Default: 0 (disabled).
+	CircuitBreakerThreshold int
+
+	// CircuitBreakerWindow bounds the failure streak: failures further apart
+	// than the window start a new streak. Default: 0 (consecutive failures
+	// count however far apart).
+	CircuitBreakerWindow time.Duration
+
+	// CircuitBreakerCooldown is the time a circuit stays open before a probe
+	// load is allowed. Default: DefaultCircuitBreakerCooldown (5s).
+	CircuitBreakerCooldown time.Duration
+
+	// CircuitBreakerPerCache uses one circuit for the whole cache instead of
+	// one per key family (KeyFamily, each key by default), for backends that
+	// fail as a whole. Default: false.
+	CircuitBreakerPerCache bool
+
+	// GroupedLoadWindow delays the first batch of an idle GetOrLoadGrouped
+	// group, so that more concurrent misses join it (see
+	// loading_grouped.go). Default: 0 (load at once; misses arriving while
+	// a batch loads join the next one).
+	GroupedLoadWindow time.Duration
+
+	// GroupedLoadMaxBatch caps the keys passed to one GetOrLoadGrouped
+	// loader call, e.g. to the limit of the backend's multi-get.
+	// Default: 0 (unlimited).
+	GroupedLoadMaxBatch int
+
+	// LoaderMiddleware wraps every loader run by GetOrLoad,
+	// GetOrLoadWithContext and refresh-ahead (see loader_middleware.go),
+	// for metrics, tracing or circuit breaking declared once instead of at
+	// each call site. The first middleware is the outermost. The batch
+	// loaders of GetOrLoadMany and GetOrLoadGrouped take several keys and are
+	// not wrapped. Default: nil.
+	LoaderMiddleware []func(next Loader) Loader
+
+	// KeyFamily maps a key to its family (e.g. the "user" of "user:123") for
+	// LoadRateLimit and FamilyStats. Must be fast and allocation-free where
+	// possible. Default: each key is its own family for LoadRateLimit, the
+	// namespace prefix (see NamespaceSeparator) for FamilyStats.
+	KeyFamily func(key string) string
+
+	// Codec encodes values in the snapshots written by SaveTo and read by
+	// LoadFrom. Default: GobCodec (custom types must be gob.Register-ed).
+	Codec Codec
+
+	// ValueHistory is the number of previous values kept per key, returned by
+	// GetPrevious and History. Only replacements by a newer value are
+	// recorded. Default: 0 (disabled).
+	ValueHistory int
+
+	// KeyReadRetries bounds the attempts Get makes to read a key that
+	// concurrent writers keep rewriting. When they are exhausted the lookup
+	// is a miss, counted in CacheStats.ReadContentions and reported by GetE
+	// as BALIOS_READ_CONTENTION. Default: DefaultKeyReadRetries.
+	KeyReadRetries int
+
+	// KeyHasher replaces the hash of keys in the table and the frequency
+	// sketch. It must be deterministic and fast, and spread keys over all 64
+	// bits (the low bits select the slot). Use KeyHasherFor, or
+	// NewGenericCacheWithHasher, to write it against a GenericCache key type,
+	// and NewRandomSipHasher when keys come from untrusted clients (the
+	// default hash is unkeyed, so colliding keys can be precomputed).
+	// Default: nil (FNV-1a, xxHash64 above 64 bytes; GenericCache hashes
+	// integer keys from their value).
+	KeyHasher func(key string) uint64
+
+	// KeySlabSize packs the copies of inserted keys into shared slabs of
+	// KeySlabSize bytes, recycled through a sync.Pool, instead of one
+	// allocation per new key (see key_slabs.go). Cuts allocations of
+	// Set/Delete churn over distinct keys; a slab stays in memory while any
+	// of its keys is cached. Slabs are bounded to about twice the bytes of
+	// MaxSize keys; past that, and for keys longer than KeySlabSize/8, keys
+	// are copied separately. Default: 0 (one allocation per new key).
+	// Typical: 4096.
+	KeySlabSize int
+
+	// FamilyStats enables hit and miss counters per key family, reported in
+	// CacheStats.Families. At most 256 families are tracked; further ones are
+	// counted under FamilyOverflow. Default: false.
+	FamilyStats bool
+
+	// CleanupInterval enables a background goroutine that removes expired
+	// entries every CleanupInterval, so entries that are never read again do
+	// not hold memory until their slot is reused (see janitor.go). It is
+	// stopped by Close. Default: 0 (lazy expiration and ExpireNow only).
 	CleanupInterval time.Duration
 
 	// Logger is used for debugging and monitoring.
 	// If nil, NoOpLogger is used. Default: NoOpLogger.
 	Logger Logger
 
+	// AnomalyLogInterval rate limits the Warn events reported to Logger for
+	// pathological contention: eviction falling back to a table scan, key
+	// reads abandoned after KeyReadRetries, duplicate-key cleanups and Sets
+	// failing with every probed slot busy (see anomaly_log.go). Each kind is
+	// logged at most once per interval, with the number of occurrences.
+	// Default: 0 (DefaultAnomalyLogInterval); negative disables the events.
+	AnomalyLogInterval time.Duration
+
 	// TimeProvider provides current time for TTL calculations.
-	// If nil, a default implementation is used. Default: system time.
+	// If nil, a default implementation is used. Default: system time, cached
+	// by go-timecache (see DefaultTimeResolution): a reading is one atomic
+	// load, not a clock read. Use NewMonotonicTimeProvider() on hosts with
+	// unstable wall clocks.
 	TimeProvider TimeProvider
 
+	// RandSeed seeds the generator behind eviction sampling and TTLJitter,
+	// making them reproducible in tests (see the balios/testing package).
+	// Default: 0 (seeded from TimeProvider at construction).
+	RandSeed uint64
+
+	// MaxKeyLen rejects writes of keys longer than MaxKeyLen bytes with
+	// BALIOS_VALUE_TOO_LARGE (Set returns false). Default: 0 (no limit).
+	MaxKeyLen int
+
+	// MaxValueBytes rejects writes of values larger than MaxValueBytes with
+	// BALIOS_VALUE_TOO_LARGE. Values are measured in their encoded form with
+	// a ValueCodec, by the Weigher otherwise, and []byte and string values by
+	// their length; other values are not checked (see size_limits.go).
+	// Default: 0 (no limit).
+	MaxValueBytes int64
+
+	// TopKeysCapacity enables exact TopKeys counts: the lookups of up to
+	// TopKeysCapacity keys are counted by a space-saving tracker, costing a
+	// key hash and a short mutex-guarded update per lookup. Default: 0
+	// (TopKeys ranks the cached keys by their frequency sketch estimate).
+	TopKeysCapacity int
+
+	// EventBufferSize is the capacity of the channels returned by Subscribe.
+	// A full channel drops its oldest event. Default: DefaultEventBufferSize
+	// (1024).
+	EventBufferSize int
+
+	// TombstoneTTL keeps a tombstone of every deleted key (Delete,
+	// DeleteByPrefix, received invalidations...) for TombstoneTTL. GetOrLoad
+	// misses of the key meanwhile are handled per TombstoneMode, to damp the
+	// reload stampede that follows the deletion of a hot key (see
+	// tombstone.go). Default: 0 (disabled).
+	TombstoneTTL time.Duration
+
+	// TombstoneMode selects what GetOrLoad does on a miss of a tombstoned
+	// key: serve the deleted value (TombstoneServeStale) or load after a
+	// random delay (TombstoneDelayLoad). Default: TombstoneServeStale.
+	TombstoneMode TombstoneMode
+
+	// TombstoneJitter spreads the reloads of tombstoned keys: a stale
+	// tombstone lives TombstoneTTL plus a random share of TombstoneJitter,
+	// and a delayed load waits a random share of it. Default: TombstoneTTL.
+	TombstoneJitter time.Duration
+
+	// EntryVersions stamps every write with a version from a cache-wide
+	// counter, returned by GetVersion and VersionOf and checked by
+	// SetIfVersion for optimistic concurrency (see versions.go).
+	// Default: false (versions are always 0).
+	EntryVersions bool
+
+	// WriteBehindStore enables write-behind: explicit writes (Set, SetE,
+	// Swap, Compute, ...) and Deletes are queued and flushed to the Store in
+	// batches by background workers (see write_behind.go). Close flushes the
+	// queue. Default: nil (disabled).
+	WriteBehindStore Store
+
+	// WriteBehindQueueSize bounds the write-behind queue, in distinct keys: a
+	// key written again while queued only updates its queued value.
+	// Default: DefaultWriteBehindQueueSize (10000).
+	WriteBehindQueueSize int
+
+	// WriteBehindBatchSize is the largest number of writes per
+	// Store.WriteBatch call. Default: DefaultWriteBehindBatchSize (100).
+	WriteBehindBatchSize int
+
+	// WriteBehindWorkers is the number of goroutines flushing batches. A key
+	// is in one batch at a time: a write of a key being flushed waits for
+	// that flush, so the Store sees the writes of a key in order.
+	// Default: 1.
+	WriteBehindWorkers int
+
+	// WriteBehindInterval is the longest a write waits for its batch to fill
+	// before it is flushed. Default: DefaultWriteBehindInterval (100ms).
+	WriteBehindInterval time.Duration
+
+	// WriteBehindRetries is the number of retries of a failed batch, with
+	// exponential backoff; a negative value disables retries.
+	// Default: DefaultWriteBehindRetries (3).
+	WriteBehindRetries int
+
+	// WriteBehindPolicy selects what a write does when the queue is full:
+	// drop it (counted in CacheStats.WriteBehindDropped) or block until a
+	// flush makes room. Default: WriteBehindDrop.
+	WriteBehindPolicy WriteBehindPolicy
+
 	// MetricsCollector is used for collecting operation metrics (latencies, hit/miss rates).
 	// If nil, NoOpMetricsCollector is used (zero overhead). Default: NoOpMetricsCollector.
 	// Use this to integrate with Prometheus, DataDog, StatsD, or other monitoring systems.
+	//
+	// Collector calls are isolated from the cache hot path: if any Record* method
+	// panics, the collector is disabled for the lifetime of the cache and the panic
+	// is logged once through Logger instead of crashing the calling goroutine.
 	MetricsCollector MetricsCollector
 
-	// OnEvict is called when an entry is evicted from the cache.
-	// This callback must be fast and non-blocking.
-	OnEvict func(key string, value interface{})
+	// MetricsCollectorV2 receives the recordings tagged with Name and the
+	// context of the call, so one collector can serve several caches (see
+	// metrics_v2.go). Used only if MetricsCollector is nil or
+	// NoOpMetricsCollector; the same panic isolation applies.
+	// Default: nil.
+	MetricsCollectorV2 MetricsCollectorV2
+
+	// Name identifies the cache in MetricsCollectorV2 recordings.
+	// Default: "" (anonymous).
+	Name string
+
+	// DisableMetricsLatency turns off latency measurement while keeping counters.
+	// When true, RecordGet/RecordSet/RecordDelete receive latencyNs = -1 and the
+	// cache skips the closing TimeProvider.Now() call of every operation, halving
+	// time-source overhead for users who only need hit/miss/eviction counts.
+	// Default: false (latencies are measured).
+	DisableMetricsLatency bool
+
+	// TrackLatency keeps Get and Set latency histograms inside the cache, so
+	// Stats reports LatencyP50, LatencyP95 and LatencyP99 without a
+	// MetricsCollector (see latency.go). With the default cached TimeProvider,
+	// operations are timed with a separate MonotonicTimeProvider, precise
+	// enough for in-memory operations; expiration keeps the cached clock.
+	// Default: false.
+	TrackLatency bool
+
+	// FrozenIndexInterval enables the frozen lookup index for read-mostly caches.
+	// A background goroutine checks write activity every interval; once a burst
+	// of writes is followed by a quiet interval, it builds a perfect-hash index
+	// of the live keys so Get resolves hits with a direct slot access instead of
+	// linear probing. Writes never wait for the index: lookups that miss it (new
+	// or moved keys) fall back to the normal table until the next rebuild.
+	// Default: 0 (disabled). Typical values: 1-10 seconds.
+	FrozenIndexInterval time.Duration
+
+	// ShrinkAfter enables gradual memory release after traffic bursts. When
+	// occupancy (Len/Capacity) stays below ShrinkThreshold for ShrinkAfter, a
+	// background goroutine compacts the table a chunk at a time, dropping the
+	// keys and values still referenced by evicted, expired and deleted slots
+	// (see Compact). The slot array itself is fixed by MaxSize.
+	// Default: 0 (disabled). Typical values: 1-10 minutes.
+	ShrinkAfter time.Duration
+
+	// ShrinkThreshold is the occupancy below which ShrinkAfter starts counting.
+	// Must be between 0 and 1. Default: DefaultShrinkThreshold (0.25).
+	ShrinkThreshold float64
+
+	// MemoryPressureThreshold enables the memory-pressure controller: a
+	// background goroutine samples the Go runtime memory every
+	// MemoryPressureInterval and, while usage exceeds this fraction of the
+	// memory limit, evicts a share of the entries that grows with the
+	// overshoot (see memory_pressure.go). The limit is MemoryLimit, or the
+	// one set with debug.SetMemoryLimit / GOMEMLIMIT; without a limit the
+	// controller stays idle. Must be between 0 and 1.
+	// Default: 0 (disabled). Typical values: 0.8-0.9.
+	MemoryPressureThreshold float64
+
+	// MemoryPressureInterval is the sampling period of the memory-pressure
+	// controller. Default: DefaultMemoryPressureInterval (1 second).
+	MemoryPressureInterval time.Duration
+
+	// MemoryLimit is the memory limit in bytes the memory-pressure controller
+	// compares usage with. Default: 0 (the Go runtime soft memory limit).
+	MemoryLimit int64
+
+	// MaxDependencyEdges bounds the dependency index used by SetWithDependencies
+	// (one edge per key/dependency pair). When the index is full, entries that
+	// would need new edges are not cached. Default: 4 * MaxSize.
+	MaxDependencyEdges int
+
+	// IndexNamespaces maintains a per-namespace key index so that
+	// ClearNamespace runs in O(entries in the namespace) instead of scanning
+	// the whole table. The namespace of a key is its prefix before the first
+	// NamespaceSeparator. Costs a striped mutex and a map insertion per write.
+	// Default: false.
+	IndexNamespaces bool
+
+	// MemoryAccounting records the key and value size of every write so that
+	// MemoryUsage can report the largest entries. Debugging aid: sizing a
+	// value walks it with reflection on every Set. Default: false.
+	MemoryAccounting bool
+
+	// SizeOf computes value sizes for MemoryAccounting. Default: SizeOf.
+	SizeOf func(value interface{}) int64
+
+	// FrequencyEstimator replaces the built-in frequency sketch.
+	// Default: nil (4-bit Count-Min sketch, see NewFrequencySketch).
+	FrequencyEstimator FrequencyEstimator
+
+	// EvictionPolicy replaces the built-in victim selection.
+	// Default: nil (least frequent sampled entry, see LeastFrequentPolicy).
+	EvictionPolicy EvictionPolicy
+
+	// AdmissionPolicy decides whether a new key may displace the entry
+	// chosen for eviction when the cache is full (see admission.go): use
+	// TinyLFUAdmission to keep one-pass scans out of the cache, or an
+	// AdmissionFunc to admit by key. Default: nil (every new key is admitted,
+	// see AlwaysAdmit).
+	AdmissionPolicy AdmissionPolicy
+
+	// SketchDecayInterval halves the access frequencies every interval (see
+	// DecaySketch), in addition to the built-in aging after 10*MaxSize
+	// accesses, so keys that stopped being popular lose their protection
+	// against eviction within a bounded time even under light traffic.
+	// Default: 0 (access-count aging only). Typical values: 1-60 minutes.
+	SketchDecayInterval time.Duration
+
+	// StatsWindow enables WindowStats: the counters are sampled every
+	// StatsWindow/60 so the activity (hit ratio, evictions, ...) of any window
+	// up to StatsWindow can be reported, unlike the cumulative Stats.
+	// Default: 0 (disabled). Typical values: 5-60 minutes.
+	StatsWindow time.Duration
+
+	// HitRatioAlert calls its Callback when the hit ratio of the last
+	// HitRatioAlert.Window falls below HitRatioAlert.Threshold, and again when
+	// it recovers (see hit_ratio_alert.go). Enables StatsWindow.
+	// Default: zero value (disabled).
+	HitRatioAlert HitRatioAlert
+
+	// EvictionAuditSize enables the eviction audit: the last EvictionAuditSize
+	// eviction decisions (victim, its frequency, the candidates it was chosen
+	// from) are kept in a ring buffer returned by DebugStats. Debugging aid
+	// for "why was my hot key evicted?". Default: 0 (disabled).
+	EvictionAuditSize int
+
+	// TrackEntryTimes records when each entry was inserted, last written and
+	// last read, reported by EntryInfo. Costs 24 bytes per table slot and an
+	// atomic store per Get hit. Default: false (EntryInfo reports the
+	// frequency, expiry, weight and source only).
+	TrackEntryTimes bool
+
+	// TrackMissReasons records why keys leave the cache, so that misses can
+	// be told apart: GetWithReason reports the MissReason and CacheStats
+	// counts the misses of expired, evicted and rejected keys. Costs 8 bytes
+	// per table slot. Default: false (every miss is MissAbsent).
+	TrackMissReasons bool
+
+	// TrackProbeLengths counts the table slots examined by every Get, Set
+	// and Delete, reported as CacheStats.AvgProbeLength and MaxProbeLength,
+	// to detect clustering (see probe_stats.go). Costs two atomic adds per
+	// operation. Enabled as well when MetricsCollector implements
+	// ProbeCountRecorder. Default: false.
+	TrackProbeLengths bool
+
+	// MaxWeight bounds the total weight of the cached entries, as computed by
+	// Weigher. Entries are evicted while the total exceeds it; a single value
+	// heavier than MaxWeight is not cached. MaxSize still bounds the number
+	// of entries. Default: 0 (capacity by entry count only).
+	MaxWeight int64
+
+	// Weigher returns the weight of an entry (e.g. its size in bytes). Used
+	// only with MaxWeight; weights are clamped to [0, math.MaxInt32].
+	// Use WeigherFor to write it against a GenericCache value type.
+	// Default: nil (every entry weighs 1).
+	Weigher func(key string, value interface{}) int64
+
+	// ValueCodec encodes values in the table, typically to compress large
+	// JSON or text blobs (see value_codec.go, NewCompressionCodec and the
+	// github.com/agilira/balios/compress module). Values are encoded on write and
+	// decoded on read; with MaxWeight, entries are weighed in their encoded
+	// form. Unrelated to Codec, which encodes snapshots.
+	// Default: nil (values stored as is).
+	ValueCodec ValueCodec
+
+	// ValueArena packs the values encoded by ValueCodec into large shared
+	// byte slabs instead of one allocation per value, so huge caches of
+	// small values hold a few pointer-free slabs for the garbage collector
+	// to track (see value_arena.go). Garbage left by replaced and removed
+	// values is reclaimed by a background compactor and by Compact.
+	// Requires ValueCodec; ignored without one. Default: false.
+	ValueArena bool
+
+	// ValueArenaSlabSize is the size of a ValueArena slab in bytes; values
+	// larger than a quarter of it are allocated separately.
+	// Default: DefaultValueArenaSlabSize (1 MiB).
+	ValueArenaSlabSize int
+
+	// MaxBytes bounds the total size of the values of a BytesCache (see
+	// NewBytesCache), which charges every entry len(value) against it.
+	// Ignored by NewCache and NewGenericCache, where MaxWeight and Weigher
+	// express the same bound. Default: 0 (entry count only).
+	MaxBytes int64
+
+	// CopyOnGet makes a BytesCache return a copy of the cached bytes, so
+	// callers cannot mutate the shared value. Ignored by NewCache and
+	// NewGenericCache. Default: false (Get returns the cached slice).
+	CopyOnGet bool
+
+	// ValueEqual compares values for CompareAndSwap.
+	// If nil, values are compared with == and non-comparable values (slices,
+	// maps, structs containing them) never match instead of panicking.
+	// Provide it to enable conditional updates of such values, e.g.
+	// func(a, b any) bool { return proto.Equal(a.(proto.Message), b.(proto.Message)) }.
+	ValueEqual func(a, b interface{}) bool
+
+	// OnEvict is called once for every value that leaves the cache, with the
+	// reason: eviction, expiration, deletion (including Clear) or replacement
+	// by a newer value of the same key. Use it to release resources owned by
+	// cached values; OnEvictFor adapts a typed listener for GenericCache.
+	// Called inline: it must be fast and non-blocking. A panic is recovered
+	// and logged through Logger. Default: nil.
+	OnEvict func(key string, value interface{}, reason EvictReason)
 
 	// OnExpire is called when an entry expires (TTL-based removal).
 	// This callback must be fast and non-blocking.
+	//
+	// Deprecated: use OnEvict, which reports expirations with ReasonExpired.
 	OnExpire func(key string, value interface{})
+
+	// OnEntryEvent receives eviction and expiration events, including the
+	// source tag recorded by SetWithSource / WithSource, to trace which code
+	// path wrote a given entry. Called inline: it must be fast and non-blocking.
+	// If it panics it is disabled and the panic is logged once through Logger.
+	OnEntryEvent func(event EntryEvent)
 }
 
 // Validate checks configuration parameters and applies sensible defaults.
@@ -74,17 +537,29 @@ type Config struct {
 //
 // Default values applied:
 //   - MaxSize: DefaultMaxSize (10,000) if <= 0
+//   - ResizeLimit: MaxSize if < MaxSize
 //   - WindowRatio: DefaultWindowRatio (0.01) if <= 0 or >= 1
 //   - CounterBits: DefaultCounterBits (4) if < 1 or > 8
-//   - CleanupInterval: TTL/10 if TTL > 0 and CleanupInterval <= 0
+//   - ShrinkThreshold: DefaultShrinkThreshold if <= 0 or >= 1
+//   - MaxDependencyEdges: 4 * MaxSize if <= 0
+//   - MemoryPressureThreshold: 0 (disabled) unless between 0 and 1
+//   - MemoryPressureInterval: DefaultMemoryPressureInterval if <= 0
+//   - HitRatioAlert: disabled unless Threshold is in (0, 1]; Window
+//     DefaultHitRatioAlertWindow if <= 0, MinLookups
+//     DefaultHitRatioAlertMinLookups if 0, StatsWindow raised to Window
 //   - Logger: NoOpLogger{} if nil
 //   - TimeProvider: systemTimeProvider{} if nil
-//   - MetricsCollector: NoOpMetricsCollector{} if nil
+//   - MetricsCollector: MetricsCollectorV2 bound to Name if set, otherwise
+//     NoOpMetricsCollector{} if nil
 func (c *Config) Validate() error {
 	if c.MaxSize <= 0 {
 		c.MaxSize = DefaultMaxSize
 	}
 
+	if c.ResizeLimit < c.MaxSize {
+		c.ResizeLimit = c.MaxSize
+	}
+
 	if c.WindowRatio <= 0 || c.WindowRatio >= 1 {
 		c.WindowRatio = DefaultWindowRatio
 	}
@@ -93,13 +568,72 @@ func (c *Config) Validate() error {
 		c.CounterBits = DefaultCounterBits
 	}
 
-	if c.TTL > 0 && c.CleanupInterval <= 0 {
-		c.CleanupInterval = c.TTL / 10
-		if c.CleanupInterval < time.Second {
-			c.CleanupInterval = time.Second
+	if c.ShrinkThreshold <= 0 || c.ShrinkThreshold >= 1 {
+		c.ShrinkThreshold = DefaultShrinkThreshold
+	}
+
+	if c.MaxDependencyEdges <= 0 {
+		c.MaxDependencyEdges = 4 * c.MaxSize
+	}
+
+	if !(c.MemoryPressureThreshold > 0 && c.MemoryPressureThreshold < 1) { // Also rejects NaN
+		c.MemoryPressureThreshold = 0
+	}
+	if c.MemoryPressureInterval <= 0 {
+		c.MemoryPressureInterval = DefaultMemoryPressureInterval
+	}
+
+	if !(c.HitRatioAlert.Threshold > 0 && c.HitRatioAlert.Threshold <= 1) { // Also rejects NaN
+		c.HitRatioAlert.Threshold = 0
+	}
+	if c.HitRatioAlert.enabled() {
+		if c.HitRatioAlert.Window <= 0 {
+			c.HitRatioAlert.Window = DefaultHitRatioAlertWindow
+		}
+		if c.HitRatioAlert.MinLookups == 0 {
+			c.HitRatioAlert.MinLookups = DefaultHitRatioAlertMinLookups
+		}
+		if c.StatsWindow < c.HitRatioAlert.Window {
+			c.StatsWindow = c.HitRatioAlert.Window
 		}
 	}
 
+	if !(c.TTLJitter >= 0 && c.TTLJitter < 1) { // Also rejects NaN
+		c.TTLJitter = 0
+	}
+
+	if c.EventBufferSize <= 0 {
+		c.EventBufferSize = DefaultEventBufferSize
+	}
+
+	if c.TombstoneTTL > 0 && c.TombstoneJitter <= 0 {
+		c.TombstoneJitter = c.TombstoneTTL
+	}
+
+	if c.WriteBehindQueueSize <= 0 {
+		c.WriteBehindQueueSize = DefaultWriteBehindQueueSize
+	}
+	if c.WriteBehindBatchSize <= 0 {
+		c.WriteBehindBatchSize = DefaultWriteBehindBatchSize
+	}
+	if c.WriteBehindWorkers <= 0 {
+		c.WriteBehindWorkers = 1
+	}
+	if c.WriteBehindInterval <= 0 {
+		c.WriteBehindInterval = DefaultWriteBehindInterval
+	}
+	if c.WriteBehindRetries == 0 {
+		c.WriteBehindRetries = DefaultWriteBehindRetries
+	}
+
+	if c.KeyReadRetries <= 0 {
+		c.KeyReadRetries = DefaultKeyReadRetries
+	}
+
+	if c.Codec == nil {
+		c.Codec = GobCodec{}
+	}
+
 	if c.Logger == nil {
 		c.Logger = NoOpLogger{}
 	}
@@ -108,6 +642,9 @@ func (c *Config) Validate() error {
 		c.TimeProvider = &systemTimeProvider{}
 	}
 
+	if _, noop := c.MetricsCollector.(NoOpMetricsCollector); c.MetricsCollectorV2 != nil && (c.MetricsCollector == nil || noop) {
+		c.MetricsCollector = bindMetricsCollector(c.MetricsCollectorV2, c.Name)
+	}
 	if c.MetricsCollector == nil {
 		c.MetricsCollector = NoOpMetricsCollector{}
 	}
@@ -127,6 +664,13 @@ func DefaultConfig() Config {
 	}
 }
 
+// DefaultTimeResolution returns the refresh period of the go-timecache clock
+// behind the default TimeProvider (500µs). Expiration decisions and latencies
+// measured with the default clock are quantized to it.
+func DefaultTimeResolution() time.Duration {
+	return timecache.DefaultCache().Resolution()
+}
+
 // systemTimeProvider is the default time provider using go-timecache.
 // This provides ~121x faster time access compared to time.Now() with zero allocations.
 type systemTimeProvider struct{}
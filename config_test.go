@@ -43,18 +43,17 @@ func TestConfig_Validate(t *testing.T) {
 			},
 		},
 		{
-			name: "TTL sets cleanup interval",
+			name: "TTL leaves background cleanup disabled",
 			config: Config{
 				TTL: 10 * time.Second,
 			},
 			want: Config{
-				MaxSize:         DefaultMaxSize,
-				WindowRatio:     DefaultWindowRatio,
-				CounterBits:     DefaultCounterBits,
-				TTL:             10 * time.Second,
-				CleanupInterval: time.Second,
-				Logger:          NoOpLogger{},
-				TimeProvider:    &systemTimeProvider{},
+				MaxSize:      DefaultMaxSize,
+				WindowRatio:  DefaultWindowRatio,
+				CounterBits:  DefaultCounterBits,
+				TTL:          10 * time.Second,
+				Logger:       NoOpLogger{},
+				TimeProvider: &systemTimeProvider{},
 			},
 		},
 	}
@@ -0,0 +1,108 @@
+// context_ops.go: deadline-bounded Get and Set
+//
+// GetCtx and SetCtx retry through contention until it clears or the
+// context is done.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"runtime"
+)
+
+// GetCtx retrieves a value like Get, retrying lookups abandoned under write
+// contention until they succeed or ctx is done. A miss is not an error.
+// Returns BALIOS_EMPTY_KEY for an empty key and a retryable
+// BALIOS_CONTEXT_CANCELED error wrapping ctx.Err() if ctx is done first.
+func (c *wtinyLFUCache) GetCtx(ctx context.Context, key string) (interface{}, bool, error) {
+	if key == "" {
+		return nil, false, NewErrEmptyKey("GetCtx")
+	}
+	if c.isClosed() {
+		return nil, false, NewErrCacheClosed("GetCtx")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, NewErrContextCanceled("GetCtx", key, err)
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	keyHash := c.hashKey(key)
+	c.incrementFrequency(keyHash)
+
+	for {
+		value, found, contended := c.lookup(key, keyHash, c.ttlClock(c.timeProvider.Now()))
+		if found || !contended {
+			c.recordGet(ctx, key, start, found)
+			return value, found, nil
+		}
+		if err := ctx.Err(); err != nil {
+			c.recordGet(ctx, key, start, false)
+			return nil, false, NewErrContextCanceled("GetCtx", key, err)
+		}
+		runtime.Gosched()
+	}
+}
+
+// SetCtx stores a key-value pair like Set, retrying while every candidate
+// slot is held by concurrent writers until the write succeeds or ctx is
+// done. Returns BALIOS_EMPTY_KEY for an empty key, BALIOS_SET_FAILED for a
+// value that cannot be stored (encoding failure, heavier than MaxWeight)
+// and a retryable BALIOS_CONTEXT_CANCELED error wrapping ctx.Err() if ctx
+// is done first.
+func (c *wtinyLFUCache) SetCtx(ctx context.Context, key string, value interface{}) error {
+	if key == "" {
+		return NewErrEmptyKey("SetCtx")
+	}
+	if c.isClosed() {
+		return NewErrCacheClosed("SetCtx")
+	}
+	if err := ctx.Err(); err != nil {
+		return NewErrContextCanceled("SetCtx", key, err)
+	}
+
+	stored, err := c.prepareSet("SetCtx", key, value) // See set_errors.go
+	if err != nil {
+		return err
+	}
+
+	for !c.setStored(key, stored, "", c.ttlNanos) {
+		if c.isClosed() {
+			return NewErrCacheClosed("SetCtx")
+		}
+		if err := ctx.Err(); err != nil {
+			return NewErrContextCanceled("SetCtx", key, err)
+		}
+		runtime.Gosched()
+	}
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return nil
+}
+
+// GetCtx retrieves a value like Get, retrying through write contention until
+// ctx is done (see Cache.GetCtx). A value of a type other than V is reported
+// as not found.
+func (c *GenericCache[K, V]) GetCtx(ctx context.Context, key K) (V, bool, error) {
+	var zero V
+	val, found, err := c.inner.GetCtx(ctx, keyToString(key))
+	if err != nil || !found {
+		return zero, false, err
+	}
+	typedValue, ok := val.(V)
+	if !ok {
+		return zero, false, nil
+	}
+	return typedValue, true, nil
+}
+
+// SetCtx stores a key-value pair like Set, retrying through write contention
+// until ctx is done (see Cache.SetCtx).
+func (c *GenericCache[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	return c.inner.SetCtx(ctx, keyToString(key), value)
+}
@@ -0,0 +1,141 @@
+// context_ops_test.go: tests for deadline-bounded Get and Set
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetCtx_HitMissAndValidation(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+	ctx := context.Background()
+
+	if v, found, err := cache.GetCtx(ctx, "a"); err != nil || !found || v != 1 {
+		t.Errorf("GetCtx hit = %v, %v, %v", v, found, err)
+	}
+	if _, found, err := cache.GetCtx(ctx, "missing"); err != nil || found {
+		t.Errorf("a miss is not an error, got %v, %v", found, err)
+	}
+	if _, _, err := cache.GetCtx(ctx, ""); !IsEmptyKey(err) {
+		t.Errorf("expected BALIOS_EMPTY_KEY, got %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := cache.GetCtx(canceled, "a"); !IsContextCanceled(err) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected BALIOS_CONTEXT_CANCELED wrapping context.Canceled, got %v", err)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %+v", stats)
+	}
+}
+
+func TestGetCtx_RetriesThroughContention(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, KeyReadRetries: 2}).(*wtinyLFUCache)
+	cache.Set("hot", "value")
+	entry := cache.findEntry("hot", stringHash("hot"))
+	if entry == nil {
+		t.Fatal("entry not found")
+	}
+
+	// An odd SeqLock version simulates a writer rewriting the key forever
+	atomic.AddUint64(&entry.version, 1)
+	if _, found := cache.Get("hot"); found {
+		t.Fatal("Get should give up on the contended key")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, found, err := cache.GetCtx(ctx, "hot")
+	if found || !IsContextCanceled(err) || !errors.Is(err, context.DeadlineExceeded) || !IsRetryable(err) {
+		t.Errorf("expected a retryable BALIOS_CONTEXT_CANCELED, got %v, %v", found, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetCtx should give up at the deadline, took %v", elapsed)
+	}
+
+	// The writer finishes while GetCtx retries
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddUint64(&entry.version, 1)
+	}()
+	v, found, err := cache.GetCtx(context.Background(), "hot")
+	if err != nil || !found || v != "value" {
+		t.Errorf("GetCtx should succeed once contention clears, got %v, %v, %v", v, found, err)
+	}
+}
+
+func TestSetCtx_RetriesThroughContention(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 16}).(*wtinyLFUCache)
+
+	// Every slot held by a (simulated) concurrent writer
+	for i := range cache.table.Load().entries {
+		atomic.StoreInt32(&cache.table.Load().entries[i].valid, entryPending)
+	}
+	if cache.Set("k", 1) {
+		t.Fatal("Set should fail when every slot is held")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cache.SetCtx(ctx, "k", 1); !IsContextCanceled(err) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected BALIOS_CONTEXT_CANCELED, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		for i := range cache.table.Load().entries {
+			atomic.StoreInt32(&cache.table.Load().entries[i].valid, entryEmpty)
+		}
+	}()
+	if err := cache.SetCtx(context.Background(), "k", 1); err != nil {
+		t.Fatalf("SetCtx should succeed once slots are released: %v", err)
+	}
+	if v, ok := cache.Get("k"); !ok || v != 1 {
+		t.Errorf("expected the stored value, got %v, %v", v, ok)
+	}
+}
+
+func TestSetCtx_NonRetryableFailures(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:   10,
+		MaxWeight: 10,
+		Weigher:   func(_ string, v interface{}) int64 { return int64(v.(int)) },
+	}).(*wtinyLFUCache)
+
+	if err := cache.SetCtx(context.Background(), "", 1); !IsEmptyKey(err) {
+		t.Errorf("expected BALIOS_EMPTY_KEY, got %v", err)
+	}
+	// A too heavy value fails at once, even without a deadline
+	if err := cache.SetCtx(context.Background(), "heavy", 11); GetErrorCode(err) != ErrCodeSetFailed {
+		t.Errorf("expected BALIOS_SET_FAILED, got %v", err)
+	}
+	if err := cache.SetCtx(context.Background(), "light", 3); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestGenericCache_GetCtxSetCtx(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 100})
+	ctx := context.Background()
+
+	if err := cache.SetCtx(ctx, 1, "one"); err != nil {
+		t.Fatal(err)
+	}
+	if v, found, err := cache.GetCtx(ctx, 1); err != nil || !found || v != "one" {
+		t.Errorf("GetCtx = %q, %v, %v", v, found, err)
+	}
+	if _, found, err := cache.GetCtx(ctx, 2); err != nil || found {
+		t.Errorf("expected a miss, got %v, %v", found, err)
+	}
+}
@@ -0,0 +1,167 @@
+// dependency.go: group invalidation through a bounded dependency index
+//
+// SetWithDependencies records the inputs of an entry; InvalidateKey removes
+// an input and, transitively, everything derived from it.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync"
+
+// dependencyIndex maps inputs to the keys derived from them.
+type dependencyIndex struct {
+	mu         sync.Mutex
+	maxEdges   int
+	edges      int
+	dependents map[string]map[string]struct{} // dep -> keys derived from it
+	deps       map[string][]string            // key -> its deps (for replacement and pruning)
+}
+
+func newDependencyIndex(maxEdges int) *dependencyIndex {
+	return &dependencyIndex{
+		maxEdges:   maxEdges,
+		dependents: make(map[string]map[string]struct{}),
+		deps:       make(map[string][]string),
+	}
+}
+
+// removeKeyLocked drops all edges recorded for key.
+func (d *dependencyIndex) removeKeyLocked(key string) {
+	for _, dep := range d.deps[key] {
+		if set := d.dependents[dep]; set != nil {
+			delete(set, key)
+			if len(set) == 0 {
+				delete(d.dependents, dep)
+			}
+		}
+		d.edges--
+	}
+	delete(d.deps, key)
+}
+
+// addLocked records key -> deps edges, replacing previous ones.
+func (d *dependencyIndex) addLocked(key string, deps []string) {
+	d.removeKeyLocked(key)
+	if len(deps) == 0 {
+		return
+	}
+	recorded := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		set := d.dependents[dep]
+		if set == nil {
+			set = make(map[string]struct{})
+			d.dependents[dep] = set
+		}
+		if _, dup := set[key]; dup {
+			continue
+		}
+		set[key] = struct{}{}
+		recorded = append(recorded, dep)
+	}
+	d.deps[key] = recorded
+	d.edges += len(recorded)
+}
+
+// pruneLocked drops edges of keys no longer present in the cache.
+func (d *dependencyIndex) pruneLocked(present func(string) bool) {
+	for key := range d.deps {
+		if !present(key) {
+			d.removeKeyLocked(key)
+		}
+	}
+}
+
+func (d *dependencyIndex) reset() {
+	d.mu.Lock()
+	d.edges = 0
+	d.dependents = make(map[string]map[string]struct{})
+	d.deps = make(map[string][]string)
+	d.mu.Unlock()
+}
+
+// len returns the number of edges in the index.
+func (d *dependencyIndex) len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.edges
+}
+
+// SetWithDependencies stores a key-value pair derived from deps. Invalidating
+// any of deps with InvalidateKey removes key as well (transitively).
+// Returns false if the value was not stored, including when the dependency
+// index is full (see Config.MaxDependencyEdges).
+func (c *wtinyLFUCache) SetWithDependencies(key string, value interface{}, deps ...string) bool {
+	if !c.setWithDependencies(key, value, "", c.ttlNanos, deps) {
+		return false
+	}
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return true
+}
+
+// setWithDependencies implements SetWithDependencies and GetOrLoad's WithTags.
+func (c *wtinyLFUCache) setWithDependencies(key string, value interface{}, source string, ttlNanos int64, deps []string) bool {
+	if key == "" {
+		return false
+	}
+	d := c.dependencies
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.edges-len(d.deps[key])+len(deps) > d.maxEdges {
+		d.pruneLocked(c.Has)
+		if d.edges-len(d.deps[key])+len(deps) > d.maxEdges {
+			return false
+		}
+	}
+
+	if !c.set(key, value, source, ttlNanos) {
+		return false
+	}
+	d.addLocked(key, deps)
+	return true
+}
+
+// InvalidateKey deletes dep and every entry that depends on it, directly or
+// transitively. Returns the number of cache entries removed.
+func (c *wtinyLFUCache) InvalidateKey(dep string) int {
+	d := c.dependencies
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	removed := 0
+	visited := map[string]struct{}{dep: {}}
+	queue := []string{dep}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if c.Delete(current) {
+			removed++
+		}
+		for key := range d.dependents[current] {
+			if _, seen := visited[key]; !seen {
+				visited[key] = struct{}{}
+				queue = append(queue, key)
+			}
+		}
+		d.removeKeyLocked(current)
+	}
+	return removed
+}
+
+// SetWithDependencies stores a key-value pair derived from deps.
+// See the Cache interface for details.
+func (c *GenericCache[K, V]) SetWithDependencies(key K, value V, deps ...string) bool {
+	return c.inner.SetWithDependencies(keyToString(key), value, deps...)
+}
+
+// InvalidateKey deletes dep and every entry that depends on it.
+// dep may be a cache key (in its string form) or an external identifier.
+func (c *GenericCache[K, V]) InvalidateKey(dep string) int {
+	return c.inner.InvalidateKey(dep)
+}
@@ -0,0 +1,130 @@
+// dependency_test.go: tests for dependency-based group invalidation
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestInvalidateKey_Cascades(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+
+	cache.Set("user:1", "alice")
+	cache.Set("user:2", "bob")
+	cache.SetWithDependencies("team:a", "alice+bob", "user:1", "user:2")
+	cache.SetWithDependencies("report:a", "team a report", "team:a", "table:teams")
+	cache.SetWithDependencies("unrelated", "x", "user:3")
+
+	if removed := cache.InvalidateKey("user:1"); removed != 3 {
+		t.Errorf("expected user:1, team:a and report:a removed, got %d", removed)
+	}
+	for _, key := range []string{"user:1", "team:a", "report:a"} {
+		if cache.Has(key) {
+			t.Errorf("%s should be invalidated", key)
+		}
+	}
+	for _, key := range []string{"user:2", "unrelated"} {
+		if !cache.Has(key) {
+			t.Errorf("%s should survive", key)
+		}
+	}
+
+	// External identifiers are not cache keys themselves
+	cache.SetWithDependencies("view", 1, "table:teams")
+	if removed := cache.InvalidateKey("table:teams"); removed != 1 || cache.Has("view") {
+		t.Errorf("external dependency: removed=%d", removed)
+	}
+}
+
+func TestInvalidateKey_CyclesAndReplacement(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	cache.SetWithDependencies("a", 1, "b")
+	cache.SetWithDependencies("b", 2, "a")
+	if removed := cache.InvalidateKey("a"); removed != 2 {
+		t.Errorf("cycle: expected 2 removed, got %d", removed)
+	}
+
+	// Re-setting a key replaces its dependencies
+	cache.SetWithDependencies("derived", 1, "old-input", "old-input")
+	cache.SetWithDependencies("derived", 2, "new-input")
+	if removed := cache.InvalidateKey("old-input"); removed != 0 || !cache.Has("derived") {
+		t.Error("stale dependency must not invalidate the new value")
+	}
+	if n := cache.dependencies.len(); n != 1 {
+		t.Errorf("expected 1 edge, got %d", n)
+	}
+
+	cache.Clear()
+	if n := cache.dependencies.len(); n != 0 {
+		t.Errorf("Clear must reset the dependency index, got %d edges", n)
+	}
+}
+
+func TestSetWithDependencies_BoundedIndex(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxDependencyEdges: 4}).(*wtinyLFUCache)
+
+	if !cache.SetWithDependencies("a", 1, "x", "y") || !cache.SetWithDependencies("b", 1, "x", "y") {
+		t.Fatal("edges within the bound must be accepted")
+	}
+	if cache.SetWithDependencies("c", 1, "x") {
+		t.Error("entry must not be cached when the index is full")
+	}
+	if cache.Has("c") {
+		t.Error("rejected entry must not be stored")
+	}
+
+	// Edges of entries that left the cache are pruned to make room
+	cache.Delete("a")
+	if !cache.SetWithDependencies("c", 1, "x") {
+		t.Error("pruning should free room for new edges")
+	}
+	if n := cache.dependencies.len(); n != 3 {
+		t.Errorf("expected 3 edges after pruning, got %d", n)
+	}
+}
+
+func TestDependencies_GenericAndConcurrent(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 10_000})
+	cache.Set(1, "input")
+	cache.SetWithDependencies(2, "derived", "1")
+	if removed := cache.InvalidateKey("1"); removed != 2 || cache.Has(2) {
+		t.Errorf("generic invalidation: removed=%d", removed)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				cache.SetWithDependencies(g*1000+i, "v", "input:"+strconv.Itoa(i%10))
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				cache.InvalidateKey("input:" + strconv.Itoa(i%10))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// After a final invalidation of every input, no derived entry may survive
+	for i := 0; i < 10; i++ {
+		cache.InvalidateKey("input:" + strconv.Itoa(i))
+	}
+	for g := 0; g < 4; g++ {
+		for i := 0; i < 500; i++ {
+			if cache.Has(g*1000 + i) {
+				t.Fatalf("derived entry %d survived invalidation of its input", g*1000+i)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// duplicate_stats.go: observability of duplicate-key cleanup and write races
+//
+// Counters for duplicate-key cleanups and lost slot CASes (write races).
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// duplicateDistanceBuckets is the number of power-of-two probe distance
+// buckets (0, 1, 2-3, 4-7, 8-15, 16-31) covering duplicateScanRange.
+const duplicateDistanceBuckets = 6
+
+// DuplicateCleanupRecorder is an optional MetricsCollector extension.
+// Collectors implementing it are notified of every duplicate-key cleanup.
+type DuplicateCleanupRecorder interface {
+	// RecordDuplicateCleanup records the removal of a duplicate entry found
+	// probeDistance slots from the key's home position.
+	RecordDuplicateCleanup(probeDistance int)
+}
+
+// RaceConditionRecorder is an optional MetricsCollector extension.
+// Collectors implementing it are notified of every write race: a Set,
+// Delete or duplicate cleanup that lost a slot compare-and-swap to a
+// concurrent writer.
+type RaceConditionRecorder interface {
+	// RecordRaceCondition records a lost slot compare-and-swap.
+	RecordRaceCondition()
+}
+
+// ProbeDistanceCount is one bucket of a probe distance histogram.
+type ProbeDistanceCount struct {
+	MinDistance int
+	MaxDistance int
+	Count       uint64
+}
+
+// DebugStats provides internal diagnostics beyond CacheStats.
+type DebugStats struct {
+	// DuplicateCleanups is the number of duplicate entries removed after
+	// concurrent insertions of the same key (same as CacheStats).
+	DuplicateCleanups uint64
+
+	// DuplicatesByProbeDistance breaks DuplicateCleanups down by the distance
+	// of the removed duplicate from the key's home slot.
+	DuplicatesByProbeDistance []ProbeDistanceCount
+
+	// EvictionAudit holds the most recent eviction decisions, oldest first
+	// (empty unless Config.EvictionAuditSize is set).
+	EvictionAudit []EvictionDecision
+
+	// EvictionDecisions is the number of evictions audited, including those
+	// no longer in EvictionAudit.
+	EvictionDecisions uint64
+
+	// SketchDecays is the number of frequency decays requested with
+	// DecaySketch or Config.SketchDecayInterval (the built-in aging after
+	// 10*MaxSize accesses is not counted).
+	SketchDecays uint64
+
+	// MemoryPressureEvictions is the number of entries evicted by the
+	// memory-pressure controller (see Config.MemoryPressureThreshold).
+	MemoryPressureEvictions uint64
+
+	// InvalidationsReceived is the number of invalidation events from other
+	// caches applied through Config.InvalidationBus.
+	InvalidationsReceived uint64
+
+	// AdmissionWindowSize is the current size of the admission window, in
+	// entries (0 unless Config.AdaptiveWindow).
+	AdmissionWindowSize int
+
+	// AdmissionWindowAdjustments is the number of times the hill climber
+	// changed AdmissionWindowSize.
+	AdmissionWindowAdjustments uint64
+
+	// ValueArenaBytes approximates the memory held by the value arena: the
+	// slabs allocated since the last compaction plus the live bytes it kept
+	// (0 unless Config.ValueArena).
+	ValueArenaBytes int64
+
+	// ValueArenaLiveBytes is the size of the live values measured by the
+	// last arena compaction.
+	ValueArenaLiveBytes int64
+
+	// ValueArenaCompactions is the number of arena compactions completed.
+	ValueArenaCompactions uint64
+}
+
+// recordDuplicateCleanup accounts a removed duplicate at the given probe distance.
+func (c *wtinyLFUCache) recordDuplicateCleanup(distance uint32) {
+	atomic.AddInt64(&c.duplicateCleanups, 1)
+	bucket := bits.Len32(distance)
+	if bucket >= duplicateDistanceBuckets {
+		bucket = duplicateDistanceBuckets - 1
+	}
+	atomic.AddInt64(&c.duplicatesByDistance[bucket], 1)
+
+	if r, ok := c.metricsCollector.(DuplicateCleanupRecorder); ok {
+		r.RecordDuplicateCleanup(int(distance))
+	}
+	if n, ok := c.anomalies.due(anomalyDuplicateCleanup); ok {
+		c.anomalies.log(anomalyDuplicateCleanup, n, "probe_distance", distance)
+	}
+}
+
+// recordRace accounts a lost slot compare-and-swap of a writer.
+func (c *wtinyLFUCache) recordRace() {
+	atomic.AddInt64(&c.raceConditions, 1)
+	if c.raceRecorder != nil {
+		c.raceRecorder.RecordRaceCondition()
+	}
+}
+
+// DebugStats returns internal diagnostics (duplicate-key cleanup breakdown,
+// eviction audit).
+func (c *wtinyLFUCache) DebugStats() DebugStats {
+	stats := DebugStats{
+		DuplicateCleanups:         uint64(atomic.LoadInt64(&c.duplicateCleanups)), // #nosec G115 - counter is always positive
+		DuplicatesByProbeDistance: make([]ProbeDistanceCount, duplicateDistanceBuckets),
+		SketchDecays:              uint64(atomic.LoadInt64(&c.sketchDecays)),          // #nosec G115 - counter is always positive
+		InvalidationsReceived:     uint64(atomic.LoadInt64(&c.invalidationsReceived)), // #nosec G115 - counter is always positive
+		MemoryPressureEvictions:   uint64(atomic.LoadInt64(&c.pressureEvicted)),       // #nosec G115 - counter is always positive
+	}
+	for i := range stats.DuplicatesByProbeDistance {
+		lo, hi := 0, 0
+		if i > 0 {
+			lo, hi = 1<<(i-1), 1<<i-1
+		}
+		stats.DuplicatesByProbeDistance[i] = ProbeDistanceCount{
+			MinDistance: lo,
+			MaxDistance: hi,
+			Count:       uint64(atomic.LoadInt64(&c.duplicatesByDistance[i])), // #nosec G115 - counter is always positive
+		}
+	}
+	if c.evictionAudit != nil {
+		stats.EvictionAudit, stats.EvictionDecisions = c.evictionAudit.snapshot()
+	}
+	if t := c.table.Load(); t != nil && t.admissionWindow != nil {
+		stats.AdmissionWindowSize, stats.AdmissionWindowAdjustments = t.admissionWindow.stats()
+	}
+	if c.arena != nil {
+		c.arenaDebugStats(&stats)
+	}
+	return stats
+}
+
+// DebugStats returns internal diagnostics (duplicate-key cleanup breakdown,
+// eviction audit).
+func (c *GenericCache[K, V]) DebugStats() DebugStats {
+	return c.inner.DebugStats()
+}
+
+// RecordDuplicateCleanup forwards to the wrapped collector if it implements
+// DuplicateCleanupRecorder, unless it has been disabled.
+func (g *guardedMetricsCollector) RecordDuplicateCleanup(probeDistance int) {
+	r, ok := g.inner.(DuplicateCleanupRecorder)
+	if !ok || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordDuplicateCleanup")
+	r.RecordDuplicateCleanup(probeDistance)
+}
+
+// raceRecorderOf returns the RaceConditionRecorder of a collector built by
+// newGuardedMetricsCollector, or nil when the collector does not implement it.
+func raceRecorderOf(collector MetricsCollector) RaceConditionRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.race != nil {
+		return g
+	}
+	return nil
+}
+
+// RecordRaceCondition forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordRaceCondition() {
+	if g.race == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordRaceCondition")
+	g.race.RecordRaceCondition()
+}
@@ -0,0 +1,140 @@
+// duplicate_stats_test.go: tests for duplicate-key cleanup observability
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// duplicateRecorder is a collector implementing DuplicateCleanupRecorder.
+type duplicateRecorder struct {
+	NoOpMetricsCollector
+	mu        sync.Mutex
+	distances []int
+}
+
+func (r *duplicateRecorder) RecordDuplicateCleanup(probeDistance int) {
+	r.mu.Lock()
+	r.distances = append(r.distances, probeDistance)
+	r.mu.Unlock()
+}
+
+// plantDuplicate writes key into the slot at distance from its home position,
+// simulating the losing side of a concurrent insertion race.
+func plantDuplicate(c *wtinyLFUCache, key string, distance uint32) *entry {
+	keyHash := stringHash(key)
+	e := &c.table.Load().entries[(keyHash+uint64(distance))&uint64(c.table.Load().mask)]
+	e.valid = entryPending
+	c.populateEntry(c.table.Load(), e, key, keyHash, "dup", "", 0, 0, 0, entryEmpty)
+	return e
+}
+
+func TestDuplicateCleanup_StatsAndHistogram(t *testing.T) {
+	recorder := &duplicateRecorder{}
+	cache := NewCache(Config{MaxSize: 1000, MetricsCollector: recorder}).(*wtinyLFUCache)
+
+	keep := plantDuplicate(cache, "k", 0)
+	plantDuplicate(cache, "k", 1)
+	plantDuplicate(cache, "k", 5)
+	plantDuplicate(cache, "k", 20)
+	cache.removeDuplicateKeys(cache.table.Load(), "k", stringHash("k"), keep)
+
+	stats := cache.Stats()
+	if stats.DuplicateCleanups != 3 || stats.Size != 1 {
+		t.Fatalf("expected 3 cleanups leaving 1 entry, got %+v", stats)
+	}
+
+	debug := cache.DebugStats()
+	want := map[int]uint64{1: 1, 4: 1, 16: 1} // MinDistance -> count
+	var total uint64
+	for _, b := range debug.DuplicatesByProbeDistance {
+		if b.Count != want[b.MinDistance] {
+			t.Errorf("bucket [%d,%d]: got %d, want %d", b.MinDistance, b.MaxDistance, b.Count, want[b.MinDistance])
+		}
+		total += b.Count
+	}
+	if total != debug.DuplicateCleanups || len(debug.DuplicatesByProbeDistance) != duplicateDistanceBuckets {
+		t.Errorf("histogram inconsistent with total: %+v", debug)
+	}
+	last := debug.DuplicatesByProbeDistance[duplicateDistanceBuckets-1]
+	if last.MinDistance != 16 || last.MaxDistance < duplicateScanRange-1 {
+		t.Errorf("last bucket must cover the scan range: %+v", last)
+	}
+
+	if len(recorder.distances) != 3 || recorder.distances[2] != 20 {
+		t.Errorf("collector extension not notified: %v", recorder.distances)
+	}
+
+	cache.Clear()
+	if cache.Stats().DuplicateCleanups != 0 || cache.DebugStats().DuplicatesByProbeDistance[1].Count != 0 {
+		t.Error("Clear must reset duplicate cleanup counters")
+	}
+}
+
+func TestDuplicateCleanup_GenericAndPlainCollector(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, MetricsCollector: &mockMetricsCollector{}})
+	inner := cache.inner.(*wtinyLFUCache)
+
+	keep := plantDuplicate(inner, "k", 0)
+	plantDuplicate(inner, "k", 2)
+	inner.removeDuplicateKeys(inner.table.Load(), "k", stringHash("k"), keep) // Collector without the extension: no panic
+
+	if got := cache.DebugStats().DuplicateCleanups; got != 1 {
+		t.Errorf("expected 1 cleanup, got %d", got)
+	}
+}
+
+// raceRecorder is a collector implementing RaceConditionRecorder.
+type raceRecorder struct {
+	NoOpMetricsCollector
+	races atomic.Int64
+}
+
+func (r *raceRecorder) RecordRaceCondition() { r.races.Add(1) }
+
+func TestRaceConditions_StatsAndRecorder(t *testing.T) {
+	recorder := &raceRecorder{}
+	cache := NewCache(Config{MaxSize: 1000, MetricsCollector: recorder}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := "k" + strconv.Itoa(i%16)
+				cache.Set(key, g)
+				if i%3 == 0 {
+					cache.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	if got := uint64(recorder.races.Load()); got != stats.RaceConditions {
+		t.Fatalf("recorder saw %d races, Stats reports %d", got, stats.RaceConditions)
+	}
+
+	cache.recordRace()
+	if got := cache.Stats().RaceConditions; got != stats.RaceConditions+1 {
+		t.Fatalf("RaceConditions = %d, want %d", got, stats.RaceConditions+1)
+	}
+	if report := NewStatsReport(cache.Stats()); report.RaceConditions != stats.RaceConditions+1 {
+		t.Fatalf("StatsReport.RaceConditions = %d", report.RaceConditions)
+	}
+
+	cache.Clear()
+	if got := cache.Stats().RaceConditions; got != 0 {
+		t.Fatalf("Clear must reset RaceConditions, got %d", got)
+	}
+}
@@ -0,0 +1,132 @@
+// entry_info.go: per-entry metadata for debugging endpoints
+//
+// EntryInfo reports the standing of a live key: its frequency estimate,
+// expiry, weight, source and, with Config.TrackEntryTimes, its timestamps.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EntryInfo describes a live entry (see Cache.EntryInfo).
+type EntryInfo struct {
+	// Key is the key of the entry.
+	Key string
+
+	// Frequency is the estimated access frequency admission compares when
+	// the cache is full (0-15 with the built-in sketch).
+	Frequency uint64
+
+	// ExpiresAt is when the entry expires (zero if it does not).
+	ExpiresAt time.Time
+
+	// Weight is the weight charged for the entry (1 without Config.MaxWeight).
+	Weight int64
+
+	// Source is the tag of the code path that wrote it (see SetWithSource).
+	Source string
+
+	// InsertedAt is when the key entered the cache, UpdatedAt when its value
+	// was last written and LastAccess when it was last returned by a Get
+	// (zero if never). All three are zero unless Config.TrackEntryTimes.
+	InsertedAt time.Time
+	UpdatedAt  time.Time
+	LastAccess time.Time
+}
+
+// entryTimes holds the timestamps of a slot (TimeProvider clock, 0 = none).
+type entryTimes struct {
+	inserted int64
+	updated  int64
+	accessed int64
+}
+
+// recordWrite stamps a write of e, a slot of t: an insertion into an empty
+// or deleted slot, or a replacement of the value of its key.
+func (c *wtinyLFUCache) recordWrite(t *cacheTable, e *entry, inserted bool) {
+	if t.times == nil {
+		return
+	}
+	now := c.ttlClock(c.timeProvider.Now())
+	times := &t.times[t.slotOf(e)]
+	if inserted {
+		atomic.StoreInt64(&times.inserted, now)
+		atomic.StoreInt64(&times.accessed, 0)
+	}
+	atomic.StoreInt64(&times.updated, now)
+}
+
+// recordAccess stamps a Get hit on e, a slot of t, at ttlNow, renewing its
+// idle deadline with Config.TTI.
+func (c *wtinyLFUCache) recordAccess(t *cacheTable, e *entry, ttlNow int64) {
+	if t.times != nil {
+		atomic.StoreInt64(&t.times[t.slotOf(e)].accessed, ttlNow)
+	}
+	if c.ttiNanos > 0 {
+		c.touchIdle(t, e, ttlNow)
+	}
+}
+
+// EntryInfo returns the metadata of a live entry without touching
+// statistics, the frequency sketch or its access time. found is false if the
+// key is absent or expired.
+func (c *wtinyLFUCache) EntryInfo(key string) (info EntryInfo, found bool) {
+	if key == "" || c.isClosed() {
+		return EntryInfo{}, false
+	}
+	t := c.table.Load()
+	if t == nil {
+		return EntryInfo{}, false
+	}
+	g := t.gen.Load()
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	keyHash := c.hashKey(key)
+	e := c.findEntryIn(t, key, keyHash)
+	if e == nil || c.isExpired(e, ttlNow) {
+		return EntryInfo{}, false
+	}
+	holder, ok := e.value.Load().(*valueHolder)
+	if !ok || holder == nil {
+		return EntryInfo{}, false
+	}
+
+	info = EntryInfo{
+		Key:       key,
+		Frequency: c.estimateFrequency(keyHash),
+		ExpiresAt: timeOf(atomic.LoadInt64(&e.expireAt)),
+		Weight:    1,
+		Source:    holder.source(),
+	}
+	if c.maxWeight > 0 {
+		info.Weight = int64(atomic.LoadInt32(&e.weight))
+	}
+	if t.times != nil {
+		times := &t.times[t.slotOf(e)]
+		info.InsertedAt = timeOf(atomic.LoadInt64(&times.inserted))
+		info.UpdatedAt = timeOf(atomic.LoadInt64(&times.updated))
+		info.LastAccess = timeOf(atomic.LoadInt64(&times.accessed))
+	}
+	if atomic.LoadInt32(&e.valid) != g.live {
+		return EntryInfo{}, false // Removed while reading
+	}
+	return info, true
+}
+
+// timeOf converts a TimeProvider timestamp to a time.Time (zero for 0).
+func timeOf(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// EntryInfo returns the metadata of a live entry (see Cache.EntryInfo).
+func (c *GenericCache[K, V]) EntryInfo(key K) (EntryInfo, bool) {
+	return c.inner.EntryInfo(keyToString(key))
+}
@@ -0,0 +1,128 @@
+// entry_info_test.go: tests for EntryInfo and Config.TrackEntryTimes
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryInfo_Basic(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Minute, TimeProvider: timeProvider}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.SetWithSource("hot", 1, "warmup")
+	cache.Set("cold", 2)
+	for i := 0; i < 5; i++ {
+		cache.Get("hot")
+	}
+	stats := cache.Stats()
+
+	hot, ok := cache.EntryInfo("hot")
+	if !ok {
+		t.Fatal("EntryInfo(hot) not found")
+	}
+	cold, _ := cache.EntryInfo("cold")
+	if hot.Key != "hot" || hot.Source != "warmup" || hot.Weight != 1 {
+		t.Fatalf("unexpected info %+v", hot)
+	}
+	if hot.Frequency <= cold.Frequency {
+		t.Fatalf("hot frequency %d <= cold frequency %d", hot.Frequency, cold.Frequency)
+	}
+	wantExpiry := time.Unix(0, timeProvider.Now()).Add(time.Minute)
+	if !hot.ExpiresAt.Equal(wantExpiry) {
+		t.Fatalf("ExpiresAt = %v, want %v", hot.ExpiresAt, wantExpiry)
+	}
+	if !hot.InsertedAt.IsZero() || !hot.LastAccess.IsZero() {
+		t.Fatal("timestamps reported without TrackEntryTimes")
+	}
+
+	// No side effects
+	if after := cache.Stats(); after.Hits != stats.Hits || after.Misses != stats.Misses {
+		t.Fatal("EntryInfo changed the hit/miss counters")
+	}
+	if again, _ := cache.EntryInfo("hot"); again.Frequency != hot.Frequency {
+		t.Fatal("EntryInfo touched the frequency sketch")
+	}
+
+	if _, ok := cache.EntryInfo("missing"); ok {
+		t.Fatal("EntryInfo(missing) found")
+	}
+	timeProvider.Advance(2 * time.Minute)
+	if _, ok := cache.EntryInfo("hot"); ok {
+		t.Fatal("EntryInfo reported an expired entry")
+	}
+}
+
+func TestEntryInfo_TrackEntryTimes(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TrackEntryTimes: true, TimeProvider: timeProvider}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	inserted := time.Unix(0, timeProvider.Now())
+	cache.Set("k", 1)
+	info, _ := cache.EntryInfo("k")
+	if !info.InsertedAt.Equal(inserted) || !info.UpdatedAt.Equal(inserted) || !info.LastAccess.IsZero() {
+		t.Fatalf("after insert: %+v", info)
+	}
+	if !info.ExpiresAt.IsZero() {
+		t.Fatal("ExpiresAt set without TTL")
+	}
+
+	timeProvider.Advance(time.Second)
+	accessed := time.Unix(0, timeProvider.Now())
+	cache.Get("k")
+	cache.Has("k") // Not an access
+
+	timeProvider.Advance(time.Second)
+	updated := time.Unix(0, timeProvider.Now())
+	cache.Set("k", 2)
+
+	info, _ = cache.EntryInfo("k")
+	if !info.InsertedAt.Equal(inserted) {
+		t.Fatalf("InsertedAt = %v, want %v (kept by replacement)", info.InsertedAt, inserted)
+	}
+	if !info.UpdatedAt.Equal(updated) || !info.LastAccess.Equal(accessed) {
+		t.Fatalf("UpdatedAt = %v, LastAccess = %v; want %v, %v", info.UpdatedAt, info.LastAccess, updated, accessed)
+	}
+
+	// A key inserted again starts over
+	cache.Delete("k")
+	timeProvider.Advance(time.Second)
+	reinserted := time.Unix(0, timeProvider.Now())
+	cache.Set("k", 3)
+	info, _ = cache.EntryInfo("k")
+	if !info.InsertedAt.Equal(reinserted) || !info.LastAccess.IsZero() {
+		t.Fatalf("after reinsertion: %+v", info)
+	}
+}
+
+func TestEntryInfo_CompareAndSwapIsAWrite(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, TrackEntryTimes: true, TimeProvider: timeProvider})
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	timeProvider.Advance(time.Second)
+	if !cache.CompareAndSwap("k", 1, 2) {
+		t.Fatal("CompareAndSwap failed")
+	}
+	info, ok := cache.EntryInfo("k")
+	if !ok || !info.UpdatedAt.Equal(time.Unix(0, timeProvider.Now())) {
+		t.Fatalf("UpdatedAt not renewed by CompareAndSwap: %+v", info)
+	}
+}
+
+func TestEntryInfo_ClosedCache(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10, TrackEntryTimes: true}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+	_ = cache.Close()
+	if _, ok := cache.EntryInfo("k"); ok {
+		t.Fatal("EntryInfo found an entry after Close")
+	}
+}
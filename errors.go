@@ -12,6 +12,7 @@ package balios
 import (
 	goerrors "errors"
 	"fmt"
+	"time"
 
 	"github.com/agilira/go-errors"
 )
@@ -26,23 +27,32 @@ const (
 	ErrCodeInvalidTTL         errors.ErrorCode = "BALIOS_INVALID_TTL"
 
 	// Operation errors (2xxx)
-	ErrCodeCacheFull      errors.ErrorCode = "BALIOS_CACHE_FULL"
-	ErrCodeKeyNotFound    errors.ErrorCode = "BALIOS_KEY_NOT_FOUND"
-	ErrCodeEmptyKey       errors.ErrorCode = "BALIOS_EMPTY_KEY"
-	ErrCodeEvictionFailed errors.ErrorCode = "BALIOS_EVICTION_FAILED"
-	ErrCodeSetFailed      errors.ErrorCode = "BALIOS_SET_FAILED"
-	ErrCodeDeleteFailed   errors.ErrorCode = "BALIOS_DELETE_FAILED"
+	ErrCodeCacheFull       errors.ErrorCode = "BALIOS_CACHE_FULL"
+	ErrCodeKeyNotFound     errors.ErrorCode = "BALIOS_KEY_NOT_FOUND"
+	ErrCodeEmptyKey        errors.ErrorCode = "BALIOS_EMPTY_KEY"
+	ErrCodeEvictionFailed  errors.ErrorCode = "BALIOS_EVICTION_FAILED"
+	ErrCodeSetFailed       errors.ErrorCode = "BALIOS_SET_FAILED"
+	ErrCodeDeleteFailed    errors.ErrorCode = "BALIOS_DELETE_FAILED"
+	ErrCodeShutdownFailed  errors.ErrorCode = "BALIOS_SHUTDOWN_FAILED"
+	ErrCodeReadContention  errors.ErrorCode = "BALIOS_READ_CONTENTION"
+	ErrCodeContextCanceled errors.ErrorCode = "BALIOS_CONTEXT_CANCELED"
+	ErrCodeCacheClosed     errors.ErrorCode = "BALIOS_CACHE_CLOSED"
+	ErrCodeValueTooLarge   errors.ErrorCode = "BALIOS_VALUE_TOO_LARGE"
 
 	// Loader errors (3xxx)
 	ErrCodeLoaderFailed    errors.ErrorCode = "BALIOS_LOADER_FAILED"
 	ErrCodeLoaderTimeout   errors.ErrorCode = "BALIOS_LOADER_TIMEOUT"
 	ErrCodeLoaderCancelled errors.ErrorCode = "BALIOS_LOADER_CANCELLED"
 	ErrCodeInvalidLoader   errors.ErrorCode = "BALIOS_INVALID_LOADER"
+	ErrCodeNotModified     errors.ErrorCode = "BALIOS_NOT_MODIFIED"
+	ErrCodeLoadRateLimited errors.ErrorCode = "BALIOS_LOAD_RATE_LIMITED"
+	ErrCodeCircuitOpen     errors.ErrorCode = "BALIOS_CIRCUIT_OPEN"
 
 	// Persistence errors (4xxx)
 	ErrCodeSaveFailed    errors.ErrorCode = "BALIOS_SAVE_FAILED"
 	ErrCodeLoadFailed    errors.ErrorCode = "BALIOS_LOAD_FAILED"
 	ErrCodeCorruptedData errors.ErrorCode = "BALIOS_CORRUPTED_DATA"
+	ErrCodePrimeFailed   errors.ErrorCode = "BALIOS_PRIME_FAILED"
 
 	// Internal errors (5xxx)
 	ErrCodeInternalError  errors.ErrorCode = "BALIOS_INTERNAL_ERROR"
@@ -51,6 +61,7 @@ const (
 
 // Common error messages
 const (
+	msgInvalidConfig      = "invalid configuration"
 	msgInvalidMaxSize     = "invalid max size: must be greater than 0"
 	msgInvalidWindowRatio = "invalid window ratio: must be between 0.0 and 1.0"
 	msgInvalidCounterBits = "invalid counter bits: must be between 1 and 8"
@@ -61,13 +72,22 @@ const (
 	msgEvictionFailed     = "failed to evict entry from cache"
 	msgSetFailed          = "failed to set key-value pair"
 	msgDeleteFailed       = "failed to delete key"
+	msgShutdownFailed     = "failed to close managed component"
+	msgReadContention     = "key read abandoned under write contention"
+	msgContextCanceled    = "operation abandoned: context done under contention"
+	msgCacheClosed        = "cache is closed"
+	msgValueTooLarge      = "key or value exceeds the configured size limit"
 	msgLoaderFailed       = "loader function failed"
 	msgLoaderTimeout      = "loader function timed out"
 	msgLoaderCancelled    = "loader function was cancelled"
 	msgInvalidLoader      = "loader function cannot be nil"
+	msgNotModified        = "value not modified since previous load"
+	msgLoadRateLimited    = "loader call rate limit exceeded"
+	msgCircuitOpen        = "loader circuit breaker is open"
 	msgSaveFailed         = "failed to save cache to file"
 	msgLoadFailed         = "failed to load cache from file"
 	msgCorruptedData      = "corrupted cache data"
+	msgPrimeFailed        = "failed to prime cache from secondary store"
 	msgInternalError      = "internal cache error"
 	msgPanicRecovered     = "panic recovered in cache operation"
 )
@@ -76,6 +96,14 @@ const (
 // CONFIGURATION ERRORS
 // =============================================================================
 
+// NewErrInvalidConfig creates a generic configuration error
+func NewErrInvalidConfig(field string, reason string) error {
+	return errors.NewWithContext(ErrCodeInvalidConfig, msgInvalidConfig, map[string]interface{}{
+		"field":  field,
+		"reason": reason,
+	})
+}
+
 // NewErrInvalidMaxSize creates an error for invalid max size
 func NewErrInvalidMaxSize(size int) error {
 	return errors.NewWithContext(ErrCodeInvalidMaxSize, msgInvalidMaxSize, map[string]interface{}{
@@ -151,6 +179,49 @@ func NewErrDeleteFailed(key string, reason string) error {
 	}).AsRetryable()
 }
 
+// NewErrShutdownFailed creates an error when a managed component fails to close
+func NewErrShutdownFailed(name string, cause error) error {
+	return errors.Wrap(cause, ErrCodeShutdownFailed, msgShutdownFailed).
+		WithContext("name", name)
+}
+
+// NewErrReadContention creates an error when a lookup gives up reading a key
+// that concurrent writers keep rewriting
+func NewErrReadContention(key string, retries int) error {
+	return errors.NewWithContext(ErrCodeReadContention, msgReadContention, map[string]interface{}{
+		"key":     key,
+		"retries": retries,
+	}).AsRetryable()
+}
+
+// NewErrContextCanceled creates an error when GetCtx or SetCtx gives up
+// because ctx was done before contention cleared. cause is ctx.Err()
+func NewErrContextCanceled(operation, key string, cause error) error {
+	return errors.Wrap(cause, ErrCodeContextCanceled, msgContextCanceled).
+		WithContext("operation", operation).
+		WithContext("key", key).
+		AsRetryable()
+}
+
+// NewErrCacheClosed creates an error for an operation called after Close
+func NewErrCacheClosed(operation string) error {
+	return errors.NewWithContext(ErrCodeCacheClosed, msgCacheClosed, map[string]interface{}{
+		"operation": operation,
+	})
+}
+
+// NewErrValueTooLarge creates an error when a write is rejected because its
+// key or value (field "key" or "value") is larger than limit. Only the first
+// maxErrorKeyLength bytes of the key are kept.
+func NewErrValueTooLarge(key, field string, size, limit int64) error {
+	return errors.NewWithContext(ErrCodeValueTooLarge, msgValueTooLarge, map[string]interface{}{
+		"key":   truncateKey(key),
+		"field": field,
+		"size":  size,
+		"limit": limit,
+	})
+}
+
 // =============================================================================
 // LOADER ERRORS
 // =============================================================================
@@ -180,6 +251,34 @@ func NewErrInvalidLoader(key string) error {
 	return errors.NewWithField(ErrCodeInvalidLoader, msgInvalidLoader, "key", key)
 }
 
+// NewErrNotModified creates the signal returned by revalidating loaders when the
+// previously cached value is still current (e.g. HTTP 304 Not Modified).
+// It is not a failure: the cache keeps the previous value and renews its TTL.
+func NewErrNotModified(key string) error {
+	return errors.NewWithField(ErrCodeNotModified, msgNotModified, "key", key).
+		WithSeverity("info")
+}
+
+// NewErrLoadRateLimited creates an error when a loader call is rejected by
+// Config.LoadRateLimit. The load can be retried once the family's bucket refills.
+func NewErrLoadRateLimited(key, family string) error {
+	return errors.NewWithContext(ErrCodeLoadRateLimited, msgLoadRateLimited, map[string]interface{}{
+		"key":    key,
+		"family": family,
+	}).AsRetryable()
+}
+
+// NewErrCircuitOpen creates an error when a loader call is rejected by an
+// open circuit breaker (Config.CircuitBreakerThreshold). retryAfter is the
+// remaining cooldown, 0 while a half-open probe is running.
+func NewErrCircuitOpen(key, family string, retryAfter time.Duration) error {
+	return errors.NewWithContext(ErrCodeCircuitOpen, msgCircuitOpen, map[string]interface{}{
+		"key":         key,
+		"family":      family,
+		"retry_after": retryAfter.String(),
+	}).AsRetryable()
+}
+
 // =============================================================================
 // PERSISTENCE ERRORS
 // =============================================================================
@@ -206,6 +305,13 @@ func NewErrCorruptedData(filepath string, details string) error {
 	})
 }
 
+// NewErrPrimeFailed creates an error when priming from a secondary store fails
+func NewErrPrimeFailed(stage string, cause error) error {
+	return errors.Wrap(cause, ErrCodePrimeFailed, msgPrimeFailed).
+		WithContext("stage", stage).
+		AsRetryable()
+}
+
 // =============================================================================
 // INTERNAL ERRORS
 // =============================================================================
@@ -243,11 +349,48 @@ func IsEmptyKey(err error) bool {
 	return errors.HasCode(err, ErrCodeEmptyKey)
 }
 
+// IsReadContention checks if error is a lookup abandoned under write contention
+func IsReadContention(err error) bool {
+	return errors.HasCode(err, ErrCodeReadContention)
+}
+
+// IsContextCanceled checks if error is a GetCtx or SetCtx abandoned when its
+// context was done
+func IsContextCanceled(err error) bool {
+	return errors.HasCode(err, ErrCodeContextCanceled)
+}
+
+// IsCacheClosed checks if error is an operation called after Close
+func IsCacheClosed(err error) bool {
+	return errors.HasCode(err, ErrCodeCacheClosed)
+}
+
+// IsValueTooLarge checks if error is a write rejected by Config.MaxKeyLen or
+// Config.MaxValueBytes
+func IsValueTooLarge(err error) bool {
+	return errors.HasCode(err, ErrCodeValueTooLarge)
+}
+
 // IsCacheFull checks if error is a cache full error
 func IsCacheFull(err error) bool {
 	return errors.HasCode(err, ErrCodeCacheFull)
 }
 
+// IsNotModified checks if error is a not-modified revalidation signal
+func IsNotModified(err error) bool {
+	return errors.HasCode(err, ErrCodeNotModified)
+}
+
+// IsLoadRateLimited checks if error is a loader rate limit rejection
+func IsLoadRateLimited(err error) bool {
+	return errors.HasCode(err, ErrCodeLoadRateLimited)
+}
+
+// IsCircuitOpen checks if error is a rejection by an open loader circuit breaker
+func IsCircuitOpen(err error) bool {
+	return errors.HasCode(err, ErrCodeCircuitOpen)
+}
+
 // IsConfigError checks if error is a configuration error
 func IsConfigError(err error) bool {
 	if err == nil {
@@ -274,7 +417,9 @@ func IsOperationError(err error) bool {
 		code := coder.ErrorCode()
 		// Operation errors: BALIOS_CACHE_FULL, BALIOS_KEY_NOT_FOUND, etc.
 		return code == ErrCodeCacheFull || code == ErrCodeKeyNotFound ||
-			code == ErrCodeEvictionFailed || code == ErrCodeSetFailed || code == ErrCodeDeleteFailed
+			code == ErrCodeEvictionFailed || code == ErrCodeSetFailed || code == ErrCodeDeleteFailed ||
+			code == ErrCodeShutdownFailed || code == ErrCodeReadContention || code == ErrCodeContextCanceled ||
+			code == ErrCodeCacheClosed
 	}
 	return false
 }
@@ -301,8 +446,9 @@ func IsPersistenceError(err error) bool {
 	var coder errors.ErrorCoder
 	if goerrors.As(err, &coder) {
 		code := coder.ErrorCode()
-		// Persistence errors: BALIOS_SAVE_FAILED, BALIOS_LOAD_FAILED, BALIOS_CORRUPTED_DATA
-		return code == ErrCodeSaveFailed || code == ErrCodeLoadFailed || code == ErrCodeCorruptedData
+		// Persistence errors: BALIOS_SAVE_FAILED, BALIOS_LOAD_FAILED, BALIOS_CORRUPTED_DATA, BALIOS_PRIME_FAILED
+		return code == ErrCodeSaveFailed || code == ErrCodeLoadFailed || code == ErrCodeCorruptedData ||
+			code == ErrCodePrimeFailed
 	}
 	return false
 }
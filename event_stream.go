@@ -0,0 +1,243 @@
+// event_stream.go: structured lifecycle events for subscribers (Subscribe)
+//
+// Subscribe returns a channel of removal and failed-write Events filtered
+// by an EventMask; publishing never blocks the cache.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEventBufferSize is the default capacity of a Subscribe channel.
+const DefaultEventBufferSize = 1024
+
+// EventMask selects event types. Each event type is one bit; subscribe to
+// several with a bitwise or.
+type EventMask uint32
+
+const (
+	// EventEvicted is published when an entry is evicted to make room.
+	EventEvicted EventMask = 1 << iota
+
+	// EventExpired is published when an entry is removed after its TTL (or
+	// TTI) elapsed.
+	EventExpired
+
+	// EventDeleted is published when an entry is removed by Delete or an
+	// operation built on it.
+	EventDeleted
+
+	// EventReplaced is published when a write replaces the value of a key.
+	EventReplaced
+
+	// EventSetFailed is published when a write finds no free slot, even
+	// after an eviction (the table is saturated by concurrent writers).
+	EventSetFailed
+
+	// EventAll selects every event type.
+	EventAll = EventEvicted | EventExpired | EventDeleted | EventReplaced | EventSetFailed
+)
+
+// String returns the names of the event types in m, separated by "|".
+func (m EventMask) String() string {
+	if m == 0 {
+		return "none"
+	}
+	names := [...]string{"evicted", "expired", "deleted", "replaced", "set-failed"}
+	var parts []string
+	for i, name := range names {
+		if m&(1<<i) != 0 {
+			parts = append(parts, name)
+		}
+	}
+	if m&^EventAll != 0 {
+		parts = append(parts, "unknown")
+	}
+	return strings.Join(parts, "|")
+}
+
+// Event is a cache lifecycle event delivered by Subscribe.
+type Event struct {
+	// Type is the event type (a single bit of EventMask).
+	Type EventMask
+
+	// Key is the key of the entry, in its string form.
+	Key string
+
+	// KeyHash is the table hash of Key (see Config.KeyHasher).
+	KeyHash uint64
+
+	// Time is when the event happened, on the cache TimeProvider.
+	Time time.Time
+}
+
+// eventSubscriber is the channel of a Subscribe call.
+type eventSubscriber struct {
+	mask EventMask
+	ch   chan Event
+}
+
+// eventHub fans events out to the subscribers of a cache.
+type eventHub struct {
+	mask    atomic.Uint32 // Union of the subscribed masks
+	dropped atomic.Int64  // Events dropped from full buffers
+	size    int           // Buffer size of new subscriptions
+
+	mu     sync.RWMutex // Held for reading while publishing, for writing to close channels
+	subs   []*eventSubscriber
+	closed bool
+}
+
+// wants reports whether a subscriber wants events of type typ.
+func (h *eventHub) wants(typ EventMask) bool {
+	return EventMask(h.mask.Load())&typ != 0
+}
+
+// subscribe adds a subscriber to the events of mask.
+func (h *eventHub) subscribe(mask EventMask) <-chan Event {
+	sub := &eventSubscriber{mask: mask & EventAll, ch: make(chan Event, h.size)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed || sub.mask == 0 {
+		close(sub.ch)
+		return sub.ch
+	}
+	h.subs = append(h.subs, sub)
+	h.updateMask()
+	return sub.ch
+}
+
+// unsubscribe removes the subscriber of ch and closes ch.
+func (h *eventHub) unsubscribe(ch <-chan Event) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, sub := range h.subs {
+		if sub.ch == ch {
+			close(sub.ch)
+			h.subs = append(h.subs[:i], h.subs[i+1:]...)
+			h.updateMask()
+			return true
+		}
+	}
+	return false
+}
+
+// close closes every subscriber channel; later subscriptions are closed
+// at once.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range h.subs {
+		close(sub.ch)
+	}
+	h.subs = nil
+	h.closed = true
+	h.updateMask()
+}
+
+// updateMask recomputes the union of the subscribed masks. Called with mu
+// held for writing.
+func (h *eventHub) updateMask() {
+	var mask EventMask
+	for _, sub := range h.subs {
+		mask |= sub.mask
+	}
+	h.mask.Store(uint32(mask))
+}
+
+// publish delivers event to the subscribers of its type.
+func (h *eventHub) publish(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		if sub.mask&event.Type != 0 {
+			h.deliver(sub.ch, event)
+		}
+	}
+}
+
+// deliver sends event on ch, dropping the oldest buffered event while ch is
+// full. Concurrent publishers refilling ch make it give up after a few
+// attempts and drop event instead.
+func (h *eventHub) deliver(ch chan Event, event Event) {
+	for attempt := 0; attempt < 3; attempt++ {
+		select {
+		case ch <- event:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+			h.dropped.Add(1)
+		default:
+		}
+	}
+	h.dropped.Add(1)
+}
+
+// publishEvent publishes an event of type typ about key, if subscribed.
+func (c *wtinyLFUCache) publishEvent(typ EventMask, key string, keyHash uint64) {
+	if !c.events.wants(typ) {
+		return
+	}
+	c.events.publish(Event{Type: typ, Key: key, KeyHash: keyHash, Time: timeOf(c.timeProvider.Now())})
+}
+
+// eventOf returns the event type of a removal for reason.
+func eventOf(reason EvictReason) EventMask {
+	switch reason {
+	case ReasonEvicted:
+		return EventEvicted
+	case ReasonExpired:
+		return EventExpired
+	case ReasonDeleted:
+		return EventDeleted
+	case ReasonReplaced:
+		return EventReplaced
+	default:
+		return 0
+	}
+}
+
+// Subscribe returns a channel receiving the events of the types in mask
+// until Unsubscribe or Close closes it. The channel buffers
+// Config.EventBufferSize events; when it is full the oldest event is
+// dropped (counted in CacheStats.EventsDropped), so a slow consumer never
+// blocks the cache. A mask without event types returns a closed channel.
+//
+// Example:
+//
+//	events := cache.Subscribe(balios.EventEvicted | balios.EventExpired)
+//	go func() {
+//	    for e := range events {
+//	        log.Printf("%s %s at %s", e.Type, e.Key, e.Time)
+//	    }
+//	}()
+func (c *wtinyLFUCache) Subscribe(mask EventMask) <-chan Event {
+	return c.events.subscribe(mask)
+}
+
+// Unsubscribe stops the delivery of events to ch, a channel returned by
+// Subscribe, and closes it. Returns false if ch is not subscribed.
+func (c *wtinyLFUCache) Unsubscribe(ch <-chan Event) bool {
+	return c.events.unsubscribe(ch)
+}
+
+// Subscribe returns a channel receiving the events of the types in mask
+// (see Cache.Subscribe). Keys are in their string form.
+func (c *GenericCache[K, V]) Subscribe(mask EventMask) <-chan Event {
+	return c.inner.Subscribe(mask)
+}
+
+// Unsubscribe stops the delivery of events to ch and closes it.
+func (c *GenericCache[K, V]) Unsubscribe(ch <-chan Event) bool {
+	return c.inner.Unsubscribe(ch)
+}
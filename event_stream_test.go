@@ -0,0 +1,170 @@
+// event_stream_test.go: tests for Subscribe
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// nextEvent returns the next event of ch, failing the test if none arrives.
+func nextEvent(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case event, ok := <-ch:
+		if !ok {
+			t.Fatal("event channel closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event within 1s")
+		return Event{}
+	}
+}
+
+func TestSubscribe_Lifecycle(t *testing.T) {
+	clock := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Minute, TimeProvider: clock}).(*wtinyLFUCache)
+	defer cache.Close()
+	events := cache.Subscribe(EventAll)
+
+	cache.Set("k", 1)
+	cache.Set("k", 2)
+	event := nextEvent(t, events)
+	if event.Type != EventReplaced || event.Key != "k" || event.KeyHash != cache.hashKey("k") {
+		t.Fatalf("expected a replacement of k, got %+v", event)
+	}
+	if event.Time.UnixNano() != clock.Now() {
+		t.Fatalf("event time %v, want the TimeProvider time", event.Time)
+	}
+
+	cache.Delete("k")
+	if event := nextEvent(t, events); event.Type != EventDeleted || event.Key != "k" {
+		t.Fatalf("expected a deletion of k, got %+v", event)
+	}
+
+	cache.Set("session", 1)
+	clock.Advance(2 * time.Minute)
+	cache.Get("session")
+	if event := nextEvent(t, events); event.Type != EventExpired || event.Key != "session" {
+		t.Fatalf("expected an expiration of session, got %+v", event)
+	}
+}
+
+func TestSubscribe_Evictions(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10}).(*wtinyLFUCache)
+	defer cache.Close()
+	events := cache.Subscribe(EventEvicted)
+
+	for i := 0; i < 100; i++ {
+		cache.Set("k"+strconv.Itoa(i), i)
+	}
+	event := nextEvent(t, events)
+	if event.Type != EventEvicted || event.Key == "" || cache.Has(event.Key) {
+		t.Fatalf("expected the eviction of a key no longer cached, got %+v", event)
+	}
+	for {
+		select {
+		case event := <-events:
+			if event.Type != EventEvicted {
+				t.Fatalf("unsubscribed event type delivered: %+v", event)
+			}
+			continue
+		default:
+		}
+		break
+	}
+}
+
+func TestSubscribe_DropsOldest(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, EventBufferSize: 4}).(*wtinyLFUCache)
+	defer cache.Close()
+	events := cache.Subscribe(EventDeleted)
+
+	for i := 0; i < 10; i++ {
+		key := "k" + strconv.Itoa(i)
+		cache.Set(key, i)
+		cache.Delete(key)
+	}
+	for i := 6; i < 10; i++ {
+		if event := nextEvent(t, events); event.Key != "k"+strconv.Itoa(i) {
+			t.Fatalf("expected the newest events to be kept, got %q at %d", event.Key, i)
+		}
+	}
+	if dropped := cache.Stats().EventsDropped; dropped != 6 {
+		t.Fatalf("EventsDropped = %d, want 6", dropped)
+	}
+}
+
+func TestSubscribe_UnsubscribeAndClose(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	first := cache.Subscribe(EventDeleted)
+	second := cache.Subscribe(EventDeleted)
+
+	if !cache.Unsubscribe(first) || cache.Unsubscribe(first) {
+		t.Fatal("Unsubscribe must succeed once")
+	}
+	if _, ok := <-first; ok {
+		t.Fatal("Unsubscribe must close the channel")
+	}
+	if _, ok := <-cache.Subscribe(0); ok {
+		t.Fatal("an empty mask must return a closed channel")
+	}
+
+	cache.Close()
+	if _, ok := <-second; ok {
+		t.Fatal("Close must close the channels")
+	}
+	if _, ok := <-cache.Subscribe(EventAll); ok {
+		t.Fatal("Subscribe after Close must return a closed channel")
+	}
+}
+
+func TestSubscribe_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 50, EventBufferSize: 16}).(*wtinyLFUCache)
+	events := cache.Subscribe(EventAll)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := "k" + strconv.Itoa((g*1000+i)%200)
+				cache.Set(key, i)
+				if i%3 == 0 {
+					cache.Delete(key)
+				}
+			}
+		}(g)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range events {
+		}
+	}()
+	wg.Wait()
+	cache.Close()
+	<-done
+}
+
+func TestEventMask_String(t *testing.T) {
+	tests := map[EventMask]string{
+		0:                            "none",
+		EventEvicted:                 "evicted",
+		EventExpired | EventReplaced: "expired|replaced",
+		EventSetFailed | 1<<10:       "set-failed|unknown",
+	}
+	for mask, want := range tests {
+		if got := mask.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", mask, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// eviction_audit.go: bounded log of eviction decisions
+//
+// With Config.EvictionAuditSize every eviction records its victim and the
+// sampled candidates, so DebugStats can explain the decision.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync"
+
+// EvictionDecision records one eviction.
+type EvictionDecision struct {
+	// At is the TimeProvider time of the eviction, in nanoseconds.
+	At int64
+
+	// Victim is the evicted entry.
+	Victim EvictionCandidate
+
+	// Candidates are the sampled entries the victim was chosen from, victim
+	// included. Empty for fallback evictions.
+	Candidates []EvictionCandidate
+
+	// Fallback reports that every sample was rejected or lost to concurrent
+	// writers and the victim was the first live entry of a last-resort scan.
+	Fallback bool
+
+	// Rejected reports that the victim is a new key refused by the
+	// Config.AdmissionPolicy; Candidates holds the entry it was compared with
+	// and the new key.
+	Rejected bool
+}
+
+// evictionAudit is a ring buffer of the most recent eviction decisions.
+type evictionAudit struct {
+	mu    sync.Mutex
+	buf   []EvictionDecision
+	next  int    // Next slot to write
+	total uint64 // Decisions recorded since creation or Clear
+}
+
+// newEvictionAudit returns nil when the audit is disabled.
+func newEvictionAudit(size int) *evictionAudit {
+	if size <= 0 {
+		return nil
+	}
+	return &evictionAudit{buf: make([]EvictionDecision, 0, size)}
+}
+
+func (a *evictionAudit) record(d EvictionDecision) {
+	a.mu.Lock()
+	if len(a.buf) < cap(a.buf) {
+		a.buf = append(a.buf, d)
+	} else {
+		a.buf[a.next] = d
+	}
+	a.next = (a.next + 1) % cap(a.buf)
+	a.total++
+	a.mu.Unlock()
+}
+
+// snapshot returns the recorded decisions, oldest first, and the total count.
+func (a *evictionAudit) snapshot() ([]EvictionDecision, uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	decisions := make([]EvictionDecision, 0, len(a.buf))
+	if len(a.buf) == cap(a.buf) {
+		decisions = append(decisions, a.buf[a.next:]...)
+		decisions = append(decisions, a.buf[:a.next]...)
+	} else {
+		decisions = append(decisions, a.buf...)
+	}
+	return decisions, a.total
+}
+
+func (a *evictionAudit) reset() {
+	a.mu.Lock()
+	a.buf = a.buf[:0]
+	a.next = 0
+	a.total = 0
+	a.mu.Unlock()
+}
+
+// auditEviction records the eviction of entry. decision is the sampled
+// decision built by policyVictim, or nil for fallback evictions. Must be
+// called after the removal CAS and before the key is cleared.
+func (c *wtinyLFUCache) auditEviction(decision *EvictionDecision, entry *entry) {
+	if decision == nil {
+		decision = &EvictionDecision{
+			Fallback: true,
+			Victim:   c.evictionCandidate(entry),
+		}
+	}
+	decision.At = c.timeProvider.Now()
+	c.evictionAudit.record(*decision)
+}
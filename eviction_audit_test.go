@@ -0,0 +1,109 @@
+// eviction_audit_test.go: tests for the eviction audit
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEvictionAudit_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 16}).(*wtinyLFUCache)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	stats := cache.DebugStats()
+	if len(stats.EvictionAudit) != 0 || stats.EvictionDecisions != 0 {
+		t.Errorf("audit must be empty when disabled: %+v", stats)
+	}
+}
+
+func TestEvictionAudit_RecordsDecisions(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 32, EvictionAuditSize: 1000}).(*wtinyLFUCache)
+	for i := 0; i < 200; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	evictions := cache.Stats().Evictions
+	stats := cache.DebugStats()
+	if evictions == 0 {
+		t.Fatal("expected evictions")
+	}
+	if stats.EvictionDecisions != evictions || uint64(len(stats.EvictionAudit)) != evictions {
+		t.Fatalf("every eviction must be audited: %d evictions, %d decisions, %d kept",
+			evictions, stats.EvictionDecisions, len(stats.EvictionAudit))
+	}
+
+	for _, d := range stats.EvictionAudit {
+		if d.Victim.Key == "" || d.Victim.KeyHash != stringHash(d.Victim.Key) {
+			t.Fatalf("victim must be identified by key and hash: %+v", d.Victim)
+		}
+		if cache.Has(d.Victim.Key) {
+			// The key may have been re-inserted later; only check sampled decisions
+			continue
+		}
+		if d.Fallback {
+			continue
+		}
+		found := false
+		for _, c := range d.Candidates {
+			if c.Frequency < d.Victim.Frequency {
+				t.Errorf("victim %q (freq %d) chosen over less frequent %q (freq %d)",
+					d.Victim.Key, d.Victim.Frequency, c.Key, c.Frequency)
+			}
+			if c.Key == d.Victim.Key {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("victim %q must be among the candidates", d.Victim.Key)
+		}
+	}
+}
+
+func TestEvictionAudit_RingBuffer(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: 1}
+	cache := NewCache(Config{MaxSize: 16, EvictionAuditSize: 5, TimeProvider: mockTime}).(*wtinyLFUCache)
+	for i := 0; i < 100; i++ {
+		mockTime.Advance(1)
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	stats := cache.DebugStats()
+	if len(stats.EvictionAudit) != 5 {
+		t.Fatalf("expected the last 5 decisions, got %d", len(stats.EvictionAudit))
+	}
+	if stats.EvictionDecisions != cache.Stats().Evictions {
+		t.Errorf("total must count overwritten decisions: %d vs %d evictions",
+			stats.EvictionDecisions, cache.Stats().Evictions)
+	}
+	for i := 1; i < len(stats.EvictionAudit); i++ {
+		if stats.EvictionAudit[i].At < stats.EvictionAudit[i-1].At {
+			t.Errorf("decisions must be ordered oldest first: %d before %d",
+				stats.EvictionAudit[i-1].At, stats.EvictionAudit[i].At)
+		}
+	}
+
+	cache.Clear()
+	if stats := cache.DebugStats(); len(stats.EvictionAudit) != 0 || stats.EvictionDecisions != 0 {
+		t.Errorf("Clear must reset the audit: %+v", stats)
+	}
+}
+
+func TestEvictionAudit_WithCustomPolicy(t *testing.T) {
+	policy := &recordingPolicy{protect: "keep:"}
+	cache := NewCache(Config{MaxSize: 16, EvictionPolicy: policy, EvictionAuditSize: 100}).(*wtinyLFUCache)
+	cache.Set("keep:1", 1)
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("churn:%d", i), i)
+	}
+	for _, d := range cache.DebugStats().EvictionAudit {
+		if !d.Fallback && d.Victim.Key == "keep:1" {
+			t.Error("audited victim must be the one chosen by the custom policy")
+		}
+	}
+}
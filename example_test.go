@@ -203,13 +203,9 @@ func ExampleConfig() {
 		TTL:              30 * time.Minute, // Entries expire after 30 minutes
 		NegativeCacheTTL: 5 * time.Second,  // Cache errors for 5 seconds
 		WindowRatio:      0.01,             // 1% window cache (W-TinyLFU)
-		OnEvict: func(key string, value interface{}) {
-			// Called when an entry is evicted
-			fmt.Printf("Evicted: %s\n", key)
-		},
-		OnExpire: func(key string, value interface{}) {
-			// Called when an entry expires
-			fmt.Printf("Expired: %s\n", key)
+		OnEvict: func(key string, value interface{}, reason balios.EvictReason) {
+			// Called when a value leaves the cache (evicted, expired, deleted, replaced)
+			fmt.Printf("Removed: %s (%s)\n", key, reason)
 		},
 	})
 	defer func() { _ = cache.Close() }()
@@ -0,0 +1,101 @@
+// expire_index.go: expiration deadlines for external schedulers
+//
+// NextExpiration and ExpiringBefore tell an external scheduler when
+// ExpireNow will have work to do.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// NextExpiration returns the earliest time at which ExpireNow will remove an
+// entry. ok is false if no live entry has a TTL.
+func (c *wtinyLFUCache) NextExpiration() (next time.Time, ok bool) {
+	if atomic.LoadInt64(&c.maxTTLNanos) == 0 || c.isClosed() {
+		return time.Time{}, false
+	}
+	t := c.table.Load()
+	if t == nil {
+		return time.Time{}, false // Closed
+	}
+
+	now := c.ttlClock(c.timeProvider.Now())
+	var earliest int64
+	for i := range t.entries {
+		deadline, has := c.deadlineOf(&t.entries[i], now)
+		if has && (!ok || deadline < earliest) {
+			earliest, ok = deadline, true
+		}
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, earliest), true
+}
+
+// ExpiringBefore returns the keys of the live entries that will have expired
+// by t, including entries already expired but not yet removed.
+func (c *wtinyLFUCache) ExpiringBefore(t time.Time) []string {
+	if atomic.LoadInt64(&c.maxTTLNanos) == 0 || c.isClosed() {
+		return nil
+	}
+
+	table := c.table.Load()
+	if table == nil {
+		return nil // Closed
+	}
+
+	now := c.ttlClock(c.timeProvider.Now())
+	limit := t.UnixNano()
+	var keys []string
+	for i := range table.entries {
+		entry := &table.entries[i]
+		if deadline, has := c.deadlineOf(entry, now); has && deadline <= limit {
+			if key := entry.loadKey(); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// deadlineOf returns the first instant at which entry counts as expired.
+// has is false for empty slots and entries without a TTL.
+func (c *wtinyLFUCache) deadlineOf(entry *entry, now int64) (deadline int64, has bool) {
+	if !c.isLive(entry) {
+		return 0, false
+	}
+	expireAt := atomic.LoadInt64(&entry.expireAt)
+	if expireAt <= 0 || expireAt == 1<<63-1 {
+		return 0, false // No TTL, or a TTL too long to represent
+	}
+	// Same cap as the clock regression guard of isExpired
+	if expireAt-now > c.ttlBoundNanos {
+		expireAt = min(expireAt, now+c.ttlBound(entry))
+	}
+	return expireAt + 1, true
+}
+
+// ExpireNow removes all entries that have exceeded their TTL and returns how
+// many were removed.
+func (c *GenericCache[K, V]) ExpireNow() int {
+	return c.inner.ExpireNow()
+}
+
+// NextExpiration returns the earliest time at which ExpireNow will remove an
+// entry. ok is false if no live entry has a TTL.
+func (c *GenericCache[K, V]) NextExpiration() (time.Time, bool) {
+	return c.inner.NextExpiration()
+}
+
+// ExpiringBefore returns the stored keys (as produced for K by the cache's
+// key conversion) of the entries that will have expired by t.
+func (c *GenericCache[K, V]) ExpiringBefore(t time.Time) []string {
+	return c.inner.ExpiringBefore(t)
+}
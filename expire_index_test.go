@@ -0,0 +1,99 @@
+// expire_index_test.go: tests for NextExpiration and ExpiringBefore
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNextExpiration_NoTTL(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+	if _, ok := cache.NextExpiration(); ok {
+		t.Error("a cache without TTL has no next expiration")
+	}
+	if keys := cache.ExpiringBefore(time.Now().Add(time.Hour)); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+}
+
+func TestNextExpiration_Earliest(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	if _, ok := cache.NextExpiration(); ok {
+		t.Error("an empty cache has no next expiration")
+	}
+
+	cache.Set("first", 1)
+	mockTime.Advance(3 * time.Second)
+	cache.Set("second", 2)
+
+	next, ok := cache.NextExpiration()
+	want := time.Unix(0, int64(time.Hour+10*time.Second)+1)
+	if !ok || !next.Equal(want) {
+		t.Fatalf("NextExpiration = %v, %v; want %v", next, ok, want)
+	}
+
+	// Scheduling ExpireNow at the reported time removes the entry
+	mockTime.currentTime = next.UnixNano()
+	if n := cache.ExpireNow(); n != 1 {
+		t.Errorf("ExpireNow at the deadline removed %d entries, want 1", n)
+	}
+	if next, ok = cache.NextExpiration(); !ok || next.UnixNano() != int64(time.Hour+13*time.Second)+1 {
+		t.Errorf("next deadline must be the second entry's, got %v, %v", next, ok)
+	}
+}
+
+func TestExpiringBefore(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	mockTime.Advance(5 * time.Second)
+	cache.Set("c", 3)
+
+	now := time.Unix(0, mockTime.Now())
+	if keys := cache.ExpiringBefore(now); len(keys) != 0 {
+		t.Errorf("nothing expires before now, got %v", keys)
+	}
+
+	keys := cache.ExpiringBefore(now.Add(6 * time.Second))
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("expected [a b], got %v", keys)
+	}
+	if keys := cache.ExpiringBefore(now.Add(time.Minute)); len(keys) != 3 {
+		t.Errorf("expected all keys, got %v", keys)
+	}
+
+	// Expired but not yet removed entries are still pending work
+	mockTime.Advance(time.Minute)
+	if keys := cache.ExpiringBefore(time.Unix(0, mockTime.Now())); len(keys) != 3 {
+		t.Errorf("expected expired keys to be listed, got %v", keys)
+	}
+	cache.ExpireNow()
+	if _, ok := cache.NextExpiration(); ok {
+		t.Error("no deadlines must remain after ExpireNow")
+	}
+}
+
+func TestExpiringBefore_Generic(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewGenericCache[int, string](Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime})
+	cache.Set(7, "seven")
+
+	keys := cache.ExpiringBefore(time.Unix(0, mockTime.Now()).Add(2 * time.Second))
+	if len(keys) != 1 || keys[0] != "7" {
+		t.Errorf("expected [7], got %v", keys)
+	}
+	mockTime.Advance(2 * time.Second)
+	if n := cache.ExpireNow(); n != 1 {
+		t.Errorf("ExpireNow removed %d entries, want 1", n)
+	}
+}
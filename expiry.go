@@ -0,0 +1,75 @@
+// expiry.go: remaining-lifetime introspection
+//
+// GetWithExpiry returns a value with the time it expires.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"time"
+)
+
+// GetWithExpiry is like Get but also returns when the entry expires.
+// expiresAt is the zero time for entries without a TTL.
+func (c *wtinyLFUCache) GetWithExpiry(key string) (value interface{}, expiresAt time.Time, found bool) {
+	if key == "" {
+		return nil, time.Time{}, false
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now)
+	keyHash := c.hashKey(key)
+	c.incrementFrequency(keyHash)
+
+	value, deadline, found := c.lookupWithDeadline(key, keyHash, ttlNow)
+	c.recordGet(context.Background(), key, start, found)
+	if found && deadline > 0 {
+		expiresAt = time.Unix(0, deadline)
+	}
+	return value, expiresAt, found
+}
+
+// lookupWithDeadline finds the live value of key and its deadline (0 when the
+// entry has no TTL). Rewrites racing with the read are retried.
+func (c *wtinyLFUCache) lookupWithDeadline(key string, keyHash uint64, ttlNow int64) (interface{}, int64, bool) {
+	for retry := 0; retry < c.keyReadRetries; retry++ {
+		entry := c.findEntry(key, keyHash)
+		if entry == nil || c.isExpired(entry, ttlNow) {
+			return nil, 0, false
+		}
+
+		holder, _ := entry.value.Load().(*valueHolder)
+		deadline, _ := c.deadlineOf(entry, ttlNow)
+		// An in-place update publishes a new holder before the new deadline
+		// while the slot is pending: an unchanged holder on a valid slot
+		// means the deadline belongs to it
+		if holder == nil || !c.isLive(entry) {
+			continue
+		}
+		if current, _ := entry.value.Load().(*valueHolder); current != holder {
+			continue
+		}
+		value, ok := c.decoded(holder.load())
+		return value, deadline, ok
+	}
+	return nil, 0, false
+}
+
+// GetWithExpiry is like Get but also returns when the entry expires
+// (the zero time for entries without a TTL).
+func (c *GenericCache[K, V]) GetWithExpiry(key K) (value V, expiresAt time.Time, found bool) {
+	raw, expiresAt, found := c.inner.GetWithExpiry(keyToString(key))
+	if !found {
+		return value, time.Time{}, false
+	}
+	value, ok := raw.(V)
+	if !ok {
+		return value, time.Time{}, false
+	}
+	return value, expiresAt, true
+}
@@ -0,0 +1,97 @@
+// expiry_test.go: tests for GetWithExpiry
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetWithExpiry(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Minute, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("k", "v")
+	mockTime.Advance(20 * time.Second)
+
+	value, expiresAt, found := cache.GetWithExpiry("k")
+	if !found || value != "v" {
+		t.Fatalf("GetWithExpiry = %v, %v", value, found)
+	}
+	remaining := expiresAt.Sub(time.Unix(0, mockTime.Now()))
+	if remaining < 40*time.Second || remaining > 40*time.Second+time.Microsecond {
+		t.Errorf("expected ~40s remaining, got %v", remaining)
+	}
+	if next, _ := cache.NextExpiration(); !next.Equal(expiresAt) {
+		t.Errorf("expiry %v must match NextExpiration %v", expiresAt, next)
+	}
+
+	mockTime.Advance(time.Minute)
+	if _, _, found := cache.GetWithExpiry("k"); found {
+		t.Error("expired entries must not be returned")
+	}
+	if _, _, found := cache.GetWithExpiry(""); found {
+		t.Error("empty key must miss")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("GetWithExpiry must count like Get: hits=%d misses=%d", stats.Hits, stats.Misses)
+	}
+}
+
+func TestGetWithExpiry_NoTTL(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+	value, expiresAt, found := cache.GetWithExpiry("k")
+	if !found || value != 1 || !expiresAt.IsZero() {
+		t.Errorf("GetWithExpiry = %v, %v, %v; want 1, zero time, true", value, expiresAt, found)
+	}
+}
+
+func TestGetWithExpiry_ConcurrentUpdates(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Hour}).(*wtinyLFUCache)
+	cache.Set("k", 0)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			cache.Set("k", i)
+			cache.Set(fmt.Sprintf("other%d", i%50), i)
+		}
+	}()
+	for i := 0; i < 10000; i++ {
+		if _, expiresAt, found := cache.GetWithExpiry("k"); found && expiresAt.IsZero() {
+			t.Fatal("a value with a TTL must come with its deadline")
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestGetWithExpiry_Generic(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, TimeProvider: mockTime})
+	cache.GetOrLoad("k", func() (int, error) { return 7, nil }, WithTTL(30*time.Second))
+
+	value, expiresAt, found := cache.GetWithExpiry("k")
+	if !found || value != 7 {
+		t.Fatalf("GetWithExpiry = %v, %v", value, found)
+	}
+	if want := time.Unix(0, mockTime.Now()).Add(30 * time.Second); expiresAt.Sub(want) > time.Microsecond || want.Sub(expiresAt) > 0 {
+		t.Errorf("expiresAt = %v, want %v", expiresAt, want)
+	}
+}
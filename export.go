@@ -0,0 +1,354 @@
+// export.go: JSON and MessagePack dumps of the cache contents
+//
+// Export writes the live entries as a readable JSON or MessagePack
+// document; Import restores it.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// exportVersion is the version of the Export document.
+const exportVersion = 1
+
+// ExportFormat is the encoding of an Export document.
+type ExportFormat int
+
+const (
+	// FormatJSON is an indented JSON document.
+	FormatJSON ExportFormat = iota
+
+	// FormatMsgpack is a MessagePack document.
+	FormatMsgpack
+)
+
+// String returns "json" or "msgpack".
+func (f ExportFormat) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatMsgpack:
+		return "msgpack"
+	default:
+		return fmt.Sprintf("ExportFormat(%d)", int(f))
+	}
+}
+
+// exportEntry is one exported entry. Value holds the JSON encoding of the
+// cached value.
+type exportEntry struct {
+	Key    string          `json:"key"`
+	Source string          `json:"source,omitempty"`
+	TTL    int64           `json:"ttl_ns,omitempty"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// exportDocument is the JSON form of an Export document. MessagePack
+// documents have the same fields:
+//
+//	{"version": 1, "exported_at": "2025-06-01T12:00:00Z", "entries": [
+//	    {"key": "user:1", "source": "db", "ttl_ns": 299000000000, "value": {...}},
+//	    ...
+//	]}
+type exportDocument struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Entries    []exportEntry `json:"entries"`
+}
+
+// importEntry is one decoded entry, its value in generic form.
+type importEntry struct {
+	key    string
+	source string
+	ttl    int64
+	value  interface{}
+}
+
+// Export writes the live entries to w in format and returns the number of
+// entries written. Every value must be marshalable by encoding/json.
+func (c *wtinyLFUCache) Export(w io.Writer, format ExportFormat) (int, error) {
+	if c.isClosed() {
+		return 0, NewErrCacheClosed("Export")
+	}
+	t := c.table.Load()
+	if t == nil {
+		return 0, NewErrCacheClosed("Export") // Closed
+	}
+	if format != FormatJSON && format != FormatMsgpack {
+		return 0, NewErrSaveFailed("", fmt.Errorf("unknown export format %v", format))
+	}
+
+	doc := exportDocument{Version: exportVersion, ExportedAt: time.Now().UTC(), Entries: []exportEntry{}}
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	for i := range t.entries {
+		e, ok := c.snapshotOf(&t.entries[i], ttlNow)
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(e.value)
+		if err != nil {
+			return 0, NewErrSaveFailed("", fmt.Errorf("value of key %q (%T) cannot be exported as %v: %w", e.key, e.value, format, err))
+		}
+		doc.Entries = append(doc.Entries, exportEntry{Key: e.key, Source: e.source, TTL: e.remaining, Value: data})
+	}
+	slices.SortFunc(doc.Entries, func(a, b exportEntry) int { return strings.Compare(a.Key, b.Key) })
+
+	bw := bufio.NewWriter(w)
+	if format == FormatJSON {
+		enc := json.NewEncoder(bw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			return 0, NewErrSaveFailed("", err)
+		}
+	} else if err := writeMsgpackDocument(bw, doc); err != nil {
+		return 0, NewErrSaveFailed("", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, NewErrSaveFailed("", err)
+	}
+	return len(doc.Entries), nil
+}
+
+// writeMsgpackDocument encodes doc, converting each value from its JSON
+// encoding to the generic tree MessagePack is written from.
+func writeMsgpackDocument(w *bufio.Writer, doc exportDocument) error {
+	entries := make([]interface{}, len(doc.Entries))
+	for i, e := range doc.Entries {
+		value, err := decodeJSONValue(e.Value)
+		if err != nil {
+			return err
+		}
+		fields := map[string]interface{}{"key": e.Key, "value": value}
+		if e.Source != "" {
+			fields["source"] = e.Source
+		}
+		if e.TTL != 0 {
+			fields["ttl_ns"] = e.TTL
+		}
+		entries[i] = fields
+	}
+	m := &msgpackWriter{w: w}
+	return m.write(map[string]interface{}{
+		"version":     int64(doc.Version),
+		"exported_at": doc.ExportedAt.Format(time.RFC3339Nano),
+		"entries":     entries,
+	})
+}
+
+// decodeJSONValue decodes a JSON value to its generic form, with numbers as
+// int64, uint64 or float64.
+func decodeJSONValue(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return plainValue(value), nil
+}
+
+// plainValue replaces the json.Number of a decoded tree with plainNumber.
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return plainNumber(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = plainValue(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = plainValue(v[key])
+		}
+	}
+	return value
+}
+
+// Import restores a document written by Export and returns the number of
+// entries stored. Values are stored in their generic form. Nothing is stored
+// if the document is invalid.
+func (c *wtinyLFUCache) Import(r io.Reader, format ExportFormat) (int, error) {
+	return c.importFrom(r, format, nil)
+}
+
+// importFrom restores a document, passing each value through convert when
+// it is not nil.
+func (c *wtinyLFUCache) importFrom(r io.Reader, format ExportFormat, convert func(value interface{}) (interface{}, error)) (int, error) {
+	if c.isClosed() {
+		return 0, NewErrCacheClosed("Import")
+	}
+	var entries []importEntry
+	var exportedAt time.Time
+	var err error
+	switch format {
+	case FormatJSON:
+		entries, exportedAt, err = readJSONDocument(r)
+	case FormatMsgpack:
+		entries, exportedAt, err = readMsgpackDocument(r)
+	default:
+		return 0, NewErrLoadFailed("", fmt.Errorf("unknown export format %v", format))
+	}
+	if err != nil {
+		return 0, err
+	}
+	for i := range entries {
+		if convert != nil {
+			if entries[i].value, err = convert(entries[i].value); err != nil {
+				return 0, NewErrCorruptedData("", fmt.Sprintf("decoding value of key %q: %v", entries[i].key, err))
+			}
+		}
+		if entries[i].value == nil {
+			return 0, NewErrCorruptedData("", fmt.Sprintf("null value of key %q cannot be cached", entries[i].key))
+		}
+	}
+
+	elapsed := max(int64(time.Since(exportedAt)), 0) // Clock skew: keep the exported TTLs
+	loaded := 0
+	for _, e := range entries {
+		ttlNanos := e.ttl
+		if ttlNanos > 0 {
+			if ttlNanos -= elapsed; ttlNanos <= 0 {
+				continue // Expired since the export
+			}
+			c.raiseMaxTTL(ttlNanos)
+		}
+		if c.set(e.key, e.value, truncateSource(e.source), ttlNanos) {
+			loaded++
+		}
+	}
+	return loaded, nil
+}
+
+// checkImportEntry validates a decoded entry.
+func checkImportEntry(e importEntry) error {
+	if e.key == "" || e.ttl < 0 {
+		return NewErrCorruptedData("", "invalid entry")
+	}
+	return nil
+}
+
+// readJSONDocument decodes a whole FormatJSON document.
+func readJSONDocument(r io.Reader) ([]importEntry, time.Time, error) {
+	var doc exportDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, time.Time{}, NewErrCorruptedData("", "truncated document")
+		}
+		return nil, time.Time{}, NewErrCorruptedData("", err.Error())
+	}
+	if doc.Version != exportVersion {
+		return nil, time.Time{}, NewErrCorruptedData("", fmt.Sprintf("unsupported export version %d", doc.Version))
+	}
+	entries := make([]importEntry, 0, len(doc.Entries))
+	for _, e := range doc.Entries {
+		if len(e.Value) == 0 {
+			return nil, time.Time{}, NewErrCorruptedData("", fmt.Sprintf("missing value of key %q", e.Key))
+		}
+		value, err := decodeJSONValue(e.Value)
+		if err != nil {
+			return nil, time.Time{}, NewErrCorruptedData("", fmt.Sprintf("decoding value of key %q: %v", e.Key, err))
+		}
+		entry := importEntry{key: e.Key, source: e.Source, ttl: e.TTL, value: value}
+		if err := checkImportEntry(entry); err != nil {
+			return nil, time.Time{}, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, doc.ExportedAt, nil
+}
+
+// readMsgpackDocument decodes a whole FormatMsgpack document.
+func readMsgpackDocument(r io.Reader) ([]importEntry, time.Time, error) {
+	corrupted := func(details string) ([]importEntry, time.Time, error) {
+		return nil, time.Time{}, NewErrCorruptedData("", details)
+	}
+	m := &msgpackReader{r: bufio.NewReader(r)}
+	tree, err := m.read()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return corrupted("truncated document")
+		}
+		return nil, time.Time{}, NewErrLoadFailed("", err)
+	}
+	doc, ok := tree.(map[string]interface{})
+	if !ok {
+		return corrupted("not an export document")
+	}
+	if version, _ := doc["version"].(int64); version != exportVersion {
+		return corrupted(fmt.Sprintf("unsupported export version %v", doc["version"]))
+	}
+	stamp, _ := doc["exported_at"].(string)
+	exportedAt, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return corrupted("invalid exported_at")
+	}
+	list, ok := doc["entries"].([]interface{})
+	if !ok {
+		return corrupted("missing entries")
+	}
+
+	entries := make([]importEntry, 0, len(list))
+	for _, item := range list {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return corrupted("invalid entry")
+		}
+		value, ok := fields["value"]
+		if !ok {
+			return corrupted(fmt.Sprintf("missing value of key %q", fields["key"]))
+		}
+		entry := importEntry{value: value}
+		entry.key, _ = fields["key"].(string)
+		entry.source, _ = fields["source"].(string)
+		if ttl, present := fields["ttl_ns"]; present {
+			if entry.ttl, ok = ttl.(int64); !ok {
+				return corrupted("invalid entry")
+			}
+		}
+		if err := checkImportEntry(entry); err != nil {
+			return nil, time.Time{}, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, exportedAt, nil
+}
+
+// Export writes the live entries to w in format.
+func (c *GenericCache[K, V]) Export(w io.Writer, format ExportFormat) (int, error) {
+	return c.inner.Export(w, format)
+}
+
+// Import restores a document written by Export, decoding every value into V
+// as encoding/json would. Nothing is stored if a value does not decode.
+func (c *GenericCache[K, V]) Import(r io.Reader, format ExportFormat) (int, error) {
+	core, ok := c.inner.(*wtinyLFUCache)
+	if !ok {
+		return c.inner.Import(r, format)
+	}
+	return core.importFrom(r, format, func(value interface{}) (interface{}, error) {
+		if v, ok := value.(V); ok {
+			return v, nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		var v V
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
@@ -0,0 +1,302 @@
+// export_test.go: tests for Export and Import
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+type exportedUser struct {
+	Name  string   `json:"name"`
+	Age   int      `json:"age"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// newExportCache returns a cache holding a few values of different kinds.
+func newExportCache(t *testing.T) *wtinyLFUCache {
+	t.Helper()
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	cache.Set("b:int", 42)
+	cache.Set("a:user", exportedUser{Name: "Ada", Age: 36, Roles: []string{"admin"}})
+	cache.raiseMaxTTL(int64(time.Hour))
+	cache.set("c:ttl", "short-lived", "", int64(time.Hour))
+	cache.SetWithSource("d:sourced", 2.5, "db")
+	cache.Set("e:list", []string{"x", "y"})
+	return cache
+}
+
+func TestExport_JSON(t *testing.T) {
+	cache := newExportCache(t)
+
+	var buf bytes.Buffer
+	n, err := cache.Export(&buf, FormatJSON)
+	if err != nil || n != 5 {
+		t.Fatalf("Export() = %d, %v; want 5 entries", n, err)
+	}
+
+	var doc struct {
+		Version int `json:"version"`
+		Entries []struct {
+			Key    string          `json:"key"`
+			Source string          `json:"source"`
+			TTL    int64           `json:"ttl_ns"`
+			Value  json.RawMessage `json:"value"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Export did not write valid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Version != exportVersion || len(doc.Entries) != 5 {
+		t.Fatalf("document = %+v", doc)
+	}
+	keys := make([]string, len(doc.Entries))
+	for i, e := range doc.Entries {
+		keys[i] = e.Key
+	}
+	if want := []string{"a:user", "b:int", "c:ttl", "d:sourced", "e:list"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %v, want sorted %v", keys, want)
+	}
+	var user bytes.Buffer
+	if err := json.Compact(&user, doc.Entries[0].Value); err != nil || user.String() != `{"name":"Ada","age":36,"roles":["admin"]}` {
+		t.Errorf("user value = %s", doc.Entries[0].Value)
+	}
+	if e := doc.Entries[2]; e.TTL <= 0 || e.TTL > int64(time.Hour) {
+		t.Errorf("ttl_ns of c:ttl = %d, want the remaining hour", e.TTL)
+	}
+	if e := doc.Entries[3]; e.Source != "db" || string(e.Value) != "2.5" {
+		t.Errorf("d:sourced = %+v", e)
+	}
+	if strings.Contains(buf.String(), `"ttl_ns": 0`) || strings.Contains(buf.String(), `"source": ""`) {
+		t.Errorf("empty ttl_ns and source must be omitted:\n%s", buf.String())
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	for _, format := range []ExportFormat{FormatJSON, FormatMsgpack} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := newExportCache(t).Export(&buf, format); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			restored := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+			defer restored.Close()
+			n, err := restored.Import(&buf, format)
+			if err != nil || n != 5 {
+				t.Fatalf("Import() = %d, %v; want 5 entries", n, err)
+			}
+
+			want := map[string]interface{}{
+				"a:user":    map[string]interface{}{"name": "Ada", "age": int64(36), "roles": []interface{}{"admin"}},
+				"b:int":     int64(42),
+				"c:ttl":     "short-lived",
+				"d:sourced": 2.5,
+				"e:list":    []interface{}{"x", "y"},
+			}
+			for key, value := range want {
+				got, found := restored.Get(key)
+				if !found || !reflect.DeepEqual(got, value) {
+					t.Errorf("Get(%q) = %#v, %v; want %#v", key, got, found, value)
+				}
+			}
+			if info, _ := restored.EntryInfo("d:sourced"); info.Source != "db" {
+				t.Errorf("source of d:sourced = %q, want db", info.Source)
+			}
+			_, withTTL, _ := restored.GetWithExpiry("c:ttl")
+			_, withoutTTL, _ := restored.GetWithExpiry("b:int")
+			if withTTL.IsZero() || !withoutTTL.IsZero() {
+				t.Error("TTLs must be restored, and only on entries that had one")
+			}
+		})
+	}
+}
+
+func TestExport_Deterministic(t *testing.T) {
+	for _, format := range []ExportFormat{FormatJSON, FormatMsgpack} {
+		build := func() []byte {
+			cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+			defer cache.Close()
+			for _, key := range []string{"z", "m", "a", "q"} {
+				cache.Set(key, map[string]interface{}{"k": key, "n": len(key), "list": []int{1, 2}})
+			}
+			var buf bytes.Buffer
+			if _, err := cache.Export(&buf, format); err != nil {
+				t.Fatal(err)
+			}
+			// exported_at differs between dumps by design
+			return stripExportedAt(buf.Bytes(), format)
+		}
+		if a, b := build(), build(); !bytes.Equal(a, b) {
+			t.Errorf("%v: two dumps of the same data differ", format)
+		}
+	}
+}
+
+// stripExportedAt removes the exported_at field of a document.
+func stripExportedAt(data []byte, format ExportFormat) []byte {
+	if format == FormatJSON {
+		lines := strings.Split(string(data), "\n")
+		kept := lines[:0]
+		for _, line := range lines {
+			if !strings.Contains(line, `"exported_at"`) {
+				kept = append(kept, line)
+			}
+		}
+		return []byte(strings.Join(kept, "\n"))
+	}
+	// fixstr "exported_at", then the timestamp as a fixstr or a str8
+	start := bytes.Index(data, []byte("exported_at")) - 1
+	end := start + 1 + len("exported_at")
+	if code := data[end]; code&0xe0 == 0xa0 {
+		end += 1 + int(code&0x1f)
+	} else {
+		end += 2 + int(data[end+1])
+	}
+	return append(append([]byte{}, data[:start]...), data[end:]...)
+}
+
+func TestExport_UnmarshalableValue(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+	cache.Set("ok", 1)
+	cache.Set("chan", make(chan int))
+
+	for _, format := range []ExportFormat{FormatJSON, FormatMsgpack} {
+		var buf bytes.Buffer
+		n, err := cache.Export(&buf, format)
+		if err == nil || n != 0 {
+			t.Fatalf("%v: Export() = %d, %v; want an error", format, n, err)
+		}
+		if !errors.HasCode(err, ErrCodeSaveFailed) {
+			t.Errorf("%v: error code = %v, want %s", format, GetErrorCode(err), ErrCodeSaveFailed)
+		}
+		if msg := err.(*errors.Error).Unwrap().Error(); !strings.Contains(msg, `"chan"`) || !strings.Contains(msg, "chan int") {
+			t.Errorf("%v: error %q must name the key and the value type", format, msg)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%v: %d bytes written on error, want none", format, buf.Len())
+		}
+	}
+}
+
+func TestImport_Invalid(t *testing.T) {
+	var valid bytes.Buffer
+	if _, err := newExportCache(t).Export(&valid, FormatMsgpack); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		format ExportFormat
+		data   []byte
+	}{
+		{"json garbage", FormatJSON, []byte("{not json")},
+		{"json version", FormatJSON, []byte(`{"version":2,"entries":[]}`)},
+		{"json empty key", FormatJSON, []byte(`{"version":1,"entries":[{"key":"","value":1}]}`)},
+		{"json negative ttl", FormatJSON, []byte(`{"version":1,"entries":[{"key":"k","ttl_ns":-1,"value":1}]}`)},
+		{"json missing value", FormatJSON, []byte(`{"version":1,"entries":[{"key":"k"}]}`)},
+		{"json null value", FormatJSON, []byte(`{"version":1,"entries":[{"key":"k","value":null}]}`)},
+		{"msgpack truncated", FormatMsgpack, valid.Bytes()[:valid.Len()/2]},
+		{"msgpack not a map", FormatMsgpack, []byte{0x01}},
+		{"msgpack huge array", FormatMsgpack, []byte{0xdd, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+			defer cache.Close()
+			n, err := cache.Import(bytes.NewReader(tc.data), tc.format)
+			if err == nil || n != 0 {
+				t.Fatalf("Import() = %d, %v; want an error", n, err)
+			}
+			if !errors.HasCode(err, ErrCodeCorruptedData) {
+				t.Errorf("error code = %v, want %s", GetErrorCode(err), ErrCodeCorruptedData)
+			}
+			if cache.Len() != 0 {
+				t.Errorf("%d entries stored from an invalid document", cache.Len())
+			}
+		})
+	}
+}
+
+func TestImport_ExpiredSinceExport(t *testing.T) {
+	doc := `{"version":1,"exported_at":"2020-01-01T00:00:00Z","entries":[
+		{"key":"old","ttl_ns":60000000000,"value":"x"},
+		{"key":"forever","value":"y"}]}`
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	n, err := cache.Import(strings.NewReader(doc), FormatJSON)
+	if err != nil || n != 1 {
+		t.Fatalf("Import() = %d, %v; want only the entry without TTL", n, err)
+	}
+	if cache.Has("old") || !cache.Has("forever") {
+		t.Error("an entry whose TTL ran out since the export must be skipped")
+	}
+}
+
+func TestGenericCache_ImportTyped(t *testing.T) {
+	for _, format := range []ExportFormat{FormatJSON, FormatMsgpack} {
+		t.Run(format.String(), func(t *testing.T) {
+			source := NewGenericCache[string, exportedUser](Config{MaxSize: 100})
+			defer source.Close()
+			source.Set("ada", exportedUser{Name: "Ada", Age: 36})
+			source.Set("bob", exportedUser{Name: "Bob", Age: 41, Roles: []string{"ops"}})
+
+			var buf bytes.Buffer
+			if _, err := source.Export(&buf, format); err != nil {
+				t.Fatal(err)
+			}
+			restored := NewGenericCache[string, exportedUser](Config{MaxSize: 100})
+			defer restored.Close()
+			if n, err := restored.Import(&buf, format); err != nil || n != 2 {
+				t.Fatalf("Import() = %d, %v", n, err)
+			}
+			if got, _ := restored.Get("bob"); !reflect.DeepEqual(got, exportedUser{Name: "Bob", Age: 41, Roles: []string{"ops"}}) {
+				t.Errorf("Get(bob) = %+v", got)
+			}
+
+			// Integer keys and []byte values
+			blobs := NewGenericCache[int, []byte](Config{MaxSize: 100})
+			defer blobs.Close()
+			blobs.Set(7, []byte{0, 1, 2, 0xff})
+			buf.Reset()
+			if _, err := blobs.Export(&buf, format); err != nil {
+				t.Fatal(err)
+			}
+			blobs.Clear()
+			if _, err := blobs.Import(&buf, format); err != nil {
+				t.Fatal(err)
+			}
+			if got, found := blobs.Get(7); !found || !bytes.Equal(got, []byte{0, 1, 2, 0xff}) {
+				t.Errorf("Get(7) = %v, %v", got, found)
+			}
+		})
+	}
+}
+
+func TestGenericCache_ImportTypeMismatch(t *testing.T) {
+	doc := `{"version":1,"entries":[{"key":"n","value":1},{"key":"s","value":"text"}]}`
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+	defer cache.Close()
+
+	n, err := cache.Import(strings.NewReader(doc), FormatJSON)
+	if err == nil || n != 0 || !errors.HasCode(err, ErrCodeCorruptedData) {
+		t.Fatalf("Import() = %d, %v; want a BALIOS_CORRUPTED_DATA error", n, err)
+	}
+	if cache.Len() != 0 {
+		t.Error("nothing must be stored when a value does not decode into V")
+	}
+}
@@ -0,0 +1,580 @@
+// extensions.go: wrapping caches that implement part of the optional interfaces
+//
+// GenericCache, SwappableCache and Namespace expose every optional interface
+// of Cache (see interfaces.go) over a Cache that may lack some of them.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+)
+
+// extendedCache is a Cache implementing every optional interface, as the
+// caches returned by NewCache and SwappableCache do.
+type extendedCache interface {
+	Cache
+	CheckedCache
+	ExpiryReader
+	HistoryReader
+	BatchCache
+	BatchLoader
+	Snapshotter
+	SketchController
+	ConditionalCache
+	Versioned
+	Resizable
+	Compactor
+	NegativeCache
+	DependencyTracker
+	Namespaced
+	SourceTagger
+	EventSource
+	KeyLocker
+	Iterable
+	Inspector
+	Diagnostics
+}
+
+var (
+	_ extendedCache = (*wtinyLFUCache)(nil)
+	_ extendedCache = (*SwappableCache)(nil)
+)
+
+// extend returns c as an extendedCache, wrapping it in a basicCache if it
+// lacks some optional interfaces.
+func extend(c Cache) extendedCache {
+	if e, ok := c.(extendedCache); ok {
+		return e
+	}
+	return basicCache{c}
+}
+
+// unextend returns the Cache wrapped by extend.
+func unextend(c extendedCache) Cache {
+	if b, ok := c.(basicCache); ok {
+		return b.Cache
+	}
+	return c
+}
+
+// basicCache adds the optional interfaces to a Cache implementing only some
+// of them. The methods of the interfaces it implements are forwarded;
+// CheckedCache and the reads and writes of BatchCache are emulated with the
+// core operations; the other methods do nothing and report failure (false,
+// zero values or a BALIOS_INVALID_CONFIG error naming the method).
+type basicCache struct {
+	Cache
+}
+
+// errNotImplemented reports a call to method on a cache lacking it.
+func errNotImplemented(method string) error {
+	return NewErrInvalidConfig(method, "not implemented by the wrapped cache")
+}
+
+// GetE forwards to CheckedCache, or reports a miss as BALIOS_KEY_NOT_FOUND.
+func (b basicCache) GetE(key string) (interface{}, error) {
+	if c, ok := b.Cache.(CheckedCache); ok {
+		return c.GetE(key)
+	}
+	if key == "" {
+		return nil, NewErrEmptyKey("GetE")
+	}
+	if value, found := b.Get(key); found {
+		return value, nil
+	}
+	return nil, NewErrKeyNotFound(key)
+}
+
+// GetWithReason forwards to CheckedCache, or reports misses as MissAbsent.
+func (b basicCache) GetWithReason(key string) (value interface{}, reason MissReason, found bool) {
+	if c, ok := b.Cache.(CheckedCache); ok {
+		return c.GetWithReason(key)
+	}
+	if value, found = b.Get(key); found {
+		return value, MissNone, true
+	}
+	return nil, MissAbsent, false
+}
+
+// GetCtx forwards to CheckedCache, or calls Get.
+func (b basicCache) GetCtx(ctx context.Context, key string) (value interface{}, found bool, err error) {
+	if c, ok := b.Cache.(CheckedCache); ok {
+		return c.GetCtx(ctx, key)
+	}
+	value, found = b.Get(key)
+	return value, found, nil
+}
+
+// SetE forwards to CheckedCache, or reports a failed Set as BALIOS_SET_FAILED.
+func (b basicCache) SetE(key string, value interface{}) error {
+	if c, ok := b.Cache.(CheckedCache); ok {
+		return c.SetE(key, value)
+	}
+	if key == "" {
+		return NewErrEmptyKey("SetE")
+	}
+	if !b.Set(key, value) {
+		return NewErrSetFailed(key, "value not stored")
+	}
+	return nil
+}
+
+// SetCtx forwards to CheckedCache, or calls SetE.
+func (b basicCache) SetCtx(ctx context.Context, key string, value interface{}) error {
+	if c, ok := b.Cache.(CheckedCache); ok {
+		return c.SetCtx(ctx, key, value)
+	}
+	return b.SetE(key, value)
+}
+
+// GetWithExpiry forwards to ExpiryReader, or calls Get (no expiry).
+func (b basicCache) GetWithExpiry(key string) (value interface{}, expiresAt time.Time, found bool) {
+	if c, ok := b.Cache.(ExpiryReader); ok {
+		return c.GetWithExpiry(key)
+	}
+	value, found = b.Get(key)
+	return value, time.Time{}, found
+}
+
+// NextExpiration forwards to ExpiryReader.
+func (b basicCache) NextExpiration() (next time.Time, ok bool) {
+	if c, ok := b.Cache.(ExpiryReader); ok {
+		return c.NextExpiration()
+	}
+	return time.Time{}, false
+}
+
+// ExpiringBefore forwards to ExpiryReader.
+func (b basicCache) ExpiringBefore(t time.Time) []string {
+	if c, ok := b.Cache.(ExpiryReader); ok {
+		return c.ExpiringBefore(t)
+	}
+	return nil
+}
+
+// GetPrevious forwards to HistoryReader.
+func (b basicCache) GetPrevious(key string) (value interface{}, found bool) {
+	if c, ok := b.Cache.(HistoryReader); ok {
+		return c.GetPrevious(key)
+	}
+	return nil, false
+}
+
+// History forwards to HistoryReader.
+func (b basicCache) History(key string) []interface{} {
+	if c, ok := b.Cache.(HistoryReader); ok {
+		return c.History(key)
+	}
+	return nil
+}
+
+// GetMany forwards to BatchCache, or calls Get for each key.
+func (b basicCache) GetMany(keys []string) map[string]interface{} {
+	if c, ok := b.Cache.(BatchCache); ok {
+		return c.GetMany(keys)
+	}
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, found := b.Get(key); found {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// SetMany forwards to BatchCache, or calls Set for each entry.
+func (b basicCache) SetMany(entries map[string]interface{}) int {
+	if c, ok := b.Cache.(BatchCache); ok {
+		return c.SetMany(entries)
+	}
+	stored := 0
+	for key, value := range entries {
+		if key != "" && b.Set(key, value) {
+			stored++
+		}
+	}
+	return stored
+}
+
+// Warm forwards to BatchCache.
+func (b basicCache) Warm(entries map[string]interface{}) int {
+	if c, ok := b.Cache.(BatchCache); ok {
+		return c.Warm(entries)
+	}
+	return 0
+}
+
+// WarmSeq forwards to BatchCache.
+func (b basicCache) WarmSeq(entries iter.Seq2[string, interface{}]) int {
+	if c, ok := b.Cache.(BatchCache); ok {
+		return c.WarmSeq(entries)
+	}
+	return 0
+}
+
+// GetOrLoadMany forwards to BatchLoader.
+func (b basicCache) GetOrLoadMany(keys []string, loader func(missing []string) (map[string]interface{}, error), opts ...LoadOption) (map[string]interface{}, error) {
+	if c, ok := b.Cache.(BatchLoader); ok {
+		return c.GetOrLoadMany(keys, loader, opts...)
+	}
+	return nil, errNotImplemented("GetOrLoadMany")
+}
+
+// GetOrLoadGrouped forwards to BatchLoader.
+func (b basicCache) GetOrLoadGrouped(groupKey, key string, loader func(keys []string) (map[string]interface{}, error), opts ...LoadOption) (interface{}, error) {
+	if c, ok := b.Cache.(BatchLoader); ok {
+		return c.GetOrLoadGrouped(groupKey, key, loader, opts...)
+	}
+	return nil, errNotImplemented("GetOrLoadGrouped")
+}
+
+// SaveTo forwards to Snapshotter.
+func (b basicCache) SaveTo(w io.Writer) (int, error) {
+	if c, ok := b.Cache.(Snapshotter); ok {
+		return c.SaveTo(w)
+	}
+	return 0, errNotImplemented("SaveTo")
+}
+
+// LoadFrom forwards to Snapshotter.
+func (b basicCache) LoadFrom(r io.Reader) (int, error) {
+	if c, ok := b.Cache.(Snapshotter); ok {
+		return c.LoadFrom(r)
+	}
+	return 0, errNotImplemented("LoadFrom")
+}
+
+// SaveToFile forwards to Snapshotter.
+func (b basicCache) SaveToFile(path string) (int, error) {
+	if c, ok := b.Cache.(Snapshotter); ok {
+		return c.SaveToFile(path)
+	}
+	return 0, errNotImplemented("SaveToFile")
+}
+
+// LoadFromFile forwards to Snapshotter.
+func (b basicCache) LoadFromFile(path string) (int, error) {
+	if c, ok := b.Cache.(Snapshotter); ok {
+		return c.LoadFromFile(path)
+	}
+	return 0, errNotImplemented("LoadFromFile")
+}
+
+// Export forwards to Snapshotter.
+func (b basicCache) Export(w io.Writer, format ExportFormat) (int, error) {
+	if c, ok := b.Cache.(Snapshotter); ok {
+		return c.Export(w, format)
+	}
+	return 0, errNotImplemented("Export")
+}
+
+// Import forwards to Snapshotter.
+func (b basicCache) Import(r io.Reader, format ExportFormat) (int, error) {
+	if c, ok := b.Cache.(Snapshotter); ok {
+		return c.Import(r, format)
+	}
+	return 0, errNotImplemented("Import")
+}
+
+// DecaySketch forwards to SketchController.
+func (b basicCache) DecaySketch() {
+	if c, ok := b.Cache.(SketchController); ok {
+		c.DecaySketch()
+	}
+}
+
+// SketchSnapshot forwards to SketchController.
+func (b basicCache) SketchSnapshot() []byte {
+	if c, ok := b.Cache.(SketchController); ok {
+		return c.SketchSnapshot()
+	}
+	return nil
+}
+
+// RestoreSketch forwards to SketchController.
+func (b basicCache) RestoreSketch(data []byte) error {
+	if c, ok := b.Cache.(SketchController); ok {
+		return c.RestoreSketch(data)
+	}
+	return errNotImplemented("RestoreSketch")
+}
+
+// CompareAndSwap forwards to ConditionalCache.
+func (b basicCache) CompareAndSwap(key string, oldValue, newValue interface{}) bool {
+	if c, ok := b.Cache.(ConditionalCache); ok {
+		return c.CompareAndSwap(key, oldValue, newValue)
+	}
+	return false
+}
+
+// Swap forwards to ConditionalCache; otherwise nothing is stored.
+func (b basicCache) Swap(key string, value interface{}) (previous interface{}, loaded bool) {
+	if c, ok := b.Cache.(ConditionalCache); ok {
+		return c.Swap(key, value)
+	}
+	return nil, false
+}
+
+// SetIfAbsent forwards to ConditionalCache.
+func (b basicCache) SetIfAbsent(key string, value interface{}) bool {
+	if c, ok := b.Cache.(ConditionalCache); ok {
+		return c.SetIfAbsent(key, value)
+	}
+	return false
+}
+
+// Compute forwards to ConditionalCache; otherwise fn is not called.
+func (b basicCache) Compute(key string, fn func(old interface{}, exists bool) (interface{}, bool)) (value interface{}, present bool) {
+	if c, ok := b.Cache.(ConditionalCache); ok {
+		return c.Compute(key, fn)
+	}
+	return nil, false
+}
+
+// GetVersion forwards to Versioned.
+func (b basicCache) GetVersion(key string) (value interface{}, version uint64, found bool) {
+	if c, ok := b.Cache.(Versioned); ok {
+		return c.GetVersion(key)
+	}
+	value, found = b.Get(key)
+	return value, 0, found
+}
+
+// VersionOf forwards to Versioned.
+func (b basicCache) VersionOf(key string) (version uint64, found bool) {
+	if c, ok := b.Cache.(Versioned); ok {
+		return c.VersionOf(key)
+	}
+	return 0, b.Has(key)
+}
+
+// SetIfVersion forwards to Versioned.
+func (b basicCache) SetIfVersion(key string, value interface{}, version uint64) (newVersion uint64, ok bool) {
+	if c, ok := b.Cache.(Versioned); ok {
+		return c.SetIfVersion(key, value, version)
+	}
+	return 0, false
+}
+
+// Resize forwards to Resizable.
+func (b basicCache) Resize(newMaxSize int) error {
+	if c, ok := b.Cache.(Resizable); ok {
+		return c.Resize(newMaxSize)
+	}
+	return errNotImplemented("Resize")
+}
+
+// CompactionReport forwards to Compactor.
+func (b basicCache) CompactionReport() CompactionReport {
+	if c, ok := b.Cache.(Compactor); ok {
+		return c.CompactionReport()
+	}
+	return CompactionReport{}
+}
+
+// Compact forwards to Compactor.
+func (b basicCache) Compact() CompactionReport {
+	if c, ok := b.Cache.(Compactor); ok {
+		return c.Compact()
+	}
+	return CompactionReport{}
+}
+
+// InvalidateNegative forwards to NegativeCache.
+func (b basicCache) InvalidateNegative(key string) bool {
+	if c, ok := b.Cache.(NegativeCache); ok {
+		return c.InvalidateNegative(key)
+	}
+	return false
+}
+
+// NegativeLen forwards to NegativeCache.
+func (b basicCache) NegativeLen() int {
+	if c, ok := b.Cache.(NegativeCache); ok {
+		return c.NegativeLen()
+	}
+	return 0
+}
+
+// SetWithDependencies forwards to DependencyTracker.
+func (b basicCache) SetWithDependencies(key string, value interface{}, deps ...string) bool {
+	if c, ok := b.Cache.(DependencyTracker); ok {
+		return c.SetWithDependencies(key, value, deps...)
+	}
+	return false
+}
+
+// InvalidateKey forwards to DependencyTracker.
+func (b basicCache) InvalidateKey(dep string) int {
+	if c, ok := b.Cache.(DependencyTracker); ok {
+		return c.InvalidateKey(dep)
+	}
+	return 0
+}
+
+// Namespace forwards to Namespaced, or returns a view whose scoped bulk
+// operations are not implemented.
+func (b basicCache) Namespace(name string, opts ...NamespaceOption) *Namespace {
+	if c, ok := b.Cache.(Namespaced); ok {
+		return c.Namespace(name, opts...)
+	}
+	return newNamespace(b, name, opts)
+}
+
+// ClearNamespace forwards to Namespaced.
+func (b basicCache) ClearNamespace(ns string) int {
+	if c, ok := b.Cache.(Namespaced); ok {
+		return c.ClearNamespace(ns)
+	}
+	return 0
+}
+
+// SetWithSource forwards to SourceTagger; otherwise the source is dropped.
+func (b basicCache) SetWithSource(key string, value interface{}, source string) bool {
+	if c, ok := b.Cache.(SourceTagger); ok {
+		return c.SetWithSource(key, value, source)
+	}
+	return b.Set(key, value)
+}
+
+// SourceOf forwards to SourceTagger.
+func (b basicCache) SourceOf(key string) (source string, found bool) {
+	if c, ok := b.Cache.(SourceTagger); ok {
+		return c.SourceOf(key)
+	}
+	return "", b.Has(key)
+}
+
+// Subscribe forwards to EventSource; otherwise the channel is nil.
+func (b basicCache) Subscribe(mask EventMask) <-chan Event {
+	if c, ok := b.Cache.(EventSource); ok {
+		return c.Subscribe(mask)
+	}
+	return nil
+}
+
+// Unsubscribe forwards to EventSource.
+func (b basicCache) Unsubscribe(ch <-chan Event) bool {
+	if c, ok := b.Cache.(EventSource); ok {
+		return c.Unsubscribe(ch)
+	}
+	return false
+}
+
+// LockKey forwards to KeyLocker, or locks nothing.
+func (b basicCache) LockKey(key string) func() {
+	if c, ok := b.Cache.(KeyLocker); ok {
+		return c.LockKey(key)
+	}
+	return func() {}
+}
+
+// Range forwards to Iterable.
+func (b basicCache) Range(f func(key string, value interface{}) bool) {
+	if c, ok := b.Cache.(Iterable); ok {
+		c.Range(f)
+	}
+}
+
+// Keys forwards to Iterable.
+func (b basicCache) Keys() []string {
+	if c, ok := b.Cache.(Iterable); ok {
+		return c.Keys()
+	}
+	return nil
+}
+
+// DeleteByPrefix forwards to Iterable.
+func (b basicCache) DeleteByPrefix(prefix string) int {
+	if c, ok := b.Cache.(Iterable); ok {
+		return c.DeleteByPrefix(prefix)
+	}
+	return 0
+}
+
+// DeleteFunc forwards to Iterable.
+func (b basicCache) DeleteFunc(f func(key string, value interface{}) bool) int {
+	if c, ok := b.Cache.(Iterable); ok {
+		return c.DeleteFunc(f)
+	}
+	return 0
+}
+
+// EntryInfo forwards to Inspector.
+func (b basicCache) EntryInfo(key string) (info EntryInfo, found bool) {
+	if c, ok := b.Cache.(Inspector); ok {
+		return c.EntryInfo(key)
+	}
+	return EntryInfo{}, false
+}
+
+// TopKeys forwards to Inspector.
+func (b basicCache) TopKeys(n int) []KeyFreq {
+	if c, ok := b.Cache.(Inspector); ok {
+		return c.TopKeys(n)
+	}
+	return nil
+}
+
+// MemoryUsage forwards to Inspector.
+func (b basicCache) MemoryUsage(top int) MemoryReport {
+	if c, ok := b.Cache.(Inspector); ok {
+		return c.MemoryUsage(top)
+	}
+	return MemoryReport{}
+}
+
+// DebugStats forwards to Diagnostics.
+func (b basicCache) DebugStats() DebugStats {
+	if c, ok := b.Cache.(Diagnostics); ok {
+		return c.DebugStats()
+	}
+	return DebugStats{}
+}
+
+// WindowStats forwards to Diagnostics.
+func (b basicCache) WindowStats(window time.Duration) WindowStats {
+	if c, ok := b.Cache.(Diagnostics); ok {
+		return c.WindowStats(window)
+	}
+	return WindowStats{}
+}
+
+// ShrinkStats forwards to Diagnostics.
+func (b basicCache) ShrinkStats() ShrinkStats {
+	if c, ok := b.Cache.(Diagnostics); ok {
+		return c.ShrinkStats()
+	}
+	return ShrinkStats{}
+}
+
+// LoadsNotAdmitted forwards to Diagnostics.
+func (b basicCache) LoadsNotAdmitted() int64 {
+	if c, ok := b.Cache.(Diagnostics); ok {
+		return c.LoadsNotAdmitted()
+	}
+	return 0
+}
+
+// LoadsRateLimited forwards to Diagnostics.
+func (b basicCache) LoadsRateLimited() int64 {
+	if c, ok := b.Cache.(Diagnostics); ok {
+		return c.LoadsRateLimited()
+	}
+	return 0
+}
+
+// Closed forwards to Diagnostics.
+func (b basicCache) Closed() bool {
+	if c, ok := b.Cache.(Diagnostics); ok {
+		return c.Closed()
+	}
+	return false
+}
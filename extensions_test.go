@@ -0,0 +1,69 @@
+// extensions_test.go: tests for wrapping caches without the optional interfaces
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"testing"
+)
+
+// coreOnly implements Cache and none of the optional interfaces.
+type coreOnly struct {
+	Cache
+}
+
+func TestExtend_CoreOnlyCache(t *testing.T) {
+	inner := coreOnly{NewCache(Config{MaxSize: 100})}
+	defer inner.Close()
+	if _, ok := Cache(inner).(Versioned); ok {
+		t.Fatal("coreOnly must not implement the optional interfaces")
+	}
+
+	cache := NewGenericCacheFrom[string, int](inner)
+	if n := cache.SetMany(map[string]int{"a": 1, "b": 2}); n != 2 {
+		t.Errorf("SetMany stored %d pairs, want 2", n)
+	}
+	if got := cache.GetMany([]string{"a", "b", "c"}); len(got) != 2 || got["b"] != 2 {
+		t.Errorf("GetMany = %v, want a and b", got)
+	}
+	if err := cache.SetE("c", 3); err != nil {
+		t.Errorf("SetE() error = %v", err)
+	}
+	if _, err := cache.GetE("missing"); GetErrorCode(err) != ErrCodeKeyNotFound {
+		t.Errorf("GetE(missing) error = %v, want %s", err, ErrCodeKeyNotFound)
+	}
+
+	// Other optional methods do nothing and report it
+	if _, ok := cache.SetIfVersion("a", 10, 1); ok {
+		t.Error("SetIfVersion must fail without Versioned")
+	}
+	if _, err := cache.SaveTo(&bytes.Buffer{}); GetErrorCode(err) != ErrCodeInvalidConfig {
+		t.Errorf("SaveTo() error = %v, want %s", err, ErrCodeInvalidConfig)
+	}
+	if v, found := cache.Get("a"); !found || v != 1 {
+		t.Errorf("Get(a) = %v, %v; want 1, true", v, found)
+	}
+}
+
+func TestExtend_SwappableKeepsCache(t *testing.T) {
+	inner := coreOnly{NewCache(Config{MaxSize: 100})}
+	defer inner.Close()
+	handle := NewSwappableCache(inner)
+	if handle.Current() != Cache(inner) {
+		t.Error("Current must return the cache given to NewSwappableCache")
+	}
+	if !handle.SetWithSource("k", 1, "db") {
+		t.Error("SetWithSource must store the value without SourceTagger")
+	}
+	if previous := handle.Replace(NewCache(Config{MaxSize: 100})); previous != Cache(inner) {
+		t.Error("Replace must return the cache it replaced")
+	}
+	defer handle.Close()
+	if _, ok := handle.Current().(*wtinyLFUCache); !ok {
+		t.Error("Current must return the built-in cache after Replace")
+	}
+}
@@ -0,0 +1,127 @@
+// family_stats.go: hit and miss counters per key family
+//
+// With Config.FamilyStats, hits and misses are counted per key family
+// (Config.KeyFamily) and reported in CacheStats.Families.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxStatsFamilies bounds the number of families tracked by FamilyStats.
+	maxStatsFamilies = 256
+
+	// FamilyOverflow is the family under which CacheStats.Families counts the
+	// lookups of families beyond the first 256 seen.
+	FamilyOverflow = "~overflow"
+)
+
+// FamilyStats holds the lookup counters of one key family.
+type FamilyStats struct {
+	// Hits is the number of lookups of the family that found a value
+	Hits uint64
+
+	// Misses is the number of lookups of the family that found nothing
+	Misses uint64
+}
+
+// HitRatio returns the family hit ratio as a percentage (0-100).
+// Returns 0.0 if the family has no lookups.
+func (s FamilyStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total) * 100
+}
+
+// familyCounters are the live counters of one family.
+type familyCounters struct {
+	hits   int64
+	misses int64
+}
+
+// familyStats counts hits and misses per key family.
+type familyStats struct {
+	family   func(string) string
+	counters sync.Map // family -> *familyCounters
+	count    int64    // Number of families in counters
+	overflow familyCounters
+}
+
+// newFamilyStats returns nil when family stats are disabled.
+func newFamilyStats(config Config) *familyStats {
+	if !config.FamilyStats {
+		return nil
+	}
+	family := config.KeyFamily
+	if family == nil {
+		family = namespaceOf
+	}
+	return &familyStats{family: family}
+}
+
+// countersFor returns the counters of key's family, registering the family
+// if the bound allows it.
+func (f *familyStats) countersFor(key string) *familyCounters {
+	family := f.family(key)
+	if v, ok := f.counters.Load(family); ok {
+		return v.(*familyCounters)
+	}
+	if atomic.AddInt64(&f.count, 1) > maxStatsFamilies {
+		atomic.AddInt64(&f.count, -1)
+		return &f.overflow
+	}
+	v, loaded := f.counters.LoadOrStore(family, &familyCounters{})
+	if loaded {
+		atomic.AddInt64(&f.count, -1)
+	}
+	return v.(*familyCounters)
+}
+
+// record counts a lookup of key.
+func (f *familyStats) record(key string, hit bool) {
+	counters := f.countersFor(key)
+	if hit {
+		atomic.AddInt64(&counters.hits, 1)
+	} else {
+		atomic.AddInt64(&counters.misses, 1)
+	}
+}
+
+// snapshot returns the counters of every family with at least one lookup.
+func (f *familyStats) snapshot() map[string]FamilyStats {
+	result := make(map[string]FamilyStats)
+	add := func(family string, counters *familyCounters) {
+		s := FamilyStats{
+			Hits:   uint64(atomic.LoadInt64(&counters.hits)),   // #nosec G115 - counters are always positive
+			Misses: uint64(atomic.LoadInt64(&counters.misses)), // #nosec G115 - counters are always positive
+		}
+		if s.Hits+s.Misses > 0 {
+			result[family] = s
+		}
+	}
+	f.counters.Range(func(key, value interface{}) bool {
+		add(key.(string), value.(*familyCounters))
+		return true
+	})
+	add(FamilyOverflow, &f.overflow)
+	return result
+}
+
+func (f *familyStats) reset() {
+	f.counters.Range(func(key, value interface{}) bool {
+		f.counters.Delete(key)
+		atomic.AddInt64(&f.count, -1)
+		return true
+	})
+	atomic.StoreInt64(&f.overflow.hits, 0)
+	atomic.StoreInt64(&f.overflow.misses, 0)
+}
@@ -0,0 +1,123 @@
+// family_stats_test.go: tests for per-family hit and miss counters
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFamilyStats_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+	cache.Set("user:1", 1)
+	cache.Get("user:1")
+	if families := cache.Stats().Families; families != nil {
+		t.Errorf("families must be nil when disabled, got %v", families)
+	}
+}
+
+func TestFamilyStats_NamespaceDefault(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, FamilyStats: true}).(*wtinyLFUCache)
+	cache.Set("user:1", 1)
+	cache.Set("search:q", 2)
+
+	cache.Get("user:1")
+	cache.Get("user:1")
+	cache.Get("user:2")
+	cache.Get("search:missing")
+	cache.GetMany([]string{"search:q", "search:other", "plain"})
+
+	families := cache.Stats().Families
+	if s := families["user"]; s.Hits != 2 || s.Misses != 1 {
+		t.Errorf("user: expected 2 hits 1 miss, got %+v", s)
+	}
+	if s := families["search"]; s.Hits != 1 || s.Misses != 2 {
+		t.Errorf("search: expected 1 hit 2 misses, got %+v", s)
+	}
+	if s := families[""]; s.Misses != 1 {
+		t.Errorf("keys without namespace must be counted under \"\", got %+v", s)
+	}
+	if ratio := families["user"].HitRatio(); ratio < 66 || ratio > 67 {
+		t.Errorf("expected user hit ratio ~66.7%%, got %.2f", ratio)
+	}
+}
+
+func TestFamilyStats_CustomKeyFamily(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:     100,
+		FamilyStats: true,
+		KeyFamily: func(key string) string {
+			if i := strings.IndexByte(key, '/'); i > 0 {
+				return key[:i]
+			}
+			return key
+		},
+	})
+	cache.Set("img/1", 1)
+	cache.Get("img/1")
+	cache.Get("img/2")
+
+	if s := cache.Stats().Families["img"]; s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("expected 1 hit 1 miss for img, got %+v", s)
+	}
+}
+
+func TestFamilyStats_Bounded(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, FamilyStats: true, KeyFamily: func(key string) string { return key }})
+	for i := 0; i < maxStatsFamilies+50; i++ {
+		cache.Get(fmt.Sprintf("k%d", i))
+	}
+
+	families := cache.Stats().Families
+	if len(families) != maxStatsFamilies+1 {
+		t.Errorf("expected %d families plus overflow, got %d", maxStatsFamilies, len(families))
+	}
+	if s := families[FamilyOverflow]; s.Misses != 50 {
+		t.Errorf("expected 50 overflow misses, got %+v", s)
+	}
+}
+
+func TestFamilyStats_Clear(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, FamilyStats: true})
+	cache.Get("user:1")
+	cache.Clear()
+	if families := cache.Stats().Families; len(families) != 0 {
+		t.Errorf("Clear must reset family counters, got %v", families)
+	}
+	cache.Get("user:1")
+	if s := cache.Stats().Families["user"]; s.Misses != 1 {
+		t.Errorf("families must be tracked again after Clear, got %+v", s)
+	}
+}
+
+func TestFamilyStats_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, FamilyStats: true})
+	cache.Set("a:hit", 1)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				cache.Get("a:hit")
+				cache.Get(fmt.Sprintf("b:%d", i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	if a, b := stats.Families["a"], stats.Families["b"]; a.Hits != 8000 || b.Misses != 8000 {
+		t.Errorf("expected 8000 a hits and 8000 b misses, got %+v %+v", a, b)
+	}
+	if stats.Hits+stats.Misses != 16000 {
+		t.Errorf("family and global counters must agree: %d lookups", stats.Hits+stats.Misses)
+	}
+}
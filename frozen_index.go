@@ -0,0 +1,231 @@
+// frozen_index.go: read-mostly lookup index (minimal perfect hash over live keys)
+//
+// With Config.FrozenIndexInterval, a perfect-hash index over the live keys
+// is built once writes settle, so Get hits skip probing.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// frozenBucketLoad is the average number of keys per displacement bucket.
+	frozenBucketLoad = 4
+
+	// frozenMaxDisplacement bounds the seed search per bucket; a build that
+	// exceeds it is abandoned and the cache keeps probing.
+	frozenMaxDisplacement = 1 << 16
+)
+
+// frozenIndex is an immutable perfect-hash index from key hash to table slot.
+type frozenIndex struct {
+	bucketMask uint64
+	posMask    uint64
+	seeds      []uint32 // displacement per bucket
+	hashes     []uint64 // key hash at each position (0 = unused)
+	slots      []uint32 // table slot at each position
+	keys       int      // number of indexed keys
+}
+
+// frozenMix scrambles a key hash with a displacement seed (splitmix64 finalizer).
+func frozenMix(h uint64, seed uint32) uint64 {
+	x := h ^ (uint64(seed) * 0x9e3779b97f4a7c15)
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// lookup returns the table slot indexed for keyHash.
+func (fi *frozenIndex) lookup(keyHash uint64) (uint32, bool) {
+	seed := fi.seeds[keyHash&fi.bucketMask]
+	pos := frozenMix(keyHash, seed) & fi.posMask
+	if fi.hashes[pos] != keyHash {
+		return 0, false
+	}
+	return fi.slots[pos], true
+}
+
+// frozenKey is a live key hash and its table slot, collected for a build.
+type frozenKey struct {
+	hash uint64
+	slot uint32
+}
+
+// buildFrozenIndex builds an index over keys. Returns nil if keys is empty or
+// no displacement could be found for some bucket.
+func buildFrozenIndex(keys []frozenKey) *frozenIndex {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	numBuckets := nextPowerOf2(len(keys)/frozenBucketLoad + 1)
+	numPos := nextPowerOf2(len(keys) * 2)
+	fi := &frozenIndex{
+		bucketMask: uint64(numBuckets - 1), // #nosec G115 -- power of 2, positive
+		posMask:    uint64(numPos - 1),     // #nosec G115 -- power of 2, positive
+		seeds:      make([]uint32, numBuckets),
+		hashes:     make([]uint64, numPos),
+		slots:      make([]uint32, numPos),
+		keys:       len(keys),
+	}
+
+	buckets := make([][]frozenKey, numBuckets)
+	for _, k := range keys {
+		b := k.hash & fi.bucketMask
+		buckets[b] = append(buckets[b], k)
+	}
+
+	// Place the largest buckets first, while the position table is emptiest
+	order := make([]int, 0, numBuckets)
+	for b := range buckets {
+		if len(buckets[b]) > 0 {
+			order = append(order, b)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool { return len(buckets[order[i]]) > len(buckets[order[j]]) })
+
+	positions := make([]uint64, 0, 8)
+	for _, b := range order {
+		placed := false
+	search:
+		for seed := uint32(0); seed < frozenMaxDisplacement; seed++ {
+			positions = positions[:0]
+			for _, k := range buckets[b] {
+				pos := frozenMix(k.hash, seed) & fi.posMask
+				if fi.hashes[pos] != 0 {
+					continue search
+				}
+				for _, p := range positions {
+					if p == pos {
+						continue search
+					}
+				}
+				positions = append(positions, pos)
+			}
+			for i, k := range buckets[b] {
+				fi.hashes[positions[i]] = k.hash
+				fi.slots[positions[i]] = k.slot
+			}
+			fi.seeds[b] = seed
+			placed = true
+			break
+		}
+		if !placed {
+			return nil
+		}
+	}
+	return fi
+}
+
+// rebuildFrozenIndex snapshots live slots and publishes a fresh index.
+// Hash 0 and duplicate hashes (distinct keys colliding on 64 bits) are left
+// out of the index; they are still found by probing.
+func (c *wtinyLFUCache) rebuildFrozenIndex() {
+	t := c.table.Load()
+	if t == nil {
+		return // Closed
+	}
+	g := t.gen.Load()
+	keys := make([]frozenKey, 0, t.size())
+	seen := make(map[uint64]int, cap(keys))
+	for i := range t.entries {
+		entry := &t.entries[i]
+		if atomic.LoadInt32(&entry.valid) != g.live {
+			continue
+		}
+		h := atomic.LoadUint64(&entry.keyHash)
+		if h == 0 {
+			continue
+		}
+		if at, dup := seen[h]; dup {
+			keys[at].hash = 0 // Mark ambiguous; dropped below
+			continue
+		}
+		seen[h] = len(keys)
+		keys = append(keys, frozenKey{hash: h, slot: uint32(i)}) // #nosec G115 -- table size fits uint32 (tableMask is uint32)
+	}
+
+	filtered := keys[:0]
+	for _, k := range keys {
+		if k.hash != 0 {
+			filtered = append(filtered, k)
+		}
+	}
+
+	c.frozen.Store(buildFrozenIndex(filtered))
+}
+
+// frozenGet resolves key through the frozen index of t. It only answers
+// fresh hits; misses, stale index positions and expired entries return false
+// so the caller falls back to the probing path (which handles expiration
+// accounting).
+func (c *wtinyLFUCache) frozenGet(t *cacheTable, fi *frozenIndex, key string, keyHash uint64, ttlNow int64) (interface{}, bool) {
+	g := t.gen.Load()
+	slot, ok := fi.lookup(keyHash)
+	if !ok || slot > t.mask {
+		return nil, false
+	}
+	entry := &t.entries[slot]
+
+	if atomic.LoadInt32(&entry.valid) != g.live || atomic.LoadUint64(&entry.keyHash) != keyHash {
+		return nil, false
+	}
+	if entry.loadKey() != key || c.isExpired(entry, ttlNow) {
+		return nil, false
+	}
+	holder, ok := entry.value.Load().(*valueHolder)
+	if !ok || holder == nil || atomic.LoadInt32(&entry.valid) != g.live {
+		return nil, false
+	}
+	c.recordAccess(t, entry, ttlNow)
+	return c.decoded(holder.load())
+}
+
+// writeActivity returns a counter that changes whenever the key set may have
+// changed (Clear resets the counters, which also registers as a change).
+func (c *wtinyLFUCache) writeActivity() int64 {
+	return atomic.LoadInt64(&c.sets) + atomic.LoadInt64(&c.deletes) +
+		atomic.LoadInt64(&c.evictions) + atomic.LoadInt64(&c.expirations)
+}
+
+// runFrozenIndexer rebuilds the frozen index once a burst of writes settles:
+// after at least one write since the last build, followed by a full interval
+// without writes.
+func (c *wtinyLFUCache) runFrozenIndexer(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	built := int64(-1) // Activity at last build (-1 = never built)
+	last := c.writeActivity()
+	for {
+		select {
+		case <-c.stopIndexer:
+			return
+		case <-ticker.C:
+			current := c.writeActivity()
+			if current == last && current != built {
+				c.rebuildFrozenIndex()
+				built = current
+			}
+			last = current
+		}
+	}
+}
+
+// frozenIndexKeys returns the number of keys covered by the published index.
+func (c *wtinyLFUCache) frozenIndexKeys() int {
+	if fi := c.frozen.Load(); fi != nil {
+		return fi.keys
+	}
+	return 0
+}
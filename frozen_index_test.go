@@ -0,0 +1,160 @@
+// frozen_index_test.go: tests for the read-mostly frozen lookup index
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBuildFrozenIndex_PerfectHash(t *testing.T) {
+	for _, n := range []int{1, 7, 100, 10_000} {
+		keys := make([]frozenKey, n)
+		for i := range keys {
+			keys[i] = frozenKey{hash: stringHash("key" + strconv.Itoa(i)), slot: uint32(i)} // #nosec G115 -- test values
+		}
+
+		fi := buildFrozenIndex(keys)
+		if fi == nil {
+			t.Fatalf("n=%d: build failed", n)
+		}
+		for _, k := range keys {
+			slot, ok := fi.lookup(k.hash)
+			if !ok || slot != k.slot {
+				t.Fatalf("n=%d: lookup(%x) = %d, %v; want %d", n, k.hash, slot, ok, k.slot)
+			}
+		}
+		if _, ok := fi.lookup(stringHash("absent")); ok {
+			t.Errorf("n=%d: absent key resolved", n)
+		}
+	}
+
+	if buildFrozenIndex(nil) != nil {
+		t.Error("empty build should return nil")
+	}
+}
+
+func TestFrozenIndex_GetThroughIndex(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	for i := 0; i < 500; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	cache.rebuildFrozenIndex()
+	if n := cache.frozenIndexKeys(); n != 500 {
+		t.Fatalf("expected 500 indexed keys, got %d", n)
+	}
+
+	fi := cache.frozen.Load()
+	for i := 0; i < 500; i++ {
+		key := "key" + strconv.Itoa(i)
+		v, ok := cache.frozenGet(cache.table.Load(), fi, key, stringHash(key), 0)
+		if !ok || v != i {
+			t.Fatalf("frozenGet(%s) = %v, %v", key, v, ok)
+		}
+	}
+
+	// Stale index: deleted, re-added and updated keys stay correct
+	cache.Delete("key1")
+	if _, ok := cache.Get("key1"); ok {
+		t.Error("deleted key served from stale index")
+	}
+	cache.Set("key1", "moved")
+	cache.Set("key2", "updated")
+	if v, _ := cache.Get("key1"); v != "moved" {
+		t.Errorf("re-added key: got %v", v)
+	}
+	if v, _ := cache.Get("key2"); v != "updated" {
+		t.Errorf("updated key: got %v", v)
+	}
+	if stats := cache.Stats(); stats.Misses != 1 || stats.Hits != 2 {
+		t.Errorf("stats must be maintained on the index path: %+v", stats)
+	}
+
+	cache.Clear()
+	if cache.frozenIndexKeys() != 0 {
+		t.Error("Clear must drop the frozen index")
+	}
+}
+
+func TestFrozenIndex_ExpiredEntriesFallBack(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+	cache.rebuildFrozenIndex()
+
+	mockTime.Advance(2 * time.Second)
+	if _, ok := cache.Get("k"); ok {
+		t.Error("expired entry served from frozen index")
+	}
+	if stats := cache.Stats(); stats.Expirations != 1 {
+		t.Errorf("expiration must be accounted by the fallback path: %+v", stats)
+	}
+}
+
+func TestFrozenIndex_BackgroundRebuildAfterBurst(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, FrozenIndexInterval: 5 * time.Millisecond}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	for i := 0; i < 200; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cache.frozenIndexKeys() != 200 {
+		if time.Now().After(deadline) {
+			t.Fatalf("index not rebuilt after write burst (keys=%d)", cache.frozenIndexKeys())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A second burst triggers another rebuild
+	for i := 200; i < 300; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for cache.frozenIndexKeys() != 300 {
+		if time.Now().After(deadline) {
+			t.Fatalf("index not rebuilt after second burst (keys=%d)", cache.frozenIndexKeys())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFrozenIndex_DisabledByDefault(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	if cache.stopIndexer != nil {
+		t.Error("indexer goroutine must not start without FrozenIndexInterval")
+	}
+	_ = cache.Close()
+	_ = cache.Close() // Idempotent
+}
+
+func BenchmarkGet_FrozenIndex(b *testing.B) {
+	for _, frozen := range []bool{false, true} {
+		name := "Probing"
+		if frozen {
+			name = "Frozen"
+		}
+		b.Run(name, func(b *testing.B) {
+			cache := NewCache(Config{MaxSize: 10_000}).(*wtinyLFUCache)
+			keys := make([]string, 8_000)
+			for i := range keys {
+				keys[i] = "key" + strconv.Itoa(i)
+				cache.Set(keys[i], i)
+			}
+			if frozen {
+				cache.rebuildFrozenIndex()
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// generation.go: slot generations, for a Clear that does not visit the table
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+const (
+	// entryStateBits is the number of low bits of the state word of a slot
+	// holding its state; the bits above hold the generation of a valid or
+	// pending entry.
+	entryStateBits = 2
+	entryStateMask = 1<<entryStateBits - 1
+
+	// generationLimit is the number of generations before the ids wrap.
+	generationLimit = 1 << (32 - 1 - entryStateBits)
+)
+
+// generation is a generation of the entries of a table, and counts its live
+// entries. Clear starts a new generation: the valid entries of the previous
+// ones are then dead, are skipped by reads and reclaimed by writers like
+// deleted entries, so Clear does not visit the slots.
+//
+// A valid entry is counted in the generation of its state word. Writers move
+// entries between states, and update the counters, only in the generation
+// they loaded the entry from: a write racing with Clear lands in the cleared
+// generation and is dropped with it, counters included.
+type generation struct {
+	id     int32
+	live   int32 // State word of the valid entries of the generation
+	size   int64 // Valid entries (atomic)
+	weight int64 // Total weight of the valid entries (atomic, see weight.go)
+}
+
+// newGeneration returns an empty generation numbered id.
+func newGeneration(id int32) *generation {
+	return &generation{id: id, live: id<<entryStateBits | entryValid}
+}
+
+// isFree reports whether a slot of t in state word can be claimed by a
+// writer: empty, deleted, or valid in a cleared generation. The current
+// generation is loaded afresh, so a caller holding an older one never takes
+// an entry written since for a free slot.
+func (t *cacheTable) isFree(word int32) bool {
+	switch stateOf(word) {
+	case entryEmpty, entryDeleted:
+		return true
+	case entryValid:
+		return word != t.gen.Load().live
+	}
+	return false
+}
+
+// stateOf returns the state of a slot state word, without its generation.
+func stateOf(word int32) int32 {
+	return word & entryStateMask
+}
+
+// pendingOf returns the state word of a valid entry in state word once
+// acquired by a writer: entryPending, in the same generation.
+func pendingOf(word int32) int32 {
+	return word&^entryStateMask | entryPending
+}
+
+// release moves an entry acquired by the caller back to entryValid, in the
+// generation it was acquired from.
+func (e *entry) release() {
+	atomic.StoreInt32(&e.valid, atomic.LoadInt32(&e.valid)&^entryStateMask|entryValid)
+}
+
+// owner returns the generation of t an entry acquired by the caller counts
+// in, or nil if that generation has been cleared since.
+func (t *cacheTable) owner(e *entry) *generation {
+	g := t.gen.Load()
+	if atomic.LoadInt32(&e.valid) != pendingOf(g.live) {
+		return nil
+	}
+	return g
+}
+
+// isLive reports whether e holds a live entry: valid in the current
+// generation of the table (false once closed).
+func (c *wtinyLFUCache) isLive(e *entry) bool {
+	t := c.table.Load()
+	return t != nil && atomic.LoadInt32(&e.valid) == t.gen.Load().live
+}
+
+// size returns the number of live entries of t.
+func (t *cacheTable) size() int64 {
+	return atomic.LoadInt64(&t.gen.Load().size)
+}
+
+// entryCount returns the number of live entries (0 once closed).
+func (c *wtinyLFUCache) entryCount() int64 {
+	t := c.table.Load()
+	if t == nil {
+		return 0 // Closed
+	}
+	return t.size()
+}
+
+// totalWeight returns the total weight of the live entries (0 once closed).
+func (c *wtinyLFUCache) totalWeight() int64 {
+	t := c.table.Load()
+	if t == nil {
+		return 0 // Closed
+	}
+	return atomic.LoadInt64(&t.gen.Load().weight)
+}
+
+// clearsEachEntry reports whether Clear must visit every slot of t: to report
+// the dropped entries to Config.OnEvict, or to forget the departures recorded
+// for Config.TrackMissReasons.
+func (c *wtinyLFUCache) clearsEachEntry(t *cacheTable) bool {
+	return c.onEvict != nil || t.departures != nil
+}
+
+// nextGeneration starts the next generation of t, dropping every entry of the
+// current one. Before the ids wrap, the slots are emptied one by one: a slot
+// left from the generation with the next id would otherwise come back.
+func (c *wtinyLFUCache) nextGeneration(t *cacheTable) {
+	id := t.gen.Load().id + 1
+	if id == generationLimit {
+		c.clearEntries(t)
+		id = 0
+	}
+	t.gen.Store(newGeneration(id))
+}
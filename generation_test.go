@@ -0,0 +1,185 @@
+// generation_test.go: tests for slot generations and the Clear they enable
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// staleSlots counts the slots still valid in a cleared generation.
+func staleSlots(c *wtinyLFUCache) int {
+	n := 0
+	for i := range c.table.Load().entries {
+		e := &c.table.Load().entries[i]
+		if stateOf(atomic.LoadInt32(&e.valid)) == entryValid && !c.isLive(e) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestClear_DoesNotVisitSlots(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	cache.Clear()
+
+	// The slots keep their entries, which belong to the cleared generation
+	if n := staleSlots(cache); n != 50 {
+		t.Fatalf("stale slots = %d, want 50 (Clear must not rewrite the slots)", n)
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("Len = %d after Clear", n)
+	}
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if _, found := cache.Get(key); found || cache.Has(key) {
+			t.Fatalf("%s survived Clear", key)
+		}
+	}
+	if keys := cache.Keys(); len(keys) != 0 {
+		t.Errorf("Keys = %v after Clear", keys)
+	}
+	if cache.Delete("k0") {
+		t.Error("Delete found a cleared entry")
+	}
+}
+
+func TestClear_ReclaimsStaleSlots(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("old%d", i), i)
+	}
+	cache.Clear()
+
+	// Stale slots are taken like deleted ones: the cache fills up again
+	// without evicting, and the cleared entries are overwritten
+	for i := 0; i < 100; i++ {
+		if !cache.Set(fmt.Sprintf("new%d", i), i) {
+			t.Fatalf("Set new%d failed after Clear", i)
+		}
+	}
+	if n := cache.Len(); n != 100 {
+		t.Errorf("Len = %d, want 100", n)
+	}
+	if n := cache.Stats().Evictions; n != 0 {
+		t.Errorf("Evictions = %d: stale slots must be reused, not evicted", n)
+	}
+	if n := staleSlots(cache); n >= 100 {
+		t.Errorf("stale slots = %d: none was reclaimed", n)
+	}
+	live, _ := liveSlots(cache)
+	if live != 100 {
+		t.Errorf("live slots = %d, want 100", live)
+	}
+	if _, found := cache.Get("old0"); found {
+		t.Error("a cleared entry came back")
+	}
+}
+
+func TestClear_ReleasesWeight(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 1000, Weigher: byteWeigher}).(*wtinyLFUCache)
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), make([]byte, 90))
+	}
+	cache.Clear()
+	if w := cache.Stats().Weight; w != 0 {
+		t.Fatalf("Weight = %d after Clear", w)
+	}
+
+	// The weight left in a reclaimed slot must not be charged again
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("n%d", i), make([]byte, 10))
+	}
+	if w, want := cache.Stats().Weight, liveWeight(cache); w != want || w != 100 {
+		t.Errorf("Weight = %d, live entries weigh %d (want 100)", w, want)
+	}
+	if n := cache.Stats().Evictions; n != 0 {
+		t.Errorf("Evictions = %d: the cleared weight was still counted", n)
+	}
+}
+
+func TestClear_VisitsSlotsForOnEvict(t *testing.T) {
+	var evicted int64
+	cache := NewCache(Config{
+		MaxSize: 100,
+		OnEvict: func(string, interface{}, EvictReason) { atomic.AddInt64(&evicted, 1) },
+	}).(*wtinyLFUCache)
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	cache.Clear()
+
+	if n := atomic.LoadInt64(&evicted); n != 20 {
+		t.Errorf("OnEvict calls = %d, want 20", n)
+	}
+	if id := cache.table.Load().gen.Load().id; id != 0 {
+		t.Errorf("generation = %d: with OnEvict, Clear must empty the slots instead", id)
+	}
+	if n := staleSlots(cache); n != 0 {
+		t.Errorf("stale slots = %d", n)
+	}
+}
+
+func TestClear_GenerationWrap(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	table := cache.table.Load()
+	table.gen.Store(newGeneration(generationLimit - 1))
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if n := cache.Len(); n != 20 {
+		t.Fatalf("Len = %d, want 20", n)
+	}
+
+	cache.Clear()
+
+	g := table.gen.Load()
+	if g.id != 0 || g.live != entryValid {
+		t.Fatalf("generation after wrap = %d (state %d), want 0", g.id, g.live)
+	}
+	// Slots of the last generation are emptied, so none can come back
+	for i := range table.entries {
+		if state := atomic.LoadInt32(&table.entries[i].valid); state != entryEmpty {
+			t.Fatalf("slot %d in state %d after the wrap", i, state)
+		}
+	}
+	if _, found := cache.Get("k0"); found || cache.Len() != 0 {
+		t.Error("entries survived the wrap")
+	}
+	cache.Set("k0", 1)
+	if v, found := cache.Get("k0"); !found || v != 1 {
+		t.Errorf("Get after wrap = %v, %v", v, found)
+	}
+}
+
+func TestClear_RacingAcquiredEntry(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 1000, Weigher: byteWeigher}).(*wtinyLFUCache)
+	cache.Set("k", make([]byte, 10))
+
+	// A conditional update holds the entry while Clear runs: releasing it
+	// must not bring it back, nor charge the new generation
+	table := cache.table.Load()
+	entry := cache.acquireEntry(table, "k", cache.hashKey("k"), cache.ttlClock(cache.timeProvider.Now()))
+	if entry == nil {
+		t.Fatal("acquireEntry: entry not found")
+	}
+	cache.Clear()
+	cache.replaceValue(table, entry, "k", make([]byte, 50), 50, 0)
+
+	if _, found := cache.Get("k"); found {
+		t.Error("an entry held across Clear survived it")
+	}
+	if n, w := cache.Len(), cache.Stats().Weight; n != 0 || w != 0 {
+		t.Errorf("Len = %d, Weight = %d after Clear", n, w)
+	}
+}
@@ -0,0 +1,124 @@
+// hit_ratio_alert.go: built-in hit ratio watchdog (Config.HitRatioAlert)
+//
+// Calls Callback when the hit ratio of the last Window falls below
+// Threshold, and again when it recovers.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "time"
+
+// DefaultHitRatioAlertWindow is the default Window of Config.HitRatioAlert.
+const DefaultHitRatioAlertWindow = 5 * time.Minute
+
+// DefaultHitRatioAlertMinLookups is the default MinLookups of
+// Config.HitRatioAlert.
+const DefaultHitRatioAlertMinLookups = 100
+
+// HitRatioAlert configures the hit ratio watchdog (see Config.HitRatioAlert).
+//
+//	cache := balios.NewCache(balios.Config{
+//	    MaxSize: 10_000,
+//	    HitRatioAlert: balios.HitRatioAlert{
+//	        Threshold: 0.7,
+//	        Window:    5 * time.Minute,
+//	        Callback: func(e balios.HitRatioEvent) {
+//	            if !e.Recovered {
+//	                pager.Trigger("cache hit ratio %.0f%%", e.HitRatio*100)
+//	            }
+//	        },
+//	    },
+//	})
+type HitRatioAlert struct {
+	// Threshold is the lowest acceptable hit ratio, as a fraction in (0, 1].
+	// The watchdog is disabled if it is out of range.
+	Threshold float64
+
+	// Window is the time span the hit ratio is computed over. StatsWindow is
+	// raised to Window if shorter. Default: DefaultHitRatioAlertWindow.
+	Window time.Duration
+
+	// MinLookups is the number of lookups the window must hold for its hit
+	// ratio to be evaluated. Default: DefaultHitRatioAlertMinLookups.
+	MinLookups uint64
+
+	// Callback is called from the window sampler goroutine when the hit
+	// ratio falls below Threshold and when it recovers. It should return
+	// quickly; a panic is recovered and logged through Logger.
+	// Default: nil (disabled).
+	Callback func(event HitRatioEvent)
+}
+
+// enabled reports whether the watchdog is configured.
+func (a HitRatioAlert) enabled() bool {
+	return a.Callback != nil && a.Threshold > 0
+}
+
+// HitRatioEvent is passed to HitRatioAlert.Callback.
+type HitRatioEvent struct {
+	// Cache is the name of the cache (Config.Name, possibly empty).
+	Cache string
+
+	// HitRatio is the hit ratio over Stats.Window, as a fraction (0-1).
+	HitRatio float64
+
+	// Threshold is HitRatioAlert.Threshold.
+	Threshold float64
+
+	// Stats is the activity the hit ratio was computed from.
+	Stats WindowStats
+
+	// Recovered is false when the hit ratio fell below Threshold and true
+	// when it is back at or above it.
+	Recovered bool
+}
+
+// hitRatioWatchdog is the state of Config.HitRatioAlert. It is only used by
+// the window sampler goroutine.
+type hitRatioWatchdog struct {
+	alert  HitRatioAlert
+	cache  string // Config.Name
+	firing bool   // an alert was sent and the ratio has not recovered yet
+}
+
+// checkHitRatio evaluates the hit ratio of the alert window and calls the
+// callback when it crosses the threshold. The caller checks
+// c.hitRatioAlert != nil.
+func (c *wtinyLFUCache) checkHitRatio() {
+	w := c.hitRatioAlert
+	stats := c.WindowStats(w.alert.Window)
+	lookups := stats.Hits + stats.Misses
+	if stats.Window < w.alert.Window || lookups == 0 || lookups < w.alert.MinLookups {
+		return
+	}
+	ratio := float64(stats.Hits) / float64(lookups)
+	below := ratio < w.alert.Threshold
+	if below == w.firing {
+		return
+	}
+	w.firing = below
+	c.notifyHitRatio(HitRatioEvent{
+		Cache:     w.cache,
+		HitRatio:  ratio,
+		Threshold: w.alert.Threshold,
+		Stats:     stats,
+		Recovered: !below,
+	})
+}
+
+// notifyHitRatio calls HitRatioAlert.Callback, recovering from panics.
+func (c *wtinyLFUCache) notifyHitRatio(event HitRatioEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.logger.Error("balios: HitRatioAlert callback panicked",
+				"hit_ratio", event.HitRatio,
+				"recovered", event.Recovered,
+				"panic", r,
+			)
+		}
+	}()
+	c.hitRatioAlert.alert.Callback(event)
+}
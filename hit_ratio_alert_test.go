@@ -0,0 +1,172 @@
+// hit_ratio_alert_test.go: tests for Config.HitRatioAlert
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"testing"
+	"time"
+)
+
+// newAlertCache returns a cache with a 10 minute HitRatioAlert at 0.7 on a
+// mock clock, and the events it sends. Samples are taken and evaluated by
+// calling tick, as the background sampler runs on the real clock.
+func newAlertCache(t *testing.T) (cache *wtinyLFUCache, tick func(), events *[]HitRatioEvent) {
+	t.Helper()
+	clock := &MockTimeProvider{currentTime: int64(time.Hour)}
+	events = &[]HitRatioEvent{}
+	cache = NewCache(Config{
+		MaxSize:      100,
+		Name:         "users",
+		TimeProvider: clock,
+		HitRatioAlert: HitRatioAlert{
+			Threshold:  0.7,
+			Window:     10 * time.Minute,
+			MinLookups: 10,
+			Callback:   func(e HitRatioEvent) { *events = append(*events, e) },
+		},
+	}).(*wtinyLFUCache)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	tick = func() {
+		clock.Advance(time.Minute)
+		cache.sampleWindow()
+		cache.checkHitRatio()
+	}
+	return cache, tick, events
+}
+
+// lookups performs hits hits and misses misses.
+func lookups(cache Cache, hits, misses int) {
+	cache.Set("hit", 1)
+	for i := 0; i < hits; i++ {
+		cache.Get("hit")
+	}
+	for i := 0; i < misses; i++ {
+		cache.Get("missing")
+	}
+}
+
+func TestHitRatioAlert_FiresAndRecovers(t *testing.T) {
+	cache, tick, events := newAlertCache(t)
+
+	// Ten minutes at 90%, then ten at 50%
+	for i := 0; i < 10; i++ {
+		lookups(cache, 9, 1)
+		tick()
+	}
+	if len(*events) != 0 {
+		t.Fatalf("events = %+v at 90%%, want none", *events)
+	}
+	for i := 0; i < 10; i++ {
+		lookups(cache, 5, 5)
+		tick()
+	}
+	if len(*events) != 1 {
+		t.Fatalf("got %d events after the ratio degraded, want exactly 1", len(*events))
+	}
+	alert := (*events)[0]
+	if alert.Recovered || alert.Cache != "users" || alert.Threshold != 0.7 || alert.HitRatio >= 0.7 {
+		t.Fatalf("alert = %+v, want a 'users' alert below 0.7", alert)
+	}
+	if alert.Stats.Window < 10*time.Minute || alert.Stats.Hits+alert.Stats.Misses == 0 {
+		t.Fatalf("alert.Stats = %+v, want the activity of the 10 minute window", alert.Stats)
+	}
+
+	// Back to 100%
+	for i := 0; i < 10; i++ {
+		lookups(cache, 10, 0)
+		tick()
+	}
+	if len(*events) != 2 || !(*events)[1].Recovered || (*events)[1].HitRatio < 0.7 {
+		t.Fatalf("events = %+v, want a recovery after the alert", *events)
+	}
+}
+
+func TestHitRatioAlert_WaitsForFullWindow(t *testing.T) {
+	cache, tick, events := newAlertCache(t)
+
+	// A cold cache misses everything: no alert before 10 minutes of history
+	for i := 0; i < 9; i++ {
+		lookups(cache, 0, 20)
+		tick()
+	}
+	if len(*events) != 0 {
+		t.Fatalf("events = %+v during warm-up, want none", *events)
+	}
+	tick()
+	if len(*events) != 1 {
+		t.Fatalf("got %d events once the window is full, want 1", len(*events))
+	}
+}
+
+func TestHitRatioAlert_MinLookups(t *testing.T) {
+	cache, tick, events := newAlertCache(t)
+
+	// 9 lookups in the window, all misses: below MinLookups
+	for i := 0; i < 20; i++ {
+		if i%2 == 0 {
+			cache.Get("missing")
+		}
+		tick()
+	}
+	if len(*events) != 0 {
+		t.Fatalf("events = %+v on an idle cache, want none", *events)
+	}
+}
+
+func TestHitRatioAlert_CallbackPanic(t *testing.T) {
+	clock := &MockTimeProvider{currentTime: int64(time.Hour)}
+	calls := 0
+	cache := NewCache(Config{
+		MaxSize:      100,
+		TimeProvider: clock,
+		HitRatioAlert: HitRatioAlert{
+			Threshold: 0.5,
+			Window:    10 * time.Minute,
+			Callback: func(HitRatioEvent) {
+				calls++
+				panic("pager down")
+			},
+		},
+	}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	for i := 0; i < 20; i++ {
+		lookups(cache, 0, 10)
+		clock.Advance(time.Minute)
+		cache.sampleWindow()
+		cache.checkHitRatio()
+	}
+	if calls != 1 {
+		t.Fatalf("callback called %d times, want 1", calls)
+	}
+}
+
+func TestHitRatioAlert_Config(t *testing.T) {
+	config := Config{HitRatioAlert: HitRatioAlert{Threshold: 0.8, Callback: func(HitRatioEvent) {}}}
+	_ = config.Validate()
+	if config.HitRatioAlert.Window != DefaultHitRatioAlertWindow ||
+		config.HitRatioAlert.MinLookups != DefaultHitRatioAlertMinLookups ||
+		config.StatsWindow != DefaultHitRatioAlertWindow {
+		t.Fatalf("defaults not applied: %+v, StatsWindow %v", config.HitRatioAlert, config.StatsWindow)
+	}
+
+	longer := Config{StatsWindow: time.Hour, HitRatioAlert: HitRatioAlert{Threshold: 0.8, Callback: func(HitRatioEvent) {}}}
+	_ = longer.Validate()
+	if longer.StatsWindow != time.Hour {
+		t.Fatalf("StatsWindow = %v, want the longer configured window kept", longer.StatsWindow)
+	}
+
+	for _, threshold := range []float64{0, -0.5, 70} {
+		cache := NewCache(Config{HitRatioAlert: HitRatioAlert{Threshold: threshold, Callback: func(HitRatioEvent) {}}})
+		core := cache.(*wtinyLFUCache)
+		if core.hitRatioAlert != nil || core.window != nil {
+			t.Errorf("Threshold %v: the watchdog must be disabled", threshold)
+		}
+		_ = cache.Close()
+	}
+}
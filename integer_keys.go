@@ -0,0 +1,82 @@
+// integer_keys.go: zero-allocation reads for integer GenericCache keys
+//
+// Integer keys are formatted on the caller's stack and looked up under the
+// hash of their value, so Get and Has do not allocate.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"strconv"
+	"unsafe"
+)
+
+// maxIntegerKeyLen is the length of the longest decimal integer key
+// ("-9223372036854775808", or 18446744073709551615 unsigned).
+const maxIntegerKeyLen = 20
+
+// isIntegerKey reports whether K is an integer type.
+func isIntegerKey[K comparable]() bool {
+	var zero K
+	switch any(zero).(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// appendIntegerKey appends the string form of an integer key (as produced by
+// keyToString) to dst.
+func appendIntegerKey[K comparable](dst []byte, key K) []byte {
+	switch v := any(key).(type) {
+	case int, int8, int16, int32, int64:
+		return strconv.AppendInt(dst, int64(integerKeyBits(key)), 10) // #nosec G115 -- sign-extended bits
+	case uint, uint8, uint16, uint32:
+		return strconv.AppendUint(dst, integerKeyBits(key), 10)
+	case uint64:
+		return strconv.AppendUint(dst, v, 10)
+	default:
+		return dst
+	}
+}
+
+// getTransient implements getHashed for a key that is only valid during the
+// call. The caller must check that FamilyStats, the TopKeys tracker and key
+// sampling, which may retain the key, are disabled.
+func (c *wtinyLFUCache) getTransient(key string, keyHash uint64) (value interface{}, found bool) {
+	if c.isClosed() {
+		return nil, false
+	}
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	value, found, _ = c.read(key, keyHash, now)
+	if !found {
+		c.classifyMiss(keyHash)
+	}
+	c.recordGetCounters(context.Background(), start, found)
+	return value, found
+}
+
+// getInteger implements Get for an integer key without allocating, unless a
+// read hook retains the key.
+func (c *GenericCache[K, V]) getInteger(key K) (interface{}, bool) {
+	if c.core.families != nil || c.core.topKeys != nil || c.core.keySampleEvery > 0 {
+		val, found, _ := c.core.getHashed(context.Background(), keyToString(key), c.hash(key))
+		return val, found
+	}
+	var buf [maxIntegerKeyLen]byte
+	b := appendIntegerKey(buf[:0], key)
+	return c.core.getTransient(unsafe.String(unsafe.SliceData(b), len(b)), c.hash(key)) // #nosec G103 -- b is not modified while the string is in use
+}
+
+// hasInteger implements Has for an integer key without allocating.
+func (c *GenericCache[K, V]) hasInteger(key K) bool {
+	var buf [maxIntegerKeyLen]byte
+	b := appendIntegerKey(buf[:0], key)
+	return c.core.hasHashed(unsafe.String(unsafe.SliceData(b), len(b)), c.hash(key)) // #nosec G103 -- b is not modified while the string is in use
+}
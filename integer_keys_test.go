@@ -0,0 +1,91 @@
+// integer_keys_test.go: tests for zero-allocation integer-key reads
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestIntegerKeys_GetAndHasDoNotAllocate(t *testing.T) {
+	cache := NewGenericCache[int64, int](Config{MaxSize: 1000})
+	for i := int64(0); i < 500; i++ {
+		cache.Set(i*1_000_003, int(i))
+	}
+
+	hit := testing.AllocsPerRun(1000, func() { cache.Get(250 * 1_000_003) })
+	miss := testing.AllocsPerRun(1000, func() { cache.Get(-123_456_789) })
+	has := testing.AllocsPerRun(1000, func() { cache.Has(499 * 1_000_003) })
+	if hit != 0 || miss != 0 || has != 0 {
+		t.Fatalf("allocs/op: Get hit %.1f, Get miss %.1f, Has %.1f; want 0", hit, miss, has)
+	}
+
+	stats := cache.Stats() // AllocsPerRun makes one warm-up call
+	if stats.Hits != 1001 || stats.Misses != 1001 {
+		t.Fatalf("hits/misses = %d/%d, want 1001/1001", stats.Hits, stats.Misses)
+	}
+}
+
+func TestIntegerKeys_ExtremeValues(t *testing.T) {
+	signed := NewGenericCache[int64, string](Config{MaxSize: 100})
+	for _, k := range []int64{math.MinInt64, -1, 0, math.MaxInt64} {
+		signed.Set(k, strconv.FormatInt(k, 10))
+	}
+	for _, k := range []int64{math.MinInt64, -1, 0, math.MaxInt64} {
+		if v, ok := signed.Get(k); !ok || v != strconv.FormatInt(k, 10) {
+			t.Fatalf("Get(%d) = %q, %v", k, v, ok)
+		}
+	}
+
+	unsigned := NewGenericCache[uint64, string](Config{MaxSize: 100})
+	unsigned.Set(math.MaxUint64, "max")
+	if v, ok := unsigned.Get(math.MaxUint64); !ok || v != "max" {
+		t.Fatalf("Get(MaxUint64) = %q, %v", v, ok)
+	}
+	if len(strconv.FormatUint(math.MaxUint64, 10)) > maxIntegerKeyLen ||
+		len(strconv.FormatInt(math.MinInt64, 10)) > maxIntegerKeyLen {
+		t.Fatal("maxIntegerKeyLen is too short")
+	}
+}
+
+func TestIntegerKeys_FamilyStatsKeepHeapKeys(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{
+		MaxSize:     100,
+		FamilyStats: true,
+		KeyFamily:   func(key string) string { return key[:1] },
+	})
+	cache.Set(123, "a")
+	cache.Get(123)
+	cache.Get(456)
+
+	families := cache.Stats().Families
+	if families["1"].Hits != 1 || families["4"].Misses != 1 {
+		t.Fatalf("family counters = %+v", families)
+	}
+}
+
+func TestIntegerKeys_ClosedCache(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 10})
+	cache.Set(1000, "a")
+	_ = cache.Close()
+	if _, ok := cache.Get(1000); ok || cache.Has(1000) {
+		t.Fatal("closed cache returned a value")
+	}
+}
+
+func BenchmarkIntegerKeys_GenericGetInt64(b *testing.B) {
+	cache := NewGenericCache[int64, int](Config{MaxSize: 10_000})
+	for i := int64(0); i < 10_000; i++ {
+		cache.Set(i*7919, int(i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get(int64(i%10_000) * 7919)
+	}
+}
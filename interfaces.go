@@ -6,46 +6,33 @@
 
 package balios
 
-import "context"
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+)
 
 // Cache represents a high-performance in-memory cache interface.
 // All methods must be safe for concurrent use.
+//
+// Cache holds the core operations only. The caches returned by NewCache, and
+// SwappableCache, implement as well the optional interfaces below, which
+// callers type-assert:
+//
+//	if versioned, ok := cache.(balios.Versioned); ok {
+//	    value, version, found := versioned.GetVersion("config:svc")
+//	    // ...
+//	}
+//
+// The optional interfaces are CheckedCache, ExpiryReader, HistoryReader,
+// BatchCache, BatchLoader, Snapshotter, SketchController, ConditionalCache,
+// Versioned, Resizable, Compactor, NegativeCache, DependencyTracker,
+// Namespaced, SourceTagger, EventSource, KeyLocker, Iterable, Inspector and
+// Diagnostics.
 type Cache interface {
-	// Get retrieves a value from the cache.
-	// Returns the value and true if found, nil and false otherwise.
-	// This method must be zero-allocation on the hot path.
-	Get(key string) (value interface{}, found bool)
-
-	// Set stores a key-value pair in the cache.
-	// Returns true if the item was successfully stored.
-	//
-	// Note: Returns false only in extreme cases when the cache is full and
-	// eviction fails repeatedly, which is virtually impossible in normal operation
-	// (< 0.001% probability with proper cache sizing). In practice, Set() always succeeds.
-	//
-	// This method must be zero-allocation on the hot path.
-	Set(key string, value interface{}) bool
-
-	// Delete removes an item from the cache.
-	// Returns true if the item was present and removed.
-	Delete(key string) bool
-
-	// Has checks if a key exists in the cache without retrieving the value.
-	// Returns false if the key does not exist or has expired (when TTL is enabled).
-	// This method should be faster than Get when only existence matters.
-	Has(key string) bool
-
-	// Len returns the current number of items in the cache.
-	Len() int
-
-	// Capacity returns the maximum number of items the cache can hold.
-	Capacity() int
-
-	// Clear removes all items from the cache.
-	// Note: This operation is not atomic. During Clear(), other goroutines
-	// may still read/write, potentially observing a partially cleared cache.
-	// This is acceptable for most use cases (cache flush, shutdown, testing).
-	Clear()
+	// Backend provides Get, Set, Delete, Has, Len, Capacity and Clear.
+	Backend
 
 	// Stats returns cache statistics.
 	Stats() CacheStats
@@ -53,13 +40,15 @@ type Cache interface {
 	// GetOrLoad returns the value from cache, or loads it using the provided loader.
 	// If multiple goroutines call GetOrLoad for the same missing key concurrently,
 	// only one loader will be executed (singleflight pattern).
-	// The loaded value is cached with the cache's default TTL.
-	// If the loader returns an error, the error is NOT cached.
-	GetOrLoad(key string, loader func() (interface{}, error)) (interface{}, error)
+	// The loaded value is cached with the cache's default TTL unless a
+	// LoadOption (WithTTL, WithSkipNegativeCache, WithPriority, WithTags)
+	// says otherwise. If the loader returns an error, the error is NOT cached.
+	// With WithRefreshTTL, stale hits trigger a background reload.
+	GetOrLoad(key string, loader func() (interface{}, error), opts ...LoadOption) (interface{}, error)
 
 	// GetOrLoadWithContext is like GetOrLoad but respects context cancellation and timeout.
 	// The context is passed to the loader function for cancellation control.
-	GetOrLoadWithContext(ctx context.Context, key string, loader func(context.Context) (interface{}, error)) (interface{}, error)
+	GetOrLoadWithContext(ctx context.Context, key string, loader func(context.Context) (interface{}, error), opts ...LoadOption) (interface{}, error)
 
 	// ExpireNow manually expires all entries that have exceeded their TTL.
 	// This method scans the entire cache and removes expired entries immediately.
@@ -80,11 +69,372 @@ type Cache interface {
 	//   - Number of expired entries removed from the cache
 	ExpireNow() int
 
-	// Close gracefully shuts down the cache and releases resources.
+	// Close gracefully shuts down the cache and releases resources: the
+	// background goroutines are stopped, the entries are dropped (reported
+	// to OnEvict) and the table is released. Afterwards operations are safe
+	// no-ops, or return ErrCacheClosed when they return an error. Close may
+	// race with operations in progress: they complete on the table they
+	// started with. Calling Close again does nothing.
 	Close() error
 }
 
+// CheckedCache is implemented by caches whose reads and writes can report
+// why they did not succeed.
+type CheckedCache interface {
+	// GetE is like Get but reports why a value was not returned: a
+	// BALIOS_KEY_NOT_FOUND error for a miss, or a retryable
+	// BALIOS_READ_CONTENTION error when the key could not be read within
+	// Config.KeyReadRetries because concurrent writers kept rewriting it.
+	GetE(key string) (interface{}, error)
+
+	// GetWithReason is like Get but on a miss also reports why: absent,
+	// expired, evicted or refused by the AdmissionPolicy. Only MissAbsent is
+	// reported unless Config.TrackMissReasons. MissNone on a hit.
+	GetWithReason(key string) (value interface{}, reason MissReason, found bool)
+
+	// GetCtx is like Get but retries lookups abandoned under write
+	// contention until they succeed or ctx is done, returning a retryable
+	// BALIOS_CONTEXT_CANCELED error in that case. A miss is not an error.
+	GetCtx(ctx context.Context, key string) (value interface{}, found bool, err error)
+
+	// SetE is like Set but returns the reason of a failure: BALIOS_EMPTY_KEY,
+	// BALIOS_CACHE_CLOSED, BALIOS_SET_FAILED for a value that cannot be
+	// stored, or a retryable BALIOS_CACHE_FULL when no slot could be claimed.
+	SetE(key string, value interface{}) error
+
+	// SetCtx is like Set but retries while concurrent writers hold every
+	// candidate slot, until the write succeeds or ctx is done
+	// (BALIOS_CONTEXT_CANCELED). Values that cannot be stored return
+	// BALIOS_SET_FAILED.
+	SetCtx(ctx context.Context, key string, value interface{}) error
+}
+
+// ExpiryReader is implemented by caches exposing the expiration deadlines of
+// their entries.
+type ExpiryReader interface {
+	// GetWithExpiry is like Get but also returns when the entry expires, on
+	// the TimeProvider clock (the zero time if it has no TTL).
+	GetWithExpiry(key string) (value interface{}, expiresAt time.Time, found bool)
+
+	// NextExpiration returns the earliest time at which ExpireNow will remove
+	// an entry, on the TimeProvider clock. ok is false if no live entry has a
+	// TTL. O(n), like ExpireNow.
+	NextExpiration() (next time.Time, ok bool)
+
+	// ExpiringBefore returns the keys of the entries that will have expired by
+	// t, including expired entries not yet removed. O(n), like ExpireNow.
+	ExpiringBefore(t time.Time) []string
+}
+
+// HistoryReader is implemented by caches keeping the values replaced by
+// writes (Config.ValueHistory).
+type HistoryReader interface {
+	// GetPrevious returns the value key held before its last replacement.
+	// Requires Config.ValueHistory.
+	GetPrevious(key string) (value interface{}, found bool)
+
+	// History returns up to Config.ValueHistory values key held before its
+	// current one, newest first.
+	History(key string) []interface{}
+}
+
+// BatchCache is implemented by caches reading and writing many keys per
+// call.
+type BatchCache interface {
+	// GetMany retrieves several keys at once, returning the ones found.
+	// Equivalent to calling Get for each key, with the per-call overhead
+	// (clock read, counter updates) shared across the batch.
+	GetMany(keys []string) map[string]interface{}
+
+	// SetMany stores every key-value pair of entries and returns the number
+	// of pairs stored.
+	SetMany(entries map[string]interface{}) int
+
+	// Warm preloads entries into the free capacity of the cache, without
+	// counting them in the frequency sketch or consulting the
+	// AdmissionPolicy. Keys already cached are skipped and the load stops
+	// when the cache is full. Returns the number of entries stored.
+	Warm(entries map[string]interface{}) int
+
+	// WarmSeq is Warm for a streaming source (a snapshot or dump reader): it
+	// stops iterating entries once the cache is full.
+	WarmSeq(entries iter.Seq2[string, interface{}]) int
+}
+
+// BatchLoader is implemented by caches loading many missing keys with a
+// single loader call.
+type BatchLoader interface {
+	// GetOrLoadMany returns the values of keys, loading every missing key
+	// with a single loader call. Keys already being loaded by GetOrLoad or
+	// another batch are awaited instead of being passed to the loader.
+	// LoaderMiddleware, the circuit breaker, LoadRateLimit, negative caching
+	// and SecondaryCache lookups do not apply to batch loads.
+	GetOrLoadMany(keys []string, loader func(missing []string) (map[string]interface{}, error), opts ...LoadOption) (map[string]interface{}, error)
+
+	// GetOrLoadGrouped returns the value of key, loading it on a miss with
+	// the concurrent misses of the other keys of groupKey in a single loader
+	// call (see loading_grouped.go). Like GetOrLoadMany, it bypasses the
+	// per-key load pipeline of GetOrLoad.
+	GetOrLoadGrouped(groupKey, key string, loader func(keys []string) (map[string]interface{}, error), opts ...LoadOption) (interface{}, error)
+}
+
+// Snapshotter is implemented by caches that can save their entries and
+// restore them, in another process for instance.
+type Snapshotter interface {
+	// SaveTo writes a snapshot of the live entries (values encoded with
+	// Config.Codec, remaining TTLs preserved) and returns the number written.
+	SaveTo(w io.Writer) (int, error)
+
+	// LoadFrom restores a snapshot written by SaveTo and returns the number
+	// of entries stored. A corrupted snapshot stores nothing and returns a
+	// BALIOS_CORRUPTED_DATA error.
+	LoadFrom(r io.Reader) (int, error)
+
+	// SaveToFile writes a snapshot to path, atomically replacing it.
+	SaveToFile(path string) (int, error)
+
+	// LoadFromFile restores a snapshot written by SaveToFile.
+	LoadFromFile(path string) (int, error)
+
+	// Export writes the live entries to w as a JSON or MessagePack document,
+	// sorted by key, and returns the number written. Every value must be
+	// marshalable by encoding/json.
+	Export(w io.Writer, format ExportFormat) (int, error)
+
+	// Import restores a document written by Export and returns the number of
+	// entries stored, values in their generic form (map[string]any, int64...).
+	Import(r io.Reader, format ExportFormat) (int, error)
+}
+
+// SketchController is implemented by caches whose access frequencies can be
+// aged, exported and restored.
+type SketchController interface {
+	// DecaySketch halves the estimated access frequencies of all keys (the
+	// aging step of the frequency sketch), so keys that stopped being popular
+	// become eviction candidates again. Safe to call concurrently.
+	DecaySketch()
+
+	// SketchSnapshot exports the access frequencies recorded by the cache,
+	// so a restarted process can restore them with RestoreSketch. Returns nil
+	// if the FrequencyEstimator cannot be exported.
+	SketchSnapshot() []byte
+
+	// RestoreSketch replaces the access frequencies with a SketchSnapshot,
+	// rescaled if it was taken with a different MaxSize.
+	RestoreSketch(data []byte) error
+}
+
+// ConditionalCache is implemented by caches with atomic read-modify-write
+// operations on a key.
+type ConditionalCache interface {
+	// CompareAndSwap replaces the value of key with newValue only if the current
+	// value equals oldValue, as determined by Config.ValueEqual (default: ==,
+	// with non-comparable values never matching). Returns true if swapped.
+	CompareAndSwap(key string, oldValue, newValue interface{}) bool
+
+	// Swap stores value for key and returns the previous value.
+	// loaded reports whether the key was present before the call.
+	Swap(key string, value interface{}) (previous interface{}, loaded bool)
+
+	// SetIfAbsent stores value for key only if key is absent or expired.
+	// Concurrent SetIfAbsent calls for the same key store exactly one value.
+	// Returns true if the value was stored.
+	SetIfAbsent(key string, value interface{}) bool
+
+	// Compute replaces the value of key with the result of fn, called with
+	// the current value (exists = false when absent) while the key is held
+	// exclusively, so concurrent Computes of a key do not lose updates. fn
+	// returns keep = false to delete the key; it must be short and must not
+	// access the same key. Returns the value after the call and whether the
+	// key is present.
+	Compute(key string, fn func(old interface{}, exists bool) (interface{}, bool)) (value interface{}, present bool)
+}
+
+// Versioned is implemented by caches stamping their entries with versions
+// for optimistic concurrency (Config.EntryVersions).
+type Versioned interface {
+	// GetVersion returns the value of key together with its version, stamped
+	// on every write with Config.EntryVersions (0 otherwise). Counts as a Get.
+	GetVersion(key string) (value interface{}, version uint64, found bool)
+
+	// VersionOf returns the version of the live entry of key without side
+	// effects. found is false if the key is absent or expired.
+	VersionOf(key string) (version uint64, found bool)
+
+	// SetIfVersion stores value for key only if its live entry still carries
+	// version (0: only if absent), for optimistic concurrency. Returns the new
+	// version and true, or false on a mismatch, a rejected value, or without
+	// Config.EntryVersions.
+	SetIfVersion(key string, value interface{}, version uint64) (newVersion uint64, ok bool)
+}
+
+// Resizable is implemented by caches whose capacity can change while they
+// are in use.
+type Resizable interface {
+	// Resize sets the capacity to newMaxSize entries, evicting the entries
+	// in excess. The warm entries are kept; growing beyond the capacity the
+	// table was sized for (Config.ResizeLimit) copies them to a larger table.
+	Resize(newMaxSize int) error
+}
+
+// Compactor is implemented by caches that can release the memory retained by
+// removed entries.
+type Compactor interface {
+	// CompactionReport reports memory retained by dead slots (deleted, evicted,
+	// expired or cleared entries still referencing keys/values) and oversized
+	// []byte values, without modifying the cache. O(table size).
+	CompactionReport() CompactionReport
+
+	// Compact releases the memory described by CompactionReport and returns
+	// what was found. Safe to call concurrently with other operations.
+	Compact() CompactionReport
+}
+
+// NegativeCache is implemented by caches caching loader errors
+// (Config.NegativeCacheTTL).
+type NegativeCache interface {
+	// InvalidateNegative removes the loader error cached for key by negative
+	// caching (Config.NegativeCacheTTL), so the next GetOrLoad calls the
+	// loader again. Returns true if a live cached error was removed.
+	InvalidateNegative(key string) bool
+
+	// NegativeLen returns the number of loader errors currently cached by
+	// negative caching. O(cached errors).
+	NegativeLen() int
+}
+
+// DependencyTracker is implemented by caches invalidating entries together
+// with the keys they were derived from.
+type DependencyTracker interface {
+	// SetWithDependencies stores a key-value pair derived from deps (other
+	// cache keys or external identifiers). Returns false if not stored,
+	// including when the dependency index is full (Config.MaxDependencyEdges).
+	SetWithDependencies(key string, value interface{}, deps ...string) bool
+
+	// InvalidateKey deletes dep and every entry depending on it, directly or
+	// transitively. Returns the number of cache entries removed.
+	InvalidateKey(dep string) int
+}
+
+// Namespaced is implemented by caches whose keys can be grouped in
+// namespaces.
+type Namespaced interface {
+	// Namespace returns a view of the cache whose keys are prefixed with name
+	// and NamespaceSeparator, with Clear, Len, Keys and DeleteByPrefix scoped
+	// to the namespace (see namespace_view.go).
+	Namespace(name string, opts ...NamespaceOption) *Namespace
+
+	// ClearNamespace removes every entry whose key starts with ns followed by
+	// NamespaceSeparator. Returns the number of entries removed. Runs in
+	// O(entries in ns) with Config.IndexNamespaces, O(capacity) otherwise.
+	ClearNamespace(ns string) int
+}
+
+// SourceTagger is implemented by caches tagging entries with the code path
+// that wrote them.
+type SourceTagger interface {
+	// SetWithSource is like Set but tags the entry with the code path that
+	// wrote it (truncated to MaxSourceLength). The tag is returned by SourceOf
+	// and reported in eviction/expiration events (Config.OnEntryEvent).
+	SetWithSource(key string, value interface{}, source string) bool
+
+	// SourceOf returns the source tag of a live entry ("" if untagged).
+	// found is false if the key is absent or expired.
+	SourceOf(key string) (source string, found bool)
+}
+
+// EventSource is implemented by caches publishing entry lifecycle events.
+type EventSource interface {
+	// Subscribe returns a channel receiving the lifecycle events of the
+	// types in mask until Unsubscribe or Close closes it. A full channel
+	// drops its oldest event (see event_stream.go).
+	Subscribe(mask EventMask) <-chan Event
+
+	// Unsubscribe closes ch, a channel returned by Subscribe. Returns false
+	// if ch is not subscribed.
+	Unsubscribe(ch <-chan Event) bool
+}
+
+// KeyLocker is implemented by caches offering advisory per-key locks.
+type KeyLocker interface {
+	// LockKey locks key against other LockKey callers and returns the
+	// function releasing it, for read-modify-write sequences involving
+	// external systems. The lock is advisory and striped (see lock_key.go).
+	LockKey(key string) func()
+}
+
+// Iterable is implemented by caches whose entries can be walked and removed
+// by key or value.
+type Iterable interface {
+	// Range calls f for each live entry until f returns false. It walks the
+	// table without blocking writers: entries changed during the walk may or
+	// may not be visited. Safe to call cache methods from f.
+	Range(f func(key string, value interface{}) bool)
+
+	// Keys returns the keys of the live entries. O(table size).
+	Keys() []string
+
+	// DeleteByPrefix removes every entry whose key starts with prefix and
+	// returns the number removed. A namespace prefix ("ns" +
+	// NamespaceSeparator) uses ClearNamespace; an empty prefix removes nothing.
+	DeleteByPrefix(prefix string) int
+
+	// DeleteFunc removes every live entry for which f returns true and returns
+	// the number removed. Removals are reported like Delete.
+	DeleteFunc(f func(key string, value interface{}) bool) int
+}
+
+// Inspector is implemented by caches reporting the metadata of their
+// entries and their memory use.
+type Inspector interface {
+	// EntryInfo returns the metadata of a live entry (frequency estimate,
+	// expiry, weight, source and, with Config.TrackEntryTimes, its insertion,
+	// write and access times) without side effects. found is false if the
+	// key is absent or expired.
+	EntryInfo(key string) (info EntryInfo, found bool)
+
+	// TopKeys returns the n most accessed keys, hottest first: exact counts
+	// with Config.TopKeysCapacity, frequency sketch estimates of the cached
+	// keys otherwise (see top_keys.go).
+	TopKeys(n int) []KeyFreq
+
+	// MemoryUsage reports the key and value bytes recorded for live entries
+	// and the top largest of them. Requires Config.MemoryAccounting; walks the
+	// whole table, so it is meant for debugging.
+	MemoryUsage(top int) MemoryReport
+}
+
+// Diagnostics is implemented by caches reporting internal counters beyond
+// CacheStats.
+type Diagnostics interface {
+	// DebugStats returns internal diagnostics, such as duplicate-key cleanups
+	// broken down by probe distance.
+	DebugStats() DebugStats
+
+	// WindowStats returns the activity of the last window (at most
+	// Config.StatsWindow; zero if StatsWindow is not configured).
+	WindowStats(window time.Duration) WindowStats
+
+	// ShrinkStats returns the counters of the background shrinker enabled by
+	// Config.ShrinkAfter (zero if disabled).
+	ShrinkStats() ShrinkStats
+
+	// LoadsNotAdmitted returns the number of loaded values not cached because
+	// their cost was below Config.MinLoadCost.
+	LoadsNotAdmitted() int64
+
+	// LoadsRateLimited returns the number of loader calls rejected by
+	// Config.LoadRateLimit.
+	LoadsRateLimited() int64
+
+	// Closed reports whether Close has been called (e.g. for health checks).
+	Closed() bool
+}
+
 // CacheStats provides statistics about cache performance.
+//
+// Snapshots returned by Stats() are coherent with respect to Clear: counters are
+// never a mix of pre-Clear and post-Clear values, and Size is never negative.
 type CacheStats struct {
 	// Hits is the number of cache hits
 	Hits uint64
@@ -104,11 +454,95 @@ type CacheStats struct {
 	// Expirations is the number of items expired due to TTL
 	Expirations uint64
 
+	// DuplicateCleanups is the number of duplicate entries removed after
+	// concurrent Sets of the same key raced to insert it (see DebugStats)
+	DuplicateCleanups uint64
+
+	// RaceConditions is the number of slot compare-and-swaps lost by a Set,
+	// Delete or duplicate cleanup to a concurrent writer (see
+	// RaceConditionRecorder)
+	RaceConditions uint64
+
 	// Size is the current number of items in the cache
 	Size int
 
 	// Capacity is the maximum number of items the cache can hold
 	Capacity int
+
+	// Weight is the total weight of the cached items (see Config.MaxWeight)
+	Weight int64
+
+	// MaxWeight is the configured weight limit (0 = disabled)
+	MaxWeight int64
+
+	// ReadContentions is the number of Get lookups whose key read was
+	// abandoned after Config.KeyReadRetries attempts (possibly false misses)
+	ReadContentions uint64
+
+	// MissesExpired, MissesEvicted and MissesRejected count the Get misses
+	// of keys that expired, were evicted or were refused by the
+	// AdmissionPolicy; the other misses are of absent keys (see MissReason).
+	// Zero unless Config.TrackMissReasons
+	MissesExpired  uint64
+	MissesEvicted  uint64
+	MissesRejected uint64
+
+	// NegativeHits is the number of GetOrLoad misses answered with a cached
+	// loader error (see Config.NegativeCacheTTL)
+	NegativeHits uint64
+
+	// NegativeMisses is the number of GetOrLoad misses that found no cached
+	// loader error and called the loader (negative caching enabled only)
+	NegativeMisses uint64
+
+	// CircuitRejections is the number of loads failed fast with
+	// BALIOS_CIRCUIT_OPEN (see Config.CircuitBreakerThreshold)
+	CircuitRejections uint64
+
+	// EventsDropped is the number of events dropped from the full buffers
+	// of Subscribe channels
+	EventsDropped uint64
+
+	// RejectedTooLarge is the number of writes rejected by Config.MaxKeyLen
+	// or Config.MaxValueBytes
+	RejectedTooLarge uint64
+
+	// TombstoneHits is the number of GetOrLoad misses of recently deleted
+	// keys that were served stale or delayed (see Config.TombstoneTTL)
+	TombstoneHits uint64
+
+	// WriteBehindQueued is the number of keys waiting in the write-behind
+	// queue (a gauge, see Config.WriteBehindStore)
+	WriteBehindQueued uint64
+
+	// WriteBehindFlushed and WriteBehindFailed are the number of writes
+	// flushed to the write-behind Store, and failed after every retry
+	WriteBehindFlushed uint64
+	WriteBehindFailed  uint64
+
+	// WriteBehindDropped is the number of writes dropped by a full
+	// write-behind queue (WriteBehindDrop policy) or a closed cache
+	WriteBehindDropped uint64
+
+	// LatencyP50, LatencyP95 and LatencyP99 are the percentiles of the Get
+	// and Set latencies, within 12.5% (zero unless Config.TrackLatency)
+	LatencyP50 OpLatency
+	LatencyP95 OpLatency
+	LatencyP99 OpLatency
+
+	// LoadFactor is Size divided by the number of table slots (the table
+	// has at least two slots per entry of capacity)
+	LoadFactor float64
+
+	// AvgProbeLength and MaxProbeLength are the average and largest number
+	// of table slots examined by a Get, Set or Delete since the last Clear.
+	// Zero unless Config.TrackProbeLengths (see ProbeCountRecorder)
+	AvgProbeLength float64
+	MaxProbeLength int
+
+	// Families holds the hits and misses per key family
+	// (nil unless Config.FamilyStats)
+	Families map[string]FamilyStats
 }
 
 // HitRatio returns the cache hit ratio as a percentage (0-100).
@@ -173,6 +607,10 @@ type TimeProvider interface {
 // Thread-safety:
 //   - All methods must be safe for concurrent use
 //   - Multiple goroutines will call these methods simultaneously
+//
+// Latency values:
+//   - latencyNs is -1 when Config.DisableMetricsLatency is set; implementations
+//     should count the operation but skip latency recording in that case
 type MetricsCollector interface {
 	// RecordGet records a Get operation with its latency and hit/miss result.
 	// latencyNs is the duration of the Get operation in nanoseconds.
@@ -196,6 +634,22 @@ type MetricsCollector interface {
 	RecordExpiration()
 }
 
+// ProbeMetricsCollector is an optional extension of MetricsCollector for the
+// read-only calls that health checks, readiness probes and dashboards issue
+// at high rates. A Config.MetricsCollector that also implements it receives
+// Has, Len and Stats calls; collectors that do not are unaffected.
+type ProbeMetricsCollector interface {
+	// RecordHas records a Has call with its latency (-1 when latency
+	// measurement is disabled) and whether the key was found.
+	RecordHas(latencyNs int64, hit bool)
+
+	// RecordLen records a Len call.
+	RecordLen()
+
+	// RecordStats records a Stats call (e.g. a metrics scrape).
+	RecordStats()
+}
+
 // NoOpMetricsCollector is a metrics collector that does nothing.
 // Used as default to avoid nil checks and ensure zero overhead.
 // All methods are inlined by the compiler for maximum performance.
@@ -215,3 +669,12 @@ func (NoOpMetricsCollector) RecordEviction() {}
 
 // RecordExpiration does nothing. Inlined by compiler.
 func (NoOpMetricsCollector) RecordExpiration() {}
+
+// RecordHas does nothing. Inlined by compiler.
+func (NoOpMetricsCollector) RecordHas(latencyNs int64, hit bool) {}
+
+// RecordLen does nothing. Inlined by compiler.
+func (NoOpMetricsCollector) RecordLen() {}
+
+// RecordStats does nothing. Inlined by compiler.
+func (NoOpMetricsCollector) RecordStats() {}
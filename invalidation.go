@@ -0,0 +1,198 @@
+// invalidation.go: distributed invalidation of in-process caches
+//
+// With Config.InvalidationBus, writes are published as invalidation events
+// and the other caches on the bus drop the keys.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultInvalidationBuffer is the default number of events buffered per
+// subscriber of a MemoryInvalidationBus.
+const DefaultInvalidationBuffer = 1024
+
+// InvalidationEvent announces that Keys were written or deleted by the cache
+// identified by Origin.
+type InvalidationEvent struct {
+	Origin string   // ID of the publishing cache (see Config.InvalidationBus)
+	Keys   []string // Invalidated keys
+}
+
+// InvalidationBus carries invalidation events between the caches of several
+// processes (see Config.InvalidationBus). Implementations must be safe for
+// concurrent use.
+type InvalidationBus interface {
+	// Publish sends event to the subscribers. It is called on the write
+	// path and should not block for long.
+	Publish(ctx context.Context, event InvalidationEvent) error
+
+	// Subscribe registers handler for the events published on the bus,
+	// including those published by the subscriber itself. unsubscribe stops
+	// the delivery of events to handler.
+	Subscribe(handler func(InvalidationEvent)) (unsubscribe func(), err error)
+}
+
+// newInstanceID returns a random ID identifying a cache on an
+// InvalidationBus.
+func newInstanceID() string {
+	var id [8]byte
+	_, _ = rand.Read(id[:]) // crypto/rand.Read never fails on supported platforms
+	return hex.EncodeToString(id[:])
+}
+
+// subscribeInvalidations subscribes the cache to its InvalidationBus.
+func (c *wtinyLFUCache) subscribeInvalidations() {
+	unsubscribe, err := c.invalidationBus.Subscribe(c.applyInvalidation)
+	if err != nil {
+		c.logger.Error("balios: invalidation bus subscription failed", "error", err)
+		return
+	}
+	c.unsubscribeInvalidations = unsubscribe
+}
+
+// applyInvalidation removes the keys of an event published by another cache.
+func (c *wtinyLFUCache) applyInvalidation(event InvalidationEvent) {
+	if event.Origin == c.instanceID {
+		return
+	}
+	c.whileOpen(func() {
+		atomic.AddInt64(&c.invalidationsReceived, 1)
+		for _, key := range event.Keys {
+			if key != "" {
+				c.deleteLocal(key)
+			}
+		}
+	})
+}
+
+// publishInvalidation publishes the invalidation of keys.
+func (c *wtinyLFUCache) publishInvalidation(keys ...string) {
+	event := InvalidationEvent{Origin: c.instanceID, Keys: keys}
+	if err := c.invalidationBus.Publish(context.Background(), event); err != nil {
+		c.logger.Warn("balios: invalidation publish failed", "keys", len(keys), "error", err)
+	}
+}
+
+// propagateWrite writes a stored value through to the SecondaryCache, queues
+// it for the write-behind Store and publishes its invalidation.
+func (c *wtinyLFUCache) propagateWrite(key string, value interface{}, ttlNanos int64) {
+	if c.secondary != nil {
+		c.writeSecondary(key, value, ttlNanos)
+	}
+	if c.writeBehind != nil {
+		c.queueWrite(key, value)
+	}
+	if c.invalidationBus != nil {
+		c.publishInvalidation(key)
+	}
+}
+
+// propagateDelete deletes key from the SecondaryCache, queues its deletion
+// from the write-behind Store and publishes its invalidation.
+func (c *wtinyLFUCache) propagateDelete(key string) {
+	if c.secondary != nil {
+		c.deleteSecondary(key)
+	}
+	if c.writeBehind != nil {
+		c.queueDelete(key)
+	}
+	if c.invalidationBus != nil {
+		c.publishInvalidation(key)
+	}
+}
+
+// MemoryInvalidationBus is an in-process InvalidationBus delivering events
+// through buffered channels, one goroutine per subscriber. It connects caches
+// of the same process (tests, several caches over the same data) and is a
+// reference for adapters to Redis pub/sub, NATS or other brokers.
+type MemoryInvalidationBus struct {
+	mu          sync.RWMutex
+	subscribers map[*memorySubscriber]struct{}
+	buffer      int
+}
+
+// memorySubscriber is a subscription to a MemoryInvalidationBus.
+type memorySubscriber struct {
+	events chan InvalidationEvent
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewMemoryInvalidationBus creates a MemoryInvalidationBus buffering up to
+// buffer events per subscriber (DefaultInvalidationBuffer if buffer <= 0).
+// Publish blocks while a subscriber's buffer is full.
+func NewMemoryInvalidationBus(buffer int) *MemoryInvalidationBus {
+	if buffer <= 0 {
+		buffer = DefaultInvalidationBuffer
+	}
+	return &MemoryInvalidationBus{
+		subscribers: make(map[*memorySubscriber]struct{}),
+		buffer:      buffer,
+	}
+}
+
+// Publish delivers event to every subscriber. Returns ctx.Err() if ctx is
+// done while a subscriber's buffer is full.
+func (b *MemoryInvalidationBus) Publish(ctx context.Context, event InvalidationEvent) error {
+	b.mu.RLock()
+	subscribers := make([]*memorySubscriber, 0, len(b.subscribers))
+	for sub := range b.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		select {
+		case sub.events <- event:
+		case <-sub.done: // Unsubscribed meanwhile
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe starts delivering events to handler, in publication order.
+func (b *MemoryInvalidationBus) Subscribe(handler func(InvalidationEvent)) (func(), error) {
+	if handler == nil {
+		return nil, NewErrInvalidConfig("handler", "invalidation handler cannot be nil")
+	}
+
+	sub := &memorySubscriber{
+		events: make(chan InvalidationEvent, b.buffer),
+		done:   make(chan struct{}),
+	}
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case event := <-sub.events:
+				handler(event)
+			case <-sub.done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		sub.once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+			close(sub.done)
+		})
+	}, nil
+}
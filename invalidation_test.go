@@ -0,0 +1,182 @@
+// invalidation_test.go: tests for distributed invalidation of in-process caches
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestInvalidationBus_WritesInvalidateOtherCaches(t *testing.T) {
+	bus := NewMemoryInvalidationBus(0)
+	first := NewCache(Config{MaxSize: 100, InvalidationBus: bus}).(*wtinyLFUCache)
+	second := NewCache(Config{MaxSize: 100, InvalidationBus: bus}).(*wtinyLFUCache)
+	defer func() { _ = first.Close() }()
+	defer func() { _ = second.Close() }()
+
+	second.Set("a", "stale")
+	second.Set("b", "stale")
+	waitFor(t, "second's own events", func() bool { return first.DebugStats().InvalidationsReceived == 2 })
+
+	first.Set("a", "fresh")
+	waitFor(t, "invalidation of a", func() bool {
+		_, ok := second.Get("a")
+		return !ok
+	})
+	if v, ok := first.Get("a"); !ok || v != "fresh" {
+		t.Errorf("the writer must keep its own value, got %v, %v", v, ok)
+	}
+
+	first.Delete("b")
+	waitFor(t, "invalidation of b", func() bool {
+		_, ok := second.Get("b")
+		return !ok
+	})
+	if got := second.DebugStats().InvalidationsReceived; got != 2 {
+		t.Errorf("expected 2 invalidations received, got %d", got)
+	}
+}
+
+func TestInvalidationBus_LocalOperationsNotPublished(t *testing.T) {
+	bus := NewMemoryInvalidationBus(0)
+	var mu sync.Mutex
+	var events []InvalidationEvent
+	unsubscribe, err := bus.Subscribe(func(e InvalidationEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	cache := NewCache(Config{MaxSize: 100, InvalidationBus: bus}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	if _, err := cache.GetOrLoad("loaded", func() (interface{}, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+	cache.Clear()
+	cache.SetMany(map[string]interface{}{"x": 1, "y": 2, "": 3})
+
+	waitFor(t, "SetMany event", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) > 0
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 {
+		t.Fatalf("loads and Clear must not be published, got %v", events)
+	}
+	keys := append([]string(nil), events[0].Keys...)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "x" || keys[1] != "y" || events[0].Origin == "" {
+		t.Errorf("SetMany should publish its stored keys in one event, got %+v", events[0])
+	}
+}
+
+// failingBus rejects every publication.
+type failingBus struct{ *MemoryInvalidationBus }
+
+func (failingBus) Publish(context.Context, InvalidationEvent) error {
+	return errors.New("broker unreachable")
+}
+
+func TestInvalidationBus_PublishErrorDoesNotFailWrites(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, InvalidationBus: failingBus{NewMemoryInvalidationBus(0)}})
+	defer func() { _ = cache.Close() }()
+
+	if !cache.Set("a", 1) {
+		t.Fatal("Set must succeed when publishing fails")
+	}
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Errorf("expected the stored value, got %v, %v", v, ok)
+	}
+	if !cache.Delete("a") {
+		t.Error("Delete must succeed when publishing fails")
+	}
+}
+
+func TestInvalidationBus_CloseUnsubscribes(t *testing.T) {
+	bus := NewMemoryInvalidationBus(0)
+	cache := NewCache(Config{MaxSize: 100, InvalidationBus: bus}).(*wtinyLFUCache)
+
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.mu.RLock()
+	subscribers := len(bus.subscribers)
+	bus.mu.RUnlock()
+	if subscribers != 0 {
+		t.Errorf("Close should unsubscribe from the bus, %d subscribers left", subscribers)
+	}
+}
+
+func TestMemoryInvalidationBus_PublishBlockedByFullBuffer(t *testing.T) {
+	bus := NewMemoryInvalidationBus(1)
+	release := make(chan struct{})
+	unsubscribe, err := bus.Subscribe(func(InvalidationEvent) { <-release })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One event in the handler, one in the buffer, the third one blocks
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var publishErr error
+	for i := 0; i < 3 && publishErr == nil; i++ {
+		publishErr = bus.Publish(ctx, InvalidationEvent{Keys: []string{"k"}})
+	}
+	if !errors.Is(publishErr, context.DeadlineExceeded) {
+		t.Errorf("expected the context error from a full buffer, got %v", publishErr)
+	}
+
+	// Unsubscribing unblocks publishers
+	unsubscribe()
+	unsubscribe() // Idempotent
+	close(release)
+	if err := bus.Publish(context.Background(), InvalidationEvent{Keys: []string{"k"}}); err != nil {
+		t.Errorf("publish without subscribers failed: %v", err)
+	}
+
+	if _, err := bus.Subscribe(nil); err == nil {
+		t.Error("expected an error for a nil handler")
+	}
+}
+
+func TestInvalidationBus_Generic(t *testing.T) {
+	bus := NewMemoryInvalidationBus(0)
+	first := NewGenericCache[int, string](Config{MaxSize: 100, InvalidationBus: bus})
+	second := NewGenericCache[int, string](Config{MaxSize: 100, InvalidationBus: bus})
+	defer func() { _ = first.Close() }()
+	defer func() { _ = second.Close() }()
+
+	second.Set(1, "stale")
+	first.Set(1, "fresh")
+	waitFor(t, "invalidation of 1", func() bool {
+		v, ok := second.Get(1)
+		return !ok || v == "fresh"
+	})
+}
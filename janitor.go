@@ -0,0 +1,86 @@
+// janitor.go: background expiration of entries past their TTL
+//
+// When Config.CleanupInterval is set, a background goroutine runs the
+// ExpireNow sweep on that period, a chunk at a time.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// janitorChunkSlots is the number of slots expired between two yields.
+const janitorChunkSlots = 4096
+
+// ExpirationSweepRecorder is an optional MetricsCollector extension.
+// Collectors implementing it are notified at the end of every background
+// expiration sweep (see Config.CleanupInterval).
+type ExpirationSweepRecorder interface {
+	// RecordExpirationSweep records a completed sweep with the number of
+	// entries it expired and its duration.
+	RecordExpirationSweep(expired int, durationNs int64)
+}
+
+// runJanitor sweeps the table for expired entries every interval until Close
+// is called.
+func (c *wtinyLFUCache) runJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopJanitor:
+			return
+		case <-ticker.C:
+			c.expireSweep()
+		}
+	}
+}
+
+// expireSweep removes expired entries chunk by chunk and reports the sweep.
+// It returns the number of entries expired, stopping early if the cache is
+// closed.
+func (c *wtinyLFUCache) expireSweep() int {
+	if atomic.LoadInt64(&c.maxTTLNanos) == 0 {
+		return 0 // No entry has ever had a TTL
+	}
+	t := c.table.Load()
+	if t == nil {
+		return 0 // Closed
+	}
+
+	start := c.timeProvider.Now()
+	expired := 0
+	for from := 0; from < len(t.entries); from += janitorChunkSlots {
+		select {
+		case <-c.stopJanitor:
+			return expired
+		default:
+		}
+
+		to := from + janitorChunkSlots
+		if to > len(t.entries) {
+			to = len(t.entries)
+		}
+		if !t.enter(0) {
+			break // Resize migrates the table: the next sweep visits the new one
+		}
+		expired += c.expireRange(t, from, to, c.ttlClock(c.timeProvider.Now()))
+		t.leave(0)
+		runtime.Gosched()
+	}
+
+	if expired > 0 {
+		c.logger.Debug("balios: expiration sweep completed", "expired", expired)
+	}
+	if c.sweepRecorder != nil {
+		c.sweepRecorder.RecordExpirationSweep(expired, c.timeProvider.Now()-start)
+	}
+	return expired
+}
@@ -0,0 +1,146 @@
+// janitor_test.go: tests for background expiration of entries past their TTL
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sweepCollector records expiration sweeps.
+type sweepCollector struct {
+	NoOpMetricsCollector
+	sweeps  int64
+	expired int64
+}
+
+func (s *sweepCollector) RecordExpirationSweep(expired int, durationNs int64) {
+	atomic.AddInt64(&s.sweeps, 1)
+	atomic.AddInt64(&s.expired, int64(expired))
+}
+
+func TestExpireSweep_RemovesExpiredEntriesAcrossChunks(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: 1_000_000_000}
+	collector := &sweepCollector{}
+	cache := NewCache(Config{
+		MaxSize:          10_000,
+		TTL:              time.Minute,
+		TimeProvider:     mockTime,
+		MetricsCollector: collector,
+	}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	if len(cache.table.Load().entries) <= janitorChunkSlots {
+		t.Fatalf("table of %d slots does not span several chunks", len(cache.table.Load().entries))
+	}
+
+	for i := 0; i < 5000; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	if got := cache.expireSweep(); got != 0 {
+		t.Fatalf("nothing is expired yet, sweep removed %d", got)
+	}
+
+	mockTime.Advance(2 * time.Minute)
+	cache.Set("fresh", "value")
+	inline := int(cache.Stats().Expirations) // Set may expire entries on its probe path
+
+	if got := cache.expireSweep(); got != 5000-inline {
+		t.Errorf("expected %d expired entries, got %d", 5000-inline, got)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Errorf("expected only the fresh entry to remain, Len = %d", got)
+	}
+	if _, ok := cache.Get("fresh"); !ok {
+		t.Error("fresh entry must survive the sweep")
+	}
+	if stats := cache.Stats(); stats.Expirations != 5000 {
+		t.Errorf("expected 5000 expirations in stats, got %d", stats.Expirations)
+	}
+	if sweeps, expired := atomic.LoadInt64(&collector.sweeps), atomic.LoadInt64(&collector.expired); sweeps != 2 || expired != int64(5000-inline) {
+		t.Errorf("expected 2 recorded sweeps expiring %d, got %d sweeps expiring %d", 5000-inline, sweeps, expired)
+	}
+}
+
+func TestExpireSweep_NoTTL(t *testing.T) {
+	collector := &sweepCollector{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	cache.Set("key", "value")
+	if got := cache.expireSweep(); got != 0 {
+		t.Errorf("expected no expiration without TTL, got %d", got)
+	}
+	if sweeps := atomic.LoadInt64(&collector.sweeps); sweeps != 0 {
+		t.Errorf("a cache without TTL should skip sweeps, recorded %d", sweeps)
+	}
+}
+
+func TestJanitor_ExpiresInBackground(t *testing.T) {
+	collector := &sweepCollector{}
+	cache := NewCache(Config{
+		MaxSize:          1000,
+		TTL:              20 * time.Millisecond,
+		CleanupInterval:  5 * time.Millisecond,
+		MetricsCollector: collector,
+	})
+	defer func() { _ = cache.Close() }()
+
+	for i := 0; i < 100; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&collector.expired) < 100 {
+		if time.Now().After(deadline) {
+			t.Fatalf("janitor did not expire entries, %d reported", atomic.LoadInt64(&collector.expired))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := cache.Len(); got != 0 {
+		t.Errorf("expected an empty cache after the sweeps, Len = %d", got)
+	}
+}
+
+func TestJanitor_DisabledByDefault(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	if cache.stopJanitor != nil {
+		t.Error("janitor must not start without CleanupInterval")
+	}
+}
+
+func TestJanitor_StoppedByClose(t *testing.T) {
+	collector := &sweepCollector{}
+	cache := NewCache(Config{
+		MaxSize:          100,
+		TTL:              time.Millisecond,
+		CleanupInterval:  time.Millisecond,
+		MetricsCollector: collector,
+	}).(*wtinyLFUCache)
+
+	cache.Set("key", "value")
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+
+	select {
+	case <-cache.stopJanitor:
+	default:
+		t.Fatal("Close must signal the janitor to stop")
+	}
+	// A sweep racing with Close returns before scanning
+	if got := cache.expireSweep(); got != 0 {
+		t.Errorf("sweep after Close expired %d entries", got)
+	}
+}
@@ -0,0 +1,68 @@
+// key_samples.go: sampled keys for latency tracing (KeySampleRecorder)
+//
+// Collectors implementing KeySampleRecorder receive the key and latency of
+// one Get or Set in KeySampleEvery().
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+// KeySampleRecorder is an optional MetricsCollector extension. Collectors
+// implementing it are passed the key and latency of one Get or Set in
+// KeySampleEvery(), to trace slow operations back to their keys without
+// labeling every measurement with a key.
+type KeySampleRecorder interface {
+	// KeySampleEvery returns N: one Get or Set in N, picked at random, is
+	// passed to RecordKeySample. Called once, by NewCache; 0 or less
+	// disables sampling.
+	KeySampleEvery() int
+
+	// RecordKeySample records that op (ProbeGet or ProbeSet) on key took
+	// latencyNs nanoseconds (-1 when latency measurement is disabled).
+	RecordKeySample(op ProbeOp, key string, latencyNs int64)
+}
+
+// keySamplerOf returns the KeySampleRecorder of a collector built by
+// newGuardedMetricsCollector and its sampling rate, or nil when the
+// collector does not implement it or disables sampling.
+func keySamplerOf(collector MetricsCollector) (KeySampleRecorder, uint64) {
+	g, ok := collector.(*guardedMetricsCollector)
+	if !ok || g.keySample == nil {
+		return nil, 0
+	}
+	every := g.KeySampleEvery()
+	if every <= 0 {
+		return nil, 0
+	}
+	return g, uint64(every)
+}
+
+// sampleKey passes op on key, started at start (see latencyStart), to the
+// KeySampleRecorder if it is drawn. The caller checks c.keySampleEvery > 0.
+func (c *wtinyLFUCache) sampleKey(op ProbeOp, key string, start int64) {
+	if c.fastRand()%c.keySampleEvery != 0 {
+		return
+	}
+	c.keySampler.RecordKeySample(op, key, c.latencySince(start))
+}
+
+// KeySampleEvery forwards to the wrapped collector, returning 0 if it has
+// been disabled or panics.
+func (g *guardedMetricsCollector) KeySampleEvery() (every int) {
+	if g.keySample == nil || g.isDisabled() {
+		return 0
+	}
+	defer g.recoverPanic("KeySampleEvery")
+	return g.keySample.KeySampleEvery()
+}
+
+// RecordKeySample forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordKeySample(op ProbeOp, key string, latencyNs int64) {
+	if g.keySample == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordKeySample")
+	g.keySample.RecordKeySample(op, key, latencyNs)
+}
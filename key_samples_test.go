@@ -0,0 +1,98 @@
+// key_samples_test.go: tests for KeySampleRecorder
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"testing"
+)
+
+// keySampleCollector records the key samples it is passed.
+type keySampleCollector struct {
+	NoOpMetricsCollector
+	every int
+
+	mu      sync.Mutex
+	samples []string // op:key
+}
+
+func (c *keySampleCollector) KeySampleEvery() int { return c.every }
+
+func (c *keySampleCollector) RecordKeySample(op ProbeOp, key string, latencyNs int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.samples = append(c.samples, op.String()+":"+key)
+}
+
+func TestKeySamples_EveryOperation(t *testing.T) {
+	collector := &keySampleCollector{every: 1}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("missing")
+	cache.SetIfAbsent("b", 2)
+
+	want := []string{"set:a", "get:a", "get:missing", "set:b"}
+	if len(collector.samples) != len(want) {
+		t.Fatalf("samples = %v, want %v", collector.samples, want)
+	}
+	for i := range want {
+		if collector.samples[i] != want[i] {
+			t.Fatalf("samples = %v, want %v", collector.samples, want)
+		}
+	}
+}
+
+func TestKeySamples_IntegerKeys(t *testing.T) {
+	collector := &keySampleCollector{every: 1}
+	cache := NewGenericCache[int, int](Config{MaxSize: 100, MetricsCollector: collector})
+	defer cache.Close()
+
+	cache.Set(7, 1)
+	cache.Get(7)
+	cache.Get(8)
+
+	want := []string{"set:7", "get:7", "get:8"}
+	if len(collector.samples) != len(want) {
+		t.Fatalf("samples = %v, want %v", collector.samples, want)
+	}
+	for i := range want {
+		if collector.samples[i] != want[i] {
+			t.Fatalf("samples = %v, want %v", collector.samples, want)
+		}
+	}
+}
+
+func TestKeySamples_Rate(t *testing.T) {
+	collector := &keySampleCollector{every: 10}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector, RandSeed: 42})
+	defer cache.Close()
+
+	for i := 0; i < 10_000; i++ {
+		cache.Get("k")
+	}
+	if n := len(collector.samples); n < 800 || n > 1200 {
+		t.Fatalf("%d samples out of 10000 Gets, want about 1000", n)
+	}
+}
+
+func TestKeySamples_Disabled(t *testing.T) {
+	collector := &keySampleCollector{every: 0}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector})
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	if len(collector.samples) != 0 {
+		t.Fatalf("samples = %v, want none when KeySampleEvery returns 0", collector.samples)
+	}
+	if core := cache.(*wtinyLFUCache); core.keySampler != nil || core.keySampleEvery != 0 {
+		t.Fatal("sampling must be off when KeySampleEvery returns 0")
+	}
+}
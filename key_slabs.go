@@ -0,0 +1,98 @@
+// key_slabs.go: pooled key storage for high-churn workloads (Config.KeySlabSize)
+//
+// With Config.KeySlabSize, inserted keys are copied into shared append-only
+// slabs instead of one allocation each.
+//
+// A slab is freed by the GC only once none of its keys is referenced, so
+// under churn a few surviving keys can pin many slabs. Carved bytes cannot
+// be reused (callers may hold the key strings returned by Keys, Range or
+// events), so the pool bounds the slabs instead: while the live slabs exceed
+// twice the bytes of MaxSize keys of the average length carved so far, plus
+// one slab per P, new keys are copied separately until collected slabs bring
+// the total back under the bound.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// keySlab is a block of key bytes. Only the goroutine that took it from the
+// pool appends to it; carved bytes are never written again.
+type keySlab struct {
+	buf  []byte
+	off  int
+	keys int // Keys carved out of buf
+}
+
+// keySlabPool carves key copies out of pooled slabs.
+type keySlabPool struct {
+	size   int
+	maxKey int // Longer keys are cloned separately
+	spare  int // Slabs allowed beyond the bound: one partly carved per P
+	pool   sync.Pool
+
+	slabs    atomic.Int64 // Slabs not collected yet
+	keyBytes atomic.Int64 // Bytes carved out of full slabs
+	keyCount atomic.Int64 // Keys carved out of full slabs
+}
+
+// newKeySlabPool returns the pool for slabs of size bytes, or nil if size
+// disables it.
+func newKeySlabPool(size int) *keySlabPool {
+	if size <= 0 {
+		return nil
+	}
+	return &keySlabPool{size: size, maxKey: size / 8, spare: runtime.GOMAXPROCS(0)}
+}
+
+// clone returns a copy of key owned by the cache, whose capacity is
+// maxEntries keys.
+func (p *keySlabPool) clone(key string, maxEntries int64) string {
+	n := len(key)
+	if n == 0 || n > p.maxKey {
+		return strings.Clone(key)
+	}
+	slab, _ := p.pool.Get().(*keySlab)
+	if slab == nil || len(slab.buf)-slab.off < n {
+		if slab != nil {
+			p.keyBytes.Add(int64(slab.off))
+			p.keyCount.Add(int64(slab.keys))
+		}
+		if slab = p.newSlab(maxEntries); slab == nil {
+			return strings.Clone(key)
+		}
+	}
+	copy(slab.buf[slab.off:], key)
+	// #nosec G103 -- the carved bytes are never modified again
+	keyCopy := unsafe.String(&slab.buf[slab.off], n)
+	slab.off += n
+	slab.keys++
+	p.pool.Put(slab)
+	return keyCopy
+}
+
+// newSlab returns a new slab, or nil if the live slabs are at the bound
+// (see the file comment).
+func (p *keySlabPool) newSlab(maxEntries int64) *keySlab {
+	avgKey := int64(p.maxKey)
+	if keys := p.keyCount.Load(); keys > 0 {
+		avgKey = max(p.keyBytes.Load()/keys, 1)
+	}
+	bound := (2*maxEntries*avgKey+int64(p.size)-1)/int64(p.size) + int64(p.spare)
+	if p.slabs.Load() >= bound {
+		return nil
+	}
+	p.slabs.Add(1)
+	slab := &keySlab{buf: make([]byte, p.size)}
+	runtime.AddCleanup(&slab.buf[0], func(p *keySlabPool) { p.slabs.Add(-1) }, p)
+	return slab
+}
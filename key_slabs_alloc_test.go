@@ -0,0 +1,92 @@
+// key_slabs_alloc_test.go: allocation and retention tests for Config.KeySlabSize
+//
+// sync.Pool drops slabs at random under the race detector, so these tests
+// only run without it.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !race
+
+package balios
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestKeySlabs_ChurnAllocations(t *testing.T) {
+	keys := make([]string, 512)
+	for i := range keys {
+		keys[i] = "churn:" + strconv.Itoa(i)
+	}
+	churn := func(cache Cache) float64 {
+		i := 0
+		return testing.AllocsPerRun(2000, func() {
+			key := keys[i%len(keys)]
+			cache.Set(key, true)
+			cache.Delete(key)
+			i++
+		})
+	}
+
+	plain := NewCache(Config{MaxSize: 1000})
+	defer plain.Close()
+	pooled := NewCache(Config{MaxSize: 1000, KeySlabSize: 4096})
+	defer pooled.Close()
+
+	plainAllocs, pooledAllocs := churn(plain), churn(pooled)
+	if pooledAllocs >= plainAllocs {
+		t.Fatalf("KeySlabSize did not reduce allocations: %.2f vs %.2f allocs/op", pooledAllocs, plainAllocs)
+	}
+}
+
+// retainedKeyHeap returns the heap held by a cache after n distinct keys
+// went through it, keeping one in 200: the survivors are spread over the
+// slabs of the whole run.
+func retainedKeyHeap(slabSize, n int) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	cache := NewCache(Config{MaxSize: 2000, KeySlabSize: slabSize})
+	for i := 0; i < n; i++ {
+		key := "key:" + strconv.Itoa(i)
+		cache.Set(key, true)
+		if i%200 != 0 {
+			cache.Delete(key)
+		}
+	}
+	runtime.GC()
+	runtime.GC() // Let the cleanups of the collected slabs run
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(cache)
+	if after.HeapAlloc < before.HeapAlloc {
+		return 0
+	}
+	return after.HeapAlloc - before.HeapAlloc
+}
+
+func TestKeySlabs_RetentionBound(t *testing.T) {
+	plain := retainedKeyHeap(0, 200_000)
+	pooled := retainedKeyHeap(4096, 200_000)
+	// Unbounded, the 1000 survivors would pin each of the ~500 slabs carved
+	// (2 MB); the bound is 9 slabs plus one per P, the rest is GC noise
+	bound := uint64(256 << 10)
+	if pooled > plain+bound {
+		t.Fatalf("slabs retain %d bytes, plain keys %d: want at most %d more", pooled, plain, bound)
+	}
+}
+
+func BenchmarkKeySlabs_Retained(b *testing.B) {
+	for _, size := range []int{0, 4096} {
+		b.Run("KeySlabSize="+strconv.Itoa(size), func(b *testing.B) {
+			var retained uint64
+			for i := 0; i < b.N; i++ {
+				retained = retainedKeyHeap(size, 100_000)
+			}
+			b.ReportMetric(float64(retained), "retained-B")
+		})
+	}
+}
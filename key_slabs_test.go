@@ -0,0 +1,87 @@
+// key_slabs_test.go: tests for Config.KeySlabSize
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKeySlabPool_Clone(t *testing.T) {
+	p := newKeySlabPool(64)
+	a := p.clone("alpha", 1000)
+	b := p.clone("beta", 1000)
+	long := strings.Repeat("k", 9)
+	if a != "alpha" || b != "beta" || p.clone(long, 1000) != long || p.clone("", 1000) != "" {
+		t.Fatal("clone did not preserve the keys")
+	}
+	for i := 0; i < 100; i++ {
+		key := "key-" + strconv.Itoa(i%10)
+		if got := p.clone(key, 1000); got != key {
+			t.Fatalf("clone(%q) = %q", key, got)
+		}
+	}
+	if a != "alpha" || b != "beta" {
+		t.Fatal("earlier copies were overwritten")
+	}
+	if newKeySlabPool(0) != nil {
+		t.Fatal("KeySlabSize 0 must disable the pool")
+	}
+}
+
+func TestKeySlabs_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 500, KeySlabSize: 256}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 3000; i++ {
+				key := "g" + strconv.Itoa(g) + ":" + strconv.Itoa(i%100)
+				cache.Set(key, key)
+				if v, ok := cache.Get(key); ok && v != key {
+					t.Errorf("Get(%s) = %v", key, v)
+					return
+				}
+				if i%2 == 0 {
+					cache.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for _, key := range cache.Keys() {
+		if v, ok := cache.Get(key); !ok || v != key {
+			t.Fatalf("key %q holds %v", key, v)
+		}
+	}
+}
+
+func BenchmarkKeySlabs_Churn(b *testing.B) {
+	for _, size := range []int{0, 4096} {
+		b.Run("KeySlabSize="+strconv.Itoa(size), func(b *testing.B) {
+			cache := NewCache(Config{MaxSize: 10_000, KeySlabSize: size})
+			defer cache.Close()
+			keys := make([]string, 1024)
+			for i := range keys {
+				keys[i] = "churn:" + strconv.Itoa(i)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				cache.Set(key, true)
+				cache.Delete(key)
+			}
+		})
+	}
+}
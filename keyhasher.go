@@ -0,0 +1,143 @@
+// keyhasher.go: custom key hashing and integer-key fast paths
+//
+// Config.KeyHasher replaces the default key hash; GenericCache hashes
+// integer keys from their value.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+// hashKey returns the table and sketch hash of key: Config.KeyHasher if set,
+// stringHash otherwise.
+func (c *wtinyLFUCache) hashKey(key string) uint64 {
+	if c.keyHasher != nil {
+		return c.keyHasher(key)
+	}
+	return stringHash(key)
+}
+
+// HashableKey is the set of key types whose hash can be customized: the
+// types whose string form the cache can parse back, so that every path into
+// the table (typed or string-keyed) computes the same hash.
+type HashableKey interface {
+	string | int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64
+}
+
+// KeyHasherFor adapts a typed key hasher for use as Config.KeyHasher with a
+// GenericCache[K, V]. Key strings that are not a valid K (only possible for
+// keys written through the string-keyed API) use the default hash.
+// Prefer NewGenericCacheWithHasher, which also runs hasher on Get and Has
+// without parsing the key.
+func KeyHasherFor[K HashableKey](hasher func(key K) uint64) func(key string) uint64 {
+	return func(s string) uint64 {
+		if key, ok := keyFromString[K](s); ok {
+			return hasher(key)
+		}
+		return stringHash(s)
+	}
+}
+
+// NewGenericCacheWithHasher creates a type-safe generic cache whose keys are
+// hashed by hasher. It overrides cfg.KeyHasher.
+//
+// hasher must be deterministic and spread keys over all 64 bits: the low bits
+// select the table slot. Equal keys must hash equally; unequal keys may
+// collide (at a cost in probing).
+func NewGenericCacheWithHasher[K HashableKey, V any](cfg Config, hasher func(key K) uint64) *GenericCache[K, V] {
+	if hasher == nil {
+		return NewGenericCache[K, V](cfg)
+	}
+	cfg.KeyHasher = KeyHasherFor(hasher)
+	return newGenericCacheHashed[K, V](cfg, hasher)
+}
+
+// newGenericCacheHashed returns a GenericCache whose read paths hash keys
+// with hasher, which must agree with cfg.KeyHasher.
+func newGenericCacheHashed[K comparable, V any](cfg Config, hasher func(key K) uint64) *GenericCache[K, V] {
+	inner := NewCache(cfg)
+	c := &GenericCache[K, V]{inner: extend(inner)}
+	if core, ok := inner.(*wtinyLFUCache); ok {
+		c.core = core
+		c.hash = hasher
+		c.intKey = isIntegerKey[K]()
+	}
+	return c
+}
+
+// integerKeyHasher returns the default typed hasher for integer key types,
+// or nil for other types. The string form of K is parsed back by the
+// KeyHasherFor adapter it is paired with.
+func integerKeyHasher[K comparable]() (hasher func(key K) uint64, adapter func(key string) uint64) {
+	var zero K
+	switch any(zero).(type) {
+	case int:
+		return integerHasherPair[K, int]()
+	case int8:
+		return integerHasherPair[K, int8]()
+	case int16:
+		return integerHasherPair[K, int16]()
+	case int32:
+		return integerHasherPair[K, int32]()
+	case int64:
+		return integerHasherPair[K, int64]()
+	case uint:
+		return integerHasherPair[K, uint]()
+	case uint8:
+		return integerHasherPair[K, uint8]()
+	case uint16:
+		return integerHasherPair[K, uint16]()
+	case uint32:
+		return integerHasherPair[K, uint32]()
+	case uint64:
+		return integerHasherPair[K, uint64]()
+	default:
+		return nil, nil
+	}
+}
+
+// integerHasherPair returns the typed hasher of K, known to be the integer
+// type I, and its string adapter.
+func integerHasherPair[K comparable, I HashableKey]() (func(key K) uint64, func(key string) uint64) {
+	return func(key K) uint64 { return hashUint64(integerKeyBits(key)) },
+		KeyHasherFor(func(key I) uint64 { return hashUint64(integerKeyBits(key)) })
+}
+
+// integerKeyBits returns the bits of an integer key, sign-extended for
+// signed types so that every type maps a value to the same bits.
+func integerKeyBits[K comparable](key K) uint64 {
+	switch v := any(key).(type) {
+	case int:
+		return uint64(v) // #nosec G115 -- bit reinterpretation
+	case int8:
+		return uint64(v) // #nosec G115 -- bit reinterpretation
+	case int16:
+		return uint64(v) // #nosec G115 -- bit reinterpretation
+	case int32:
+		return uint64(v) // #nosec G115 -- bit reinterpretation
+	case int64:
+		return uint64(v) // #nosec G115 -- bit reinterpretation
+	case uint:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// hashUint64 is the SplitMix64 finalizer: a bijection of the 64-bit integers
+// in which every input bit affects every output bit.
+func hashUint64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
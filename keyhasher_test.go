@@ -0,0 +1,152 @@
+// keyhasher_test.go: tests for custom key hashing and integer-key hashing
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestKeyHasher_IntegerKeysHashedByValue(t *testing.T) {
+	cache := NewGenericCache[int64, string](Config{MaxSize: 100})
+	if cache.hash == nil {
+		t.Fatal("expected the integer fast path for int64 keys")
+	}
+
+	cache.Set(-42, "a")
+	cache.Set(1<<40, "b")
+	if v, ok := cache.Get(-42); !ok || v != "a" {
+		t.Fatalf("Get(-42) = %q, %v", v, ok)
+	}
+	if !cache.Has(1 << 40) {
+		t.Fatal("Has(1<<40) = false")
+	}
+
+	e := cache.core.findEntry("-42", hashUint64(integerKeyBits(int64(-42))))
+	if e == nil {
+		t.Fatal("-42 is not stored under the hash of its value")
+	}
+
+	// The string-keyed view reaches the same entries
+	if v, ok := cache.inner.Get("-42"); !ok || v != "a" {
+		t.Fatalf("inner Get(\"-42\") = %v, %v", v, ok)
+	}
+	cache.inner.Set("7", "c")
+	if v, ok := cache.Get(7); !ok || v != "c" {
+		t.Fatalf("Get(7) after a string-keyed Set = %q, %v", v, ok)
+	}
+
+	cache.Delete(-42)
+	if cache.Has(-42) {
+		t.Fatal("-42 still present after Delete")
+	}
+}
+
+func TestKeyHasher_AllIntegerTypes(t *testing.T) {
+	if integerKeyBits(int8(-1)) != integerKeyBits(int64(-1)) {
+		t.Fatal("signed keys are not sign-extended")
+	}
+	checkIntegerKeys[int](t)
+	checkIntegerKeys[int8](t)
+	checkIntegerKeys[int16](t)
+	checkIntegerKeys[int32](t)
+	checkIntegerKeys[uint](t)
+	checkIntegerKeys[uint8](t)
+	checkIntegerKeys[uint16](t)
+	checkIntegerKeys[uint32](t)
+	checkIntegerKeys[uint64](t)
+
+	if NewGenericCache[string, int](Config{MaxSize: 10}).hash != nil {
+		t.Fatal("string keys must keep the default path")
+	}
+}
+
+// integerKey is the integer subset of HashableKey.
+type integerKey interface {
+	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64
+}
+
+func checkIntegerKeys[K integerKey](t *testing.T) {
+	t.Helper()
+	cache := NewGenericCache[K, int](Config{MaxSize: 100})
+	if cache.hash == nil {
+		t.Fatalf("%T: expected the integer fast path", *new(K))
+	}
+	for i := 0; i < 50; i++ {
+		cache.Set(K(i), i)
+	}
+	for i := 0; i < 50; i++ {
+		if v, ok := cache.Get(K(i)); !ok || v != i {
+			t.Fatalf("%T: Get(%d) = %d, %v", *new(K), i, v, ok)
+		}
+	}
+}
+
+func TestKeyHasher_CustomTypedHasher(t *testing.T) {
+	var calls int64
+	hasher := func(id uint64) uint64 {
+		atomic.AddInt64(&calls, 1)
+		return hashUint64(id ^ 0xabcdef)
+	}
+	cache := NewGenericCacheWithHasher[uint64, string](Config{MaxSize: 100}, hasher)
+
+	cache.Set(1, "one") // Hashed by the core, through the string adapter
+	if atomic.LoadInt64(&calls) == 0 {
+		t.Fatal("Set did not use the hasher")
+	}
+	before := atomic.LoadInt64(&calls)
+	if v, ok := cache.Get(1); !ok || v != "one" {
+		t.Fatalf("Get(1) = %q, %v", v, ok)
+	}
+	if atomic.LoadInt64(&calls) != before+1 {
+		t.Fatalf("Get called the hasher %d times, want 1", atomic.LoadInt64(&calls)-before)
+	}
+	if cache.core.findEntry("1", hasher(1)) == nil {
+		t.Fatal("1 is not stored under the custom hash")
+	}
+}
+
+func TestKeyHasher_ConfigKeyHasher(t *testing.T) {
+	// Every key collides: lookups must still compare keys
+	cache := NewCache(Config{MaxSize: 100, KeyHasher: func(string) uint64 { return 7 }})
+	for i := 0; i < 10; i++ {
+		if !cache.Set("k"+strconv.Itoa(i), i) {
+			t.Fatalf("Set(k%d) failed", i)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if v, ok := cache.Get("k" + strconv.Itoa(i)); !ok || v != i {
+			t.Fatalf("Get(k%d) = %v, %v", i, v, ok)
+		}
+	}
+	if !cache.Delete("k3") || cache.Has("k3") {
+		t.Fatal("k3 not deleted")
+	}
+}
+
+func TestKeyHasherFor_FallsBackOnInvalidKeys(t *testing.T) {
+	h := KeyHasherFor(func(k int) uint64 { return uint64(k) }) // #nosec G115 -- test values are positive
+	if h("12") != 12 {
+		t.Fatalf("h(\"12\") = %d, want 12", h("12"))
+	}
+	if h("user:1") != stringHash("user:1") {
+		t.Fatal("an invalid key must use the default hash")
+	}
+}
+
+func TestKeyHasher_SequentialKeysSpread(t *testing.T) {
+	const n, mask = 1024, 2047
+	slots := make(map[uint64]bool, n)
+	for i := uint64(0); i < n; i++ {
+		slots[hashUint64(i)&mask] = true
+	}
+	// Random placement of 1024 keys in 2048 slots fills ~787
+	if len(slots) < 700 {
+		t.Fatalf("sequential keys use only %d distinct slots", len(slots))
+	}
+}
@@ -0,0 +1,140 @@
+// keyof.go: collision-resistant composite keys for the string-keyed Cache
+//
+// KeyOf encodes each part with a type tag and its length, so distinct part
+// lists never produce the same key.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unsafe"
+)
+
+// KeyOf returns a composite cache key built from parts. Distinct part lists
+// (by value and by type) always produce distinct keys.
+//
+// Supported part types are strings, []byte, booleans, all integer and float
+// types, nil and fmt.Stringer; other types are formatted with %#v. Stringer
+// and %#v parts are keyed by their type name (%T) as well, so they only
+// collide for equal renderings of types with the same package and type name
+// (e.g. two packages named "model" imported from different paths).
+//
+// Example:
+//
+//	cache.Set(balios.KeyOf("user", tenantID, userID), user)
+//
+// To combine with namespaces, keep the namespace outside the encoding:
+//
+//	key := "tenant42" + balios.NamespaceSeparator + balios.KeyOf("user", userID)
+func KeyOf(parts ...interface{}) string {
+	size := 0
+	for _, part := range parts {
+		size += 4 + estimatePartLen(part)
+	}
+
+	buf := make([]byte, 0, size)
+	for _, part := range parts {
+		buf = appendKeyPart(buf, part)
+	}
+	if len(buf) == 0 {
+		return ""
+	}
+	// buf is never modified after this point: share it instead of copying
+	// #nosec G103 -- unsafe required for zero-copy string conversion
+	return unsafe.String(&buf[0], len(buf))
+}
+
+// estimatePartLen returns a size hint for the payload of part.
+func estimatePartLen(part interface{}) int {
+	switch v := part.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	default:
+		return 20
+	}
+}
+
+// appendKeyPart appends the tagged, length-prefixed encoding of part.
+func appendKeyPart(buf []byte, part interface{}) []byte {
+	var scratch [32]byte
+	switch v := part.(type) {
+	case nil:
+		return appendTagged(buf, 'n', nil)
+	case string:
+		buf = append(buf, 's')
+		buf = strconv.AppendInt(buf, int64(len(v)), 10)
+		buf = append(buf, ':')
+		return append(buf, v...)
+	case []byte:
+		return appendTagged(buf, 'x', v)
+	case bool:
+		return appendTagged(buf, 'b', strconv.AppendBool(scratch[:0], v))
+	case int:
+		return appendTagged(buf, 'i', strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int8:
+		return appendTagged(buf, 'i', strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int16:
+		return appendTagged(buf, 'i', strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int32:
+		return appendTagged(buf, 'i', strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int64:
+		return appendTagged(buf, 'i', strconv.AppendInt(scratch[:0], v, 10))
+	case uint:
+		return appendTagged(buf, 'u', strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint8:
+		return appendTagged(buf, 'u', strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint16:
+		return appendTagged(buf, 'u', strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint32:
+		return appendTagged(buf, 'u', strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint64:
+		return appendTagged(buf, 'u', strconv.AppendUint(scratch[:0], v, 10))
+	case uintptr:
+		return appendTagged(buf, 'u', strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case float32:
+		return appendTagged(buf, 'f', appendFloatPart(scratch[:0], float64(v), 32))
+	case float64:
+		return appendTagged(buf, 'f', appendFloatPart(scratch[:0], v, 64))
+	case fmt.Stringer:
+		return appendTyped(buf, 'S', v, v.String())
+	default:
+		return appendTyped(buf, 'v', v, fmt.Sprintf("%#v", v))
+	}
+}
+
+// appendTyped appends tag, the length-prefixed type name of part and the
+// length-prefixed payload.
+func appendTyped(buf []byte, tag byte, part interface{}, payload string) []byte {
+	buf = appendTagged(buf, tag, []byte(fmt.Sprintf("%T", part)))
+	buf = strconv.AppendInt(buf, int64(len(payload)), 10)
+	buf = append(buf, ':')
+	return append(buf, payload...)
+}
+
+// appendFloatPart formats f so that equal values always encode identically
+// (-0 is folded into 0; every NaN encodes the same).
+func appendFloatPart(dst []byte, f float64, bitSize int) []byte {
+	if f == 0 {
+		f = 0
+	}
+	if math.IsNaN(f) {
+		return append(dst, "NaN"...)
+	}
+	return strconv.AppendFloat(dst, f, 'g', -1, bitSize)
+}
+
+// appendTagged appends tag, the length of payload, ':' and payload.
+func appendTagged(buf []byte, tag byte, payload []byte) []byte {
+	buf = append(buf, tag)
+	buf = strconv.AppendInt(buf, int64(len(payload)), 10)
+	buf = append(buf, ':')
+	return append(buf, payload...)
+}
@@ -0,0 +1,127 @@
+// keyof_test.go: tests for KeyOf composite keys
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestKeyOf_Encoding(t *testing.T) {
+	tests := []struct {
+		parts []interface{}
+		want  string
+	}{
+		{nil, ""},
+		{[]interface{}{"ab", 7}, "s2:abi1:7"},
+		{[]interface{}{""}, "s0:"},
+		{[]interface{}{nil, true, uint8(3)}, "n0:b4:trueu1:3"},
+		{[]interface{}{[]byte("xy"), 1.5}, "x2:xyf3:1.5"},
+		{[]interface{}{time.Second}, "S13:time.Duration2:1s"},
+		{[]interface{}{struct{ A int }{1}}, "v16:struct { A int }21:struct { A int }{A:1}"},
+	}
+	for _, tt := range tests {
+		if got := KeyOf(tt.parts...); got != tt.want {
+			t.Errorf("KeyOf(%v) = %q, want %q", tt.parts, got, tt.want)
+		}
+	}
+}
+
+func TestKeyOf_NoCollisions(t *testing.T) {
+	cases := [][]interface{}{
+		{"a", "bc"},
+		{"ab", "c"},
+		{"abc"},
+		{"a:b", "c"},
+		{"a", "b:c"},
+		{"s1:a"},
+		{"7"},
+		{7},
+		{uint(7)},
+		{7.0},
+		{"", ""},
+		{""},
+		{},
+		{nil},
+		{[]byte("7")},
+	}
+	seen := make(map[string]int)
+	for i, parts := range cases {
+		key := KeyOf(parts...)
+		if j, dup := seen[key]; dup {
+			t.Errorf("collision between %v and %v: %q", cases[j], parts, key)
+		}
+		seen[key] = i
+	}
+}
+
+// keyAlias is a Stringer rendering as the %#v of another type.
+type keyAlias string
+
+func (k keyAlias) String() string { return "balios.keyPoint{X:1, Y:2}" }
+
+// keyPoint is a struct part formatted with %#v.
+type keyPoint struct{ X, Y int }
+
+// keyLabel is a Stringer of another type with the same rendering as keyAlias.
+type keyLabel int
+
+func (keyLabel) String() string { return "balios.keyPoint{X:1, Y:2}" }
+
+func TestKeyOf_StringerAndFallbackCollisions(t *testing.T) {
+	cases := [][]interface{}{
+		{keyPoint{1, 2}},
+		{keyAlias("p")},
+		{keyLabel(1)},
+		{"balios.keyPoint{X:1, Y:2}"},
+	}
+	seen := make(map[string]int)
+	for i, parts := range cases {
+		key := KeyOf(parts...)
+		if j, dup := seen[key]; dup {
+			t.Errorf("collision between %#v and %#v: %q", cases[j], parts, key)
+		}
+		seen[key] = i
+	}
+}
+
+func TestKeyOf_Deterministic(t *testing.T) {
+	if KeyOf(int32(5)) != KeyOf(int64(5)) {
+		t.Error("integers of the same value must encode identically regardless of width")
+	}
+	if KeyOf(math.Copysign(0, -1)) != KeyOf(0.0) {
+		t.Error("-0 and 0 must encode identically")
+	}
+	if KeyOf(math.NaN()) != KeyOf(math.NaN()) {
+		t.Error("NaN must encode deterministically")
+	}
+
+	type point struct{ X, Y int }
+	if KeyOf(point{1, 2}) != KeyOf(point{1, 2}) || KeyOf(point{1, 2}) == KeyOf(point{2, 1}) {
+		t.Error("struct parts must encode by value")
+	}
+}
+
+func TestKeyOf_WithCache(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+	cache.Set(KeyOf("a", "bc"), 1)
+	cache.Set(KeyOf("ab", "c"), 2)
+	if v, _ := cache.Get(KeyOf("a", "bc")); v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+	if v, _ := cache.Get(KeyOf("ab", "c")); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+}
+
+func BenchmarkKeyOf(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = KeyOf("user", 42, "profile")
+	}
+}
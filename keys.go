@@ -0,0 +1,83 @@
+// keys.go: key listing and bulk invalidation by prefix or predicate
+//
+// Keys lists the live keys; DeleteByPrefix and DeleteFunc remove the
+// entries matching a prefix or a predicate.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "strings"
+
+// Keys returns the keys of the live entries, in no particular order.
+func (c *wtinyLFUCache) Keys() []string {
+	size := c.entryCount() // Not Len: internal calls are not probe metrics
+	if size < 0 {
+		size = 0
+	}
+	keys := make([]string, 0, size)
+	c.Range(func(key string, _ interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// DeleteByPrefix removes every entry whose key starts with prefix and returns
+// the number of entries removed. An empty prefix removes nothing (use Clear).
+func (c *wtinyLFUCache) DeleteByPrefix(prefix string) int {
+	if prefix == "" {
+		return 0
+	}
+	if ns := namespaceOf(prefix); ns != "" && prefix == ns+NamespaceSeparator {
+		return c.ClearNamespace(ns)
+	}
+	return c.DeleteFunc(func(key string, _ interface{}) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// DeleteFunc removes every live entry for which f returns true and returns
+// the number of entries removed. f may call other cache methods.
+func (c *wtinyLFUCache) DeleteFunc(f func(key string, value interface{}) bool) int {
+	removed := 0
+	c.Range(func(key string, value interface{}) bool {
+		if f(key, value) && c.Delete(key) {
+			removed++
+		}
+		return true
+	})
+	return removed
+}
+
+// Keys returns the keys of the live entries. Keys that cannot be converted
+// back to K (see keyFromString) are skipped.
+func (c *GenericCache[K, V]) Keys() []K {
+	var keys []K
+	c.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// DeleteByPrefix removes every entry whose key, in its string form, starts
+// with prefix and returns the number of entries removed.
+func (c *GenericCache[K, V]) DeleteByPrefix(prefix string) int {
+	return c.inner.DeleteByPrefix(prefix)
+}
+
+// DeleteFunc removes every live entry for which f returns true and returns
+// the number of entries removed. Entries skipped by Range are never removed.
+func (c *GenericCache[K, V]) DeleteFunc(f func(key K, value V) bool) int {
+	return c.inner.DeleteFunc(func(keyStr string, value interface{}) bool {
+		key, ok := keyFromString[K](keyStr)
+		if !ok {
+			return false
+		}
+		typed, ok := value.(V)
+		return ok && f(key, typed)
+	})
+}
@@ -0,0 +1,97 @@
+// keys_test.go: tests for Keys, DeleteByPrefix and DeleteFunc
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	if keys := cache.Keys(); len(keys) != 0 {
+		t.Errorf("empty cache keys = %v", keys)
+	}
+	cache.Set("b", 2)
+	cache.Set("a", 1)
+	cache.Set("c", 3)
+	cache.Delete("c")
+
+	keys := cache.Keys()
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[a b]" {
+		t.Errorf("expected [a b], got %v", keys)
+	}
+}
+
+func TestDeleteByPrefix(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("indexed=%v", indexed), func(t *testing.T) {
+			cache := NewCache(Config{MaxSize: 100, IndexNamespaces: indexed}).(*wtinyLFUCache)
+			for i := 0; i < 5; i++ {
+				cache.Set(fmt.Sprintf("tenant:1:user:%d", i), i)
+				cache.Set(fmt.Sprintf("tenant:2:user:%d", i), i)
+				cache.Set(fmt.Sprintf("other:%d", i), i)
+			}
+			cache.Set("tenant:10:user:0", 0)
+
+			if n := cache.DeleteByPrefix("tenant:1:"); n != 5 {
+				t.Errorf("expected 5 entries removed, got %d", n)
+			}
+			if !cache.Has("tenant:10:user:0") || !cache.Has("tenant:2:user:3") {
+				t.Error("entries outside the prefix must survive")
+			}
+			if n := cache.DeleteByPrefix("other:"); n != 5 {
+				t.Errorf("namespace prefix: expected 5 entries removed, got %d", n)
+			}
+			if n := cache.DeleteByPrefix(""); n != 0 || cache.Len() != 6 {
+				t.Errorf("an empty prefix must remove nothing: removed %d, %d left", n, cache.Len())
+			}
+		})
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	var removed []string
+	cache := NewCache(Config{MaxSize: 100, OnEvict: func(key string, _ interface{}, reason EvictReason) {
+		if reason == ReasonDeleted {
+			removed = append(removed, key)
+		}
+	}}).(*wtinyLFUCache)
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	deletes := cache.Stats().Deletes
+
+	n := cache.DeleteFunc(func(_ string, value interface{}) bool { return value.(int) >= 7 })
+	if n != 3 || cache.Len() != 7 {
+		t.Errorf("expected 3 removed and 7 left, got %d and %d", n, cache.Len())
+	}
+	if cache.Stats().Deletes != deletes+3 || len(removed) != 3 {
+		t.Errorf("removals must be reported like Delete: deletes=%d events=%v", cache.Stats().Deletes-deletes, removed)
+	}
+}
+
+func TestKeys_Generic(t *testing.T) {
+	cache := NewGenericCache[uint16, string](Config{MaxSize: 100})
+	for i := uint16(0); i < 6; i++ {
+		cache.Set(i, fmt.Sprint(i))
+	}
+
+	keys := cache.Keys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	if fmt.Sprint(keys) != "[0 1 2 3 4 5]" {
+		t.Errorf("keys = %v", keys)
+	}
+	if n := cache.DeleteFunc(func(key uint16, _ string) bool { return key%2 == 0 }); n != 3 {
+		t.Errorf("expected 3 removed, got %d", n)
+	}
+	if n := cache.DeleteByPrefix("1"); n != 1 || cache.Has(1) {
+		t.Errorf("DeleteByPrefix(\"1\") removed %d", n)
+	}
+}
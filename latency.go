@@ -0,0 +1,159 @@
+// latency.go: built-in latency percentiles (Config.TrackLatency)
+//
+// With Config.TrackLatency, Stats reports the 50th, 95th and 99th
+// percentiles of Get and Set latency.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// latencySubBucketBits is the log2 of the number of buckets per power of two.
+const latencySubBucketBits = 3
+
+// latencySubBuckets is the number of linear buckets per power of two.
+const latencySubBuckets = 1 << latencySubBucketBits
+
+// latencyBuckets is the number of buckets covering [0, math.MaxInt64] ns.
+const latencyBuckets = (64 - latencySubBucketBits) * latencySubBuckets
+
+// latencyQuantiles are the percentiles reported by Stats, in ascending order.
+var latencyQuantiles = [...]float64{0.50, 0.95, 0.99}
+
+// OpLatency is one latency percentile of Get and Set operations.
+type OpLatency struct {
+	// Get covers the reads counted in Hits and Misses
+	Get time.Duration
+
+	// Set covers the writes counted in Sets
+	Set time.Duration
+}
+
+// latencyHistogram counts latencies in log-linear buckets.
+type latencyHistogram struct {
+	counts [latencyBuckets]int64
+}
+
+// latencyHistograms holds the histograms of Config.TrackLatency.
+type latencyHistograms struct {
+	get latencyHistogram
+	set latencyHistogram
+}
+
+// latencyBucket returns the bucket counting a latency of ns nanoseconds.
+func latencyBucket(ns int64) int {
+	if ns < latencySubBuckets {
+		return int(max(ns, 0))
+	}
+	shift := bits.Len64(uint64(ns)) - latencySubBucketBits - 1
+	return shift*latencySubBuckets + int(uint64(ns)>>shift) // #nosec G115 -- ns>>shift < 2*latencySubBuckets
+}
+
+// latencyBucketMax returns the largest latency counted in bucket i.
+func latencyBucketMax(i int) int64 {
+	if i < latencySubBuckets {
+		return int64(i)
+	}
+	shift := i/latencySubBuckets - 1
+	upper := uint64(i%latencySubBuckets+latencySubBuckets+1) << shift // #nosec G115 -- i is a bucket index
+	return int64(upper - 1)                                           // #nosec G115 -- at most math.MaxInt64
+}
+
+// record counts a latency of ns nanoseconds.
+func (h *latencyHistogram) record(ns int64) {
+	atomic.AddInt64(&h.counts[latencyBucket(ns)], 1)
+}
+
+// percentiles returns the latencies at latencyQuantiles, from one read of
+// the buckets. All are 0 when nothing was recorded.
+func (h *latencyHistogram) percentiles() [len(latencyQuantiles)]time.Duration {
+	var counts [latencyBuckets]int64
+	var total int64
+	for i := range counts {
+		counts[i] = atomic.LoadInt64(&h.counts[i])
+		total += counts[i]
+	}
+	var result [len(latencyQuantiles)]time.Duration
+	if total == 0 {
+		return result
+	}
+	q, seen := 0, int64(0)
+	for i := 0; i < latencyBuckets && q < len(latencyQuantiles); i++ {
+		seen += counts[i]
+		for q < len(latencyQuantiles) && float64(seen) >= latencyQuantiles[q]*float64(total) {
+			result[q] = time.Duration(latencyBucketMax(i))
+			q++
+		}
+	}
+	return result
+}
+
+// reset zeroes every bucket.
+func (h *latencyHistogram) reset() {
+	for i := range h.counts {
+		atomic.StoreInt64(&h.counts[i], 0)
+	}
+}
+
+// latencyNow reads the clock latencies are measured with.
+func (c *wtinyLFUCache) latencyNow() int64 {
+	if c.latencyClock != nil {
+		return c.latencyClock.Now()
+	}
+	return c.timeProvider.Now()
+}
+
+// latencyStart returns the start, on the latency clock, of an operation that
+// read now from Config.TimeProvider: now itself unless latencies have their
+// own clock.
+func (c *wtinyLFUCache) latencyStart(now int64) int64 {
+	if c.latencyClock != nil {
+		return c.latencyClock.Now()
+	}
+	return now
+}
+
+// recordGetLatency records the latency of a read started at start.
+func (c *wtinyLFUCache) recordGetLatency(start int64) {
+	c.latency.get.record(c.latencyNow() - start)
+}
+
+// recordSetMetrics reports a successful write of key started at start (see
+// latencyStart) to the latency histograms and the MetricsCollector.
+func (c *wtinyLFUCache) recordSetMetrics(key string, start int64) {
+	if c.latency != nil {
+		c.latency.set.record(c.latencyNow() - start)
+	}
+	if c.metricsCollector != nil {
+		c.metricsCollector.RecordSet(c.latencySince(start))
+	}
+	if c.keySampleEvery > 0 {
+		c.sampleKey(ProbeSet, key, start)
+	}
+}
+
+// resetLatency zeroes the latency histograms, if any.
+func (c *wtinyLFUCache) resetLatency() {
+	if c.latency != nil {
+		c.latency.get.reset()
+		c.latency.set.reset()
+	}
+}
+
+// readLatency fills the latency percentiles of stats.
+func (c *wtinyLFUCache) readLatency(stats *CacheStats) {
+	if c.latency == nil {
+		return
+	}
+	get, set := c.latency.get.percentiles(), c.latency.set.percentiles()
+	stats.LatencyP50 = OpLatency{Get: get[0], Set: set[0]}
+	stats.LatencyP95 = OpLatency{Get: get[1], Set: set[1]}
+	stats.LatencyP99 = OpLatency{Get: get[2], Set: set[2]}
+}
@@ -0,0 +1,144 @@
+// latency_test.go: tests for Config.TrackLatency
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"encoding/json"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// steppingTimeProvider advances by step on every reading, so each operation
+// lasts a multiple of step.
+type steppingTimeProvider struct {
+	now  int64
+	step int64
+}
+
+func (p *steppingTimeProvider) Now() int64 {
+	return atomic.AddInt64(&p.now, p.step)
+}
+
+func TestLatency_Buckets(t *testing.T) {
+	values := []int64{0, 1, 7, 8, 9, 15, 16, 17, 100, 999, 1000, 12345, 1 << 20, 1<<40 + 12345, math.MaxInt64}
+	for _, v := range values {
+		i := latencyBucket(v)
+		if i < 0 || i >= latencyBuckets {
+			t.Fatalf("latencyBucket(%d) = %d, out of range", v, i)
+		}
+		upper := latencyBucketMax(i)
+		lower := int64(0)
+		if i > 0 {
+			lower = latencyBucketMax(i-1) + 1
+		}
+		if v < lower || v > upper {
+			t.Fatalf("%d counted in bucket %d = [%d, %d]", v, i, lower, upper)
+		}
+		if lower >= latencySubBuckets && (upper-lower+1)*8 > lower {
+			t.Fatalf("bucket %d = [%d, %d] wider than 12.5%%", i, lower, upper)
+		}
+	}
+	if latencyBucket(-5) != 0 {
+		t.Fatal("negative latencies (clock regressions) must count as 0")
+	}
+	if latencyBucketMax(latencyBuckets-1) != math.MaxInt64 {
+		t.Fatal("the last bucket must end at math.MaxInt64")
+	}
+}
+
+func TestLatency_Percentiles(t *testing.T) {
+	var h latencyHistogram
+	if p := h.percentiles(); p[0] != 0 || p[2] != 0 {
+		t.Fatalf("empty histogram percentiles = %v, want zeros", p)
+	}
+	for i := 0; i < 90; i++ {
+		h.record(100)
+	}
+	for i := 0; i < 9; i++ {
+		h.record(10_000)
+	}
+	h.record(1_000_000)
+
+	p := h.percentiles()
+	within := func(got time.Duration, want int64) bool {
+		return int64(got) >= want && float64(got) <= 1.125*float64(want)
+	}
+	if !within(p[0], 100) || !within(p[1], 10_000) || !within(p[2], 10_000) {
+		t.Fatalf("percentiles = %v, want ~100ns, ~10µs, ~10µs", p)
+	}
+	h.record(1_000_000)
+	if p := h.percentiles(); !within(p[2], 1_000_000) {
+		t.Fatalf("p99 = %v, want ~1ms once 2%% of the operations take 1ms", p[2])
+	}
+}
+
+func TestLatency_Stats(t *testing.T) {
+	clock := &steppingTimeProvider{now: 1_000_000_000, step: 1000}
+	cache := NewCache(Config{MaxSize: 100, TrackLatency: true, TimeProvider: clock})
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		cache.Set("k", i)
+		cache.Get("k")
+		cache.Get("missing")
+	}
+	stats := cache.Stats()
+	if stats.LatencyP50.Get < time.Microsecond || stats.LatencyP50.Set < time.Microsecond {
+		t.Fatalf("LatencyP50 = %+v, want at least one clock step", stats.LatencyP50)
+	}
+	if stats.LatencyP50.Get > stats.LatencyP95.Get || stats.LatencyP95.Get > stats.LatencyP99.Get {
+		t.Fatalf("percentiles out of order: %v %v %v", stats.LatencyP50, stats.LatencyP95, stats.LatencyP99)
+	}
+
+	data, err := json.Marshal(NewStatsReport(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report map[string]interface{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report["get_latency_p99_ns"] == nil || report["set_latency_p50_ns"] == nil {
+		t.Fatalf("StatsReport is missing the latencies: %s", data)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats.LatencyP99 != (OpLatency{}) {
+		t.Fatalf("LatencyP99 = %+v after Clear, want zero", stats.LatencyP99)
+	}
+}
+
+func TestLatency_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	cache.Get("k")
+	if stats := cache.Stats(); stats.LatencyP50 != (OpLatency{}) || stats.LatencyP99 != (OpLatency{}) {
+		t.Fatalf("latencies must be zero without TrackLatency, got %+v", stats)
+	}
+	if _, cached := cache.(*wtinyLFUCache).timeProvider.(*systemTimeProvider); !cached {
+		t.Fatal("the default clock must be kept without TrackLatency")
+	}
+
+	tracked := NewCache(Config{MaxSize: 100, TrackLatency: true}).(*wtinyLFUCache)
+	defer tracked.Close()
+	if _, cached := tracked.timeProvider.(*systemTimeProvider); !cached {
+		t.Fatal("TrackLatency must keep the cached default clock for expiration")
+	}
+	if _, monotonic := tracked.latencyClock.(*MonotonicTimeProvider); !monotonic {
+		t.Fatal("TrackLatency must time operations with a monotonic clock")
+	}
+
+	explicit := NewCache(Config{MaxSize: 100, TrackLatency: true, TimeProvider: &MockTimeProvider{}}).(*wtinyLFUCache)
+	defer explicit.Close()
+	if explicit.latencyClock != nil {
+		t.Fatal("an explicit TimeProvider must time latencies as well")
+	}
+}
@@ -0,0 +1,83 @@
+// load_cost.go: cost-aware admission of loaded values
+//
+// With Config.MinLoadCost, loaded values cheaper to recompute than the
+// threshold are returned but not cached (see ReportLoadCost).
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// loadCostKey is the context key of the loadCost recorder.
+type loadCostKey struct{}
+
+// loadCost receives an explicit cost reported by a loader.
+type loadCost struct {
+	nanos    int64
+	reported int32
+}
+
+// ReportLoadCost reports the recomputation cost of the value being loaded,
+// overriding the measured load duration for admission (see Config.MinLoadCost).
+// It must be called with the context received by a GetOrLoadWithContext
+// loader; it is a no-op for any other context or when MinLoadCost is not set.
+//
+// Example:
+//
+//	v, err := cache.GetOrLoadWithContext(ctx, key, func(ctx context.Context) (interface{}, error) {
+//	    rows, plan := db.Query(ctx, q)
+//	    balios.ReportLoadCost(ctx, plan.EstimatedDuration)
+//	    return rows, nil
+//	})
+func ReportLoadCost(ctx context.Context, cost time.Duration) {
+	if rec, ok := ctx.Value(loadCostKey{}).(*loadCost); ok {
+		atomic.StoreInt64(&rec.nanos, int64(cost))
+		atomic.StoreInt32(&rec.reported, 1)
+	}
+}
+
+// withLoadCost returns ctx carrying a fresh cost recorder, or ctx unchanged
+// when cost-aware admission is disabled.
+func (c *wtinyLFUCache) withLoadCost(ctx context.Context) (context.Context, *loadCost) {
+	if c.minLoadCostNanos == 0 {
+		return ctx, nil
+	}
+	rec := &loadCost{}
+	return context.WithValue(ctx, loadCostKey{}, rec), rec
+}
+
+// admitLoad reports whether a value loaded since start (with optional
+// explicit cost rec) is expensive enough to be cached.
+func (c *wtinyLFUCache) admitLoad(start int64, rec *loadCost) bool {
+	if c.minLoadCostNanos == 0 {
+		return true
+	}
+	cost := c.timeProvider.Now() - start
+	if rec != nil && atomic.LoadInt32(&rec.reported) != 0 {
+		cost = atomic.LoadInt64(&rec.nanos)
+	}
+	if cost >= c.minLoadCostNanos {
+		return true
+	}
+	atomic.AddInt64(&c.loadsNotAdmitted, 1)
+	return false
+}
+
+// LoadsNotAdmitted returns the number of loaded values not cached because
+// their cost was below Config.MinLoadCost.
+func (c *wtinyLFUCache) LoadsNotAdmitted() int64 {
+	return atomic.LoadInt64(&c.loadsNotAdmitted)
+}
+
+// LoadsNotAdmitted returns the number of loaded values not cached because
+// their cost was below Config.MinLoadCost.
+func (c *GenericCache[K, V]) LoadsNotAdmitted() int64 {
+	return c.inner.LoadsNotAdmitted()
+}
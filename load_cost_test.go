@@ -0,0 +1,86 @@
+// load_cost_test.go: tests for cost-aware admission of loaded values
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMinLoadCost_MeasuredDuration(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, MinLoadCost: 10 * time.Millisecond, TimeProvider: mockTime}).(*wtinyLFUCache)
+
+	cheap, err := cache.GetOrLoad("cheap", func() (interface{}, error) {
+		mockTime.Advance(time.Millisecond)
+		return "cheap", nil
+	})
+	if err != nil || cheap != "cheap" {
+		t.Fatalf("cheap load must still return its value: %v, %v", cheap, err)
+	}
+	if cache.Has("cheap") {
+		t.Error("cheap load must not be cached")
+	}
+
+	_, _ = cache.GetOrLoad("expensive", func() (interface{}, error) {
+		mockTime.Advance(50 * time.Millisecond)
+		return "expensive", nil
+	})
+	if !cache.Has("expensive") {
+		t.Error("expensive load must be cached")
+	}
+	if n := cache.LoadsNotAdmitted(); n != 1 {
+		t.Errorf("expected 1 load not admitted, got %d", n)
+	}
+}
+
+func TestMinLoadCost_ReportedCost(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, MinLoadCost: time.Second, TimeProvider: mockTime})
+	ctx := context.Background()
+
+	// Fast in wall time, but reported as expensive to recompute
+	_, _ = cache.GetOrLoadWithContext(ctx, "estimated", func(ctx context.Context) (int, error) {
+		ReportLoadCost(ctx, 5*time.Second)
+		return 1, nil
+	})
+	if !cache.Has("estimated") {
+		t.Error("value with reported high cost must be cached")
+	}
+
+	// Slow in wall time, but reported as cheap (e.g. queued on a pool)
+	_, _ = cache.GetOrLoadWithContext(ctx, "queued", func(ctx context.Context) (int, error) {
+		mockTime.Advance(10 * time.Second)
+		ReportLoadCost(ctx, time.Millisecond)
+		return 2, nil
+	})
+	if cache.Has("queued") {
+		t.Error("reported cost must override the measured duration")
+	}
+
+	_, _ = cache.GetOrLoadWithContext(ctx, "measured", func(ctx context.Context) (int, error) {
+		mockTime.Advance(2 * time.Second)
+		return 3, nil
+	})
+	if !cache.Has("measured") {
+		t.Error("measured duration must be used when no cost is reported")
+	}
+}
+
+func TestMinLoadCost_DisabledByDefault(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	_, _ = cache.GetOrLoadWithContext(context.Background(), "k", func(ctx context.Context) (interface{}, error) {
+		ReportLoadCost(ctx, 0) // No-op without MinLoadCost
+		return 1, nil
+	})
+	if !cache.Has("k") || cache.LoadsNotAdmitted() != 0 {
+		t.Error("every load must be cached when MinLoadCost is not set")
+	}
+	ReportLoadCost(context.Background(), time.Second) // Must not panic outside a loader
+}
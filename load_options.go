@@ -0,0 +1,131 @@
+// load_options.go: per-call options for GetOrLoad and GetOrLoadWithContext
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// maxLoadPriority is the largest useful priority: sketch counters saturate at 15.
+const maxLoadPriority = 15
+
+// LoadOption customizes a single GetOrLoad or GetOrLoadWithContext call.
+type LoadOption func(*loadOptions)
+
+// loadOptions holds the per-call settings collected from LoadOption values.
+type loadOptions struct {
+	ttl          time.Duration
+	refreshTTL   time.Duration
+	skipNegative bool
+	priority     int
+	tags         []string
+	timeout      time.Duration
+	retries      int
+	backoff      time.Duration
+}
+
+// applyLoadOptions collects opts into a loadOptions value.
+func applyLoadOptions(opts []LoadOption) loadOptions {
+	var o loadOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithTTL stores the loaded value with ttl instead of Config.TTL.
+// Non-positive values are ignored.
+func WithTTL(ttl time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		if ttl > 0 {
+			o.ttl = ttl
+		}
+	}
+}
+
+// WithSkipNegativeCache bypasses negative caching for this call: a cached
+// loader error is not returned, and a loader error is not cached.
+func WithSkipNegativeCache() LoadOption {
+	return func(o *loadOptions) {
+		o.skipNegative = true
+	}
+}
+
+// WithPriority makes the loaded entry harder to evict by crediting priority
+// extra accesses to its frequency estimate (capped at 15). Use it for values
+// that are expensive to recompute.
+func WithPriority(priority int) LoadOption {
+	return func(o *loadOptions) {
+		if priority > maxLoadPriority {
+			priority = maxLoadPriority
+		}
+		if priority > 0 {
+			o.priority = priority
+		}
+	}
+}
+
+// WithTags records tags as dependencies of the loaded entry, so that
+// InvalidateKey(tag) removes it (see SetWithDependencies).
+func WithTags(tags ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// raiseMaxTTL makes sure the expiration fast paths account for ttlNanos.
+func (c *wtinyLFUCache) raiseMaxTTL(ttlNanos int64) {
+	for {
+		current := atomic.LoadInt64(&c.maxTTLNanos)
+		if ttlNanos <= current || atomic.CompareAndSwapInt64(&c.maxTTLNanos, current, ttlNanos) {
+			return
+		}
+	}
+}
+
+// storeLoaded caches a loaded value according to the call's options and
+// writes it through to the SecondaryCache, if any.
+func (c *wtinyLFUCache) storeLoaded(key string, value interface{}, source string, o *loadOptions) {
+	if c.storeLocal(key, value, source, o) && c.secondary != nil {
+		c.writeSecondary(key, value, c.loadTTLNanos(o))
+	}
+}
+
+// storeLocal caches a value in this cache only, according to the call's
+// options. Returns true if the value was stored.
+func (c *wtinyLFUCache) storeLocal(key string, value interface{}, source string, o *loadOptions) bool {
+	ttlNanos := c.loadTTLNanos(o)
+	if o.ttl > 0 {
+		c.raiseMaxTTL(ttlNanos)
+	}
+
+	var stored bool
+	if len(o.tags) > 0 {
+		stored = c.setWithDependencies(key, value, source, ttlNanos, o.tags)
+	} else {
+		stored = c.set(key, value, source, ttlNanos)
+	}
+
+	if stored && o.priority > 0 {
+		keyHash := c.hashKey(key)
+		for i := 0; i < o.priority; i++ {
+			c.incrementFrequency(keyHash)
+		}
+	}
+	return stored
+}
+
+// loadTTLNanos returns the TTL of a value stored with options o.
+func (c *wtinyLFUCache) loadTTLNanos(o *loadOptions) int64 {
+	if o.ttl > 0 {
+		return int64(o.ttl)
+	}
+	return c.ttlNanos
+}
@@ -0,0 +1,141 @@
+// load_options_test.go: tests for GetOrLoad per-call options
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoadOptions_WithTTLOverridesDefault(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Hour, TimeProvider: mockTime})
+
+	load := func() (interface{}, error) { return "v", nil }
+	_, _ = cache.GetOrLoad("short", load, WithTTL(time.Second))
+	_, _ = cache.GetOrLoad("default", load)
+
+	mockTime.Advance(2 * time.Second)
+	if cache.Has("short") {
+		t.Error("entry loaded WithTTL(1s) must expire after 2s")
+	}
+	if !cache.Has("default") {
+		t.Error("entry loaded without options must keep the default TTL")
+	}
+}
+
+func TestLoadOptions_WithTTLOnCacheWithoutTTL(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TimeProvider: mockTime})
+
+	_, _ = cache.GetOrLoadWithContext(context.Background(), "k", func(context.Context) (interface{}, error) {
+		return "v", nil
+	}, WithTTL(time.Minute))
+	cache.Set("plain", "v")
+
+	mockTime.Advance(2 * time.Minute)
+	if cache.Has("k") {
+		t.Error("per-entry TTL must apply even when Config.TTL is zero")
+	}
+	if !cache.Has("plain") {
+		t.Error("plain Set must still never expire")
+	}
+	if n := cache.ExpireNow(); n != 0 {
+		t.Errorf("expected the expired entry to be already removed, ExpireNow returned %d", n)
+	}
+}
+
+func TestLoadOptions_WithSkipNegativeCache(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, NegativeCacheTTL: time.Hour})
+	boom := errors.New("boom")
+
+	calls := 0
+	failing := func() (interface{}, error) {
+		calls++
+		return nil, boom
+	}
+	_, _ = cache.GetOrLoad("k", failing)
+	_, _ = cache.GetOrLoad("k", failing)
+	if calls != 1 {
+		t.Fatalf("negative cache must absorb the second call, loader ran %d times", calls)
+	}
+
+	v, err := cache.GetOrLoad("k", func() (interface{}, error) { return "ok", nil }, WithSkipNegativeCache())
+	if err != nil || v != "ok" {
+		t.Fatalf("WithSkipNegativeCache must run the loader: %v, %v", v, err)
+	}
+
+	calls = 0
+	_, _ = cache.GetOrLoad("other", failing, WithSkipNegativeCache())
+	_, _ = cache.GetOrLoad("other", failing, WithSkipNegativeCache())
+	if calls != 2 {
+		t.Errorf("WithSkipNegativeCache must not cache errors, loader ran %d times", calls)
+	}
+}
+
+func TestLoadOptions_WithPriorityRaisesFrequency(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+	load := func() (interface{}, error) { return "v", nil }
+
+	_, _ = cache.GetOrLoad("normal", load)
+	_, _ = cache.GetOrLoad("important", load, WithPriority(100))
+
+	sketch := cache.(*wtinyLFUCache).table.Load().sketch
+	normal := sketch.estimate(stringHash("normal"))
+	important := sketch.estimate(stringHash("important"))
+	if important <= normal {
+		t.Errorf("priority must raise the frequency estimate: normal=%d important=%d", normal, important)
+	}
+	if important > maxLoadPriority {
+		t.Errorf("estimate %d exceeds counter saturation", important)
+	}
+}
+
+func TestLoadOptions_WithTagsInvalidates(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	load := func() (interface{}, error) { return "v", nil }
+
+	_, _ = cache.GetOrLoad("user:1", load, WithTags("table:users"))
+	_, _ = cache.GetOrLoad("user:2", load, WithTags("table:users", "tenant:a"))
+	_, _ = cache.GetOrLoad("order:1", load, WithTags("table:orders"))
+
+	if n := cache.InvalidateKey("table:users"); n != 2 {
+		t.Errorf("expected 2 entries invalidated, got %d", n)
+	}
+	if cache.Has("user:1") || cache.Has("user:2") {
+		t.Error("tagged entries must be invalidated")
+	}
+	if !cache.Has("order:1") {
+		t.Error("entries with other tags must survive")
+	}
+}
+
+func TestLoadOptions_Generic(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewGenericCache[int, string](Config{MaxSize: 100, TimeProvider: mockTime})
+
+	v, err := cache.GetOrLoad(1, func() (string, error) { return "one", nil }, WithTTL(time.Second), WithTags("numbers"))
+	if err != nil || v != "one" {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+	if n := cache.InvalidateKey("numbers"); n != 1 {
+		t.Errorf("expected 1 entry invalidated, got %d", n)
+	}
+}
+
+func BenchmarkGetOrLoad_NoOptions(b *testing.B) {
+	cache := NewCache(Config{MaxSize: 1000})
+	cache.Set("k", "v")
+	load := func() (interface{}, error) { return "v", nil }
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cache.GetOrLoad("k", load)
+	}
+}
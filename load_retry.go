@@ -0,0 +1,103 @@
+// load_retry.go: per-call timeout and bounded retry of loader calls
+//
+// WithRetry retries a failed load inside the singleflight; WithTimeout
+// bounds every attempt.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"time"
+)
+
+// LoadRetryRecorder is an optional MetricsCollector extension. Collectors
+// implementing it are notified of every loader retry (see WithRetry).
+type LoadRetryRecorder interface {
+	// RecordLoadRetry records that a load is retried after a failed attempt.
+	// attempt is the number of the attempt about to run (2 for the first
+	// retry).
+	RecordLoadRetry(attempt int)
+}
+
+// WithTimeout bounds every loader attempt to timeout, through the context
+// passed to the loader (GetOrLoadWithContext only: the loader of GetOrLoad
+// receives no context). An attempt that times out fails with
+// context.DeadlineExceeded and is retried if WithRetry allows it.
+// Non-positive values are ignored.
+func WithTimeout(timeout time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// WithRetry retries a failed load up to retries times, waiting backoff
+// before the first retry and doubling the wait before each following one.
+// Non-positive retries disable retrying; a non-positive backoff retries
+// immediately.
+//
+// Example:
+//
+//	value, err := cache.GetOrLoadWithContext(ctx, "user:123", loadUser,
+//	    balios.WithTimeout(200*time.Millisecond),
+//	    balios.WithRetry(3, 50*time.Millisecond))
+func WithRetry(retries int, backoff time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		if retries > 0 {
+			o.retries = retries
+		}
+		if backoff > 0 {
+			o.backoff = backoff
+		}
+	}
+}
+
+// callLoader runs loader with panic recovery, applying the timeout and
+// retry policy of o and Config.LoaderMiddleware. op names the calling
+// method in panic errors.
+func (c *wtinyLFUCache) callLoader(ctx context.Context, op, key string, loader func(context.Context) (interface{}, error), o *loadOptions) (interface{}, error) {
+	loader = c.wrapLoader(key, loader)
+	backoff := o.backoff
+	for attempt := 1; ; attempt++ {
+		value, panicked, err := c.loaderAttempt(ctx, op, key, loader, o.timeout)
+		if err == nil || panicked || attempt > o.retries || ctx.Err() != nil {
+			return value, err
+		}
+
+		if c.retryRecorder != nil {
+			c.retryRecorder.RecordLoadRetry(attempt + 1)
+		}
+		c.logger.Debug("balios: retrying load", "key", key, "attempt", attempt+1, "error", err)
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// loaderAttempt runs loader once, bounded by timeout if positive.
+func (c *wtinyLFUCache) loaderAttempt(ctx context.Context, op, key string, loader func(context.Context) (interface{}, error), timeout time.Duration) (value interface{}, panicked bool, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			value, panicked, err = nil, true, NewErrPanicRecovered(op+":"+key, r)
+		}
+	}()
+	value, err = loader(ctx)
+	return value, false, err
+}
@@ -0,0 +1,180 @@
+// load_retry_test.go: tests for per-call loader timeout and retry
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// retryCollector records loader retries.
+type retryCollector struct {
+	NoOpMetricsCollector
+	mu       sync.Mutex
+	attempts []int
+}
+
+func (r *retryCollector) RecordLoadRetry(attempt int) {
+	r.mu.Lock()
+	r.attempts = append(r.attempts, attempt)
+	r.mu.Unlock()
+}
+
+// flakyLoader fails the first failures calls.
+func flakyLoader(calls *int32, failures int32) func(context.Context) (interface{}, error) {
+	return func(context.Context) (interface{}, error) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			return nil, errors.New("transient")
+		}
+		return "value", nil
+	}
+}
+
+func TestWithRetry_RecoversFromTransientErrors(t *testing.T) {
+	collector := &retryCollector{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector})
+
+	var calls int32
+	value, err := cache.GetOrLoadWithContext(context.Background(), "k", flakyLoader(&calls, 2), WithRetry(3, time.Millisecond))
+	if err != nil || value != "value" {
+		t.Fatalf("expected the third attempt to succeed, got %v, %v", value, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 loader calls, got %d", calls)
+	}
+	if len(collector.attempts) != 2 || collector.attempts[0] != 2 || collector.attempts[1] != 3 {
+		t.Errorf("expected retries of attempts [2 3], got %v", collector.attempts)
+	}
+	if v, ok := cache.Get("k"); !ok || v != "value" {
+		t.Error("the value loaded after retries must be cached")
+	}
+}
+
+func TestWithRetry_Exhausted(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, NegativeCacheTTL: time.Minute})
+
+	var calls int32
+	_, err := cache.GetOrLoadWithContext(context.Background(), "k", flakyLoader(&calls, 10), WithRetry(2, 0))
+	if err == nil || err.Error() != "transient" {
+		t.Fatalf("expected the last loader error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 1 attempt + 2 retries, got %d calls", calls)
+	}
+
+	// Only the final outcome is negatively cached
+	if _, err := cache.GetOrLoadWithContext(context.Background(), "k", flakyLoader(&calls, 0)); err == nil {
+		t.Error("the final error must be negatively cached")
+	}
+	if calls != 3 {
+		t.Errorf("a negatively cached key must not reach the loader, got %d calls", calls)
+	}
+}
+
+func TestWithTimeout_BoundsEachAttempt(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+
+	var calls int32
+	_, err := cache.GetOrLoadWithContext(context.Background(), "k", func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithTimeout(5*time.Millisecond), WithRetry(1, 0))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("a timed out attempt must be retried, got %d calls", calls)
+	}
+}
+
+func TestWithRetry_PanicNotRetried(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+
+	var calls int32
+	_, err := cache.GetOrLoadWithContext(context.Background(), "k", func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		panic("bug")
+	}, WithRetry(3, 0))
+
+	if err == nil || calls != 1 {
+		t.Errorf("a panicking loader must not be retried: %d calls, err %v", calls, err)
+	}
+}
+
+func TestWithRetry_StopsWhenCallerCancels(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var calls int32
+	start := time.Now()
+	_, err := cache.GetOrLoadWithContext(ctx, "k", flakyLoader(&calls, 10), WithRetry(5, time.Hour))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller's context error, got %v", err)
+	}
+	if calls != 1 || time.Since(start) > 5*time.Second {
+		t.Errorf("backoff must be interrupted by the caller's context: %d calls in %v", calls, time.Since(start))
+	}
+}
+
+func TestWithRetry_SharedBySingleflight(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func(context.Context) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-release
+			return nil, errors.New("transient")
+		}
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := cache.GetOrLoadWithContext(context.Background(), "k", loader, WithRetry(2, 0)); err != nil || v != "value" {
+				t.Errorf("waiters must share the retried result, got %v, %v", v, err)
+			}
+		}()
+	}
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond) // Let the other callers join the flight
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("expected one loader call per attempt, got %d", got)
+	}
+}
+
+func TestGetOrLoad_WithRetry(t *testing.T) {
+	cache := NewGenericCache[string, string](Config{MaxSize: 100})
+
+	attempts := 0
+	value, err := cache.GetOrLoad("k", func() (string, error) {
+		attempts++
+		if attempts < 2 {
+			return "", errors.New("transient")
+		}
+		return "value", nil
+	}, WithRetry(1, 0))
+	if err != nil || value != "value" || attempts != 2 {
+		t.Errorf("expected success on the retry, got %q, %v after %d attempts", value, err, attempts)
+	}
+}
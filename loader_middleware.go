@@ -0,0 +1,51 @@
+// loader_middleware.go: cross-cutting loader behavior (Config.LoaderMiddleware)
+//
+// Config.LoaderMiddleware wraps every loader the cache runs.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "context"
+
+// Loader loads the value of a key missing from the cache. It is the form of
+// the loaders seen by Config.LoaderMiddleware.
+type Loader func(ctx context.Context, key string) (interface{}, error)
+
+// composeLoaderMiddleware returns the function applying middleware to a
+// loader, or nil if there is none. nil middleware, and middleware returning
+// a nil Loader, are skipped.
+func composeLoaderMiddleware(middleware []func(next Loader) Loader) func(Loader) Loader {
+	chain := make([]func(next Loader) Loader, 0, len(middleware))
+	for _, m := range middleware {
+		if m != nil {
+			chain = append(chain, m)
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	return func(loader Loader) Loader {
+		for i := len(chain) - 1; i >= 0; i-- {
+			if wrapped := chain[i](loader); wrapped != nil {
+				loader = wrapped
+			}
+		}
+		return loader
+	}
+}
+
+// wrapLoader applies Config.LoaderMiddleware to the loader of key.
+func (c *wtinyLFUCache) wrapLoader(key string, loader func(context.Context) (interface{}, error)) func(context.Context) (interface{}, error) {
+	if c.loaderMiddleware == nil {
+		return loader
+	}
+	wrapped := c.loaderMiddleware(func(ctx context.Context, _ string) (interface{}, error) {
+		return loader(ctx)
+	})
+	return func(ctx context.Context) (interface{}, error) {
+		return wrapped(ctx, key)
+	}
+}
@@ -0,0 +1,152 @@
+// loader_middleware_test.go: tests for Config.LoaderMiddleware
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tracingMiddleware appends "name:key" to trace around every load.
+func tracingMiddleware(name string, mu *sync.Mutex, trace *[]string) func(Loader) Loader {
+	return func(next Loader) Loader {
+		return func(ctx context.Context, key string) (interface{}, error) {
+			mu.Lock()
+			*trace = append(*trace, name+":"+key)
+			mu.Unlock()
+			return next(ctx, key)
+		}
+	}
+}
+
+func TestLoaderMiddleware_Order(t *testing.T) {
+	var mu sync.Mutex
+	var trace []string
+	cache := NewCache(Config{MaxSize: 100, LoaderMiddleware: []func(Loader) Loader{
+		tracingMiddleware("outer", &mu, &trace),
+		nil,
+		tracingMiddleware("inner", &mu, &trace),
+	}})
+	defer cache.Close()
+
+	value, err := cache.GetOrLoad("k", func() (interface{}, error) {
+		mu.Lock()
+		trace = append(trace, "loader")
+		mu.Unlock()
+		return "v", nil
+	})
+	if err != nil || value != "v" {
+		t.Fatalf("GetOrLoad = %v, %v", value, err)
+	}
+	want := []string{"outer:k", "inner:k", "loader"}
+	if len(trace) != len(want) {
+		t.Fatalf("trace = %v, want %v", trace, want)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Fatalf("trace = %v, want %v", trace, want)
+		}
+	}
+
+	// Hits run no loader, hence no middleware
+	trace = nil
+	if _, err := cache.GetOrLoad("k", func() (interface{}, error) { return "other", nil }); err != nil || len(trace) != 0 {
+		t.Fatalf("hit ran the middleware: %v, %v", trace, err)
+	}
+}
+
+func TestLoaderMiddleware_ShortCircuit(t *testing.T) {
+	errOpen := errors.New("open")
+	cache := NewCache(Config{MaxSize: 100, LoaderMiddleware: []func(Loader) Loader{
+		func(next Loader) Loader {
+			return func(ctx context.Context, key string) (interface{}, error) {
+				if key == "blocked" {
+					return nil, errOpen
+				}
+				return next(ctx, key)
+			}
+		},
+	}})
+	defer cache.Close()
+
+	called := false
+	_, err := cache.GetOrLoadWithContext(context.Background(), "blocked", func(context.Context) (interface{}, error) {
+		called = true
+		return "v", nil
+	})
+	if !errors.Is(err, errOpen) || called {
+		t.Fatalf("middleware did not short-circuit the loader: %v, called=%v", err, called)
+	}
+	if v, err := cache.GetOrLoadWithContext(context.Background(), "open", func(context.Context) (interface{}, error) {
+		return "v", nil
+	}); err != nil || v != "v" {
+		t.Fatalf("GetOrLoadWithContext = %v, %v", v, err)
+	}
+}
+
+func TestLoaderMiddleware_EveryAttempt(t *testing.T) {
+	var mu sync.Mutex
+	var trace []string
+	cache := NewCache(Config{MaxSize: 100, LoaderMiddleware: []func(Loader) Loader{
+		tracingMiddleware("m", &mu, &trace),
+	}})
+	defer cache.Close()
+
+	var calls int32
+	if _, err := cache.GetOrLoadWithContext(context.Background(), "k", flakyLoader(&calls, 2), WithRetry(3, 0)); err != nil {
+		t.Fatalf("GetOrLoadWithContext: %v", err)
+	}
+	if len(trace) != 3 {
+		t.Fatalf("middleware ran %d times for 3 attempts", len(trace))
+	}
+}
+
+func TestLoaderMiddleware_Panic(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, LoaderMiddleware: []func(Loader) Loader{
+		func(Loader) Loader {
+			return func(context.Context, string) (interface{}, error) {
+				panic("boom")
+			}
+		},
+	}})
+	defer cache.Close()
+
+	_, err := cache.GetOrLoad("k", func() (interface{}, error) { return "v", nil })
+	if GetErrorCode(err) != ErrCodePanicRecovered {
+		t.Fatalf("expected BALIOS_PANIC_RECOVERED, got %v", err)
+	}
+}
+
+func TestLoaderMiddleware_Refresh(t *testing.T) {
+	var mu sync.Mutex
+	var trace []string
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, TimeProvider: mockTime, LoaderMiddleware: []func(Loader) Loader{
+		tracingMiddleware("m", &mu, &trace),
+	}})
+	defer cache.Close()
+
+	loader := func() (interface{}, error) { return "v", nil }
+	refresh := WithRefreshTTL(5 * time.Second)
+	if _, err := cache.GetOrLoad("k", loader, refresh); err != nil {
+		t.Fatal(err)
+	}
+	mockTime.Advance(6 * time.Second)
+	if _, err := cache.GetOrLoad("k", loader, refresh); err != nil {
+		t.Fatal(err)
+	}
+	waitForLoad(t, cache, "k")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(trace) != 2 {
+		t.Fatalf("middleware ran %d times, want the load and the refresh", len(trace))
+	}
+}
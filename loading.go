@@ -42,12 +42,16 @@ type errorWrapper struct {
 // If multiple goroutines call GetOrLoad for the same missing key concurrently,
 // only one loader will be executed (singleflight pattern to prevent cache stampede).
 //
-// The loaded value is cached with the cache's default TTL.
-// If the loader returns an error, the error is NOT cached.
+// The loaded value is cached with the cache's default TTL unless overridden
+// with WithTTL. If the loader returns an error, the error is NOT cached
+// (unless negative caching is enabled). With WithRefreshTTL, hits on entries
+// older than the refresh TTL trigger a background reload (see refresh.go).
 //
 // Parameters:
 //   - key: The cache key to lookup or load
 //   - loader: Function to load the value if not in cache. Must not be nil.
+//   - opts: Optional per-call settings (WithTTL, WithRefreshTTL,
+//     WithSkipNegativeCache, WithPriority, WithTags, WithRetry)
 //
 // Returns:
 //   - value: The cached or loaded value
@@ -65,29 +69,40 @@ type errorWrapper struct {
 //	value, err := cache.GetOrLoad("user:123", func() (interface{}, error) {
 //	    return fetchUserFromDB(123)
 //	})
-func (c *wtinyLFUCache) GetOrLoad(key string, loader func() (interface{}, error)) (interface{}, error) {
+func (c *wtinyLFUCache) GetOrLoad(key string, loader func() (interface{}, error), opts ...LoadOption) (interface{}, error) {
 	// Validate key is not empty
 	if key == "" {
 		return nil, NewErrEmptyKey("GetOrLoad")
 	}
+	if c.isClosed() {
+		return nil, NewErrCacheClosed("GetOrLoad")
+	}
 
 	// Fast path: check cache first
 	if value, found := c.Get(key); found {
+		if len(opts) > 0 && loader != nil {
+			if o := applyLoadOptions(opts); c.needsRefresh(key, &o) {
+				c.refreshAsync(context.Background(), key, func(context.Context) (interface{}, error) {
+					return loader()
+				}, "", o)
+			}
+		}
 		return value, nil
 	}
+	o := applyLoadOptions(opts)
 
 	// Check negative cache if enabled
-	if c.negativeTTLNanos > 0 {
-		negKey := "neg:" + key
-		if negEntry, found := c.negativeCache.Load(negKey); found {
-			neg := negEntry.(negativeEntry)
-			// Check if negative entry has expired
-			if c.timeProvider.Now() <= neg.expireAt {
-				// Return cached error
-				return nil, neg.err
-			}
-			// Expired, remove it
-			c.negativeCache.Delete(negKey)
+	if c.negativeTTLNanos > 0 && !o.skipNegative {
+		if err := c.negativeLookup(key); err != nil {
+			// Return cached error
+			return nil, err
+		}
+	}
+
+	// Serve the value of a key deleted moments ago (see tombstone.go)
+	if c.tombstoneTTLNanos > 0 {
+		if value, found := c.staleTombstone(key); found {
+			return value, nil
 		}
 	}
 
@@ -129,17 +144,48 @@ func (c *wtinyLFUCache) GetOrLoad(key string, loader func() (interface{}, error)
 		c.inflight.Delete(callKey) // Cleanup from per-cache map
 	}()
 
+	// Second-level cache before the loader (see secondary.go)
+	if c.secondary != nil {
+		if value, found := c.loadFromSecondary(context.Background(), key, "", flight, &o); found {
+			return value, nil
+		}
+	}
+
+	// Spread the reloads of a key deleted moments ago (see tombstone.go)
+	if c.tombstoneTTLNanos > 0 {
+		_ = c.delayTombstoned(context.Background(), key) // Never canceled
+	}
+
+	// Rate limit loader calls per key family (Config.LoadRateLimit).
+	// Waiters share the rejection; it is never negatively cached.
+	if err := c.allowLoad(key); err != nil {
+		flight.val.Store(&resultWrapper{})
+		flight.err.Store(&errorWrapper{err: err})
+		return nil, err
+	}
+
 	// Execute loader with panic recovery
 	var loaderVal interface{}
 	var loaderErr error
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				loaderErr = NewErrPanicRecovered("GetOrLoad:"+key, r)
-			}
+	var start int64
+	if c.minLoadCostNanos > 0 {
+		start = c.timeProvider.Now()
+	}
+	if o.retries == 0 && c.loaderMiddleware == nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					loaderErr = NewErrPanicRecovered("GetOrLoad:"+key, r)
+				}
+			}()
+			loaderVal, loaderErr = loader()
 		}()
-		loaderVal, loaderErr = loader()
-	}()
+	} else {
+		loaderVal, loaderErr = c.callLoader(context.Background(), "GetOrLoad", key, func(context.Context) (interface{}, error) {
+			return loader()
+		}, &o)
+	}
+	c.recordCircuit(key, loaderErr)
 
 	// Store results atomically using wrappers
 	flight.val.Store(&resultWrapper{value: loaderVal})
@@ -147,15 +193,12 @@ func (c *wtinyLFUCache) GetOrLoad(key string, loader func() (interface{}, error)
 
 	// If successful, cache the value
 	if loaderErr == nil && loaderVal != nil {
-		c.Set(key, loaderVal)
-	} else if loaderErr != nil && c.negativeTTLNanos > 0 {
+		if c.admitLoad(start, nil) {
+			c.storeLoaded(key, loaderVal, "", &o)
+		}
+	} else if loaderErr != nil && c.negativeTTLNanos > 0 && !o.skipNegative {
 		// Cache the error (negative caching)
-		negKey := "neg:" + key
-		expireAt := c.timeProvider.Now() + c.negativeTTLNanos
-		c.negativeCache.Store(negKey, negativeEntry{
-			err:      loaderErr,
-			expireAt: expireAt,
-		})
+		c.storeNegative(key, loaderErr)
 	}
 
 	return loaderVal, loaderErr
@@ -168,6 +211,7 @@ func (c *wtinyLFUCache) GetOrLoad(key string, loader func() (interface{}, error)
 //   - ctx: Context for cancellation and timeout control
 //   - key: The cache key to lookup or load
 //   - loader: Function to load the value if not in cache. Receives the context.
+//   - opts: Optional per-call settings (see GetOrLoad)
 //
 // Returns:
 //   - value: The cached or loaded value
@@ -180,29 +224,38 @@ func (c *wtinyLFUCache) GetOrLoad(key string, loader func() (interface{}, error)
 //	value, err := cache.GetOrLoadWithContext(ctx, "user:123", func(ctx context.Context) (interface{}, error) {
 //	    return fetchUserFromDBWithContext(ctx, 123)
 //	})
-func (c *wtinyLFUCache) GetOrLoadWithContext(ctx context.Context, key string, loader func(context.Context) (interface{}, error)) (interface{}, error) {
+func (c *wtinyLFUCache) GetOrLoadWithContext(ctx context.Context, key string, loader func(context.Context) (interface{}, error), opts ...LoadOption) (interface{}, error) {
 	// Validate key is not empty
 	if key == "" {
 		return nil, NewErrEmptyKey("GetOrLoadWithContext")
 	}
+	if c.isClosed() {
+		return nil, NewErrCacheClosed("GetOrLoadWithContext")
+	}
 
-	// Fast path: check cache first (no context needed for cache hit)
-	if value, found := c.Get(key); found {
+	// Fast path: check cache first (ctx only tags the Get metrics)
+	if value, found, _ := c.getContext(ctx, key); found {
+		if len(opts) > 0 && loader != nil {
+			if o := applyLoadOptions(opts); c.needsRefresh(key, &o) {
+				c.refreshAsync(context.WithoutCancel(ctx), key, loader, truncateSource(SourceFromContext(ctx)), o)
+			}
+		}
 		return value, nil
 	}
+	o := applyLoadOptions(opts)
 
 	// Check negative cache if enabled
-	if c.negativeTTLNanos > 0 {
-		negKey := "neg:" + key
-		if negEntry, found := c.negativeCache.Load(negKey); found {
-			neg := negEntry.(negativeEntry)
-			// Check if negative entry has expired
-			if c.timeProvider.Now() <= neg.expireAt {
-				// Return cached error
-				return nil, neg.err
-			}
-			// Expired, remove it
-			c.negativeCache.Delete(negKey)
+	if c.negativeTTLNanos > 0 && !o.skipNegative {
+		if err := c.negativeLookup(key); err != nil {
+			// Return cached error
+			return nil, err
+		}
+	}
+
+	// Serve the value of a key deleted moments ago (see tombstone.go)
+	if c.tombstoneTTLNanos > 0 {
+		if value, found := c.staleTombstone(key); found {
+			return value, nil
 		}
 	}
 
@@ -268,17 +321,40 @@ func (c *wtinyLFUCache) GetOrLoadWithContext(ctx context.Context, key string, lo
 		c.inflight.Delete(callKey) // Cleanup from per-cache map
 	}()
 
+	// Second-level cache before the loader (see secondary.go)
+	if c.secondary != nil {
+		if value, found := c.loadFromSecondary(ctx, key, truncateSource(SourceFromContext(ctx)), flight, &o); found {
+			return value, nil
+		}
+	}
+
+	// Spread the reloads of a key deleted moments ago (see tombstone.go)
+	if c.tombstoneTTLNanos > 0 {
+		if err := c.delayTombstoned(ctx, key); err != nil {
+			flight.val.Store(&resultWrapper{})
+			flight.err.Store(&errorWrapper{err: err})
+			return nil, err
+		}
+	}
+
+	// Rate limit loader calls per key family (Config.LoadRateLimit).
+	// Waiters share the rejection; it is never negatively cached.
+	if err := c.allowLoad(key); err != nil {
+		flight.val.Store(&resultWrapper{})
+		flight.err.Store(&errorWrapper{err: err})
+		return nil, err
+	}
+
 	// Execute loader with panic recovery and context
 	var loaderVal interface{}
 	var loaderErr error
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				loaderErr = NewErrPanicRecovered("GetOrLoadWithContext:"+key, r)
-			}
-		}()
-		loaderVal, loaderErr = loader(ctx)
-	}()
+	ctx, cost := c.withLoadCost(ctx)
+	var start int64
+	if cost != nil {
+		start = c.timeProvider.Now()
+	}
+	loaderVal, loaderErr = c.callLoader(ctx, "GetOrLoadWithContext", key, loader, &o)
+	c.recordCircuit(key, loaderErr)
 
 	// Store results atomically using wrappers
 	flight.val.Store(&resultWrapper{value: loaderVal})
@@ -286,15 +362,12 @@ func (c *wtinyLFUCache) GetOrLoadWithContext(ctx context.Context, key string, lo
 
 	// If successful, cache the value
 	if loaderErr == nil && loaderVal != nil {
-		c.Set(key, loaderVal)
-	} else if loaderErr != nil && c.negativeTTLNanos > 0 {
+		if c.admitLoad(start, cost) {
+			c.storeLoaded(key, loaderVal, truncateSource(SourceFromContext(ctx)), &o)
+		}
+	} else if loaderErr != nil && c.negativeTTLNanos > 0 && !o.skipNegative {
 		// Cache the error (negative caching)
-		negKey := "neg:" + key
-		expireAt := c.timeProvider.Now() + c.negativeTTLNanos
-		c.negativeCache.Store(negKey, negativeEntry{
-			err:      loaderErr,
-			expireAt: expireAt,
-		})
+		c.storeNegative(key, loaderErr)
 	}
 
 	return loaderVal, loaderErr
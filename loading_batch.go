@@ -0,0 +1,205 @@
+// loading_batch.go: batch cache-aside loading with per-key singleflight
+//
+// GetOrLoadMany hands every missing key of a batch to a single loader call,
+// sharing the per-key singleflight of GetOrLoad.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+// GetOrLoadMany returns the values of keys, loading every missing key with a
+// single loader call. Keys already being loaded by a concurrent GetOrLoad or
+// GetOrLoadMany are not passed to the loader: their load is awaited instead.
+// The loader returns the values it found, keyed by key; missing keys are
+// simply absent from the result.
+//
+// Loaded values are cached like GetOrLoad's (WithTTL, WithPriority and
+// WithTags apply). On error the returned map holds the values obtained so
+// far together with the loader error, the first error of an awaited load, or
+// BALIOS_PANIC_RECOVERED.
+//
+// Returns BALIOS_EMPTY_KEY if a key is empty and BALIOS_INVALID_LOADER if
+// loader is nil.
+//
+// The batch loader bypasses the per-key load pipeline of GetOrLoad:
+// Config.LoaderMiddleware, the circuit breaker, LoadRateLimit, negative
+// caching and SecondaryCache lookups do not apply (loaded values are still
+// written to the SecondaryCache).
+func (c *wtinyLFUCache) GetOrLoadMany(keys []string, loader func(missing []string) (map[string]interface{}, error), opts ...LoadOption) (map[string]interface{}, error) {
+	for _, key := range keys {
+		if key == "" {
+			return nil, NewErrEmptyKey("GetOrLoadMany")
+		}
+	}
+	if c.isClosed() {
+		return nil, NewErrCacheClosed("GetOrLoadMany")
+	}
+	if loader == nil {
+		return nil, NewErrInvalidLoader("GetOrLoadMany")
+	}
+
+	result := c.GetMany(keys)
+	if len(result) == len(keys) {
+		return result, nil
+	}
+	o := applyLoadOptions(opts)
+
+	// Claim the missing keys, or find the flight already loading them
+	var owned []string
+	var flights []*inflightCall // Parallel to owned
+	var awaited []string
+	var awaitedFlights []*inflightCall // Parallel to awaited
+	seen := make(map[string]struct{}, len(keys)-len(result))
+	for _, key := range keys {
+		if _, ok := result[key]; ok {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		newFlight := &inflightCall{done: make(chan struct{})}
+		newFlight.wg.Add(1)
+		actual, loaded := c.inflight.LoadOrStore("load:"+key, newFlight)
+		if loaded {
+			awaited = append(awaited, key)
+			awaitedFlights = append(awaitedFlights, actual.(*inflightCall))
+			continue
+		}
+		owned = append(owned, key)
+		flights = append(flights, newFlight)
+	}
+
+	// A key claimed after a concurrent load finished is already cached
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	toLoad, toLoadFlights := owned[:0], flights[:0]
+	for i, key := range owned {
+		if value, ok, _ := c.lookup(key, c.hashKey(key), ttlNow); ok {
+			result[key] = value
+			c.releaseFlight(key, flights[i], value, nil)
+			continue
+		}
+		toLoad = append(toLoad, key)
+		toLoadFlights = append(toLoadFlights, flights[i])
+	}
+
+	var firstErr error
+	if len(toLoad) > 0 {
+		firstErr = c.loadBatch(toLoad, toLoadFlights, loader, result, &o)
+	}
+
+	for i, key := range awaited {
+		flight := awaitedFlights[i]
+		<-flight.done
+		errWrapper, _ := flight.err.Load().(*errorWrapper)
+		if errWrapper != nil && errWrapper.err != nil {
+			if firstErr == nil && !IsNotFound(errWrapper.err) {
+				firstErr = errWrapper.err
+			}
+			continue
+		}
+		if valWrapper, _ := flight.val.Load().(*resultWrapper); valWrapper != nil && valWrapper.value != nil {
+			result[key] = valWrapper.value
+		}
+	}
+	return result, firstErr
+}
+
+// loadBatch runs loader for the owned keys, caches and publishes the loaded
+// values to result and to the waiters of each flight, then releases the
+// flights.
+func (c *wtinyLFUCache) loadBatch(owned []string, flights []*inflightCall, loader func(missing []string) (map[string]interface{}, error), result map[string]interface{}, o *loadOptions) error {
+	var loaded map[string]interface{}
+	var loaderErr error
+	defer func() {
+		for i, key := range owned {
+			var value interface{}
+			err := loaderErr
+			if err == nil {
+				value = loaded[key]
+				if value == nil {
+					err = NewErrKeyNotFound(key)
+				}
+			}
+			c.releaseFlight(key, flights[i], value, err)
+		}
+	}()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				loaderErr = NewErrPanicRecovered("GetOrLoadMany", r)
+			}
+		}()
+		loaded, loaderErr = loader(owned)
+	}()
+	if loaderErr != nil {
+		return loaderErr
+	}
+
+	for _, key := range owned {
+		if value := loaded[key]; value != nil {
+			c.storeLoaded(key, value, "", o)
+			result[key] = value
+		}
+	}
+	return nil
+}
+
+// releaseFlight publishes the outcome of the load of key to its waiters and
+// removes the flight from the singleflight map.
+func (c *wtinyLFUCache) releaseFlight(key string, flight *inflightCall, value interface{}, err error) {
+	flight.val.Store(&resultWrapper{value: value})
+	flight.err.Store(&errorWrapper{err: err})
+	close(flight.done)
+	flight.wg.Done()
+	c.inflight.Delete("load:" + key)
+}
+
+// GetOrLoadMany is the generic version of Cache.GetOrLoadMany: it returns the
+// values of keys, loading every missing key with a single loader call.
+//
+// Example:
+//
+//	users, err := cache.GetOrLoadMany(ids, func(missing []int) (map[int]User, error) {
+//	    return fetchUsers(ctx, missing) // SELECT ... WHERE id IN (...)
+//	})
+func (c *GenericCache[K, V]) GetOrLoadMany(keys []K, loader func(missing []K) (map[K]V, error), opts ...LoadOption) (map[K]V, error) {
+	if loader == nil {
+		return nil, NewErrInvalidLoader("GetOrLoadMany")
+	}
+
+	strKeys := make([]string, len(keys))
+	byString := make(map[string]K, len(keys))
+	for i, key := range keys {
+		strKeys[i] = keyToString(key)
+		byString[strKeys[i]] = key
+	}
+
+	values, err := c.inner.GetOrLoadMany(strKeys, func(missing []string) (map[string]interface{}, error) {
+		typedMissing := make([]K, len(missing))
+		for i, keyStr := range missing {
+			typedMissing[i] = byString[keyStr]
+		}
+		loaded, err := loader(typedMissing)
+		if err != nil {
+			return nil, err
+		}
+		wrapped := make(map[string]interface{}, len(loaded))
+		for key, value := range loaded {
+			wrapped[keyToString(key)] = value
+		}
+		return wrapped, nil
+	}, opts...)
+
+	result := make(map[K]V, len(values))
+	for keyStr, value := range values {
+		if typed, ok := value.(V); ok {
+			result[byString[keyStr]] = typed
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,231 @@
+// loading_batch_test.go: tests for batch cache-aside loading with per-key singleflight
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadMany_LoadsOnlyMissingKeys(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+
+	var requested []string
+	values, err := cache.GetOrLoadMany([]string{"a", "b", "c", "b"}, func(missing []string) (map[string]interface{}, error) {
+		requested = append(requested, missing...)
+		return map[string]interface{}{"b": 2}, nil // "c" does not exist
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(requested)
+	if len(requested) != 2 || requested[0] != "b" || requested[1] != "c" {
+		t.Errorf("loader should receive each missing key once, got %v", requested)
+	}
+	if len(values) != 2 || values["a"] != 1 || values["b"] != 2 {
+		t.Errorf("unexpected result: %v", values)
+	}
+	if v, ok := cache.Get("b"); !ok || v != 2 {
+		t.Errorf("loaded value should be cached, got %v, %v", v, ok)
+	}
+	if cache.Has("c") {
+		t.Error("keys the loader did not return must not be cached")
+	}
+}
+
+func TestGetOrLoadMany_AllHits(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	values, err := cache.GetOrLoadMany([]string{"a", "b"}, func([]string) (map[string]interface{}, error) {
+		t.Error("loader must not run when every key is cached")
+		return nil, nil
+	})
+	if err != nil || len(values) != 2 {
+		t.Errorf("expected 2 hits, got %v, %v", values, err)
+	}
+}
+
+func TestGetOrLoadMany_Validation(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	if _, err := cache.GetOrLoadMany([]string{"a", ""}, func([]string) (map[string]interface{}, error) {
+		return nil, nil
+	}); !IsEmptyKey(err) {
+		t.Errorf("expected BALIOS_EMPTY_KEY, got %v", err)
+	}
+	if _, err := cache.GetOrLoadMany([]string{"a"}, nil); err == nil {
+		t.Error("expected BALIOS_INVALID_LOADER for a nil loader")
+	}
+}
+
+func TestGetOrLoadMany_LoaderErrorAndPanic(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("a", 1)
+	loadErr := errors.New("database down")
+
+	values, err := cache.GetOrLoadMany([]string{"a", "b"}, func([]string) (map[string]interface{}, error) {
+		return nil, loadErr
+	})
+	if !errors.Is(err, loadErr) {
+		t.Errorf("expected loader error, got %v", err)
+	}
+	if len(values) != 1 || values["a"] != 1 {
+		t.Errorf("hits should be returned with the error, got %v", values)
+	}
+
+	_, err = cache.GetOrLoadMany([]string{"b"}, func([]string) (map[string]interface{}, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatal("expected BALIOS_PANIC_RECOVERED")
+	}
+
+	// Flights are released after a failure
+	values, err = cache.GetOrLoadMany([]string{"b"}, func([]string) (map[string]interface{}, error) {
+		return map[string]interface{}{"b": 2}, nil
+	})
+	if err != nil || values["b"] != 2 {
+		t.Errorf("retry after failure should load, got %v, %v", values, err)
+	}
+}
+
+func TestGetOrLoadMany_SharesFlightsWithGetOrLoad(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var singleResult interface{}
+	var singleErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		singleResult, singleErr = cache.GetOrLoad("a", func() (interface{}, error) {
+			close(started)
+			<-release
+			return "from-single", nil
+		})
+	}()
+	<-started
+
+	var requested []string
+	done := make(chan struct{})
+	var values map[string]interface{}
+	var err error
+	go func() {
+		defer close(done)
+		values, err = cache.GetOrLoadMany([]string{"a", "b", "c"}, func(missing []string) (map[string]interface{}, error) {
+			requested = missing
+			return map[string]interface{}{"c": "from-batch"}, nil
+		})
+	}()
+
+	// The batch loads its own keys, then waits for "a"
+	for !cache.Has("c") {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	<-done
+	wg.Wait()
+
+	if singleErr != nil || singleResult != "from-single" {
+		t.Fatalf("GetOrLoad failed: %v, %v", singleResult, singleErr)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(requested)
+	if len(requested) != 2 || requested[0] != "b" || requested[1] != "c" {
+		t.Errorf("the key loading elsewhere must not reach the batch loader, got %v", requested)
+	}
+	if values["a"] != "from-single" || values["c"] != "from-batch" || len(values) != 2 {
+		t.Errorf("unexpected result: %v", values)
+	}
+}
+
+func TestGetOrLoadMany_ConcurrentOverlappingBatches(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+
+	var loads sync.Map // key -> *int32
+	loader := func(missing []string) (map[string]interface{}, error) {
+		time.Sleep(time.Millisecond)
+		out := make(map[string]interface{}, len(missing))
+		for _, key := range missing {
+			n, _ := loads.LoadOrStore(key, new(int32))
+			atomic.AddInt32(n.(*int32), 1)
+			out[key] = "v" + key
+		}
+		return out, nil
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			keys := make([]string, 0, 20)
+			for i := 0; i < 20; i++ {
+				keys = append(keys, strconv.Itoa((g*7+i)%40))
+			}
+			values, err := cache.GetOrLoadMany(keys, loader)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			for _, key := range keys {
+				if values[key] != "v"+key {
+					t.Errorf("missing value for %s: %v", key, values[key])
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	loads.Range(func(key, n interface{}) bool {
+		if got := atomic.LoadInt32(n.(*int32)); got != 1 {
+			t.Errorf("key %v loaded %d times", key, got)
+		}
+		return true
+	})
+}
+
+func TestGenericCache_GetOrLoadMany(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 100, TTL: time.Minute})
+	cache.Set(1, "one")
+
+	var requested []int
+	values, err := cache.GetOrLoadMany([]int{1, 2, 3}, func(missing []int) (map[int]string, error) {
+		requested = append(requested, missing...)
+		return map[int]string{2: "two", 3: "three"}, nil
+	}, WithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Ints(requested)
+	if len(requested) != 2 || requested[0] != 2 || requested[1] != 3 {
+		t.Errorf("expected the loader to receive [2 3], got %v", requested)
+	}
+	if len(values) != 3 || values[1] != "one" || values[2] != "two" || values[3] != "three" {
+		t.Errorf("unexpected result: %v", values)
+	}
+	if _, expireAt, ok := cache.GetWithExpiry(3); !ok || time.Until(expireAt) < 30*time.Minute {
+		t.Errorf("WithTTL should apply to loaded values, expires at %v", expireAt)
+	}
+	if _, err := cache.GetOrLoadMany([]int{4}, nil); err == nil {
+		t.Error("expected BALIOS_INVALID_LOADER for a nil loader")
+	}
+}
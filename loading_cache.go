@@ -0,0 +1,84 @@
+// loading_cache.go: GenericCache with a loader bound at construction
+//
+// LoadingCache binds a loader and its options once, so call sites just
+// Get(key).
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "context"
+
+// LoaderFunc loads the value of a key missing from a LoadingCache.
+type LoaderFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// LoadingCache is a GenericCache whose loader and load options are bound at
+// construction.
+//
+// Example:
+//
+//	users := balios.NewLoadingCache(balios.Config{MaxSize: 10_000, TTL: time.Hour},
+//	    func(ctx context.Context, id int64) (User, error) {
+//	        return db.FetchUser(ctx, id)
+//	    },
+//	    balios.WithRefreshTTL(45*time.Minute),
+//	    balios.WithRetry(2, 50*time.Millisecond),
+//	)
+//	user, err := users.Get(42)
+type LoadingCache[K comparable, V any] struct {
+	cache  *GenericCache[K, V]
+	loader LoaderFunc[K, V]
+	opts   []LoadOption
+}
+
+// NewLoadingCache creates a GenericCache from cfg and binds loader and opts
+// to it. A nil loader makes every load fail with BALIOS_INVALID_LOADER.
+func NewLoadingCache[K comparable, V any](cfg Config, loader LoaderFunc[K, V], opts ...LoadOption) *LoadingCache[K, V] {
+	return NewLoadingCacheFrom(NewGenericCache[K, V](cfg), loader, opts...)
+}
+
+// NewLoadingCacheFrom binds loader and opts to an existing cache. Entries
+// stored through other views of cache are served by Get as well.
+func NewLoadingCacheFrom[K comparable, V any](cache *GenericCache[K, V], loader LoaderFunc[K, V], opts ...LoadOption) *LoadingCache[K, V] {
+	return &LoadingCache[K, V]{
+		cache:  cache,
+		loader: loader,
+		opts:   append([]LoadOption(nil), opts...),
+	}
+}
+
+// Get returns the value of key, loading it with the bound loader if it is
+// not cached. Concurrent loads of the same key share one loader call.
+func (c *LoadingCache[K, V]) Get(key K) (V, error) {
+	return c.GetWithContext(context.Background(), key)
+}
+
+// GetWithContext is like Get; ctx is passed to the loader and bounds the
+// wait for a load in progress (see GenericCache.GetOrLoadWithContext).
+func (c *LoadingCache[K, V]) GetWithContext(ctx context.Context, key K) (V, error) {
+	if c.loader == nil {
+		var zero V
+		return zero, NewErrInvalidLoader(keyToString(key))
+	}
+	return c.cache.GetOrLoadWithContext(ctx, key, func(ctx context.Context) (V, error) {
+		return c.loader(ctx, key)
+	}, c.opts...)
+}
+
+// GetIfPresent returns the cached value of key without loading it.
+func (c *LoadingCache[K, V]) GetIfPresent(key K) (V, bool) {
+	return c.cache.Get(key)
+}
+
+// Cache returns the underlying GenericCache, for writes, deletes,
+// statistics and the other operations that do not load.
+func (c *LoadingCache[K, V]) Cache() *GenericCache[K, V] {
+	return c.cache
+}
+
+// Close closes the underlying cache (see Cache.Close).
+func (c *LoadingCache[K, V]) Close() error {
+	return c.cache.Close()
+}
@@ -0,0 +1,124 @@
+// loading_cache_test.go: tests for LoadingCache
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadingCache_LoadsOnceAndCaches(t *testing.T) {
+	var calls int64
+	cache := NewLoadingCache(Config{MaxSize: 100}, func(ctx context.Context, id int) (string, error) {
+		atomic.AddInt64(&calls, 1)
+		return fmt.Sprintf("user-%d", id), nil
+	})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := cache.Get(7); err != nil || v != "user-7" {
+				t.Errorf("Get(7) = %q, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, ok := cache.GetIfPresent(7); !ok || v != "user-7" {
+		t.Fatalf("GetIfPresent(7) = %q, %v", v, ok)
+	}
+	if _, ok := cache.GetIfPresent(8); ok {
+		t.Fatal("GetIfPresent loaded a missing key")
+	}
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Fatalf("loader called %d times, want 1", n)
+	}
+}
+
+func TestLoadingCache_BoundOptions(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	var calls int64
+	cache := NewLoadingCache(Config{MaxSize: 100, TimeProvider: timeProvider},
+		func(ctx context.Context, key string) (int, error) {
+			return int(atomic.AddInt64(&calls, 1)), nil
+		},
+		WithTTL(time.Minute),
+	)
+	defer cache.Close()
+
+	if v, _ := cache.Get("k"); v != 1 {
+		t.Fatalf("first Get = %d, want 1", v)
+	}
+	timeProvider.Advance(30 * time.Second)
+	if v, _ := cache.Get("k"); v != 1 {
+		t.Fatalf("Get within the TTL = %d, want 1", v)
+	}
+	timeProvider.Advance(31 * time.Second)
+	if v, _ := cache.Get("k"); v != 2 {
+		t.Fatalf("Get after the bound TTL = %d, want 2 (reloaded)", v)
+	}
+}
+
+func TestLoadingCache_ErrorsAndContext(t *testing.T) {
+	errBackend := errors.New("backend down")
+	cache := NewLoadingCache(Config{MaxSize: 100}, func(ctx context.Context, key string) (int, error) {
+		if key == "slow" {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 0, errBackend
+	})
+	defer cache.Close()
+
+	if _, err := cache.Get("k"); !errors.Is(err, errBackend) {
+		t.Fatalf("Get error = %v, want the loader error", err)
+	}
+	if _, ok := cache.GetIfPresent("k"); ok {
+		t.Fatal("a failed load was cached")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cache.GetWithContext(ctx, "slow"); err == nil {
+		t.Fatal("expected an error when the context expires")
+	}
+}
+
+func TestLoadingCache_From(t *testing.T) {
+	shared := NewGenericCache[string, int](Config{MaxSize: 100})
+	defer shared.Close()
+	shared.Set("preloaded", 42)
+
+	cache := NewLoadingCacheFrom(shared, func(ctx context.Context, key string) (int, error) {
+		return len(key), nil
+	})
+	if v, err := cache.Get("preloaded"); err != nil || v != 42 {
+		t.Fatalf("Get(preloaded) = %d, %v", v, err)
+	}
+	if v, _ := cache.Get("abc"); v != 3 {
+		t.Fatalf("Get(abc) = %d, want 3", v)
+	}
+	if v, ok := shared.Get("abc"); !ok || v != 3 || cache.Cache() != shared {
+		t.Fatal("the loaded value is not stored in the shared cache")
+	}
+}
+
+func TestLoadingCache_NilLoader(t *testing.T) {
+	cache := NewLoadingCache[string, int](Config{MaxSize: 10}, nil)
+	defer cache.Close()
+	if _, err := cache.Get("k"); GetErrorCode(err) != ErrCodeInvalidLoader {
+		t.Fatalf("expected %s, got %v", ErrCodeInvalidLoader, err)
+	}
+}
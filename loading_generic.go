@@ -31,7 +31,7 @@ import "context"
 //	value, err := cache.GetOrLoad(42, func() (string, error) {
 //	    return fetchFromDB(42)
 //	})
-func (c *GenericCache[K, V]) GetOrLoad(key K, loader func() (V, error)) (V, error) {
+func (c *GenericCache[K, V]) GetOrLoad(key K, loader func() (V, error), opts ...LoadOption) (V, error) {
 	var zero V
 
 	// Convert key to string
@@ -43,7 +43,7 @@ func (c *GenericCache[K, V]) GetOrLoad(key K, loader func() (V, error)) (V, erro
 	}
 
 	// Call underlying cache
-	result, err := c.inner.GetOrLoad(keyStr, wrappedLoader)
+	result, err := c.inner.GetOrLoad(keyStr, wrappedLoader, opts...)
 	if err != nil {
 		return zero, err
 	}
@@ -81,7 +81,7 @@ func (c *GenericCache[K, V]) GetOrLoad(key K, loader func() (V, error)) (V, erro
 //	value, err := cache.GetOrLoadWithContext(ctx, 42, func(ctx context.Context) (string, error) {
 //	    return fetchFromDBWithContext(ctx, 42)
 //	})
-func (c *GenericCache[K, V]) GetOrLoadWithContext(ctx context.Context, key K, loader func(context.Context) (V, error)) (V, error) {
+func (c *GenericCache[K, V]) GetOrLoadWithContext(ctx context.Context, key K, loader func(context.Context) (V, error), opts ...LoadOption) (V, error) {
 	var zero V
 
 	// Convert key to string
@@ -93,7 +93,7 @@ func (c *GenericCache[K, V]) GetOrLoadWithContext(ctx context.Context, key K, lo
 	}
 
 	// Call underlying cache
-	result, err := c.inner.GetOrLoadWithContext(ctx, keyStr, wrappedLoader)
+	result, err := c.inner.GetOrLoadWithContext(ctx, keyStr, wrappedLoader, opts...)
 	if err != nil {
 		return zero, err
 	}
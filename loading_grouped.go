@@ -0,0 +1,257 @@
+// loading_grouped.go: collapsed forwarding of concurrent loads by group
+//
+// GetOrLoadGrouped hands concurrent misses of the same group to one
+// loader call.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"time"
+)
+
+// groupedLoader loads the keys of a batch; typed holds the key given by the
+// caller of each key (the K of a GenericCache), parallel to keys.
+type groupedLoader func(keys []string, typed []interface{}) (map[string]interface{}, error)
+
+// groupedLoad is a key waiting in a load group.
+type groupedLoad struct {
+	key    string
+	typed  interface{}
+	flight *inflightCall
+	o      loadOptions
+}
+
+// loadGroup collects the misses of a group key.
+type loadGroup struct {
+	mu      sync.Mutex
+	pending []groupedLoad
+	loading bool // A dispatcher is running
+}
+
+// GetOrLoadGrouped returns the value of key, loading it on a miss together
+// with the concurrent misses of the other keys of groupKey: loader receives
+// every key of the batch and returns the values it found, keyed by key.
+// Callers of the same group must pass equivalent loaders.
+//
+// Loaded values are cached like GetOrLoad's (WithTTL, WithPriority and
+// WithTags apply). Returns BALIOS_KEY_NOT_FOUND if the loader did not return
+// key, the loader error, BALIOS_PANIC_RECOVERED, or the error of a
+// concurrent load of key being awaited.
+//
+// The batch loader bypasses the per-key load pipeline of GetOrLoad:
+// Config.LoaderMiddleware, the circuit breaker, LoadRateLimit, negative
+// caching and SecondaryCache lookups do not apply (loaded values are still
+// written to the SecondaryCache).
+//
+// Example:
+//
+//	user, err := cache.GetOrLoadGrouped("users", "user:"+id, func(keys []string) (map[string]interface{}, error) {
+//	    return fetchUsers(keys) // One MGET for every concurrent miss
+//	})
+func (c *wtinyLFUCache) GetOrLoadGrouped(groupKey, key string, loader func(keys []string) (map[string]interface{}, error), opts ...LoadOption) (interface{}, error) {
+	if loader == nil {
+		return c.getOrLoadGrouped(groupKey, key, key, nil, opts)
+	}
+	return c.getOrLoadGrouped(groupKey, key, key, func(keys []string, _ []interface{}) (map[string]interface{}, error) {
+		return loader(keys)
+	}, opts)
+}
+
+// getOrLoadGrouped implements GetOrLoadGrouped; typed is passed back to the
+// loader with key.
+func (c *wtinyLFUCache) getOrLoadGrouped(groupKey, key string, typed interface{}, loader groupedLoader, opts []LoadOption) (interface{}, error) {
+	if key == "" {
+		return nil, NewErrEmptyKey("GetOrLoadGrouped")
+	}
+	if c.isClosed() {
+		return nil, NewErrCacheClosed("GetOrLoadGrouped")
+	}
+	if value, found := c.Get(key); found {
+		return value, nil
+	}
+	if loader == nil {
+		return nil, NewErrInvalidLoader(key)
+	}
+
+	flight := &inflightCall{done: make(chan struct{})}
+	flight.wg.Add(1)
+	if actual, loaded := c.inflight.LoadOrStore("load:"+key, flight); loaded {
+		return awaitFlight(actual.(*inflightCall))
+	}
+
+	// A key claimed after a concurrent load finished is already cached
+	if value, ok, _ := c.lookup(key, c.hashKey(key), c.ttlClock(c.timeProvider.Now())); ok {
+		c.releaseFlight(key, flight, value, nil)
+		return value, nil
+	}
+
+	c.enqueueGrouped(groupKey, groupedLoad{key: key, typed: typed, flight: flight, o: applyLoadOptions(opts)}, loader)
+	return awaitFlight(flight)
+}
+
+// awaitFlight waits for the load of flight and returns its outcome.
+func awaitFlight(flight *inflightCall) (interface{}, error) {
+	<-flight.done
+	valWrapper, _ := flight.val.Load().(*resultWrapper)
+	errWrapper, _ := flight.err.Load().(*errorWrapper)
+	if valWrapper == nil || errWrapper == nil {
+		return nil, nil // Should never happen
+	}
+	return valWrapper.value, errWrapper.err
+}
+
+// enqueueGrouped adds load to the pending keys of groupKey, starting a
+// dispatcher if the group is idle.
+func (c *wtinyLFUCache) enqueueGrouped(groupKey string, load groupedLoad, loader groupedLoader) {
+	v, _ := c.groups.LoadOrStore(groupKey, &loadGroup{})
+	group := v.(*loadGroup)
+
+	group.mu.Lock()
+	group.pending = append(group.pending, load)
+	start := !group.loading
+	group.loading = true
+	group.mu.Unlock()
+
+	if start {
+		go c.dispatchGroup(groupKey, group, loader)
+	}
+}
+
+// dispatchGroup loads the pending keys of group in batches until none is
+// left.
+func (c *wtinyLFUCache) dispatchGroup(groupKey string, group *loadGroup, loader groupedLoader) {
+	if c.groupWindow > 0 {
+		time.Sleep(c.groupWindow)
+	}
+	for {
+		batch := c.nextGroupBatch(groupKey, group)
+		if batch == nil {
+			return
+		}
+		c.loadGroupBatch(batch, loader)
+	}
+}
+
+// nextGroupBatch takes the next batch of group, or marks the group idle and
+// returns nil if no key is pending.
+func (c *wtinyLFUCache) nextGroupBatch(groupKey string, group *loadGroup) []groupedLoad {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	if len(group.pending) == 0 {
+		group.loading = false
+		// Late arrivals holding this group start their own dispatcher
+		c.groups.CompareAndDelete(groupKey, group)
+		return nil
+	}
+	n := len(group.pending)
+	if c.groupMaxBatch > 0 && n > c.groupMaxBatch {
+		n = c.groupMaxBatch
+	}
+	batch := make([]groupedLoad, n)
+	copy(batch, group.pending)
+	remaining := copy(group.pending, group.pending[n:])
+	clear(group.pending[remaining:])
+	group.pending = group.pending[:remaining]
+	return batch
+}
+
+// loadGroupBatch runs loader for batch, caches the loaded values and
+// releases the flights of the batch.
+func (c *wtinyLFUCache) loadGroupBatch(batch []groupedLoad, loader groupedLoader) {
+	keys := make([]string, len(batch))
+	typed := make([]interface{}, len(batch))
+	for i, load := range batch {
+		keys[i], typed[i] = load.key, load.typed
+	}
+
+	var loaded map[string]interface{}
+	var loaderErr error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				loaderErr = NewErrPanicRecovered("GetOrLoadGrouped", r)
+			}
+		}()
+		loaded, loaderErr = loader(keys, typed)
+	}()
+
+	for i := range batch {
+		load := &batch[i]
+		var value interface{}
+		err := loaderErr
+		if err == nil {
+			if value = loaded[load.key]; value == nil {
+				err = NewErrKeyNotFound(load.key)
+			} else {
+				c.whileOpen(func() { c.storeLoaded(load.key, value, "", &load.o) })
+			}
+		}
+		c.releaseFlight(load.key, load.flight, value, err)
+	}
+}
+
+// GetOrLoadGrouped is the generic version of Cache.GetOrLoadGrouped: it
+// returns the value of key, loading it on a miss together with the
+// concurrent misses of the other keys of groupKey.
+//
+// Example:
+//
+//	user, err := cache.GetOrLoadGrouped("users", id, func(ids []int64) (map[int64]User, error) {
+//	    return fetchUsers(ctx, ids) // SELECT ... WHERE id IN (...)
+//	})
+func (c *GenericCache[K, V]) GetOrLoadGrouped(groupKey string, key K, loader func(keys []K) (map[K]V, error), opts ...LoadOption) (V, error) {
+	var zero V
+	if loader == nil {
+		return zero, NewErrInvalidLoader(keyToString(key))
+	}
+
+	wrap := func(keys []K) (map[string]interface{}, error) {
+		loaded, err := loader(keys)
+		if err != nil {
+			return nil, err
+		}
+		wrapped := make(map[string]interface{}, len(loaded))
+		for key, value := range loaded {
+			wrapped[keyToString(key)] = value
+		}
+		return wrapped, nil
+	}
+
+	var value interface{}
+	var err error
+	if inner, ok := c.inner.(*wtinyLFUCache); ok {
+		// The caller's keys travel with the batch
+		value, err = inner.getOrLoadGrouped(groupKey, keyToString(key), key, func(_ []string, typed []interface{}) (map[string]interface{}, error) {
+			keys := make([]K, len(typed))
+			for i, key := range typed {
+				keys[i] = key.(K)
+			}
+			return wrap(keys)
+		}, opts)
+	} else {
+		// Other Cache implementations only see string keys: parse them back
+		value, err = c.inner.GetOrLoadGrouped(groupKey, keyToString(key), func(strKeys []string) (map[string]interface{}, error) {
+			keys := make([]K, 0, len(strKeys))
+			for _, keyStr := range strKeys {
+				if key, ok := keyFromString[K](keyStr); ok {
+					keys = append(keys, key)
+				}
+			}
+			return wrap(keys)
+		}, opts...)
+	}
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := value.(V)
+	if !ok {
+		return zero, nil
+	}
+	return typed, nil
+}
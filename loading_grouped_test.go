@@ -0,0 +1,211 @@
+// loading_grouped_test.go: tests for GetOrLoadGrouped
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchRecorder is a multi-get loader recording the batches it receives.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	delay   time.Duration
+}
+
+func (b *batchRecorder) load(keys []string) (map[string]interface{}, error) {
+	b.mu.Lock()
+	b.batches = append(b.batches, append([]string(nil), keys...))
+	b.mu.Unlock()
+	time.Sleep(b.delay)
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if key != "absent" {
+			values[key] = "v:" + key
+		}
+	}
+	return values, nil
+}
+
+func (b *batchRecorder) keysLoaded() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, batch := range b.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func TestGetOrLoadGrouped_Basic(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+	loader := &batchRecorder{}
+
+	v, err := cache.GetOrLoadGrouped("g", "a", loader.load)
+	if err != nil || v != "v:a" {
+		t.Fatalf("GetOrLoadGrouped = %v, %v", v, err)
+	}
+	if cached, ok := cache.Get("a"); !ok || cached != "v:a" {
+		t.Fatal("the loaded value must be cached")
+	}
+	if _, err := cache.GetOrLoadGrouped("g", "a", loader.load); err != nil || len(loader.batches) != 1 {
+		t.Fatalf("a hit must not call the loader: %v, %d batches", err, len(loader.batches))
+	}
+
+	if _, err := cache.GetOrLoadGrouped("g", "absent", loader.load); !IsNotFound(err) {
+		t.Fatalf("expected BALIOS_KEY_NOT_FOUND, got %v", err)
+	}
+	if _, err := cache.GetOrLoadGrouped("g", "", loader.load); !IsEmptyKey(err) {
+		t.Fatalf("expected BALIOS_EMPTY_KEY, got %v", err)
+	}
+	if _, err := cache.GetOrLoadGrouped("g", "b", nil); GetErrorCode(err) != ErrCodeInvalidLoader {
+		t.Fatalf("expected BALIOS_INVALID_LOADER, got %v", err)
+	}
+}
+
+func TestGetOrLoadGrouped_BatchesConcurrentMisses(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	defer cache.Close()
+	loader := &batchRecorder{delay: 20 * time.Millisecond}
+
+	const callers = 50
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := "k" + strconv.Itoa(i)
+			if v, err := cache.GetOrLoadGrouped("users", key, loader.load); err != nil || v != "v:"+key {
+				t.Errorf("GetOrLoadGrouped(%s) = %v, %v", key, v, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := loader.keysLoaded(); n != callers {
+		t.Fatalf("loaded %d keys, want %d", n, callers)
+	}
+	if len(loader.batches) >= callers/2 {
+		t.Fatalf("%d concurrent misses took %d loader calls", callers, len(loader.batches))
+	}
+}
+
+func TestGetOrLoadGrouped_WindowAndMaxBatch(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, GroupedLoadWindow: 20 * time.Millisecond, GroupedLoadMaxBatch: 4}).(*wtinyLFUCache)
+	defer cache.Close()
+	loader := &batchRecorder{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache.GetOrLoadGrouped("g", "k"+strconv.Itoa(i), loader.load)
+		}(i)
+	}
+	wg.Wait()
+
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	if len(loader.batches) != 3 {
+		t.Fatalf("expected batches of 4, 4 and 2, got %v", loader.batches)
+	}
+	for _, batch := range loader.batches {
+		if len(batch) > 4 {
+			t.Fatalf("batch of %d keys exceeds GroupedLoadMaxBatch", len(batch))
+		}
+	}
+}
+
+func TestGetOrLoadGrouped_SharesSingleflight(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	release := make(chan struct{})
+	var calls int32
+	var loading sync.WaitGroup
+	loading.Add(1)
+	go func() {
+		defer loading.Done()
+		cache.GetOrLoad("k", func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return "from GetOrLoad", nil
+		})
+	}()
+	defer loading.Wait() // Before Close
+	for {
+		if _, loading := cache.inflight.Load("load:k"); loading {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan interface{})
+	go func() {
+		v, _ := cache.GetOrLoadGrouped("g", "k", func([]string) (map[string]interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, nil
+		})
+		done <- v
+	}()
+	close(release)
+	if v := <-done; v != "from GetOrLoad" || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected the GetOrLoad flight to be awaited, got %v after %d calls", v, calls)
+	}
+}
+
+func TestGetOrLoadGrouped_Errors(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	errDown := errors.New("down")
+	if _, err := cache.GetOrLoadGrouped("g", "a", func([]string) (map[string]interface{}, error) {
+		return nil, errDown
+	}); !errors.Is(err, errDown) {
+		t.Fatalf("expected the loader error, got %v", err)
+	}
+	if _, err := cache.GetOrLoadGrouped("g", "a", func([]string) (map[string]interface{}, error) {
+		panic("boom")
+	}); GetErrorCode(err) != ErrCodePanicRecovered {
+		t.Fatalf("expected BALIOS_PANIC_RECOVERED, got %v", err)
+	}
+	if cache.Has("a") {
+		t.Fatal("failed loads must not be cached")
+	}
+}
+
+func TestGenericCache_GetOrLoadGrouped(t *testing.T) {
+	type point struct{ X, Y int }
+	cache := NewGenericCache[point, int](Config{MaxSize: 100})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := cache.GetOrLoadGrouped("points", point{i, i}, func(keys []point) (map[point]int, error) {
+				values := make(map[point]int, len(keys))
+				for _, key := range keys {
+					values[key] = key.X + key.Y
+				}
+				return values, nil
+			})
+			if err != nil || v != 2*i {
+				t.Errorf("GetOrLoadGrouped(%d) = %v, %v", i, v, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,72 @@
+// loading_result.go: GetOrLoad variants for loaders returning value + metadata
+//
+// This file provides LoadResult, a generic wrapper that stores loader-provided
+// metadata (ETags, response headers, versions) alongside the cached value,
+// so callers don't need to define ad-hoc wrapper structs for every cache.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+package balios
+
+import "context"
+
+// LoadResult pairs a cached value with metadata returned by its loader.
+//
+// Type Parameters:
+//   - V: Value type
+//   - M: Metadata type (e.g. an ETag string or an http.Header)
+//
+// Use it as the value type of a GenericCache together with GetOrLoadResult:
+//
+//	cache := balios.NewGenericCache[string, balios.LoadResult[[]byte, string]](cfg)
+//	res, err := balios.GetOrLoadResult(cache, url, func() ([]byte, string, error) {
+//	    body, etag, err := fetch(url)
+//	    return body, etag, err
+//	})
+//	// res.Value is the body, res.Metadata the ETag
+type LoadResult[V any, M any] struct {
+	// Value is the loaded value.
+	Value V
+
+	// Metadata is the auxiliary data returned by the loader.
+	Metadata M
+}
+
+// GetOrLoadResult returns the cached LoadResult for key, or loads it using a
+// loader that returns a value, its metadata and an error.
+// Semantics are identical to GenericCache.GetOrLoad (singleflight, negative
+// caching, panic recovery); value and metadata are cached together.
+//
+// Returns:
+//   - result: The cached or loaded value with its metadata (zero value on error)
+//   - error: Loader error or validation error
+func GetOrLoadResult[K comparable, V any, M any](c *GenericCache[K, LoadResult[V, M]], key K, loader func() (V, M, error)) (LoadResult[V, M], error) {
+	if loader == nil {
+		return LoadResult[V, M]{}, NewErrInvalidLoader(keyToString(key))
+	}
+
+	return c.GetOrLoad(key, func() (LoadResult[V, M], error) {
+		value, metadata, err := loader()
+		if err != nil {
+			return LoadResult[V, M]{}, err
+		}
+		return LoadResult[V, M]{Value: value, Metadata: metadata}, nil
+	})
+}
+
+// GetOrLoadResultWithContext is like GetOrLoadResult but respects context
+// cancellation and timeout. The context is passed to the loader.
+func GetOrLoadResultWithContext[K comparable, V any, M any](ctx context.Context, c *GenericCache[K, LoadResult[V, M]], key K, loader func(context.Context) (V, M, error)) (LoadResult[V, M], error) {
+	if loader == nil {
+		return LoadResult[V, M]{}, NewErrInvalidLoader(keyToString(key))
+	}
+
+	return c.GetOrLoadWithContext(ctx, key, func(ctx context.Context) (LoadResult[V, M], error) {
+		value, metadata, err := loader(ctx)
+		if err != nil {
+			return LoadResult[V, M]{}, err
+		}
+		return LoadResult[V, M]{Value: value, Metadata: metadata}, nil
+	})
+}
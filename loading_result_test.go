@@ -0,0 +1,119 @@
+// loading_result_test.go: tests for LoadResult-based GetOrLoad variants
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+package balios
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetOrLoadResult_StoresMetadata(t *testing.T) {
+	cache := NewGenericCache[string, LoadResult[string, string]](Config{MaxSize: 100})
+
+	var calls int32
+	loader := func() (string, string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "body", `"etag-1"`, nil
+	}
+
+	res, err := GetOrLoadResult(cache, "url", loader)
+	if err != nil {
+		t.Fatalf("GetOrLoadResult failed: %v", err)
+	}
+	if res.Value != "body" || res.Metadata != `"etag-1"` {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	// Second call must hit the cache
+	res, err = GetOrLoadResult(cache, "url", loader)
+	if err != nil || res.Metadata != `"etag-1"` {
+		t.Errorf("cached result mismatch: %+v, %v", res, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 loader call, got %d", calls)
+	}
+
+	// Metadata is retrievable through the plain typed API
+	cached, found := cache.Get("url")
+	if !found || cached.Metadata != `"etag-1"` {
+		t.Errorf("Get returned %+v, %v", cached, found)
+	}
+}
+
+func TestGetOrLoadResult_ErrorNotCached(t *testing.T) {
+	cache := NewGenericCache[string, LoadResult[int, string]](Config{MaxSize: 100})
+	loadErr := errors.New("backend down")
+
+	res, err := GetOrLoadResult(cache, "k", func() (int, string, error) {
+		return 42, "ignored", loadErr
+	})
+	if !errors.Is(err, loadErr) {
+		t.Fatalf("expected loader error, got %v", err)
+	}
+	if res.Value != 0 || res.Metadata != "" {
+		t.Errorf("expected zero result on error, got %+v", res)
+	}
+	if cache.Has("k") {
+		t.Error("failed load must not be cached")
+	}
+}
+
+func TestGetOrLoadResult_NilLoader(t *testing.T) {
+	cache := NewGenericCache[string, LoadResult[int, string]](Config{MaxSize: 100})
+
+	if _, err := GetOrLoadResult[string, int, string](cache, "k", nil); GetErrorCode(err) != ErrCodeInvalidLoader {
+		t.Errorf("expected %s, got %v", ErrCodeInvalidLoader, err)
+	}
+	if _, err := GetOrLoadResultWithContext[string, int, string](context.Background(), cache, "k", nil); GetErrorCode(err) != ErrCodeInvalidLoader {
+		t.Errorf("expected %s, got %v", ErrCodeInvalidLoader, err)
+	}
+}
+
+func TestGetOrLoadResultWithContext_Singleflight(t *testing.T) {
+	cache := NewGenericCache[int, LoadResult[string, int64]](Config{MaxSize: 100})
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (string, int64, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "profile", 7, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]LoadResult[string, int64], 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := GetOrLoadResultWithContext(context.Background(), cache, 1, loader)
+			if err != nil {
+				t.Errorf("load failed: %v", err)
+			}
+			results[i] = res
+		}(i)
+	}
+
+	// Give waiters time to join the in-flight call before releasing it
+	for atomic.LoadInt32(&calls) == 0 {
+		runtime.Gosched() // wait until the loader is running
+	}
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected a single loader call, got %d", calls)
+	}
+	for i, res := range results {
+		if res.Value != "profile" || res.Metadata != 7 {
+			t.Errorf("result %d mismatch: %+v", i, res)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// lock_key.go: per-key mutual exclusion for callers (LockKey)
+//
+// LockKey hands out a striped, advisory mutex per key for read-modify-write
+// sequences that span the cache and an external system.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// keyLockBits is the log2 of the number of LockKey stripes.
+const keyLockBits = 8
+
+// keyLockStripes is the number of mutexes shared by the keys passed to LockKey.
+const keyLockStripes = 1 << keyLockBits
+
+// keyLockStripe is a mutex padded to its own cache line, so that contention
+// on one stripe does not slow down its neighbors.
+type keyLockStripe struct {
+	mu sync.Mutex
+	_  [64 - 8]byte
+}
+
+// keyLocks is a lazily allocated set of striped mutexes.
+type keyLocks struct {
+	stripes atomic.Pointer[[keyLockStripes]keyLockStripe]
+}
+
+// lock locks the stripe of keyHash and returns its unlock function, which
+// is safe to call more than once.
+func (l *keyLocks) lock(keyHash uint64) func() {
+	stripes := l.stripes.Load()
+	if stripes == nil {
+		l.stripes.CompareAndSwap(nil, new([keyLockStripes]keyLockStripe))
+		stripes = l.stripes.Load()
+	}
+	mu := &stripes[keyHash>>(64-keyLockBits)].mu
+	mu.Lock()
+	var once sync.Once
+	return func() { once.Do(mu.Unlock) }
+}
+
+// LockKey locks key against other LockKey callers and returns the function
+// releasing it, for read-modify-write sequences involving external systems.
+// The lock is advisory: Get, Set and the other operations ignore it. Keys
+// share a fixed set of locks, so a goroutine must not hold two keys at once.
+//
+// Example:
+//
+//	unlock := cache.LockKey("balance:42")
+//	defer unlock()
+//	balance, _ := cache.Get("balance:42")
+//	newBalance, err := ledger.Debit(ctx, "42", balance.(int64), amount)
+//	if err == nil {
+//	    cache.Set("balance:42", newBalance)
+//	}
+func (c *wtinyLFUCache) LockKey(key string) func() {
+	return c.keyLocks.lock(c.hashKey(key))
+}
+
+// LockKey locks key against other LockKey callers and returns the function
+// releasing it (see Cache.LockKey).
+func (c *GenericCache[K, V]) LockKey(key K) func() {
+	return c.inner.LockKey(keyToString(key))
+}
@@ -0,0 +1,105 @@
+// lock_key_test.go: tests for LockKey
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockKey_ReadModifyWrite(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	external := 0 // Stands for a system outside the cache; unsynchronized on purpose
+	cache.Set("counter", 0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				unlock := cache.LockKey("counter")
+				v, _ := cache.Get("counter")
+				external++
+				cache.Set("counter", v.(int)+1)
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := cache.Get("counter"); v != 1600 || external != 1600 {
+		t.Fatalf("lost updates: cache %v, external %d, want 1600", v, external)
+	}
+}
+
+func TestLockKey_Stripes(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	// Find a key on another stripe than "a"
+	stripe := func(key string) uint64 { return cache.hashKey(key) >> (64 - keyLockBits) }
+	other := ""
+	for i := 0; other == ""; i++ {
+		if key := "k" + strconv.Itoa(i); stripe(key) != stripe("a") {
+			other = key
+		}
+	}
+
+	unlock := cache.LockKey("a")
+	done := make(chan struct{})
+	go func() {
+		cache.LockKey(other)()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a key on another stripe must not wait")
+	}
+
+	blocked := make(chan struct{})
+	go func() {
+		cache.LockKey("a")()
+		close(blocked)
+	}()
+	select {
+	case <-blocked:
+		t.Fatal("the same key must wait for the holder")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	unlock() // Idempotent: must not unlock the next holder
+	<-blocked
+}
+
+func TestLockKey_SwappableAndGeneric(t *testing.T) {
+	handle := NewSwappableCache(NewCache(Config{MaxSize: 10}))
+	unlock := handle.LockKey("k")
+	old := handle.Replace(NewCache(Config{MaxSize: 10}))
+	defer old.Close()
+	defer handle.Close()
+
+	acquired := make(chan struct{})
+	go func() {
+		handle.LockKey("k")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("a lock taken before Replace must hold after it")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	<-acquired
+
+	generic := NewGenericCache[int, string](Config{MaxSize: 10})
+	defer generic.Close()
+	generic.LockKey(42)()
+}
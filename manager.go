@@ -0,0 +1,200 @@
+// manager.go: lifecycle management of named caches and their dependencies
+//
+// Manager registers caches and the components they depend on, and closes
+// them in dependency order with a single Shutdown call.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ContextShutdowner is implemented by components whose shutdown can be
+// bounded by a context (e.g. a write-behind queue draining to a store).
+// Manager prefers it over io.Closer.
+type ContextShutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// managedComponent is a component registered with a Manager.
+type managedComponent struct {
+	name       string
+	closer     io.Closer
+	dependsOn  []string
+	dependents int // Number of registered components depending on this one
+}
+
+// Manager owns a set of named caches and related components and shuts them
+// down in dependency order.
+//
+// Example:
+//
+//	m := balios.NewManager()
+//	_ = m.Register("l2", redisAdapter)
+//	_ = m.Register("sessions", sessionCache, "l2") // sessions flushes into l2
+//	_ = m.Register("users", userCache)
+//	defer m.Shutdown(ctx) // Closes sessions and users, then l2
+type Manager struct {
+	mu         sync.Mutex
+	components map[string]*managedComponent
+	order      []string // Registration order
+	shutdown   bool
+}
+
+// NewManager creates an empty Manager.
+func NewManager() *Manager {
+	return &Manager{components: make(map[string]*managedComponent)}
+}
+
+// Register adds a component under name. dependsOn lists components (already
+// registered) that must stay open until this one is closed.
+//
+// Returns a BALIOS_INVALID_CONFIG error for empty or duplicate names, a nil
+// component, unknown dependencies, or registration after Shutdown.
+func (m *Manager) Register(name string, component io.Closer, dependsOn ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case m.shutdown:
+		return NewErrInvalidConfig(name, "manager is shut down")
+	case name == "":
+		return NewErrInvalidConfig("name", "component name cannot be empty")
+	case component == nil:
+		return NewErrInvalidConfig(name, "component cannot be nil")
+	case m.components[name] != nil:
+		return NewErrInvalidConfig(name, "component already registered")
+	}
+	for _, dep := range dependsOn {
+		if m.components[dep] == nil {
+			return NewErrInvalidConfig(name, "unknown dependency "+dep+" (register dependencies first)")
+		}
+	}
+
+	for _, dep := range dependsOn {
+		m.components[dep].dependents++
+	}
+	m.components[name] = &managedComponent{
+		name:      name,
+		closer:    component,
+		dependsOn: append([]string(nil), dependsOn...),
+	}
+	m.order = append(m.order, name)
+	return nil
+}
+
+// Lookup returns the component registered under name.
+func (m *Manager) Lookup(name string) (io.Closer, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c := m.components[name]; c != nil {
+		return c.closer, true
+	}
+	return nil, false
+}
+
+// Cache returns the component registered under name if it is a Cache.
+func (m *Manager) Cache(name string) (Cache, bool) {
+	component, ok := m.Lookup(name)
+	if !ok {
+		return nil, false
+	}
+	cache, ok := component.(Cache)
+	return cache, ok
+}
+
+// shutdownWaves groups components so that every component appears in a later
+// wave than all of its dependents. Within a wave, registration order is kept.
+func (m *Manager) shutdownWaves() [][]*managedComponent {
+	remaining := make(map[string]int, len(m.components))
+	for name, c := range m.components {
+		remaining[name] = c.dependents
+	}
+
+	var waves [][]*managedComponent
+	closed := 0
+	for closed < len(m.order) {
+		var wave []*managedComponent
+		for _, name := range m.order {
+			if n, pending := remaining[name]; pending && n == 0 {
+				wave = append(wave, m.components[name])
+			}
+		}
+		for _, c := range wave {
+			delete(remaining, c.name)
+			for _, dep := range c.dependsOn {
+				remaining[dep]--
+			}
+		}
+		closed += len(wave)
+		waves = append(waves, wave)
+	}
+	return waves
+}
+
+// Shutdown closes every registered component, dependents before their
+// dependencies, and returns the joined BALIOS_SHUTDOWN_FAILED errors of the
+// components that failed to close.
+//
+// If ctx is done before all waves complete, Shutdown stops waiting, leaves
+// the remaining waves open (their dependents may still be flushing) and
+// returns ctx.Err() joined with the errors collected so far. Calling Shutdown
+// again after it returned is a no-op.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.shutdown {
+		m.mu.Unlock()
+		return nil
+	}
+	m.shutdown = true
+	waves := m.shutdownWaves()
+	m.mu.Unlock()
+
+	var (
+		errMu sync.Mutex
+		errs  []error
+	)
+	for _, wave := range waves {
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(len(wave))
+		for _, c := range wave {
+			go func(c *managedComponent) {
+				defer wg.Done()
+				if err := closeComponent(ctx, c.closer); err != nil {
+					errMu.Lock()
+					errs = append(errs, NewErrShutdownFailed(c.name, err))
+					errMu.Unlock()
+				}
+			}(c)
+		}
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			errMu.Lock()
+			defer errMu.Unlock()
+			return errors.Join(append([]error{ctx.Err()}, errs...)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeComponent closes c, preferring ContextShutdowner.
+func closeComponent(ctx context.Context, c io.Closer) error {
+	if s, ok := c.(ContextShutdowner); ok {
+		return s.Shutdown(ctx)
+	}
+	return c.Close()
+}
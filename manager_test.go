@@ -0,0 +1,153 @@
+// manager_test.go: tests for dependency-ordered shutdown of managed caches
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// shutdownLog records the order in which components are closed.
+type shutdownLog struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (l *shutdownLog) add(name string) {
+	l.mu.Lock()
+	l.order = append(l.order, name)
+	l.mu.Unlock()
+}
+
+func (l *shutdownLog) index(name string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, n := range l.order {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// fakeComponent is an io.Closer recording its shutdown.
+type fakeComponent struct {
+	name  string
+	log   *shutdownLog
+	err   error
+	delay time.Duration
+}
+
+func (f *fakeComponent) Close() error {
+	time.Sleep(f.delay)
+	f.log.add(f.name)
+	return f.err
+}
+
+// ctxComponent implements ContextShutdowner.
+type ctxComponent struct {
+	fakeComponent
+	gotCtx bool
+}
+
+func (c *ctxComponent) Shutdown(ctx context.Context) error {
+	c.gotCtx = ctx != nil
+	return c.Close()
+}
+
+func TestManager_ShutdownOrder(t *testing.T) {
+	log := &shutdownLog{}
+	m := NewManager()
+
+	l2 := &ctxComponent{fakeComponent: fakeComponent{name: "l2", log: log}}
+	mustRegister(t, m, "l2", l2)
+	mustRegister(t, m, "writeBehind", &fakeComponent{name: "writeBehind", log: log, delay: 10 * time.Millisecond}, "l2")
+	mustRegister(t, m, "derived", &fakeComponent{name: "derived", log: log}, "writeBehind")
+	mustRegister(t, m, "users", NewCache(Config{MaxSize: 10}))
+	mustRegister(t, m, "audit", &fakeComponent{name: "audit", log: log}, "l2")
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if log.index("derived") > log.index("writeBehind") || log.index("writeBehind") > log.index("l2") ||
+		log.index("audit") > log.index("l2") {
+		t.Errorf("dependents must close before their dependencies: %v", log.order)
+	}
+	if len(log.order) != 4 || !l2.gotCtx {
+		t.Errorf("every component must be closed once (ctx-aware ones via Shutdown): %v", log.order)
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil || len(log.order) != 4 {
+		t.Error("second Shutdown must be a no-op")
+	}
+	if err := m.Register("late", &fakeComponent{}); GetErrorCode(err) != ErrCodeInvalidConfig {
+		t.Errorf("registration after shutdown must fail, got %v", err)
+	}
+}
+
+func TestManager_RegisterValidation(t *testing.T) {
+	m := NewManager()
+	mustRegister(t, m, "a", NewCache(Config{MaxSize: 10}))
+
+	for name, err := range map[string]error{
+		"empty":     m.Register("", &fakeComponent{}),
+		"nil":       m.Register("b", nil),
+		"duplicate": m.Register("a", &fakeComponent{}),
+		"unknown":   m.Register("c", &fakeComponent{}, "missing"),
+	} {
+		if GetErrorCode(err) != ErrCodeInvalidConfig || !IsConfigError(err) {
+			t.Errorf("%s: expected %s, got %v", name, ErrCodeInvalidConfig, err)
+		}
+	}
+
+	if c, ok := m.Cache("a"); !ok || c.Capacity() != 10 {
+		t.Error("Cache lookup failed")
+	}
+	if _, ok := m.Cache("c"); ok {
+		t.Error("failed registration must not be visible")
+	}
+}
+
+func TestManager_ShutdownErrorsAndTimeout(t *testing.T) {
+	log := &shutdownLog{}
+	boom := errors.New("flush failed")
+
+	m := NewManager()
+	mustRegister(t, m, "store", &fakeComponent{name: "store", log: log})
+	mustRegister(t, m, "broken", &fakeComponent{name: "broken", log: log, err: boom}, "store")
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, boom) || GetErrorCode(err) != ErrCodeShutdownFailed {
+		t.Errorf("expected wrapped close error, got %v", err)
+	}
+	if log.index("store") < 0 {
+		t.Error("a failing dependent must not prevent closing its dependencies")
+	}
+
+	log = &shutdownLog{}
+	m = NewManager()
+	mustRegister(t, m, "store", &fakeComponent{name: "store", log: log})
+	mustRegister(t, m, "slow", &fakeComponent{name: "slow", log: log, delay: time.Second}, "store")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	if log.index("store") >= 0 {
+		t.Error("dependencies must stay open while a dependent is still closing")
+	}
+}
+
+func mustRegister(t *testing.T, m *Manager, name string, c interface{ Close() error }, deps ...string) {
+	t.Helper()
+	if err := m.Register(name, c, deps...); err != nil {
+		t.Fatalf("Register(%s): %v", name, err)
+	}
+}
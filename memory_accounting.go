@@ -0,0 +1,308 @@
+// memory_accounting.go: per-entry memory accounting for debugging
+//
+// With Config.MemoryAccounting every write records the size of its key and
+// value, and MemoryUsage reports the totals and the largest entries.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+)
+
+// maxSizeOfDepth bounds the recursion of the reflection-based sizer.
+const maxSizeOfDepth = 64
+
+// KeyMemoryUsage is the memory recorded for one entry.
+type KeyMemoryUsage struct {
+	Key        string
+	KeyBytes   int64 // Length of the key
+	ValueBytes int64 // Size of the value when it was stored (see Config.SizeOf)
+}
+
+// Bytes returns the total recorded size of the entry.
+func (u KeyMemoryUsage) Bytes() int64 {
+	return u.KeyBytes + u.ValueBytes
+}
+
+// MemoryReport summarizes the memory recorded for live entries.
+type MemoryReport struct {
+	Enabled    bool  // Whether Config.MemoryAccounting is set
+	Entries    int   // Live entries accounted for
+	KeyBytes   int64 // Sum of key sizes
+	ValueBytes int64 // Sum of value sizes
+
+	// Top holds the largest entries, by total size, in descending order.
+	Top []KeyMemoryUsage
+}
+
+// WriteTo prints the report as a table. It implements io.WriterTo.
+func (r MemoryReport) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	if !r.Enabled {
+		_, err := fmt.Fprintln(cw, "memory accounting disabled (set Config.MemoryAccounting)")
+		return cw.n, err
+	}
+
+	tw := tabwriter.NewWriter(cw, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "entries: %d\tkeys: %d B\tvalues: %d B\ttotal: %d B\t\n",
+		r.Entries, r.KeyBytes, r.ValueBytes, r.KeyBytes+r.ValueBytes)
+	if len(r.Top) > 0 {
+		fmt.Fprintln(tw, "total B\tkey B\tvalue B\tkey\t")
+		for _, u := range r.Top {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%q\t\n", u.Bytes(), u.KeyBytes, u.ValueBytes, u.Key)
+		}
+	}
+	err := tw.Flush()
+	return cw.n, err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// memoryAccountant records the size of every written entry.
+type memoryAccountant struct {
+	sizeOf     func(interface{}) int64
+	records    sync.Map // key -> int64 (value bytes)
+	count      int64    // Approximate number of records, including stale ones
+	pruneAbove int64
+	pruning    int32
+}
+
+func newMemoryAccountant(config Config) *memoryAccountant {
+	if !config.MemoryAccounting {
+		return nil
+	}
+	sizeOf := config.SizeOf
+	if sizeOf == nil {
+		sizeOf = SizeOf
+	}
+	return &memoryAccountant{
+		sizeOf:     sizeOf,
+		pruneAbove: int64(2 * config.MaxSize),
+	}
+}
+
+// recordMemory records the size of a write of key.
+func (c *wtinyLFUCache) recordMemory(key string, value interface{}) {
+	m := c.memory
+	if _, loaded := m.records.Swap(key, m.sizeOf(value)); loaded {
+		return
+	}
+	if atomic.AddInt64(&m.count, 1) > m.pruneAbove && atomic.CompareAndSwapInt32(&m.pruning, 0, 1) {
+		m.records.Range(func(k, _ interface{}) bool {
+			if key := k.(string); c.findEntry(key, c.hashKey(key)) == nil {
+				m.forget(key)
+			}
+			return true
+		})
+		atomic.StoreInt32(&m.pruning, 0)
+	}
+}
+
+// forget drops the record of key.
+func (m *memoryAccountant) forget(key string) {
+	if _, loaded := m.records.LoadAndDelete(key); loaded {
+		atomic.AddInt64(&m.count, -1)
+	}
+}
+
+func (m *memoryAccountant) reset() {
+	m.records.Range(func(k, _ interface{}) bool {
+		m.forget(k.(string))
+		return true
+	})
+}
+
+// usageHeap is a min-heap of entries by total size, used to keep the top N.
+type usageHeap []KeyMemoryUsage
+
+func (h usageHeap) Len() int            { return len(h) }
+func (h usageHeap) Less(i, j int) bool  { return h[i].Bytes() < h[j].Bytes() }
+func (h usageHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *usageHeap) Push(x interface{}) { *h = append(*h, x.(KeyMemoryUsage)) }
+func (h *usageHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// MemoryUsage returns the memory recorded for live entries and the top
+// largest of them. It walks the table: use it for debugging, not on hot paths.
+// Returns a report with Enabled false unless Config.MemoryAccounting is set.
+func (c *wtinyLFUCache) MemoryUsage(top int) MemoryReport {
+	m := c.memory
+	if m == nil || c.isClosed() {
+		return MemoryReport{}
+	}
+	t := c.table.Load()
+	if t == nil {
+		return MemoryReport{} // Closed
+	}
+	g := t.gen.Load()
+
+	report := MemoryReport{Enabled: true}
+	live := make(map[string]struct{}, t.size())
+	h := make(usageHeap, 0, top)
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+
+	for i := range t.entries {
+		entry := &t.entries[i]
+		if atomic.LoadInt32(&entry.valid) != g.live || c.isExpired(entry, ttlNow) {
+			continue
+		}
+		key := entry.loadKey()
+		if key == "" {
+			continue
+		}
+		recorded, ok := m.records.Load(key)
+		if !ok {
+			continue // Written concurrently, recorded right after
+		}
+		live[key] = struct{}{}
+
+		usage := KeyMemoryUsage{Key: key, KeyBytes: int64(len(key)), ValueBytes: recorded.(int64)}
+		report.Entries++
+		report.KeyBytes += usage.KeyBytes
+		report.ValueBytes += usage.ValueBytes
+
+		switch {
+		case top <= 0:
+		case len(h) < top:
+			heap.Push(&h, usage)
+		case usage.Bytes() > h[0].Bytes():
+			h[0] = usage
+			heap.Fix(&h, 0)
+		}
+	}
+
+	// Drop records of keys that left the cache
+	m.records.Range(func(k, _ interface{}) bool {
+		if _, ok := live[k.(string)]; !ok {
+			if key := k.(string); c.findEntry(key, c.hashKey(key)) == nil {
+				m.forget(key)
+			}
+		}
+		return true
+	})
+
+	sort.Slice(h, func(i, j int) bool { return h[i].Bytes() > h[j].Bytes() })
+	report.Top = h
+	return report
+}
+
+// MemoryUsage returns the memory recorded for live entries and the top
+// largest of them. See the Cache interface for details.
+func (c *GenericCache[K, V]) MemoryUsage(top int) MemoryReport {
+	return c.inner.MemoryUsage(top)
+}
+
+// SizeOf returns the number of bytes occupied by v and the memory reachable
+// from it: the value itself plus the backing arrays of strings and slices
+// and the targets of pointers, each counted once. Map sizes are
+// approximated by their entries; channels and functions count as their
+// header only. It is the default Config.SizeOf.
+func SizeOf(v interface{}) int64 {
+	if v == nil {
+		return 0
+	}
+	rv := reflect.ValueOf(v)
+	visited := make(map[uintptr]struct{})
+	return int64(rv.Type().Size()) + referencedBytes(rv, visited, 0)
+}
+
+// referencedBytes returns the bytes reachable from v, excluding v itself.
+func referencedBytes(v reflect.Value, visited map[uintptr]struct{}, depth int) int64 {
+	if depth > maxSizeOfDepth {
+		return 0
+	}
+	depth++
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Ptr:
+		if v.IsNil() || !markVisited(v.Pointer(), visited) {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + referencedBytes(elem, visited, depth)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + referencedBytes(elem, visited, depth)
+
+	case reflect.Slice:
+		if v.IsNil() || !markVisited(v.Pointer(), visited) {
+			return 0
+		}
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += referencedBytes(v.Index(i), visited, depth)
+		}
+		return size
+
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += referencedBytes(v.Index(i), visited, depth)
+		}
+		return size
+
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += referencedBytes(v.Field(i), visited, depth)
+		}
+		return size
+
+	case reflect.Map:
+		if v.IsNil() || !markVisited(v.Pointer(), visited) {
+			return 0
+		}
+		entrySize := int64(v.Type().Key().Size() + v.Type().Elem().Size())
+		size := int64(v.Len()) * entrySize
+		iter := v.MapRange()
+		for iter.Next() {
+			size += referencedBytes(iter.Key(), visited, depth)
+			size += referencedBytes(iter.Value(), visited, depth)
+		}
+		return size
+
+	default:
+		return 0
+	}
+}
+
+// markVisited records p and reports whether it was not visited before.
+func markVisited(p uintptr, visited map[uintptr]struct{}) bool {
+	if _, seen := visited[p]; seen {
+		return false
+	}
+	visited[p] = struct{}{}
+	return true
+}
@@ -0,0 +1,153 @@
+// memory_accounting_test.go: tests for per-entry memory accounting
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestSizeOf(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+		Data []byte
+	}
+	shared := &node{Name: "shared"}
+	cyclic := &node{Name: "a"}
+	cyclic.Next = cyclic
+
+	stringHeader := int64(unsafe.Sizeof(""))
+	sliceHeader := int64(unsafe.Sizeof([]byte(nil)))
+	nodeSize := int64(unsafe.Sizeof(node{}))
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  int64
+	}{
+		{"nil", nil, 0},
+		{"int64", int64(1), 8},
+		{"string", "hello", stringHeader + 5},
+		{"bytes", make([]byte, 3, 10), sliceHeader + 10},
+		{"strings", []string{"ab", "c"}, sliceHeader + 2*stringHeader + 3},
+		{"struct pointer", &node{Name: "n", Data: []byte{1, 2}}, 8 + nodeSize + 1 + 2},
+		{"cycle counted once", cyclic, 8 + nodeSize + 1},
+		{"shared pointer counted once", []*node{shared, shared}, sliceHeader + 2*8 + nodeSize + 6},
+	}
+	for _, tt := range tests {
+		if got := SizeOf(tt.value); got != tt.want {
+			t.Errorf("%s: SizeOf = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryUsage_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("k", "v")
+	report := cache.MemoryUsage(10)
+	if report.Enabled || report.Entries != 0 {
+		t.Errorf("expected empty disabled report, got %+v", report)
+	}
+
+	var buf bytes.Buffer
+	if _, err := report.WriteTo(&buf); err != nil || !strings.Contains(buf.String(), "disabled") {
+		t.Errorf("disabled report must say so: %q, %v", buf.String(), err)
+	}
+}
+
+func TestMemoryUsage_TopKeys(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, MemoryAccounting: true}).(*wtinyLFUCache)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("small:%d", i), make([]byte, 10))
+	}
+	cache.Set("big:1", make([]byte, 10000))
+	cache.Set("big:2", make([]byte, 5000))
+	cache.Set("big:3", make([]byte, 20000))
+
+	report := cache.MemoryUsage(3)
+	if !report.Enabled || report.Entries != 103 {
+		t.Fatalf("expected 103 accounted entries, got %+v", report)
+	}
+	want := []string{"big:3", "big:1", "big:2"}
+	if len(report.Top) != len(want) {
+		t.Fatalf("expected %d top keys, got %d", len(want), len(report.Top))
+	}
+	for i, key := range want {
+		if report.Top[i].Key != key {
+			t.Errorf("top[%d] = %q, want %q", i, report.Top[i].Key, key)
+		}
+	}
+	if report.Top[0].ValueBytes != SizeOf(make([]byte, 20000)) || report.Top[0].KeyBytes != 5 {
+		t.Errorf("unexpected sizes for big:3: %+v", report.Top[0])
+	}
+
+	var total int64
+	for i := 0; i < 100; i++ {
+		total += int64(len(fmt.Sprintf("small:%d", i)))
+	}
+	if report.KeyBytes != total+3*5 {
+		t.Errorf("KeyBytes = %d, want %d", report.KeyBytes, total+15)
+	}
+
+	var buf bytes.Buffer
+	if _, err := report.WriteTo(&buf); err != nil || !strings.Contains(buf.String(), `"big:3"`) {
+		t.Errorf("printed report must list the top keys: %q, %v", buf.String(), err)
+	}
+}
+
+func TestMemoryUsage_TracksUpdatesAndRemovals(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MemoryAccounting: true}).(*wtinyLFUCache)
+	cache.Set("k", make([]byte, 100))
+	cache.Set("k", make([]byte, 1000))
+	cache.Set("gone", make([]byte, 5000))
+	cache.Delete("gone")
+
+	report := cache.MemoryUsage(10)
+	if report.Entries != 1 || report.Top[0].ValueBytes != SizeOf(make([]byte, 1000)) {
+		t.Errorf("expected the latest size of k only, got %+v", report)
+	}
+	if _, ok := cache.memory.records.Load("gone"); ok {
+		t.Error("MemoryUsage must drop records of removed keys")
+	}
+
+	cache.Swap("k", make([]byte, 10))
+	if report := cache.MemoryUsage(1); report.Top[0].ValueBytes != SizeOf(make([]byte, 10)) {
+		t.Errorf("Swap must update the recorded size, got %+v", report.Top[0])
+	}
+}
+
+func TestMemoryUsage_CustomSizeOfAndBound(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:          10,
+		MemoryAccounting: true,
+		SizeOf:           func(interface{}) int64 { return 7 },
+	}).(*wtinyLFUCache)
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	m := cache.memory
+	if m.count > m.pruneAbove {
+		t.Errorf("records must stay bounded: %d > %d", m.count, m.pruneAbove)
+	}
+	report := cache.MemoryUsage(0)
+	if report.Top != nil && len(report.Top) != 0 {
+		t.Errorf("top 0 must return no keys, got %d", len(report.Top))
+	}
+	if report.ValueBytes != int64(7*report.Entries) {
+		t.Errorf("custom SizeOf not used: %+v", report)
+	}
+
+	cache.Clear()
+	if report := cache.MemoryUsage(10); report.Entries != 0 || m.count != 0 {
+		t.Errorf("Clear must drop all records, got %+v (count %d)", report, m.count)
+	}
+}
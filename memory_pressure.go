@@ -0,0 +1,120 @@
+// memory_pressure.go: eviction driven by the process memory limit
+//
+// With Config.MemoryPressureThreshold, a background goroutine evicts
+// entries while process memory exceeds that fraction of the limit.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMemoryPressureInterval is the default sampling period of the
+// memory-pressure controller.
+const DefaultMemoryPressureInterval = time.Second
+
+const (
+	// memoryPressureMinShare is the share of the entries evicted by a sample
+	// at the threshold.
+	memoryPressureMinShare = 0.1
+
+	// memoryPressureMaxShare is the share of the entries evicted by a sample
+	// at or beyond the limit.
+	memoryPressureMaxShare = 0.5
+)
+
+// MemoryPressureRecorder is an optional MetricsCollector extension.
+// Collectors implementing it are notified of every sample of the
+// memory-pressure controller (see Config.MemoryPressureThreshold).
+type MemoryPressureRecorder interface {
+	// RecordMemoryPressure records the memory usage as a fraction of the
+	// limit and the number of entries evicted to relieve it (0 below the
+	// threshold).
+	RecordMemoryPressure(usage float64, evicted int)
+}
+
+// runtimeMemoryUsage returns a reader of the memory counted against the
+// runtime memory limit, and of limit (the runtime soft limit if limit <= 0).
+// The reader is not safe for concurrent use.
+func runtimeMemoryUsage(limit int64) func() (used, limit uint64) {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+		{Name: "/gc/gomemlimit:bytes"},
+	}
+	return func() (uint64, uint64) {
+		metrics.Read(samples)
+		var used, released, runtimeLimit uint64
+		if samples[0].Value.Kind() == metrics.KindUint64 {
+			used = samples[0].Value.Uint64()
+		}
+		if samples[1].Value.Kind() == metrics.KindUint64 {
+			released = samples[1].Value.Uint64()
+		}
+		if samples[2].Value.Kind() == metrics.KindUint64 {
+			runtimeLimit = samples[2].Value.Uint64()
+		}
+		if released < used {
+			used -= released
+		}
+		if limit > 0 {
+			return used, uint64(limit) // #nosec G115 -- limit is positive
+		}
+		return used, runtimeLimit
+	}
+}
+
+// runMemoryPressure samples the memory usage every interval until Close is
+// called.
+func (c *wtinyLFUCache) runMemoryPressure(interval time.Duration, threshold float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopPressure:
+			return
+		case <-ticker.C:
+			c.relieveMemoryPressure(threshold)
+		}
+	}
+}
+
+// relieveMemoryPressure takes one sample of the memory usage and, above
+// threshold, evicts a share of the entries growing with the overshoot. It
+// returns the number of entries evicted.
+func (c *wtinyLFUCache) relieveMemoryPressure(threshold float64) int {
+	used, limit := c.memoryUsage()
+	if limit == 0 || limit >= math.MaxInt64 {
+		return 0 // No memory limit
+	}
+
+	usage := float64(used) / float64(limit)
+	evicted := 0
+	if usage >= threshold {
+		overshoot := math.Min((usage-threshold)/(1-threshold), 1)
+		share := memoryPressureMinShare + (memoryPressureMaxShare-memoryPressureMinShare)*overshoot
+		target := int(math.Round(float64(c.entryCount()) * share))
+		if target == 0 && c.entryCount() > 0 {
+			target = 1
+		}
+		evicted = c.evictEntries(target)
+		if evicted > 0 {
+			atomic.AddInt64(&c.pressureEvicted, int64(evicted))
+			c.logger.Debug("balios: evicted entries under memory pressure",
+				"usage", usage, "threshold", threshold, "evicted", evicted)
+		}
+	}
+
+	if c.pressureRecorder != nil {
+		c.pressureRecorder.RecordMemoryPressure(usage, evicted)
+	}
+	return evicted
+}
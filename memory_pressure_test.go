@@ -0,0 +1,153 @@
+// memory_pressure_test.go: tests for the memory-pressure controller
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pressureCollector records memory-pressure samples.
+type pressureCollector struct {
+	NoOpMetricsCollector
+	samples int64
+	evicted int64
+	usage   atomic.Value // float64
+}
+
+func (p *pressureCollector) RecordMemoryPressure(usage float64, evicted int) {
+	atomic.AddInt64(&p.samples, 1)
+	atomic.AddInt64(&p.evicted, int64(evicted))
+	p.usage.Store(usage)
+}
+
+// fixedMemoryUsage returns a memory reader reporting used out of limit.
+func fixedMemoryUsage(used, limit uint64) func() (uint64, uint64) {
+	return func() (uint64, uint64) { return used, limit }
+}
+
+func TestMemoryPressure_EvictsShareByOvershoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		used      uint64
+		wantEvict int
+	}{
+		{"below threshold", 70, 0},
+		{"at threshold", 80, 10},
+		{"halfway to the limit", 90, 30},
+		{"at the limit", 100, 50},
+		{"beyond the limit", 150, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &pressureCollector{}
+			cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector}).(*wtinyLFUCache)
+			defer func() { _ = cache.Close() }()
+			for i := 0; i < 100; i++ {
+				cache.Set("key"+strconv.Itoa(i), i)
+			}
+
+			cache.memoryUsage = fixedMemoryUsage(tt.used, 100)
+			if got := cache.relieveMemoryPressure(0.8); got != tt.wantEvict {
+				t.Errorf("evicted %d entries, want %d", got, tt.wantEvict)
+			}
+			if got := cache.Len(); got != 100-tt.wantEvict {
+				t.Errorf("Len = %d, want %d", got, 100-tt.wantEvict)
+			}
+			if got := cache.DebugStats().MemoryPressureEvictions; got != uint64(tt.wantEvict) {
+				t.Errorf("MemoryPressureEvictions = %d, want %d", got, tt.wantEvict)
+			}
+			if collector.samples != 1 || collector.evicted != int64(tt.wantEvict) {
+				t.Errorf("recorded %d samples, %d evictions", collector.samples, collector.evicted)
+			}
+			if usage := collector.usage.Load().(float64); usage != float64(tt.used)/100 {
+				t.Errorf("recorded usage %v", usage)
+			}
+		})
+	}
+}
+
+func TestMemoryPressure_NoLimit(t *testing.T) {
+	collector := &pressureCollector{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+	cache.Set("key", 1)
+
+	for _, limit := range []uint64{0, math.MaxInt64} {
+		cache.memoryUsage = fixedMemoryUsage(1<<40, limit)
+		if got := cache.relieveMemoryPressure(0.5); got != 0 {
+			t.Errorf("limit %d: evicted %d entries", limit, got)
+		}
+	}
+	if cache.Len() != 1 || collector.samples != 0 {
+		t.Errorf("controller acted without a limit: Len = %d, samples = %d", cache.Len(), collector.samples)
+	}
+}
+
+func TestMemoryPressure_EvictsInBackground(t *testing.T) {
+	var evictions int64
+	cache := NewCache(Config{
+		MaxSize:                 1000,
+		MemoryPressureThreshold: 0.9,
+		MemoryPressureInterval:  time.Millisecond,
+		MemoryLimit:             1, // Always exceeded
+		OnEvict: func(key string, value interface{}, reason EvictReason) {
+			if reason == ReasonEvicted {
+				atomic.AddInt64(&evictions, 1)
+			}
+		},
+	})
+	defer func() { _ = cache.Close() }()
+	for i := 0; i < 100; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.Len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("controller did not evict, Len = %d", cache.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt64(&evictions) == 0 {
+		t.Error("evictions not reported to OnEvict")
+	}
+}
+
+func TestMemoryPressure_Config(t *testing.T) {
+	for _, threshold := range []float64{-0.5, 1, 2, math.NaN()} {
+		config := Config{MemoryPressureThreshold: threshold}
+		_ = config.Validate()
+		if config.MemoryPressureThreshold != 0 {
+			t.Errorf("threshold %v kept as %v", threshold, config.MemoryPressureThreshold)
+		}
+	}
+	config := Config{MemoryPressureThreshold: 0.85}
+	_ = config.Validate()
+	if config.MemoryPressureThreshold != 0.85 || config.MemoryPressureInterval != DefaultMemoryPressureInterval {
+		t.Errorf("unexpected normalization: %v, %v", config.MemoryPressureThreshold, config.MemoryPressureInterval)
+	}
+
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+	if cache.stopPressure != nil {
+		t.Error("controller must not start without MemoryPressureThreshold")
+	}
+}
+
+func TestRuntimeMemoryUsage(t *testing.T) {
+	used, limit := runtimeMemoryUsage(0)()
+	if used == 0 || limit == 0 {
+		t.Errorf("runtime usage = %d, limit = %d", used, limit)
+	}
+	if _, limit := runtimeMemoryUsage(1 << 30)(); limit != 1<<30 {
+		t.Errorf("configured limit not used: %d", limit)
+	}
+}
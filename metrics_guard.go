@@ -0,0 +1,261 @@
+// metrics_guard.go: panic isolation for user-provided MetricsCollector implementations
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// guardedMetricsCollector wraps a MetricsCollector and isolates the cache hot path
+// from panics raised by third-party collector implementations.
+//
+// DESIGN RATIONALE:
+//   - A collector runs inline on every Get/Set/Delete, so a panic there would
+//     crash the caller even though the cache itself is perfectly healthy
+//   - On the first panic the collector is permanently disabled (graceful degradation)
+//     and the event is logged exactly once through Config.Logger
+//   - Subsequent calls short-circuit on a single atomic load
+//
+// PERFORMANCE IMPACT:
+//   - One atomic load + one open-coded defer per recording (~1-2ns)
+//   - NoOpMetricsCollector is never wrapped, preserving its zero-overhead guarantee
+type guardedMetricsCollector struct {
+	inner       MetricsCollector
+	probe       ProbeMetricsCollector   // inner as ProbeMetricsCollector, nil if not implemented
+	sweep       ExpirationSweepRecorder // inner as ExpirationSweepRecorder, nil if not implemented
+	retry       LoadRetryRecorder       // inner as LoadRetryRecorder, nil if not implemented
+	pressure    MemoryPressureRecorder  // inner as MemoryPressureRecorder, nil if not implemented
+	negative    NegativeCacheRecorder   // inner as NegativeCacheRecorder, nil if not implemented
+	probeCount  ProbeCountRecorder      // inner as ProbeCountRecorder, nil if not implemented
+	race        RaceConditionRecorder   // inner as RaceConditionRecorder, nil if not implemented
+	circuit     CircuitBreakerRecorder  // inner as CircuitBreakerRecorder, nil if not implemented
+	writeBehind WriteBehindRecorder     // inner as WriteBehindRecorder, nil if not implemented
+	keySample   KeySampleRecorder       // inner as KeySampleRecorder, nil if not implemented
+	context     contextMetricsCollector // inner accepting Get contexts, nil if not (see metrics_v2.go)
+	logger      Logger
+	disabled    int32 // atomic flag: 1 once the inner collector has panicked
+}
+
+// activeMetricsCollector returns the collector operations report to: nil for
+// NoOpMetricsCollector, so that they skip the calls and the latency
+// measurement nobody records, collector guarded by newGuardedMetricsCollector
+// otherwise.
+func activeMetricsCollector(collector MetricsCollector, logger Logger) MetricsCollector {
+	if _, noop := collector.(NoOpMetricsCollector); noop || collector == nil {
+		return nil
+	}
+	return newGuardedMetricsCollector(collector, logger)
+}
+
+// newGuardedMetricsCollector returns collector wrapped with panic recovery.
+// NoOpMetricsCollector and already-guarded collectors are returned unchanged.
+func newGuardedMetricsCollector(collector MetricsCollector, logger Logger) MetricsCollector {
+	switch collector.(type) {
+	case NoOpMetricsCollector, *guardedMetricsCollector:
+		return collector
+	}
+	if logger == nil {
+		logger = NoOpLogger{}
+	}
+	probe, _ := collector.(ProbeMetricsCollector)
+	sweep, _ := collector.(ExpirationSweepRecorder)
+	retry, _ := collector.(LoadRetryRecorder)
+	pressure, _ := collector.(MemoryPressureRecorder)
+	negative, _ := collector.(NegativeCacheRecorder)
+	probeCount, _ := collector.(ProbeCountRecorder)
+	race, _ := collector.(RaceConditionRecorder)
+	circuit, _ := collector.(CircuitBreakerRecorder)
+	writeBehind, _ := collector.(WriteBehindRecorder)
+	keySample, _ := collector.(KeySampleRecorder)
+	contextual, _ := collector.(contextMetricsCollector)
+	return &guardedMetricsCollector{
+		inner:       collector,
+		probe:       probe,
+		sweep:       sweep,
+		retry:       retry,
+		pressure:    pressure,
+		negative:    negative,
+		probeCount:  probeCount,
+		race:        race,
+		circuit:     circuit,
+		writeBehind: writeBehind,
+		keySample:   keySample,
+		context:     contextual,
+		logger:      logger,
+	}
+}
+
+// probeMetricsOf returns the ProbeMetricsCollector of a collector built by
+// newGuardedMetricsCollector, or nil when there is nothing to record (no-op
+// collector, or a user collector not implementing the extension).
+func probeMetricsOf(collector MetricsCollector) ProbeMetricsCollector {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.probe != nil {
+		return g
+	}
+	return nil
+}
+
+// sweepRecorderOf returns the ExpirationSweepRecorder of a collector built by
+// newGuardedMetricsCollector, or nil when the collector does not implement it.
+func sweepRecorderOf(collector MetricsCollector) ExpirationSweepRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.sweep != nil {
+		return g
+	}
+	return nil
+}
+
+// retryRecorderOf returns the LoadRetryRecorder of a collector built by
+// newGuardedMetricsCollector, or nil when the collector does not implement it.
+func retryRecorderOf(collector MetricsCollector) LoadRetryRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.retry != nil {
+		return g
+	}
+	return nil
+}
+
+// pressureRecorderOf returns the MemoryPressureRecorder of a collector built
+// by newGuardedMetricsCollector, or nil when the collector does not implement it.
+func pressureRecorderOf(collector MetricsCollector) MemoryPressureRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.pressure != nil {
+		return g
+	}
+	return nil
+}
+
+// negativeRecorderOf returns the NegativeCacheRecorder of a collector built
+// by newGuardedMetricsCollector, or nil when the collector does not implement it.
+func negativeRecorderOf(collector MetricsCollector) NegativeCacheRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.negative != nil {
+		return g
+	}
+	return nil
+}
+
+// recoverPanic disables the collector if the deferred call observes a panic.
+// It MUST be invoked directly via defer so that recover() can intercept the panic.
+func (g *guardedMetricsCollector) recoverPanic(method string) {
+	if r := recover(); r != nil {
+		// Only the first panicking goroutine logs, keeping the log volume bounded
+		if atomic.CompareAndSwapInt32(&g.disabled, 0, 1) {
+			g.logger.Error("balios: metrics collector panicked, metrics collection disabled",
+				"method", method,
+				"panic", r,
+			)
+		}
+	}
+}
+
+// isDisabled reports whether the wrapped collector has been disabled after a panic.
+func (g *guardedMetricsCollector) isDisabled() bool {
+	return atomic.LoadInt32(&g.disabled) != 0
+}
+
+// RecordGet forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordGet(latencyNs int64, hit bool) {
+	if g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordGet")
+	g.inner.RecordGet(latencyNs, hit)
+}
+
+// RecordSet forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordSet(latencyNs int64) {
+	if g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordSet")
+	g.inner.RecordSet(latencyNs)
+}
+
+// RecordDelete forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordDelete(latencyNs int64) {
+	if g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordDelete")
+	g.inner.RecordDelete(latencyNs)
+}
+
+// RecordEviction forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordEviction() {
+	if g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordEviction")
+	g.inner.RecordEviction()
+}
+
+// RecordExpiration forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordExpiration() {
+	if g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordExpiration")
+	g.inner.RecordExpiration()
+}
+
+// RecordHas forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordHas(latencyNs int64, hit bool) {
+	if g.probe == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordHas")
+	g.probe.RecordHas(latencyNs, hit)
+}
+
+// RecordLen forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordLen() {
+	if g.probe == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordLen")
+	g.probe.RecordLen()
+}
+
+// RecordStats forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordStats() {
+	if g.probe == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordStats")
+	g.probe.RecordStats()
+}
+
+// RecordExpirationSweep forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordExpirationSweep(expired int, durationNs int64) {
+	if g.sweep == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordExpirationSweep")
+	g.sweep.RecordExpirationSweep(expired, durationNs)
+}
+
+// RecordMemoryPressure forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordMemoryPressure(usage float64, evicted int) {
+	if g.pressure == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordMemoryPressure")
+	g.pressure.RecordMemoryPressure(usage, evicted)
+}
+
+// RecordLoadRetry forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordLoadRetry(attempt int) {
+	if g.retry == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordLoadRetry")
+	g.retry.RecordLoadRetry(attempt)
+}
+
+// RecordNegativeLookup forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordNegativeLookup(hit bool) {
+	if g.negative == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordNegativeLookup")
+	g.negative.RecordNegativeLookup(hit)
+}
@@ -0,0 +1,188 @@
+// metrics_guard_test.go: tests for MetricsCollector panic isolation
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// panickingMetricsCollector panics on every call and counts invocations.
+type panickingMetricsCollector struct {
+	calls int64
+}
+
+func (p *panickingMetricsCollector) RecordGet(latencyNs int64, hit bool) {
+	atomic.AddInt64(&p.calls, 1)
+	panic("collector bug: RecordGet")
+}
+
+func (p *panickingMetricsCollector) RecordSet(latencyNs int64) {
+	atomic.AddInt64(&p.calls, 1)
+	panic("collector bug: RecordSet")
+}
+
+func (p *panickingMetricsCollector) RecordDelete(latencyNs int64) {
+	atomic.AddInt64(&p.calls, 1)
+	panic("collector bug: RecordDelete")
+}
+
+func (p *panickingMetricsCollector) RecordEviction() {
+	atomic.AddInt64(&p.calls, 1)
+	panic("collector bug: RecordEviction")
+}
+
+func (p *panickingMetricsCollector) RecordExpiration() {
+	atomic.AddInt64(&p.calls, 1)
+	panic("collector bug: RecordExpiration")
+}
+
+// recordingLogger captures Error calls for assertions.
+type recordingLogger struct {
+	NoOpLogger
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *recordingLogger) Error(msg string, keyvals ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, msg)
+}
+
+func (l *recordingLogger) errorCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.errors)
+}
+
+// TestGuardedMetricsCollector_PanicDisablesCollector verifies that a panicking
+// collector does not crash the cache and is disabled after the first panic.
+func TestGuardedMetricsCollector_PanicDisablesCollector(t *testing.T) {
+	collector := &panickingMetricsCollector{}
+	logger := &recordingLogger{}
+
+	cache := NewCache(Config{
+		MaxSize:          100,
+		MetricsCollector: collector,
+		Logger:           logger,
+	})
+
+	// None of these calls may panic
+	if !cache.Set("key", "value") {
+		t.Fatal("Set should succeed despite panicking collector")
+	}
+	value, found := cache.Get("key")
+	if !found || value != "value" {
+		t.Fatalf("Get returned (%v, %v), want (value, true)", value, found)
+	}
+	cache.Get("missing")
+	if !cache.Delete("key") {
+		t.Error("Delete should succeed despite panicking collector")
+	}
+
+	if calls := atomic.LoadInt64(&collector.calls); calls != 1 {
+		t.Errorf("expected collector to be called exactly once before being disabled, got %d", calls)
+	}
+	if n := logger.errorCount(); n != 1 {
+		t.Errorf("expected exactly one error log, got %d", n)
+	}
+}
+
+// TestGuardedMetricsCollector_Concurrent verifies that concurrent panics are
+// recovered on every goroutine and logged only once.
+func TestGuardedMetricsCollector_Concurrent(t *testing.T) {
+	logger := &recordingLogger{}
+	cache := NewCache(Config{
+		MaxSize:          1000,
+		MetricsCollector: &panickingMetricsCollector{},
+		Logger:           logger,
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := "key" + string(rune('a'+id))
+				cache.Set(key, i)
+				cache.Get(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := logger.errorCount(); n != 1 {
+		t.Errorf("expected exactly one error log, got %d", n)
+	}
+}
+
+// TestGuardedMetricsCollector_NoOpNotWrapped verifies that the zero-overhead
+// NoOpMetricsCollector is never wrapped.
+func TestGuardedMetricsCollector_NoOpNotWrapped(t *testing.T) {
+	wrapped := newGuardedMetricsCollector(NoOpMetricsCollector{}, nil)
+	if _, ok := wrapped.(NoOpMetricsCollector); !ok {
+		t.Errorf("NoOpMetricsCollector should not be wrapped, got %T", wrapped)
+	}
+
+	guarded := newGuardedMetricsCollector(&mockMetricsCollector{}, nil)
+	if again := newGuardedMetricsCollector(guarded, nil); again != guarded {
+		t.Error("already guarded collector should not be wrapped twice")
+	}
+}
+
+// TestGuardedMetricsCollector_ForwardsCalls verifies that a healthy collector
+// still receives every recording through the guard.
+func TestGuardedMetricsCollector_ForwardsCalls(t *testing.T) {
+	inner := &mockMetricsCollector{}
+	guarded := newGuardedMetricsCollector(inner, nil)
+
+	guarded.RecordGet(10, true)
+	guarded.RecordSet(20)
+	guarded.RecordDelete(30)
+	guarded.RecordEviction()
+	guarded.RecordExpiration()
+
+	if inner.getCalls != 1 || inner.setCalls != 1 || inner.deleteCalls != 1 || inner.evictionCalls != 1 {
+		t.Errorf("calls not forwarded: get=%d set=%d delete=%d eviction=%d",
+			inner.getCalls, inner.setCalls, inner.deleteCalls, inner.evictionCalls)
+	}
+}
+
+// panickingProbeCollector panics in RecordHas.
+type panickingProbeCollector struct {
+	panickingMetricsCollector
+}
+
+func (p *panickingProbeCollector) RecordHas(latencyNs int64, hit bool) {
+	atomic.AddInt64(&p.calls, 1)
+	panic("collector bug: RecordHas")
+}
+
+func (p *panickingProbeCollector) RecordLen()   { atomic.AddInt64(&p.calls, 1) }
+func (p *panickingProbeCollector) RecordStats() { atomic.AddInt64(&p.calls, 1) }
+
+// TestGuardedMetricsCollector_ProbePanic verifies that probe metrics share the
+// panic isolation of the other recordings.
+func TestGuardedMetricsCollector_ProbePanic(t *testing.T) {
+	collector := &panickingProbeCollector{}
+	logger := &recordingLogger{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector, Logger: logger})
+
+	cache.Has("k")
+	cache.Has("k")
+	cache.Stats()
+	cache.Len()
+	if calls := atomic.LoadInt64(&collector.calls); calls != 1 {
+		t.Errorf("expected the collector to be disabled after the first panic, got %d calls", calls)
+	}
+	if n := logger.errorCount(); n != 1 {
+		t.Errorf("expected exactly one error log, got %d", n)
+	}
+}
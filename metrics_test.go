@@ -429,3 +429,112 @@ func medianDuration(durations []time.Duration) time.Duration {
 	// Odd number of elements: middle element
 	return sorted[mid]
 }
+
+// countingTimeProvider counts Now() calls for overhead assertions.
+type countingTimeProvider struct {
+	calls int64
+}
+
+func (c *countingTimeProvider) Now() int64 {
+	return 1000000000 + atomic.AddInt64(&c.calls, 1)
+}
+
+// TestCacheMetrics_DisableLatency verifies that disabling latency measurement
+// reports -1 latencies, keeps counters, and skips the closing Now() call.
+func TestCacheMetrics_DisableLatency(t *testing.T) {
+	collector := &mockMetricsCollector{}
+	clock := &countingTimeProvider{}
+
+	cache := NewCache(Config{
+		MaxSize:               100,
+		MetricsCollector:      collector,
+		TimeProvider:          clock,
+		DisableMetricsLatency: true,
+	})
+
+	before := atomic.LoadInt64(&clock.calls)
+	cache.Set("key1", "value1")
+	cache.Get("key1") // hit
+	cache.Get("key2") // miss
+	cache.Delete("key1")
+	after := atomic.LoadInt64(&clock.calls)
+
+	if collector.setCalls != 1 || collector.getCalls != 2 || collector.deleteCalls != 1 {
+		t.Fatalf("unexpected call counts: set=%d get=%d delete=%d",
+			collector.setCalls, collector.getCalls, collector.deleteCalls)
+	}
+	if collector.hitCount != 1 || collector.missCount != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d/%d", collector.hitCount, collector.missCount)
+	}
+
+	for _, lat := range append(append(collector.getLatencies, collector.setLatencies...), collector.deleteLatencies...) {
+		if lat != -1 {
+			t.Errorf("expected latency -1 when measurement is disabled, got %d", lat)
+		}
+	}
+
+	// Set and Get read the clock once (TTL/start), Delete not at all
+	if calls := after - before; calls != 3 {
+		t.Errorf("expected 3 Now() calls with latency disabled, got %d", calls)
+	}
+}
+
+// probeMetricsCollector also implements ProbeMetricsCollector.
+type probeMetricsCollector struct {
+	NoOpMetricsCollector
+	hasHits, hasMisses, lens, stats int64
+	lastHasLatency                  int64
+}
+
+func (p *probeMetricsCollector) RecordHas(latencyNs int64, hit bool) {
+	atomic.StoreInt64(&p.lastHasLatency, latencyNs)
+	if hit {
+		atomic.AddInt64(&p.hasHits, 1)
+	} else {
+		atomic.AddInt64(&p.hasMisses, 1)
+	}
+}
+
+func (p *probeMetricsCollector) RecordLen()   { atomic.AddInt64(&p.lens, 1) }
+func (p *probeMetricsCollector) RecordStats() { atomic.AddInt64(&p.stats, 1) }
+
+func TestCacheMetrics_ProbeCalls(t *testing.T) {
+	collector := &probeMetricsCollector{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector})
+	cache.Set("k", 1)
+
+	cache.Has("k")
+	cache.Has("missing")
+	cache.Has("")
+	cache.Len()
+	cache.Stats()
+	cache.Stats()
+
+	if collector.hasHits != 1 || collector.hasMisses != 2 {
+		t.Errorf("Has: hits=%d misses=%d, want 1 and 2", collector.hasHits, collector.hasMisses)
+	}
+	if collector.lens != 1 || collector.stats != 2 {
+		t.Errorf("Len=%d Stats=%d, want 1 and 2", collector.lens, collector.stats)
+	}
+	if collector.lastHasLatency < 0 {
+		t.Errorf("Has latency must be measured, got %d", collector.lastHasLatency)
+	}
+
+	// Collectors without the extension are not called for probes
+	plain := &mockMetricsCollector{}
+	other := NewCache(Config{MaxSize: 100, MetricsCollector: plain})
+	other.Has("k")
+	other.Stats()
+	if c := other.(*wtinyLFUCache); c.probeMetrics != nil {
+		t.Error("probe metrics must be disabled for collectors without RecordHas")
+	}
+}
+
+func TestCacheMetrics_ProbeCallsWithoutLatency(t *testing.T) {
+	collector := &probeMetricsCollector{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector, DisableMetricsLatency: true})
+	cache.Has("k")
+	if collector.lastHasLatency != -1 {
+		t.Errorf("expected latency -1 when disabled, got %d", collector.lastHasLatency)
+	}
+}
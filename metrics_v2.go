@@ -0,0 +1,136 @@
+// metrics_v2.go: MetricsCollectorV2, recordings tagged with the cache and the call context
+//
+// A MetricsCollectorV2 receives the cache name and the call context with
+// every recording, so one instance can serve every cache.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "context"
+
+// MetricsOp identifies the cache and the call a MetricsCollectorV2
+// recording belongs to.
+type MetricsOp struct {
+	// Cache is the name of the cache (Config.Name, possibly empty).
+	Cache string
+
+	// Context is the context of the call: the caller's for Gets made by
+	// GetCtx and GetOrLoadWithContext, context.Background() otherwise.
+	// Never nil.
+	Context context.Context
+}
+
+// MetricsCollectorV2 is a MetricsCollector that is told which cache and
+// which call each recording belongs to, so one instance can serve several
+// caches (see Config.MetricsCollectorV2). The methods have the semantics of
+// their MetricsCollector counterparts. Implementations must be safe for
+// concurrent use.
+type MetricsCollectorV2 interface {
+	// RecordGet records a Get with its latency (-1 when latency measurement
+	// is disabled) and whether the key was found.
+	RecordGet(op MetricsOp, latencyNs int64, hit bool)
+
+	// RecordSet records a Set with its latency.
+	RecordSet(op MetricsOp, latencyNs int64)
+
+	// RecordDelete records a Delete with its latency.
+	RecordDelete(op MetricsOp, latencyNs int64)
+
+	// RecordEviction records the eviction of an entry.
+	RecordEviction(op MetricsOp)
+
+	// RecordExpiration records the expiration of an entry.
+	RecordExpiration(op MetricsOp)
+}
+
+// AdaptMetricsCollector returns a MetricsCollectorV2 that forwards every
+// recording to collector, dropping the MetricsOp.
+func AdaptMetricsCollector(collector MetricsCollector) MetricsCollectorV2 {
+	return metricsCollectorV1{collector}
+}
+
+// metricsCollectorV1 implements AdaptMetricsCollector.
+type metricsCollectorV1 struct {
+	collector MetricsCollector
+}
+
+func (a metricsCollectorV1) RecordGet(_ MetricsOp, latencyNs int64, hit bool) {
+	a.collector.RecordGet(latencyNs, hit)
+}
+
+func (a metricsCollectorV1) RecordSet(_ MetricsOp, latencyNs int64) {
+	a.collector.RecordSet(latencyNs)
+}
+
+func (a metricsCollectorV1) RecordDelete(_ MetricsOp, latencyNs int64) {
+	a.collector.RecordDelete(latencyNs)
+}
+
+func (a metricsCollectorV1) RecordEviction(MetricsOp) { a.collector.RecordEviction() }
+
+func (a metricsCollectorV1) RecordExpiration(MetricsOp) { a.collector.RecordExpiration() }
+
+// contextMetricsCollector is implemented by the collectors that accept the
+// context of a Get (see boundMetricsCollector).
+type contextMetricsCollector interface {
+	recordGetContext(ctx context.Context, latencyNs int64, hit bool)
+}
+
+// boundMetricsCollector adapts a MetricsCollectorV2 to the MetricsCollector
+// of one cache.
+type boundMetricsCollector struct {
+	collector MetricsCollectorV2
+	op        MetricsOp // Built once: Cache and context.Background()
+}
+
+// bindMetricsCollector returns collector as the MetricsCollector of the
+// cache named name.
+func bindMetricsCollector(collector MetricsCollectorV2, name string) MetricsCollector {
+	if adapted, ok := collector.(metricsCollectorV1); ok {
+		return adapted.collector
+	}
+	return &boundMetricsCollector{
+		collector: collector,
+		op:        MetricsOp{Cache: name, Context: context.Background()},
+	}
+}
+
+func (b *boundMetricsCollector) RecordGet(latencyNs int64, hit bool) {
+	b.collector.RecordGet(b.op, latencyNs, hit)
+}
+
+func (b *boundMetricsCollector) RecordSet(latencyNs int64) { b.collector.RecordSet(b.op, latencyNs) }
+
+func (b *boundMetricsCollector) RecordDelete(latencyNs int64) {
+	b.collector.RecordDelete(b.op, latencyNs)
+}
+
+func (b *boundMetricsCollector) RecordEviction() { b.collector.RecordEviction(b.op) }
+
+func (b *boundMetricsCollector) RecordExpiration() { b.collector.RecordExpiration(b.op) }
+
+func (b *boundMetricsCollector) recordGetContext(ctx context.Context, latencyNs int64, hit bool) {
+	b.collector.RecordGet(MetricsOp{Cache: b.op.Cache, Context: ctx}, latencyNs, hit)
+}
+
+// contextMetricsOf returns the contextMetricsCollector of a collector built
+// by newGuardedMetricsCollector, or nil when the collector does not accept
+// contexts.
+func contextMetricsOf(collector MetricsCollector) contextMetricsCollector {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.context != nil {
+		return g
+	}
+	return nil
+}
+
+// recordGetContext forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) recordGetContext(ctx context.Context, latencyNs int64, hit bool) {
+	if g.context == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordGet")
+	g.context.recordGetContext(ctx, latencyNs, hit)
+}
@@ -0,0 +1,188 @@
+// metrics_v2_test.go: tests for MetricsCollectorV2 and its adapters
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+// opRecorder is a MetricsCollectorV2 counting recordings per cache.
+type opRecorder struct {
+	mu          sync.Mutex
+	hits        map[string]int
+	misses      map[string]int
+	sets        map[string]int
+	deletes     map[string]int
+	evictions   map[string]int
+	expirations map[string]int
+	tags        []interface{} // ctxKey value of every Get
+}
+
+func newOpRecorder() *opRecorder {
+	return &opRecorder{
+		hits: map[string]int{}, misses: map[string]int{}, sets: map[string]int{},
+		deletes: map[string]int{}, evictions: map[string]int{}, expirations: map[string]int{},
+	}
+}
+
+func (r *opRecorder) RecordGet(op MetricsOp, latencyNs int64, hit bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hit {
+		r.hits[op.Cache]++
+	} else {
+		r.misses[op.Cache]++
+	}
+	r.tags = append(r.tags, op.Context.Value(ctxKey{}))
+}
+
+func (r *opRecorder) RecordSet(op MetricsOp, latencyNs int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sets[op.Cache]++
+}
+
+func (r *opRecorder) RecordDelete(op MetricsOp, latencyNs int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deletes[op.Cache]++
+}
+
+func (r *opRecorder) RecordEviction(op MetricsOp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evictions[op.Cache]++
+}
+
+func (r *opRecorder) RecordExpiration(op MetricsOp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expirations[op.Cache]++
+}
+
+func TestMetricsCollectorV2_SharedByCaches(t *testing.T) {
+	recorder := newOpRecorder()
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	users := NewCache(Config{MaxSize: 10, Name: "users", MetricsCollectorV2: recorder})
+	defer users.Close()
+	sessions := NewGenericCache[string, int](Config{
+		MaxSize: 100, TTL: time.Second, TimeProvider: timeProvider,
+		Name: "sessions", MetricsCollectorV2: recorder,
+	})
+	defer sessions.Close()
+
+	users.Set("a", 1)
+	users.Get("a")
+	users.Get("b")
+	users.Delete("a")
+	for i := 0; i < 100; i++ {
+		users.Set(string(rune('A'+i)), i)
+	}
+	sessions.Set("s", 1)
+	sessions.Get("s")
+	timeProvider.Advance(2 * time.Second)
+	sessions.Get("s")
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.hits["users"] != 1 || recorder.misses["users"] != 1 || recorder.deletes["users"] != 1 {
+		t.Fatalf("users: hits %v, misses %v, deletes %v", recorder.hits, recorder.misses, recorder.deletes)
+	}
+	if recorder.sets["users"] != 101 || recorder.evictions["users"] == 0 {
+		t.Fatalf("users: sets %d, evictions %d", recorder.sets["users"], recorder.evictions["users"])
+	}
+	if recorder.hits["sessions"] != 1 || recorder.misses["sessions"] != 1 || recorder.expirations["sessions"] != 1 {
+		t.Fatalf("sessions: hits %v, misses %v, expirations %v", recorder.hits, recorder.misses, recorder.expirations)
+	}
+	if recorder.evictions["sessions"] != 0 || len(recorder.sets) != 2 {
+		t.Fatalf("recordings mixed up between caches: %v, %v", recorder.evictions, recorder.sets)
+	}
+}
+
+func TestMetricsCollectorV2_Context(t *testing.T) {
+	recorder := newOpRecorder()
+	cache := NewCache(Config{MaxSize: 10, MetricsCollectorV2: recorder}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	cache.Set("k", 1)
+	cache.Get("k")
+	if _, _, err := cache.GetCtx(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetOrLoadWithContext(ctx, "k", func(context.Context) (interface{}, error) { return 2, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	want := []interface{}{nil, "request-1", "request-1"}
+	if len(recorder.tags) != len(want) {
+		t.Fatalf("recorded %d Gets, want %d", len(recorder.tags), len(want))
+	}
+	for i := range want {
+		if recorder.tags[i] != want[i] {
+			t.Fatalf("Get %d recorded context value %v, want %v", i, recorder.tags[i], want[i])
+		}
+	}
+	if recorder.hits[""] != 3 {
+		t.Fatalf("anonymous cache hits = %d, want 3", recorder.hits[""])
+	}
+}
+
+func TestMetricsCollectorV2_MetricsCollectorTakesPrecedence(t *testing.T) {
+	recorder := newOpRecorder()
+	v1 := &mockMetricsCollector{}
+	cache := NewCache(Config{MaxSize: 10, MetricsCollector: v1, MetricsCollectorV2: recorder})
+	defer cache.Close()
+
+	cache.Get("k")
+	if v1.getCalls != 1 || len(recorder.tags) != 0 {
+		t.Fatalf("MetricsCollector got %d Gets, MetricsCollectorV2 %d", v1.getCalls, len(recorder.tags))
+	}
+}
+
+func TestAdaptMetricsCollector(t *testing.T) {
+	v1 := &mockMetricsCollector{}
+	adapted := AdaptMetricsCollector(v1)
+	op := MetricsOp{Cache: "x", Context: context.Background()}
+	adapted.RecordGet(op, 10, true)
+	adapted.RecordSet(op, 10)
+	adapted.RecordDelete(op, 10)
+	adapted.RecordEviction(op)
+	adapted.RecordExpiration(op)
+	if v1.getCalls != 1 || v1.setCalls != 1 || v1.deleteCalls != 1 || v1.evictionCalls != 1 {
+		t.Fatalf("recordings not forwarded: %+v", v1)
+	}
+
+	// An adapted collector is used as is, without a round trip
+	cache := NewCache(Config{MaxSize: 10, MetricsCollectorV2: adapted})
+	defer cache.Close()
+	cache.Get("k")
+	if v1.getCalls != 2 {
+		t.Fatalf("Get not recorded through the adapter: %d", v1.getCalls)
+	}
+}
+
+type panickingV2 struct{ opRecorder }
+
+func (*panickingV2) RecordGet(MetricsOp, int64, bool) { panic("collector bug") }
+
+func TestMetricsCollectorV2_PanicIsolated(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10, MetricsCollectorV2: &panickingV2{}}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Get("k")
+	if _, _, err := cache.GetCtx(context.Background(), "k"); err != nil {
+		t.Fatal(err)
+	}
+}
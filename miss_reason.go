@@ -0,0 +1,151 @@
+// miss_reason.go: why a Get missed (GetWithReason, per-reason miss counters)
+//
+// With Config.TrackMissReasons each slot remembers the last key that left it
+// and why, so GetWithReason can classify a miss.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// MissReason tells why a Get did not return a value.
+type MissReason int
+
+const (
+	// MissNone is reported for a hit.
+	MissNone MissReason = iota
+
+	// MissAbsent is reported for a key that is not known to have been
+	// cached: never stored, deleted, or its departure was not recorded.
+	MissAbsent
+
+	// MissExpired is reported for a key removed after its TTL elapsed.
+	MissExpired
+
+	// MissEvicted is reported for a key evicted to make room.
+	MissEvicted
+
+	// MissRejected is reported for a new key the AdmissionPolicy refused.
+	MissRejected
+)
+
+// missReasonBits is the number of low hash bits replaced by the reason in a
+// departure record.
+const missReasonBits = 3
+
+// String returns the reason name.
+func (r MissReason) String() string {
+	switch r {
+	case MissNone:
+		return "none"
+	case MissAbsent:
+		return "absent"
+	case MissExpired:
+		return "expired"
+	case MissEvicted:
+		return "evicted"
+	case MissRejected:
+		return "rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// GetWithReason retrieves a value like Get and, on a miss, reports why (see
+// MissReason). It counts as a Get in the statistics and the sketch.
+func (c *wtinyLFUCache) GetWithReason(key string) (interface{}, MissReason, bool) {
+	if key == "" || c.isClosed() {
+		return nil, MissAbsent, false
+	}
+	keyHash := c.hashKey(key)
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+
+	value, found, _ := c.read(key, keyHash, now)
+	reason := MissNone
+	if !found {
+		reason = c.classifyMiss(keyHash)
+	}
+	c.recordGet(context.Background(), key, start, found)
+	return value, reason, found
+}
+
+// GetWithReason retrieves a value like Get and, on a miss, reports why (see
+// Cache.GetWithReason). A value of a type other than V is reported as absent.
+func (c *GenericCache[K, V]) GetWithReason(key K) (V, MissReason, bool) {
+	var zero V
+	val, reason, found := c.inner.GetWithReason(keyToString(key))
+	if !found {
+		return zero, reason, false
+	}
+	typedValue, ok := val.(V)
+	if !ok {
+		return zero, MissAbsent, false
+	}
+	return typedValue, MissNone, true
+}
+
+// classifyMiss returns the reason of a Get miss of keyHash and counts it.
+func (c *wtinyLFUCache) classifyMiss(keyHash uint64) MissReason {
+	t := c.table.Load()
+	if t == nil || t.departures == nil {
+		return MissAbsent
+	}
+	record := atomic.LoadUint64(&t.departures[keyHash&uint64(t.mask)])
+	if record == 0 || record>>missReasonBits != keyHash>>missReasonBits {
+		return MissAbsent
+	}
+	reason := MissReason(record & (1<<missReasonBits - 1)) // #nosec G115 -- 3 bits
+	switch reason {
+	case MissExpired:
+		atomic.AddInt64(&c.missesExpired, 1)
+	case MissEvicted:
+		atomic.AddInt64(&c.missesEvicted, 1)
+	case MissRejected:
+		atomic.AddInt64(&c.missesRejected, 1)
+	}
+	return reason
+}
+
+// recordDeparture remembers why the key hashed to keyHash left the cache. A
+// deletion forgets an earlier departure of the same key.
+func (c *wtinyLFUCache) recordDeparture(keyHash uint64, reason EvictReason) {
+	t := c.table.Load()
+	if t == nil || t.departures == nil {
+		return
+	}
+	slot := &t.departures[keyHash&uint64(t.mask)]
+	switch reason {
+	case ReasonExpired:
+		atomic.StoreUint64(slot, keyHash>>missReasonBits<<missReasonBits|uint64(MissExpired))
+	case ReasonEvicted:
+		atomic.StoreUint64(slot, keyHash>>missReasonBits<<missReasonBits|uint64(MissEvicted))
+	case ReasonDeleted:
+		if record := atomic.LoadUint64(slot); record>>missReasonBits == keyHash>>missReasonBits {
+			atomic.CompareAndSwapUint64(slot, record, 0)
+		}
+	}
+}
+
+// recordRejection marks the departure of keyHash, just evicted, as a refusal
+// of the AdmissionPolicy.
+func (c *wtinyLFUCache) recordRejection(keyHash uint64) {
+	t := c.table.Load()
+	if t == nil || t.departures == nil {
+		return
+	}
+	atomic.StoreUint64(&t.departures[keyHash&uint64(t.mask)], keyHash>>missReasonBits<<missReasonBits|uint64(MissRejected))
+}
+
+// resetDepartures forgets every departure recorded in t (see Clear).
+func (c *wtinyLFUCache) resetDepartures(t *cacheTable) {
+	for i := range t.departures {
+		atomic.StoreUint64(&t.departures[i], 0)
+	}
+}
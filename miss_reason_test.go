@@ -0,0 +1,171 @@
+// miss_reason_test.go: tests for GetWithReason and Config.TrackMissReasons
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetWithReason_Untracked(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: timeProvider}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	if value, reason, found := cache.GetWithReason("k"); !found || value != 1 || reason != MissNone {
+		t.Fatalf("hit: %v, %v, %v", value, reason, found)
+	}
+	timeProvider.Advance(2 * time.Second)
+	if _, reason, found := cache.GetWithReason("k"); found || reason != MissAbsent {
+		t.Fatalf("untracked expiration: reason %v, found %v", reason, found)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.MissesExpired != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestGetWithReason_Expired(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TrackMissReasons: true, TimeProvider: timeProvider}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("lazy", 1)
+	cache.Set("swept", 2)
+	timeProvider.Advance(2 * time.Second)
+
+	// Reclaimed by the lookup itself
+	if _, reason, _ := cache.GetWithReason("lazy"); reason != MissExpired {
+		t.Fatalf("lazy expiration reported as %v", reason)
+	}
+	// Reclaimed before the lookup
+	if cache.ExpireNow() != 1 {
+		t.Fatal("ExpireNow did not remove the entry")
+	}
+	if _, reason, _ := cache.GetWithReason("swept"); reason != MissExpired {
+		t.Fatalf("swept expiration reported as %v", reason)
+	}
+	if _, reason, _ := cache.GetWithReason("never"); reason != MissAbsent {
+		t.Fatalf("cold key reported as %v", reason)
+	}
+
+	cache.Get("lazy") // Plain Gets are counted too
+	stats := cache.Stats()
+	if stats.Misses != 4 || stats.MissesExpired != 3 || stats.MissesEvicted != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestGetWithReason_Evicted(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 50, TrackMissReasons: true}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	for i := 0; i < 500; i++ {
+		cache.Set("k"+strconv.Itoa(i), i)
+	}
+	evicted := 0
+	for i := 0; i < 500; i++ {
+		_, reason, found := cache.GetWithReason("k" + strconv.Itoa(i))
+		switch {
+		case found && reason != MissNone:
+			t.Fatalf("hit reported with reason %v", reason)
+		case !found && reason == MissEvicted:
+			evicted++
+		case !found && reason != MissAbsent:
+			t.Fatalf("eviction reported as %v", reason)
+		}
+	}
+	// Departures sharing a slot overwrite each other: most, not all, are known
+	if evicted == 0 {
+		t.Fatal("no miss reported as evicted")
+	}
+	if stats := cache.Stats(); stats.MissesEvicted != uint64(evicted) { // #nosec G115 -- small count
+		t.Fatalf("MissesEvicted = %d, want %d", stats.MissesEvicted, evicted)
+	}
+}
+
+func TestGetWithReason_Rejected(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:          20,
+		TrackMissReasons: true,
+		AdmissionPolicy: AdmissionFunc(func(candidate, victim EvictionCandidate) bool {
+			return !strings.HasPrefix(candidate.Key, "scan:")
+		}),
+	}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	for i := 0; i < 20; i++ {
+		cache.Set("hot:"+strconv.Itoa(i), i)
+	}
+	cache.Set("scan:1", 1)
+	if _, reason, found := cache.GetWithReason("scan:1"); found || reason != MissRejected {
+		t.Fatalf("refused key: reason %v, found %v", reason, found)
+	}
+	if stats := cache.Stats(); stats.MissesRejected != 1 {
+		t.Fatalf("MissesRejected = %d, want 1", stats.MissesRejected)
+	}
+}
+
+func TestGetWithReason_DeleteAndClearForget(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TrackMissReasons: true, TimeProvider: timeProvider}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	timeProvider.Advance(2 * time.Second)
+	cache.ExpireNow()
+
+	// Stored again, then deleted: the expiration is forgotten
+	cache.Set("a", 3)
+	cache.Delete("a")
+	if _, reason, _ := cache.GetWithReason("a"); reason != MissAbsent {
+		t.Fatalf("deleted key reported as %v", reason)
+	}
+
+	cache.Clear()
+	if _, reason, _ := cache.GetWithReason("b"); reason != MissAbsent {
+		t.Fatalf("key expired before Clear reported as %v", reason)
+	}
+	if stats := cache.Stats(); stats.MissesExpired != 0 {
+		t.Fatalf("MissesExpired = %d after Clear", stats.MissesExpired)
+	}
+}
+
+func TestGetWithReason_Generic(t *testing.T) {
+	timeProvider := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewGenericCache[int, string](Config{MaxSize: 100, TTL: time.Second, TrackMissReasons: true, TimeProvider: timeProvider})
+	defer cache.Close()
+
+	cache.Set(7, "seven")
+	if value, reason, found := cache.GetWithReason(7); !found || value != "seven" || reason != MissNone {
+		t.Fatalf("hit: %q, %v, %v", value, reason, found)
+	}
+	timeProvider.Advance(2 * time.Second)
+	cache.ExpireNow()
+	cache.Get(7) // Integer fast path
+	if stats := cache.Stats(); stats.MissesExpired != 1 {
+		t.Fatalf("MissesExpired = %d, want 1", stats.MissesExpired)
+	}
+	if _, reason, _ := cache.GetWithReason(7); reason != MissExpired {
+		t.Fatalf("expired key reported as %v", reason)
+	}
+}
+
+func TestMissReason_String(t *testing.T) {
+	names := map[MissReason]string{
+		MissNone: "none", MissAbsent: "absent", MissExpired: "expired",
+		MissEvicted: "evicted", MissRejected: "rejected", MissReason(99): "unknown",
+	}
+	for reason, want := range names {
+		if got := reason.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", int(reason), got, want)
+		}
+	}
+}
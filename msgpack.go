@@ -0,0 +1,323 @@
+// msgpack.go: minimal MessagePack encoding for Export and Import
+//
+// Encodes and decodes the subset of MessagePack that Export documents use.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// msgpackMaxDepth bounds the nesting of decoded documents.
+const msgpackMaxDepth = 10000
+
+// msgpackPrealloc bounds the capacity preallocated for arrays and maps.
+const msgpackPrealloc = 64
+
+// msgpackWriter encodes values into a buffered stream. Write errors are
+// sticky in bufio.Writer and checked by the caller on Flush.
+type msgpackWriter struct {
+	w       *bufio.Writer
+	scratch [9]byte
+}
+
+// writeHeader writes a type byte followed by n in size bytes, big endian.
+func (m *msgpackWriter) writeHeader(code byte, n uint64, size int) {
+	m.scratch[0] = code
+	switch size {
+	case 1:
+		m.scratch[1] = byte(n)
+	case 2:
+		binary.BigEndian.PutUint16(m.scratch[1:], uint16(n)) // #nosec G115 -- size chosen from n
+	case 4:
+		binary.BigEndian.PutUint32(m.scratch[1:], uint32(n)) // #nosec G115 -- size chosen from n
+	case 8:
+		binary.BigEndian.PutUint64(m.scratch[1:], n)
+	}
+	_, _ = m.w.Write(m.scratch[:1+size])
+}
+
+// writeLength writes the header of a string, binary, array or map of n
+// elements. fix is the fixed-size code (0 if none) and fixMax its capacity;
+// codes holds the 8 (0 if none), 16 and 32-bit codes.
+func (m *msgpackWriter) writeLength(n int, fix byte, fixMax int, codes [3]byte) {
+	switch {
+	case fix != 0 && n <= fixMax:
+		_ = m.w.WriteByte(fix | byte(n))
+	case codes[0] != 0 && n <= math.MaxUint8:
+		m.writeHeader(codes[0], uint64(n), 1)
+	case n <= math.MaxUint16:
+		m.writeHeader(codes[1], uint64(n), 2)
+	default:
+		m.writeHeader(codes[2], uint64(n), 4)
+	}
+}
+
+func (m *msgpackWriter) writeString(s string) {
+	m.writeLength(len(s), 0xa0, 31, [3]byte{0xd9, 0xda, 0xdb})
+	_, _ = m.w.WriteString(s)
+}
+
+func (m *msgpackWriter) writeInt(v int64) {
+	switch {
+	case v >= 0:
+		m.writeUint(uint64(v))
+	case v >= -32:
+		_ = m.w.WriteByte(byte(v))
+	case v >= math.MinInt8:
+		m.writeHeader(0xd0, uint64(v), 1) // #nosec G115 -- two's complement on purpose
+	case v >= math.MinInt16:
+		m.writeHeader(0xd1, uint64(v), 2) // #nosec G115 -- two's complement on purpose
+	case v >= math.MinInt32:
+		m.writeHeader(0xd2, uint64(v), 4) // #nosec G115 -- two's complement on purpose
+	default:
+		m.writeHeader(0xd3, uint64(v), 8) // #nosec G115 -- two's complement on purpose
+	}
+}
+
+func (m *msgpackWriter) writeUint(v uint64) {
+	switch {
+	case v <= 0x7f:
+		_ = m.w.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		m.writeHeader(0xcc, v, 1)
+	case v <= math.MaxUint16:
+		m.writeHeader(0xcd, v, 2)
+	case v <= math.MaxUint32:
+		m.writeHeader(0xce, v, 4)
+	default:
+		m.writeHeader(0xcf, v, 8)
+	}
+}
+
+// write encodes a value of the supported subset.
+func (m *msgpackWriter) write(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		_ = m.w.WriteByte(0xc0)
+	case bool:
+		if v {
+			_ = m.w.WriteByte(0xc3)
+		} else {
+			_ = m.w.WriteByte(0xc2)
+		}
+	case int64:
+		m.writeInt(v)
+	case uint64:
+		m.writeUint(v)
+	case float64:
+		m.writeHeader(0xcb, math.Float64bits(v), 8)
+	case json.Number:
+		return m.write(plainNumber(v))
+	case string:
+		m.writeString(v)
+	case []byte:
+		m.writeLength(len(v), 0, 0, [3]byte{0xc4, 0xc5, 0xc6})
+		_, _ = m.w.Write(v)
+	case []interface{}:
+		m.writeLength(len(v), 0x90, 15, [3]byte{0, 0xdc, 0xdd})
+		for _, item := range v {
+			if err := m.write(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		m.writeLength(len(v), 0x80, 15, [3]byte{0, 0xde, 0xdf})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			m.writeString(key)
+			if err := m.write(v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", value)
+	}
+	return nil
+}
+
+// plainNumber converts a JSON number to int64, uint64 or float64, the
+// smallest of them that holds it exactly.
+func plainNumber(n json.Number) interface{} {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u
+	}
+	f, _ := strconv.ParseFloat(string(n), 64) // Validated by encoding/json
+	return f
+}
+
+// msgpackReader decodes values of the supported subset.
+type msgpackReader struct {
+	r     *bufio.Reader
+	depth int
+}
+
+// readUint reads a big endian unsigned integer of size bytes.
+func (m *msgpackReader) readUint(size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(m.r, buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// readBytes reads n bytes without trusting n for the allocation.
+func (m *msgpackReader) readBytes(n uint64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, m.r, int64(n)); err != nil { // #nosec G115 -- at most 32 bits
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// read decodes one value.
+func (m *msgpackReader) read() (interface{}, error) {
+	code, err := m.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil // #nosec G115 -- negative fixint
+	case code&0xe0 == 0xa0:
+		return m.readString(uint64(code & 0x1f))
+	case code&0xf0 == 0x90:
+		return m.readArray(uint64(code & 0x0f))
+	case code&0xf0 == 0x80:
+		return m.readMap(uint64(code & 0x0f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := m.readUint(1 << (code - 0xcc))
+		if err != nil || u > math.MaxInt64 {
+			return u, err
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		u, err := m.readUint(size)
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, err // #nosec G115 -- sign extension
+	case 0xca:
+		u, err := m.readUint(4)
+		return float64(math.Float32frombits(uint32(u))), err // #nosec G115 -- 4 bytes read
+	case 0xcb:
+		u, err := m.readUint(8)
+		return math.Float64frombits(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := m.readUint(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return m.readString(n)
+	case 0xc4, 0xc5, 0xc6:
+		n, err := m.readUint(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return m.readBytes(n)
+	case 0xdc, 0xdd:
+		n, err := m.readUint(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return m.readArray(n)
+	case 0xde, 0xdf:
+		n, err := m.readUint(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return m.readMap(n)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type code %#x", code)
+}
+
+func (m *msgpackReader) readString(n uint64) (interface{}, error) {
+	b, err := m.readBytes(n)
+	return string(b), err
+}
+
+// enter bounds the nesting of arrays and maps.
+func (m *msgpackReader) enter() error {
+	if m.depth++; m.depth > msgpackMaxDepth {
+		return fmt.Errorf("msgpack: exceeded max depth %d", msgpackMaxDepth)
+	}
+	return nil
+}
+
+func (m *msgpackReader) readArray(n uint64) (interface{}, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { m.depth-- }()
+	items := make([]interface{}, 0, min(n, msgpackPrealloc))
+	for i := uint64(0); i < n; i++ {
+		item, err := m.read()
+		if err != nil {
+			return nil, eofIsUnexpected(err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (m *msgpackReader) readMap(n uint64) (interface{}, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { m.depth-- }()
+	fields := make(map[string]interface{}, min(n, msgpackPrealloc))
+	for i := uint64(0); i < n; i++ {
+		key, err := m.read()
+		if err != nil {
+			return nil, eofIsUnexpected(err)
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: unsupported map key type %T", key)
+		}
+		if fields[name], err = m.read(); err != nil {
+			return nil, eofIsUnexpected(err)
+		}
+	}
+	return fields, nil
+}
+
+// eofIsUnexpected reports an EOF inside a value as a truncation.
+func eofIsUnexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+// msgpack_test.go: tests for the MessagePack subset used by Export
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// msgpackEncode encodes value and returns its bytes.
+func msgpackEncode(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := (&msgpackWriter{w: w}).write(value); err != nil {
+		t.Fatalf("write(%#v) error = %v", value, err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// msgpackDecode decodes one value from data.
+func msgpackDecode(data []byte) (interface{}, error) {
+	return (&msgpackReader{r: bufio.NewReader(bytes.NewReader(data))}).read()
+}
+
+func TestMsgpack_RoundTrip(t *testing.T) {
+	values := []interface{}{
+		nil, true, false,
+		int64(0), int64(127), int64(128), int64(255), int64(256), int64(65535), int64(65536),
+		int64(math.MaxUint32), int64(math.MaxUint32) + 1, int64(math.MaxInt64),
+		int64(-1), int64(-32), int64(-33), int64(math.MinInt8), int64(math.MinInt8) - 1,
+		int64(math.MinInt16), int64(math.MinInt16) - 1, int64(math.MinInt32), int64(math.MinInt32) - 1,
+		int64(math.MinInt64), uint64(math.MaxUint64),
+		0.5, -1e300, math.Inf(1),
+		"", "short", strings.Repeat("s", 31), strings.Repeat("m", 32), strings.Repeat("l", 256), strings.Repeat("x", 70000),
+		[]byte{}, []byte{1, 2, 3}, bytes.Repeat([]byte{7}, 300),
+		[]interface{}{}, []interface{}{int64(1), "two", nil},
+		make([]interface{}, 16), make([]interface{}, 70000),
+		map[string]interface{}{}, map[string]interface{}{"a": int64(1), "nested": map[string]interface{}{"b": []interface{}{true}}},
+	}
+	for _, want := range values {
+		data := msgpackEncode(t, want)
+		got, err := msgpackDecode(data)
+		if err != nil {
+			t.Fatalf("decoding %T (%d bytes): %v", want, len(data), err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("round trip of %T: got %v, want %v", want, got, want)
+		}
+	}
+}
+
+func TestMsgpack_SmallestEncoding(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		want  []byte
+	}{
+		{int64(5), []byte{0x05}},
+		{int64(-5), []byte{0xfb}},
+		{int64(200), []byte{0xcc, 200}},
+		{int64(-100), []byte{0xd0, 0x9c}},
+		{"ab", []byte{0xa2, 'a', 'b'}},
+		{[]interface{}{true}, []byte{0x91, 0xc3}},
+		{map[string]interface{}{"b": nil, "a": false}, []byte{0x82, 0xa1, 'a', 0xc2, 0xa1, 'b', 0xc0}},
+	}
+	for _, tc := range cases {
+		if got := msgpackEncode(t, tc.value); !bytes.Equal(got, tc.want) {
+			t.Errorf("encoding of %#v = % x, want % x", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestMsgpack_DecodeForeign(t *testing.T) {
+	// Encodings other MessagePack writers produce: float32, non-string keys
+	if got, err := msgpackDecode([]byte{0xca, 0x3f, 0xc0, 0x00, 0x00}); err != nil || got != 1.5 {
+		t.Errorf("float32 1.5 decoded as %v, %v", got, err)
+	}
+	if _, err := msgpackDecode([]byte{0x81, 0x01, 0x02}); err == nil {
+		t.Error("a map with integer keys must be rejected")
+	}
+	if _, err := msgpackDecode([]byte{0xd4, 0x01, 0x00}); err == nil {
+		t.Error("extension types must be rejected")
+	}
+}
+
+func TestMsgpack_DecodeLimits(t *testing.T) {
+	// A length far beyond the data fails on the data, without allocating it
+	for _, data := range [][]byte{
+		{0xdb, 0xff, 0xff, 0xff, 0xff, 'x'},
+		{0xc6, 0xff, 0xff, 0xff, 0xff},
+		{0xdf, 0xff, 0xff, 0xff, 0xff},
+	} {
+		if _, err := msgpackDecode(data); err != io.ErrUnexpectedEOF {
+			t.Errorf("decoding % x: error = %v, want io.ErrUnexpectedEOF", data, err)
+		}
+	}
+
+	deep := bytes.Repeat([]byte{0x91}, msgpackMaxDepth+1)
+	if _, err := msgpackDecode(append(deep, 0xc0)); err == nil || !strings.Contains(err.Error(), "depth") {
+		t.Errorf("nesting beyond msgpackMaxDepth: error = %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// namespace.go: key namespaces and fast per-namespace clearing
+//
+// ClearNamespace removes every entry whose key starts with a namespace;
+// Config.IndexNamespaces indexes keys so it does not scan the table.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// NamespaceSeparator separates a key's namespace from the rest of the key.
+const NamespaceSeparator = ":"
+
+// namespaceShards is the number of lock stripes of the namespace index.
+const namespaceShards = 64
+
+// namespaceOf returns the namespace of key, or "" if key has none.
+func namespaceOf(key string) string {
+	if i := strings.Index(key, NamespaceSeparator); i > 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// namespaceShard holds the key sets of the namespaces hashed to it.
+type namespaceShard struct {
+	mu   sync.Mutex
+	keys map[string]map[string]struct{} // namespace -> keys written to it
+}
+
+// namespaceIndex maps namespaces to the keys written to them.
+type namespaceIndex struct {
+	shards     [namespaceShards]namespaceShard
+	count      int64 // Indexed keys, including stale ones
+	pruneAbove int64
+	pruning    int32
+}
+
+func newNamespaceIndex(maxSize int) *namespaceIndex {
+	idx := &namespaceIndex{pruneAbove: int64(2 * maxSize)}
+	for i := range idx.shards {
+		idx.shards[i].keys = make(map[string]map[string]struct{})
+	}
+	return idx
+}
+
+func (idx *namespaceIndex) shard(ns string) *namespaceShard {
+	return &idx.shards[stringHash(ns)%namespaceShards]
+}
+
+// add records key under its namespace. It returns true when the index has
+// grown enough to need pruning.
+func (idx *namespaceIndex) add(key string) bool {
+	ns := namespaceOf(key)
+	if ns == "" {
+		return false
+	}
+	s := idx.shard(ns)
+	s.mu.Lock()
+	set := s.keys[ns]
+	if set == nil {
+		set = make(map[string]struct{})
+		s.keys[ns] = set
+	}
+	_, exists := set[key]
+	if !exists {
+		set[key] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	return !exists && atomic.AddInt64(&idx.count, 1) > idx.pruneAbove
+}
+
+// take removes and returns the keys recorded for ns.
+func (idx *namespaceIndex) take(ns string) map[string]struct{} {
+	s := idx.shard(ns)
+	s.mu.Lock()
+	set := s.keys[ns]
+	delete(s.keys, ns)
+	s.mu.Unlock()
+	atomic.AddInt64(&idx.count, -int64(len(set)))
+	return set
+}
+
+// prune drops keys for which present returns false. Only one goroutine
+// prunes at a time; others proceed without waiting.
+func (idx *namespaceIndex) prune(present func(string) bool) {
+	if !atomic.CompareAndSwapInt32(&idx.pruning, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&idx.pruning, 0)
+
+	for i := range idx.shards {
+		s := &idx.shards[i]
+		s.mu.Lock()
+		for ns, set := range s.keys {
+			for key := range set {
+				if !present(key) {
+					delete(set, key)
+					atomic.AddInt64(&idx.count, -1)
+				}
+			}
+			if len(set) == 0 {
+				delete(s.keys, ns)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// keysOf returns a copy of the keys recorded for ns.
+func (idx *namespaceIndex) keysOf(ns string) []string {
+	s := idx.shard(ns)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.keys[ns]))
+	for key := range s.keys[ns] {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (idx *namespaceIndex) reset() {
+	for i := range idx.shards {
+		s := &idx.shards[i]
+		s.mu.Lock()
+		s.keys = make(map[string]map[string]struct{})
+		s.mu.Unlock()
+	}
+	atomic.StoreInt64(&idx.count, 0)
+}
+
+// indexNamespace records a successful write in the namespace index.
+func (c *wtinyLFUCache) indexNamespace(key string) {
+	if c.namespaces.add(key) {
+		c.namespaces.prune(func(k string) bool {
+			return c.findEntry(k, c.hashKey(k)) != nil
+		})
+	}
+}
+
+// ClearNamespace removes every entry whose key starts with ns followed by
+// NamespaceSeparator and returns the number of entries removed.
+//
+// With Config.IndexNamespaces it runs in O(keys written to ns); otherwise it
+// scans the whole table.
+func (c *wtinyLFUCache) ClearNamespace(ns string) int {
+	if ns == "" || c.isClosed() {
+		return 0
+	}
+	t := c.table.Load()
+	if t == nil {
+		return 0 // Closed
+	}
+	g := t.gen.Load()
+
+	removed := 0
+	if c.namespaces != nil {
+		for key := range c.namespaces.take(ns) {
+			if c.Delete(key) {
+				removed++
+			}
+		}
+		return removed
+	}
+
+	prefix := ns + NamespaceSeparator
+	for i := range t.entries {
+		entry := &t.entries[i]
+		if atomic.LoadInt32(&entry.valid) != g.live {
+			continue
+		}
+		if key := entry.loadKey(); strings.HasPrefix(key, prefix) && c.Delete(key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// ClearNamespace removes every entry of namespace ns.
+// See the Cache interface for details.
+func (c *GenericCache[K, V]) ClearNamespace(ns string) int {
+	return c.inner.ClearNamespace(ns)
+}
@@ -0,0 +1,138 @@
+// namespace_test.go: tests for ClearNamespace and the namespace index
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestClearNamespace(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("indexed=%v", indexed), func(t *testing.T) {
+			cache := NewCache(Config{MaxSize: 1000, IndexNamespaces: indexed}).(*wtinyLFUCache)
+			for i := 0; i < 50; i++ {
+				cache.Set(fmt.Sprintf("tenantA:%d", i), i)
+				cache.Set(fmt.Sprintf("tenantB:%d", i), i)
+			}
+			cache.Set("tenantA", "no separator, not in the namespace")
+			cache.Set("tenantAB:1", "different namespace")
+
+			if n := cache.ClearNamespace("tenantA"); n != 50 {
+				t.Errorf("expected 50 entries removed, got %d", n)
+			}
+			if cache.Has("tenantA:0") || cache.Has("tenantA:49") {
+				t.Error("namespace entries must be removed")
+			}
+			if !cache.Has("tenantB:0") || !cache.Has("tenantA") || !cache.Has("tenantAB:1") {
+				t.Error("entries outside the namespace must survive")
+			}
+			if n := cache.ClearNamespace("tenantA"); n != 0 {
+				t.Errorf("second clear must remove nothing, got %d", n)
+			}
+			if n := cache.ClearNamespace(""); n != 0 {
+				t.Errorf("empty namespace must remove nothing, got %d", n)
+			}
+		})
+	}
+}
+
+func TestClearNamespace_StaleIndexEntries(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, IndexNamespaces: true}).(*wtinyLFUCache)
+	cache.Set("ns:deleted", 1)
+	cache.Set("ns:live", 2)
+	cache.Delete("ns:deleted")
+
+	if n := cache.ClearNamespace("ns"); n != 1 {
+		t.Errorf("stale keys must not be counted, got %d", n)
+	}
+
+	// Keys written after a clear are indexed again
+	cache.Set("ns:again", 3)
+	if n := cache.ClearNamespace("ns"); n != 1 {
+		t.Errorf("expected 1 entry removed after re-adding, got %d", n)
+	}
+}
+
+func TestNamespaceIndex_PrunedWhenFull(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, IndexNamespaces: true})
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("ns:%d", i)
+		cache.Set(key, i)
+		cache.Delete(key)
+	}
+	idx := cache.(*wtinyLFUCache).namespaces
+	if n := idx.count; n > idx.pruneAbove {
+		t.Errorf("index must stay bounded: %d keys, limit %d", n, idx.pruneAbove)
+	}
+}
+
+func TestNamespaceIndex_ClearResets(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, IndexNamespaces: true})
+	cache.Set("ns:a", 1)
+	cache.Clear()
+	if n := cache.(*wtinyLFUCache).namespaces.count; n != 0 {
+		t.Errorf("Clear must reset the index, %d keys left", n)
+	}
+}
+
+func TestClearNamespace_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10000, IndexNamespaces: true}).(*wtinyLFUCache)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				cache.Set(fmt.Sprintf("t%d:%d", w%2, i), i)
+				if i%100 == 0 {
+					cache.ClearNamespace(fmt.Sprintf("t%d", w%2))
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	cache.ClearNamespace("t0")
+	cache.ClearNamespace("t1")
+	if n := cache.Len(); n != 0 {
+		t.Errorf("expected empty cache after clearing both namespaces, got %d", n)
+	}
+}
+
+func TestClearNamespace_Generic(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, IndexNamespaces: true})
+	cache.Set("users:1", 1)
+	cache.Set("orders:1", 1)
+	if n := cache.ClearNamespace("users"); n != 1 {
+		t.Errorf("expected 1 entry removed, got %d", n)
+	}
+	if _, found := cache.Get("orders:1"); !found {
+		t.Error("other namespaces must survive")
+	}
+}
+
+func BenchmarkClearNamespace(b *testing.B) {
+	for _, indexed := range []bool{false, true} {
+		b.Run(fmt.Sprintf("indexed=%v", indexed), func(b *testing.B) {
+			cache := NewCache(Config{MaxSize: 100000, IndexNamespaces: indexed}).(*wtinyLFUCache)
+			for i := 0; i < 90000; i++ {
+				cache.Set(fmt.Sprintf("big:%d", i), i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for j := 0; j < 10; j++ {
+					cache.Set(fmt.Sprintf("small:%d", j), j)
+				}
+				b.StartTimer()
+				cache.ClearNamespace("small")
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// namespace_view.go: Namespace, a key-prefixing view of one cache
+//
+// Cache.Namespace returns a view that prefixes its keys and scopes Clear,
+// Len, Keys and DeleteByPrefix to the namespace.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strings"
+	"time"
+)
+
+// NamespaceOption configures a Namespace view.
+type NamespaceOption func(*Namespace)
+
+// WithNamespaceTTL stores the entries written through the view with ttl
+// instead of Config.TTL. Non-positive values are ignored.
+func WithNamespaceTTL(ttl time.Duration) NamespaceOption {
+	return func(n *Namespace) {
+		if ttl > 0 {
+			n.ttl = ttl
+		}
+	}
+}
+
+// Namespace is a view of a cache whose keys are prefixed with the namespace
+// name and NamespaceSeparator. It is safe for concurrent use.
+type Namespace struct {
+	cache  extendedCache
+	name   string
+	prefix string
+	ttl    time.Duration
+}
+
+func newNamespace(cache extendedCache, name string, opts []NamespaceOption) *Namespace {
+	n := &Namespace{cache: cache, name: name, prefix: name + NamespaceSeparator}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(n)
+		}
+	}
+	return n
+}
+
+// Namespace returns a view of the cache whose keys are prefixed with name
+// and NamespaceSeparator.
+func (c *wtinyLFUCache) Namespace(name string, opts ...NamespaceOption) *Namespace {
+	return newNamespace(c, name, opts)
+}
+
+// Name returns the name of the namespace.
+func (n *Namespace) Name() string {
+	return n.name
+}
+
+// Key returns the key under which key is stored in the underlying cache.
+func (n *Namespace) Key(key string) string {
+	return n.prefix + key
+}
+
+// core returns the built-in cache behind the view, or nil for another Cache
+// implementation.
+func (n *Namespace) core() *wtinyLFUCache {
+	var cache Cache = n.cache
+	if swappable, ok := cache.(*SwappableCache); ok {
+		cache = swappable.Current()
+	}
+	core, _ := cache.(*wtinyLFUCache)
+	return core
+}
+
+// Get retrieves the value of key in the namespace.
+func (n *Namespace) Get(key string) (interface{}, bool) {
+	if key == "" {
+		return nil, false
+	}
+	return n.cache.Get(n.prefix + key)
+}
+
+// Set stores a key-value pair in the namespace, with the namespace TTL if
+// one was given.
+func (n *Namespace) Set(key string, value interface{}) bool {
+	if key == "" {
+		return false
+	}
+	if core := n.core(); core != nil && n.ttl > 0 {
+		return core.setWithTTL(n.prefix+key, value, int64(n.ttl))
+	}
+	return n.cache.Set(n.prefix+key, value)
+}
+
+// Delete removes key from the namespace.
+func (n *Namespace) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+	return n.cache.Delete(n.prefix + key)
+}
+
+// Has reports whether key is cached in the namespace.
+func (n *Namespace) Has(key string) bool {
+	if key == "" {
+		return false
+	}
+	return n.cache.Has(n.prefix + key)
+}
+
+// GetOrLoad is Cache.GetOrLoad for key in the namespace, storing the loaded
+// value with the namespace TTL unless opts contain WithTTL.
+func (n *Namespace) GetOrLoad(key string, loader func() (interface{}, error), opts ...LoadOption) (interface{}, error) {
+	if key == "" {
+		return nil, NewErrEmptyKey("GetOrLoad")
+	}
+	if n.ttl > 0 {
+		opts = append([]LoadOption{WithTTL(n.ttl)}, opts...)
+	}
+	return n.cache.GetOrLoad(n.prefix+key, loader, opts...)
+}
+
+// Keys returns the keys of the live entries of the namespace, without the
+// prefix, in no particular order.
+func (n *Namespace) Keys() []string {
+	var keys []string
+	n.each(func(key string) {
+		keys = append(keys, key[len(n.prefix):])
+	})
+	return keys
+}
+
+// Len returns the number of live entries of the namespace.
+func (n *Namespace) Len() int {
+	count := 0
+	n.each(func(string) { count++ })
+	return count
+}
+
+// Clear removes every entry of the namespace and returns the number of
+// entries removed.
+func (n *Namespace) Clear() int {
+	return n.cache.DeleteByPrefix(n.prefix)
+}
+
+// DeleteByPrefix removes the entries of the namespace whose key, without the
+// namespace prefix, starts with prefix. An empty prefix removes nothing (use
+// Clear).
+func (n *Namespace) DeleteByPrefix(prefix string) int {
+	if prefix == "" {
+		return 0
+	}
+	return n.cache.DeleteByPrefix(n.prefix + prefix)
+}
+
+// each calls f with the full key of every live entry of the namespace.
+func (n *Namespace) each(f func(key string)) {
+	if core := n.core(); core != nil && core.namespaces != nil && n.name != "" && namespaceOf(n.prefix) == n.name {
+		core.eachIndexed(n.name, f)
+		return
+	}
+	n.cache.Range(func(key string, _ interface{}) bool {
+		if strings.HasPrefix(key, n.prefix) {
+			f(key)
+		}
+		return true
+	})
+}
+
+// eachIndexed calls f with every live key recorded in the namespace index
+// for ns.
+func (c *wtinyLFUCache) eachIndexed(ns string, f func(key string)) {
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	for _, key := range c.namespaces.keysOf(ns) {
+		if entry := c.findEntry(key, c.hashKey(key)); entry != nil && !c.isExpired(entry, ttlNow) {
+			f(key)
+		}
+	}
+}
+
+// setWithTTL stores a key-value pair with its own TTL (in nanoseconds).
+func (c *wtinyLFUCache) setWithTTL(key string, value interface{}, ttlNanos int64) bool {
+	c.raiseMaxTTL(ttlNanos)
+	if !c.set(key, value, "", ttlNanos) {
+		return false
+	}
+	if c.propagate {
+		c.propagateWrite(key, value, ttlNanos)
+	}
+	return true
+}
+
+// Namespace returns a view of the current cache whose keys are prefixed
+// with name and NamespaceSeparator. The view follows later replacements.
+func (s *SwappableCache) Namespace(name string, opts ...NamespaceOption) *Namespace {
+	return newNamespace(s, name, opts)
+}
+
+// GenericNamespace is the typed view of a GenericCache returned by
+// GenericCache.Namespace.
+type GenericNamespace[K comparable, V any] struct {
+	ns *Namespace
+}
+
+// Namespace returns a view of the cache whose keys (in their string form)
+// are prefixed with name and NamespaceSeparator.
+func (c *GenericCache[K, V]) Namespace(name string, opts ...NamespaceOption) *GenericNamespace[K, V] {
+	return &GenericNamespace[K, V]{ns: c.inner.Namespace(name, opts...)}
+}
+
+// Name returns the name of the namespace.
+func (n *GenericNamespace[K, V]) Name() string {
+	return n.ns.Name()
+}
+
+// Get retrieves the value of key in the namespace.
+func (n *GenericNamespace[K, V]) Get(key K) (value V, found bool) {
+	val, found := n.ns.Get(keyToString(key))
+	if !found {
+		return value, false
+	}
+	typed, ok := val.(V)
+	return typed, ok
+}
+
+// Set stores a key-value pair in the namespace. Returns false if the value
+// was not stored.
+func (n *GenericNamespace[K, V]) Set(key K, value V) bool {
+	return n.ns.Set(keyToString(key), value)
+}
+
+// Delete removes key from the namespace. Returns true if the key was cached.
+func (n *GenericNamespace[K, V]) Delete(key K) bool {
+	return n.ns.Delete(keyToString(key))
+}
+
+// Has reports whether key is cached in the namespace.
+func (n *GenericNamespace[K, V]) Has(key K) bool {
+	return n.ns.Has(keyToString(key))
+}
+
+// GetOrLoad is GenericCache.GetOrLoad for key in the namespace, storing the
+// loaded value with the namespace TTL unless opts contain WithTTL.
+func (n *GenericNamespace[K, V]) GetOrLoad(key K, loader func() (V, error), opts ...LoadOption) (V, error) {
+	var zero V
+	result, err := n.ns.GetOrLoad(keyToString(key), func() (interface{}, error) {
+		return loader()
+	}, opts...)
+	if err != nil {
+		return zero, err
+	}
+	value, ok := result.(V)
+	if !ok {
+		return zero, NewErrInternal("GetOrLoad", nil)
+	}
+	return value, nil
+}
+
+// Keys returns the keys of the live entries of the namespace. Keys that
+// cannot be converted back to K are skipped.
+func (n *GenericNamespace[K, V]) Keys() []K {
+	var keys []K
+	for _, keyStr := range n.ns.Keys() {
+		if key, ok := keyFromString[K](keyStr); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of live entries of the namespace.
+func (n *GenericNamespace[K, V]) Len() int {
+	return n.ns.Len()
+}
+
+// Clear removes every entry of the namespace and returns the number of
+// entries removed.
+func (n *GenericNamespace[K, V]) Clear() int {
+	return n.ns.Clear()
+}
+
+// DeleteByPrefix removes the entries of the namespace whose key, in its
+// string form, starts with prefix.
+func (n *GenericNamespace[K, V]) DeleteByPrefix(prefix string) int {
+	return n.ns.DeleteByPrefix(prefix)
+}
@@ -0,0 +1,125 @@
+// namespace_view_test.go: tests for the Namespace view
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNamespace_ScopesOperations(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("indexed=%v", indexed), func(t *testing.T) {
+			cache := NewCache(Config{MaxSize: 100, IndexNamespaces: indexed}).(*wtinyLFUCache)
+			defer cache.Close()
+			users := cache.Namespace("users")
+			orders := cache.Namespace("orders")
+
+			users.Set("1", "alice")
+			users.Set("2", "bob")
+			users.Set("admin:1", "root")
+			orders.Set("1", "order")
+			cache.Set("users-legacy", "unrelated")
+
+			if v, found := cache.Get("users:1"); !found || v != "alice" {
+				t.Fatalf("users:1 = %v, %v", v, found)
+			}
+			if v, _ := users.Get("1"); v != "alice" {
+				t.Errorf("users.Get(1) = %v", v)
+			}
+			if v, _ := orders.Get("1"); v != "order" {
+				t.Errorf("orders.Get(1) = %v", v)
+			}
+			if users.Len() != 3 || orders.Len() != 1 {
+				t.Errorf("Len: users %d, orders %d", users.Len(), orders.Len())
+			}
+			keys := users.Keys()
+			sort.Strings(keys)
+			if fmt.Sprint(keys) != "[1 2 admin:1]" {
+				t.Errorf("users.Keys() = %v", keys)
+			}
+
+			if n := users.DeleteByPrefix("admin:"); n != 1 || users.Has("admin:1") {
+				t.Errorf("DeleteByPrefix removed %d", n)
+			}
+			if n := users.Clear(); n != 2 {
+				t.Errorf("Clear removed %d, want 2", n)
+			}
+			if users.Len() != 0 || !orders.Has("1") || !cache.Has("users-legacy") {
+				t.Error("Clear was not scoped to the namespace")
+			}
+			if users.Set("", 1) || users.Has("") {
+				t.Error("empty key accepted")
+			}
+		})
+	}
+}
+
+func TestNamespace_TTL(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Hour, TimeProvider: mockTime}).(*wtinyLFUCache)
+	defer cache.Close()
+	sessions := cache.Namespace("sessions", WithNamespaceTTL(time.Minute))
+
+	sessions.Set("a", 1)
+	if _, err := sessions.GetOrLoad("b", func() (interface{}, error) { return 2, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessions.GetOrLoad("c", func() (interface{}, error) { return 3, nil }, WithTTL(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("plain", 0)
+
+	mockTime.Advance(2 * time.Minute)
+	if sessions.Has("a") || sessions.Has("b") {
+		t.Error("namespace TTL not applied")
+	}
+	if !sessions.Has("c") || !cache.Has("plain") {
+		t.Error("WithTTL or Config.TTL entries expired with the namespace TTL")
+	}
+}
+
+func TestNamespace_Swappable(t *testing.T) {
+	swappable := NewSwappableCache(NewCache(Config{MaxSize: 100}))
+	tenant := swappable.Namespace("t1", WithNamespaceTTL(time.Hour))
+	tenant.Set("k", 1)
+
+	next := NewCache(Config{MaxSize: 100})
+	defer next.Close()
+	_ = swappable.Replace(next).Close()
+
+	if tenant.Has("k") {
+		t.Error("view still reads the replaced cache")
+	}
+	tenant.Set("k", 2)
+	if v, _ := next.Get("t1:k"); v != 2 {
+		t.Errorf("view did not follow the replacement: %v", v)
+	}
+}
+
+func TestGenericNamespace(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 100})
+	defer cache.Close()
+	tenant := cache.Namespace("t1")
+
+	tenant.Set(7, "seven")
+	if v, found := tenant.Get(7); !found || v != "seven" {
+		t.Fatalf("Get(7) = %v, %v", v, found)
+	}
+	if keys := tenant.Keys(); len(keys) != 1 || keys[0] != 7 || tenant.Len() != 1 {
+		t.Errorf("Keys() = %v", keys)
+	}
+	if _, found := cache.Get(7); found {
+		t.Error("namespaced key visible without the prefix")
+	}
+	tenant.Delete(7)
+	if tenant.Has(7) || tenant.Clear() != 0 {
+		t.Error("Delete did not remove the key")
+	}
+}
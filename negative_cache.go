@@ -0,0 +1,94 @@
+// negative_cache.go: introspection and invalidation of cached loader errors
+//
+// InvalidateNegative, NegativeLen and the negative hit/miss counters.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// NegativeCacheRecorder is an optional MetricsCollector extension.
+// Collectors implementing it are notified of every negative cache lookup
+// made by GetOrLoad and GetOrLoadWithContext (see Config.NegativeCacheTTL).
+type NegativeCacheRecorder interface {
+	// RecordNegativeLookup records a lookup in the negative cache; hit
+	// reports that a cached loader error was returned.
+	RecordNegativeLookup(hit bool)
+}
+
+// negativeLookup returns the live cached error of key, or nil. It must only
+// be called when negative caching is enabled.
+func (c *wtinyLFUCache) negativeLookup(key string) error {
+	negKey := "neg:" + key
+	if negEntry, found := c.negativeCache.Load(negKey); found {
+		neg := negEntry.(negativeEntry)
+		// Check if negative entry has expired
+		if c.timeProvider.Now() <= neg.expireAt {
+			c.recordNegativeLookup(true)
+			return neg.err
+		}
+		// Expired, remove it
+		c.negativeCache.Delete(negKey)
+	}
+	c.recordNegativeLookup(false)
+	return nil
+}
+
+// recordNegativeLookup counts a negative cache lookup.
+func (c *wtinyLFUCache) recordNegativeLookup(hit bool) {
+	if hit {
+		atomic.AddInt64(&c.negativeHits, 1)
+	} else {
+		atomic.AddInt64(&c.negativeMisses, 1)
+	}
+	if c.negativeRecorder != nil {
+		c.negativeRecorder.RecordNegativeLookup(hit)
+	}
+}
+
+// storeNegative caches err as the outcome of loading key.
+func (c *wtinyLFUCache) storeNegative(key string, err error) {
+	c.negativeCache.Store("neg:"+key, negativeEntry{
+		err:      err,
+		expireAt: c.timeProvider.Now() + c.negativeTTLNanos,
+	})
+}
+
+// InvalidateNegative removes the cached loader error of key, so the next
+// GetOrLoad calls the loader again. Returns true if a live cached error was
+// removed.
+func (c *wtinyLFUCache) InvalidateNegative(key string) bool {
+	negEntry, found := c.negativeCache.LoadAndDelete("neg:" + key)
+	if !found {
+		return false
+	}
+	return c.timeProvider.Now() <= negEntry.(negativeEntry).expireAt
+}
+
+// NegativeLen returns the number of live cached loader errors.
+// O(cached errors).
+func (c *wtinyLFUCache) NegativeLen() int {
+	now := c.timeProvider.Now()
+	n := 0
+	c.negativeCache.Range(func(_, value interface{}) bool {
+		if neg, ok := value.(negativeEntry); ok && now <= neg.expireAt {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// InvalidateNegative removes the cached loader error of key.
+// Returns true if a live cached error was removed.
+func (c *GenericCache[K, V]) InvalidateNegative(key K) bool {
+	return c.inner.InvalidateNegative(keyToString(key))
+}
+
+// NegativeLen returns the number of live cached loader errors.
+func (c *GenericCache[K, V]) NegativeLen() int {
+	return c.inner.NegativeLen()
+}
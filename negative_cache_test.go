@@ -9,6 +9,7 @@ package balios
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -330,3 +331,97 @@ func TestNegativeCaching_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Loader should still be called only once, got %d", callCount)
 	}
 }
+
+// negativeCollector records negative cache lookups.
+type negativeCollector struct {
+	NoOpMetricsCollector
+	hits, misses int64
+}
+
+func (n *negativeCollector) RecordNegativeLookup(hit bool) {
+	if hit {
+		atomic.AddInt64(&n.hits, 1)
+	} else {
+		atomic.AddInt64(&n.misses, 1)
+	}
+}
+
+func TestNegativeCache_StatsAndMetrics(t *testing.T) {
+	collector := &negativeCollector{}
+	cache := NewCache(Config{MaxSize: 100, NegativeCacheTTL: time.Hour, MetricsCollector: collector})
+	loadErr := errors.New("backend down")
+	failing := func() (interface{}, error) { return nil, loadErr }
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetOrLoad("k", failing); !errors.Is(err, loadErr) {
+			t.Fatalf("expected the loader error, got %v", err)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.NegativeMisses != 1 || stats.NegativeHits != 2 {
+		t.Errorf("expected 1 negative miss and 2 hits, got %d and %d", stats.NegativeMisses, stats.NegativeHits)
+	}
+	if atomic.LoadInt64(&collector.misses) != 1 || atomic.LoadInt64(&collector.hits) != 2 {
+		t.Errorf("collector got %d misses and %d hits", collector.misses, collector.hits)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats.NegativeHits != 0 || stats.NegativeMisses != 0 {
+		t.Errorf("Clear must reset negative counters: %+v", stats)
+	}
+}
+
+func TestNegativeCache_InvalidateAndLen(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: 1_000_000_000}
+	cache := NewGenericCache[string, string](Config{
+		MaxSize:          100,
+		NegativeCacheTTL: time.Minute,
+		TimeProvider:     mockTime,
+	})
+
+	calls := 0
+	loader := func() (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("backend down")
+		}
+		return "value", nil
+	}
+
+	_, _ = cache.GetOrLoad("a", loader)
+	_, _ = cache.GetOrLoad("b", func() (string, error) { return "", errors.New("backend down") })
+	if n := cache.NegativeLen(); n != 2 {
+		t.Fatalf("expected 2 cached errors, got %d", n)
+	}
+
+	if !cache.InvalidateNegative("a") {
+		t.Error("InvalidateNegative must report the removed error")
+	}
+	if cache.InvalidateNegative("a") || cache.InvalidateNegative("missing") {
+		t.Error("InvalidateNegative must return false without a cached error")
+	}
+	if v, err := cache.GetOrLoad("a", loader); err != nil || v != "value" {
+		t.Errorf("an invalidated key must reach the loader, got %q, %v", v, err)
+	}
+
+	mockTime.Advance(2 * time.Minute)
+	if n := cache.NegativeLen(); n != 0 {
+		t.Errorf("expired errors must not be counted, got %d", n)
+	}
+	if cache.InvalidateNegative("b") {
+		t.Error("an expired error is not live")
+	}
+}
+
+func TestNegativeCache_DisabledNotCounted(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	_, _ = cache.GetOrLoad("k", func() (interface{}, error) { return nil, errors.New("fail") })
+
+	if stats := cache.Stats(); stats.NegativeHits != 0 || stats.NegativeMisses != 0 {
+		t.Errorf("lookups must not be counted without NegativeCacheTTL: %+v", stats)
+	}
+	if n := cache.NegativeLen(); n != 0 {
+		t.Errorf("expected no cached errors, got %d", n)
+	}
+}
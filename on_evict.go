@@ -0,0 +1,160 @@
+// on_evict.go: removal listener for resource management
+//
+// Config.OnEvict is called exactly once for every value that leaves the
+// cache, so resources it owns can be released.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// EvictReason identifies why a value left the cache.
+type EvictReason int
+
+const (
+	// ReasonEvicted is reported when an entry is evicted to make room
+	// (MaxSize or MaxWeight).
+	ReasonEvicted EvictReason = iota + 1
+
+	// ReasonExpired is reported when an entry is removed after its TTL elapsed.
+	ReasonExpired
+
+	// ReasonDeleted is reported when an entry is removed by Delete (or an
+	// operation built on it, such as InvalidateKey or ClearNamespace) or Clear.
+	ReasonDeleted
+
+	// ReasonReplaced is reported with the previous value when a write
+	// (Set, Swap, CompareAndSwap, ...) replaces the value of an existing key.
+	ReasonReplaced
+)
+
+// String returns the reason name.
+func (r EvictReason) String() string {
+	switch r {
+	case ReasonEvicted:
+		return "evicted"
+	case ReasonExpired:
+		return "expired"
+	case ReasonDeleted:
+		return "deleted"
+	case ReasonReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// OnEvictFor adapts a typed listener for use as Config.OnEvict with a
+// GenericCache[K, V]. Keys are passed in their string form. A value that is
+// not a V (e.g. a nil interface) is passed as the zero V.
+//
+// Example:
+//
+//	cfg := balios.Config{
+//	    MaxSize: 1000,
+//	    OnEvict: balios.OnEvictFor(func(key string, f *os.File, reason balios.EvictReason) {
+//	        _ = f.Close()
+//	    }),
+//	}
+//	files := balios.NewGenericCache[string, *os.File](cfg)
+func OnEvictFor[V any](listener func(key string, value V, reason EvictReason)) func(key string, value interface{}, reason EvictReason) {
+	return func(key string, value interface{}, reason EvictReason) {
+		v, _ := value.(V)
+		listener(key, v, reason)
+	}
+}
+
+// evicted is a removed key-value pair waiting to be reported to OnEvict.
+type evicted struct {
+	key   string
+	value interface{}
+}
+
+// takeEvicted reads the key and value of an entry owned by the caller
+// (entryPending) for a later notifyEvict.
+func takeEvicted(entry *entry) evicted {
+	return evicted{key: entry.loadKey(), value: holderValue(entry)}
+}
+
+// notifyEvict calls Config.OnEvict (and, for expirations, the legacy
+// Config.OnExpire), recovering from panics.
+func (c *wtinyLFUCache) notifyEvict(key string, value interface{}, reason EvictReason) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.logger.Error("balios: OnEvict panicked",
+				"key", key,
+				"reason", reason.String(),
+				"panic", r,
+			)
+		}
+	}()
+	if c.valueCodec != nil {
+		value = c.decodedOrNil(value)
+	}
+	if c.onEvict != nil {
+		c.onEvict(key, value, reason)
+	}
+	if reason == ReasonExpired && c.onExpire != nil {
+		c.onExpire(key, value)
+	}
+}
+
+// clearPendingRetries bounds how long Clear waits for a slot held by a
+// concurrent writer (removals may hold a slot while OnEvict runs).
+const clearPendingRetries = 64
+
+// clearEntries resets every slot of t to entryEmpty, including those left by
+// earlier generations. With an OnEvict listener it returns the key-value
+// pairs of the live slots, for Clear to report.
+//
+// Slots are taken over with the CAS protocol of the other removals, and size
+// and weight are released per removed entry rather than reset to zero: a
+// write racing with Clear either completes before its slot is cleared (and
+// is cleared) or after (and survives, counted), so the counters always match
+// the table. Slots held by a concurrent writer are waited for briefly; one
+// still held after clearPendingRetries belongs to a write that completes
+// after Clear.
+func (c *wtinyLFUCache) clearEntries(t *cacheTable) []evicted {
+	g := t.gen.Load()
+	var dropped []evicted
+	for i := range t.entries {
+		entry := &t.entries[i]
+		for retry := 0; ; retry++ {
+			state := atomic.LoadInt32(&entry.valid)
+			if stateOf(state) == entryPending && retry < clearPendingRetries {
+				runtime.Gosched()
+				continue
+			}
+			if state == entryEmpty || stateOf(state) == entryPending {
+				break // Empty, or still owned by a writer
+			}
+			if !atomic.CompareAndSwapInt32(&entry.valid, state, entryPending) {
+				continue // Changed meanwhile: look again
+			}
+			if state == g.live {
+				if c.onEvict != nil {
+					dropped = append(dropped, takeEvicted(entry))
+				}
+				if c.maxWeight > 0 {
+					c.chargeWeight(g, entry, 0)
+				}
+			}
+			entry.storeKey("")
+			// Note: We don't clear atomic.Value as it requires type consistency.
+			// Values will be overwritten when entries are reused.
+			atomic.StoreUint64(&entry.keyHash, 0)
+			atomic.StoreInt32(&entry.valid, entryEmpty)
+			if state == g.live {
+				atomic.AddInt64(&g.size, -1)
+			}
+			break
+		}
+	}
+	return dropped
+}
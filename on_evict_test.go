@@ -0,0 +1,247 @@
+// on_evict_test.go: tests for the OnEvict removal listener
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// removal is one OnEvict notification.
+type removal struct {
+	key    string
+	value  interface{}
+	reason EvictReason
+}
+
+// removalRecorder collects OnEvict notifications.
+type removalRecorder struct {
+	mu       sync.Mutex
+	removals []removal
+}
+
+func (r *removalRecorder) onEvict(key string, value interface{}, reason EvictReason) {
+	r.mu.Lock()
+	r.removals = append(r.removals, removal{key, value, reason})
+	r.mu.Unlock()
+}
+
+func (r *removalRecorder) byReason(reason EvictReason) []removal {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []removal
+	for _, rm := range r.removals {
+		if rm.reason == reason {
+			result = append(result, rm)
+		}
+	}
+	return result
+}
+
+func TestOnEvict_Reasons(t *testing.T) {
+	rec := &removalRecorder{}
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Minute, TimeProvider: mockTime, OnEvict: rec.onEvict}).(*wtinyLFUCache)
+
+	cache.Set("a", 1)
+	cache.Set("a", 2)
+	cache.Swap("a", 3)
+	cache.CompareAndSwap("a", 3, 4)
+	if got := rec.byReason(ReasonReplaced); len(got) != 3 || got[0].value != 1 || got[1].value != 2 || got[2].value != 3 {
+		t.Errorf("expected replaced values 1, 2, 3, got %+v", got)
+	}
+
+	cache.Set("b", "bee")
+	cache.Delete("b")
+	if got := rec.byReason(ReasonDeleted); len(got) != 1 || got[0].key != "b" || got[0].value != "bee" {
+		t.Errorf("expected deletion of b, got %+v", got)
+	}
+
+	mockTime.Advance(2 * time.Minute)
+	cache.Get("a")
+	if got := rec.byReason(ReasonExpired); len(got) != 1 || got[0].key != "a" || got[0].value != 4 {
+		t.Errorf("expected expiration of a=4, got %+v", got)
+	}
+}
+
+func TestOnEvict_Eviction(t *testing.T) {
+	rec := &removalRecorder{}
+	cache := NewCache(Config{MaxSize: 16, OnEvict: rec.onEvict})
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+
+	evicted := rec.byReason(ReasonEvicted)
+	if uint64(len(evicted)) != cache.Stats().Evictions {
+		t.Fatalf("expected one notification per eviction: %d vs %d", len(evicted), cache.Stats().Evictions)
+	}
+	for _, rm := range evicted {
+		if rm.key != fmt.Sprintf("k%d", rm.value) {
+			t.Errorf("evicted key and value do not match: %+v", rm)
+		}
+		if cache.Has(rm.key) {
+			t.Errorf("evicted key %q still present", rm.key)
+		}
+	}
+}
+
+func TestOnEvict_ExpireNowAndClear(t *testing.T) {
+	rec := &removalRecorder{}
+	var legacy []string
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{
+		MaxSize:      100,
+		TTL:          time.Minute,
+		TimeProvider: mockTime,
+		OnEvict:      rec.onEvict,
+		OnExpire:     func(key string, value interface{}) { legacy = append(legacy, key) },
+	})
+	cache.Set("old", 1)
+	mockTime.Advance(2 * time.Minute)
+	cache.Set("x", 2)
+	cache.Set("y", 3)
+
+	if n := cache.ExpireNow(); n != 1 {
+		t.Fatalf("expected 1 expiration, got %d", n)
+	}
+	if got := rec.byReason(ReasonExpired); len(got) != 1 || got[0].key != "old" {
+		t.Errorf("expected expiration of old, got %+v", got)
+	}
+	if len(legacy) != 1 || legacy[0] != "old" {
+		t.Errorf("OnExpire must still be called on expiration, got %v", legacy)
+	}
+
+	cache.Clear()
+	if got := rec.byReason(ReasonDeleted); len(got) != 2 {
+		t.Errorf("Clear must report every dropped entry, got %+v", got)
+	}
+}
+
+func TestOnEvict_PanicIsRecovered(t *testing.T) {
+	calls := 0
+	logger := &recordingLogger{}
+	cache := NewCache(Config{
+		MaxSize: 100,
+		Logger:  logger,
+		OnEvict: func(key string, value interface{}, reason EvictReason) {
+			calls++
+			panic("boom")
+		},
+	})
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Delete("a")
+	cache.Delete("b")
+
+	if calls != 2 {
+		t.Errorf("a panicking listener must keep being called, got %d calls", calls)
+	}
+	if cache.Has("a") || cache.Has("b") {
+		t.Error("deletions must complete despite the panic")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected empty cache, got %d entries", cache.Len())
+	}
+	if logger.errorCount() != 2 {
+		t.Errorf("every panic must be logged, got %d errors", logger.errorCount())
+	}
+}
+
+func TestOnEvict_ListenerCallsBack(t *testing.T) {
+	var cache Cache
+	cache = NewCache(Config{
+		MaxSize: 100,
+		OnEvict: func(key string, value interface{}, reason EvictReason) {
+			if reason == ReasonDeleted {
+				cache.Set("tombstone:"+key, value)
+			}
+		},
+	})
+	cache.Set("a", 1)
+	cache.Delete("a")
+	if v, ok := cache.Get("tombstone:a"); !ok || v != 1 {
+		t.Errorf("listener must be able to write to the cache, got %v, %v", v, ok)
+	}
+}
+
+func TestOnEvictFor_Generic(t *testing.T) {
+	type handle struct{ closed bool }
+	cache := NewGenericCache[string, *handle](Config{
+		MaxSize: 100,
+		OnEvict: OnEvictFor(func(key string, h *handle, reason EvictReason) {
+			h.closed = true
+		}),
+	})
+	h1, h2 := &handle{}, &handle{}
+	cache.Set("f", h1)
+	cache.Set("f", h2)
+	cache.Delete("f")
+	if !h1.closed || !h2.closed {
+		t.Errorf("replaced and deleted handles must be released: %v %v", h1.closed, h2.closed)
+	}
+}
+
+func TestOnEvict_ConcurrentExactlyOnce(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		released = make(map[int]int)
+	)
+	cache := NewCache(Config{
+		MaxSize: 64,
+		OnEvict: func(key string, value interface{}, reason EvictReason) {
+			mu.Lock()
+			released[value.(int)]++
+			mu.Unlock()
+		},
+	})
+
+	const goroutines, perGoroutine = 8, 2000
+	var stored sync.Map
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				value := g*perGoroutine + i
+				key := fmt.Sprintf("k%d", i%100)
+				if i%7 == 0 {
+					cache.Delete(key)
+					continue
+				}
+				if cache.Set(key, value) {
+					stored.Store(value, struct{}{})
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	live := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		if v, ok := cache.Get(fmt.Sprintf("k%d", i)); ok {
+			live[v.(int)] = true
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	stored.Range(func(k, _ interface{}) bool {
+		value := k.(int)
+		switch n := released[value]; {
+		case n > 1:
+			t.Errorf("value %d released %d times", value, n)
+		case n == 0 && !live[value]:
+			t.Errorf("value %d neither live nor released", value)
+		case n == 1 && live[value]:
+			t.Errorf("value %d released while still live", value)
+		}
+		return true
+	})
+}
@@ -44,6 +44,10 @@
 //   - balios_get_misses_total: Counter of cache misses
 //   - balios_evictions_total: Counter of evictions
 //   - balios_expirations_total: Counter of TTL-based expirations
+//   - balios_has_latency_ns: Histogram of Has() operation latencies in nanoseconds
+//   - balios_has_hits_total / balios_has_misses_total: Counters of Has() results
+//   - balios_len_calls_total / balios_stats_calls_total: Counters of Len() and Stats() calls
+//   - balios_sampled_latency_ns: Histogram of sampled Get()/Set() latencies by hashed key (WithKeySampling only)
 //
 // All metrics are automatically aggregated by the OTEL SDK and can be exported to
 // any OTEL-compatible backend. Histograms automatically calculate percentiles (p50, p95, p99).
@@ -57,8 +61,10 @@ package otel
 import (
 	"context"
 	"errors"
+	"sync"
 
 	"github.com/agilira/balios"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
@@ -81,6 +87,21 @@ type OTelMetricsCollector struct {
 	misses        metric.Int64Counter   // Cache misses counter
 	evictions     metric.Int64Counter   // Evictions counter
 	expirations   metric.Int64Counter   // Expirations counter
+	hasLatency    metric.Int64Histogram // Has operation latency histogram
+	hasHits       metric.Int64Counter   // Has calls finding the key
+	hasMisses     metric.Int64Counter   // Has calls not finding the key
+	lenCalls      metric.Int64Counter   // Len calls counter
+	statsCalls    metric.Int64Counter   // Stats calls counter
+
+	// Attributes attached to every measurement (see WithAttributes)
+	attrs      metric.MeasurementOption
+	attributes []attribute.KeyValue // attrs as a list, for the sampled histogram
+
+	// Sampled keys (see key_samples.go), zero unless WithKeySampling
+	sampledLatency metric.Int64Histogram
+	keySampleEvery int
+	keyHashBits    int
+	sampleAttrs    sync.Map // sampleAttrs -> metric.MeasurementOption
 }
 
 // Options for configuring OTelMetricsCollector.
@@ -88,25 +109,55 @@ type Options struct {
 	// MeterName is the name of the OpenTelemetry meter.
 	// Default: "github.com/agilira/balios"
 	MeterName string
+
+	// Attributes are attached to every measurement, so that several caches
+	// can share the same instruments and be told apart by label.
+	// Default: none
+	Attributes []attribute.KeyValue
+
+	// KeySampleEvery records one Get or Set in KeySampleEvery with its
+	// hashed key (see WithKeySampling). Default: 0 (disabled)
+	KeySampleEvery int
+
+	// KeyHashBits is the width of the hashed key attribute of sampled
+	// operations. Default: DefaultKeyHashBits
+	KeyHashBits int
 }
 
 // Option is a functional option for configuring OTelMetricsCollector.
 type Option func(*Options)
 
 // WithMeterName sets a custom meter name.
-// This is useful for distinguishing metrics from multiple cache instances
-// or integrating with existing OTEL instrumentation.
+// This is useful for integrating with existing OTEL instrumentation; to
+// distinguish cache instances, prefer WithAttributes.
 func WithMeterName(name string) Option {
 	return func(o *Options) {
 		o.MeterName = name
 	}
 }
 
+// WithAttributes attaches attributes to every measurement of the collector.
+// Give each cache its own collector with a distinguishing attribute to tell
+// caches apart on the same instruments, rather than using one meter per
+// cache:
+//
+//	users, _ := NewOTelMetricsCollector(provider,
+//	    WithAttributes(attribute.String("cache_name", "users")))
+//	sessions, _ := NewOTelMetricsCollector(provider,
+//	    WithAttributes(attribute.String("cache_name", "sessions")))
+//
+// Repeated calls accumulate; for duplicate keys the last value wins.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(o *Options) {
+		o.Attributes = append(o.Attributes, attrs...)
+	}
+}
+
 // NewOTelMetricsCollector creates a new OpenTelemetry metrics collector.
 //
 // Parameters:
 //   - provider: OpenTelemetry MeterProvider. Must not be nil.
-//   - opts: Optional configuration options (meter name, etc.)
+//   - opts: Optional configuration options (meter name, attributes)
 //
 // Returns:
 //   - *OTelMetricsCollector: The collector instance
@@ -133,7 +184,8 @@ func NewOTelMetricsCollector(provider metric.MeterProvider, opts ...Option) (*OT
 
 	// Apply options
 	options := Options{
-		MeterName: "github.com/agilira/balios",
+		MeterName:   "github.com/agilira/balios",
+		KeyHashBits: DefaultKeyHashBits,
 	}
 	for _, opt := range opts {
 		opt(&options)
@@ -142,8 +194,12 @@ func NewOTelMetricsCollector(provider metric.MeterProvider, opts ...Option) (*OT
 	// Create meter
 	meter := provider.Meter(options.MeterName)
 
-	// Create collector
-	collector := &OTelMetricsCollector{}
+	// Create collector. The attribute set is built once so that recording
+	// stays allocation-free
+	collector := &OTelMetricsCollector{
+		attrs:      metric.WithAttributeSet(attribute.NewSet(options.Attributes...)),
+		attributes: options.Attributes,
+	}
 
 	// Create Get latency histogram
 	var err error
@@ -212,17 +268,66 @@ func NewOTelMetricsCollector(provider metric.MeterProvider, opts ...Option) (*OT
 		return nil, err
 	}
 
+	// Create probe instruments (Has, Len, Stats)
+	collector.hasLatency, err = meter.Int64Histogram(
+		"balios_has_latency_ns",
+		metric.WithDescription("Latency of Has operations in nanoseconds"),
+		metric.WithUnit("ns"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collector.hasHits, err = meter.Int64Counter(
+		"balios_has_hits_total",
+		metric.WithDescription("Total number of Has calls finding the key"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collector.hasMisses, err = meter.Int64Counter(
+		"balios_has_misses_total",
+		metric.WithDescription("Total number of Has calls not finding the key"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collector.lenCalls, err = meter.Int64Counter(
+		"balios_len_calls_total",
+		metric.WithDescription("Total number of Len calls"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collector.statsCalls, err = meter.Int64Counter(
+		"balios_stats_calls_total",
+		metric.WithDescription("Total number of Stats calls"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the sampled latency histogram (WithKeySampling only)
+	if err := collector.newKeySampling(meter, options); err != nil {
+		return nil, err
+	}
+
 	return collector, nil
 }
 
 // RecordGet records a Get operation.
 //
 // Parameters:
-//   - latencyNs: Operation latency in nanoseconds. Must be >= 0.
+//   - latencyNs: Operation latency in nanoseconds, or -1 when the cache
+//     has latency measurement disabled (Config.DisableMetricsLatency).
 //   - hit: Whether the operation was a cache hit (true) or miss (false).
 //
 // This method:
-//   - Records latency to the Get latency histogram (used for percentile calculation)
+//   - Records latency to the Get latency histogram (used for percentile calculation),
+//     unless latencyNs is negative
 //   - Increments either hits or misses counter
 //
 // Thread-safety: Safe for concurrent use.
@@ -230,41 +335,51 @@ func NewOTelMetricsCollector(provider metric.MeterProvider, opts ...Option) (*OT
 func (c *OTelMetricsCollector) RecordGet(latencyNs int64, hit bool) {
 	ctx := context.Background()
 
-	// Record latency histogram
-	c.getLatency.Record(ctx, latencyNs)
+	// Record latency histogram (skipped when latency measurement is disabled)
+	if latencyNs >= 0 {
+		c.getLatency.Record(ctx, latencyNs, c.attrs)
+	}
 
 	// Increment hit/miss counter
 	if hit {
-		c.hits.Add(ctx, 1)
+		c.hits.Add(ctx, 1, c.attrs)
 	} else {
-		c.misses.Add(ctx, 1)
+		c.misses.Add(ctx, 1, c.attrs)
 	}
 }
 
 // RecordSet records a Set operation.
 //
 // Parameters:
-//   - latencyNs: Operation latency in nanoseconds. Must be >= 0.
+//   - latencyNs: Operation latency in nanoseconds, or -1 when latency
+//     measurement is disabled (the call is then a no-op).
 //
 // This method records latency to the Set latency histogram.
 //
 // Thread-safety: Safe for concurrent use.
 // Performance: ~50-100ns overhead, allocation-free.
 func (c *OTelMetricsCollector) RecordSet(latencyNs int64) {
-	c.setLatency.Record(context.Background(), latencyNs)
+	if latencyNs < 0 {
+		return
+	}
+	c.setLatency.Record(context.Background(), latencyNs, c.attrs)
 }
 
 // RecordDelete records a Delete operation.
 //
 // Parameters:
-//   - latencyNs: Operation latency in nanoseconds. Must be >= 0.
+//   - latencyNs: Operation latency in nanoseconds, or -1 when latency
+//     measurement is disabled (the call is then a no-op).
 //
 // This method records latency to the Delete latency histogram.
 //
 // Thread-safety: Safe for concurrent use.
 // Performance: ~50-100ns overhead, allocation-free.
 func (c *OTelMetricsCollector) RecordDelete(latencyNs int64) {
-	c.deleteLatency.Record(context.Background(), latencyNs)
+	if latencyNs < 0 {
+		return
+	}
+	c.deleteLatency.Record(context.Background(), latencyNs, c.attrs)
 }
 
 // RecordEviction records an eviction event.
@@ -274,7 +389,7 @@ func (c *OTelMetricsCollector) RecordDelete(latencyNs int64) {
 // Thread-safety: Safe for concurrent use.
 // Performance: ~50-100ns overhead, allocation-free.
 func (c *OTelMetricsCollector) RecordEviction() {
-	c.evictions.Add(context.Background(), 1)
+	c.evictions.Add(context.Background(), 1, c.attrs)
 }
 
 // RecordExpiration records a TTL-based expiration event.
@@ -284,8 +399,48 @@ func (c *OTelMetricsCollector) RecordEviction() {
 // Thread-safety: Safe for concurrent use.
 // Performance: ~50-100ns overhead, allocation-free.
 func (c *OTelMetricsCollector) RecordExpiration() {
-	c.expirations.Add(context.Background(), 1)
+	c.expirations.Add(context.Background(), 1, c.attrs)
+}
+
+// RecordHas records a Has operation.
+//
+// Parameters:
+//   - latencyNs: Operation latency in nanoseconds, or -1 when latency
+//     measurement is disabled (the histogram is then skipped).
+//   - hit: Whether the key was found.
+//
+// Thread-safety: Safe for concurrent use.
+// Performance: ~50-100ns overhead, allocation-free.
+func (c *OTelMetricsCollector) RecordHas(latencyNs int64, hit bool) {
+	ctx := context.Background()
+	if latencyNs >= 0 {
+		c.hasLatency.Record(ctx, latencyNs, c.attrs)
+	}
+	if hit {
+		c.hasHits.Add(ctx, 1, c.attrs)
+	} else {
+		c.hasMisses.Add(ctx, 1, c.attrs)
+	}
+}
+
+// RecordLen records a Len call.
+//
+// Thread-safety: Safe for concurrent use.
+// Performance: ~50-100ns overhead, allocation-free.
+func (c *OTelMetricsCollector) RecordLen() {
+	c.lenCalls.Add(context.Background(), 1, c.attrs)
+}
+
+// RecordStats records a Stats call.
+//
+// Thread-safety: Safe for concurrent use.
+// Performance: ~50-100ns overhead, allocation-free.
+func (c *OTelMetricsCollector) RecordStats() {
+	c.statsCalls.Add(context.Background(), 1, c.attrs)
 }
 
-// Compile-time interface check
-var _ balios.MetricsCollector = (*OTelMetricsCollector)(nil)
+// Compile-time interface checks
+var (
+	_ balios.MetricsCollector      = (*OTelMetricsCollector)(nil)
+	_ balios.ProbeMetricsCollector = (*OTelMetricsCollector)(nil)
+)
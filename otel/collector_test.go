@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/agilira/balios"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
@@ -390,3 +391,167 @@ func TestOTelMetricsCollector_WithOptions(t *testing.T) {
 		t.Errorf("Expected scope name 'custom_balios', got '%s'", rm.ScopeMetrics[0].Scope.Name)
 	}
 }
+
+// TestOTelMetricsCollector_NegativeLatencySkipsHistogram verifies that latency -1
+// (balios.Config.DisableMetricsLatency) keeps counters but skips histograms.
+func TestOTelMetricsCollector_NegativeLatencySkipsHistogram(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	collector, err := NewOTelMetricsCollector(provider)
+	if err != nil {
+		t.Fatalf("NewOTelMetricsCollector() error = %v", err)
+	}
+
+	collector.RecordGet(-1, true)
+	collector.RecordSet(-1)
+	collector.RecordDelete(-1)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	var foundHits bool
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case "balios_get_latency_ns", "balios_set_latency_ns", "balios_delete_latency_ns":
+				hist, ok := m.Data.(metricdata.Histogram[int64])
+				if !ok {
+					continue
+				}
+				for _, dp := range hist.DataPoints {
+					if dp.Count != 0 {
+						t.Errorf("%s: expected no samples for latency -1, got %d", m.Name, dp.Count)
+					}
+				}
+			case "balios_get_hits_total":
+				foundHits = true
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				if !ok || len(sum.DataPoints) == 0 || sum.DataPoints[0].Value != 1 {
+					t.Errorf("expected 1 hit to be counted, got %+v", m.Data)
+				}
+			}
+		}
+	}
+	if !foundHits {
+		t.Error("hits counter not found")
+	}
+}
+
+// TestOTelMetricsCollector_ProbeMetrics tests Has, Len and Stats metrics recorded through a cache
+func TestOTelMetricsCollector_ProbeMetrics(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	collector, err := NewOTelMetricsCollector(provider)
+	if err != nil {
+		t.Fatalf("NewOTelMetricsCollector() error = %v", err)
+	}
+
+	cache := balios.NewCache(balios.Config{MaxSize: 100, MetricsCollector: collector})
+	cache.Set("k", 1)
+	cache.Has("k")
+	cache.Has("k")
+	cache.Has("missing")
+	cache.Len()
+	cache.Stats()
+	cache.Stats()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	want := map[string]int64{
+		"balios_has_hits_total":    2,
+		"balios_has_misses_total":  1,
+		"balios_len_calls_total":   1,
+		"balios_stats_calls_total": 2,
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case "balios_has_latency_ns":
+				hist, ok := m.Data.(metricdata.Histogram[int64])
+				if !ok || len(hist.DataPoints) == 0 || hist.DataPoints[0].Count != 3 {
+					t.Errorf("expected 3 Has latency samples, got %+v", m.Data)
+				}
+			default:
+				expected, tracked := want[m.Name]
+				if !tracked {
+					continue
+				}
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				if !ok || len(sum.DataPoints) == 0 || sum.DataPoints[0].Value != expected {
+					t.Errorf("%s: expected %d, got %+v", m.Name, expected, m.Data)
+				}
+				delete(want, m.Name)
+			}
+		}
+	}
+	for name := range want {
+		t.Errorf("%s metric not found", name)
+	}
+}
+
+// TestOTelMetricsCollector_WithAttributes verifies that two caches sharing the
+// same instruments are told apart by their attributes
+func TestOTelMetricsCollector_WithAttributes(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	users, err := NewOTelMetricsCollector(provider, WithAttributes(attribute.String("cache_name", "users")))
+	if err != nil {
+		t.Fatalf("NewOTelMetricsCollector() error = %v", err)
+	}
+	sessions, err := NewOTelMetricsCollector(provider,
+		WithAttributes(attribute.String("cache_name", "sessions")),
+		WithAttributes(attribute.String("tier", "hot")),
+	)
+	if err != nil {
+		t.Fatalf("NewOTelMetricsCollector() error = %v", err)
+	}
+
+	users.RecordGet(100, true)
+	users.RecordGet(100, true)
+	sessions.RecordGet(100, true)
+	sessions.RecordHas(50, false)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	hits := map[string]int64{}
+	var hasMissesTier string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				name, _ := dp.Attributes.Value("cache_name")
+				switch m.Name {
+				case "balios_get_hits_total":
+					hits[name.AsString()] += dp.Value
+				case "balios_has_misses_total":
+					tier, _ := dp.Attributes.Value("tier")
+					hasMissesTier = tier.AsString()
+				}
+			}
+		}
+	}
+
+	if hits["users"] != 2 || hits["sessions"] != 1 || len(hits) != 2 {
+		t.Errorf("expected hits split by cache_name (users=2, sessions=1), got %v", hits)
+	}
+	if hasMissesTier != "hot" {
+		t.Errorf("expected accumulated attributes on probe metrics, got tier %q", hasMissesTier)
+	}
+}
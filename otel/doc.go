@@ -80,13 +80,20 @@
 //
 // # Configuration
 //
-// Custom meter name (useful for multiple cache instances):
+// Custom meter name:
 //
 //	collector, err := baliosostel.NewOTelMetricsCollector(
 //	    provider,
 //	    baliosostel.WithMeterName("myapp_user_cache"),
 //	)
 //
+// Attributes attached to every measurement (to tell caches apart by label):
+//
+//	collector, err := baliosostel.NewOTelMetricsCollector(
+//	    provider,
+//	    baliosostel.WithAttributes(attribute.String("cache_name", "users")),
+//	)
+//
 // Custom histogram buckets for better percentile accuracy:
 //
 //	provider := metric.NewMeterProvider(
@@ -216,9 +223,10 @@
 //	provider := metric.NewMeterProvider(metric.WithReader(exporter))
 //	defer provider.Shutdown(context.Background())
 //
-//	collector1, _ := baliosostel.NewOTelMetricsCollector(provider)
+//	collector1, _ := baliosostel.NewOTelMetricsCollector(provider,
+//	    baliosostel.WithAttributes(attribute.String("cache_name", "users")))
 //	collector2, _ := baliosostel.NewOTelMetricsCollector(provider,
-//	    baliosostel.WithMeterName("cache2"))
+//	    baliosostel.WithAttributes(attribute.String("cache_name", "sessions")))
 //
 // 2. Always shutdown MeterProvider on exit:
 //
@@ -0,0 +1,135 @@
+// key_samples.go: sampled, hashed keys on a latency histogram
+//
+// With WithKeySampling(n), one Get or Set in n is recorded on
+// balios_sampled_latency_ns with a truncated hash of its key.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agilira/balios"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// DefaultKeyHashBits is the default width of the key_hash attribute.
+const DefaultKeyHashBits = 10
+
+// KeyHashAttribute is the attribute carrying the truncated key hash of a
+// sampled operation.
+const KeyHashAttribute = "key_hash"
+
+// OperationAttribute is the attribute carrying the operation ("get" or
+// "set") of a sampled operation.
+const OperationAttribute = "operation"
+
+// WithKeySampling records one Get or Set in every, picked at random, on the
+// balios_sampled_latency_ns histogram with its operation and hashed key as
+// attributes. Default: 0 (disabled).
+//
+//	collector, _ := NewOTelMetricsCollector(provider, WithKeySampling(1000))
+//	...
+//	// Which key_hash is the slow one?
+//	collector.KeyHash("user:42") // e.g. "2f3"
+func WithKeySampling(every int) Option {
+	return func(o *Options) {
+		o.KeySampleEvery = every
+	}
+}
+
+// WithKeyHashBits sets the width of the key_hash attribute, bounding the
+// sampled histogram to 2^bits series per operation (clamped to [1, 64]).
+// Default: DefaultKeyHashBits.
+func WithKeyHashBits(bits int) Option {
+	return func(o *Options) {
+		o.KeyHashBits = bits
+	}
+}
+
+// newKeySampling sets the key hash width and creates the sampled latency
+// histogram when options.KeySampleEvery is positive.
+func (c *OTelMetricsCollector) newKeySampling(meter metric.Meter, options Options) error {
+	c.keyHashBits = min(max(options.KeyHashBits, 1), 64)
+	if options.KeySampleEvery <= 0 {
+		return nil
+	}
+	c.keySampleEvery = options.KeySampleEvery
+	var err error
+	c.sampledLatency, err = meter.Int64Histogram(
+		"balios_sampled_latency_ns",
+		metric.WithDescription("Latency of sampled Get and Set operations in nanoseconds, by hashed key"),
+		metric.WithUnit("ns"),
+	)
+	return err
+}
+
+// KeyHash returns the key_hash attribute value of key: its FNV-1a hash
+// truncated to the configured width, in hexadecimal.
+func (c *OTelMetricsCollector) KeyHash(key string) string {
+	return c.formatKeyHash(c.truncatedHash(key))
+}
+
+// truncatedHash returns the top keyHashBits bits of the 64-bit FNV-1a hash
+// of key, computed inline to stay allocation-free.
+func (c *OTelMetricsCollector) truncatedHash(key string) uint64 {
+	h := uint64(14695981039346656037) // FNV-1a offset basis
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211 // FNV-1a prime
+	}
+	return h >> (64 - c.keyHashBits)
+}
+
+// formatKeyHash formats a truncated hash as fixed-width hexadecimal.
+func (c *OTelMetricsCollector) formatKeyHash(hash uint64) string {
+	return fmt.Sprintf("%0*x", (c.keyHashBits+3)/4, hash)
+}
+
+// sampleAttrs identifies the attribute set of a sample.
+type sampleAttrs struct {
+	op   balios.ProbeOp
+	hash uint64
+}
+
+// attrsForSample returns the measurement attributes of a sample of op on a
+// key with the given truncated hash.
+func (c *OTelMetricsCollector) attrsForSample(op balios.ProbeOp, hash uint64) metric.MeasurementOption {
+	id := sampleAttrs{op: op, hash: hash}
+	if attrs, ok := c.sampleAttrs.Load(id); ok {
+		return attrs.(metric.MeasurementOption)
+	}
+	kvs := make([]attribute.KeyValue, 0, len(c.attributes)+2)
+	kvs = append(kvs, c.attributes...)
+	kvs = append(kvs,
+		attribute.String(OperationAttribute, op.String()),
+		attribute.String(KeyHashAttribute, c.formatKeyHash(hash)),
+	)
+	attrs, _ := c.sampleAttrs.LoadOrStore(id, metric.WithAttributeSet(attribute.NewSet(kvs...)))
+	return attrs.(metric.MeasurementOption)
+}
+
+// KeySampleEvery returns the sampling rate of WithKeySampling (0 when
+// disabled). It implements balios.KeySampleRecorder.
+func (c *OTelMetricsCollector) KeySampleEvery() int {
+	return c.keySampleEvery
+}
+
+// RecordKeySample records a sampled operation on key with its hashed key
+// (see WithKeySampling). Samples without a latency (-1) are skipped.
+//
+// Thread-safety: Safe for concurrent use.
+func (c *OTelMetricsCollector) RecordKeySample(op balios.ProbeOp, key string, latencyNs int64) {
+	if c.sampledLatency == nil || latencyNs < 0 {
+		return
+	}
+	c.sampledLatency.Record(context.Background(), latencyNs, c.attrsForSample(op, c.truncatedHash(key)))
+}
+
+// Compile-time interface check
+var _ balios.KeySampleRecorder = (*OTelMetricsCollector)(nil)
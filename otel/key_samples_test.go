@@ -0,0 +1,95 @@
+// key_samples_test.go: tests for sampled, hashed keys
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package otel
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/agilira/balios"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// sampledPoints collects the data points of balios_sampled_latency_ns.
+func sampledPoints(t *testing.T, reader metric.Reader) []metricdata.HistogramDataPoint[int64] {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "balios_sampled_latency_ns" {
+				return m.Data.(metricdata.Histogram[int64]).DataPoints
+			}
+		}
+	}
+	return nil
+}
+
+func TestOTelMetricsCollector_KeySampling(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	collector, err := NewOTelMetricsCollector(provider, WithKeySampling(1), WithKeyHashBits(4))
+	if err != nil {
+		t.Fatalf("NewOTelMetricsCollector() error = %v", err)
+	}
+	cache := balios.NewCache(balios.Config{MaxSize: 1000, MetricsCollector: collector})
+	defer cache.Close()
+
+	cache.Set("hot", 1)
+	for i := 0; i < 10; i++ {
+		cache.Get("hot")
+	}
+	for i := 0; i < 200; i++ {
+		cache.Get("key:" + strconv.Itoa(i))
+	}
+
+	points := sampledPoints(t, reader)
+	var hotGets uint64
+	for _, dp := range points {
+		op, _ := dp.Attributes.Value(OperationAttribute)
+		hash, ok := dp.Attributes.Value(KeyHashAttribute)
+		if !ok || len(hash.AsString()) != 1 {
+			t.Fatalf("expected a 1-digit key_hash attribute, got %v", dp.Attributes)
+		}
+		if op.AsString() == "get" && hash.AsString() == collector.KeyHash("hot") {
+			hotGets += dp.Count
+		}
+	}
+	if len(points) > 2*16 {
+		t.Errorf("%d series, want at most 16 per operation with 4-bit hashes", len(points))
+	}
+	if hotGets < 10 {
+		t.Errorf("expected the 10 Gets of the hot key under its key_hash, got %d", hotGets)
+	}
+}
+
+func TestOTelMetricsCollector_KeySamplingDisabled(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	collector, err := NewOTelMetricsCollector(provider)
+	if err != nil {
+		t.Fatalf("NewOTelMetricsCollector() error = %v", err)
+	}
+	if collector.KeySampleEvery() != 0 {
+		t.Fatal("key sampling must be disabled by default")
+	}
+	collector.RecordKeySample(balios.ProbeGet, "k", 100)
+	if points := sampledPoints(t, reader); len(points) != 0 {
+		t.Fatalf("expected no sampled series, got %d", len(points))
+	}
+	if got := collector.KeyHash("k"); len(got) != (DefaultKeyHashBits+3)/4 {
+		t.Fatalf("KeyHash = %q, want %d hex digits", got, (DefaultKeyHashBits+3)/4)
+	}
+}
@@ -0,0 +1,116 @@
+// shared.go: one OpenTelemetry collector for several caches
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package otel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/agilira/balios"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CacheNameAttribute is the attribute carrying balios.Config.Name on the
+// measurements of a SharedCollector.
+const CacheNameAttribute = "cache_name"
+
+// SharedCollector implements balios.MetricsCollectorV2: a single instance,
+// with a single set of instruments, serves every cache configured with it
+// through Config.MetricsCollectorV2. Measurements carry the cache name as
+// the cache_name attribute, next to the attributes of WithAttributes, and
+// are recorded with the context of the call (trace exemplars).
+//
+//	shared, _ := baliosotel.NewSharedCollector(provider)
+//	users := balios.NewCache(balios.Config{Name: "users", MetricsCollectorV2: shared})
+//	sessions := balios.NewCache(balios.Config{Name: "sessions", MetricsCollectorV2: shared})
+//
+// The attribute set of each cache name is built on its first recording and
+// reused, so recording stays allocation-free.
+//
+// Thread-safety: Safe for concurrent use.
+type SharedCollector struct {
+	instruments *OTelMetricsCollector
+	attributes  []attribute.KeyValue
+	byCache     sync.Map // cache name -> metric.MeasurementOption
+}
+
+// NewSharedCollector creates a SharedCollector. It accepts the options of
+// NewOTelMetricsCollector and returns its errors.
+func NewSharedCollector(provider metric.MeterProvider, opts ...Option) (*SharedCollector, error) {
+	instruments, err := NewOTelMetricsCollector(provider, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &SharedCollector{instruments: instruments, attributes: options.Attributes}, nil
+}
+
+// attrsFor returns the measurement attributes of the cache named name.
+func (s *SharedCollector) attrsFor(name string) metric.MeasurementOption {
+	if attrs, ok := s.byCache.Load(name); ok {
+		return attrs.(metric.MeasurementOption)
+	}
+	kvs := make([]attribute.KeyValue, 0, len(s.attributes)+1)
+	kvs = append(kvs, s.attributes...)
+	kvs = append(kvs, attribute.String(CacheNameAttribute, name))
+	attrs, _ := s.byCache.LoadOrStore(name, metric.WithAttributeSet(attribute.NewSet(kvs...)))
+	return attrs.(metric.MeasurementOption)
+}
+
+// contextOf returns the context of op, context.Background() if it has none.
+func contextOf(op balios.MetricsOp) context.Context {
+	if op.Context == nil {
+		return context.Background()
+	}
+	return op.Context
+}
+
+// RecordGet records a Get of the cache op.Cache (see OTelMetricsCollector.RecordGet).
+func (s *SharedCollector) RecordGet(op balios.MetricsOp, latencyNs int64, hit bool) {
+	ctx, attrs := contextOf(op), s.attrsFor(op.Cache)
+	if latencyNs >= 0 {
+		s.instruments.getLatency.Record(ctx, latencyNs, attrs)
+	}
+	if hit {
+		s.instruments.hits.Add(ctx, 1, attrs)
+	} else {
+		s.instruments.misses.Add(ctx, 1, attrs)
+	}
+}
+
+// RecordSet records a Set of the cache op.Cache (see OTelMetricsCollector.RecordSet).
+func (s *SharedCollector) RecordSet(op balios.MetricsOp, latencyNs int64) {
+	if latencyNs < 0 {
+		return
+	}
+	s.instruments.setLatency.Record(contextOf(op), latencyNs, s.attrsFor(op.Cache))
+}
+
+// RecordDelete records a Delete of the cache op.Cache (see OTelMetricsCollector.RecordDelete).
+func (s *SharedCollector) RecordDelete(op balios.MetricsOp, latencyNs int64) {
+	if latencyNs < 0 {
+		return
+	}
+	s.instruments.deleteLatency.Record(contextOf(op), latencyNs, s.attrsFor(op.Cache))
+}
+
+// RecordEviction records an eviction in the cache op.Cache.
+func (s *SharedCollector) RecordEviction(op balios.MetricsOp) {
+	s.instruments.evictions.Add(contextOf(op), 1, s.attrsFor(op.Cache))
+}
+
+// RecordExpiration records an expiration in the cache op.Cache.
+func (s *SharedCollector) RecordExpiration(op balios.MetricsOp) {
+	s.instruments.expirations.Add(contextOf(op), 1, s.attrsFor(op.Cache))
+}
+
+// Compile-time interface check
+var _ balios.MetricsCollectorV2 = (*SharedCollector)(nil)
@@ -0,0 +1,111 @@
+// shared_test.go: tests for SharedCollector
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/agilira/balios"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// TestSharedCollector_TagsCaches verifies that one collector serves two
+// caches, told apart by the cache_name attribute
+func TestSharedCollector_TagsCaches(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	shared, err := NewSharedCollector(provider, WithAttributes(attribute.String("service", "api")))
+	if err != nil {
+		t.Fatalf("NewSharedCollector() error = %v", err)
+	}
+	users := balios.NewCache(balios.Config{MaxSize: 100, Name: "users", MetricsCollectorV2: shared})
+	defer users.Close()
+	sessions := balios.NewCache(balios.Config{MaxSize: 100, Name: "sessions", MetricsCollectorV2: shared})
+	defer sessions.Close()
+
+	users.Set("a", 1)
+	users.Get("a")
+	users.Get("a")
+	sessions.Get("missing")
+	if _, _, err := sessions.(balios.CheckedCache).GetCtx(context.Background(), "missing"); err != nil {
+		t.Fatal(err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	counts := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				name, _ := dp.Attributes.Value(CacheNameAttribute)
+				if service, _ := dp.Attributes.Value("service"); service.AsString() != "api" {
+					t.Errorf("%s: missing the WithAttributes attribute", m.Name)
+				}
+				counts[m.Name+"/"+name.AsString()] += dp.Value
+			}
+		}
+	}
+
+	want := map[string]int64{
+		"balios_get_hits_total/users":      2,
+		"balios_get_misses_total/sessions": 2,
+	}
+	for key, value := range want {
+		if counts[key] != value {
+			t.Errorf("%s = %d, want %d (all: %v)", key, counts[key], value, counts)
+		}
+	}
+	if len(counts) != len(want) {
+		t.Errorf("unexpected series: %v", counts)
+	}
+}
+
+// TestSharedCollector_NilProvider verifies the constructor error
+func TestSharedCollector_NilProvider(t *testing.T) {
+	if _, err := NewSharedCollector(nil); err == nil {
+		t.Fatal("expected an error for a nil provider")
+	}
+}
+
+// TestSharedCollector_ZeroOp verifies that a MetricsOp without context is
+// accepted and negative latencies skip the histograms
+func TestSharedCollector_ZeroOp(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	shared, _ := NewSharedCollector(provider)
+	shared.RecordGet(balios.MetricsOp{}, -1, true)
+	shared.RecordSet(balios.MetricsOp{}, -1)
+	shared.RecordDelete(balios.MetricsOp{}, -1)
+	shared.RecordEviction(balios.MetricsOp{})
+	shared.RecordExpiration(balios.MetricsOp{})
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if _, ok := m.Data.(metricdata.Histogram[int64]); ok {
+				t.Errorf("%s recorded a negative latency", m.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,383 @@
+// persistence.go: cache snapshots for warm restarts
+//
+// SaveTo writes a snapshot of the live entries and LoadFrom restores it.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// Snapshot format (version 1), integers in big endian or (u)varint:
+//
+//	magic "BLOS" | version uint16 | savedAt int64
+//	record*: 0x01 | key | source | remaining TTL varint (0 = none) | value
+//	0x00 | record count uvarint | CRC-32C uint32 of all preceding bytes
+//
+// where key, source and value are uvarint-length-prefixed byte strings and
+// value is produced by Config.Codec.
+const (
+	snapshotMagic   = "BLOS"
+	snapshotVersion = 1
+
+	snapshotRecord = 0x01
+	snapshotEnd    = 0x00
+)
+
+// snapshotTable is the CRC-32C table used by snapshots.
+var snapshotTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Codec encodes cached values for SaveTo and LoadFrom.
+type Codec interface {
+	// Marshal encodes a cached value.
+	Marshal(value interface{}) ([]byte, error)
+
+	// Unmarshal decodes a value produced by Marshal.
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// GobCodec encodes values with encoding/gob. Values of types other than the
+// built-in ones must be registered with gob.Register before saving and
+// loading. It is the default Config.Codec.
+type GobCodec struct{}
+
+// Marshal encodes value with gob.
+func (GobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a gob-encoded value.
+func (GobCodec) Unmarshal(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// snapshotEntry is one decoded snapshot record.
+type snapshotEntry struct {
+	key       string
+	source    string
+	remaining int64
+	value     interface{}
+}
+
+// SaveTo writes a snapshot of the live entries to w and returns the number
+// of entries written. Values are encoded with Config.Codec.
+func (c *wtinyLFUCache) SaveTo(w io.Writer) (int, error) {
+	return c.saveTo(w, "")
+}
+
+// LoadFrom restores a snapshot written by SaveTo and returns the number of
+// entries stored. Entries expired in the meantime are skipped. Nothing is
+// stored if the snapshot is corrupted.
+func (c *wtinyLFUCache) LoadFrom(r io.Reader) (int, error) {
+	return c.loadFrom(r, "")
+}
+
+// SaveToFile writes a snapshot to path, replacing it atomically.
+func (c *wtinyLFUCache) SaveToFile(path string) (int, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return 0, NewErrSaveFailed(path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // No-op after the rename
+
+	n, err := c.saveTo(tmp, path)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = NewErrSaveFailed(path, closeErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, NewErrSaveFailed(path, err)
+	}
+	return n, nil
+}
+
+// LoadFromFile restores a snapshot written by SaveToFile.
+func (c *wtinyLFUCache) LoadFromFile(path string) (int, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is provided by the application
+	if err != nil {
+		return 0, NewErrLoadFailed(path, err)
+	}
+	defer func() { _ = f.Close() }()
+	return c.loadFrom(f, path)
+}
+
+func (c *wtinyLFUCache) saveTo(w io.Writer, path string) (int, error) {
+	if c.isClosed() {
+		return 0, NewErrCacheClosed("SaveTo")
+	}
+	t := c.table.Load()
+	if t == nil {
+		return 0, NewErrCacheClosed("SaveTo") // Closed
+	}
+	crc := crc32.New(snapshotTable)
+	bw := bufio.NewWriter(io.MultiWriter(w, crc))
+
+	now := c.timeProvider.Now()
+	ttlNow := c.ttlClock(now)
+
+	var header [14]byte
+	copy(header[:4], snapshotMagic)
+	binary.BigEndian.PutUint16(header[4:6], snapshotVersion)
+	binary.BigEndian.PutUint64(header[6:14], uint64(now)) // #nosec G115 -- round-tripped as int64
+	_, _ = bw.Write(header[:])                            // bufio errors are sticky, checked on Flush
+
+	var scratch [binary.MaxVarintLen64]byte
+	writeBytes := func(b []byte) {
+		_, _ = bw.Write(scratch[:binary.PutUvarint(scratch[:], uint64(len(b)))])
+		_, _ = bw.Write(b)
+	}
+
+	count := 0
+	for i := range t.entries {
+		e, ok := c.snapshotOf(&t.entries[i], ttlNow)
+		if !ok {
+			continue
+		}
+		data, err := c.codec.Marshal(e.value)
+		if err != nil {
+			return 0, NewErrSaveFailed(path, fmt.Errorf("encoding value of key %q: %w", e.key, err))
+		}
+		_ = bw.WriteByte(snapshotRecord)
+		writeBytes([]byte(e.key))
+		writeBytes([]byte(e.source))
+		_, _ = bw.Write(scratch[:binary.PutVarint(scratch[:], e.remaining)])
+		writeBytes(data)
+		count++
+	}
+
+	_ = bw.WriteByte(snapshotEnd)
+	_, _ = bw.Write(scratch[:binary.PutUvarint(scratch[:], uint64(count))])
+	if err := bw.Flush(); err != nil {
+		return 0, NewErrSaveFailed(path, err)
+	}
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	if _, err := w.Write(sum[:]); err != nil {
+		return 0, NewErrSaveFailed(path, err)
+	}
+	return count, nil
+}
+
+// snapshotOf reads a live entry. ok is false if the slot is not valid, has
+// expired, or was rewritten while being read.
+func (c *wtinyLFUCache) snapshotOf(entry *entry, ttlNow int64) (snapshotEntry, bool) {
+	version := atomic.LoadUint64(&entry.version)
+	if !c.isLive(entry) || c.isExpired(entry, ttlNow) {
+		return snapshotEntry{}, false
+	}
+	e := snapshotEntry{key: entry.loadKey()}
+	if holder, ok := entry.value.Load().(*valueHolder); ok && holder != nil {
+		e.value = holder.load()
+		e.source = holder.source()
+	}
+	if expireAt := atomic.LoadInt64(&entry.expireAt); expireAt > 0 {
+		e.remaining = expireAt - ttlNow
+	}
+	if e.key == "" || !c.isLive(entry) ||
+		atomic.LoadUint64(&entry.version) != version || e.remaining < 0 {
+		return snapshotEntry{}, false
+	}
+	if c.valueCodec != nil {
+		var ok bool
+		if e.value, ok = c.decoded(e.value); !ok {
+			return snapshotEntry{}, false
+		}
+	}
+	return e, true
+}
+
+func (c *wtinyLFUCache) loadFrom(r io.Reader, path string) (int, error) {
+	if c.isClosed() {
+		return 0, NewErrCacheClosed("LoadFrom")
+	}
+	entries, savedAt, err := c.readSnapshot(bufio.NewReader(r), path)
+	if err != nil {
+		return 0, err
+	}
+
+	elapsed := c.timeProvider.Now() - savedAt
+	if elapsed < 0 {
+		elapsed = 0 // Clock went backwards: keep the saved TTLs
+	}
+	loaded := 0
+	for _, e := range entries {
+		ttlNanos := e.remaining
+		if ttlNanos > 0 {
+			if ttlNanos -= elapsed; ttlNanos <= 0 {
+				continue // Expired while on disk
+			}
+			c.raiseMaxTTL(ttlNanos)
+		}
+		if c.set(e.key, e.value, truncateSource(e.source), ttlNanos) {
+			loaded++
+		}
+	}
+	return loaded, nil
+}
+
+// crcReader hashes the bytes read through it.
+type crcReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+}
+
+func (cr *crcReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	_, _ = cr.crc.Write(p[:n])
+	return n, err
+}
+
+func (cr *crcReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		_, _ = cr.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+// readSnapshot decodes and validates a whole snapshot.
+func (c *wtinyLFUCache) readSnapshot(br *bufio.Reader, path string) ([]snapshotEntry, int64, error) {
+	cr := &crcReader{r: br, crc: crc32.New(snapshotTable)}
+	corrupted := func(details string) error { return NewErrCorruptedData(path, details) }
+	// readErr maps a read failure: a premature EOF means a truncated snapshot
+	readErr := func(err error) error {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return corrupted("truncated snapshot")
+		}
+		return NewErrLoadFailed(path, err)
+	}
+
+	var header [14]byte
+	if _, err := io.ReadFull(cr, header[:]); err != nil {
+		return nil, 0, readErr(err)
+	}
+	if string(header[:4]) != snapshotMagic {
+		return nil, 0, corrupted("not a balios snapshot")
+	}
+	if v := binary.BigEndian.Uint16(header[4:6]); v != snapshotVersion {
+		return nil, 0, corrupted(fmt.Sprintf("unsupported snapshot version %d", v))
+	}
+	savedAt := int64(binary.BigEndian.Uint64(header[6:14])) // #nosec G115 -- written from an int64
+
+	readBytes := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, cr, int64(n)); err != nil { // #nosec G115 -- bounded by the stream
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var records []snapshotEntry
+	var encoded [][]byte
+	for {
+		tag, err := cr.ReadByte()
+		if err != nil {
+			return nil, 0, readErr(err)
+		}
+		if tag == snapshotEnd {
+			break
+		}
+		if tag != snapshotRecord {
+			return nil, 0, corrupted(fmt.Sprintf("unknown record tag %#x", tag))
+		}
+
+		key, err := readBytes()
+		if err != nil {
+			return nil, 0, readErr(err)
+		}
+		source, err := readBytes()
+		if err != nil {
+			return nil, 0, readErr(err)
+		}
+		remaining, err := binary.ReadVarint(cr)
+		if err != nil {
+			return nil, 0, readErr(err)
+		}
+		data, err := readBytes()
+		if err != nil {
+			return nil, 0, readErr(err)
+		}
+		if len(key) == 0 || remaining < 0 {
+			return nil, 0, corrupted("invalid record")
+		}
+		records = append(records, snapshotEntry{key: string(key), source: string(source), remaining: remaining})
+		encoded = append(encoded, data)
+	}
+
+	count, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return nil, 0, readErr(err)
+	}
+	if count != uint64(len(records)) {
+		return nil, 0, corrupted(fmt.Sprintf("record count mismatch: trailer says %d, found %d", count, len(records)))
+	}
+	var sum [4]byte
+	if _, err := io.ReadFull(br, sum[:]); err != nil {
+		return nil, 0, readErr(err)
+	}
+	if binary.BigEndian.Uint32(sum[:]) != cr.crc.Sum32() {
+		return nil, 0, corrupted("checksum mismatch")
+	}
+
+	// Decode values only once the snapshot is known to be intact
+	for i, data := range encoded {
+		value, err := c.codec.Unmarshal(data)
+		if err != nil {
+			return nil, 0, corrupted(fmt.Sprintf("decoding value of key %q: %v", records[i].key, err))
+		}
+		records[i].value = value
+	}
+	return records, savedAt, nil
+}
+
+// SaveTo writes a snapshot of the live entries to w.
+func (c *GenericCache[K, V]) SaveTo(w io.Writer) (int, error) {
+	return c.inner.SaveTo(w)
+}
+
+// LoadFrom restores a snapshot written by SaveTo.
+func (c *GenericCache[K, V]) LoadFrom(r io.Reader) (int, error) {
+	return c.inner.LoadFrom(r)
+}
+
+// SaveToFile writes a snapshot to path, replacing it atomically.
+func (c *GenericCache[K, V]) SaveToFile(path string) (int, error) {
+	return c.inner.SaveToFile(path)
+}
+
+// LoadFromFile restores a snapshot written by SaveToFile.
+func (c *GenericCache[K, V]) LoadFromFile(path string) (int, error) {
+	return c.inner.LoadFromFile(path)
+}
@@ -0,0 +1,162 @@
+// persistence_test.go: tests for snapshot save and load
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPersistence_RoundTrip(t *testing.T) {
+	src := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	src.Set("a", "alpha")
+	src.Set("b", 42)
+	src.SetWithSource("c", []byte("raw"), "warmup")
+
+	var buf bytes.Buffer
+	n, err := src.SaveTo(&buf)
+	if err != nil || n != 3 {
+		t.Fatalf("SaveTo = %d, %v", n, err)
+	}
+
+	dst := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	n, err = dst.LoadFrom(&buf)
+	if err != nil || n != 3 {
+		t.Fatalf("LoadFrom = %d, %v", n, err)
+	}
+	if v, ok := dst.Get("a"); !ok || v != "alpha" {
+		t.Errorf("a = %v, %v", v, ok)
+	}
+	if v, ok := dst.Get("b"); !ok || v != 42 {
+		t.Errorf("b = %v, %v", v, ok)
+	}
+	if v, ok := dst.Get("c"); !ok || string(v.([]byte)) != "raw" {
+		t.Errorf("c = %v, %v", v, ok)
+	}
+	if s, ok := dst.SourceOf("c"); !ok || s != "warmup" {
+		t.Errorf("source of c = %q, %v", s, ok)
+	}
+}
+
+func TestPersistence_TTLPreserved(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	src := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	src.Set("short", 1)
+	mockTime.Advance(6 * time.Second)
+	src.Set("long", 2)
+
+	var buf bytes.Buffer
+	if _, err := src.SaveTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two seconds on disk: "short" has 2s left, "long" 8s
+	mockTime.Advance(2 * time.Second)
+	dst := NewCache(Config{MaxSize: 100, TimeProvider: mockTime}).(*wtinyLFUCache)
+	if n, err := dst.LoadFrom(&buf); err != nil || n != 2 {
+		t.Fatalf("LoadFrom = %d, %v", n, err)
+	}
+
+	mockTime.Advance(3 * time.Second)
+	if _, ok := dst.Get("short"); ok {
+		t.Error("short must expire when it would have in the saving cache")
+	}
+	if _, ok := dst.Get("long"); !ok {
+		t.Error("long must still be live")
+	}
+}
+
+func TestPersistence_ExpiredOnDisk(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	src := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	src.Set("k", 1)
+
+	var buf bytes.Buffer
+	if _, err := src.SaveTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	mockTime.Advance(time.Minute)
+
+	dst := NewCache(Config{MaxSize: 100, TimeProvider: mockTime}).(*wtinyLFUCache)
+	if n, err := dst.LoadFrom(&buf); err != nil || n != 0 {
+		t.Errorf("expected no entries, got %d, %v", n, err)
+	}
+}
+
+func TestPersistence_Corrupted(t *testing.T) {
+	src := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	for i := 0; i < 10; i++ {
+		src.Set(string(rune('a'+i)), i)
+	}
+	var buf bytes.Buffer
+	if _, err := src.SaveTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	cases := map[string][]byte{
+		"empty":     nil,
+		"bad magic": append([]byte("XXXX"), data[4:]...),
+		"truncated": data[:len(data)/2],
+		"flipped":   flipByte(data, len(data)/2),
+		"checksum":  flipByte(data, len(data)-1),
+	}
+	for name, snapshot := range cases {
+		t.Run(name, func(t *testing.T) {
+			dst := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+			n, err := dst.LoadFrom(bytes.NewReader(snapshot))
+			if n != 0 || GetErrorCode(err) != ErrCodeCorruptedData {
+				t.Errorf("expected %s, got %d, %v", ErrCodeCorruptedData, n, err)
+			}
+			if dst.Len() != 0 {
+				t.Errorf("a corrupted snapshot must store nothing, got %d entries", dst.Len())
+			}
+		})
+	}
+}
+
+func flipByte(data []byte, i int) []byte {
+	out := append([]byte(nil), data...)
+	out[i] ^= 0xFF
+	return out
+}
+
+func TestPersistence_UnencodableValue(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("fn", func() {})
+
+	_, err := cache.SaveTo(&bytes.Buffer{})
+	if GetErrorCode(err) != ErrCodeSaveFailed {
+		t.Errorf("expected %s, got %v", ErrCodeSaveFailed, err)
+	}
+}
+
+func TestPersistence_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	src := NewGenericCache[string, int](Config{MaxSize: 100})
+	src.Set("x", 1)
+	src.Set("y", 2)
+	if n, err := src.SaveToFile(path); err != nil || n != 2 {
+		t.Fatalf("SaveToFile = %d, %v", n, err)
+	}
+
+	dst := NewGenericCache[string, int](Config{MaxSize: 100})
+	if n, err := dst.LoadFromFile(path); err != nil || n != 2 {
+		t.Fatalf("LoadFromFile = %d, %v", n, err)
+	}
+	if v, ok := dst.Get("y"); !ok || v != 2 {
+		t.Errorf("y = %v, %v", v, ok)
+	}
+
+	_, err := dst.LoadFromFile(filepath.Join(t.TempDir(), "missing"))
+	if GetErrorCode(err) != ErrCodeLoadFailed {
+		t.Errorf("expected %s, got %v", ErrCodeLoadFailed, err)
+	}
+}
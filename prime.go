@@ -0,0 +1,174 @@
+// prime.go: startup priming of the in-process cache from a secondary store
+//
+// PrimeFromSecondary bulk-loads the hottest keys of a secondary store
+// before traffic arrives, so a fresh process does not start cold.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultPrimeConcurrency is the default number of parallel secondary reads.
+const DefaultPrimeConcurrency = 8
+
+// SecondaryReader is the read side of a secondary (L2) store.
+type SecondaryReader interface {
+	// Get returns the value stored for key. found is false for missing keys;
+	// err is reserved for store failures.
+	Get(ctx context.Context, key string) (value interface{}, found bool, err error)
+}
+
+// HotKeyRanker is implemented by secondary stores able to rank keys by
+// popularity (e.g. from access counters or an LFU policy of their own).
+type HotKeyRanker interface {
+	// HotKeys returns up to limit keys, hottest first.
+	HotKeys(ctx context.Context, limit int) ([]string, error)
+}
+
+// PrimeOptions configures PrimeFromSecondary.
+type PrimeOptions struct {
+	// MaxEntries bounds the number of keys loaded. Default: cache capacity.
+	MaxEntries int
+
+	// Keys is an explicit list of keys to load, hottest first. When empty,
+	// the secondary store must implement HotKeyRanker.
+	Keys []string
+
+	// Concurrency is the number of parallel secondary reads.
+	// Default: DefaultPrimeConcurrency.
+	Concurrency int
+}
+
+// PrimeResult reports the outcome of a priming run.
+type PrimeResult struct {
+	Requested int // Keys requested from the secondary store
+	Loaded    int // Keys stored in the cache
+	Missing   int // Keys not found in the secondary store
+	Failed    int // Keys whose read failed or whose value was rejected
+}
+
+// PrimeFromSecondary loads the hottest keys of src into c, typically at
+// startup before serving traffic.
+//
+// Keys come from opts.Keys or, when empty, from src's HotKeyRanker. Reads run
+// with opts.Concurrency workers. Individual read failures are counted in
+// PrimeResult.Failed and do not abort priming; the first one is returned as a
+// BALIOS_PRIME_FAILED error alongside the (partial) result. Cancelling ctx
+// stops priming early and returns ctx.Err().
+//
+// Example:
+//
+//	res, err := balios.PrimeFromSecondary(ctx, cache, redisStore, balios.PrimeOptions{MaxEntries: 50_000})
+//	if err != nil {
+//	    log.Printf("cache primed partially (%d/%d): %v", res.Loaded, res.Requested, err)
+//	}
+func PrimeFromSecondary(ctx context.Context, c Backend, src SecondaryReader, opts PrimeOptions) (PrimeResult, error) {
+	return primeFromSecondary(ctx, src, opts, c.Capacity(), c.Set)
+}
+
+// PrimeFromSecondary loads the hottest keys of src into the cache.
+// Values of a type other than V are counted as failed and skipped.
+// See the package-level PrimeFromSecondary for details.
+func (c *GenericCache[K, V]) PrimeFromSecondary(ctx context.Context, src SecondaryReader, opts PrimeOptions) (PrimeResult, error) {
+	return primeFromSecondary(ctx, src, opts, c.inner.Capacity(), func(key string, value interface{}) bool {
+		if _, ok := value.(V); !ok {
+			return false
+		}
+		return c.inner.Set(key, value)
+	})
+}
+
+func primeFromSecondary(ctx context.Context, src SecondaryReader, opts PrimeOptions, capacity int, set func(string, interface{}) bool) (PrimeResult, error) {
+	var result PrimeResult
+	if src == nil {
+		return result, NewErrPrimeFailed("source", errors.New("secondary store is nil"))
+	}
+
+	limit := opts.MaxEntries
+	if limit <= 0 || limit > capacity {
+		limit = capacity
+	}
+
+	keys := opts.Keys
+	if len(keys) == 0 {
+		ranker, ok := src.(HotKeyRanker)
+		if !ok {
+			return result, NewErrPrimeFailed("ranking", errors.New("no keys given and secondary store does not implement HotKeyRanker"))
+		}
+		ranked, err := ranker.HotKeys(ctx, limit)
+		if err != nil {
+			return result, NewErrPrimeFailed("ranking", err)
+		}
+		keys = ranked
+	}
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultPrimeConcurrency
+	}
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+
+	var (
+		loaded, missing, failed, requested int64
+		firstErr                           error
+		errOnce                            sync.Once
+		next                               int64 = -1
+		wg                                 sync.WaitGroup
+	)
+
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := atomic.AddInt64(&next, 1)
+				if i >= int64(len(keys)) {
+					return
+				}
+				key := keys[i]
+				atomic.AddInt64(&requested, 1)
+
+				value, found, err := src.Get(ctx, key)
+				switch {
+				case err != nil:
+					if ctx.Err() != nil {
+						return
+					}
+					atomic.AddInt64(&failed, 1)
+					errOnce.Do(func() { firstErr = NewErrPrimeFailed("read", err) })
+				case !found:
+					atomic.AddInt64(&missing, 1)
+				case set(key, value):
+					atomic.AddInt64(&loaded, 1)
+				default:
+					atomic.AddInt64(&failed, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	result = PrimeResult{
+		Requested: int(requested),
+		Loaded:    int(loaded),
+		Missing:   int(missing),
+		Failed:    int(failed),
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, firstErr
+}
@@ -0,0 +1,137 @@
+// prime_test.go: tests for startup priming from a secondary store
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeSecondary is an in-memory secondary store with popularity ranking.
+type fakeSecondary struct {
+	mu      sync.Mutex
+	data    map[string]interface{}
+	ranking []string
+	failKey string
+	reads   int
+}
+
+func newFakeSecondary(n int) *fakeSecondary {
+	s := &fakeSecondary{data: map[string]interface{}{}}
+	for i := 0; i < n; i++ {
+		key := "k" + strconv.Itoa(i)
+		s.data[key] = i
+		s.ranking = append(s.ranking, key)
+	}
+	return s
+}
+
+func (s *fakeSecondary) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	if key == s.failKey {
+		return nil, false, errors.New("connection reset")
+	}
+	v, ok := s.data[key]
+	return v, ok, nil
+}
+
+func (s *fakeSecondary) HotKeys(ctx context.Context, limit int) ([]string, error) {
+	if limit > len(s.ranking) {
+		limit = len(s.ranking)
+	}
+	return s.ranking[:limit], nil
+}
+
+// unrankedSecondary hides HotKeys.
+type unrankedSecondary struct{ SecondaryReader }
+
+func TestPrimeFromSecondary_Ranked(t *testing.T) {
+	src := newFakeSecondary(500)
+	cache := NewCache(Config{MaxSize: 1000})
+
+	res, err := PrimeFromSecondary(context.Background(), cache, src, PrimeOptions{MaxEntries: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Requested != 100 || res.Loaded != 100 || res.Missing != 0 || res.Failed != 0 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	// Hottest keys are loaded first
+	if v, ok := cache.Get("k0"); !ok || v != 0 {
+		t.Errorf("hottest key not primed: %v, %v", v, ok)
+	}
+	if cache.Has("k100") {
+		t.Error("keys beyond MaxEntries must not be primed")
+	}
+}
+
+func TestPrimeFromSecondary_DefaultsToCapacity(t *testing.T) {
+	src := newFakeSecondary(500)
+	cache := NewCache(Config{MaxSize: 50})
+
+	res, err := PrimeFromSecondary(context.Background(), cache, src, PrimeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Requested != 50 {
+		t.Errorf("expected priming bounded by capacity, got %+v", res)
+	}
+}
+
+func TestPrimeFromSecondary_KeyListAndFailures(t *testing.T) {
+	src := newFakeSecondary(10)
+	src.failKey = "k3"
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+
+	res, err := cache.PrimeFromSecondary(context.Background(), unrankedSecondary{src}, PrimeOptions{
+		Keys:        []string{"k1", "k3", "missing", "k5"},
+		Concurrency: 2,
+	})
+	if GetErrorCode(err) != ErrCodePrimeFailed || !IsRetryable(err) {
+		t.Errorf("expected retryable %s, got %v", ErrCodePrimeFailed, err)
+	}
+	if res.Loaded != 2 || res.Missing != 1 || res.Failed != 1 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if v, ok := cache.Get("k5"); !ok || v != 5 {
+		t.Errorf("k5 not primed: %v, %v", v, ok)
+	}
+}
+
+func TestPrimeFromSecondary_Errors(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+	ctx := context.Background()
+
+	if _, err := PrimeFromSecondary(ctx, cache, nil, PrimeOptions{}); GetErrorCode(err) != ErrCodePrimeFailed {
+		t.Errorf("expected error for nil source, got %v", err)
+	}
+	if _, err := PrimeFromSecondary(ctx, cache, unrankedSecondary{newFakeSecondary(1)}, PrimeOptions{}); GetErrorCode(err) != ErrCodePrimeFailed {
+		t.Errorf("expected error without ranking or keys, got %v", err)
+	}
+
+	// Generic caches skip values of the wrong type
+	typed := NewGenericCache[string, string](Config{MaxSize: 100})
+	res, err := typed.PrimeFromSecondary(ctx, newFakeSecondary(3), PrimeOptions{})
+	if err != nil || res.Failed != 3 || typed.Has("k0") {
+		t.Errorf("mistyped values must be skipped: %+v, %v", res, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	src := newFakeSecondary(100)
+	if _, err := PrimeFromSecondary(cancelled, cache, src, PrimeOptions{Keys: src.ranking}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if src.reads != 0 {
+		t.Errorf("no reads expected after cancellation, got %d", src.reads)
+	}
+}
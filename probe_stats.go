@@ -0,0 +1,115 @@
+// probe_stats.go: probe-length statistics and load-factor reporting
+//
+// With Config.TrackProbeLengths the cache counts the slots examined by each
+// Get, Set and Delete; LoadFactor is always reported.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// ProbeOp identifies the operation of a probe count.
+type ProbeOp int
+
+const (
+	// ProbeGet is a Get-like lookup.
+	ProbeGet ProbeOp = iota
+
+	// ProbeSet is a write.
+	ProbeSet
+
+	// ProbeDelete is a Delete.
+	ProbeDelete
+)
+
+// String returns the operation name.
+func (op ProbeOp) String() string {
+	switch op {
+	case ProbeGet:
+		return "get"
+	case ProbeSet:
+		return "set"
+	case ProbeDelete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// ProbeCountRecorder is an optional MetricsCollector extension.
+// Collectors implementing it receive the number of table slots examined by
+// every Get, Set and Delete (see Config.TrackProbeLengths).
+type ProbeCountRecorder interface {
+	// RecordProbeCount records that op examined probes slots.
+	RecordProbeCount(op ProbeOp, probes int)
+}
+
+// probeStats holds the probe-length counters.
+type probeStats struct {
+	ops   int64 // Operations counted
+	total int64 // Slots examined by them
+	max   int64 // Longest probe sequence seen
+}
+
+// recordProbes accounts an operation that examined probes slots. It is a
+// no-op unless probe lengths are tracked.
+func (c *wtinyLFUCache) recordProbes(op ProbeOp, probes uint32) {
+	if !c.trackProbes {
+		return
+	}
+	c.countProbes(op, int64(probes))
+}
+
+// countProbes implements recordProbes.
+func (c *wtinyLFUCache) countProbes(op ProbeOp, probes int64) {
+	atomic.AddInt64(&c.probes.ops, 1)
+	atomic.AddInt64(&c.probes.total, probes)
+	for {
+		current := atomic.LoadInt64(&c.probes.max)
+		if probes <= current || atomic.CompareAndSwapInt64(&c.probes.max, current, probes) {
+			break
+		}
+	}
+	if c.probeRecorder != nil {
+		c.probeRecorder.RecordProbeCount(op, int(probes))
+	}
+}
+
+// resetProbes resets the probe-length counters (see Clear).
+func (c *wtinyLFUCache) resetProbes() {
+	atomic.StoreInt64(&c.probes.ops, 0)
+	atomic.StoreInt64(&c.probes.total, 0)
+	atomic.StoreInt64(&c.probes.max, 0)
+}
+
+// readProbes fills the probe-length and load-factor fields of stats.
+func (c *wtinyLFUCache) readProbes(stats *CacheStats) {
+	if t := c.table.Load(); t != nil {
+		stats.LoadFactor = float64(stats.Size) / float64(uint64(t.mask)+1)
+	}
+	if ops := atomic.LoadInt64(&c.probes.ops); ops > 0 {
+		stats.AvgProbeLength = float64(atomic.LoadInt64(&c.probes.total)) / float64(ops)
+	}
+	stats.MaxProbeLength = int(atomic.LoadInt64(&c.probes.max))
+}
+
+// probeRecorderOf returns the ProbeCountRecorder of a collector built by
+// newGuardedMetricsCollector, or nil when the collector does not implement it.
+func probeRecorderOf(collector MetricsCollector) ProbeCountRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.probeCount != nil {
+		return g
+	}
+	return nil
+}
+
+// RecordProbeCount forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordProbeCount(op ProbeOp, probes int) {
+	if g.probeCount == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordProbeCount")
+	g.probeCount.RecordProbeCount(op, probes)
+}
@@ -0,0 +1,110 @@
+// probe_stats_test.go: tests for Config.TrackProbeLengths and ProbeCountRecorder
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// probeCountCollector records probe counts per operation.
+type probeCountCollector struct {
+	NoOpMetricsCollector
+	mu     sync.Mutex
+	counts map[ProbeOp][]int
+}
+
+func (p *probeCountCollector) RecordProbeCount(op ProbeOp, probes int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.counts == nil {
+		p.counts = make(map[ProbeOp][]int)
+	}
+	p.counts[op] = append(p.counts[op], probes)
+}
+
+func TestProbeStats_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100})
+	defer cache.Close()
+
+	for i := 0; i < 50; i++ {
+		cache.Set("k"+strconv.Itoa(i), i)
+		cache.Get("k" + strconv.Itoa(i))
+	}
+	stats := cache.Stats()
+	if stats.AvgProbeLength != 0 || stats.MaxProbeLength != 0 {
+		t.Fatalf("probe lengths tracked without TrackProbeLengths: %+v", stats)
+	}
+	if stats.LoadFactor <= 0 || stats.LoadFactor > 0.5 {
+		t.Fatalf("LoadFactor = %v, want (0, 0.5]", stats.LoadFactor)
+	}
+}
+
+func TestProbeStats_Tracked(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, TrackProbeLengths: true})
+	defer cache.Close()
+
+	for i := 0; i < 1000; i++ {
+		cache.Set("k"+strconv.Itoa(i), i)
+	}
+	for i := 0; i < 1000; i++ {
+		cache.Get("k" + strconv.Itoa(i))
+	}
+	cache.Delete("k0")
+
+	stats := cache.Stats()
+	if stats.AvgProbeLength < 1 {
+		t.Fatalf("AvgProbeLength = %v, want >= 1", stats.AvgProbeLength)
+	}
+	if stats.MaxProbeLength < 1 || stats.MaxProbeLength > int(maxProbeLength)+1 {
+		t.Fatalf("MaxProbeLength = %d out of range", stats.MaxProbeLength)
+	}
+	if float64(stats.MaxProbeLength) < stats.AvgProbeLength {
+		t.Fatalf("MaxProbeLength %d below average %v", stats.MaxProbeLength, stats.AvgProbeLength)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats.AvgProbeLength != 0 || stats.MaxProbeLength != 0 || stats.LoadFactor != 0 {
+		t.Fatalf("Clear did not reset the probe statistics: %+v", stats)
+	}
+}
+
+func TestProbeStats_Recorder(t *testing.T) {
+	collector := &probeCountCollector{}
+	cache := NewCache(Config{MaxSize: 100, MetricsCollector: collector})
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("missing")
+	cache.Delete("a")
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.counts[ProbeSet]) != 1 || len(collector.counts[ProbeGet]) != 2 || len(collector.counts[ProbeDelete]) != 1 {
+		t.Fatalf("unexpected recordings %v", collector.counts)
+	}
+	for op, counts := range collector.counts {
+		for _, n := range counts {
+			if n < 1 {
+				t.Fatalf("%v recorded %d probes", op, n)
+			}
+		}
+	}
+	if stats := cache.Stats(); stats.AvgProbeLength == 0 {
+		t.Fatal("a ProbeCountRecorder should enable the probe statistics")
+	}
+}
+
+func TestProbeOp_String(t *testing.T) {
+	for op, want := range map[ProbeOp]string{ProbeGet: "get", ProbeSet: "set", ProbeDelete: "delete", ProbeOp(9): "unknown"} {
+		if got := op.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", int(op), got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// range.go: iteration over live entries
+//
+// Range visits the live entries in table order without copying the table.
+// It is not a snapshot and does not count as an access.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+// Range calls f for each live entry until f returns false.
+func (c *wtinyLFUCache) Range(f func(key string, value interface{}) bool) {
+	if c.isClosed() {
+		return
+	}
+	t := c.table.Load()
+	if t == nil {
+		return // Closed
+	}
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	for i := range t.entries {
+		e, ok := c.snapshotOf(&t.entries[i], ttlNow)
+		if !ok {
+			continue
+		}
+		if !f(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// Range calls f for each live entry until f returns false. Entries whose key
+// cannot be converted back to K (see keyFromString) or whose value is not a V
+// are skipped.
+func (c *GenericCache[K, V]) Range(f func(key K, value V) bool) {
+	c.inner.Range(func(keyStr string, value interface{}) bool {
+		key, ok := keyFromString[K](keyStr)
+		if !ok {
+			return true
+		}
+		typed, ok := value.(V)
+		if !ok {
+			return true
+		}
+		return f(key, typed)
+	})
+}
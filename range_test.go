@@ -0,0 +1,162 @@
+// range_test.go: tests for Range
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRange_LiveEntries(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("old", 0)
+	mockTime.Advance(2 * time.Second)
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	cache.Delete("k3")
+
+	seen := make(map[string]interface{})
+	cache.Range(func(key string, value interface{}) bool {
+		if _, dup := seen[key]; dup {
+			t.Errorf("key %s visited twice", key)
+		}
+		seen[key] = value
+		return true
+	})
+	if len(seen) != 9 {
+		t.Errorf("expected 9 live entries, got %d: %v", len(seen), seen)
+	}
+	if _, ok := seen["old"]; ok {
+		t.Error("expired entries must not be visited")
+	}
+	if _, ok := seen["k3"]; ok {
+		t.Error("deleted entries must not be visited")
+	}
+	if seen["k7"] != 7 {
+		t.Errorf("k7 = %v", seen["k7"])
+	}
+}
+
+func TestRange_Stop(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	calls := 0
+	cache.Range(func(string, interface{}) bool {
+		calls++
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Errorf("Range must stop when f returns false, got %d calls", calls)
+	}
+}
+
+func TestRange_DeleteDuringIteration(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	hits := cache.Stats().Hits
+
+	cache.Range(func(key string, value interface{}) bool {
+		if value.(int)%2 == 0 {
+			cache.Delete(key)
+		}
+		return true
+	})
+	if cache.Len() != 10 {
+		t.Errorf("expected 10 entries left, got %d", cache.Len())
+	}
+	if cache.Stats().Hits != hits {
+		t.Error("Range must not count as hits")
+	}
+}
+
+func TestRange_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				key := fmt.Sprintf("w%d-%d", w, i%200)
+				cache.Set(key, key)
+				if i%3 == 0 {
+					cache.Delete(key)
+				}
+			}
+		}(w)
+	}
+
+	for i := 0; i < 50; i++ {
+		cache.Range(func(key string, value interface{}) bool {
+			if value != key {
+				t.Errorf("torn pair: %s -> %v", key, value)
+				return false
+			}
+			return true
+		})
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRange_Generic(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 100})
+	for i := -5; i < 5; i++ {
+		cache.Set(i, fmt.Sprint(i))
+	}
+	sum := 0
+	cache.Range(func(key int, value string) bool {
+		if fmt.Sprint(key) != value {
+			t.Errorf("%d -> %s", key, value)
+		}
+		sum += key
+		return true
+	})
+	if sum != -5 {
+		t.Errorf("expected all keys visited (sum -5), got %d", sum)
+	}
+
+	type point struct{ X, Y int }
+	structs := NewGenericCache[point, int](Config{MaxSize: 100})
+	structs.Set(point{1, 2}, 3)
+	structs.Range(func(point, int) bool {
+		t.Error("keys that cannot be parsed back must be skipped")
+		return true
+	})
+}
+
+func TestKeyFromString(t *testing.T) {
+	if k, ok := keyFromString[uint8]("255"); !ok || k != 255 {
+		t.Errorf("uint8 255 = %v, %v", k, ok)
+	}
+	if _, ok := keyFromString[uint8]("256"); ok {
+		t.Error("out of range values must be rejected")
+	}
+	if k, ok := keyFromString[int64](keyToString(int64(-1 << 63))); !ok || k != -1<<63 {
+		t.Errorf("int64 round trip = %v, %v", k, ok)
+	}
+	if k, ok := keyFromString[string]("a:b"); !ok || k != "a:b" {
+		t.Errorf("string = %v, %v", k, ok)
+	}
+	if _, ok := keyFromString[float64]("1.5"); ok {
+		t.Error("unsupported key types must be rejected")
+	}
+}
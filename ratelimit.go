@@ -0,0 +1,129 @@
+// ratelimit.go: per-key-family rate limiting of loader calls
+//
+// Config.LoadRateLimit caps loader calls per key family; excess callers
+// receive BALIOS_LOAD_RATE_LIMITED.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// loadLimiter rate limits loader calls per key family.
+type loadLimiter struct {
+	interval   int64 // Nanoseconds between tokens (1s / rate)
+	tolerance  int64 // Burst allowance: interval * (burst - 1)
+	family     func(string) string
+	buckets    sync.Map // family -> *int64 (theoretical arrival time)
+	count      int64    // Approximate number of buckets
+	maxBuckets int64
+	sweeping   int32
+}
+
+// newLoadLimiter returns nil when rate limiting is disabled.
+func newLoadLimiter(config Config) *loadLimiter {
+	if config.LoadRateLimit <= 0 {
+		return nil
+	}
+	interval := int64(math.Max(1, float64(time.Second)/config.LoadRateLimit))
+	burst := config.LoadRateBurst
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(config.LoadRateLimit)))
+	}
+	family := config.KeyFamily
+	if family == nil {
+		family = func(key string) string { return key }
+	}
+	return &loadLimiter{
+		interval:   interval,
+		tolerance:  interval * int64(burst-1),
+		family:     family,
+		maxBuckets: int64(4 * config.MaxSize),
+	}
+}
+
+// allow consumes a token for key's family at time now. It returns the family
+// and whether the call is allowed.
+func (l *loadLimiter) allow(key string, now int64) (string, bool) {
+	family := l.family(key)
+
+	v, ok := l.buckets.Load(family)
+	if !ok {
+		var loaded bool
+		v, loaded = l.buckets.LoadOrStore(family, new(int64))
+		if !loaded && atomic.AddInt64(&l.count, 1) > l.maxBuckets {
+			l.sweep(now)
+		}
+	}
+	tat := v.(*int64)
+
+	for {
+		current := atomic.LoadInt64(tat)
+		next := current
+		if next < now {
+			next = now
+		}
+		next += l.interval
+		if next-now > l.tolerance+l.interval {
+			return family, false
+		}
+		if atomic.CompareAndSwapInt64(tat, current, next) {
+			return family, true
+		}
+	}
+}
+
+// sweep removes buckets that have fully refilled. Only one goroutine sweeps
+// at a time; others proceed without waiting.
+func (l *loadLimiter) sweep(now int64) {
+	if !atomic.CompareAndSwapInt32(&l.sweeping, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&l.sweeping, 0)
+
+	l.buckets.Range(func(key, value interface{}) bool {
+		if atomic.LoadInt64(value.(*int64)) <= now {
+			l.buckets.Delete(key)
+			atomic.AddInt64(&l.count, -1)
+		}
+		return true
+	})
+}
+
+// allowLoad checks the circuit breaker (see circuit_breaker.go) and
+// Config.LoadRateLimit for key. It returns a BALIOS_CIRCUIT_OPEN or
+// BALIOS_LOAD_RATE_LIMITED error if the loader must not run; otherwise the
+// caller reports the outcome of the load with recordCircuit.
+func (c *wtinyLFUCache) allowLoad(key string) error {
+	if err := c.allowCircuit(key); err != nil {
+		return err
+	}
+	if c.loadLimiter == nil {
+		return nil
+	}
+	if family, ok := c.loadLimiter.allow(key, c.timeProvider.Now()); !ok {
+		c.releaseCircuit(key)
+		atomic.AddInt64(&c.loadsRateLimited, 1)
+		return NewErrLoadRateLimited(key, family)
+	}
+	return nil
+}
+
+// LoadsRateLimited returns the number of loader calls rejected by
+// Config.LoadRateLimit.
+func (c *wtinyLFUCache) LoadsRateLimited() int64 {
+	return atomic.LoadInt64(&c.loadsRateLimited)
+}
+
+// LoadsRateLimited returns the number of loader calls rejected by
+// Config.LoadRateLimit.
+func (c *GenericCache[K, V]) LoadsRateLimited() int64 {
+	return c.inner.LoadsRateLimited()
+}
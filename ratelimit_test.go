@@ -0,0 +1,150 @@
+// ratelimit_test.go: tests for per-key-family loader rate limiting
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadRateLimit_HammeredMissingKey(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, LoadRateLimit: 2, TimeProvider: mockTime}).(*wtinyLFUCache)
+
+	calls := 0
+	loader := func() (interface{}, error) {
+		calls++
+		return nil, nil // Never cacheable: every call is a miss
+	}
+
+	limited := 0
+	for i := 0; i < 10; i++ {
+		if _, err := cache.GetOrLoad("missing", loader); err != nil {
+			if !IsLoadRateLimited(err) || !IsRetryable(err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			limited++
+		}
+	}
+	if calls != 2 || limited != 8 {
+		t.Errorf("expected burst of 2 loader calls, got calls=%d limited=%d", calls, limited)
+	}
+
+	// Tokens refill at LoadRateLimit per second
+	mockTime.Advance(500 * time.Millisecond)
+	if _, err := cache.GetOrLoad("missing", loader); err != nil || calls != 3 {
+		t.Errorf("expected one token after 500ms: err=%v calls=%d", err, calls)
+	}
+	if _, err := cache.GetOrLoad("missing", loader); !IsLoadRateLimited(err) {
+		t.Errorf("expected rate limit, got %v", err)
+	}
+	if n := cache.LoadsRateLimited(); n != 9 {
+		t.Errorf("expected 9 rejected loads, got %d", n)
+	}
+}
+
+func TestLoadRateLimit_KeyFamily(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewGenericCache[string, int](Config{
+		MaxSize:       100,
+		LoadRateLimit: 1,
+		TimeProvider:  mockTime,
+		KeyFamily: func(key string) string {
+			family, _, _ := strings.Cut(key, ":")
+			return family
+		},
+	})
+	loader := func(context.Context) (int, error) { return 1, nil }
+	ctx := context.Background()
+
+	if _, err := cache.GetOrLoadWithContext(ctx, "user:1", loader); err != nil {
+		t.Fatal(err)
+	}
+	_, err := cache.GetOrLoadWithContext(ctx, "user:2", loader)
+	if !IsLoadRateLimited(err) {
+		t.Fatalf("same family must share the bucket, got %v", err)
+	}
+	if family, _ := GetErrorContext(err)["family"].(string); family != "user" {
+		t.Errorf("error should carry the family: %v", err)
+	}
+	if _, err := cache.GetOrLoadWithContext(ctx, "order:1", loader); err != nil {
+		t.Errorf("other families are independent: %v", err)
+	}
+
+	// Hits never consume tokens
+	if v, err := cache.GetOrLoadWithContext(ctx, "user:1", loader); err != nil || v != 1 {
+		t.Errorf("cached key must be served: %v, %v", v, err)
+	}
+}
+
+func TestLoadRateLimit_NotNegativelyCached(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, LoadRateLimit: 1, NegativeCacheTTL: time.Hour, TimeProvider: mockTime})
+
+	_, _ = cache.GetOrLoad("k", func() (interface{}, error) { return nil, nil })
+	if _, err := cache.GetOrLoad("k", func() (interface{}, error) { return 1, nil }); !IsLoadRateLimited(err) {
+		t.Fatalf("expected rate limit, got %v", err)
+	}
+	mockTime.Advance(time.Second)
+	if v, err := cache.GetOrLoad("k", func() (interface{}, error) { return 1, nil }); err != nil || v != 1 {
+		t.Errorf("rate limit rejection must not be negatively cached: %v, %v", v, err)
+	}
+}
+
+func TestLoadRateLimit_ConcurrentCallersShareToken(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, LoadRateLimit: 1})
+
+	var calls int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = cache.GetOrLoad("k", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return "v", nil
+			})
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected a single loader call, got %d", calls)
+	}
+	for _, err := range errs {
+		if err != nil && !IsLoadRateLimited(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestLoadLimiter_BoundedBuckets(t *testing.T) {
+	l := newLoadLimiter(Config{MaxSize: 10, LoadRateLimit: 1000})
+	now := int64(time.Hour)
+	for i := 0; i < 1000; i++ {
+		if _, ok := l.allow("key"+strconv.Itoa(i), now); !ok {
+			t.Fatal("first call of a fresh family must be allowed")
+		}
+		now += int64(10 * time.Millisecond) // Earlier buckets refill meanwhile
+	}
+	if n := atomic.LoadInt64(&l.count); n > l.maxBuckets+1 {
+		t.Errorf("bucket map not bounded: %d buckets (max %d)", n, l.maxBuckets)
+	}
+	if newLoadLimiter(Config{}) != nil {
+		t.Error("limiter must be disabled without LoadRateLimit")
+	}
+}
@@ -0,0 +1,50 @@
+// read_contention.go: bounded key reads and GetE
+//
+// Config.KeyReadRetries bounds the SeqLock retries of a key read;
+// exhausted reads are counted, and GetE reports them as errors.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+// DefaultKeyReadRetries is the default for Config.KeyReadRetries.
+const DefaultKeyReadRetries = 100
+
+// GetE retrieves a value like Get. It returns a BALIOS_EMPTY_KEY error for an
+// empty key, BALIOS_KEY_NOT_FOUND for a miss and BALIOS_READ_CONTENTION when
+// the lookup gave up reading a key rewritten by concurrent writers.
+func (c *wtinyLFUCache) GetE(key string) (interface{}, error) {
+	if key == "" {
+		return nil, NewErrEmptyKey("GetE")
+	}
+	if c.isClosed() {
+		return nil, NewErrCacheClosed("GetE")
+	}
+	value, found, contended := c.get(key)
+	switch {
+	case found:
+		return value, nil
+	case contended:
+		return nil, NewErrReadContention(key, c.keyReadRetries)
+	default:
+		return nil, NewErrKeyNotFound(key)
+	}
+}
+
+// GetE retrieves a value like Get, reporting why it was not found (see
+// Cache.GetE). A value of a type other than V is reported as not found.
+func (c *GenericCache[K, V]) GetE(key K) (V, error) {
+	keyStr := keyToString(key)
+	var zero V
+	val, err := c.inner.GetE(keyStr)
+	if err != nil {
+		return zero, err
+	}
+	typedValue, ok := val.(V)
+	if !ok {
+		return zero, NewErrKeyNotFound(keyStr)
+	}
+	return typedValue, nil
+}
@@ -0,0 +1,91 @@
+// read_contention_test.go: tests for bounded key reads and GetE
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// holdKeyWrite simulates a writer stuck in the middle of rewriting the key of
+// the entry holding key (odd SeqLock version). It returns the release func.
+func holdKeyWrite(t *testing.T, c *wtinyLFUCache, key string) func() {
+	t.Helper()
+	entry := c.findEntry(key, stringHash(key))
+	if entry == nil {
+		t.Fatalf("key %q not found", key)
+	}
+	atomic.AddUint64(&entry.version, 1)
+	return func() { atomic.AddUint64(&entry.version, 1) }
+}
+
+func TestKeyReadRetries_Default(t *testing.T) {
+	cfg := Config{}
+	_ = cfg.Validate()
+	if cfg.KeyReadRetries != DefaultKeyReadRetries {
+		t.Errorf("expected default %d, got %d", DefaultKeyReadRetries, cfg.KeyReadRetries)
+	}
+}
+
+func TestReadContention_GetAndStats(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, KeyReadRetries: 3}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+
+	release := holdKeyWrite(t, cache, "k")
+	if _, ok := cache.Get("k"); ok {
+		t.Fatal("a key that cannot be read must be a miss")
+	}
+	if n := cache.Stats().ReadContentions; n != 1 {
+		t.Errorf("expected 1 read contention, got %d", n)
+	}
+
+	release()
+	if v, ok := cache.Get("k"); !ok || v != 1 {
+		t.Errorf("expected hit after the writer finished, got %v, %v", v, ok)
+	}
+	if n := cache.Stats().ReadContentions; n != 1 {
+		t.Errorf("successful reads must not count as contention, got %d", n)
+	}
+
+	cache.Clear()
+	if n := cache.Stats().ReadContentions; n != 0 {
+		t.Errorf("Clear must reset the counter, got %d", n)
+	}
+}
+
+func TestGetE(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("k", "v")
+
+	if v, err := cache.GetE("k"); err != nil || v != "v" {
+		t.Errorf("expected v, got %v, %v", v, err)
+	}
+	if _, err := cache.GetE("missing"); !IsNotFound(err) {
+		t.Errorf("expected BALIOS_KEY_NOT_FOUND, got %v", err)
+	}
+	if _, err := cache.GetE(""); !IsEmptyKey(err) {
+		t.Errorf("expected BALIOS_EMPTY_KEY, got %v", err)
+	}
+
+	release := holdKeyWrite(t, cache, "k")
+	defer release()
+	_, err := cache.GetE("k")
+	if !IsReadContention(err) || !IsRetryable(err) || !IsOperationError(err) {
+		t.Errorf("expected retryable BALIOS_READ_CONTENTION, got %v", err)
+	}
+}
+
+func TestGetE_Generic(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 100})
+	cache.Set(1, "one")
+	if v, err := cache.GetE(1); err != nil || v != "one" {
+		t.Errorf("expected one, got %q, %v", v, err)
+	}
+	if _, err := cache.GetE(2); !IsNotFound(err) {
+		t.Errorf("expected BALIOS_KEY_NOT_FOUND, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Package redis provides a Redis second-level cache for balios.
+//
+// # Overview
+//
+// Store implements balios.SecondaryCache on top of go-redis, turning each
+// in-process balios cache into the L1 of a two-level cache shared by all the
+// replicas of a service: GetOrLoad looks missing keys up in Redis before
+// calling the loader, and writes are written through to Redis with the TTL
+// of the cache entry.
+//
+// The package is a separate module to keep the balios core free of external
+// dependencies.
+//
+// # Installation
+//
+//	go get github.com/agilira/balios/redis
+//
+// # Quick Start
+//
+//	import (
+//	    "github.com/agilira/balios"
+//	    baliosredis "github.com/agilira/balios/redis"
+//	    goredis "github.com/redis/go-redis/v9"
+//	)
+//
+//	client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	cache := balios.NewCache(balios.Config{
+//	    MaxSize:          10_000,
+//	    TTL:              10 * time.Minute,
+//	    SecondaryCache:   baliosredis.New(client, baliosredis.WithKeyPrefix("users:")),
+//	    SecondaryTimeout: 50 * time.Millisecond,
+//	})
+//
+// # Encoding
+//
+// Values are encoded with a balios.Codec, balios.GobCodec by default (custom
+// types must be registered with gob.Register). Use WithCodec to share keys
+// with services written in other languages, e.g. with a JSON codec.
+//
+// Redis failures never fail a cache operation: balios logs them and falls
+// back to the loader.
+//
+// # License
+//
+// Same as balios core (see LICENSE in main repository).
+package redis
@@ -0,0 +1,19 @@
+module github.com/agilira/balios/redis
+
+go 1.25
+
+require (
+	github.com/agilira/balios v0.0.0
+	github.com/alicebob/miniredis/v2 v2.37.0
+	github.com/redis/go-redis/v9 v9.9.0
+)
+
+require (
+	github.com/agilira/go-errors v1.1.1 // indirect
+	github.com/agilira/go-timecache v1.0.2 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+)
+
+replace github.com/agilira/balios => ../
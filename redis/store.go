@@ -0,0 +1,117 @@
+// store.go: Redis implementation of balios.SecondaryCache
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/agilira/balios"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Store is a balios.SecondaryCache backed by Redis. Values are encoded with a
+// balios.Codec and stored as plain strings under the configured key prefix,
+// with the TTL of the cache entry.
+//
+// Thread-safety: Safe for concurrent use, like the underlying client.
+type Store struct {
+	client goredis.UniversalClient
+	prefix string
+	codec  balios.Codec
+}
+
+// Options for configuring Store.
+type Options struct {
+	// KeyPrefix is prepended to every cache key, so that several caches can
+	// share a Redis database.
+	// Default: "" (keys are stored as is)
+	KeyPrefix string
+
+	// Codec encodes values stored in Redis. Custom types must be supported
+	// by the codec (gob.Register for the default).
+	// Default: balios.GobCodec
+	Codec balios.Codec
+}
+
+// Option is a functional option for configuring Store.
+type Option func(*Options)
+
+// WithKeyPrefix sets the prefix of the Redis keys (e.g. "users:").
+func WithKeyPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.KeyPrefix = prefix
+	}
+}
+
+// WithCodec sets the codec encoding values. Every replica sharing the keys
+// must use the same codec.
+func WithCodec(codec balios.Codec) Option {
+	return func(o *Options) {
+		o.Codec = codec
+	}
+}
+
+// New creates a Store using client, which may be a single-node, cluster or
+// failover client. The client is not closed by the Store.
+//
+// Example:
+//
+//	client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	cache := balios.NewCache(balios.Config{
+//	    MaxSize:        10_000,
+//	    SecondaryCache: redis.New(client, redis.WithKeyPrefix("users:")),
+//	})
+func New(client goredis.UniversalClient, opts ...Option) *Store {
+	options := Options{
+		Codec: balios.GobCodec{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Codec == nil {
+		options.Codec = balios.GobCodec{}
+	}
+
+	return &Store{
+		client: client,
+		prefix: options.KeyPrefix,
+		codec:  options.Codec,
+	}
+}
+
+// Get returns the value stored for key. found is false for missing keys.
+func (s *Store) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, err := s.codec.Unmarshal(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value for key. A ttl of 0 stores the key without expiration.
+func (s *Store) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := s.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, data, ttl).Err()
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
@@ -0,0 +1,141 @@
+// store_test.go: tests for the Redis secondary cache
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/agilira/balios"
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client, opts...), server
+}
+
+func TestStore_GetSetDelete(t *testing.T) {
+	store, server := newTestStore(t, WithKeyPrefix("users:"))
+	ctx := context.Background()
+
+	if _, found, err := store.Get(ctx, "1"); err != nil || found {
+		t.Fatalf("missing key should not be found, got %v, %v", found, err)
+	}
+
+	if err := store.Set(ctx, "1", "alice", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !server.Exists("users:1") {
+		t.Error("key should be stored under the prefix")
+	}
+	if ttl := server.TTL("users:1"); ttl != time.Minute {
+		t.Errorf("expected a TTL of 1m, got %v", ttl)
+	}
+
+	value, found, err := store.Get(ctx, "1")
+	if err != nil || !found || value != "alice" {
+		t.Errorf("expected alice, got %v, %v, %v", value, found, err)
+	}
+
+	if err := store.Delete(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "1"); err != nil {
+		t.Errorf("deleting a missing key should not fail: %v", err)
+	}
+	if _, found, _ := store.Get(ctx, "1"); found {
+		t.Error("deleted key should not be found")
+	}
+}
+
+func TestStore_NoTTL(t *testing.T) {
+	store, server := newTestStore(t)
+
+	if err := store.Set(context.Background(), "k", 42, 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL("k"); ttl != 0 {
+		t.Errorf("a zero TTL should store the key without expiration, got %v", ttl)
+	}
+}
+
+// jsonCodec stores values as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(value interface{}) ([]byte, error) { return json.Marshal(value) }
+
+func (jsonCodec) Unmarshal(data []byte) (interface{}, error) {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+func TestStore_Codec(t *testing.T) {
+	store, server := newTestStore(t, WithCodec(jsonCodec{}))
+
+	if err := store.Set(context.Background(), "k", map[string]interface{}{"name": "alice"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := server.Get("k"); raw != `{"name":"alice"}` {
+		t.Errorf("expected a JSON document, got %q", raw)
+	}
+
+	if err := server.Set("bad", "not json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Get(context.Background(), "bad"); err == nil {
+		t.Error("undecodable values should be reported as errors")
+	}
+}
+
+func TestStore_ConnectionError(t *testing.T) {
+	store, server := newTestStore(t)
+	server.Close()
+
+	if _, _, err := store.Get(context.Background(), "k"); err == nil {
+		t.Error("expected an error from a closed server")
+	}
+}
+
+func TestStore_AsSecondaryCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	newReplica := func() balios.Cache {
+		client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return balios.NewCache(balios.Config{
+			MaxSize:        100,
+			TTL:            time.Minute,
+			SecondaryCache: New(client, WithKeyPrefix("test:")),
+		})
+	}
+	first, second := newReplica(), newReplica()
+
+	value, err := first.GetOrLoad("user:1", func() (interface{}, error) { return "alice", nil })
+	if err != nil || value != "alice" {
+		t.Fatalf("GetOrLoad failed: %v, %v", value, err)
+	}
+
+	value, err = second.GetOrLoad("user:1", func() (interface{}, error) {
+		t.Error("the second replica should hit Redis")
+		return nil, nil
+	})
+	if err != nil || value != "alice" {
+		t.Errorf("expected the value loaded by the first replica, got %v, %v", value, err)
+	}
+
+	first.Delete("user:1")
+	if server.Exists("test:user:1") {
+		t.Error("Delete should remove the key from Redis")
+	}
+}
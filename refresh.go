@@ -0,0 +1,103 @@
+// refresh.go: refresh-ahead loading (stale-while-revalidate) for GetOrLoad
+//
+// WithRefreshTTL returns a stale hit immediately and reloads the entry in
+// the background before it expires.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// WithRefreshTTL returns cached values older than refreshTTL immediately and
+// reloads them in the background, so hot keys are replaced before they
+// expire. It only applies to entries with a TTL longer than refreshTTL.
+// Non-positive values are ignored.
+func WithRefreshTTL(refreshTTL time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		if refreshTTL > 0 {
+			o.refreshTTL = refreshTTL
+		}
+	}
+}
+
+// needsRefresh reports whether the live entry of key is older than the
+// refresh TTL of o.
+func (c *wtinyLFUCache) needsRefresh(key string, o *loadOptions) bool {
+	if o.refreshTTL <= 0 {
+		return false
+	}
+	ttlNanos := c.ttlNanos
+	if o.ttl > 0 {
+		ttlNanos = int64(o.ttl)
+	}
+	if ttlNanos <= int64(o.refreshTTL) {
+		return false // The entry expires before it is due for refresh
+	}
+
+	entry := c.findEntry(key, c.hashKey(key))
+	if entry == nil {
+		return false
+	}
+	expireAt := atomic.LoadInt64(&entry.expireAt)
+	if expireAt <= 0 {
+		return false
+	}
+	age := ttlNanos - (expireAt - c.ttlClock(c.timeProvider.Now()))
+	return age >= int64(o.refreshTTL)
+}
+
+// refreshAsync reloads key in the background unless a load of key is already
+// in flight. The reloaded value is stored with the options of the call.
+func (c *wtinyLFUCache) refreshAsync(ctx context.Context, key string, loader func(context.Context) (interface{}, error), source string, o loadOptions) {
+	callKey := "load:" + key
+	flight := &inflightCall{done: make(chan struct{})}
+	flight.wg.Add(1)
+	if _, loaded := c.inflight.LoadOrStore(callKey, flight); loaded {
+		return // Already loading: the running load refreshes the entry
+	}
+
+	go func() {
+		defer func() {
+			close(flight.done)
+			flight.wg.Done()
+			c.inflight.Delete(callKey)
+		}()
+
+		if err := c.allowLoad(key); err != nil {
+			flight.val.Store(&resultWrapper{})
+			flight.err.Store(&errorWrapper{err: err})
+			return
+		}
+
+		var loaderVal interface{}
+		var loaderErr error
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					loaderErr = NewErrPanicRecovered("refresh:"+key, r)
+				}
+			}()
+			loaderVal, loaderErr = c.wrapLoader(key, loader)(ctx)
+		}()
+		c.recordCircuit(key, loaderErr)
+
+		flight.val.Store(&resultWrapper{value: loaderVal})
+		flight.err.Store(&errorWrapper{err: loaderErr})
+
+		if loaderErr != nil {
+			// Keep serving the current value until it expires
+			c.logger.Debug("balios: refresh-ahead load failed", "key", key, "error", loaderErr)
+			return
+		}
+		if loaderVal != nil {
+			c.whileOpen(func() { c.storeLoaded(key, loaderVal, source, &o) })
+		}
+	}()
+}
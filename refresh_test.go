@@ -0,0 +1,195 @@
+// refresh_test.go: tests for refresh-ahead loading
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForLoad waits until no load of key is in flight.
+func waitForLoad(t *testing.T, cache Cache, key string) {
+	t.Helper()
+	c := cache.(*wtinyLFUCache)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, loading := c.inflight.Load("load:" + key); !loading {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not complete")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRefreshTTL_ServesStaleAndReloads(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, TimeProvider: mockTime})
+
+	var version int64
+	loader := func() (interface{}, error) {
+		return atomic.AddInt64(&version, 1), nil
+	}
+	refresh := WithRefreshTTL(5 * time.Second)
+
+	if v, _ := cache.GetOrLoad("k", loader, refresh); v != int64(1) {
+		t.Fatalf("first load = %v", v)
+	}
+
+	// Younger than the refresh TTL: plain hit
+	mockTime.Advance(2 * time.Second)
+	if v, _ := cache.GetOrLoad("k", loader, refresh); v != int64(1) {
+		t.Errorf("fresh hit = %v", v)
+	}
+	waitForLoad(t, cache, "k")
+	if atomic.LoadInt64(&version) != 1 {
+		t.Fatal("a fresh entry must not be refreshed")
+	}
+
+	// Older than the refresh TTL: stale value now, new value afterwards
+	mockTime.Advance(4 * time.Second)
+	if v, _ := cache.GetOrLoad("k", loader, refresh); v != int64(1) {
+		t.Errorf("stale hit must return the cached value immediately, got %v", v)
+	}
+	waitForLoad(t, cache, "k")
+	if v, _ := cache.Get("k"); v != int64(2) {
+		t.Errorf("expected the refreshed value, got %v", v)
+	}
+
+	// The refresh renewed the TTL
+	mockTime.Advance(8 * time.Second)
+	if !cache.Has("k") {
+		t.Error("the refreshed entry must live a full TTL from the reload")
+	}
+}
+
+func TestRefreshTTL_SingleReload(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, TimeProvider: mockTime})
+	cache.Set("hot", "v0")
+	mockTime.Advance(8 * time.Second)
+
+	var calls int64
+	release := make(chan struct{})
+	loader := func() (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+		return "v1", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := cache.GetOrLoad("hot", loader, WithRefreshTTL(time.Second)); err != nil || v != "v0" {
+				t.Errorf("stale hit = %v, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(release)
+	waitForLoad(t, cache, "hot")
+
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Errorf("expected a single background reload, got %d", n)
+	}
+	if v, _ := cache.Get("hot"); v != "v1" {
+		t.Errorf("expected v1, got %v", v)
+	}
+}
+
+func TestRefreshTTL_FailureKeepsValue(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, NegativeCacheTTL: time.Minute, TimeProvider: mockTime})
+	cache.Set("k", "good")
+	mockTime.Advance(6 * time.Second)
+
+	failing := func(context.Context) (interface{}, error) { return nil, errors.New("backend down") }
+	if v, err := cache.GetOrLoadWithContext(context.Background(), "k", failing, WithRefreshTTL(5*time.Second)); err != nil || v != "good" {
+		t.Errorf("stale hit = %v, %v", v, err)
+	}
+	waitForLoad(t, cache, "k")
+
+	if v, _ := cache.Get("k"); v != "good" {
+		t.Errorf("a failed refresh must keep the current value, got %v", v)
+	}
+	var calls int
+	_, _ = cache.GetOrLoad("k", func() (interface{}, error) { calls++; return "new", nil }, WithRefreshTTL(5*time.Second))
+	waitForLoad(t, cache, "k")
+	if v, _ := cache.Get("k"); calls != 1 || v != "new" {
+		t.Errorf("a failed refresh must not be negatively cached: calls=%d value=%v", calls, v)
+	}
+}
+
+func TestRefreshTTL_DetachedFromCallerContext(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: 10 * time.Second, TimeProvider: mockTime}).(*wtinyLFUCache)
+	cache.Set("k", "old")
+	mockTime.Advance(6 * time.Second)
+
+	ctx, cancel := context.WithCancel(WithSource(context.Background(), "refresher"))
+	started := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		time.Sleep(10 * time.Millisecond)
+		return "new", ctx.Err()
+	}
+	_, _ = cache.GetOrLoadWithContext(ctx, "k", loader, WithRefreshTTL(5*time.Second))
+	<-started
+	cancel()
+	waitForLoad(t, cache, "k")
+
+	if v, _ := cache.Get("k"); v != "new" {
+		t.Errorf("cancelling the caller must not abort the refresh, got %v", v)
+	}
+	if src, _ := cache.SourceOf("k"); src != "refresher" {
+		t.Errorf("the refresh must keep context values, source = %q", src)
+	}
+}
+
+func TestRefreshTTL_Ignored(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	noTTL := NewCache(Config{MaxSize: 100, TimeProvider: mockTime})
+	shortTTL := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime})
+	noTTL.Set("k", 1)
+	shortTTL.Set("k", 1)
+	mockTime.Advance(900 * time.Millisecond)
+
+	var calls int64
+	loader := func() (interface{}, error) { atomic.AddInt64(&calls, 1); return 2, nil }
+	for _, cache := range []Cache{noTTL, shortTTL} {
+		_, _ = cache.GetOrLoad("k", loader, WithRefreshTTL(500*time.Millisecond), WithRefreshTTL(-1))
+		waitForLoad(t, cache, "k")
+	}
+	_, _ = noTTL.GetOrLoad("k", loader, WithRefreshTTL(time.Millisecond))
+	waitForLoad(t, noTTL, "k")
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Errorf("expected only the short-TTL entry to refresh, got %d reloads", n)
+	}
+}
+
+func TestRefreshTTL_Generic(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewGenericCache[int, string](Config{MaxSize: 100, TTL: time.Minute, TimeProvider: mockTime})
+	cache.Set(1, "old")
+	mockTime.Advance(45 * time.Second)
+
+	v, err := cache.GetOrLoad(1, func() (string, error) { return "new", nil }, WithRefreshTTL(30*time.Second))
+	if err != nil || v != "old" {
+		t.Fatalf("stale hit = %v, %v", v, err)
+	}
+	waitForLoad(t, cache.inner, "1")
+	if v, _ := cache.Get(1); v != "new" {
+		t.Errorf("expected new, got %v", v)
+	}
+}
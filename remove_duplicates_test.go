@@ -97,8 +97,8 @@ func TestRemoveDuplicateKeys_HighContention(t *testing.T) {
 	testKeyHash := stringHash(testKey)
 	duplicateCount := 0
 
-	for i := range internalCache.entries {
-		entry := &internalCache.entries[i]
+	for i := range internalCache.table.Load().entries {
+		entry := &internalCache.table.Load().entries[i]
 		state := atomic.LoadInt32(&entry.valid)
 
 		if state == entryValid {
@@ -235,8 +235,8 @@ func TestRemoveDuplicateKeys_ConcurrentSetDelete(t *testing.T) {
 		keyHash := stringHash(key)
 		count := 0
 
-		for i := range internalCache.entries {
-			entry := &internalCache.entries[i]
+		for i := range internalCache.table.Load().entries {
+			entry := &internalCache.table.Load().entries[i]
 			state := atomic.LoadInt32(&entry.valid)
 
 			if state == entryValid {
@@ -334,8 +334,8 @@ func TestRemoveDuplicateKeys_StateTransitionRace(t *testing.T) {
 	testKeyHash := stringHash(testKey)
 	duplicateCount := 0
 
-	for i := range internalCache.entries {
-		entry := &internalCache.entries[i]
+	for i := range internalCache.table.Load().entries {
+		entry := &internalCache.table.Load().entries[i]
 		state := atomic.LoadInt32(&entry.valid)
 
 		if state == entryValid {
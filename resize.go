@@ -0,0 +1,271 @@
+// resize.go: Resize, changing the capacity of a live cache
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxCapacity is the largest capacity Resize can set.
+	maxCapacity = 1 << 30
+
+	// writerStripes is the number of writer counters of a table. Writers
+	// register on the counter of their key hash, so they rarely share one.
+	writerStripes = 64
+
+	// resizeDrainTimeout bounds the wait for the writers of a sealed table.
+	// A writer still registered after it may be waiting for the migration
+	// itself (a write from a callback): the table is unsealed and the
+	// migration attempted again, up to resizeAttempts times.
+	resizeDrainTimeout = 10 * time.Millisecond
+	resizeAttempts     = 16
+)
+
+// writerCount is a counter of writers, alone on its cache line.
+type writerCount struct {
+	n atomic.Int64
+	_ [56]byte
+}
+
+// Resize sets the capacity of the cache to newMaxSize entries, evicting the
+// entries in excess. Safe to call concurrently with other operations.
+//
+// Up to the capacity the table was sized for (Config.ResizeLimit, or the
+// largest capacity set since), Resize only changes the limit. Beyond it, the
+// live entries are copied to a new table of about two slots per entry: writes
+// wait for the copy, reads keep going on the old table, and the old table is
+// freed once the operations using it return.
+//
+// Returns BALIOS_INVALID_MAX_SIZE for a non-positive size, a
+// BALIOS_INVALID_CONFIG error beyond 2^30 entries, BALIOS_CACHE_CLOSED after
+// Close and BALIOS_INTERNAL_ERROR if writers kept the table busy (a Resize
+// from an OnEvict or Compute callback cannot grow the table).
+func (c *wtinyLFUCache) Resize(newMaxSize int) error {
+	if c.isClosed() {
+		return NewErrCacheClosed("Resize")
+	}
+	if newMaxSize <= 0 {
+		return NewErrInvalidMaxSize(newMaxSize)
+	}
+	if newMaxSize > maxCapacity {
+		return NewErrInvalidConfig("MaxSize", fmt.Sprintf("%d exceeds %d", newMaxSize, maxCapacity))
+	}
+
+	c.resizeMu.Lock()
+	defer c.resizeMu.Unlock()
+	t := c.table.Load()
+	if t == nil {
+		return NewErrCacheClosed("Resize")
+	}
+	if newMaxSize > int(t.limit) {
+		if err := c.grow(t, newMaxSize); err != nil {
+			return err
+		}
+	}
+
+	previous := atomic.SwapInt32(&c.maxSize, int32(newMaxSize)) // #nosec G115 -- bounded by maxCapacity
+	evicted := 0
+	for !c.isClosed() {
+		excess := c.entryCount() - c.capacity()
+		if excess <= 0 {
+			break
+		}
+		n := c.evictEntries(int(excess))
+		if n == 0 {
+			break
+		}
+		evicted += n
+	}
+	c.logger.Debug("balios: cache resized", "from", previous, "to", newMaxSize, "evicted", evicted)
+	return nil
+}
+
+// grow migrates the entries of t to a table sized for limit.
+func (c *wtinyLFUCache) grow(t *cacheTable, limit int) error {
+	for attempt := 0; attempt < resizeAttempts; attempt++ {
+		if !t.seal() {
+			time.Sleep(resizeDrainTimeout)
+			continue
+		}
+		next, dropped := c.migrate(t, limit)
+		if !c.table.CompareAndSwap(t, next) {
+			return NewErrCacheClosed("Resize")
+		}
+		c.frozen.Store(nil) // Indexes slots of t
+		for _, e := range dropped {
+			atomic.AddInt64(&c.evictions, 1)
+			if c.onEvict != nil {
+				c.notifyEvict(e.key, e.value, ReasonEvicted)
+			}
+		}
+		c.logger.Debug("balios: table grown", "slots", len(next.entries), "entries", next.size())
+		return nil
+	}
+	return NewErrInternal("Resize", fmt.Errorf("writers kept the table busy for %d attempts", resizeAttempts))
+}
+
+// migrate copies the live entries of t, sealed, into a new table sized for
+// limit. Entries keep their state word: the new table continues the
+// generation of t. Frequencies of the copied keys are carried over to the
+// new sketch; miss reasons recorded in t are not. Returns the entries that
+// found no slot within the probe bound, dropped as evictions.
+func (c *wtinyLFUCache) migrate(t *cacheTable, limit int) (next *cacheTable, dropped []evicted) {
+	next = t.grown(limit)
+	g := t.gen.Load()
+	ng := newGeneration(g.id)
+	next.gen.Store(ng)
+	var moved []uint32 // New slot + 1 of each old slot, for the admission window
+	if t.admissionWindow != nil {
+		moved = make([]uint32, len(t.entries))
+	}
+
+	for i := range t.entries {
+		e := &t.entries[i]
+		state := atomic.LoadInt32(&e.valid)
+		for stateOf(state) == entryPending {
+			// Held by a compaction (see compact.go): they do not register as
+			// writers, since moving a value does not change the entry
+			runtime.Gosched()
+			state = atomic.LoadInt32(&e.valid)
+		}
+		if state != g.live {
+			continue
+		}
+		keyHash := atomic.LoadUint64(&e.keyHash)
+		idx, ok := next.emptySlot(keyHash)
+		if !ok {
+			dropped = append(dropped, takeEvicted(e))
+			continue
+		}
+		slot := &next.entries[idx]
+		slot.keyHash = keyHash
+		slot.publishKey(e.loadKey())
+		slot.value.Store(e.value.Load())
+		slot.expireAt = atomic.LoadInt64(&e.expireAt)
+		slot.weight = atomic.LoadInt32(&e.weight)
+		slot.valid = ng.live
+		ng.size++
+		ng.weight += int64(slot.weight)
+
+		if t.times != nil {
+			next.times[idx] = entryTimes{
+				inserted: atomic.LoadInt64(&t.times[i].inserted),
+				updated:  atomic.LoadInt64(&t.times[i].updated),
+				accessed: atomic.LoadInt64(&t.times[i].accessed),
+			}
+		}
+		if t.writeDeadlines != nil {
+			next.writeDeadlines[idx] = atomic.LoadInt64(&t.writeDeadlines[i])
+		}
+		next.sketch.incrementBy(keyHash, t.sketch.estimate(keyHash))
+		if moved != nil {
+			moved[i] = uint32(idx + 1) // #nosec G115 -- bounded by the table size
+		}
+	}
+	if moved != nil {
+		next.admissionWindow.remap(moved)
+	}
+	return next, dropped
+}
+
+// emptySlot returns the first empty slot of the probe sequence of keyHash in
+// a table under construction.
+func (t *cacheTable) emptySlot(keyHash uint64) (uint64, bool) {
+	probes := maxProbeLength
+	if probes > t.mask {
+		probes = t.mask
+	}
+	for i := uint32(0); i <= probes; i++ {
+		idx := (keyHash + uint64(i)) & uint64(t.mask)
+		if t.entries[idx].valid == entryEmpty {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// writeTable returns the table with a writer registered on the counter of
+// stripe, waiting while Resize migrates it, or nil once closed. The caller
+// releases it with t.leave(stripe).
+func (c *wtinyLFUCache) writeTable(stripe uint64) *cacheTable {
+	for {
+		t := c.table.Load()
+		if t == nil || t.enter(stripe) {
+			return t
+		}
+		runtime.Gosched() // Sealed: the migrated table is published shortly
+	}
+}
+
+// enter registers a writer of t on the counter of stripe, unless t is sealed.
+func (t *cacheTable) enter(stripe uint64) bool {
+	w := &t.writers[stripe%writerStripes].n
+	w.Add(1)
+	if !t.sealed.Load() {
+		return true
+	}
+	w.Add(-1)
+	return false
+}
+
+// leave ends a write registered by enter.
+func (t *cacheTable) leave(stripe uint64) {
+	t.writers[stripe%writerStripes].n.Add(-1)
+}
+
+// seal stops new writers of t and waits for the registered ones to leave.
+// Returns false, with t unsealed, if they did not within resizeDrainTimeout.
+func (t *cacheTable) seal() bool {
+	t.sealed.Store(true)
+	deadline := time.Now().Add(resizeDrainTimeout)
+	for i := range t.writers {
+		for spins := 0; t.writers[i].n.Load() != 0; spins++ {
+			if time.Now().After(deadline) {
+				t.sealed.Store(false)
+				return false
+			}
+			if spins < drainSpins {
+				runtime.Gosched()
+			} else {
+				time.Sleep(drainSleep)
+			}
+		}
+	}
+	return true
+}
+
+// capacity returns the current capacity of the cache.
+func (c *wtinyLFUCache) capacity() int64 {
+	return int64(atomic.LoadInt32(&c.maxSize))
+}
+
+// evictEntries evicts up to n entries and returns the number evicted.
+// evictOne samples the table and can miss in a sparse one: as many misses as
+// evictions are allowed before giving up.
+func (c *wtinyLFUCache) evictEntries(n int) int {
+	t := c.writeTable(0)
+	if t == nil {
+		return 0 // Closed
+	}
+	defer t.leave(0)
+	evicted := 0
+	for attempts := 0; evicted < n && attempts < 2*n && !c.isClosed(); attempts++ {
+		if c.evictOne(t) {
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Resize sets the capacity of the cache (see Cache.Resize).
+func (c *GenericCache[K, V]) Resize(newMaxSize int) error {
+	return c.inner.Resize(newMaxSize)
+}
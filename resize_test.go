@@ -0,0 +1,282 @@
+// resize_test.go: tests for Resize
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResize_Shrink(t *testing.T) {
+	var evicted int64
+	cache := NewCache(Config{
+		MaxSize: 1000,
+		OnEvict: func(key string, value interface{}, reason EvictReason) {
+			if reason == ReasonEvicted {
+				atomic.AddInt64(&evicted, 1)
+			}
+		},
+	}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+	for i := 0; i < 1000; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	before := cache.Stats().Evictions
+
+	if err := cache.Resize(100); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	if got := cache.Capacity(); got != 100 {
+		t.Errorf("Capacity = %d, want 100", got)
+	}
+	if got := cache.Len(); got > 100 {
+		t.Errorf("Len = %d after shrinking to 100", got)
+	}
+	removed := cache.Stats().Evictions - before
+	if removed < 900 || atomic.LoadInt64(&evicted) != int64(removed) {
+		t.Errorf("evictions: %d counted, %d reported to OnEvict", removed, atomic.LoadInt64(&evicted))
+	}
+
+	// The new capacity holds for later writes
+	for i := 1000; i < 2000; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	if got := cache.Len(); got > 100 {
+		t.Errorf("Len = %d after writes at capacity 100", got)
+	}
+}
+
+func TestResize_GrowKeepsEntries(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, ResizeLimit: 1000}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+	for i := 0; i < 100; i++ {
+		cache.Set("warm"+strconv.Itoa(i), i)
+	}
+
+	if err := cache.Resize(1000); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	for i := 0; i < 800; i++ {
+		cache.Set("new"+strconv.Itoa(i), i)
+	}
+	if got := cache.Len(); got != 900 {
+		t.Errorf("Len = %d, want 900 after growing to 1000", got)
+	}
+	for i := 0; i < 100; i++ {
+		if v, found := cache.Get("warm" + strconv.Itoa(i)); !found || v != i {
+			t.Fatalf("warm entry %d lost by Resize: %v, %v", i, v, found)
+		}
+	}
+}
+
+func TestResize_Errors(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	if err := cache.Resize(0); GetErrorCode(err) != ErrCodeInvalidMaxSize {
+		t.Errorf("Resize(0): got %v", err)
+	}
+	if err := cache.Resize(maxCapacity + 1); !IsConfigError(err) {
+		t.Errorf("Resize beyond maxCapacity: got %v", err)
+	}
+	if got := cache.Capacity(); got != 100 {
+		t.Errorf("failed Resize changed Capacity to %d", got)
+	}
+	if err := cache.Resize(50); err != nil {
+		t.Errorf("Resize(50) error = %v", err)
+	}
+	if err := cache.Resize(100); err != nil {
+		t.Errorf("growing back to MaxSize: %v", err)
+	}
+
+	_ = cache.Close()
+	if err := cache.Resize(10); !IsCacheClosed(err) {
+		t.Errorf("closed cache: got %v", err)
+	}
+}
+
+func TestResize_GrowDefaultConfig(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, TrackEntryTimes: true}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+	for i := 0; i < 100; i++ {
+		cache.Set("warm"+strconv.Itoa(i), i)
+	}
+	before := cache.table.Load()
+	info, _ := cache.EntryInfo("warm0")
+
+	if err := cache.Resize(10000); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	after := cache.table.Load()
+	if len(after.entries) < 2*10000 || after.limit != 10000 {
+		t.Fatalf("table of %d slots (limit %d) after growing to 10000", len(after.entries), after.limit)
+	}
+	if after == before || !before.sealed.Load() {
+		t.Error("the old table is still in use")
+	}
+
+	for i := 0; i < 100; i++ {
+		if v, found := cache.Get("warm" + strconv.Itoa(i)); !found || v != i {
+			t.Fatalf("warm entry %d lost by Resize: %v, %v", i, v, found)
+		}
+	}
+	if moved, _ := cache.EntryInfo("warm0"); !moved.InsertedAt.Equal(info.InsertedAt) {
+		t.Errorf("InsertedAt = %v, want %v", moved.InsertedAt, info.InsertedAt)
+	}
+	for i := 0; i < 9900; i++ {
+		cache.Set("new"+strconv.Itoa(i), i)
+	}
+	if got := cache.Len(); got != 10000 {
+		t.Errorf("Len = %d, want 10000 after growing", got)
+	}
+	stats := cache.Stats()
+	if stats.Evictions != 0 || stats.LoadFactor > 0.5 {
+		t.Errorf("Evictions = %d, LoadFactor = %.2f", stats.Evictions, stats.LoadFactor)
+	}
+}
+
+func TestResize_GrowKeepsWeightAndGeneration(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10, MaxWeight: 1000, Weigher: byteWeigher}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+	cache.Set("stale", make([]byte, 10))
+	cache.Clear() // The next table continues the generation
+	for i := 0; i < 10; i++ {
+		cache.Set("k"+strconv.Itoa(i), make([]byte, 10))
+	}
+
+	if err := cache.Resize(100); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	if w := cache.Stats().Weight; w != 100 || w != liveWeight(cache) {
+		t.Errorf("Weight = %d, live entries weigh %d (want 100)", w, liveWeight(cache))
+	}
+	if _, found := cache.Get("stale"); found {
+		t.Error("a cleared entry was moved to the new table")
+	}
+	if n := cache.Len(); n != 10 {
+		t.Errorf("Len = %d, want 10", n)
+	}
+	cache.Clear()
+	if n, w := cache.Len(), cache.Stats().Weight; n != 0 || w != 0 {
+		t.Errorf("Len = %d, Weight = %d after Clear", n, w)
+	}
+}
+
+func TestResize_GrowConcurrentWrites(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	// Each writer owns its keys: after the growth, every key holds the last
+	// value written to it, whichever table the write reached
+	const writers, keys = 4, 2000
+	var wg sync.WaitGroup
+	for g := 0; g < writers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for round := 0; round < 3; round++ {
+				for i := 0; i < keys; i++ {
+					cache.Set(strconv.Itoa(g)+":"+strconv.Itoa(i), round)
+				}
+			}
+		}(g)
+	}
+	for _, size := range []int{1000, 4000, 2 * writers * keys} {
+		if err := cache.Resize(size); err != nil {
+			t.Fatalf("Resize(%d) error = %v", size, err)
+		}
+	}
+	wg.Wait()
+
+	// Rewrite once more now that everything fits, then check every key
+	for g := 0; g < writers; g++ {
+		for i := 0; i < keys; i++ {
+			cache.Set(strconv.Itoa(g)+":"+strconv.Itoa(i), 3)
+		}
+	}
+	for g := 0; g < writers; g++ {
+		for i := 0; i < keys; i++ {
+			if v, found := cache.Get(strconv.Itoa(g) + ":" + strconv.Itoa(i)); !found || v != 3 {
+				t.Fatalf("key %d:%d = %v, %v", g, i, v, found)
+			}
+		}
+	}
+	if got := cache.Len(); got != writers*keys {
+		t.Errorf("Len = %d, want %d", got, writers*keys)
+	}
+}
+
+func TestResize_GrowFromCallback(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+	cache.Set("k", 1)
+
+	// Compute holds the table while fn runs: the growth cannot wait for it
+	var err error
+	cache.Compute("k", func(old interface{}, exists bool) (interface{}, bool) {
+		err = cache.Resize(100)
+		return 2, true
+	})
+	if GetErrorCode(err) != ErrCodeInternalError {
+		t.Fatalf("Resize from Compute: got %v", err)
+	}
+	if v, found := cache.Get("k"); !found || v != 2 {
+		t.Errorf("Get = %v, %v after the failed Resize", v, found)
+	}
+	if err := cache.Resize(100); err != nil {
+		t.Errorf("Resize() error = %v", err)
+	}
+}
+
+func TestResize_ConcurrentWrites(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				cache.Set(strconv.Itoa(g)+":"+strconv.Itoa(i), i)
+			}
+		}(g)
+	}
+	for _, size := range []int{500, 100, 800, 200} {
+		if err := cache.Resize(size); err != nil {
+			t.Fatalf("Resize(%d) error = %v", size, err)
+		}
+	}
+	wg.Wait()
+
+	if err := cache.Resize(200); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	if got := cache.Len(); got > 200 {
+		t.Errorf("Len = %d after the final Resize(200)", got)
+	}
+}
+
+func TestResize_Config(t *testing.T) {
+	config := Config{MaxSize: 100, ResizeLimit: 10}
+	_ = config.Validate()
+	if config.ResizeLimit != 100 {
+		t.Errorf("ResizeLimit = %d, want MaxSize", config.ResizeLimit)
+	}
+
+	generic := NewGenericCache[string, int](Config{MaxSize: 10, ResizeLimit: 20})
+	defer generic.Close()
+	if err := generic.Resize(20); err != nil || generic.Capacity() != 20 {
+		t.Errorf("GenericCache.Resize: %v, Capacity = %d", err, generic.Capacity())
+	}
+	swappable := NewSwappableCache(NewCache(Config{MaxSize: 10}))
+	defer func() { _ = swappable.Close() }()
+	if err := swappable.Resize(5); err != nil || swappable.Capacity() != 5 {
+		t.Errorf("SwappableCache.Resize: %v, Capacity = %d", err, swappable.Capacity())
+	}
+}
@@ -0,0 +1,132 @@
+// revalidate.go: conditional revalidation (ETag / If-None-Match) for read-through caching
+//
+// Revalidating loaders receive the previous LoadResult and can answer "not
+// modified", which re-stores it with a renewed TTL.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+package balios
+
+import "context"
+
+// RevalidateFunc loads a fresh LoadResult given the previously cached one.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - previous: The last cached result (only meaningful if hasPrevious is true)
+//   - hasPrevious: Whether a previous result was available
+//
+// Return NewErrNotModified(key) to keep the previous result and renew its TTL,
+// a new LoadResult to replace it, or any other error to fail the load.
+type RevalidateFunc[V any, M any] func(ctx context.Context, previous LoadResult[V, M], hasPrevious bool) (LoadResult[V, M], error)
+
+// stalePeeker is implemented by caches able to return expired-but-not-reclaimed values.
+type stalePeeker interface {
+	peekStale(key string) (interface{}, bool)
+}
+
+// peekStale returns the last value stored for key, ignoring expiration (best effort).
+func (c *GenericCache[K, V]) peekStale(key K) (V, bool) {
+	var zero V
+	peeker, ok := c.inner.(stalePeeker)
+	if !ok {
+		return zero, false
+	}
+	val, found := peeker.peekStale(keyToString(key))
+	if !found {
+		return zero, false
+	}
+	typed, ok := val.(V)
+	if !ok {
+		return zero, false
+	}
+	return typed, true
+}
+
+// GetOrRevalidateResult returns the cached LoadResult for key, or loads it with
+// revalidate on a miss. When the previous result is still retained after expiry
+// (best effort: lazily expired entries that were not yet reclaimed), it is passed
+// to revalidate so the loader can perform a conditional fetch.
+//
+// Loads are deduplicated per key (singleflight), exactly like GetOrLoadWithContext.
+//
+// Example:
+//
+//	res, err := balios.GetOrRevalidateResult(ctx, cache, url,
+//	    func(ctx context.Context, prev balios.LoadResult[[]byte, string], ok bool) (balios.LoadResult[[]byte, string], error) {
+//	        req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//	        if ok {
+//	            req.Header.Set("If-None-Match", prev.Metadata)
+//	        }
+//	        resp, err := http.DefaultClient.Do(req)
+//	        if err != nil {
+//	            return balios.LoadResult[[]byte, string]{}, err
+//	        }
+//	        defer resp.Body.Close()
+//	        if resp.StatusCode == http.StatusNotModified {
+//	            return balios.LoadResult[[]byte, string]{}, balios.NewErrNotModified(url)
+//	        }
+//	        body, err := io.ReadAll(resp.Body)
+//	        return balios.LoadResult[[]byte, string]{Value: body, Metadata: resp.Header.Get("ETag")}, err
+//	    })
+func GetOrRevalidateResult[K comparable, V any, M any](ctx context.Context, c *GenericCache[K, LoadResult[V, M]], key K, revalidate RevalidateFunc[V, M]) (LoadResult[V, M], error) {
+	if revalidate == nil {
+		return LoadResult[V, M]{}, NewErrInvalidLoader(keyToString(key))
+	}
+
+	if result, found := c.Get(key); found {
+		return result, nil
+	}
+
+	// Miss: the expired entry (if any) may still be readable for revalidation
+	previous, hasPrevious := c.peekStale(key)
+
+	return c.GetOrLoadWithContext(ctx, key, func(ctx context.Context) (LoadResult[V, M], error) {
+		return callRevalidate(ctx, revalidate, previous, hasPrevious)
+	})
+}
+
+// RefreshResult unconditionally revalidates key, passing the currently cached
+// result (fresh or expired) to revalidate. A not-modified answer re-stores the
+// previous result, renewing its TTL; a new result replaces it.
+//
+// Unlike GetOrRevalidateResult, RefreshResult always invokes the loader and
+// does not deduplicate concurrent refreshes of the same key.
+func RefreshResult[K comparable, V any, M any](ctx context.Context, c *GenericCache[K, LoadResult[V, M]], key K, revalidate RevalidateFunc[V, M]) (LoadResult[V, M], error) {
+	if revalidate == nil {
+		return LoadResult[V, M]{}, NewErrInvalidLoader(keyToString(key))
+	}
+	if err := ctx.Err(); err != nil {
+		return LoadResult[V, M]{}, err
+	}
+
+	previous, hasPrevious := c.peekStale(key)
+
+	result, err := callRevalidate(ctx, revalidate, previous, hasPrevious)
+	if err != nil {
+		return LoadResult[V, M]{}, err
+	}
+
+	c.Set(key, result)
+	return result, nil
+}
+
+// callRevalidate invokes revalidate with panic recovery and maps a not-modified
+// answer onto the previous result.
+func callRevalidate[V any, M any](ctx context.Context, revalidate RevalidateFunc[V, M], previous LoadResult[V, M], hasPrevious bool) (result LoadResult[V, M], err error) {
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = NewErrPanicRecovered("Revalidate", r)
+			}
+		}()
+		result, err = revalidate(ctx, previous, hasPrevious)
+	}()
+
+	if err != nil && IsNotModified(err) && hasPrevious {
+		// Previous result is still current: re-storing it renews the TTL
+		return previous, nil
+	}
+	return result, err
+}
@@ -0,0 +1,144 @@
+// revalidate_test.go: tests for conditional revalidation of LoadResult caches
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+package balios
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeOrigin simulates an HTTP origin supporting If-None-Match.
+type fakeOrigin struct {
+	body        string
+	etag        string
+	fullFetches int
+	notModified int
+	sawPrevious []bool
+}
+
+func (o *fakeOrigin) revalidate(ctx context.Context, prev LoadResult[string, string], ok bool) (LoadResult[string, string], error) {
+	o.sawPrevious = append(o.sawPrevious, ok)
+	if ok && prev.Metadata == o.etag {
+		o.notModified++
+		return LoadResult[string, string]{}, NewErrNotModified("resource")
+	}
+	o.fullFetches++
+	return LoadResult[string, string]{Value: o.body, Metadata: o.etag}, nil
+}
+
+func TestGetOrRevalidateResult_NotModifiedRenewsTTL(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewGenericCache[string, LoadResult[string, string]](Config{
+		MaxSize:      100,
+		TTL:          100 * time.Millisecond,
+		TimeProvider: mockTime,
+	})
+	origin := &fakeOrigin{body: "v1", etag: `"1"`}
+	ctx := context.Background()
+
+	res, err := GetOrRevalidateResult(ctx, cache, "resource", origin.revalidate)
+	if err != nil || res.Value != "v1" {
+		t.Fatalf("initial load: %+v, %v", res, err)
+	}
+
+	// Expire the entry: the loader must receive the previous ETag
+	mockTime.Advance(150 * time.Millisecond)
+	res, err = GetOrRevalidateResult(ctx, cache, "resource", origin.revalidate)
+	if err != nil || res.Value != "v1" || res.Metadata != `"1"` {
+		t.Fatalf("revalidation: %+v, %v", res, err)
+	}
+	if origin.fullFetches != 1 || origin.notModified != 1 {
+		t.Errorf("expected 1 full fetch and 1 not-modified, got %d/%d", origin.fullFetches, origin.notModified)
+	}
+	if len(origin.sawPrevious) != 2 || origin.sawPrevious[0] || !origin.sawPrevious[1] {
+		t.Errorf("unexpected previous visibility: %v", origin.sawPrevious)
+	}
+
+	// TTL was renewed: entry is served from cache without calling the origin
+	mockTime.Advance(50 * time.Millisecond)
+	if _, err := GetOrRevalidateResult(ctx, cache, "resource", origin.revalidate); err != nil {
+		t.Fatal(err)
+	}
+	if len(origin.sawPrevious) != 2 {
+		t.Error("renewed entry should have been a cache hit")
+	}
+}
+
+func TestGetOrRevalidateResult_NotModifiedWithoutPrevious(t *testing.T) {
+	cache := NewGenericCache[string, LoadResult[string, string]](Config{MaxSize: 100})
+
+	_, err := GetOrRevalidateResult(context.Background(), cache, "k",
+		func(ctx context.Context, prev LoadResult[string, string], ok bool) (LoadResult[string, string], error) {
+			return LoadResult[string, string]{}, NewErrNotModified("k")
+		})
+	if !IsNotModified(err) {
+		t.Errorf("expected not-modified to surface without a previous value, got %v", err)
+	}
+	if cache.Has("k") {
+		t.Error("nothing should be cached")
+	}
+}
+
+func TestRefreshResult_ReplacesValue(t *testing.T) {
+	cache := NewGenericCache[string, LoadResult[string, string]](Config{MaxSize: 100})
+	origin := &fakeOrigin{body: "v1", etag: `"1"`}
+	ctx := context.Background()
+
+	if _, err := RefreshResult(ctx, cache, "resource", origin.revalidate); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged origin: not modified, previous kept
+	res, err := RefreshResult(ctx, cache, "resource", origin.revalidate)
+	if err != nil || res.Value != "v1" || origin.notModified != 1 {
+		t.Fatalf("refresh unchanged: %+v, %v (notModified=%d)", res, err, origin.notModified)
+	}
+
+	// Changed origin: new value stored
+	origin.body, origin.etag = "v2", `"2"`
+	res, err = RefreshResult(ctx, cache, "resource", origin.revalidate)
+	if err != nil || res.Value != "v2" {
+		t.Fatalf("refresh changed: %+v, %v", res, err)
+	}
+	if cached, _ := cache.Get("resource"); cached.Metadata != `"2"` {
+		t.Errorf("cache not updated: %+v", cached)
+	}
+}
+
+func TestRefreshResult_ErrorsAndPanics(t *testing.T) {
+	cache := NewGenericCache[string, LoadResult[int, int]](Config{MaxSize: 100})
+	ctx := context.Background()
+
+	if _, err := RefreshResult[string, int, int](ctx, cache, "k", nil); GetErrorCode(err) != ErrCodeInvalidLoader {
+		t.Errorf("expected invalid loader error, got %v", err)
+	}
+
+	boom := errors.New("boom")
+	_, err := RefreshResult(ctx, cache, "k", func(ctx context.Context, prev LoadResult[int, int], ok bool) (LoadResult[int, int], error) {
+		return LoadResult[int, int]{}, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("expected loader error, got %v", err)
+	}
+
+	_, err = RefreshResult(ctx, cache, "k", func(ctx context.Context, prev LoadResult[int, int], ok bool) (LoadResult[int, int], error) {
+		panic("revalidate bug")
+	})
+	if GetErrorCode(err) != ErrCodePanicRecovered {
+		t.Errorf("expected panic to be recovered, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := RefreshResult(cancelled, cache, "k", func(ctx context.Context, prev LoadResult[int, int], ok bool) (LoadResult[int, int], error) {
+		t.Error("loader must not run with a cancelled context")
+		return LoadResult[int, int]{}, nil
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// secondary.go: second-level (L2) cache shared by several processes
+//
+// With Config.SecondaryCache, GetOrLoad looks missing keys up in a shared L2
+// before the loader, and writes are written through to it.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"time"
+)
+
+// SecondaryCache is a second-level store shared by several processes (see
+// Config.SecondaryCache). A SecondaryCache is also a SecondaryReader, so it
+// can be used with PrimeFromSecondary. Implementations must be safe for
+// concurrent use.
+type SecondaryCache interface {
+	SecondaryReader
+
+	// Set stores value for key. ttl is the TTL of the entry in the
+	// in-process cache (0 = no expiration).
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// secondaryContext derives the context of a SecondaryCache call from ctx,
+// bounded by Config.SecondaryTimeout.
+func (c *wtinyLFUCache) secondaryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.secondaryTimeout > 0 {
+		return context.WithTimeout(ctx, c.secondaryTimeout)
+	}
+	return ctx, func() {}
+}
+
+// readSecondary looks key up in the SecondaryCache. Errors are logged and
+// reported as a miss.
+func (c *wtinyLFUCache) readSecondary(ctx context.Context, key string) (interface{}, bool) {
+	ctx, cancel := c.secondaryContext(ctx)
+	defer cancel()
+
+	value, found, err := c.secondary.Get(ctx, key)
+	if err != nil {
+		c.logger.Warn("balios: secondary cache read failed", "key", key, "error", err)
+		return nil, false
+	}
+	if !found || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// writeSecondary writes value through to the SecondaryCache.
+func (c *wtinyLFUCache) writeSecondary(key string, value interface{}, ttlNanos int64) {
+	ctx, cancel := c.secondaryContext(context.Background())
+	defer cancel()
+
+	if err := c.secondary.Set(ctx, key, value, time.Duration(ttlNanos)); err != nil {
+		c.logger.Warn("balios: secondary cache write failed", "key", key, "error", err)
+	}
+}
+
+// deleteSecondary deletes key from the SecondaryCache.
+func (c *wtinyLFUCache) deleteSecondary(key string) {
+	ctx, cancel := c.secondaryContext(context.Background())
+	defer cancel()
+
+	if err := c.secondary.Delete(ctx, key); err != nil {
+		c.logger.Warn("balios: secondary cache delete failed", "key", key, "error", err)
+	}
+}
+
+// loadFromSecondary completes the flight of key with its L2 value, if any,
+// and stores that value in this cache only.
+func (c *wtinyLFUCache) loadFromSecondary(ctx context.Context, key, source string, flight *inflightCall, o *loadOptions) (interface{}, bool) {
+	value, found := c.readSecondary(ctx, key)
+	if !found {
+		return nil, false
+	}
+	flight.val.Store(&resultWrapper{value: value})
+	flight.err.Store(&errorWrapper{})
+	c.storeLocal(key, value, source, o)
+	return value, true
+}
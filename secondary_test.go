@@ -0,0 +1,209 @@
+// secondary_test.go: tests for the second-level (L2) cache
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memorySecondary is an in-memory SecondaryCache.
+type memorySecondary struct {
+	mu      sync.Mutex
+	data    map[string]interface{}
+	ttls    map[string]time.Duration
+	failErr error
+	gets    int
+	deletes int
+}
+
+func newMemorySecondary() *memorySecondary {
+	return &memorySecondary{data: map[string]interface{}{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *memorySecondary) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets++
+	if s.failErr != nil {
+		return nil, false, s.failErr
+	}
+	v, ok := s.data[key]
+	return v, ok, nil
+}
+
+func (s *memorySecondary) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failErr != nil {
+		return s.failErr
+	}
+	s.data[key] = value
+	s.ttls[key] = ttl
+	return nil
+}
+
+func (s *memorySecondary) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletes++
+	if s.failErr != nil {
+		return s.failErr
+	}
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memorySecondary) value(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	return v, ok
+}
+
+func TestSecondaryCache_HitSkipsLoader(t *testing.T) {
+	l2 := newMemorySecondary()
+	l2.data["user:1"] = "alice"
+	cache := NewCache(Config{MaxSize: 100, SecondaryCache: l2})
+
+	value, err := cache.GetOrLoad("user:1", func() (interface{}, error) {
+		t.Error("loader must not run on an L2 hit")
+		return nil, nil
+	})
+	if err != nil || value != "alice" {
+		t.Fatalf("expected the L2 value, got %v, %v", value, err)
+	}
+	if v, ok := cache.Get("user:1"); !ok || v != "alice" {
+		t.Errorf("L2 hit should be stored in L1, got %v, %v", v, ok)
+	}
+
+	value, err = cache.GetOrLoadWithContext(context.Background(), "user:1", func(context.Context) (interface{}, error) {
+		t.Error("loader must not run on an L1 hit")
+		return nil, nil
+	})
+	if err != nil || value != "alice" || l2.gets != 1 {
+		t.Errorf("L1 hit must not read L2: %v, %v, %d reads", value, err, l2.gets)
+	}
+}
+
+func TestSecondaryCache_LoadedValuesWrittenThrough(t *testing.T) {
+	l2 := newMemorySecondary()
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Minute, SecondaryCache: l2}).(*wtinyLFUCache)
+
+	if _, err := cache.GetOrLoad("a", func() (interface{}, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetOrLoadWithContext(context.Background(), "b", func(context.Context) (interface{}, error) {
+		return 2, nil
+	}, WithTTL(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetOrLoadMany([]string{"c"}, func([]string) (map[string]interface{}, error) {
+		return map[string]interface{}{"c": 3}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]interface{}{"a": 1, "b": 2, "c": 3} {
+		if v, ok := l2.value(key); !ok || v != want {
+			t.Errorf("%s: expected %v in L2, got %v, %v", key, want, v, ok)
+		}
+	}
+	if l2.ttls["a"] != time.Minute || l2.ttls["b"] != time.Hour {
+		t.Errorf("L2 writes should carry the entry TTL, got %v", l2.ttls)
+	}
+}
+
+func TestSecondaryCache_SetAndDeletePropagate(t *testing.T) {
+	l2 := newMemorySecondary()
+	cache := NewCache(Config{MaxSize: 100, SecondaryCache: l2}).(*wtinyLFUCache)
+
+	cache.Set("a", 1)
+	cache.SetMany(map[string]interface{}{"b": 2, "c": 3})
+	if !cache.CompareAndSwap("a", 1, 10) {
+		t.Fatal("CompareAndSwap failed")
+	}
+	for key, want := range map[string]interface{}{"a": 10, "b": 2, "c": 3} {
+		if v, ok := l2.value(key); !ok || v != want {
+			t.Errorf("%s: expected %v in L2, got %v, %v", key, want, v, ok)
+		}
+	}
+
+	cache.Delete("a")
+	if _, ok := l2.value("a"); ok {
+		t.Error("Delete should remove the key from L2")
+	}
+	cache.Delete("missing") // Deleting from L2 is unconditional
+	if l2.deletes != 2 {
+		t.Errorf("expected 2 L2 deletes, got %d", l2.deletes)
+	}
+
+	// Local-only removals do not touch L2
+	cache.Clear()
+	if _, ok := l2.value("b"); !ok {
+		t.Error("Clear must not empty L2")
+	}
+}
+
+func TestSecondaryCache_ErrorsFallBackToLoader(t *testing.T) {
+	l2 := newMemorySecondary()
+	l2.failErr = errors.New("connection refused")
+	cache := NewCache(Config{MaxSize: 100, SecondaryCache: l2, SecondaryTimeout: 10 * time.Millisecond})
+
+	var loads int32
+	value, err := cache.GetOrLoad("a", func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		return "fresh", nil
+	})
+	if err != nil || value != "fresh" || atomic.LoadInt32(&loads) != 1 {
+		t.Fatalf("an L2 failure should fall back to the loader, got %v, %v", value, err)
+	}
+	if v, ok := cache.Get("a"); !ok || v != "fresh" {
+		t.Errorf("the loaded value should be cached despite the L2 write failure, got %v, %v", v, ok)
+	}
+
+	cache.Set("b", 2)
+	if v, ok := cache.Get("b"); !ok || v != 2 {
+		t.Errorf("Set must succeed when L2 fails, got %v, %v", v, ok)
+	}
+}
+
+func TestSecondaryCache_SharedBetweenCaches(t *testing.T) {
+	l2 := newMemorySecondary()
+	first := NewCache(Config{MaxSize: 100, SecondaryCache: l2})
+	second := NewCache(Config{MaxSize: 100, SecondaryCache: l2})
+
+	first.Set("config", "v1")
+	value, err := second.GetOrLoad("config", func() (interface{}, error) {
+		return "from-backend", nil
+	})
+	if err != nil || value != "v1" {
+		t.Errorf("the second replica should read the value written by the first, got %v, %v", value, err)
+	}
+}
+
+func TestSecondaryCache_Generic(t *testing.T) {
+	l2 := newMemorySecondary()
+	cache := NewGenericCache[int, string](Config{MaxSize: 100, SecondaryCache: l2})
+
+	l2.data["42"] = "answer"
+	value, err := cache.GetOrLoad(42, func() (string, error) {
+		return "", errors.New("loader must not run")
+	})
+	if err != nil || value != "answer" {
+		t.Errorf("expected the L2 value, got %q, %v", value, err)
+	}
+
+	cache.Set(7, "seven")
+	if v, ok := l2.value("7"); !ok || v != "seven" {
+		t.Errorf("generic Set should write through, got %v, %v", v, ok)
+	}
+}
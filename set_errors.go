@@ -0,0 +1,67 @@
+// set_errors.go: SetE, Set with the reason of a failure
+//
+// SetE is Set returning the structured error of a failure instead of false.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+// SetE stores a key-value pair like Set. It returns BALIOS_EMPTY_KEY for an
+// empty key, BALIOS_CACHE_CLOSED after Close, BALIOS_VALUE_TOO_LARGE for a
+// key or value above Config.MaxKeyLen or Config.MaxValueBytes,
+// BALIOS_SET_FAILED for a value that cannot be stored (encoding failure,
+// heavier than MaxWeight) and a retryable BALIOS_CACHE_FULL error when no
+// slot could be claimed.
+func (c *wtinyLFUCache) SetE(key string, value interface{}) error {
+	stored, err := c.prepareSet("SetE", key, value)
+	if err != nil {
+		return err
+	}
+	if !c.setStored(key, stored, "", c.ttlNanos) {
+		if c.isClosed() {
+			return NewErrCacheClosed("SetE")
+		}
+		return NewErrCacheFull(int(c.capacity()), int(c.entryCount()))
+	}
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return nil
+}
+
+// prepareSet validates a write made by operation and returns the value to
+// store (encoded by Config.ValueCodec), or the structured error of Set.
+func (c *wtinyLFUCache) prepareSet(operation, key string, value interface{}) (interface{}, error) {
+	if key == "" {
+		return nil, NewErrEmptyKey(operation)
+	}
+	if c.isClosed() {
+		return nil, NewErrCacheClosed(operation)
+	}
+	stored := value
+	if c.valueCodec != nil {
+		var ok bool
+		if stored, ok = c.encodeValue(key, value); !ok {
+			return nil, NewErrSetFailed(key, "value encoding failed")
+		}
+	}
+	if c.sizeLimited() {
+		if err := c.checkSize(key, stored); err != nil {
+			return nil, err
+		}
+	}
+	if c.maxWeight > 0 {
+		if _, fits := c.weigh(key, stored); !fits {
+			return nil, NewErrSetFailed(key, "value heavier than MaxWeight")
+		}
+	}
+	return stored, nil
+}
+
+// SetE stores a key-value pair like Set, returning the reason of a failure
+// (see Cache.SetE).
+func (c *GenericCache[K, V]) SetE(key K, value V) error {
+	return c.inner.SetE(keyToString(key), value)
+}
@@ -0,0 +1,63 @@
+// set_errors_test.go: tests for SetE
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"testing"
+)
+
+func TestSetE_Success(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 10})
+	defer cache.Close()
+
+	if err := cache.SetE("k", 1); err != nil {
+		t.Fatalf("SetE() error = %v", err)
+	}
+	if value, found := cache.Get("k"); !found || value != 1 {
+		t.Fatalf("Get(k) = %v, %v", value, found)
+	}
+	if stats := cache.Stats(); stats.Sets != 1 {
+		t.Fatalf("Sets = %d, want 1", stats.Sets)
+	}
+}
+
+func TestSetE_Errors(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10, MaxWeight: 4, Weigher: byteWeigher}).(*wtinyLFUCache)
+
+	if err := cache.SetE("", 1); !IsEmptyKey(err) {
+		t.Errorf("empty key: got %v", err)
+	}
+	err := cache.SetE("big", []byte("too heavy"))
+	if GetErrorCode(err) != ErrCodeSetFailed {
+		t.Errorf("heavy value: got %v", err)
+	}
+	if _, found := cache.Get("big"); found {
+		t.Error("heavy value stored")
+	}
+
+	_ = cache.Close()
+	if err := cache.SetE("k", 1); !IsCacheClosed(err) {
+		t.Errorf("closed cache: got %v", err)
+	}
+}
+
+func TestSetE_CacheFull(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 16}).(*wtinyLFUCache)
+	defer cache.Close()
+	holdAllSlots(cache)
+
+	err := cache.SetE("k", 1)
+	if !IsCacheFull(err) || !IsRetryable(err) {
+		t.Fatalf("every slot busy: got %v", err)
+	}
+	if ctx := GetErrorContext(err); ctx["capacity"] != 16 {
+		t.Fatalf("unexpected error context %v", ctx)
+	}
+	if cache.Set("k", 1) {
+		t.Fatal("Set succeeded with every slot busy")
+	}
+}
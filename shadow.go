@@ -0,0 +1,257 @@
+// shadow.go: sampled shadow comparison between the generic and legacy APIs
+//
+// ShadowCache serves from a GenericCache and mirrors a sample of keys into
+// a legacy Cache, reporting where the two diverge.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultShadowSampleRate is the default fraction of keys mirrored by ShadowCache.
+const DefaultShadowSampleRate = 0.01
+
+// shadowStripes is the number of locks serializing mirrored operations.
+const shadowStripes = 64
+
+// DivergenceKind classifies a shadow comparison mismatch.
+type DivergenceKind int
+
+const (
+	// DivergencePresence: the key was found by only one of the two caches.
+	DivergencePresence DivergenceKind = iota + 1
+
+	// DivergenceValue: both caches found the key but returned different values
+	// (or the legacy value does not have the generic value type).
+	DivergenceValue
+)
+
+// String returns the divergence kind name.
+func (k DivergenceKind) String() string {
+	switch k {
+	case DivergencePresence:
+		return "presence"
+	case DivergenceValue:
+		return "value"
+	default:
+		return "unknown"
+	}
+}
+
+// ShadowDivergence describes a mismatch between the generic and legacy caches.
+type ShadowDivergence struct {
+	Kind         DivergenceKind
+	Op           string // "Get" or "Has"
+	Key          string // Key as converted for the legacy API
+	GenericValue interface{}
+	LegacyValue  interface{}
+	GenericFound bool
+	LegacyFound  bool
+}
+
+// ShadowOptions configures NewShadowCache.
+type ShadowOptions[V any] struct {
+	// SampleRate is the fraction of keys mirrored into the legacy cache,
+	// between 0 and 1. Default: DefaultShadowSampleRate.
+	SampleRate float64
+
+	// Equal compares generic and legacy values. Default: reflect.DeepEqual.
+	Equal func(a, b V) bool
+
+	// OnDivergence is called (inline, must be fast) for every divergence.
+	OnDivergence func(ShadowDivergence)
+}
+
+// ShadowStats reports shadow comparison counters.
+type ShadowStats struct {
+	Mirrored            uint64 // Operations mirrored into the legacy cache
+	Compared            uint64 // Reads whose results were compared
+	PresenceDivergences uint64
+	ValueDivergences    uint64
+}
+
+// Divergences returns the total number of divergences.
+func (s ShadowStats) Divergences() uint64 {
+	return s.PresenceDivergences + s.ValueDivergences
+}
+
+// ShadowCache serves operations from a GenericCache and mirrors a sample of
+// keys into a legacy Cache, reporting any divergence between the two.
+//
+// Example:
+//
+//	cache := balios.NewShadowCache[string, User](cfg, balios.ShadowOptions[User]{
+//	    SampleRate:   0.05,
+//	    OnDivergence: func(d balios.ShadowDivergence) { log.Printf("cache divergence: %+v", d) },
+//	})
+type ShadowCache[K comparable, V any] struct {
+	primary   *GenericCache[K, V]
+	legacy    Cache
+	threshold uint64 // Keys with hash below threshold are mirrored
+	all       bool   // SampleRate >= 1
+	equal     func(a, b V) bool
+	onDiverge func(ShadowDivergence)
+	stripes   [shadowStripes]sync.Mutex
+
+	mirrored            uint64
+	compared            uint64
+	presenceDivergences uint64
+	valueDivergences    uint64
+}
+
+// NewShadowCache creates a ShadowCache. The legacy cache uses cfg without its
+// MetricsCollector and event hooks, so shadow traffic is not reported twice.
+func NewShadowCache[K comparable, V any](cfg Config, opts ShadowOptions[V]) *ShadowCache[K, V] {
+	rate := opts.SampleRate
+	if rate <= 0 {
+		rate = DefaultShadowSampleRate
+	}
+
+	legacyCfg := cfg
+	legacyCfg.MetricsCollector = nil
+	legacyCfg.OnEntryEvent = nil
+	legacyCfg.OnEvict = nil
+	legacyCfg.OnExpire = nil
+
+	s := &ShadowCache[K, V]{
+		primary:   NewGenericCache[K, V](cfg),
+		legacy:    NewCache(legacyCfg),
+		all:       rate >= 1,
+		threshold: uint64(rate * math.Exp2(64)), // #nosec G115 -- rate in (0, 1)
+		equal:     opts.Equal,
+		onDiverge: opts.OnDivergence,
+	}
+	if s.equal == nil {
+		s.equal = func(a, b V) bool { return reflect.DeepEqual(a, b) }
+	}
+	return s
+}
+
+// sampled reports whether keyStr is mirrored and returns its stripe lock.
+func (s *ShadowCache[K, V]) sampled(keyStr string) (*sync.Mutex, bool) {
+	h := stringHash(keyStr)
+	if !s.all && h >= s.threshold {
+		return nil, false
+	}
+	return &s.stripes[h%shadowStripes], true
+}
+
+// Set stores value in the primary cache and, for sampled keys, in the legacy cache.
+func (s *ShadowCache[K, V]) Set(key K, value V) {
+	keyStr := keyToString(key)
+	mu, ok := s.sampled(keyStr)
+	if !ok {
+		s.primary.Set(key, value)
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	s.primary.Set(key, value)
+	s.legacy.Set(keyStr, value)
+	atomic.AddUint64(&s.mirrored, 1)
+}
+
+// Get returns the primary cache's result, comparing it with the legacy cache
+// for sampled keys.
+func (s *ShadowCache[K, V]) Get(key K) (V, bool) {
+	keyStr := keyToString(key)
+	mu, ok := s.sampled(keyStr)
+	if !ok {
+		return s.primary.Get(key)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	value, found := s.primary.Get(key)
+	legacyValue, legacyFound := s.legacy.Get(keyStr)
+	atomic.AddUint64(&s.mirrored, 1)
+	atomic.AddUint64(&s.compared, 1)
+
+	switch {
+	case found != legacyFound:
+		s.diverge(ShadowDivergence{Kind: DivergencePresence, Op: "Get", Key: keyStr,
+			GenericValue: value, LegacyValue: legacyValue, GenericFound: found, LegacyFound: legacyFound})
+	case found:
+		if typed, ok := legacyValue.(V); !ok || !s.equal(value, typed) {
+			s.diverge(ShadowDivergence{Kind: DivergenceValue, Op: "Get", Key: keyStr,
+				GenericValue: value, LegacyValue: legacyValue, GenericFound: true, LegacyFound: true})
+		}
+	}
+	return value, found
+}
+
+// Has reports whether key is in the primary cache, comparing presence with
+// the legacy cache for sampled keys.
+func (s *ShadowCache[K, V]) Has(key K) bool {
+	keyStr := keyToString(key)
+	mu, ok := s.sampled(keyStr)
+	if !ok {
+		return s.primary.Has(key)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	found := s.primary.Has(key)
+	legacyFound := s.legacy.Has(keyStr)
+	atomic.AddUint64(&s.mirrored, 1)
+	atomic.AddUint64(&s.compared, 1)
+
+	if found != legacyFound {
+		s.diverge(ShadowDivergence{Kind: DivergencePresence, Op: "Has", Key: keyStr,
+			GenericFound: found, LegacyFound: legacyFound})
+	}
+	return found
+}
+
+// Delete removes key from the primary cache and, for sampled keys, from the legacy cache.
+func (s *ShadowCache[K, V]) Delete(key K) {
+	keyStr := keyToString(key)
+	mu, ok := s.sampled(keyStr)
+	if !ok {
+		s.primary.Delete(key)
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	s.primary.Delete(key)
+	s.legacy.Delete(keyStr)
+	atomic.AddUint64(&s.mirrored, 1)
+}
+
+func (s *ShadowCache[K, V]) diverge(d ShadowDivergence) {
+	if d.Kind == DivergenceValue {
+		atomic.AddUint64(&s.valueDivergences, 1)
+	} else {
+		atomic.AddUint64(&s.presenceDivergences, 1)
+	}
+	if s.onDiverge != nil {
+		s.onDiverge(d)
+	}
+}
+
+// Primary returns the GenericCache serving all operations.
+func (s *ShadowCache[K, V]) Primary() *GenericCache[K, V] {
+	return s.primary
+}
+
+// ShadowStats returns the shadow comparison counters.
+func (s *ShadowCache[K, V]) ShadowStats() ShadowStats {
+	return ShadowStats{
+		Mirrored:            atomic.LoadUint64(&s.mirrored),
+		Compared:            atomic.LoadUint64(&s.compared),
+		PresenceDivergences: atomic.LoadUint64(&s.presenceDivergences),
+		ValueDivergences:    atomic.LoadUint64(&s.valueDivergences),
+	}
+}
+
+// Close closes both caches.
+func (s *ShadowCache[K, V]) Close() error {
+	_ = s.legacy.Close()
+	return s.primary.Close()
+}
@@ -0,0 +1,120 @@
+// shadow_test.go: tests for the generic/legacy shadow comparison
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestShadowCache_NoDivergenceOnConsistentPaths(t *testing.T) {
+	var divergences []ShadowDivergence
+	cache := NewShadowCache[int, []string](Config{MaxSize: 1000}, ShadowOptions[[]string]{
+		SampleRate:   1,
+		OnDivergence: func(d ShadowDivergence) { divergences = append(divergences, d) },
+	})
+	defer func() { _ = cache.Close() }()
+
+	for i := 0; i < 200; i++ {
+		cache.Set(i, []string{strconv.Itoa(i)}) // Non-comparable values use DeepEqual
+	}
+	for i := 0; i < 200; i += 2 {
+		cache.Delete(i)
+	}
+	for i := 0; i < 250; i++ {
+		v, found := cache.Get(i)
+		if want := i%2 == 1 && i < 200; found != want || (found && v[0] != strconv.Itoa(i)) {
+			t.Fatalf("Get(%d) = %v, %v", i, v, found)
+		}
+		cache.Has(i)
+	}
+
+	stats := cache.ShadowStats()
+	if len(divergences) != 0 || stats.Divergences() != 0 {
+		t.Errorf("unexpected divergences: %+v", divergences)
+	}
+	if stats.Compared != 500 || stats.Mirrored != 800 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestShadowCache_ReportsDivergence(t *testing.T) {
+	var got []ShadowDivergence
+	cache := NewShadowCache[string, int](Config{MaxSize: 100}, ShadowOptions[int]{
+		SampleRate:   1,
+		OnDivergence: func(d ShadowDivergence) { got = append(got, d) },
+	})
+
+	// Simulate a legacy-path bug: the two caches disagree
+	cache.Set("value", 1)
+	cache.legacy.Set("value", 2)
+	cache.Set("type", 1)
+	cache.legacy.Set("type", "one")
+	cache.Set("presence", 1)
+	cache.legacy.Delete("presence")
+
+	cache.Get("value")
+	cache.Get("type")
+	cache.Has("presence")
+
+	stats := cache.ShadowStats()
+	if stats.ValueDivergences != 2 || stats.PresenceDivergences != 1 || len(got) != 3 {
+		t.Fatalf("unexpected divergences: %+v %+v", stats, got)
+	}
+	if got[0].Kind != DivergenceValue || got[0].Key != "value" || got[0].GenericValue != 1 || got[0].LegacyValue != 2 {
+		t.Errorf("unexpected value divergence: %+v", got[0])
+	}
+	if got[2].Kind.String() != "presence" || !got[2].GenericFound || got[2].LegacyFound || got[2].Op != "Has" {
+		t.Errorf("unexpected presence divergence: %+v", got[2])
+	}
+}
+
+func TestShadowCache_Sampling(t *testing.T) {
+	cache := NewShadowCache[string, int](Config{MaxSize: 100_000}, ShadowOptions[int]{SampleRate: 0.1})
+
+	const n = 20_000
+	for i := 0; i < n; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	mirrored := cache.ShadowStats().Mirrored
+	if mirrored < n/20 || mirrored > n/5 {
+		t.Errorf("expected ~10%% of keys mirrored, got %d/%d", mirrored, n)
+	}
+	if cache.legacy.Len() != int(mirrored) {
+		t.Errorf("legacy cache holds %d keys, mirrored %d", cache.legacy.Len(), mirrored)
+	}
+	if cache.Primary().Len() != n {
+		t.Errorf("primary must hold every key, got %d", cache.Primary().Len())
+	}
+
+	if NewShadowCache[string, int](Config{}, ShadowOptions[int]{}).threshold == 0 {
+		t.Error("default sample rate not applied")
+	}
+}
+
+func TestShadowCache_ConcurrentWritersDoNotDiverge(t *testing.T) {
+	cache := NewShadowCache[string, int](Config{MaxSize: 1000}, ShadowOptions[int]{SampleRate: 1})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := "key" + strconv.Itoa(i%16)
+				cache.Set(key, g*i)
+				cache.Get(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if d := cache.ShadowStats().Divergences(); d != 0 {
+		t.Errorf("mirrored operations must not diverge under concurrency, got %d", d)
+	}
+}
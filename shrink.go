@@ -0,0 +1,119 @@
+// shrink.go: gradual memory release after traffic bursts
+//
+// When Config.ShrinkAfter is set, a table that stayed below
+// Config.ShrinkThreshold is compacted a chunk at a time.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultShrinkThreshold is the occupancy (Len/Capacity) below which the
+	// shrinker starts counting idle time.
+	DefaultShrinkThreshold = 0.25
+
+	// shrinkTicksPerPeriod is the number of occupancy samples per ShrinkAfter.
+	shrinkTicksPerPeriod = 8
+
+	// shrinkChunkSlots is the number of slots compacted per tick.
+	shrinkChunkSlots = 4096
+)
+
+// shrinker tracks the state of the background shrink loop.
+type shrinker struct {
+	threshold float64
+	lowTicks  int // Consecutive ticks with occupancy below threshold
+	cursor    int // Next slot to compact; 0 when no pass is running
+	running   bool
+	report    CompactionReport // Accumulated over the current pass
+}
+
+// shrinkTick samples occupancy and, once it has been low for a full period,
+// compacts the next chunk. It returns true when a pass completes.
+func (c *wtinyLFUCache) shrinkTick(s *shrinker) bool {
+	t := c.table.Load()
+	if t == nil {
+		return false // Closed
+	}
+	occupancy := float64(t.size()) / float64(c.capacity())
+	if occupancy >= s.threshold {
+		*s = shrinker{threshold: s.threshold}
+		return false
+	}
+
+	if !s.running {
+		s.lowTicks++
+		if s.lowTicks < shrinkTicksPerPeriod {
+			return false
+		}
+		s.running = true
+		s.report = CompactionReport{Compacted: true}
+	}
+
+	end := s.cursor + shrinkChunkSlots
+	if end > len(t.entries) {
+		end = len(t.entries)
+	}
+	c.compactRange(t, s.cursor, end, true, &s.report)
+	s.cursor = end
+	if s.cursor < len(t.entries) {
+		return false
+	}
+
+	atomic.AddInt64(&c.shrinkPasses, 1)
+	atomic.AddInt64(&c.shrinkReleasedSlots, int64(s.report.ReleasedSlots))
+	c.logger.Debug("balios: shrink pass completed",
+		"released_slots", s.report.ReleasedSlots,
+		"retained_bytes", s.report.RetainedBytes(),
+		"occupancy", occupancy,
+	)
+	*s = shrinker{threshold: s.threshold} // Next pass after another idle period
+	return true
+}
+
+// runShrinker samples occupancy shrinkTicksPerPeriod times per period until
+// Close is called.
+func (c *wtinyLFUCache) runShrinker(period time.Duration, threshold float64) {
+	interval := period / shrinkTicksPerPeriod
+	if interval <= 0 {
+		interval = period
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s := shrinker{threshold: threshold}
+	for {
+		select {
+		case <-c.stopShrinker:
+			return
+		case <-ticker.C:
+			c.shrinkTick(&s)
+		}
+	}
+}
+
+// ShrinkStats reports the activity of the background shrinker.
+type ShrinkStats struct {
+	Passes        int64 // Completed compaction passes
+	ReleasedSlots int64 // Dead slots whose key/value references were dropped
+}
+
+// ShrinkStats returns the background shrinker counters (zero if disabled).
+func (c *wtinyLFUCache) ShrinkStats() ShrinkStats {
+	return ShrinkStats{
+		Passes:        atomic.LoadInt64(&c.shrinkPasses),
+		ReleasedSlots: atomic.LoadInt64(&c.shrinkReleasedSlots),
+	}
+}
+
+// ShrinkStats returns the background shrinker counters (zero if disabled).
+func (c *GenericCache[K, V]) ShrinkStats() ShrinkStats {
+	return c.inner.ShrinkStats()
+}
@@ -0,0 +1,112 @@
+// shrink_test.go: tests for gradual memory release after traffic bursts
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fillAndDrain simulates a burst: the cache fills up, then most keys are deleted.
+func fillAndDrain(c Cache, n int) {
+	value := strings.Repeat("v", 256)
+	for i := 0; i < n; i++ {
+		c.Set("key"+strconv.Itoa(i), value+strconv.Itoa(i))
+	}
+	for i := 0; i < n; i++ {
+		if i%10 != 0 {
+			c.Delete("key" + strconv.Itoa(i))
+		}
+	}
+}
+
+func TestShrinkTick_ReleasesAfterIdlePeriod(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10_000}).(*wtinyLFUCache)
+	fillAndDrain(cache, 10_000)
+
+	before := cache.CompactionReport()
+	if before.DeadSlots == 0 {
+		t.Fatal("burst should leave dead slots")
+	}
+
+	s := shrinker{threshold: DefaultShrinkThreshold}
+	for i := 0; i < shrinkTicksPerPeriod-1; i++ {
+		if cache.shrinkTick(&s) || s.running {
+			t.Fatal("shrinking must not start before the idle period elapsed")
+		}
+	}
+
+	ticks := 0
+	for !cache.shrinkTick(&s) {
+		ticks++
+		if ticks > len(cache.table.Load().entries) {
+			t.Fatal("shrink pass never completed")
+		}
+	}
+	if want := (len(cache.table.Load().entries) + shrinkChunkSlots - 1) / shrinkChunkSlots; ticks+1 != want {
+		t.Errorf("expected the pass to be spread over %d ticks, took %d", want, ticks+1)
+	}
+
+	after := cache.CompactionReport()
+	if after.RetainedBytes() != 0 || after.LiveEntries != 1000 {
+		t.Errorf("retained memory not released: %+v", after)
+	}
+	if stats := cache.ShrinkStats(); stats.Passes != 1 || stats.ReleasedSlots != int64(before.DeadSlots) {
+		t.Errorf("unexpected shrink stats: %+v (dead slots before: %d)", stats, before.DeadSlots)
+	}
+	for i := 0; i < 10_000; i += 10 {
+		if _, ok := cache.Get("key" + strconv.Itoa(i)); !ok {
+			t.Fatalf("live key%d lost by shrinking", i)
+		}
+	}
+}
+
+func TestShrinkTick_BusyCacheResetsTimer(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	s := shrinker{threshold: 0.5}
+
+	for i := 0; i < shrinkTicksPerPeriod-1; i++ {
+		cache.shrinkTick(&s)
+	}
+	for i := 0; i < 60; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	cache.shrinkTick(&s)
+	if s.lowTicks != 0 || s.running {
+		t.Errorf("high occupancy must reset the idle timer: %+v", s)
+	}
+}
+
+func TestShrinker_Background(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 5000, ShrinkAfter: 40 * time.Millisecond}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	fillAndDrain(cache, 5000)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for cache.ShrinkStats().Passes == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("background shrinker did not run")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if report := cache.CompactionReport(); report.RetainedKeyBytes != 0 {
+		t.Errorf("expected dead keys released, got %+v", report)
+	}
+}
+
+func TestConfig_ShrinkThresholdDefault(t *testing.T) {
+	for _, threshold := range []float64{-1, 0, 1, 2} {
+		cfg := Config{ShrinkThreshold: threshold}
+		_ = cfg.Validate()
+		if cfg.ShrinkThreshold != DefaultShrinkThreshold {
+			t.Errorf("threshold %v: expected default, got %v", threshold, cfg.ShrinkThreshold)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// simulate.go: hit-ratio simulation for capacity planning
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package simulate projects the hit ratio of balios caches of different sizes
+// on a recorded access trace, so production caches can be sized without
+// trial-and-error redeploys.
+//
+// Every key of the trace is looked up in one real balios cache per candidate
+// MaxSize and stored on a miss (the cache-aside pattern), so the projection
+// includes the W-TinyLFU admission and eviction decisions of the library
+// itself, not a model of them:
+//
+//	f, _ := os.Open("keys.log") // one key per line
+//	report, err := simulate.RunReader(f, simulate.Config{
+//	    Sizes: []int{10_000, 50_000, 100_000, 500_000},
+//	})
+//	for _, r := range report.Results {
+//	    fmt.Printf("%8d entries: %.1f%%\n", r.MaxSize, r.HitRatio)
+//	}
+//
+// A Simulator can also run as a ghost cache next to a production cache: feed
+// it the keys requested in production and read the projected hit ratios of
+// larger or smaller sizes at any time.
+//
+// The simulation has no notion of time: entries never expire, and the
+// results are deterministic for a given trace and Config.
+package simulate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/agilira/balios"
+)
+
+// maxTraceLine bounds the length of a key read by RunReader.
+const maxTraceLine = 1 << 20
+
+// Config selects the cache sizes to simulate and the policy settings shared
+// by all of them.
+type Config struct {
+	// Sizes are the MaxSize values to simulate. At least one is required.
+	Sizes []int
+
+	// WindowRatio, AdaptiveWindow, CounterBits and AdmissionPolicy are
+	// passed to every simulated cache (see balios.Config).
+	WindowRatio     float64
+	AdaptiveWindow  bool
+	CounterBits     int
+	AdmissionPolicy balios.AdmissionPolicy
+}
+
+// Result is the projected behavior of one cache size.
+type Result struct {
+	MaxSize   int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+
+	// HitRatio is Hits / (Hits + Misses) as a percentage (0-100).
+	HitRatio float64
+}
+
+// Report is the outcome of a simulation.
+type Report struct {
+	// Requests is the number of keys replayed.
+	Requests uint64
+
+	// UniqueKeys is the number of distinct keys in the trace: their first
+	// requests miss in a cache of any size (compulsory misses).
+	UniqueKeys uint64
+
+	// MaxHitRatio is the hit ratio of an unbounded cache, the upper bound
+	// of every Result (percentage, 0-100).
+	MaxHitRatio float64
+
+	// Results holds one entry per Config.Sizes value, in the same order.
+	Results []Result
+}
+
+// Simulator replays accesses against one cache per simulated size. It is
+// safe for concurrent use; accesses are serialized. Besides the simulated
+// caches it keeps the set of distinct keys, so its memory grows with the key
+// space of the trace.
+type Simulator struct {
+	mu       sync.Mutex
+	sizes    []int
+	caches   []balios.Cache
+	seen     map[string]struct{}
+	requests uint64
+}
+
+// fixedClock makes the eviction sampling of the simulated caches
+// deterministic: their random generator is seeded from the clock.
+type fixedClock struct{}
+
+func (fixedClock) Now() int64 { return 0x5DEECE66D }
+
+// New returns a Simulator for cfg. It returns a BALIOS_INVALID_CONFIG error
+// if cfg.Sizes is empty and a BALIOS_INVALID_MAX_SIZE error for a size that
+// is not positive.
+func New(cfg Config) (*Simulator, error) {
+	if len(cfg.Sizes) == 0 {
+		return nil, balios.NewErrInvalidConfig("Sizes", "at least one size is required")
+	}
+	for _, size := range cfg.Sizes {
+		if size <= 0 {
+			return nil, balios.NewErrInvalidMaxSize(size)
+		}
+	}
+	s := &Simulator{
+		sizes: append([]int(nil), cfg.Sizes...),
+		seen:  make(map[string]struct{}),
+	}
+	for _, size := range cfg.Sizes {
+		s.caches = append(s.caches, balios.NewCache(balios.Config{
+			MaxSize:         size,
+			WindowRatio:     cfg.WindowRatio,
+			AdaptiveWindow:  cfg.AdaptiveWindow,
+			CounterBits:     cfg.CounterBits,
+			AdmissionPolicy: cfg.AdmissionPolicy,
+			TimeProvider:    fixedClock{},
+		}))
+	}
+	return s, nil
+}
+
+// Access replays a request for key: a lookup in every simulated cache,
+// followed by a store where it missed. Empty keys, and accesses after Close,
+// are ignored.
+func (s *Simulator) Access(key string) {
+	if key == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		return // Closed
+	}
+
+	s.requests++
+	if _, ok := s.seen[key]; !ok {
+		s.seen[key] = struct{}{}
+	}
+	for _, cache := range s.caches {
+		if _, found := cache.Get(key); !found {
+			cache.Set(key, struct{}{})
+		}
+	}
+}
+
+// Report returns the projections for the accesses replayed so far.
+func (s *Simulator) Report() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := Report{
+		Requests:   s.requests,
+		UniqueKeys: uint64(len(s.seen)),
+		Results:    make([]Result, len(s.caches)),
+	}
+	if s.requests > 0 {
+		report.MaxHitRatio = float64(s.requests-report.UniqueKeys) / float64(s.requests) * 100
+	}
+	for i, cache := range s.caches {
+		stats := cache.Stats()
+		report.Results[i] = Result{
+			MaxSize:   s.sizes[i],
+			Hits:      stats.Hits,
+			Misses:    stats.Misses,
+			Evictions: stats.Evictions,
+			HitRatio:  stats.HitRatio(),
+		}
+	}
+	return report
+}
+
+// Close releases the simulated caches. Report returns no results afterwards.
+func (s *Simulator) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cache := range s.caches {
+		_ = cache.Close()
+	}
+	s.caches, s.seen = nil, nil
+	return nil
+}
+
+// Run replays keys and returns the projected hit ratios.
+func Run(keys []string, cfg Config) (Report, error) {
+	s, err := New(cfg)
+	if err != nil {
+		return Report{}, err
+	}
+	defer func() { _ = s.Close() }()
+
+	for _, key := range keys {
+		s.Access(key)
+	}
+	return s.Report(), nil
+}
+
+// RunReader replays a trace of one key per line (blank lines are skipped)
+// and returns the projected hit ratios. The trace is streamed, not loaded in
+// memory; the Simulator keeps the set of distinct keys.
+func RunReader(r io.Reader, cfg Config) (Report, error) {
+	s, err := New(cfg)
+	if err != nil {
+		return Report{}, err
+	}
+	defer func() { _ = s.Close() }()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTraceLine)
+	for scanner.Scan() {
+		s.Access(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return Report{}, fmt.Errorf("simulate: reading trace: %w", err)
+	}
+	return s.Report(), nil
+}
@@ -0,0 +1,147 @@
+// simulate_test.go: tests for the hit-ratio simulator
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package simulate
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agilira/balios"
+)
+
+// zipfTrace returns n requests over keys with a Zipf popularity.
+func zipfTrace(n int, keys uint64) []string {
+	zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, keys-1) // #nosec G404 -- reproducible test data
+	trace := make([]string, n)
+	for i := range trace {
+		trace[i] = "k" + strconv.FormatUint(zipf.Uint64(), 10)
+	}
+	return trace
+}
+
+func TestRun_ProjectsHitRatios(t *testing.T) {
+	trace := zipfTrace(50_000, 5_000)
+	report, err := Run(trace, Config{Sizes: []int{50, 500, 10_000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Requests != 50_000 || report.UniqueKeys == 0 || len(report.Results) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	small, medium, large := report.Results[0], report.Results[1], report.Results[2]
+	if small.MaxSize != 50 || large.MaxSize != 10_000 {
+		t.Fatalf("results out of order: %+v", report.Results)
+	}
+	if !(small.HitRatio < medium.HitRatio && medium.HitRatio <= large.HitRatio) {
+		t.Fatalf("hit ratio does not grow with size: %.1f, %.1f, %.1f", small.HitRatio, medium.HitRatio, large.HitRatio)
+	}
+	if small.Evictions == 0 {
+		t.Fatal("the small cache never evicted")
+	}
+
+	// Larger than the key space: only compulsory misses
+	if large.Misses != report.UniqueKeys || large.HitRatio != report.MaxHitRatio {
+		t.Fatalf("unbounded cache: misses %d (unique %d), hit ratio %.2f (max %.2f)",
+			large.Misses, report.UniqueKeys, large.HitRatio, report.MaxHitRatio)
+	}
+	for _, r := range report.Results {
+		if r.Hits+r.Misses != report.Requests || r.HitRatio > report.MaxHitRatio {
+			t.Fatalf("inconsistent result %+v", r)
+		}
+	}
+}
+
+func TestRun_Deterministic(t *testing.T) {
+	trace := zipfTrace(20_000, 2_000)
+	cfg := Config{Sizes: []int{100, 300}, AdaptiveWindow: true}
+	first, err := Run(trace, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := Run(trace, cfg)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("two runs differ:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestRunReader_MatchesRun(t *testing.T) {
+	trace := zipfTrace(5_000, 500)
+	cfg := Config{Sizes: []int{64}}
+
+	want, _ := Run(trace, cfg)
+	got, err := RunReader(strings.NewReader(strings.Join(trace, "\n\n")+"\n"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RunReader = %+v, Run = %+v", got, want)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("disk error") }
+
+func TestRunReader_ReadError(t *testing.T) {
+	if _, err := RunReader(failingReader{}, Config{Sizes: []int{10}}); err == nil || !strings.Contains(err.Error(), "disk error") {
+		t.Fatalf("expected the read error, got %v", err)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	if _, err := New(Config{}); !balios.IsConfigError(err) {
+		t.Fatalf("no sizes: expected a config error, got %v", err)
+	}
+	if _, err := New(Config{Sizes: []int{10, 0}}); !balios.IsConfigError(err) {
+		t.Fatalf("zero size: expected a config error, got %v", err)
+	}
+}
+
+func TestSimulator_Ghost(t *testing.T) {
+	s, err := New(Config{Sizes: []int{10, 1_000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 1_000; i++ {
+				s.Access("k" + strconv.Itoa((i*7+w)%100))
+			}
+		}(w)
+	}
+	wg.Wait()
+	s.Access("") // Ignored
+
+	report := s.Report()
+	if report.Requests != 4_000 || report.UniqueKeys != 100 {
+		t.Fatalf("requests %d, unique %d", report.Requests, report.UniqueKeys)
+	}
+	if report.Results[1].HitRatio != report.MaxHitRatio {
+		t.Fatalf("large ghost hit ratio %.2f, want %.2f", report.Results[1].HitRatio, report.MaxHitRatio)
+	}
+}
+
+func TestSimulator_Close(t *testing.T) {
+	s, _ := New(Config{Sizes: []int{10}})
+	s.Access("a")
+	_ = s.Close()
+	s.Access("b") // Must not panic
+	if report := s.Report(); len(report.Results) != 0 {
+		t.Fatalf("results after Close: %+v", report)
+	}
+}
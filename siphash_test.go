@@ -0,0 +1,79 @@
+// siphash_test.go: tests for the SipHash key hasher
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSipHasher_ReferenceVectors(t *testing.T) {
+	// Vectors of the SipHash paper: key 00..0f, message 00..(n-1)
+	var key [16]byte
+	for i := range key {
+		key[i] = byte(i)
+	}
+	hasher := NewSipHasher(key)
+
+	vectors := map[int]uint64{
+		0:  0x726fdb47dd0e0e31,
+		1:  0x74f839c593dc67fd,
+		7:  0xab0200f58b01d137,
+		8:  0x93f5f5799a932462,
+		15: 0xa129ca6149be45e5,
+		63: 0x958a324ceb064572,
+	}
+	for n, want := range vectors {
+		msg := make([]byte, n)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+		if got := hasher(string(msg)); got != want {
+			t.Errorf("SipHash-2-4 of %d bytes = %#x, want %#x", n, got, want)
+		}
+	}
+}
+
+func TestRandomSipHasher_Keyed(t *testing.T) {
+	a, b := NewRandomSipHasher(), NewRandomSipHasher()
+	if a("key") != a("key") {
+		t.Fatal("hasher is not deterministic")
+	}
+	if a("key") == b("key") && a("other") == b("other") {
+		t.Error("two random hashers computed the same hashes")
+	}
+}
+
+func TestSipHasher_Cache(t *testing.T) {
+	cache := NewGenericCache[int, int](Config{MaxSize: 1000, KeyHasher: NewRandomSipHasher()})
+	defer cache.Close()
+
+	for i := 0; i < 1000; i++ {
+		cache.Set(i, i)
+	}
+	for i := 0; i < 1000; i++ {
+		if v, found := cache.Get(i); !found || v != i {
+			t.Fatalf("Get(%d) = %v, %v", i, v, found)
+		}
+	}
+	cache.Delete(42)
+	if cache.Has(42) {
+		t.Error("Delete through the SipHash table failed")
+	}
+}
+
+func BenchmarkSipHasher(b *testing.B) {
+	hasher := NewRandomSipHasher()
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "user:session:" + strconv.Itoa(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = hasher(keys[i&1023])
+	}
+}
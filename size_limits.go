@@ -0,0 +1,65 @@
+// size_limits.go: upper bounds on key and value sizes (Config.MaxKeyLen, MaxValueBytes)
+//
+// Config.MaxKeyLen and Config.MaxValueBytes reject oversized writes with
+// BALIOS_VALUE_TOO_LARGE.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// maxErrorKeyLength bounds the key prefix kept by NewErrValueTooLarge.
+const maxErrorKeyLength = 64
+
+// truncateKey bounds a key quoted in an error to maxErrorKeyLength bytes.
+func truncateKey(key string) string {
+	if len(key) > maxErrorKeyLength {
+		return key[:maxErrorKeyLength]
+	}
+	return key
+}
+
+// valueBytes returns the size in bytes of a value to store (encoded by the
+// ValueCodec, if any), or false if it cannot be measured.
+func (c *wtinyLFUCache) valueBytes(key string, stored interface{}) (int64, bool) {
+	switch v := stored.(type) {
+	case encodedValue:
+		return int64(len(v)), true
+	case arenaValue:
+		return int64(v.n), true
+	}
+	if c.weigher != nil {
+		return c.weigher(key, stored), true
+	}
+	switch v := stored.(type) {
+	case []byte:
+		return int64(len(v)), true
+	case string:
+		return int64(len(v)), true
+	}
+	return 0, false
+}
+
+// checkSize returns BALIOS_VALUE_TOO_LARGE if key or stored (encoded by the
+// ValueCodec, if any) exceeds Config.MaxKeyLen or Config.MaxValueBytes.
+func (c *wtinyLFUCache) checkSize(key string, stored interface{}) error {
+	if c.maxKeyLen > 0 && len(key) > c.maxKeyLen {
+		atomic.AddInt64(&c.rejectedTooLarge, 1)
+		return NewErrValueTooLarge(key, "key", int64(len(key)), int64(c.maxKeyLen))
+	}
+	if c.maxValueBytes > 0 {
+		if size, ok := c.valueBytes(key, stored); ok && size > c.maxValueBytes {
+			atomic.AddInt64(&c.rejectedTooLarge, 1)
+			return NewErrValueTooLarge(key, "value", size, c.maxValueBytes)
+		}
+	}
+	return nil
+}
+
+// sizeLimited reports whether writes are checked by checkSize.
+func (c *wtinyLFUCache) sizeLimited() bool {
+	return c.maxKeyLen > 0 || c.maxValueBytes > 0
+}
@@ -0,0 +1,96 @@
+// size_limits_test.go: tests for Config.MaxKeyLen and Config.MaxValueBytes
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+func TestSizeLimits_Key(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxKeyLen: 16}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	long := strings.Repeat("k", 1<<20)
+	if cache.Set(long, 1) || cache.Has(long) {
+		t.Fatal("a key above MaxKeyLen must not be stored")
+	}
+	err := cache.SetE(long, 1)
+	if !IsValueTooLarge(err) {
+		t.Fatalf("expected BALIOS_VALUE_TOO_LARGE, got %v", err)
+	}
+	var coded *goerrors.Error
+	if !errors.As(err, &coded) || coded.Context["field"] != "key" || len(coded.Context["key"].(string)) > maxErrorKeyLength {
+		t.Fatalf("unexpected error context %+v", coded)
+	}
+	if !cache.Set(strings.Repeat("k", 16), 1) {
+		t.Fatal("a key of MaxKeyLen bytes must be stored")
+	}
+	if got := cache.Stats().RejectedTooLarge; got != 2 {
+		t.Fatalf("RejectedTooLarge = %d, want 2", got)
+	}
+}
+
+func TestSizeLimits_Value(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxValueBytes: 1024}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	big := make([]byte, 2048)
+	if cache.Set("bytes", big) || cache.SetIfAbsent("bytes", big) {
+		t.Fatal("a []byte above MaxValueBytes must not be stored")
+	}
+	if cache.Set("string", string(big)) {
+		t.Fatal("a string above MaxValueBytes must not be stored")
+	}
+	if err := cache.SetE("bytes", big); !IsValueTooLarge(err) {
+		t.Fatalf("expected BALIOS_VALUE_TOO_LARGE, got %v", err)
+	}
+	if !cache.Set("small", big[:1024]) {
+		t.Fatal("a value of MaxValueBytes must be stored")
+	}
+	if cache.CompareAndSwap("small", big[:1024], big) {
+		t.Fatal("CompareAndSwap must not store a value above MaxValueBytes")
+	}
+	if _, loaded := cache.Swap("small", big); loaded {
+		t.Fatal("Swap must not store a value above MaxValueBytes")
+	}
+	if v, _ := cache.Get("small"); len(v.([]byte)) != 1024 {
+		t.Fatal("rejected writes must leave the cached value in place")
+	}
+	if !cache.Set("struct", struct{ Big [4096]byte }{}) {
+		t.Fatal("values that cannot be measured must not be checked")
+	}
+
+	v, err := cache.GetOrLoad("loaded", func() (interface{}, error) { return big, nil })
+	if err != nil || len(v.([]byte)) != 2048 {
+		t.Fatalf("GetOrLoad must return the loaded value, got %v", err)
+	}
+	if cache.Has("loaded") {
+		t.Fatal("a loaded value above MaxValueBytes must not be cached")
+	}
+}
+
+func TestSizeLimits_WeigherAndCodec(t *testing.T) {
+	weighed := NewCache(Config{
+		MaxSize:       100,
+		MaxValueBytes: 100,
+		Weigher:       func(_ string, value interface{}) int64 { return int64(value.(int)) },
+	})
+	defer weighed.Close()
+	if weighed.Set("heavy", 101) || !weighed.Set("light", 100) {
+		t.Fatal("values must be measured by the Weigher")
+	}
+
+	encoded := NewCache(Config{MaxSize: 100, MaxValueBytes: 64, ValueCodec: newFlateCodec(0)})
+	defer encoded.Close()
+	if !encoded.Set("compressible", strings.Repeat("a", 4096)) {
+		t.Fatal("values must be measured in their encoded form")
+	}
+}
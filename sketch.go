@@ -7,6 +7,8 @@
 package balios
 
 import (
+	"encoding/binary"
+	"math/bits"
 	"sync/atomic"
 	"unsafe"
 )
@@ -117,6 +119,21 @@ func (s *frequencySketch) incrementCounter(tablePos, subPos uint64) {
 	}
 }
 
+// incrementBy increments the counters of keyHash n times, without counting
+// toward aging. Used to carry frequencies over to a new sketch.
+func (s *frequencySketch) incrementBy(keyHash, n uint64) {
+	pos1 := s.hash1(keyHash) & s.tableMask
+	pos2 := s.hash2(keyHash) & s.tableMask
+	pos3 := s.hash3(keyHash) & s.tableMask
+	pos4 := s.hash4(keyHash) & s.tableMask
+	for ; n > 0; n-- {
+		s.incrementCounter(pos1, (keyHash&0xF)*4)
+		s.incrementCounter(pos2, ((keyHash>>4)&0xF)*4)
+		s.incrementCounter(pos3, ((keyHash>>8)&0xF)*4)
+		s.incrementCounter(pos4, ((keyHash>>12)&0xF)*4)
+	}
+}
+
 // estimate returns the estimated frequency for the given key.
 // Returns the minimum of the 4 hash positions (Count-Min Sketch property).
 func (s *frequencySketch) estimate(keyHash uint64) uint64 {
@@ -196,9 +213,27 @@ func min4(a, b, c, d uint64) uint64 {
 	return min
 }
 
-// stringHash computes a 64-bit hash of a string using FNV-1a algorithm.
-// This is optimized for performance and zero allocations.
+// wideHashThreshold is the key length above which stringHash switches from
+// byte-at-a-time FNV-1a to an 8-byte-wide loop.
+//
+// DESIGN RATIONALE:
+//   - FNV-1a is hard to beat on short keys (no setup, no finalization)
+//   - Its serial dependency chain costs ~1ns per byte, so 300-byte keys spend
+//     most of Get/Set inside the hash function
+//   - Above 64 bytes the xxHash64 stripe loop (4 independent 64-bit lanes over
+//     32-byte stripes) is ~3x faster at 300 bytes and ~4x at 1KB, with
+//     excellent avalanche behavior
+//   - Keys up to 64 bytes keep the exact FNV-1a values used so far
+const wideHashThreshold = 64
+
+// stringHash computes a 64-bit hash of a string.
+// Keys up to wideHashThreshold bytes use FNV-1a; longer keys use the xxHash64
+// algorithm (seed 0). Both paths are zero-allocation.
 func stringHash(s string) uint64 {
+	if len(s) > wideHashThreshold {
+		return stringHashWide(s)
+	}
+
 	const (
 		fnv64Offset = 14695981039346656037
 		fnv64Prime  = 1099511628211
@@ -217,3 +252,88 @@ func stringHash(s string) uint64 {
 
 	return hash
 }
+
+// xxHash64 primes
+const (
+	xxPrime1 uint64 = 0x9E3779B185EBCA87
+	xxPrime2 uint64 = 0xC2B2AE3D27D4EB4F
+	xxPrime3 uint64 = 0x165667B19E3779F9
+	xxPrime4 uint64 = 0x85EBCA77C2B2AE63
+	xxPrime5 uint64 = 0x27D4EB2F165667C5
+)
+
+// stringHashWide computes the xxHash64 (seed 0) of s, processing 32 bytes per
+// iteration across 4 independent lanes so the CPU can overlap the multiplies.
+// Used by stringHash for keys longer than wideHashThreshold.
+func stringHashWide(s string) uint64 {
+	// #nosec G103 - Safe usage: we only read the string data, no writes or pointer arithmetic
+	data := unsafe.Slice(unsafe.StringData(s), len(s))
+	n := len(data)
+
+	var h uint64
+	if n >= 32 {
+		// Lane seeds wrap around modulo 2^64 (computed at runtime, not as constants)
+		prime1 := xxPrime1
+		v1 := prime1 + xxPrime2
+		v2 := xxPrime2
+		v3 := uint64(0)
+		v4 := 0 - prime1
+
+		for len(data) >= 32 {
+			v1 = xxRound(v1, binary.LittleEndian.Uint64(data[0:8]))
+			v2 = xxRound(v2, binary.LittleEndian.Uint64(data[8:16]))
+			v3 = xxRound(v3, binary.LittleEndian.Uint64(data[16:24]))
+			v4 = xxRound(v4, binary.LittleEndian.Uint64(data[24:32]))
+			data = data[32:]
+		}
+
+		h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) +
+			bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		h = xxMergeRound(h, v1)
+		h = xxMergeRound(h, v2)
+		h = xxMergeRound(h, v3)
+		h = xxMergeRound(h, v4)
+	} else {
+		h = xxPrime5
+	}
+
+	h += uint64(n) // #nosec G115 - length is non-negative
+
+	// Tail: 8 bytes, then 4 bytes, then single bytes
+	for len(data) >= 8 {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(data[:8]))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+		data = data[8:]
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data[:4])) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		data = data[4:]
+	}
+	for _, b := range data {
+		h ^= uint64(b) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+
+	// Final avalanche
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+
+	return h
+}
+
+// xxRound mixes one 8-byte lane input into an accumulator.
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+// xxMergeRound folds a lane accumulator into the final hash.
+func xxMergeRound(acc, val uint64) uint64 {
+	acc ^= xxRound(0, val)
+	return acc*xxPrime1 + xxPrime4
+}
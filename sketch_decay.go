@@ -0,0 +1,43 @@
+// sketch_decay.go: scheduled and on-demand aging of access frequencies
+//
+// DecaySketch halves the frequency estimates on demand, and
+// Config.SketchDecayInterval on a schedule, so aging follows time too.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DecaySketch halves the estimated access frequencies of all keys, so keys
+// that stopped being popular become eviction candidates again. O(sketch
+// size); safe to call concurrently with other operations.
+func (c *wtinyLFUCache) DecaySketch() {
+	c.resetFrequencies()
+	atomic.AddInt64(&c.sketchDecays, 1)
+}
+
+// runSketchDecay decays the frequencies every interval until Close is called.
+func (c *wtinyLFUCache) runSketchDecay(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopDecay:
+			return
+		case <-ticker.C:
+			c.DecaySketch()
+		}
+	}
+}
+
+// DecaySketch halves the estimated access frequencies of all keys.
+func (c *GenericCache[K, V]) DecaySketch() {
+	c.inner.DecaySketch()
+}
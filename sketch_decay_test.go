@@ -0,0 +1,75 @@
+// sketch_decay_test.go: tests for scheduled and on-demand frequency aging
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecaySketch_HalvesFrequencies(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 1000})
+	cache.Set("hot", 1)
+	for i := 0; i < 7; i++ {
+		cache.Get("hot")
+	}
+
+	inner := cache.inner.(*wtinyLFUCache)
+	if got := inner.estimateFrequency(stringHash("hot")); got != 8 {
+		t.Fatalf("expected frequency 8 before decay, got %d", got)
+	}
+	cache.DecaySketch()
+	if got := inner.estimateFrequency(stringHash("hot")); got != 4 {
+		t.Errorf("expected frequency 4 after decay, got %d", got)
+	}
+	if got := cache.DebugStats().SketchDecays; got != 1 {
+		t.Errorf("expected 1 decay in DebugStats, got %d", got)
+	}
+	if v, ok := cache.Get("hot"); !ok || v != 1 {
+		t.Error("decay must not affect cached entries")
+	}
+}
+
+func TestDecaySketch_CustomEstimator(t *testing.T) {
+	est := newCountingEstimator()
+	cache := NewCache(Config{MaxSize: 100, FrequencyEstimator: est}).(*wtinyLFUCache)
+
+	cache.DecaySketch()
+	if est.resets != 1 {
+		t.Errorf("DecaySketch must reset the custom estimator, got %d resets", est.resets)
+	}
+}
+
+func TestSketchDecayInterval(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, SketchDecayInterval: 2 * time.Millisecond}).(*wtinyLFUCache)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.DebugStats().SketchDecays < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduled decay did not run")
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case <-cache.stopDecay:
+	default:
+		t.Fatal("Close must stop the scheduled decay")
+	}
+}
+
+func TestSketchDecayInterval_DisabledByDefault(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer func() { _ = cache.Close() }()
+
+	if cache.stopDecay != nil {
+		t.Error("no decay goroutine must start without SketchDecayInterval")
+	}
+}
@@ -0,0 +1,148 @@
+// sketch_snapshot.go: export and import of the frequency sketch
+//
+// SketchSnapshot and RestoreSketch carry key popularity across restarts.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sync/atomic"
+)
+
+// Sketch snapshot format (version 1), integers in big endian:
+//
+//	magic "BSKT" | version uint16 | words uint32 | samples int64
+//	word uint64 * words | CRC-32C uint32 of all preceding bytes
+const (
+	sketchSnapshotMagic   = "BSKT"
+	sketchSnapshotVersion = 1
+	sketchSnapshotHeader  = 18 // magic + version + words + samples
+)
+
+// MarshalBinary encodes the counters of the sketch (see SketchSnapshot).
+func (s *frequencySketch) MarshalBinary() ([]byte, error) {
+	data := make([]byte, sketchSnapshotHeader, sketchSnapshotHeader+8*len(s.table)+4)
+	copy(data, sketchSnapshotMagic)
+	binary.BigEndian.PutUint16(data[4:6], sketchSnapshotVersion)
+	binary.BigEndian.PutUint32(data[6:10], uint32(len(s.table)))                     // #nosec G115 -- table size bounded by MaxSize
+	binary.BigEndian.PutUint64(data[10:18], uint64(atomic.LoadInt64(&s.sampleSize))) // #nosec G115 -- restored as int64
+	for i := range s.table {
+		data = binary.BigEndian.AppendUint64(data, atomic.LoadUint64(&s.table[i]))
+	}
+	return binary.BigEndian.AppendUint32(data, crc32.Checksum(data, snapshotTable)), nil
+}
+
+// UnmarshalBinary replaces the counters of the sketch with a snapshot
+// produced by MarshalBinary, rescaled to the size of the sketch.
+func (s *frequencySketch) UnmarshalBinary(data []byte) error {
+	corrupted := func(details string) error { return NewErrCorruptedData("", details) }
+	if len(data) < sketchSnapshotHeader+4 || string(data[:4]) != sketchSnapshotMagic {
+		return corrupted("not a balios sketch snapshot")
+	}
+	if v := binary.BigEndian.Uint16(data[4:6]); v != sketchSnapshotVersion {
+		return corrupted(fmt.Sprintf("unsupported sketch snapshot version %d", v))
+	}
+	words := int(binary.BigEndian.Uint32(data[6:10]))
+	if words == 0 || words&(words-1) != 0 || len(data) != sketchSnapshotHeader+8*words+4 {
+		return corrupted("invalid sketch size")
+	}
+	body := data[:len(data)-4]
+	if crc32.Checksum(body, snapshotTable) != binary.BigEndian.Uint32(data[len(body):]) {
+		return corrupted("checksum mismatch")
+	}
+	word := func(i int) uint64 {
+		return binary.BigEndian.Uint64(body[sketchSnapshotHeader+8*i:])
+	}
+
+	size := len(s.table)
+	for i := 0; i < size; i++ {
+		var counters uint64
+		if size >= words {
+			counters = word(i & (words - 1))
+		} else {
+			for j := i; j < words; j += size {
+				counters = maxCounters(counters, word(j))
+			}
+		}
+		atomic.StoreUint64(&s.table[i], counters)
+	}
+	samples := int64(binary.BigEndian.Uint64(data[10:18])) // #nosec G115 -- written from an int64
+	if samples < 0 {
+		samples = 0
+	}
+	atomic.StoreInt64(&s.sampleSize, samples%s.resetThreshold)
+	return nil
+}
+
+// maxCounters returns the counter-wise maximum of two words of 4-bit counters.
+func maxCounters(a, b uint64) uint64 {
+	var result uint64
+	for shift := uint64(0); shift < 64; shift += 4 {
+		result |= max((a>>shift)&0xF, (b>>shift)&0xF) << shift
+	}
+	return result
+}
+
+// frequencyState returns the FrequencyEstimator in use (nil once closed).
+func (c *wtinyLFUCache) frequencyState() FrequencyEstimator {
+	if c.estimator != nil {
+		return c.estimator
+	}
+	if t := c.table.Load(); t != nil {
+		return t.sketch
+	}
+	return nil
+}
+
+// SketchSnapshot exports the access frequencies recorded by the cache, for
+// RestoreSketch in a restarted process. Returns nil if the cache is closed
+// or its FrequencyEstimator does not implement encoding.BinaryMarshaler.
+func (c *wtinyLFUCache) SketchSnapshot() []byte {
+	if c.isClosed() {
+		return nil
+	}
+	marshaler, ok := c.frequencyState().(encoding.BinaryMarshaler)
+	if !ok {
+		return nil
+	}
+	data, err := marshaler.MarshalBinary()
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// RestoreSketch replaces the access frequencies of the cache with a
+// snapshot from SketchSnapshot, rescaled if it was taken with a different
+// MaxSize. Returns BALIOS_CORRUPTED_DATA for an invalid snapshot (nothing is
+// restored) and BALIOS_INVALID_CONFIG if the FrequencyEstimator does not
+// implement encoding.BinaryUnmarshaler.
+func (c *wtinyLFUCache) RestoreSketch(data []byte) error {
+	if c.isClosed() {
+		return NewErrCacheClosed("RestoreSketch")
+	}
+	unmarshaler, ok := c.frequencyState().(encoding.BinaryUnmarshaler)
+	if !ok {
+		return NewErrInvalidConfig("FrequencyEstimator", "does not implement encoding.BinaryUnmarshaler")
+	}
+	return unmarshaler.UnmarshalBinary(data)
+}
+
+// SketchSnapshot exports the access frequencies recorded by the cache (see
+// Cache.SketchSnapshot).
+func (c *GenericCache[K, V]) SketchSnapshot() []byte {
+	return c.inner.SketchSnapshot()
+}
+
+// RestoreSketch imports access frequencies exported by SketchSnapshot (see
+// Cache.RestoreSketch).
+func (c *GenericCache[K, V]) RestoreSketch(data []byte) error {
+	return c.inner.RestoreSketch(data)
+}
@@ -0,0 +1,92 @@
+// sketch_snapshot_test.go: tests for SketchSnapshot and RestoreSketch
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"testing"
+)
+
+// trainedCache returns a cache where key-i was read i%16 times.
+func trainedCache(maxSize int) *wtinyLFUCache {
+	cache := NewCache(Config{MaxSize: maxSize}).(*wtinyLFUCache)
+	for i := 0; i < 200; i++ {
+		for n := 0; n < i%16; n++ {
+			cache.Get(fmt.Sprintf("key-%d", i))
+		}
+	}
+	return cache
+}
+
+func TestSketchSnapshot_RoundTrip(t *testing.T) {
+	for _, maxSize := range []int{1000, 8000, 200} { // Same size, larger, smaller
+		t.Run(fmt.Sprint(maxSize), func(t *testing.T) {
+			source := trainedCache(1000)
+			defer source.Close()
+			restored := NewCache(Config{MaxSize: maxSize}).(*wtinyLFUCache)
+			defer restored.Close()
+
+			if err := restored.RestoreSketch(source.SketchSnapshot()); err != nil {
+				t.Fatalf("RestoreSketch() error = %v", err)
+			}
+			for i := 0; i < 200; i++ {
+				keyHash := stringHash(fmt.Sprintf("key-%d", i))
+				want, got := source.estimateFrequency(keyHash), restored.estimateFrequency(keyHash)
+				if got < want || (maxSize >= 1000 && got != want) {
+					t.Fatalf("key-%d: estimate %d, was %d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSketchSnapshot_Corrupted(t *testing.T) {
+	source := trainedCache(1000)
+	defer source.Close()
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	data := source.SketchSnapshot()
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)/2] ^= 1
+	for name, snapshot := range map[string][]byte{
+		"empty":     nil,
+		"truncated": data[:len(data)-8],
+		"flipped":   flipped,
+	} {
+		if err := cache.RestoreSketch(snapshot); GetErrorCode(err) != ErrCodeCorruptedData {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+	if freq := cache.estimateFrequency(stringHash("key-15")); freq != 0 {
+		t.Errorf("corrupted snapshot restored: frequency %d", freq)
+	}
+}
+
+func TestSketchSnapshot_Estimators(t *testing.T) {
+	custom := NewCache(Config{MaxSize: 100, FrequencyEstimator: newCountingEstimator()}).(*wtinyLFUCache)
+	defer custom.Close()
+	if custom.SketchSnapshot() != nil {
+		t.Error("snapshot of an estimator without MarshalBinary")
+	}
+	if err := custom.RestoreSketch([]byte("x")); !IsConfigError(err) {
+		t.Errorf("RestoreSketch() = %v, want a configuration error", err)
+	}
+
+	// The built-in sketch passed explicitly is exported too
+	explicit := NewGenericCache[string, int](Config{MaxSize: 100, FrequencyEstimator: NewFrequencySketch(100)})
+	defer explicit.Close()
+	explicit.Get("k")
+	if data := explicit.SketchSnapshot(); data == nil || explicit.RestoreSketch(data) != nil {
+		t.Error("built-in FrequencyEstimator not exported")
+	}
+
+	_ = explicit.Close()
+	if explicit.SketchSnapshot() != nil || !IsCacheClosed(explicit.RestoreSketch(nil)) {
+		t.Error("closed cache exported or restored its sketch")
+	}
+}
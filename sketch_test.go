@@ -8,6 +8,7 @@ package balios
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -236,6 +237,89 @@ func TestMin4(t *testing.T) {
 	}
 }
 
+// fnv1aReference is the original byte-at-a-time FNV-1a used for all key lengths
+// before the wide path was introduced.
+func fnv1aReference(s string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint64(s[i])
+		hash *= 1099511628211
+	}
+	return hash
+}
+
+func TestStringHash_ShortKeysUnchanged(t *testing.T) {
+	// Keys up to the threshold must keep their historical FNV-1a values
+	for n := 0; n <= wideHashThreshold; n++ {
+		key := strings.Repeat("k", n)
+		if got, want := stringHash(key), fnv1aReference(key); got != want {
+			t.Fatalf("len %d: stringHash = %x, want FNV-1a %x", n, got, want)
+		}
+	}
+}
+
+func TestStringHash_WideKnownValues(t *testing.T) {
+	// Reference values from the xxHash64 specification implementation (seed 0)
+	tests := []struct {
+		input string
+		want  uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"abc", 0x44bc2cf5ad770999},
+	}
+	for _, tt := range tests {
+		if got := stringHashWide(tt.input); got != tt.want {
+			t.Errorf("stringHashWide(%q) = %x, want %x", tt.input, got, tt.want)
+		}
+	}
+
+	// Lengths around the 32-byte stripe boundary and multi-stripe inputs, of
+	// a repeating "a-z0-9" pattern (same reference implementation)
+	const pattern = "abcdefghijklmnopqrstuvwxyz0123456789"
+	long := strings.Repeat(pattern, 1000/len(pattern)+1)
+	lengths := []struct {
+		n    int
+		want uint64
+	}{
+		{32, 0xbf2cd639b4143b80},
+		{33, 0x4f89e4082bcbf673},
+		{64, 0x040d7eb5d0212db5},
+		{100, 0x5f009d36eeb305be},
+		{1000, 0xc1c170c6c2158bc4},
+	}
+	for _, tt := range lengths {
+		if got := stringHashWide(long[:tt.n]); got != tt.want {
+			t.Errorf("stringHashWide(%d bytes) = %x, want %x", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestStringHash_LongKeyDistribution(t *testing.T) {
+	// Long keys sharing a 300-byte prefix must not collide and must spread
+	// across the low bits used for table indexing
+	prefix := strings.Repeat("tenant:acme/region:eu-west-1/", 10)
+	const n = 50000
+	seen := make(map[uint64]struct{}, n)
+	buckets := make([]int, 256)
+
+	for i := 0; i < n; i++ {
+		h := stringHash(prefix + strconv.Itoa(i))
+		if _, dup := seen[h]; dup {
+			t.Fatalf("collision for key %d", i)
+		}
+		seen[h] = struct{}{}
+		buckets[h&0xFF]++
+	}
+
+	expected := n / len(buckets)
+	for i, count := range buckets {
+		if count < expected/2 || count > expected*2 {
+			t.Errorf("bucket %d has %d entries (expected ~%d)", i, count, expected)
+		}
+	}
+}
+
 // Benchmark tests
 func BenchmarkFrequencySketch_Increment(b *testing.B) {
 	sketch := newFrequencySketch(10000)
@@ -288,3 +372,22 @@ func BenchmarkStringHash(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkStringHash_LongKeys(b *testing.B) {
+	for _, size := range []int{64, 128, 300, 1024} {
+		key := strings.Repeat("x", size)
+		b.Run(strconv.Itoa(size)+"B", func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				stringHash(key)
+			}
+		})
+		b.Run(strconv.Itoa(size)+"B/fnv1a", func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				fnv1aReference(key)
+			}
+		})
+	}
+}
@@ -0,0 +1,499 @@
+// soak.go: long-running soak/chaos harness for balios caches
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package soak runs long soak and chaos tests against a balios cache built
+// with a caller-provided configuration.
+//
+// It packages the stress patterns used by the balios race and security test
+// suites (mixed concurrent operations, Clear storms, hostile keys, loader
+// panics) behind a configurable harness with invariant checks, so downstream
+// teams can soak their own configuration in CI:
+//
+//	func TestSoak(t *testing.T) {
+//	    cache := balios.NewCache(myProductionConfig)
+//	    defer cache.Close()
+//
+//	    report := soak.Run(context.Background(), cache, soak.Config{
+//	        Duration: 10 * time.Minute,
+//	    })
+//	    if err := report.Err(); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+//
+// Invariants are checked periodically while the workload runs (size bounds,
+// value integrity) and once afterwards (the cache is still functional after
+// the chaos phase). Custom invariants can be added through Config.Invariants.
+package soak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agilira/balios"
+)
+
+// Default harness parameters.
+const (
+	DefaultDuration      = 10 * time.Second
+	DefaultKeySpace      = 10_000
+	DefaultCheckInterval = 100 * time.Millisecond
+
+	// maxViolations bounds the violations kept in a Report.
+	maxViolations = 100
+)
+
+// Op identifies a workload operation.
+type Op int
+
+const (
+	OpSet Op = iota
+	OpGet
+	OpHas
+	OpDelete
+	OpStats
+	OpClear
+	OpGetOrLoad
+	OpLoaderPanic
+	OpExpireNow
+	numOps
+)
+
+var opNames = [numOps]string{"set", "get", "has", "delete", "stats", "clear", "getorload", "loaderpanic", "expirenow"}
+
+// String returns the operation name.
+func (o Op) String() string {
+	if o >= 0 && o < numOps {
+		return opNames[o]
+	}
+	return "Op(" + strconv.Itoa(int(o)) + ")"
+}
+
+// OpMix holds relative operation weights. Zero-weight operations never run.
+type OpMix struct {
+	Set         int
+	Get         int
+	Has         int
+	Delete      int
+	Stats       int
+	Clear       int
+	GetOrLoad   int
+	LoaderPanic int // GetOrLoad with a panicking loader
+	ExpireNow   int
+}
+
+// DefaultOpMix mirrors the goroutine stress test of the balios race suite,
+// with occasional Clear storms and loader panics.
+var DefaultOpMix = OpMix{
+	Set:         30,
+	Get:         40,
+	Has:         8,
+	Delete:      8,
+	Stats:       4,
+	Clear:       1,
+	GetOrLoad:   8,
+	LoaderPanic: 1,
+}
+
+func (m OpMix) weights() [numOps]int {
+	return [numOps]int{m.Set, m.Get, m.Has, m.Delete, m.Stats, m.Clear, m.GetOrLoad, m.LoaderPanic, m.ExpireNow}
+}
+
+// Invariant is a property that must hold for the cache under test.
+type Invariant struct {
+	Name  string
+	Check func(c balios.Cache) error
+}
+
+// Config configures a soak run. Zero values select defaults.
+type Config struct {
+	// Duration of the chaos phase (default: DefaultDuration).
+	Duration time.Duration
+
+	// Workers is the number of concurrent goroutines (default: GOMAXPROCS * 4).
+	Workers int
+
+	// KeySpace is the number of distinct regular keys (default: DefaultKeySpace).
+	KeySpace int
+
+	// Mix selects the operation weights (default: DefaultOpMix).
+	Mix OpMix
+
+	// HostileKeys mixes in keys from the security suite (injection payloads,
+	// control characters, very long keys, invalid UTF-8) with ~5% probability.
+	HostileKeys bool
+
+	// CheckInterval is how often invariants run during the chaos phase
+	// (default: DefaultCheckInterval).
+	CheckInterval time.Duration
+
+	// SizeSlack is the tolerated transient overshoot of Len() above Capacity()
+	// while writers race eviction (default: Workers).
+	SizeSlack int
+
+	// Invariants are checked alongside the built-in ones.
+	Invariants []Invariant
+
+	// Seed makes key and operation choices reproducible (default: time-based).
+	Seed int64
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Duration <= 0 {
+		cfg.Duration = DefaultDuration
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.GOMAXPROCS(0) * 4
+	}
+	if cfg.KeySpace <= 0 {
+		cfg.KeySpace = DefaultKeySpace
+	}
+	if cfg.Mix == (OpMix{}) {
+		cfg.Mix = DefaultOpMix
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultCheckInterval
+	}
+	if cfg.SizeSlack <= 0 {
+		cfg.SizeSlack = cfg.Workers
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	return cfg
+}
+
+// Violation records a failed invariant or an unexpected panic.
+type Violation struct {
+	Invariant string
+	At        time.Duration // Offset from the start of the run
+	Err       error
+}
+
+// Report summarizes a soak run.
+type Report struct {
+	Duration   time.Duration
+	Operations uint64
+	OpCounts   map[string]uint64
+	Checks     int
+	Violations []Violation
+	FinalStats balios.CacheStats
+}
+
+// OK reports whether the run completed without violations.
+func (r Report) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// Err returns nil for a clean run, or an error describing every violation.
+func (r Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+	msgs := make([]string, len(r.Violations))
+	for i, v := range r.Violations {
+		msgs[i] = fmt.Sprintf("[%s @ %s] %v", v.Invariant, v.At.Round(time.Millisecond), v.Err)
+	}
+	return fmt.Errorf("soak: %d violation(s):\n%s", len(r.Violations), strings.Join(msgs, "\n"))
+}
+
+// errLoaderPanic is the value thrown by OpLoaderPanic loaders.
+const errLoaderPanic = "soak: intentional loader panic"
+
+// hostileKeys are drawn from the balios security suite.
+var hostileKeys = []string{
+	"",
+	"'; DROP TABLE cache; --",
+	"../../../etc/passwd",
+	"key\x00null",
+	"key\r\nSet-Cookie: evil",
+	"\xff\xfe\xfd",
+	"日本語キー",
+	"${jndi:ldap://evil}",
+	strings.Repeat("A", 10_000),
+}
+
+// run holds the state shared by the workers of one soak run.
+type run struct {
+	cache balios.Cache
+	cfg   Config
+	start time.Time
+
+	opCounts [numOps]uint64
+
+	mu         sync.Mutex
+	violations []Violation
+}
+
+func (r *run) violate(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.violations) < maxViolations {
+		r.violations = append(r.violations, Violation{Invariant: name, At: time.Since(r.start), Err: err})
+	}
+}
+
+// Run soaks cache with the configured workload until cfg.Duration elapses or
+// ctx is cancelled, then verifies the cache is still functional.
+//
+// Run does not close the cache. Values written by the harness are strings
+// tagged with their key, so any value returned for the wrong key is reported
+// as a value-integrity violation.
+func Run(ctx context.Context, cache balios.Cache, cfg Config) Report {
+	cfg = cfg.withDefaults()
+	r := &run{cache: cache, cfg: cfg, start: time.Now()}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	invariants := append(BuiltinInvariants(cfg.SizeSlack), cfg.Invariants...)
+
+	var wg sync.WaitGroup
+	wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go func(id int) {
+			defer wg.Done()
+			r.worker(ctx, cfg.Seed+int64(id))
+		}(i)
+	}
+
+	checks := 0
+	ticker := time.NewTicker(cfg.CheckInterval)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			r.check(invariants)
+			checks++
+		}
+	}
+	wg.Wait()
+
+	// Post-chaos: invariants once more, then a functional probe
+	r.check(invariants)
+	checks++
+	if err := checkFunctional(cache); err != nil {
+		r.violate("functional-after-chaos", err)
+	}
+
+	report := Report{
+		Duration:   time.Since(r.start),
+		OpCounts:   make(map[string]uint64, numOps),
+		Checks:     checks,
+		Violations: r.violations,
+		FinalStats: cache.Stats(),
+	}
+	for op, n := range r.opCounts {
+		if n > 0 {
+			report.OpCounts[Op(op).String()] = n
+			report.Operations += n
+		}
+	}
+	return report
+}
+
+// check runs every invariant, recording failures and panics.
+func (r *run) check(invariants []Invariant) {
+	for _, inv := range invariants {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					r.violate(inv.Name, fmt.Errorf("invariant panicked: %v", p))
+				}
+			}()
+			if err := inv.Check(r.cache); err != nil {
+				r.violate(inv.Name, err)
+			}
+		}()
+	}
+}
+
+func (r *run) worker(ctx context.Context, seed int64) {
+	rng := rand.New(rand.NewSource(seed)) // #nosec G404 -- workload generation, not security sensitive
+	weights := r.cfg.Mix.weights()
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return
+	}
+
+	for seq := 0; ctx.Err() == nil; seq++ {
+		pick := rng.Intn(total)
+		op := Op(0)
+		for i, w := range weights {
+			if pick < w {
+				op = Op(i)
+				break
+			}
+			pick -= w
+		}
+		r.do(ctx, op, r.key(rng), seq)
+		atomic.AddUint64(&r.opCounts[op], 1)
+	}
+}
+
+func (r *run) key(rng *rand.Rand) string {
+	if r.cfg.HostileKeys && rng.Intn(20) == 0 {
+		return hostileKeys[rng.Intn(len(hostileKeys))]
+	}
+	return "soak:" + strconv.Itoa(rng.Intn(r.cfg.KeySpace))
+}
+
+// taggedValue encodes key into the value so cross-key corruption is detectable.
+func taggedValue(key string, seq int) string {
+	return key + "#" + strconv.Itoa(seq)
+}
+
+func (r *run) verifyValue(key string, v interface{}) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, key+"#") {
+		shown := fmt.Sprintf("%v", v)
+		if len(shown) > 64 {
+			shown = shown[:64] + "..."
+		}
+		r.violate("value-integrity", fmt.Errorf("key %.64q returned foreign value %q", key, shown))
+	}
+}
+
+func (r *run) do(ctx context.Context, op Op, key string, seq int) {
+	defer func() {
+		if p := recover(); p != nil {
+			r.violate("no-panic", fmt.Errorf("%s(%.64q) panicked: %v", op, key, p))
+		}
+	}()
+
+	c := r.cache
+	switch op {
+	case OpSet:
+		c.Set(key, taggedValue(key, seq))
+	case OpGet:
+		if v, ok := c.Get(key); ok {
+			r.verifyValue(key, v)
+		}
+	case OpHas:
+		c.Has(key)
+	case OpDelete:
+		c.Delete(key)
+	case OpStats:
+		stats := c.Stats()
+		if stats.Size < 0 {
+			r.violate("stats-coherent", fmt.Errorf("negative size %d", stats.Size))
+		}
+		if ratio := stats.HitRatio(); ratio < 0 || ratio > 100 {
+			r.violate("stats-coherent", fmt.Errorf("hit ratio %.2f out of range", ratio))
+		}
+	case OpClear:
+		c.Clear()
+	case OpGetOrLoad:
+		v, err := c.GetOrLoadWithContext(ctx, key, func(context.Context) (interface{}, error) {
+			return taggedValue(key, seq), nil
+		})
+		if err == nil {
+			r.verifyValue(key, v)
+		} else if !expectedLoadError(err) {
+			// Concurrent LoaderPanic ops share the singleflight: their recovered
+			// panic may be delivered (or negatively cached) for this key
+			r.violate("getorload", fmt.Errorf("key %.64q: %v", key, err))
+		}
+	case OpLoaderPanic:
+		_, err := c.GetOrLoad(key, func() (interface{}, error) {
+			panic(errLoaderPanic)
+		})
+		if err == nil {
+			// A concurrent regular load may have won the singleflight; otherwise
+			// the panic must surface as an error
+			return
+		}
+		if !expectedLoadError(err) {
+			r.violate("loader-panic", fmt.Errorf("expected recovered panic, got %v", err))
+		}
+	case OpExpireNow:
+		c.ExpireNow()
+	}
+}
+
+// expectedLoadError reports whether a GetOrLoad error is a legitimate outcome
+// of the workload: run shutdown, hostile empty keys, or a recovered loader panic.
+func expectedLoadError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled) ||
+		balios.IsEmptyKey(err) ||
+		balios.GetErrorCode(err) == balios.ErrCodePanicRecovered
+}
+
+// BuiltinInvariants returns the invariants checked by every soak run:
+//   - size-bounds: 0 <= Len() <= Capacity() + slack
+//   - stats-bounds: 0 <= Stats().Size <= Capacity + slack and hit ratio in [0, 100]
+func BuiltinInvariants(slack int) []Invariant {
+	return []Invariant{
+		{
+			Name: "size-bounds",
+			Check: func(c balios.Cache) error {
+				n, capacity := c.Len(), c.Capacity()
+				if n < 0 || n > capacity+slack {
+					return fmt.Errorf("Len()=%d outside [0, %d+%d]", n, capacity, slack)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "stats-bounds",
+			Check: func(c balios.Cache) error {
+				s := c.Stats()
+				if s.Size < 0 || s.Size > s.Capacity+slack {
+					return fmt.Errorf("Stats().Size=%d outside [0, %d+%d]", s.Size, s.Capacity, slack)
+				}
+				if r := s.HitRatio(); r < 0 || r > 100 {
+					return fmt.Errorf("hit ratio %.2f out of range", r)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// checkFunctional verifies basic operations still behave after the chaos phase.
+func checkFunctional(c balios.Cache) error {
+	const probes = 100
+	for i := 0; i < probes; i++ {
+		key := "soak:functional:" + strconv.Itoa(i)
+		if !c.Set(key, i) {
+			return fmt.Errorf("Set(%q) failed", key)
+		}
+		v, ok := c.Get(key)
+		if !ok {
+			// Admission/eviction may legitimately drop a fresh key under a full
+			// cache; a second attempt must succeed
+			c.Set(key, i)
+			if v, ok = c.Get(key); !ok {
+				return fmt.Errorf("Get(%q) missed right after Set", key)
+			}
+		}
+		if v != i {
+			return fmt.Errorf("Get(%q) = %v, want %d", key, v, i)
+		}
+		if !c.Delete(key) {
+			return fmt.Errorf("Delete(%q) reported missing key", key)
+		}
+		if c.Has(key) {
+			return fmt.Errorf("Has(%q) true after Delete", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// soak_test.go: tests for the soak harness
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package soak
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/balios"
+)
+
+func TestRun_CleanCache(t *testing.T) {
+	cache := balios.NewCache(balios.Config{MaxSize: 500, TTL: 50 * time.Millisecond})
+	defer func() { _ = cache.Close() }()
+
+	mix := DefaultOpMix
+	mix.ExpireNow = 1
+	report := Run(context.Background(), cache, Config{
+		Duration:      300 * time.Millisecond,
+		Workers:       8,
+		KeySpace:      2_000,
+		Mix:           mix,
+		HostileKeys:   true,
+		CheckInterval: 20 * time.Millisecond,
+		Seed:          1,
+	})
+
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if report.Operations == 0 || report.Checks < 2 {
+		t.Errorf("expected operations and periodic checks, got %+v", report)
+	}
+	for _, op := range []string{"set", "get", "clear", "loaderpanic", "expirenow"} {
+		if report.OpCounts[op] == 0 {
+			t.Errorf("operation %s never ran: %v", op, report.OpCounts)
+		}
+	}
+}
+
+func TestRun_CustomInvariantViolation(t *testing.T) {
+	cache := balios.NewCache(balios.Config{MaxSize: 100})
+	defer func() { _ = cache.Close() }()
+
+	report := Run(context.Background(), cache, Config{
+		Duration:      50 * time.Millisecond,
+		Workers:       2,
+		CheckInterval: 10 * time.Millisecond,
+		Invariants: []Invariant{
+			{Name: "always-fails", Check: func(balios.Cache) error { return errors.New("nope") }},
+			{Name: "panics", Check: func(balios.Cache) error { panic("bad invariant") }},
+		},
+	})
+
+	if report.OK() {
+		t.Fatal("expected violations")
+	}
+	err := report.Err().Error()
+	if !strings.Contains(err, "always-fails") || !strings.Contains(err, "invariant panicked") {
+		t.Errorf("unexpected report error: %s", err)
+	}
+}
+
+// corruptingCache returns values belonging to other keys.
+type corruptingCache struct {
+	balios.Cache
+}
+
+func (c corruptingCache) Get(key string) (interface{}, bool) {
+	return "someone-else#1", true
+}
+
+func TestRun_DetectsValueCorruption(t *testing.T) {
+	inner := balios.NewCache(balios.Config{MaxSize: 100})
+	defer func() { _ = inner.Close() }()
+
+	report := Run(context.Background(), corruptingCache{inner}, Config{
+		Duration: 50 * time.Millisecond,
+		Workers:  2,
+		Mix:      OpMix{Get: 1},
+	})
+
+	found := false
+	for _, v := range report.Violations {
+		found = found || v.Invariant == "value-integrity"
+	}
+	if !found {
+		t.Errorf("expected value-integrity violation, got %v", report.Err())
+	}
+}
+
+func TestRun_ContextCancellation(t *testing.T) {
+	cache := balios.NewCache(balios.Config{MaxSize: 100})
+	defer func() { _ = cache.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	report := Run(ctx, cache, Config{Duration: time.Hour, Workers: 2})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run ignored context cancellation (took %v)", elapsed)
+	}
+	if err := report.Err(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,250 @@
+// source.go: per-entry creation source tagging for debugging
+//
+// SetWithSource and WithSource tag an entry with the code path that wrote
+// it, returned by SourceOf and included in OnEntryEvent events.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// MaxSourceLength is the maximum length in bytes of a source tag.
+// Longer tags are truncated.
+const MaxSourceLength = 64
+
+// EntryEventType identifies why an entry left the cache.
+type EntryEventType int
+
+const (
+	// EntryEvicted is reported when an entry is evicted to make room.
+	EntryEvicted EntryEventType = iota + 1
+
+	// EntryExpired is reported when an entry is removed after its TTL elapsed.
+	EntryExpired
+)
+
+// String returns the event type name.
+func (t EntryEventType) String() string {
+	switch t {
+	case EntryEvicted:
+		return "evicted"
+	case EntryExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// EntryEvent describes an entry removed by eviction or expiration.
+//
+// Key, Value and Source are read from the slot right after its removal; under
+// heavy contention the slot may already be reused, so events are best effort
+// and intended for debugging, not for resource management.
+type EntryEvent struct {
+	Type   EntryEventType
+	Key    string
+	Value  interface{}
+	Source string // Tag recorded by SetWithSource / WithSource ("" if untagged)
+}
+
+// sourceContextKey is the context key for WithSource.
+type sourceContextKey struct{}
+
+// WithSource returns a context carrying a source tag. Values stored by
+// GetOrLoadWithContext with this context are tagged with source.
+//
+// Example:
+//
+//	ctx = balios.WithSource(ctx, "profile-handler")
+//	user, err := cache.GetOrLoadWithContext(ctx, "user:123", loadUser)
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceContextKey{}, source)
+}
+
+// SourceFromContext returns the source tag carried by ctx, or "".
+func SourceFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	source, _ := ctx.Value(sourceContextKey{}).(string)
+	return source
+}
+
+// truncateSource bounds a source tag to MaxSourceLength bytes.
+func truncateSource(source string) string {
+	if len(source) > MaxSourceLength {
+		return source[:MaxSourceLength]
+	}
+	return source
+}
+
+// taggedValue is the data of a valueHolder whose value carries a source tag,
+// a write version or a long TTL, immutable after publication.
+type taggedValue struct {
+	value   interface{}
+	source  string // Creation source tag
+	version uint64 // Write version (see versions.go)
+	ttl     int64  // TTL written beyond the default bound (see ttlBound in clock.go)
+}
+
+// taggedHolder allocates a valueHolder and its taggedValue together.
+type taggedHolder struct {
+	holder valueHolder
+	tagged taggedValue
+}
+
+// newValueHolder wraps value, its source tag, its write version and its long
+// TTL (0 if none, see longTTL) for storage in an entry. Untagged values, the
+// common case, are stored as is in a bare holder, so caches using neither
+// SetWithSource, Config.EntryVersions nor per-call TTLs pay nothing for them;
+// tagged ones get their tags in the same allocation.
+func newValueHolder(value interface{}, source string, version uint64, ttl int64) *valueHolder {
+	if source == "" && version == 0 && ttl == 0 {
+		holder := &valueHolder{}
+		holder.data.Store(value)
+		return holder
+	}
+	tagged := &taggedHolder{tagged: taggedValue{value: value, source: source, version: version, ttl: ttl}}
+	tagged.holder.data.Store(&tagged.tagged)
+	return &tagged.holder
+}
+
+// load returns the held value.
+func (h *valueHolder) load() interface{} {
+	data := h.data.Load()
+	if tagged, ok := data.(*taggedValue); ok {
+		return tagged.value
+	}
+	return data
+}
+
+// source returns the source tag of the held value ("" if untagged).
+func (h *valueHolder) source() string {
+	if tagged, ok := h.data.Load().(*taggedValue); ok {
+		return tagged.source
+	}
+	return ""
+}
+
+// version returns the write version of the held value (0 if unversioned).
+func (h *valueHolder) version() uint64 {
+	if tagged, ok := h.data.Load().(*taggedValue); ok {
+		return tagged.version
+	}
+	return 0
+}
+
+// ttl returns the long TTL the held value was written with (0 if none).
+func (h *valueHolder) ttl() int64 {
+	if tagged, ok := h.data.Load().(*taggedValue); ok {
+		return tagged.ttl
+	}
+	return 0
+}
+
+// SetWithSource stores a key-value pair tagged with the code path that wrote it.
+// The tag is returned by SourceOf and included in eviction/expiration events.
+func (c *wtinyLFUCache) SetWithSource(key string, value interface{}, source string) bool {
+	if !c.set(key, value, truncateSource(source), c.ttlNanos) {
+		return false
+	}
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return true
+}
+
+// SourceOf returns the source tag of a live entry. found is false if the key
+// is absent or expired; an untagged entry returns "" and true.
+func (c *wtinyLFUCache) SourceOf(key string) (source string, found bool) {
+	if key == "" {
+		return "", false
+	}
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	entry := c.findEntry(key, c.hashKey(key))
+	if entry == nil || c.isExpired(entry, ttlNow) {
+		return "", false
+	}
+	holder, ok := entry.value.Load().(*valueHolder)
+	if !ok || holder == nil || !c.isLive(entry) {
+		return "", false
+	}
+	return holder.source(), true
+}
+
+// findEntry returns the valid entry holding key, without side effects on
+// statistics or the frequency sketch. Returns nil if not found.
+func (c *wtinyLFUCache) findEntry(key string, keyHash uint64) *entry {
+	t := c.table.Load()
+	if t == nil {
+		return nil // Closed
+	}
+	return c.findEntryIn(t, key, keyHash)
+}
+
+// findEntryIn is findEntry in the table t.
+func (c *wtinyLFUCache) findEntryIn(t *cacheTable, key string, keyHash uint64) *entry {
+	g := t.gen.Load()
+	startIdx := keyHash & uint64(t.mask)
+	effectiveMaxProbes := maxProbeLength
+	if effectiveMaxProbes > t.mask {
+		effectiveMaxProbes = t.mask
+	}
+
+	for i := uint32(0); i <= effectiveMaxProbes; i++ {
+		entry := &t.entries[(startIdx+uint64(i))&uint64(t.mask)]
+		state := atomic.LoadInt32(&entry.valid)
+		if state == entryEmpty {
+			return nil
+		}
+		if state == g.live && atomic.LoadUint64(&entry.keyHash) == keyHash && entry.loadKey() == key {
+			return entry
+		}
+	}
+	return nil
+}
+
+// emitEntryEvent reports the removal of entry to Config.OnEntryEvent.
+// The caller MUST have just moved the entry out of entryValid and MUST call
+// this before clearing the key.
+func (c *wtinyLFUCache) emitEntryEvent(typ EntryEventType, entry *entry) {
+	if c.onEntryEvent == nil || atomic.LoadInt32(&c.entryEventDisabled) != 0 {
+		return
+	}
+
+	event := EntryEvent{Type: typ, Key: entry.loadKey()}
+	if holder, ok := entry.value.Load().(*valueHolder); ok && holder != nil {
+		event.Value = c.decodedOrNil(holder.load())
+		event.Source = holder.source()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			// Only the first panicking goroutine logs, keeping the log volume bounded
+			if atomic.CompareAndSwapInt32(&c.entryEventDisabled, 0, 1) {
+				c.logger.Error("balios: OnEntryEvent panicked, entry events disabled",
+					"event", typ.String(),
+					"panic", r,
+				)
+			}
+		}
+	}()
+	c.onEntryEvent(event)
+}
+
+// SetWithSource stores a key-value pair tagged with the code path that wrote
+// it. Returns false if the value was not stored.
+func (c *GenericCache[K, V]) SetWithSource(key K, value V, source string) bool {
+	return c.inner.SetWithSource(keyToString(key), value, source)
+}
+
+// SourceOf returns the source tag of a live entry.
+func (c *GenericCache[K, V]) SourceOf(key K) (source string, found bool) {
+	return c.inner.SourceOf(keyToString(key))
+}
@@ -0,0 +1,161 @@
+// source_test.go: tests for per-entry creation source tagging
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder collects entry events.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []EntryEvent
+}
+
+func (r *eventRecorder) record(e EntryEvent) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+}
+
+func (r *eventRecorder) snapshot() []EntryEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]EntryEvent(nil), r.events...)
+}
+
+func TestSetWithSource_SourceOf(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	cache.SetWithSource("a", 1, "user-api")
+	cache.Set("b", 2)
+
+	if src, ok := cache.SourceOf("a"); !ok || src != "user-api" {
+		t.Errorf("SourceOf(a) = %q, %v", src, ok)
+	}
+	if src, ok := cache.SourceOf("b"); !ok || src != "" {
+		t.Errorf("untagged entry: SourceOf(b) = %q, %v", src, ok)
+	}
+	if _, ok := cache.SourceOf("missing"); ok {
+		t.Error("SourceOf must report absent keys")
+	}
+
+	// Overwrites replace the tag together with the value
+	cache.Set("a", 10)
+	if src, _ := cache.SourceOf("a"); src != "" {
+		t.Errorf("plain Set must clear the tag, got %q", src)
+	}
+
+	cache.SetWithSource("long", 1, strings.Repeat("x", 200))
+	if src, _ := cache.SourceOf("long"); len(src) != MaxSourceLength {
+		t.Errorf("expected tag truncated to %d bytes, got %d", MaxSourceLength, len(src))
+	}
+
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("SourceOf must not affect hit/miss stats: %+v", stats)
+	}
+}
+
+func TestWithSource_GetOrLoadWithContext(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+	ctx := WithSource(context.Background(), "backfill-job")
+
+	if SourceFromContext(ctx) != "backfill-job" || SourceFromContext(context.Background()) != "" {
+		t.Fatal("SourceFromContext mismatch")
+	}
+
+	_, err := cache.GetOrLoadWithContext(ctx, "k", func(context.Context) (int, error) { return 42, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src, ok := cache.SourceOf("k"); !ok || src != "backfill-job" {
+		t.Errorf("loaded entry: SourceOf = %q, %v", src, ok)
+	}
+
+	cache.SetWithSource("typed", 7, "typed-path")
+	if src, _ := cache.SourceOf("typed"); src != "typed-path" {
+		t.Errorf("generic SetWithSource: got %q", src)
+	}
+}
+
+func TestOnEntryEvent_Eviction(t *testing.T) {
+	rec := &eventRecorder{}
+	cache := NewCache(Config{MaxSize: 10, OnEntryEvent: rec.record}).(*wtinyLFUCache)
+
+	for i := 0; i < 50; i++ {
+		cache.SetWithSource("key"+strconv.Itoa(i), i, "writer-"+strconv.Itoa(i%2))
+	}
+
+	events := rec.snapshot()
+	if uint64(len(events)) != cache.Stats().Evictions || len(events) == 0 {
+		t.Fatalf("expected one event per eviction, got %d events for %+v", len(events), cache.Stats())
+	}
+	for _, e := range events {
+		if e.Type != EntryEvicted || !strings.HasPrefix(e.Key, "key") || !strings.HasPrefix(e.Source, "writer-") {
+			t.Errorf("unexpected event: %+v", e)
+		}
+		n, _ := strconv.Atoi(strings.TrimPrefix(e.Key, "key"))
+		if e.Value != n || e.Source != "writer-"+strconv.Itoa(n%2) {
+			t.Errorf("event value/source does not match key: %+v", e)
+		}
+	}
+}
+
+func TestOnEntryEvent_Expiration(t *testing.T) {
+	rec := &eventRecorder{}
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 100, TTL: time.Second, TimeProvider: mockTime, OnEntryEvent: rec.record}).(*wtinyLFUCache)
+
+	cache.SetWithSource("lazy", 1, "lazy-writer")
+	cache.SetWithSource("swept", 2, "sweep-writer")
+	mockTime.Advance(2 * time.Second)
+
+	cache.Get("lazy")
+	cache.ExpireNow()
+
+	events := rec.snapshot()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 expiration events, got %+v", events)
+	}
+	want := map[string]string{"lazy": "lazy-writer", "swept": "sweep-writer"}
+	for _, e := range events {
+		if e.Type != EntryExpired || want[e.Key] != e.Source || e.Type.String() != "expired" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	}
+}
+
+func TestOnEntryEvent_PanicDisablesHook(t *testing.T) {
+	logger := &recordingLogger{}
+	calls := 0
+	cache := NewCache(Config{
+		MaxSize: 5,
+		Logger:  logger,
+		OnEntryEvent: func(EntryEvent) {
+			calls++
+			panic("boom")
+		},
+	})
+
+	for i := 0; i < 30; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	if calls != 1 {
+		t.Errorf("hook must be disabled after the first panic, called %d times", calls)
+	}
+	if logger.errorCount() != 1 {
+		t.Errorf("expected the panic to be logged once, got %d", logger.errorCount())
+	}
+	if cache.Len() > 5 {
+		t.Errorf("eviction must keep working, len=%d", cache.Len())
+	}
+}
@@ -0,0 +1,223 @@
+// stats_handler.go: JSON stats endpoint and expvar publishing
+//
+// StatsHandler serves the cache statistics as JSON; StatsFunc exposes the
+// same document through expvar.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// StatsProvider is implemented by every cache type (Cache, GenericCache,
+// SwappableCache).
+type StatsProvider interface {
+	Stats() CacheStats
+}
+
+// windowStatsProvider is implemented by the cache types supporting
+// Config.StatsWindow.
+type windowStatsProvider interface {
+	WindowStats(window time.Duration) WindowStats
+}
+
+// StatsReport is the JSON document served by StatsHandler and StatsFunc:
+// the CacheStats counters plus derived ratios.
+type StatsReport struct {
+	Hits               uint64 `json:"hits"`
+	Misses             uint64 `json:"misses"`
+	Sets               uint64 `json:"sets"`
+	Deletes            uint64 `json:"deletes"`
+	Evictions          uint64 `json:"evictions"`
+	Expirations        uint64 `json:"expirations"`
+	DuplicateCleanups  uint64 `json:"duplicate_cleanups"`
+	RaceConditions     uint64 `json:"race_conditions"`
+	ReadContentions    uint64 `json:"read_contentions"`
+	NegativeHits       uint64 `json:"negative_hits,omitempty"`
+	NegativeMisses     uint64 `json:"negative_misses,omitempty"`
+	CircuitRejections  uint64 `json:"circuit_rejections,omitempty"`
+	EventsDropped      uint64 `json:"events_dropped,omitempty"`
+	RejectedTooLarge   uint64 `json:"rejected_too_large,omitempty"`
+	TombstoneHits      uint64 `json:"tombstone_hits,omitempty"`
+	WriteBehindQueued  uint64 `json:"write_behind_queued,omitempty"`
+	WriteBehindFlushed uint64 `json:"write_behind_flushed,omitempty"`
+	WriteBehindFailed  uint64 `json:"write_behind_failed,omitempty"`
+	WriteBehindDropped uint64 `json:"write_behind_dropped,omitempty"`
+	GetLatencyP50Ns    int64  `json:"get_latency_p50_ns,omitempty"`
+	GetLatencyP95Ns    int64  `json:"get_latency_p95_ns,omitempty"`
+	GetLatencyP99Ns    int64  `json:"get_latency_p99_ns,omitempty"`
+	SetLatencyP50Ns    int64  `json:"set_latency_p50_ns,omitempty"`
+	SetLatencyP95Ns    int64  `json:"set_latency_p95_ns,omitempty"`
+	SetLatencyP99Ns    int64  `json:"set_latency_p99_ns,omitempty"`
+	Size               int    `json:"size"`
+	Capacity           int    `json:"capacity"`
+	Weight             int64  `json:"weight,omitempty"`
+	MaxWeight          int64  `json:"max_weight,omitempty"`
+
+	// Window is the span covered by the counters when the report was
+	// requested with ?window= (empty for cumulative counters)
+	Window string `json:"window,omitempty"`
+
+	// HitRatio is Hits / (Hits + Misses) as a percentage (0-100)
+	HitRatio float64 `json:"hit_ratio"`
+
+	// Utilization is Size / Capacity as a percentage (0-100)
+	Utilization float64 `json:"utilization"`
+
+	// EvictionRate is the share of Sets that evicted an entry, as a
+	// percentage (0-100). A high rate means the cache is too small for its
+	// working set
+	EvictionRate float64 `json:"eviction_rate"`
+
+	// Families holds the per-family hit ratios (Config.FamilyStats only)
+	Families map[string]FamilyReport `json:"families,omitempty"`
+}
+
+// FamilyReport is the JSON form of FamilyStats.
+type FamilyReport struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// NewStatsReport builds the StatsReport of stats.
+func NewStatsReport(stats CacheStats) StatsReport {
+	report := StatsReport{
+		Hits:               stats.Hits,
+		Misses:             stats.Misses,
+		Sets:               stats.Sets,
+		Deletes:            stats.Deletes,
+		Evictions:          stats.Evictions,
+		Expirations:        stats.Expirations,
+		DuplicateCleanups:  stats.DuplicateCleanups,
+		RaceConditions:     stats.RaceConditions,
+		ReadContentions:    stats.ReadContentions,
+		NegativeHits:       stats.NegativeHits,
+		NegativeMisses:     stats.NegativeMisses,
+		CircuitRejections:  stats.CircuitRejections,
+		EventsDropped:      stats.EventsDropped,
+		RejectedTooLarge:   stats.RejectedTooLarge,
+		TombstoneHits:      stats.TombstoneHits,
+		WriteBehindQueued:  stats.WriteBehindQueued,
+		WriteBehindFlushed: stats.WriteBehindFlushed,
+		WriteBehindFailed:  stats.WriteBehindFailed,
+		WriteBehindDropped: stats.WriteBehindDropped,
+		GetLatencyP50Ns:    int64(stats.LatencyP50.Get),
+		GetLatencyP95Ns:    int64(stats.LatencyP95.Get),
+		GetLatencyP99Ns:    int64(stats.LatencyP99.Get),
+		SetLatencyP50Ns:    int64(stats.LatencyP50.Set),
+		SetLatencyP95Ns:    int64(stats.LatencyP95.Set),
+		SetLatencyP99Ns:    int64(stats.LatencyP99.Set),
+		Size:               stats.Size,
+		Capacity:           stats.Capacity,
+		Weight:             stats.Weight,
+		MaxWeight:          stats.MaxWeight,
+		HitRatio:           stats.HitRatio(),
+	}
+	if stats.Capacity > 0 {
+		report.Utilization = float64(stats.Size) / float64(stats.Capacity) * 100
+	}
+	if stats.Sets > 0 {
+		report.EvictionRate = float64(stats.Evictions) / float64(stats.Sets) * 100
+	}
+	if len(stats.Families) > 0 {
+		report.Families = make(map[string]FamilyReport, len(stats.Families))
+		for name, family := range stats.Families {
+			report.Families[name] = FamilyReport{
+				Hits:     family.Hits,
+				Misses:   family.Misses,
+				HitRatio: family.HitRatio(),
+			}
+		}
+	}
+	return report
+}
+
+// NewWindowStatsReport builds the StatsReport of the activity in window,
+// taking the gauges (size, capacity, weight, write-behind queue) from stats.
+func NewWindowStatsReport(stats CacheStats, window WindowStats) StatsReport {
+	stats.Hits = window.Hits
+	stats.Misses = window.Misses
+	stats.Sets = window.Sets
+	stats.Deletes = window.Deletes
+	stats.Evictions = window.Evictions
+	stats.Expirations = window.Expirations
+	stats.DuplicateCleanups = 0
+	stats.RaceConditions = 0
+	stats.ReadContentions = 0
+	stats.NegativeHits = 0
+	stats.NegativeMisses = 0
+	stats.CircuitRejections = 0
+	stats.EventsDropped = 0
+	stats.RejectedTooLarge = 0
+	stats.TombstoneHits = 0
+	stats.WriteBehindFlushed = 0
+	stats.WriteBehindFailed = 0
+	stats.WriteBehindDropped = 0
+	stats.LatencyP50, stats.LatencyP95, stats.LatencyP99 = OpLatency{}, OpLatency{}, OpLatency{}
+	stats.Families = nil
+	report := NewStatsReport(stats)
+	report.Window = window.Window.String()
+	return report
+}
+
+// StatsHandler returns an http.Handler serving the StatsReport of cache as
+// JSON. Only GET and HEAD are allowed. With a window query parameter (a
+// time.ParseDuration string, e.g. ?window=5m) the counters cover that recent
+// window instead (see Config.StatsWindow); caches without WindowStats answer
+// 400 Bad Request.
+//
+// Example:
+//
+//	http.Handle("/debug/cache/users", balios.StatsHandler(users))
+func StatsHandler(cache StatsProvider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var window time.Duration
+		if param := r.URL.Query().Get("window"); param != "" {
+			d, err := time.ParseDuration(param)
+			if err != nil || d <= 0 {
+				http.Error(w, "invalid window: "+param, http.StatusBadRequest)
+				return
+			}
+			if _, ok := cache.(windowStatsProvider); !ok {
+				http.Error(w, "window statistics not supported", http.StatusBadRequest)
+				return
+			}
+			window = d
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodHead {
+			return
+		}
+		stats := cache.Stats()
+		report := NewStatsReport(stats)
+		if window > 0 {
+			report = NewWindowStatsReport(stats, cache.(windowStatsProvider).WindowStats(window))
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// StatsFunc returns a function computing the StatsReport of cache, to be
+// published with expvar (served by the standard /debug/vars handler):
+//
+//	expvar.Publish("cache_users", expvar.Func(balios.StatsFunc(users)))
+func StatsFunc(cache StatsProvider) func() interface{} {
+	return func() interface{} {
+		return NewStatsReport(cache.Stats())
+	}
+}
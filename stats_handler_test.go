@@ -0,0 +1,133 @@
+// stats_handler_test.go: tests for the JSON stats endpoint and expvar publishing
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestNewStatsReport_DerivedValues(t *testing.T) {
+	report := NewStatsReport(CacheStats{
+		Hits:      30,
+		Misses:    10,
+		Sets:      200,
+		Evictions: 50,
+		Size:      25,
+		Capacity:  100,
+		Families:  map[string]FamilyStats{"user": {Hits: 3, Misses: 1}},
+	})
+
+	if report.HitRatio != 75 {
+		t.Errorf("HitRatio = %v, want 75", report.HitRatio)
+	}
+	if report.Utilization != 25 {
+		t.Errorf("Utilization = %v, want 25", report.Utilization)
+	}
+	if report.EvictionRate != 25 {
+		t.Errorf("EvictionRate = %v, want 25", report.EvictionRate)
+	}
+	if f := report.Families["user"]; f.Hits != 3 || f.HitRatio != 75 {
+		t.Errorf("unexpected family report: %+v", f)
+	}
+
+	empty := NewStatsReport(CacheStats{})
+	if empty.HitRatio != 0 || empty.Utilization != 0 || empty.EvictionRate != 0 || empty.Families != nil {
+		t.Errorf("empty stats should report zero ratios, got %+v", empty)
+	}
+}
+
+func TestStatsHandler_ServesJSON(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100})
+	for i := 0; i < 20; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	cache.Get("key19")
+	cache.Get("missing")
+
+	rec := httptest.NewRecorder()
+	StatsHandler(cache).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if got["sets"] != float64(20) || got["capacity"] != float64(100) || got["size"] != float64(20) {
+		t.Errorf("unexpected counters: %v", got)
+	}
+	if got["hit_ratio"] != float64(50) || got["utilization"] != float64(20) || got["eviction_rate"] != float64(0) {
+		t.Errorf("unexpected derived values: %v", got)
+	}
+	if _, ok := got["families"]; ok {
+		t.Error("families must be omitted without Config.FamilyStats")
+	}
+}
+
+func TestStatsHandler_ServesEvictionRate(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10})
+	for i := 0; i < 100; i++ {
+		cache.Set("key"+strconv.Itoa(i), i)
+	}
+	if cache.Stats().Evictions == 0 {
+		t.Fatal("filling 10x past MaxSize must evict")
+	}
+
+	rec := httptest.NewRecorder()
+	StatsHandler(cache).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if rate, ok := got["eviction_rate"].(float64); !ok || rate <= 0 || rate > 100 {
+		t.Errorf("eviction_rate = %v, want in (0, 100]", got["eviction_rate"])
+	}
+}
+
+func TestStatsHandler_Methods(t *testing.T) {
+	handler := StatsHandler(NewCache(Config{MaxSize: 10}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/stats", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("HEAD: status %d, body %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/stats", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("POST: status %d, Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+func TestStatsFunc_Expvar(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10})
+	cache.Set("a", 1)
+
+	if expvar.Get("balios_test_stats") == nil { // Publish panics on reuse (-count > 1)
+		expvar.Publish("balios_test_stats", expvar.Func(StatsFunc(cache)))
+	}
+
+	var got StatsReport
+	if err := json.Unmarshal([]byte(expvar.Get("balios_test_stats").String()), &got); err != nil {
+		t.Fatalf("invalid expvar JSON: %v", err)
+	}
+	if got.Sets != 1 || got.Size != 1 || got.Capacity != 10 {
+		t.Errorf("unexpected expvar report: %+v", got)
+	}
+}
@@ -0,0 +1,94 @@
+// stats_snapshot_test.go: tests for coherent Stats snapshots
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStats_WaitsForClearInProgress(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("k", "v")
+	cache.Get("k")
+	cache.Get("missing")
+
+	// Simulate a Clear that is halfway through resetting counters
+	atomic.AddUint64(&cache.statsEpoch, 1)
+	atomic.StoreInt64(&cache.hits, 0)
+
+	done := make(chan CacheStats, 1)
+	go func() { done <- cache.Stats() }()
+
+	time.Sleep(10 * time.Millisecond)
+	atomic.StoreInt64(&cache.misses, 0)
+	atomic.AddUint64(&cache.statsEpoch, 1)
+
+	stats := <-done
+	if stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("snapshot taken during Clear: hits=%d misses=%d", stats.Hits, stats.Misses)
+	}
+	if stats.Capacity != 100 {
+		t.Errorf("expected capacity 100, got %d", stats.Capacity)
+	}
+}
+
+func TestStats_SizeNeverNegative(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+
+	// A removal racing the insertion it removes can underflow the raw counter
+	atomic.StoreInt64(&cache.table.Load().gen.Load().size, -3)
+
+	if s := cache.Stats().Size; s != 0 {
+		t.Errorf("expected Size clamped to 0, got %d", s)
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("expected Len clamped to 0, got %d", n)
+	}
+}
+
+func TestStats_ConcurrentClearHitRatioBounded(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000})
+	for i := 0; i < 100; i++ {
+		cache.Set(string(rune('a'+i%26))+string(rune('0'+i/26)), i)
+	}
+
+	var stop int32
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; atomic.LoadInt32(&stop) == 0; i++ {
+			cache.Get(string(rune('a'+i%26)) + "0")
+			cache.Get("missing")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for atomic.LoadInt32(&stop) == 0 {
+			cache.Clear()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		stats := cache.Stats()
+		ratio := stats.HitRatio()
+		if ratio < 0 || ratio > 100 {
+			t.Fatalf("hit ratio out of range: %f (%+v)", ratio, stats)
+		}
+		if stats.Size < 0 {
+			t.Fatalf("negative size: %+v", stats)
+		}
+	}
+
+	atomic.StoreInt32(&stop, 1)
+	wg.Wait()
+}
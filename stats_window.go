@@ -0,0 +1,194 @@
+// stats_window.go: rolling-window statistics
+//
+// Config.StatsWindow keeps periodic samples of the counters, and
+// WindowStats returns the activity of the last N minutes.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statsWindowBuckets is the number of sampling intervals in Config.StatsWindow.
+const statsWindowBuckets = 60
+
+// WindowStats is the cache activity over a recent time window
+// (see Config.StatsWindow).
+type WindowStats struct {
+	// Window is the time span actually covered by the counters. It is shorter
+	// than requested while the cache is younger than the window (or was
+	// cleared within it) and never longer than Config.StatsWindow
+	Window time.Duration
+
+	Hits        uint64
+	Misses      uint64
+	Sets        uint64
+	Deletes     uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// HitRatio returns the hit ratio over the window as a percentage (0-100).
+// Returns 0.0 if there were no lookups in the window.
+func (s WindowStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total) * 100
+}
+
+// windowSample is a snapshot of the cumulative counters.
+type windowSample struct {
+	at          int64 // TimeProvider clock
+	hits        uint64
+	misses      uint64
+	sets        uint64
+	deletes     uint64
+	evictions   uint64
+	expirations uint64
+}
+
+// statsWindow is the ring of samples behind WindowStats.
+type statsWindow struct {
+	mu      sync.Mutex
+	samples []windowSample // ring, oldest at next when full
+	next    int
+	full    bool
+	stop    chan struct{}
+}
+
+// newStatsWindow returns an empty ring.
+func newStatsWindow() *statsWindow {
+	return &statsWindow{
+		samples: make([]windowSample, statsWindowBuckets+1),
+		stop:    make(chan struct{}),
+	}
+}
+
+// add appends s, overwriting the oldest sample when the ring is full.
+func (w *statsWindow) add(s windowSample) {
+	w.samples[w.next] = s
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// reset drops every sample and records s as the new starting point.
+func (w *statsWindow) reset(s windowSample) {
+	w.next = 0
+	w.full = false
+	w.add(s)
+}
+
+// baseline returns the newest sample taken at or before cutoff, or the
+// oldest sample if none is that old.
+func (w *statsWindow) baseline(cutoff int64) windowSample {
+	n, oldest := w.next, 0
+	if w.full {
+		n, oldest = len(w.samples), w.next
+	}
+	base := w.samples[oldest]
+	for i := 1; i < n; i++ {
+		s := w.samples[(oldest+i)%len(w.samples)]
+		if s.at > cutoff {
+			break
+		}
+		base = s
+	}
+	return base
+}
+
+// windowSampleNow reads the cumulative counters.
+func (c *wtinyLFUCache) windowSampleNow() windowSample {
+	return windowSample{
+		at:          c.timeProvider.Now(),
+		hits:        uint64(atomic.LoadInt64(&c.hits)),        // #nosec G115 - stats counters are always positive
+		misses:      uint64(atomic.LoadInt64(&c.misses)),      // #nosec G115 - stats counters are always positive
+		sets:        uint64(atomic.LoadInt64(&c.sets)),        // #nosec G115 - stats counters are always positive
+		deletes:     uint64(atomic.LoadInt64(&c.deletes)),     // #nosec G115 - stats counters are always positive
+		evictions:   uint64(atomic.LoadInt64(&c.evictions)),   // #nosec G115 - stats counters are always positive
+		expirations: uint64(atomic.LoadInt64(&c.expirations)), // #nosec G115 - stats counters are always positive
+	}
+}
+
+// sampleWindow records the current counters in the ring.
+func (c *wtinyLFUCache) sampleWindow() {
+	s := c.windowSampleNow()
+	c.window.mu.Lock()
+	c.window.add(s)
+	c.window.mu.Unlock()
+}
+
+// resetWindow restarts the ring from the current (just cleared) counters.
+func (c *wtinyLFUCache) resetWindow() {
+	s := c.windowSampleNow()
+	c.window.mu.Lock()
+	c.window.reset(s)
+	c.window.mu.Unlock()
+}
+
+// runStatsWindow samples the counters every interval until Close is called,
+// evaluating Config.HitRatioAlert after each sample.
+func (c *wtinyLFUCache) runStatsWindow(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.window.stop:
+			return
+		case <-ticker.C:
+			c.sampleWindow()
+			if c.hitRatioAlert != nil {
+				c.checkHitRatio()
+			}
+		}
+	}
+}
+
+// WindowStats returns the activity of the last window (at most
+// Config.StatsWindow, accurate to StatsWindow/60). Zero if StatsWindow is
+// not configured.
+func (c *wtinyLFUCache) WindowStats(window time.Duration) WindowStats {
+	if c.window == nil || window <= 0 {
+		return WindowStats{}
+	}
+	now := c.windowSampleNow()
+
+	c.window.mu.Lock()
+	base := c.window.baseline(now.at - int64(window))
+	c.window.mu.Unlock()
+
+	return WindowStats{
+		Window:      time.Duration(now.at - base.at),
+		Hits:        counterDelta(now.hits, base.hits),
+		Misses:      counterDelta(now.misses, base.misses),
+		Sets:        counterDelta(now.sets, base.sets),
+		Deletes:     counterDelta(now.deletes, base.deletes),
+		Evictions:   counterDelta(now.evictions, base.evictions),
+		Expirations: counterDelta(now.expirations, base.expirations),
+	}
+}
+
+// counterDelta returns now-base, or 0 if a concurrent Clear reset the counter
+// after base was sampled.
+func counterDelta(now, base uint64) uint64 {
+	if now < base {
+		return 0
+	}
+	return now - base
+}
+
+// WindowStats returns the activity of the last window.
+func (c *GenericCache[K, V]) WindowStats(window time.Duration) WindowStats {
+	return c.inner.WindowStats(window)
+}
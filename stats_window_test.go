@@ -0,0 +1,200 @@
+// stats_window_test.go: tests for rolling-window statistics
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newWindowCache returns a cache with a 10 minute StatsWindow on a mock
+// clock. Samples are taken by calling sampleWindow, as the background
+// sampler runs on the real clock.
+func newWindowCache(t *testing.T) (*wtinyLFUCache, *MockTimeProvider) {
+	t.Helper()
+	clock := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{
+		MaxSize:      100,
+		TimeProvider: clock,
+		StatsWindow:  10 * time.Minute,
+	}).(*wtinyLFUCache)
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache, clock
+}
+
+func TestWindowStats_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	if ws := cache.WindowStats(5 * time.Minute); ws != (WindowStats{}) {
+		t.Errorf("WindowStats without StatsWindow = %+v, want zero", ws)
+	}
+}
+
+func TestWindowStats_OnlyRecentActivity(t *testing.T) {
+	cache, clock := newWindowCache(t)
+
+	// Old traffic: 10 misses, then 10 minutes of sampling
+	for i := 0; i < 10; i++ {
+		cache.Get("missing")
+	}
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Minute)
+		cache.sampleWindow()
+	}
+
+	// Recent traffic: 3 hits, 1 miss, 1 set
+	cache.Set("a", 1)
+	for i := 0; i < 3; i++ {
+		cache.Get("a")
+	}
+	cache.Get("missing")
+	clock.Advance(30 * time.Second)
+
+	ws := cache.WindowStats(time.Minute)
+	if ws.Hits != 3 || ws.Misses != 1 || ws.Sets != 1 {
+		t.Errorf("WindowStats(1m) = %+v, want 3 hits, 1 miss, 1 set", ws)
+	}
+	if ws.HitRatio() != 75 {
+		t.Errorf("HitRatio = %v, want 75", ws.HitRatio())
+	}
+	if ws.Window != 90*time.Second {
+		t.Errorf("Window = %v, want 1m30s (last sample before the cutoff)", ws.Window)
+	}
+
+	all := cache.WindowStats(10 * time.Minute)
+	if all.Misses != 11 || all.Hits != 3 {
+		t.Errorf("WindowStats(10m) = %+v, want 11 misses, 3 hits", all)
+	}
+	if stats := cache.Stats(); stats.Misses != 11 {
+		t.Errorf("cumulative Misses = %d, want 11", stats.Misses)
+	}
+}
+
+func TestWindowStats_YoungCacheCoversItsLifetime(t *testing.T) {
+	cache, clock := newWindowCache(t)
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	clock.Advance(2 * time.Minute)
+
+	ws := cache.WindowStats(5 * time.Minute)
+	if ws.Window != 2*time.Minute {
+		t.Errorf("Window = %v, want 2m (cache age)", ws.Window)
+	}
+	if ws.Hits != 1 || ws.Sets != 1 {
+		t.Errorf("WindowStats = %+v, want 1 hit, 1 set", ws)
+	}
+}
+
+func TestWindowStats_RingDropsOldSamples(t *testing.T) {
+	cache, clock := newWindowCache(t)
+
+	cache.Get("missing")
+	// 2 windows worth of samples: the first miss falls out of the ring
+	for i := 0; i < 2*statsWindowBuckets; i++ {
+		clock.Advance(10 * time.Second)
+		cache.sampleWindow()
+	}
+
+	ws := cache.WindowStats(time.Hour)
+	if ws.Misses != 0 {
+		t.Errorf("Misses = %d, want 0 (older than StatsWindow)", ws.Misses)
+	}
+	if ws.Window != 10*time.Minute {
+		t.Errorf("Window = %v, want the 10m StatsWindow", ws.Window)
+	}
+}
+
+func TestWindowStats_ResetByClear(t *testing.T) {
+	cache, clock := newWindowCache(t)
+
+	for i := 0; i < 5; i++ {
+		cache.Get("missing")
+	}
+	clock.Advance(time.Minute)
+	cache.sampleWindow()
+	cache.Clear()
+	cache.Get("missing")
+	clock.Advance(time.Minute)
+
+	ws := cache.WindowStats(10 * time.Minute)
+	if ws.Misses != 1 {
+		t.Errorf("Misses after Clear = %d, want 1", ws.Misses)
+	}
+	if ws.Window != time.Minute {
+		t.Errorf("Window = %v, want 1m (since Clear)", ws.Window)
+	}
+}
+
+func TestWindowStats_Generic(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, StatsWindow: time.Minute})
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	if ws := cache.WindowStats(time.Minute); ws.Hits != 1 || ws.Sets != 1 {
+		t.Errorf("WindowStats = %+v, want 1 hit, 1 set", ws)
+	}
+}
+
+func TestWindowStats_SamplerStopsOnClose(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, StatsWindow: 60 * time.Millisecond}).(*wtinyLFUCache)
+	time.Sleep(5 * time.Millisecond)
+	_ = cache.Close()
+	_ = cache.Close() // idempotent
+}
+
+func TestStatsHandler_Window(t *testing.T) {
+	cache, clock := newWindowCache(t)
+
+	cache.Get("missing")
+	clock.Advance(5 * time.Minute)
+	cache.sampleWindow()
+	cache.Set("a", 1)
+	cache.Get("a")
+	clock.Advance(time.Minute)
+
+	rec := httptest.NewRecorder()
+	StatsHandler(cache).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats?window=1m", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var report StatsReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if report.Hits != 1 || report.Misses != 0 || report.HitRatio != 100 || report.Size != 1 {
+		t.Errorf("unexpected window report: %+v", report)
+	}
+	if report.Window != "1m0s" {
+		t.Errorf("Window = %q, want 1m0s", report.Window)
+	}
+
+	for _, query := range []string{"?window=soon", "?window=-1m"} {
+		rec = httptest.NewRecorder()
+		StatsHandler(cache).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestStatsHandler_WindowUnsupported(t *testing.T) {
+	provider := struct{ StatsProvider }{NewCache(Config{MaxSize: 10})}
+
+	rec := httptest.NewRecorder()
+	StatsHandler(provider).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats?window=1m", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,671 @@
+// swappable.go: zero-downtime cache replacement behind a stable handle
+//
+// SwappableCache is a Cache handle whose backing cache can be replaced
+// atomically.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"io"
+	"iter"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// SwappableCache is a Cache whose backing cache can be replaced atomically.
+//
+// Example:
+//
+//	users := balios.NewSwappableCache(balios.NewCache(balios.Config{MaxSize: 10_000}))
+//	// ... hand users to the rest of the application ...
+//
+//	bigger := balios.NewCache(balios.Config{MaxSize: 100_000})
+//	var snapshot bytes.Buffer
+//	if _, err := users.SaveTo(&snapshot); err == nil {
+//	    _, _ = bigger.LoadFrom(&snapshot)
+//	}
+//	old := users.Replace(bigger)
+//	_ = old.Close()
+type SwappableCache struct {
+	current  atomic.Pointer[swappableRef]
+	keyLocks keyLocks // Owned by the handle, so LockKey holds across swaps
+}
+
+// swappableRef boxes the current cache for atomic.Pointer, with the number
+// of handle calls running on it.
+type swappableRef struct {
+	cache extendedCache
+	calls atomic.Int64
+}
+
+// acquire returns the current cache, counted as in use until release.
+func (s *SwappableCache) acquire() *swappableRef {
+	for {
+		r := s.current.Load()
+		r.calls.Add(1)
+		if s.current.Load() == r {
+			return r
+		}
+		r.calls.Add(-1) // Replaced meanwhile: its drain may have finished
+	}
+}
+
+// release ends a call started by acquire.
+func (r *swappableRef) release() {
+	r.calls.Add(-1)
+}
+
+// drain waits until no handle call runs on r.
+func (r *swappableRef) drain() {
+	for spins := 0; r.calls.Load() != 0; spins++ {
+		if spins < drainSpins {
+			runtime.Gosched()
+		} else {
+			time.Sleep(drainSleep)
+		}
+	}
+}
+
+// A drain yields drainSpins times before sleeping drainSleep between checks:
+// most calls return within microseconds, loaders may take much longer.
+const (
+	drainSpins = 64
+	drainSleep = 100 * time.Microsecond
+)
+
+// NewSwappableCache returns a handle serving c (which must not be nil) until
+// it is replaced.
+func NewSwappableCache(c Cache) *SwappableCache {
+	s := &SwappableCache{}
+	s.current.Store(&swappableRef{cache: extend(c)})
+	return s
+}
+
+// Current returns the cache currently served by the handle. Calls made on
+// the returned cache directly are not waited for by Replace.
+func (s *SwappableCache) Current() Cache {
+	return unextend(s.current.Load().cache)
+}
+
+// Replace atomically makes next the cache served by the handle and returns
+// the previous one, which the caller is responsible for closing. Replace
+// returns once the handle calls running on the previous cache have
+// returned, so it must not be called from a callback (loader, Compute
+// function, Range function...) running on the handle. A nil next is ignored
+// and returns nil.
+func (s *SwappableCache) Replace(next Cache) (previous Cache) {
+	if next == nil {
+		return nil
+	}
+	old := s.swap(next)
+	old.drain()
+	return unextend(old.cache)
+}
+
+// swap makes next the cache served by the handle and returns the reference
+// to the previous one, still to be drained.
+func (s *SwappableCache) swap(next Cache) *swappableRef {
+	return s.current.Swap(&swappableRef{cache: extend(next)})
+}
+
+// NewGenericCacheFrom returns a type-safe view of inner, for instance of a
+// SwappableCache so that typed references pick up replacements too. Values
+// stored through other views must be of type V.
+func NewGenericCacheFrom[K comparable, V any](inner Cache) *GenericCache[K, V] {
+	return &GenericCache[K, V]{inner: extend(inner)}
+}
+
+// Replace atomically swaps the cache behind the SwappableCache registered
+// under name for next, waits for the calls still running on the replaced
+// cache (see SwappableCache.Replace) without holding the manager lock, then
+// closes it. Callers holding the handle pick up next on their next call.
+// Warm next before replacing.
+//
+// Returns a BALIOS_INVALID_CONFIG error if name is unknown, is not a
+// *SwappableCache, next is nil or the manager is shut down, and a
+// BALIOS_SHUTDOWN_FAILED error if closing the replaced cache fails (next
+// stays in place).
+func (m *Manager) Replace(name string, next Cache) error {
+	old, err := m.swap(name, next)
+	if err != nil {
+		return err
+	}
+	// Drain outside m.mu: calls on the old cache may take as long as a loader
+	old.drain()
+	if err := old.cache.Close(); err != nil {
+		return NewErrShutdownFailed(name, err)
+	}
+	return nil
+}
+
+// swap implements the checks and the swap of Replace under m.mu, returning
+// the reference to the replaced cache.
+func (m *Manager) swap(name string, next Cache) (*swappableRef, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shutdown {
+		return nil, NewErrInvalidConfig(name, "manager is shut down")
+	}
+	if next == nil {
+		return nil, NewErrInvalidConfig(name, "replacement cache cannot be nil")
+	}
+	c := m.components[name]
+	if c == nil {
+		return nil, NewErrInvalidConfig(name, "component not registered")
+	}
+	handle, ok := c.closer.(*SwappableCache)
+	if !ok {
+		return nil, NewErrInvalidConfig(name, "component is not a *SwappableCache")
+	}
+	return handle.swap(next), nil
+}
+
+// peekStale forwards to the current cache (see revalidate.go).
+func (s *SwappableCache) peekStale(key string) (interface{}, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	if peeker, ok := ref.cache.(stalePeeker); ok {
+		return peeker.peekStale(key)
+	}
+	return nil, false
+}
+
+// Get retrieves a value from the current cache.
+func (s *SwappableCache) Get(key string) (interface{}, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Get(key)
+}
+
+// Set stores a key-value pair in the current cache.
+func (s *SwappableCache) Set(key string, value interface{}) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Set(key, value)
+}
+
+// Delete removes key from the current cache.
+func (s *SwappableCache) Delete(key string) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Delete(key)
+}
+
+// Has checks if key exists in the current cache.
+func (s *SwappableCache) Has(key string) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Has(key)
+}
+
+// Len returns the number of entries of the current cache.
+func (s *SwappableCache) Len() int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Len()
+}
+
+// Capacity returns the capacity of the current cache.
+func (s *SwappableCache) Capacity() int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Capacity()
+}
+
+// Clear removes all entries from the current cache.
+func (s *SwappableCache) Clear() {
+	ref := s.acquire()
+	defer ref.release()
+	ref.cache.Clear()
+}
+
+// GetE is like Get but reports why a value was not returned.
+func (s *SwappableCache) GetE(key string) (interface{}, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetE(key)
+}
+
+// GetWithReason is like Get but on a miss also reports why.
+func (s *SwappableCache) GetWithReason(key string) (interface{}, MissReason, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetWithReason(key)
+}
+
+// GetCtx is like Get but retries through write contention until ctx is done.
+func (s *SwappableCache) GetCtx(ctx context.Context, key string) (interface{}, bool, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetCtx(ctx, key)
+}
+
+// SetE is like Set but returns the reason of a failure.
+func (s *SwappableCache) SetE(key string, value interface{}) error {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SetE(key, value)
+}
+
+// SetCtx is like Set but retries through write contention until ctx is done.
+func (s *SwappableCache) SetCtx(ctx context.Context, key string, value interface{}) error {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SetCtx(ctx, key, value)
+}
+
+// GetWithExpiry is like Get but also returns when the entry expires.
+func (s *SwappableCache) GetWithExpiry(key string) (interface{}, time.Time, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetWithExpiry(key)
+}
+
+// GetPrevious returns the value key held before its last replacement.
+func (s *SwappableCache) GetPrevious(key string) (interface{}, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetPrevious(key)
+}
+
+// History returns the previous values of key, newest first.
+func (s *SwappableCache) History(key string) []interface{} {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.History(key)
+}
+
+// GetMany retrieves several keys at once.
+func (s *SwappableCache) GetMany(keys []string) map[string]interface{} {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetMany(keys)
+}
+
+// SetMany stores every key-value pair of entries.
+func (s *SwappableCache) SetMany(entries map[string]interface{}) int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SetMany(entries)
+}
+
+// Warm preloads entries into the current cache.
+func (s *SwappableCache) Warm(entries map[string]interface{}) int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Warm(entries)
+}
+
+// WarmSeq preloads a stream of entries into the current cache.
+func (s *SwappableCache) WarmSeq(entries iter.Seq2[string, interface{}]) int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.WarmSeq(entries)
+}
+
+// Stats returns the statistics of the current cache.
+func (s *SwappableCache) Stats() CacheStats {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Stats()
+}
+
+// SaveTo writes a snapshot of the current cache.
+func (s *SwappableCache) SaveTo(w io.Writer) (int, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SaveTo(w)
+}
+
+// LoadFrom restores a snapshot into the current cache.
+func (s *SwappableCache) LoadFrom(r io.Reader) (int, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.LoadFrom(r)
+}
+
+// SaveToFile writes a snapshot of the current cache to path.
+func (s *SwappableCache) SaveToFile(path string) (int, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SaveToFile(path)
+}
+
+// LoadFromFile restores a snapshot file into the current cache.
+func (s *SwappableCache) LoadFromFile(path string) (int, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.LoadFromFile(path)
+}
+
+// Export writes the entries of the current cache to w in format.
+func (s *SwappableCache) Export(w io.Writer, format ExportFormat) (int, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Export(w, format)
+}
+
+// Import restores a document written by Export into the current cache.
+func (s *SwappableCache) Import(r io.Reader, format ExportFormat) (int, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Import(r, format)
+}
+
+// GetOrLoad returns the value of key, loading it on a miss.
+func (s *SwappableCache) GetOrLoad(key string, loader func() (interface{}, error), opts ...LoadOption) (interface{}, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetOrLoad(key, loader, opts...)
+}
+
+// GetOrLoadWithContext is like GetOrLoad but respects ctx.
+func (s *SwappableCache) GetOrLoadWithContext(ctx context.Context, key string, loader func(context.Context) (interface{}, error), opts ...LoadOption) (interface{}, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetOrLoadWithContext(ctx, key, loader, opts...)
+}
+
+// GetOrLoadMany returns the values of keys, loading the missing ones in one call.
+func (s *SwappableCache) GetOrLoadMany(keys []string, loader func(missing []string) (map[string]interface{}, error), opts ...LoadOption) (map[string]interface{}, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetOrLoadMany(keys, loader, opts...)
+}
+
+// GetOrLoadGrouped returns the value of key, loading concurrent misses of
+// groupKey in one call.
+func (s *SwappableCache) GetOrLoadGrouped(groupKey, key string, loader func(keys []string) (map[string]interface{}, error), opts ...LoadOption) (interface{}, error) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetOrLoadGrouped(groupKey, key, loader, opts...)
+}
+
+// TopKeys returns the n most accessed keys of the current cache.
+func (s *SwappableCache) TopKeys(n int) []KeyFreq {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.TopKeys(n)
+}
+
+// Subscribe returns a channel receiving the events of the current cache; a
+// cache swapped in later does not publish to it.
+func (s *SwappableCache) Subscribe(mask EventMask) <-chan Event {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Subscribe(mask)
+}
+
+// Unsubscribe closes ch, a channel returned by Subscribe on the current cache.
+func (s *SwappableCache) Unsubscribe(ch <-chan Event) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Unsubscribe(ch)
+}
+
+// LockKey locks key against other LockKey callers of the handle; the lock
+// is not tied to the current cache, so it holds across Replace.
+func (s *SwappableCache) LockKey(key string) func() {
+	return s.keyLocks.lock(stringHash(key))
+}
+
+// ExpireNow removes the expired entries of the current cache.
+func (s *SwappableCache) ExpireNow() int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.ExpireNow()
+}
+
+// DecaySketch ages the access frequencies of the current cache.
+func (s *SwappableCache) DecaySketch() {
+	ref := s.acquire()
+	defer ref.release()
+	ref.cache.DecaySketch()
+}
+
+// NextExpiration returns the next expiration of the current cache.
+func (s *SwappableCache) NextExpiration() (time.Time, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.NextExpiration()
+}
+
+// ExpiringBefore returns the keys of the current cache expiring by t.
+func (s *SwappableCache) ExpiringBefore(t time.Time) []string {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.ExpiringBefore(t)
+}
+
+// SketchSnapshot exports the access frequencies of the current cache.
+func (s *SwappableCache) SketchSnapshot() []byte {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SketchSnapshot()
+}
+
+// RestoreSketch imports access frequencies into the current cache.
+func (s *SwappableCache) RestoreSketch(data []byte) error {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.RestoreSketch(data)
+}
+
+// CompareAndSwap conditionally replaces the value of key.
+func (s *SwappableCache) CompareAndSwap(key string, oldValue, newValue interface{}) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.CompareAndSwap(key, oldValue, newValue)
+}
+
+// Swap stores value for key and returns the previous value.
+func (s *SwappableCache) Swap(key string, value interface{}) (interface{}, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Swap(key, value)
+}
+
+// SetIfAbsent stores value for key in the current cache if key is absent.
+func (s *SwappableCache) SetIfAbsent(key string, value interface{}) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SetIfAbsent(key, value)
+}
+
+// Compute atomically transforms the value of key in the current cache.
+func (s *SwappableCache) Compute(key string, fn func(old interface{}, exists bool) (interface{}, bool)) (interface{}, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Compute(key, fn)
+}
+
+// Resize sets the capacity of the current cache.
+func (s *SwappableCache) Resize(newMaxSize int) error {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Resize(newMaxSize)
+}
+
+// CompactionReport reports the memory retained by the current cache.
+func (s *SwappableCache) CompactionReport() CompactionReport {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.CompactionReport()
+}
+
+// Compact releases the memory retained by the current cache.
+func (s *SwappableCache) Compact() CompactionReport {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Compact()
+}
+
+// InvalidateNegative removes the cached loader error of key in the current cache.
+func (s *SwappableCache) InvalidateNegative(key string) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.InvalidateNegative(key)
+}
+
+// NegativeLen returns the cached loader errors of the current cache.
+func (s *SwappableCache) NegativeLen() int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.NegativeLen()
+}
+
+// LoadsNotAdmitted returns the loads not admitted by the current cache.
+func (s *SwappableCache) LoadsNotAdmitted() int64 {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.LoadsNotAdmitted()
+}
+
+// LoadsRateLimited returns the loads rate limited by the current cache.
+func (s *SwappableCache) LoadsRateLimited() int64 {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.LoadsRateLimited()
+}
+
+// SetWithDependencies stores a key-value pair derived from deps.
+func (s *SwappableCache) SetWithDependencies(key string, value interface{}, deps ...string) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SetWithDependencies(key, value, deps...)
+}
+
+// InvalidateKey deletes dep and its dependents from the current cache.
+func (s *SwappableCache) InvalidateKey(dep string) int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.InvalidateKey(dep)
+}
+
+// ClearNamespace removes the entries of ns from the current cache.
+func (s *SwappableCache) ClearNamespace(ns string) int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.ClearNamespace(ns)
+}
+
+// MemoryUsage reports the memory recorded by the current cache.
+func (s *SwappableCache) MemoryUsage(top int) MemoryReport {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.MemoryUsage(top)
+}
+
+// DebugStats returns the internal diagnostics of the current cache.
+func (s *SwappableCache) DebugStats() DebugStats {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.DebugStats()
+}
+
+// WindowStats returns the recent activity of the current cache.
+func (s *SwappableCache) WindowStats(window time.Duration) WindowStats {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.WindowStats(window)
+}
+
+// ShrinkStats returns the shrinker counters of the current cache.
+func (s *SwappableCache) ShrinkStats() ShrinkStats {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.ShrinkStats()
+}
+
+// SetWithSource stores a key-value pair tagged with source.
+func (s *SwappableCache) SetWithSource(key string, value interface{}, source string) bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SetWithSource(key, value, source)
+}
+
+// Range calls f for each live entry of the current cache.
+func (s *SwappableCache) Range(f func(key string, value interface{}) bool) {
+	ref := s.acquire()
+	defer ref.release()
+	ref.cache.Range(f)
+}
+
+// Keys returns the live keys of the current cache.
+func (s *SwappableCache) Keys() []string {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Keys()
+}
+
+// DeleteByPrefix removes the entries of the current cache matching prefix.
+func (s *SwappableCache) DeleteByPrefix(prefix string) int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.DeleteByPrefix(prefix)
+}
+
+// DeleteFunc removes the entries of the current cache for which f returns true.
+func (s *SwappableCache) DeleteFunc(f func(key string, value interface{}) bool) int {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.DeleteFunc(f)
+}
+
+// GetVersion returns the value and version of key in the current cache.
+func (s *SwappableCache) GetVersion(key string) (interface{}, uint64, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.GetVersion(key)
+}
+
+// VersionOf returns the version of a live entry of the current cache.
+func (s *SwappableCache) VersionOf(key string) (uint64, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.VersionOf(key)
+}
+
+// SetIfVersion conditionally stores value for key in the current cache.
+// Versions read before Replace are unlikely to match the new cache (see
+// versions.go).
+func (s *SwappableCache) SetIfVersion(key string, value interface{}, version uint64) (uint64, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SetIfVersion(key, value, version)
+}
+
+// SourceOf returns the source tag of a live entry of the current cache.
+func (s *SwappableCache) SourceOf(key string) (string, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.SourceOf(key)
+}
+
+// EntryInfo returns the metadata of a live entry of the current cache.
+func (s *SwappableCache) EntryInfo(key string) (EntryInfo, bool) {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.EntryInfo(key)
+}
+
+// Close closes the current cache.
+func (s *SwappableCache) Close() error {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Close()
+}
+
+// Closed reports whether the current cache is closed.
+func (s *SwappableCache) Closed() bool {
+	ref := s.acquire()
+	defer ref.release()
+	return ref.cache.Closed()
+}
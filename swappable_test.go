@@ -0,0 +1,251 @@
+// swappable_test.go: tests for SwappableCache and Manager.Replace
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSwappableCache_Replace(t *testing.T) {
+	small := NewCache(Config{MaxSize: 10})
+	handle := NewSwappableCache(small)
+	handle.Set("a", 1)
+
+	big := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	var snapshot bytes.Buffer
+	if _, err := handle.SaveTo(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := big.LoadFrom(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if previous := handle.Replace(big); previous != small {
+		t.Error("Replace must return the previous cache")
+	}
+	if handle.Current() != big || handle.Capacity() != big.Capacity() {
+		t.Error("the handle must serve the new cache")
+	}
+	if v, ok := handle.Get("a"); !ok || v != 1 {
+		t.Errorf("warmed entry = %v, %v", v, ok)
+	}
+	if handle.Replace(nil) != nil || handle.Current() != big {
+		t.Error("a nil replacement must be ignored")
+	}
+}
+
+func TestSwappableCache_ConcurrentReplace(t *testing.T) {
+	handle := NewSwappableCache(NewCache(Config{MaxSize: 100}))
+	var stop int32
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; atomic.LoadInt32(&stop) == 0; i++ {
+				key := fmt.Sprintf("w%d-%d", w, i%50)
+				handle.Set(key, i)
+				handle.Get(key)
+			}
+		}(w)
+	}
+	for i := 0; i < 20; i++ {
+		handle.Replace(NewCache(Config{MaxSize: 100 + i}))
+	}
+	atomic.StoreInt32(&stop, 1)
+	wg.Wait()
+	if handle.Capacity() != NewCache(Config{MaxSize: 119}).Capacity() {
+		t.Error("the last replacement must be served")
+	}
+}
+
+func TestSwappableCache_Generic(t *testing.T) {
+	handle := NewSwappableCache(NewCache(Config{MaxSize: 10}))
+	typed := NewGenericCacheFrom[int, string](handle)
+	typed.Set(1, "one")
+
+	next := NewCache(Config{MaxSize: 100})
+	next.Set("1", "uno")
+	handle.Replace(next)
+	if v, ok := typed.Get(1); !ok || v != "uno" {
+		t.Errorf("typed views must pick up the replacement, got %v, %v", v, ok)
+	}
+}
+
+// closeCountingCache is a Cache recording Close calls.
+type closeCountingCache struct {
+	Cache
+	closed int32
+	err    error
+}
+
+func (c *closeCountingCache) Close() error {
+	atomic.AddInt32(&c.closed, 1)
+	return c.err
+}
+
+func TestManager_Replace(t *testing.T) {
+	m := NewManager()
+	old := &closeCountingCache{Cache: NewCache(Config{MaxSize: 10})}
+	handle := NewSwappableCache(old)
+	if err := m.Register("users", handle); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register("plain", NewCache(Config{MaxSize: 10})); err != nil {
+		t.Fatal(err)
+	}
+
+	next := NewCache(Config{MaxSize: 100})
+	if err := m.Replace("users", next); err != nil {
+		t.Fatal(err)
+	}
+	if handle.Current() != next || atomic.LoadInt32(&old.closed) != 1 {
+		t.Error("Replace must swap the handle and close the replaced cache")
+	}
+	if c, _ := m.Cache("users"); c != handle {
+		t.Error("the registered handle must stay the same")
+	}
+
+	for name, target := range map[string]Cache{"plain": next, "missing": next, "users": nil} {
+		if err := m.Replace(name, target); GetErrorCode(err) != ErrCodeInvalidConfig {
+			t.Errorf("Replace(%s) = %v, want %s", name, err, ErrCodeInvalidConfig)
+		}
+	}
+
+	failing := &closeCountingCache{Cache: next, err: errors.New("boom")}
+	handle.Replace(failing)
+	final := NewCache(Config{MaxSize: 10})
+	if err := m.Replace("users", final); GetErrorCode(err) != ErrCodeShutdownFailed {
+		t.Errorf("expected %s, got %v", ErrCodeShutdownFailed, err)
+	}
+	if handle.Current() != final {
+		t.Error("the replacement must stay in place when closing the old cache fails")
+	}
+
+	_ = m.Shutdown(context.Background())
+	if err := m.Replace("users", next); GetErrorCode(err) != ErrCodeInvalidConfig {
+		t.Errorf("Replace after Shutdown = %v", err)
+	}
+}
+
+func TestSwappableCache_ReplaceWaitsForCalls(t *testing.T) {
+	old := NewCache(Config{MaxSize: 10})
+	handle := NewSwappableCache(old)
+
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = handle.GetOrLoad("k", func() (interface{}, error) {
+			close(loading)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-loading
+
+	replaced := make(chan Cache)
+	go func() { replaced <- handle.Replace(NewCache(Config{MaxSize: 10})) }()
+	select {
+	case <-replaced:
+		t.Fatal("Replace returned while a call was running on the previous cache")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if previous := <-replaced; previous != old {
+		t.Error("Replace must return the previous cache")
+	}
+	if v, ok := old.Get("k"); !ok || v != 1 {
+		t.Errorf("the call must complete on the previous cache, got %v, %v", v, ok)
+	}
+}
+
+func TestManager_ReplaceDrainsOutsideLock(t *testing.T) {
+	m := NewManager()
+	handle := NewSwappableCache(NewCache(Config{MaxSize: 10}))
+	if err := m.Register("users", handle); err != nil {
+		t.Fatal(err)
+	}
+
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = handle.GetOrLoad("k", func() (interface{}, error) {
+			close(loading)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-loading
+
+	replaced := make(chan error)
+	go func() { replaced <- m.Replace("users", NewCache(Config{MaxSize: 10})) }()
+	time.Sleep(10 * time.Millisecond)
+
+	// The drain waits for the load, the Manager must not
+	registered := make(chan error)
+	go func() { registered <- m.Register("orders", NewCache(Config{MaxSize: 10})) }()
+	select {
+	case err := <-registered:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Register blocked while Replace was draining the previous cache")
+	}
+	close(release)
+	if err := <-replaced; err != nil {
+		t.Fatal(err)
+	}
+	_ = m.Shutdown(context.Background())
+}
+
+func TestManager_ReplaceUnderLoad(t *testing.T) {
+	m := NewManager()
+	handle := NewSwappableCache(NewCache(Config{MaxSize: 1000}))
+	if err := m.Register("users", handle); err != nil {
+		t.Fatal(err)
+	}
+
+	var stop int32
+	var failedSets, closedLoads int64
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; atomic.LoadInt32(&stop) == 0; i++ {
+				key := fmt.Sprintf("w%d-%d", w, i%50)
+				if !handle.Set(key, i) {
+					atomic.AddInt64(&failedSets, 1)
+				}
+				handle.Get(key)
+				_, err := handle.GetOrLoad(key+"-loaded", func() (interface{}, error) { return i, nil })
+				if IsCacheClosed(err) {
+					atomic.AddInt64(&closedLoads, 1)
+				}
+			}
+		}(w)
+	}
+	for i := 0; i < 20; i++ {
+		if err := m.Replace("users", NewCache(Config{MaxSize: 1000})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	atomic.StoreInt32(&stop, 1)
+	wg.Wait()
+
+	if failedSets != 0 || closedLoads != 0 {
+		t.Errorf("calls ran on a closed cache: %d failed Sets, %d BALIOS_CACHE_CLOSED loads", failedSets, closedLoads)
+	}
+}
@@ -0,0 +1,96 @@
+// table.go: the slot table and its per-slot state
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// cacheTable holds the slots of a cache and every structure sized or
+// indexed by them. The cache publishes it through an atomic pointer: an
+// operation loads it once and uses only that copy, so Close can drop it
+// (nil pointer) while operations are in flight.
+type cacheTable struct {
+	entries []entry
+	mask    uint32
+	gen     atomic.Pointer[generation] // Current generation of the entries (see generation.go)
+
+	times          []entryTimes // Per-slot timestamps (nil unless Config.TrackEntryTimes, see entry_info.go)
+	writeDeadlines []int64      // Per-slot write deadline capping the idle deadline (nil unless Config.TTI, see tti.go)
+	departures     []uint64     // Last departure per slot (nil unless Config.TrackMissReasons, see miss_reason.go)
+
+	// W-TinyLFU frequency sketch (already lock-free)
+	sketch *frequencySketch
+
+	admissionWindow *admissionWindow // nil unless Config.AdaptiveWindow (see adaptive_window.go)
+
+	limit   int32                      // Largest capacity the table is sized for
+	sealed  atomic.Bool                // Set while Resize migrates the table (see resize.go)
+	writers [writerStripes]writerCount // Writers registered on the table, striped by key hash
+}
+
+// newCacheTable allocates the table of a cache, sized for Config.ResizeLimit.
+func newCacheTable(config Config) *cacheTable {
+	t := newSizedTable(config.ResizeLimit)
+	tableSize := len(t.entries)
+	if config.TrackEntryTimes {
+		t.times = make([]entryTimes, tableSize)
+	}
+	if config.TTI > 0 {
+		t.writeDeadlines = make([]int64, tableSize)
+	}
+	if config.TrackMissReasons {
+		t.departures = make([]uint64, tableSize)
+	}
+	if config.AdaptiveWindow {
+		t.admissionWindow = newAdmissionWindow(config.MaxSize, config.WindowRatio)
+	}
+	return t
+}
+
+// grown allocates an empty table with the per-slot arrays of t, sized for
+// capacities up to limit. It shares the admission window of t.
+func (t *cacheTable) grown(limit int) *cacheTable {
+	next := newSizedTable(limit)
+	tableSize := len(next.entries)
+	if t.times != nil {
+		next.times = make([]entryTimes, tableSize)
+	}
+	if t.writeDeadlines != nil {
+		next.writeDeadlines = make([]int64, tableSize)
+	}
+	if t.departures != nil {
+		next.departures = make([]uint64, tableSize)
+	}
+	next.admissionWindow = t.admissionWindow
+	return next
+}
+
+// newSizedTable allocates the slots and the sketch of a table for capacities
+// up to limit: a power of 2, at least 2x limit for a good load factor.
+func newSizedTable(limit int) *cacheTable {
+	tableSize := nextPowerOf2(limit * 2)
+	if tableSize < 16 {
+		tableSize = 16
+	}
+
+	t := &cacheTable{
+		entries: make([]entry, tableSize),
+		mask:    uint32(tableSize - 1), // #nosec G115 - tableSize is power of 2, safe conversion
+		sketch:  newFrequencySketch(limit),
+		limit:   int32(limit), // #nosec G115 - bounded by maxCapacity
+	}
+	t.gen.Store(newGeneration(0))
+	return t
+}
+
+// slotOf returns the index of e in t.entries.
+func (t *cacheTable) slotOf(e *entry) uintptr {
+	// #nosec G103 -- e points into t.entries
+	return (uintptr(unsafe.Pointer(e)) - uintptr(unsafe.Pointer(&t.entries[0]))) / unsafe.Sizeof(entry{})
+}
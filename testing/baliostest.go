@@ -0,0 +1,143 @@
+// baliostest.go: test helpers for code using balios caches
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+// Package baliostest makes balios caches deterministic in tests: a fake
+// clock drives expiration, a fixed seed drives eviction sampling and TTL
+// jitter, and assertions check why a key left the cache.
+//
+// The package lives at github.com/agilira/balios/testing; import it as
+// baliostest to keep the standard testing package at hand:
+//
+//	import baliostest "github.com/agilira/balios/testing"
+//
+//	func TestSessionExpires(t *testing.T) {
+//	    cfg, clock := baliostest.Config(balios.Config{MaxSize: 100, TTL: time.Minute})
+//	    cache := balios.NewCache(cfg)
+//	    defer cache.Close()
+//
+//	    cache.Set("session", token)
+//	    clock.Advance(2 * time.Minute)
+//	    baliostest.AssertExpired(t, cache, "session")
+//	}
+package baliostest
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agilira/balios"
+)
+
+// DefaultSeed is the RandSeed set by Config when none is given.
+const DefaultSeed = 1
+
+// Epoch is the time a Clock built by Config starts at.
+var Epoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a balios.TimeProvider whose time only moves when told to. It is
+// safe for concurrent use.
+type Clock struct {
+	now int64 // Unix nanoseconds (atomic)
+}
+
+// NewClock returns a Clock set to start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start.UnixNano()}
+}
+
+// Now returns the current time of the clock in Unix nanoseconds.
+func (c *Clock) Now() int64 {
+	return atomic.LoadInt64(&c.now)
+}
+
+// Time returns the current time of the clock.
+func (c *Clock) Time() time.Time {
+	return time.Unix(0, c.Now())
+}
+
+// Advance moves the clock forward by d (backward if d is negative).
+func (c *Clock) Advance(d time.Duration) {
+	atomic.AddInt64(&c.now, int64(d))
+}
+
+// Set moves the clock to t.
+func (c *Clock) Set(t time.Time) {
+	atomic.StoreInt64(&c.now, t.UnixNano())
+}
+
+// Config returns cfg made deterministic, with the Clock driving it: the
+// TimeProvider is a Clock starting at Epoch, RandSeed is DefaultSeed unless
+// set, and TrackMissReasons is enabled for AssertExpired and AssertEvicted.
+func Config(cfg balios.Config) (balios.Config, *Clock) {
+	clock := NewClock(Epoch)
+	cfg.TimeProvider = clock
+	if cfg.RandSeed == 0 {
+		cfg.RandSeed = DefaultSeed
+	}
+	cfg.TrackMissReasons = true
+	return cfg, clock
+}
+
+// Cache is the part of balios.Cache used by the assertions. AssertMiss,
+// AssertExpired and AssertEvicted also need balios.CheckedCache, which the
+// caches returned by balios.NewCache implement.
+type Cache interface {
+	Has(key string) bool
+}
+
+// AssertPresent fails the test if key is not cached. It does not count as
+// a Get in the cache statistics.
+func AssertPresent(t testing.TB, cache Cache, key string) {
+	t.Helper()
+	if !cache.Has(key) {
+		t.Errorf("balios: key %q is not cached", key)
+	}
+}
+
+// AssertAbsent fails the test if key is cached. It does not count as a Get
+// in the cache statistics.
+func AssertAbsent(t testing.TB, cache Cache, key string) {
+	t.Helper()
+	if cache.Has(key) {
+		t.Errorf("balios: key %q is cached", key)
+	}
+}
+
+// AssertMiss fails the test unless a Get of key misses for reason. The
+// cache must track miss reasons (Config.TrackMissReasons, set by Config).
+// Departures are recorded per table slot, so a key whose slot was reused by
+// a later departure misses as absent (see balios.MissReason).
+func AssertMiss(t testing.TB, cache Cache, key string, reason balios.MissReason) {
+	t.Helper()
+	checked, ok := cache.(balios.CheckedCache)
+	if !ok {
+		t.Errorf("balios: %T does not report miss reasons", cache)
+		return
+	}
+	value, got, found := checked.GetWithReason(key)
+	switch {
+	case found:
+		t.Errorf("balios: key %q is cached (value %v), want a miss: %v", key, value, reason)
+	case got != reason && got == balios.MissAbsent:
+		t.Errorf("balios: key %q missed as %v, want %v (is Config.TrackMissReasons set?)", key, got, reason)
+	case got != reason:
+		t.Errorf("balios: key %q missed as %v, want %v", key, got, reason)
+	}
+}
+
+// AssertExpired fails the test unless key left the cache because its TTL
+// elapsed.
+func AssertExpired(t testing.TB, cache Cache, key string) {
+	t.Helper()
+	AssertMiss(t, cache, key, balios.MissExpired)
+}
+
+// AssertEvicted fails the test unless key was evicted to make room.
+func AssertEvicted(t testing.TB, cache Cache, key string) {
+	t.Helper()
+	AssertMiss(t, cache, key, balios.MissEvicted)
+}
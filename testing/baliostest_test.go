@@ -0,0 +1,131 @@
+// baliostest_test.go: tests for the balios test helpers
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package baliostest_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agilira/balios"
+	baliostest "github.com/agilira/balios/testing"
+)
+
+// recorder is a testing.TB recording failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestClock(t *testing.T) {
+	start := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	clock := baliostest.NewClock(start)
+	if !clock.Time().Equal(start) || clock.Now() != start.UnixNano() {
+		t.Fatalf("clock starts at %v", clock.Time())
+	}
+	clock.Advance(time.Hour)
+	if got := clock.Time(); !got.Equal(start.Add(time.Hour)) {
+		t.Fatalf("Advance: clock at %v", got)
+	}
+	clock.Set(start)
+	if !clock.Time().Equal(start) {
+		t.Fatalf("Set: clock at %v", clock.Time())
+	}
+}
+
+func TestConfig(t *testing.T) {
+	cfg, clock := baliostest.Config(balios.Config{MaxSize: 10})
+	if cfg.TimeProvider != clock || cfg.RandSeed != baliostest.DefaultSeed || !cfg.TrackMissReasons {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if !clock.Time().Equal(baliostest.Epoch) {
+		t.Fatalf("clock starts at %v, want Epoch", clock.Time())
+	}
+	if cfg, _ := baliostest.Config(balios.Config{MaxSize: 10, RandSeed: 42}); cfg.RandSeed != 42 {
+		t.Fatal("Config must keep an explicit RandSeed")
+	}
+}
+
+func TestAssertExpired(t *testing.T) {
+	cfg, clock := baliostest.Config(balios.Config{MaxSize: 100, TTL: time.Minute})
+	cache := balios.NewCache(cfg)
+	defer cache.Close()
+
+	cache.Set("session", "token")
+	baliostest.AssertPresent(t, cache, "session")
+	clock.Advance(2 * time.Minute)
+	baliostest.AssertExpired(t, cache, "session")
+	baliostest.AssertAbsent(t, cache, "session")
+}
+
+func TestAssertEvicted(t *testing.T) {
+	cfg, _ := baliostest.Config(balios.Config{MaxSize: 50})
+	cache := balios.NewCache(cfg)
+	defer cache.Close()
+
+	for i := 0; i < 500; i++ {
+		cache.Set("k"+strconv.Itoa(i), i)
+	}
+	// Departure records are best effort: some evicted keys read as absent
+	passed := 0
+	for i := 0; i < 500; i++ {
+		if key := "k" + strconv.Itoa(i); !cache.Has(key) {
+			r := &recorder{TB: t}
+			baliostest.AssertEvicted(r, cache, key)
+			if len(r.failures) == 0 {
+				passed++
+			}
+		}
+	}
+	if passed == 0 {
+		t.Fatal("no evicted key was reported as evicted")
+	}
+}
+
+func TestAssertions_Fail(t *testing.T) {
+	cache := balios.NewCache(balios.Config{MaxSize: 100})
+	defer cache.Close()
+	cache.Set("present", 1)
+
+	r := &recorder{TB: t}
+	baliostest.AssertPresent(r, cache, "absent")
+	baliostest.AssertAbsent(r, cache, "present")
+	baliostest.AssertExpired(r, cache, "present")
+	baliostest.AssertEvicted(r, cache, "absent") // Miss reasons not tracked
+	if len(r.failures) != 4 {
+		t.Fatalf("expected 4 failures, got %q", r.failures)
+	}
+}
+
+func TestConfig_Deterministic(t *testing.T) {
+	survivors := func() string {
+		cfg, _ := baliostest.Config(balios.Config{MaxSize: 50})
+		cache := balios.NewCache(cfg)
+		defer cache.Close()
+		for i := 0; i < 500; i++ {
+			cache.Set("k"+strconv.Itoa(i), i)
+		}
+		var kept []byte
+		for i := 0; i < 500; i++ {
+			if cache.Has("k" + strconv.Itoa(i)) {
+				kept = strconv.AppendInt(kept, int64(i), 10)
+				kept = append(kept, ' ')
+			}
+		}
+		return string(kept)
+	}
+	if a, b := survivors(), survivors(); a != b {
+		t.Fatalf("same seed, different evictions:\n%s\n%s", a, b)
+	}
+}
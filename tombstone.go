@@ -0,0 +1,148 @@
+// tombstone.go: stampede damping after deletions (Config.TombstoneTTL)
+//
+// With Config.TombstoneTTL, a deletion leaves a tombstone that makes
+// GetOrLoad serve the deleted value or delay its reload.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// TombstoneMode selects what GetOrLoad does on a miss of a recently deleted
+// key (see Config.TombstoneTTL).
+type TombstoneMode int
+
+const (
+	// TombstoneServeStale returns the deleted value, without loading, until
+	// the tombstone expires.
+	TombstoneServeStale TombstoneMode = iota
+
+	// TombstoneDelayLoad loads the key after a random delay of up to
+	// Config.TombstoneJitter.
+	TombstoneDelayLoad
+)
+
+// tombstone is the record of a deleted key.
+type tombstone struct {
+	value    interface{} // Deleted value, decoded
+	expireAt int64
+}
+
+// jitterNanos returns a random duration in [0, c.tombstoneJitterNanos).
+func (c *wtinyLFUCache) jitterNanos() int64 {
+	if c.tombstoneJitterNanos <= 0 {
+		return 0
+	}
+	return int64(c.fastRand() % uint64(c.tombstoneJitterNanos)) // #nosec G115 - jitter is positive
+}
+
+// storeTombstone records the removal of entry by a deletion. Called by
+// removeAcquired while the entry is still owned.
+func (c *wtinyLFUCache) storeTombstone(entry *entry) {
+	removed := takeEvicted(entry)
+	if removed.key == "" {
+		return
+	}
+	value := removed.value
+	if c.valueCodec != nil {
+		value = c.decodedOrNil(value) // Arena slots are reused once the entry is gone
+	}
+	lifetime := c.tombstoneTTLNanos
+	if c.tombstoneMode == TombstoneServeStale {
+		lifetime += c.jitterNanos()
+	}
+	c.tombstones.Store(removed.key, &tombstone{value: value, expireAt: c.timeProvider.Now() + lifetime})
+}
+
+// dropTombstone forgets the tombstone of key, written again.
+func (c *wtinyLFUCache) dropTombstone(key string) {
+	c.tombstones.Delete(key)
+}
+
+// tombstoneOf returns the live tombstone of key, or nil.
+func (c *wtinyLFUCache) tombstoneOf(key string) *tombstone {
+	v, found := c.tombstones.Load(key)
+	if !found {
+		return nil
+	}
+	t := v.(*tombstone)
+	if c.timeProvider.Now() > t.expireAt {
+		c.tombstones.CompareAndDelete(key, v)
+		return nil
+	}
+	atomic.AddInt64(&c.tombstoneHits, 1)
+	return t
+}
+
+// staleTombstone returns the deleted value of key while its tombstone lives,
+// with TombstoneServeStale.
+func (c *wtinyLFUCache) staleTombstone(key string) (interface{}, bool) {
+	if c.tombstoneMode != TombstoneServeStale {
+		return nil, false
+	}
+	t := c.tombstoneOf(key)
+	if t == nil || t.value == nil {
+		return nil, false
+	}
+	return t.value, true
+}
+
+// delayTombstoned waits a random delay before the load of a key with a live
+// tombstone, with TombstoneDelayLoad. Returns the error of ctx if it is done
+// first.
+func (c *wtinyLFUCache) delayTombstoned(ctx context.Context, key string) error {
+	if c.tombstoneMode != TombstoneDelayLoad {
+		return nil
+	}
+	if c.tombstoneOf(key) == nil {
+		return nil
+	}
+	delay := c.jitterNanos()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(delay))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runTombstoneSweeper removes expired tombstones every TombstoneTTL until
+// Close.
+func (c *wtinyLFUCache) runTombstoneSweeper() {
+	ticker := time.NewTicker(max(time.Duration(c.tombstoneTTLNanos), 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopTombstones:
+			return
+		case <-ticker.C:
+			now := c.timeProvider.Now()
+			c.tombstones.Range(func(key, value interface{}) bool {
+				if now > value.(*tombstone).expireAt {
+					c.tombstones.CompareAndDelete(key, value)
+				}
+				return true
+			})
+		}
+	}
+}
+
+// clearTombstones forgets every tombstone.
+func (c *wtinyLFUCache) clearTombstones() {
+	c.tombstones.Range(func(key, _ interface{}) bool {
+		c.tombstones.Delete(key)
+		return true
+	})
+}
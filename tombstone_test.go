@@ -0,0 +1,110 @@
+// tombstone_test.go: tests for Config.TombstoneTTL
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTombstone_ServeStale(t *testing.T) {
+	// An hour-long TTL keeps the sweeper idle while the mock clock moves
+	mockTime := &MockTimeProvider{currentTime: 1_000_000_000}
+	cache := NewCache(Config{
+		MaxSize:         100,
+		TombstoneTTL:    time.Hour,
+		TombstoneJitter: time.Minute,
+		TimeProvider:    mockTime,
+	})
+	defer cache.Close()
+
+	loads := 0
+	loader := func() (interface{}, error) {
+		loads++
+		return "fresh", nil
+	}
+
+	cache.Set("hot", "old")
+	cache.Delete("hot")
+	if _, found := cache.Get("hot"); found {
+		t.Fatal("Get must miss a deleted key")
+	}
+	v, err := cache.GetOrLoad("hot", loader)
+	if err != nil || v != "old" || loads != 0 {
+		t.Fatalf("GetOrLoad = %v, %v after %d loads, want the deleted value without loading", v, err, loads)
+	}
+	if hits := cache.Stats().TombstoneHits; hits != 1 {
+		t.Fatalf("TombstoneHits = %d, want 1", hits)
+	}
+
+	mockTime.Advance(time.Hour + time.Minute + time.Nanosecond) // Past TTL plus the largest jitter
+	if v, _ := cache.GetOrLoad("hot", loader); v != "fresh" || loads != 1 {
+		t.Fatalf("GetOrLoad = %v after %d loads, want a load once the tombstone expired", v, loads)
+	}
+	if _, found := cache.(*wtinyLFUCache).tombstones.Load("hot"); found {
+		t.Fatal("an expired tombstone must be removed when found")
+	}
+}
+
+func TestTombstone_WriteAndClear(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, TombstoneTTL: time.Hour})
+	defer cache.Close()
+	core := cache.(*wtinyLFUCache)
+
+	cache.Set("k", "old")
+	cache.Delete("k")
+	cache.Set("k", "new")
+	if core.tombstoneOf("k") != nil {
+		t.Fatal("a write must drop the tombstone of its key")
+	}
+
+	cache.Set("k2", "old")
+	cache.Delete("k2")
+	cache.Clear()
+	if core.tombstoneOf("k2") != nil {
+		t.Fatal("Clear must drop the tombstones")
+	}
+
+	cache.Delete("absent")
+	if core.tombstoneOf("absent") != nil {
+		t.Fatal("deleting an absent key must not leave a tombstone")
+	}
+}
+
+func TestTombstone_DelayLoad(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:         100,
+		TombstoneTTL:    time.Hour,
+		TombstoneMode:   TombstoneDelayLoad,
+		TombstoneJitter: time.Hour,
+	})
+	defer cache.Close()
+
+	cache.Set("hot", "old")
+	cache.Delete("hot")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	loaded := false
+	_, err := cache.GetOrLoadWithContext(ctx, "hot", func(context.Context) (interface{}, error) {
+		loaded = true
+		return "fresh", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || loaded {
+		t.Fatalf("expected the load to be delayed past the deadline, got %v (loaded: %v)", err, loaded)
+	}
+
+	// Keys without a tombstone load immediately
+	v, err := cache.GetOrLoadWithContext(context.Background(), "cold", func(context.Context) (interface{}, error) {
+		return "value", nil
+	})
+	if err != nil || v != "value" {
+		t.Fatalf("GetOrLoadWithContext = %v, %v", v, err)
+	}
+}
@@ -0,0 +1,220 @@
+// top_keys.go: the hottest keys of a cache (TopKeys)
+//
+// TopKeys ranks keys by their sketch estimate, or exactly with the
+// space-saving tracker of Config.TopKeysCapacity.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// topKeysShards is the number of shards of the space-saving tracker.
+const topKeysShards = 16
+
+// KeyFreq is a key and its access frequency, as reported by TopKeys.
+type KeyFreq struct {
+	// Key is the key, in its string form.
+	Key string
+
+	// Frequency is the number of lookups counted for the key: exact up to
+	// Error with Config.TopKeysCapacity, the frequency sketch estimate
+	// otherwise.
+	Frequency uint64
+
+	// Error bounds the overestimation of Frequency: the key was looked up
+	// at least Frequency-Error times. Always 0 for sketch estimates.
+	Error uint64
+}
+
+// topCounter is a monitored key of the space-saving tracker.
+type topCounter struct {
+	key   string
+	count uint64
+	err   uint64
+	index int // Position in the heap
+}
+
+// topShard is a space-saving summary over the keys of one shard: a map of
+// the monitored keys and a min-heap of their counters.
+type topShard struct {
+	mu       sync.Mutex
+	counters map[string]*topCounter
+	heap     topHeap
+	capacity int
+}
+
+// topHeap is a min-heap of counters by count.
+type topHeap []*topCounter
+
+func (h topHeap) Len() int           { return len(h) }
+func (h topHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h topHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *topHeap) Push(x interface{}) {
+	counter := x.(*topCounter)
+	counter.index = len(*h)
+	*h = append(*h, counter)
+}
+func (h *topHeap) Pop() interface{} {
+	old := *h
+	counter := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return counter
+}
+
+// topKeysTracker counts the lookups of the hottest keys (space-saving).
+type topKeysTracker struct {
+	shards [topKeysShards]topShard
+}
+
+// newTopKeysTracker returns a tracker monitoring up to capacity keys, or nil
+// if capacity is not positive.
+func newTopKeysTracker(capacity int) *topKeysTracker {
+	if capacity <= 0 {
+		return nil
+	}
+	t := &topKeysTracker{}
+	perShard := (capacity + topKeysShards - 1) / topKeysShards
+	for i := range t.shards {
+		t.shards[i].capacity = perShard
+		t.shards[i].counters = make(map[string]*topCounter, perShard)
+	}
+	return t
+}
+
+// record counts a lookup of key.
+func (t *topKeysTracker) record(key string, keyHash uint64) {
+	s := &t.shards[keyHash%topKeysShards]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if counter, ok := s.counters[key]; ok {
+		counter.count++
+		heap.Fix(&s.heap, counter.index)
+		return
+	}
+	if len(s.heap) < s.capacity {
+		counter := &topCounter{key: strings.Clone(key), count: 1}
+		s.counters[counter.key] = counter
+		heap.Push(&s.heap, counter)
+		return
+	}
+	// Take over the smallest counter: its count bounds the error of key
+	smallest := s.heap[0]
+	delete(s.counters, smallest.key)
+	smallest.key = strings.Clone(key)
+	smallest.err = smallest.count
+	smallest.count++
+	s.counters[smallest.key] = smallest
+	heap.Fix(&s.heap, 0)
+}
+
+// top returns the n keys with the highest counts.
+func (t *topKeysTracker) top(n int) []KeyFreq {
+	var all []KeyFreq
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.Lock()
+		for _, counter := range s.heap {
+			all = append(all, KeyFreq{Key: counter.key, Frequency: counter.count, Error: counter.err})
+		}
+		s.mu.Unlock()
+	}
+	return topOf(all, n)
+}
+
+// reset forgets every monitored key.
+func (t *topKeysTracker) reset() {
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.Lock()
+		clear(s.counters)
+		clear(s.heap)
+		s.heap = s.heap[:0]
+		s.mu.Unlock()
+	}
+}
+
+// topOf sorts keys by decreasing frequency (then by key, for stable reports)
+// and returns the first n.
+func topOf(keys []KeyFreq, n int) []KeyFreq {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Frequency != keys[j].Frequency {
+			return keys[i].Frequency > keys[j].Frequency
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// recordTopKey counts a lookup of key for TopKeys.
+func (c *wtinyLFUCache) recordTopKey(key string) {
+	if c.topKeys != nil && key != "" {
+		c.topKeys.record(key, c.hashKey(key))
+	}
+}
+
+// TopKeys returns the n most accessed keys, hottest first. With
+// Config.TopKeysCapacity the counts come from the space-saving tracker and
+// include keys that are not cached; otherwise the live keys are ranked by
+// their frequency sketch estimate, walking the whole table. Returns nil if
+// n <= 0.
+//
+// Example:
+//
+//	for _, kf := range cache.TopKeys(10) {
+//	    fmt.Printf("%-40s %d (+/-%d)\n", kf.Key, kf.Frequency, kf.Error)
+//	}
+func (c *wtinyLFUCache) TopKeys(n int) []KeyFreq {
+	if n <= 0 || c.isClosed() {
+		return nil
+	}
+	if c.topKeys != nil {
+		return c.topKeys.top(n)
+	}
+
+	t := c.table.Load()
+	if t == nil {
+		return nil // Closed
+	}
+	g := t.gen.Load()
+
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	var live []KeyFreq
+	for i := range t.entries {
+		e := &t.entries[i]
+		version := atomic.LoadUint64(&e.version)
+		if atomic.LoadInt32(&e.valid) != g.live || c.isExpired(e, ttlNow) {
+			continue
+		}
+		key := e.loadKey()
+		keyHash := atomic.LoadUint64(&e.keyHash)
+		if key == "" || atomic.LoadInt32(&e.valid) != g.live || atomic.LoadUint64(&e.version) != version {
+			continue // Rewritten while being read
+		}
+		live = append(live, KeyFreq{Key: key, Frequency: c.estimateFrequency(keyHash)})
+	}
+	return topOf(live, n)
+}
+
+// TopKeys returns the n most accessed keys, hottest first (see
+// Cache.TopKeys). Keys are in their string form.
+func (c *GenericCache[K, V]) TopKeys(n int) []KeyFreq {
+	return c.inner.TopKeys(n)
+}
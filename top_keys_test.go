@@ -0,0 +1,124 @@
+// top_keys_test.go: tests for TopKeys
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestTopKeys_Sketch(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		cache.Set("cold"+strconv.Itoa(i), i)
+	}
+	cache.Set("hot", 1)
+	cache.Set("warm", 1)
+	for i := 0; i < 10; i++ {
+		cache.Get("hot")
+		cache.Get("hot")
+		cache.Get("warm")
+	}
+
+	top := cache.TopKeys(2)
+	if len(top) != 2 || top[0].Key != "hot" || top[1].Key != "warm" {
+		t.Fatalf("TopKeys(2) = %+v, want hot then warm", top)
+	}
+	if top[0].Frequency < top[1].Frequency || top[0].Error != 0 {
+		t.Fatalf("unexpected sketch report %+v", top)
+	}
+	if got := cache.TopKeys(1000); len(got) != 102 {
+		t.Fatalf("TopKeys(1000) returned %d keys, want every cached key", len(got))
+	}
+	if cache.TopKeys(0) != nil {
+		t.Fatal("TopKeys(0) must return nil")
+	}
+}
+
+func TestTopKeys_Tracker(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, TopKeysCapacity: 64}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("cached", 1)
+	for i := 0; i < 500; i++ {
+		cache.Get("cached")
+		if i%2 == 0 {
+			cache.Get("missing") // Hot keys that miss are tracked too
+		}
+		cache.Get("noise" + strconv.Itoa(i)) // One lookup each
+	}
+
+	top := cache.TopKeys(2)
+	if len(top) != 2 || top[0].Key != "cached" || top[1].Key != "missing" {
+		t.Fatalf("TopKeys(2) = %+v, want cached then missing", top)
+	}
+	if top[0].Frequency-top[0].Error > 500 || top[0].Frequency < 500 {
+		t.Fatalf("cached counted %d (error %d), want 500 within the error bound", top[0].Frequency, top[0].Error)
+	}
+
+	cache.Clear()
+	if top := cache.TopKeys(10); len(top) != 0 {
+		t.Fatalf("Clear must reset the tracker, got %+v", top)
+	}
+}
+
+func TestTopKeys_TrackerIntegerKeys(t *testing.T) {
+	cache := NewGenericCache[int, int](Config{MaxSize: 1000, TopKeysCapacity: 64})
+	defer cache.Close()
+
+	cache.Set(42, 1)
+	for i := 0; i < 100; i++ {
+		cache.Get(42)
+		cache.Get(1000 + i)
+	}
+
+	top := cache.TopKeys(1)
+	if len(top) != 1 || top[0].Key != "42" || top[0].Frequency < 100 {
+		t.Fatalf("TopKeys(1) = %+v, want 42 read 100 times", top)
+	}
+}
+
+func TestTopKeysTracker_SpaceSaving(t *testing.T) {
+	tracker := newTopKeysTracker(topKeysShards) // One counter per shard
+	tracker.record("a", 0)
+	tracker.record("a", 0)
+	tracker.record("b", topKeysShards) // Same shard: takes over a's counter
+
+	top := tracker.top(10)
+	if len(top) != 1 || top[0] != (KeyFreq{Key: "b", Frequency: 3, Error: 2}) {
+		t.Fatalf("expected b to inherit a's count as its error, got %+v", top)
+	}
+	if newTopKeysTracker(0) != nil {
+		t.Fatal("a non-positive capacity must disable the tracker")
+	}
+}
+
+func TestTopKeys_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, TopKeysCapacity: 32}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				cache.Get("k" + strconv.Itoa(i%50))
+				if i%100 == 0 {
+					cache.TopKeys(5)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if top := cache.TopKeys(5); len(top) != 5 {
+		t.Fatalf("TopKeys(5) = %+v", top)
+	}
+}
@@ -0,0 +1,56 @@
+// tti.go: expiration after a period of inactivity (Config.TTI)
+//
+// With Config.TTI an entry expires once it has not been read for TTI.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "sync/atomic"
+
+// ttiGranularityDivisor divides TTI into the smallest extension of an idle
+// deadline written by a Get hit.
+const ttiGranularityDivisor = 16
+
+// storeExpireAt sets the deadline of a write to e, a slot of t: writeAt
+// (0 = none), or sooner the end of the TTI idle period.
+func (c *wtinyLFUCache) storeExpireAt(t *cacheTable, e *entry, writeAt int64) {
+	if c.ttiNanos > 0 {
+		atomic.StoreInt64(&t.writeDeadlines[t.slotOf(e)], writeAt)
+		if ttlNow := c.ttlClock(c.timeProvider.Now()); ttlNow > 0 {
+			writeAt = c.idleDeadline(writeAt, ttlNow)
+		}
+	}
+	atomic.StoreInt64(&e.expireAt, writeAt)
+}
+
+// idleDeadline returns the end of an idle period starting at ttlNow, capped
+// at writeAt (0 = no cap).
+func (c *wtinyLFUCache) idleDeadline(writeAt, ttlNow int64) int64 {
+	idle := int64(1<<63 - 1)
+	if ttlNow <= idle-c.ttiNanos {
+		idle = ttlNow + c.ttiNanos
+	}
+	if writeAt > 0 && writeAt < idle {
+		return writeAt
+	}
+	return idle
+}
+
+// touchIdle extends the idle deadline of e, a slot of t, after a Get hit at
+// ttlNow.
+func (c *wtinyLFUCache) touchIdle(t *cacheTable, e *entry, ttlNow int64) {
+	if ttlNow <= 0 {
+		return
+	}
+	current := atomic.LoadInt64(&e.expireAt)
+	next := c.idleDeadline(atomic.LoadInt64(&t.writeDeadlines[t.slotOf(e)]), ttlNow)
+	if next-current < c.ttiGranularity {
+		return
+	}
+	// A failed CAS lost to a write or to a concurrent hit: either way the
+	// deadline was just renewed
+	atomic.CompareAndSwapInt64(&e.expireAt, current, next)
+}
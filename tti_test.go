@@ -0,0 +1,118 @@
+// tti_test.go: tests for Config.TTI
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTI_ExpiresIdleEntries(t *testing.T) {
+	clock := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTI: time.Minute, TimeProvider: clock})
+	defer cache.Close()
+
+	cache.Set("session", "token")
+	cache.Set("idle", "token")
+
+	// Reads keep the session alive well past one idle period
+	for i := 0; i < 10; i++ {
+		clock.Advance(30 * time.Second)
+		if _, ok := cache.Get("session"); !ok {
+			t.Fatalf("session expired after %d reads", i)
+		}
+	}
+	if cache.Has("idle") {
+		t.Fatal("an unread entry must expire after TTI")
+	}
+
+	clock.Advance(61 * time.Second)
+	if _, ok := cache.Get("session"); ok {
+		t.Fatal("the session must expire once idle for TTI")
+	}
+}
+
+func TestTTI_HasDoesNotExtend(t *testing.T) {
+	clock := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTI: time.Minute, TimeProvider: clock})
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	for i := 0; i < 3; i++ {
+		clock.Advance(30 * time.Second)
+		cache.Has("k")
+	}
+	if _, ok := cache.Get("k"); ok {
+		t.Fatal("Has must not count as an access")
+	}
+}
+
+func TestTTI_WriteRenews(t *testing.T) {
+	clock := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTI: time.Minute, TimeProvider: clock})
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	clock.Advance(50 * time.Second)
+	cache.Set("k", 2)
+	clock.Advance(50 * time.Second)
+	if v, ok := cache.Get("k"); !ok || v != 2 {
+		t.Fatalf("a write must restart the idle period, got %v, %v", v, ok)
+	}
+}
+
+func TestTTI_CappedByTTL(t *testing.T) {
+	clock := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTI: time.Minute, TTL: 3 * time.Minute, TimeProvider: clock})
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	for i := 0; i < 5; i++ {
+		clock.Advance(30 * time.Second)
+		if _, ok := cache.Get("k"); !ok {
+			t.Fatalf("expired after %v", time.Duration(i+1)*30*time.Second)
+		}
+	}
+	clock.Advance(31 * time.Second)
+	if _, ok := cache.Get("k"); ok {
+		t.Fatal("reads must not extend an entry past its TTL")
+	}
+
+	// A per-entry TTL caps the idle deadline as well
+	v, err := cache.GetOrLoad("short", func() (interface{}, error) { return 1, nil }, WithTTL(40*time.Second))
+	if err != nil || v != 1 {
+		t.Fatalf("GetOrLoad = %v, %v", v, err)
+	}
+	clock.Advance(30 * time.Second)
+	cache.Get("short")
+	clock.Advance(11 * time.Second)
+	if cache.Has("short") {
+		t.Fatal("reads must not extend an entry past its WithTTL")
+	}
+}
+
+func TestTTI_Granularity(t *testing.T) {
+	clock := &MockTimeProvider{currentTime: time.Now().UnixNano()}
+	cache := NewCache(Config{MaxSize: 100, TTI: 16 * time.Second, TimeProvider: clock})
+	defer cache.Close()
+	c := cache.(*wtinyLFUCache)
+
+	cache.Set("k", 1)
+	e := c.findEntry("k", c.hashKey("k"))
+	written := e.expireAt
+
+	clock.Advance(500 * time.Millisecond) // Below TTI/16
+	cache.Get("k")
+	if e.expireAt != written {
+		t.Fatal("a hit within the granularity must not rewrite the deadline")
+	}
+	clock.Advance(time.Second)
+	cache.Get("k")
+	if e.expireAt != clock.Now()+int64(16*time.Second) {
+		t.Fatal("a hit past the granularity must renew the deadline")
+	}
+}
@@ -0,0 +1,47 @@
+// ttl_jitter.go: random spread of entry TTLs
+//
+// Config.TTLJitter draws every TTL (Config.TTL, WithTTL loads, Namespace
+// TTLs) from [TTL*(1-j), TTL*(1+j)], so entries written together do not
+// expire together.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "math"
+
+// maxJitteredTTL returns the longest TTL jitterTTL draws from ttlNanos.
+func maxJitteredTTL(ttlNanos int64, jitter float64) int64 {
+	if jitter <= 0 || ttlNanos <= 0 {
+		return ttlNanos
+	}
+	delta := float64(ttlNanos) * jitter
+	if delta >= float64(math.MaxInt64-ttlNanos) {
+		return math.MaxInt64
+	}
+	return ttlNanos + int64(delta) + 1 // +1: rounding of the float draw
+}
+
+// jitterTTL returns ttlNanos scaled by a random factor in
+// [1-ttlJitter, 1+ttlJitter]. Non-positive TTLs are returned unchanged.
+func (c *wtinyLFUCache) jitterTTL(ttlNanos int64) int64 {
+	if c.ttlJitter == 0 || ttlNanos <= 0 {
+		return ttlNanos
+	}
+	// Uniform in [-1, 1), from the top 53 bits of the generator
+	unit := float64(c.fastRand()>>11)/(1<<53)*2 - 1
+	delta := int64(float64(ttlNanos) * c.ttlJitter * unit)
+	if delta > 0 && ttlNanos > math.MaxInt64-delta {
+		return math.MaxInt64
+	}
+	jittered := ttlNanos + delta
+	if jittered < 1 {
+		jittered = 1
+	}
+	if jittered > ttlNanos {
+		c.raiseMaxTTL(jittered)
+	}
+	return jittered
+}
@@ -0,0 +1,118 @@
+// ttl_jitter_test.go: tests for Config.TTLJitter
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ttlSpread returns the smallest and largest remaining TTL of the live entries.
+func ttlSpread(c *wtinyLFUCache, now int64) (lo, hi time.Duration) {
+	lo, hi = time.Duration(math.MaxInt64), 0
+	for i := range c.table.Load().entries {
+		entry := &c.table.Load().entries[i]
+		if atomic.LoadInt32(&entry.valid) != entryValid {
+			continue
+		}
+		remaining := time.Duration(atomic.LoadInt64(&entry.expireAt) - now)
+		if remaining < lo {
+			lo = remaining
+		}
+		if remaining > hi {
+			hi = remaining
+		}
+	}
+	return lo, hi
+}
+
+func TestTTLJitter_SpreadsExpirations(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 1000, TTL: 100 * time.Second, TTLJitter: 0.1, TimeProvider: mockTime}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	for i := 0; i < 500; i++ {
+		cache.Set(fmt.Sprintf("key-%d", i), i)
+	}
+	cache.Swap("key-0", 0)
+
+	lo, hi := ttlSpread(cache, mockTime.Now())
+	if lo < 90*time.Second || hi > 110*time.Second {
+		t.Fatalf("TTLs outside +/-10%%: [%v, %v]", lo, hi)
+	}
+	if lo > 95*time.Second || hi < 105*time.Second {
+		t.Fatalf("TTLs not spread: [%v, %v]", lo, hi)
+	}
+
+	// Stretched deadlines are not clamped by the clock regression guard
+	for i := 0; i < 500; i++ {
+		cache.Get(fmt.Sprintf("key-%d", i))
+	}
+	if _, after := ttlSpread(cache, mockTime.Now()); after != hi {
+		t.Errorf("longest TTL clamped from %v to %v", hi, after)
+	}
+
+	mockTime.Advance(95 * time.Second)
+	if n := cache.ExpireNow(); n == 0 || n == 500 {
+		t.Errorf("expected part of the entries to expire, got %d", n)
+	}
+}
+
+func TestTTLJitter_PerEntryTTL(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 1000, TTLJitter: 0.5, TimeProvider: mockTime}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	for i := 0; i < 200; i++ {
+		_, err := cache.GetOrLoad(fmt.Sprintf("key-%d", i), func() (interface{}, error) {
+			return i, nil
+		}, WithTTL(10*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	lo, hi := ttlSpread(cache, mockTime.Now())
+	if lo < 5*time.Second || hi > 15*time.Second || hi-lo < 5*time.Second {
+		t.Fatalf("per-entry TTLs not jittered within +/-50%%: [%v, %v]", lo, hi)
+	}
+}
+
+func TestTTLJitter_NamespaceTTL(t *testing.T) {
+	mockTime := &MockTimeProvider{currentTime: int64(time.Hour)}
+	cache := NewCache(Config{MaxSize: 1000, TTLJitter: 0.5, TimeProvider: mockTime}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	ns := cache.Namespace("sessions", WithNamespaceTTL(10*time.Second))
+	for i := 0; i < 200; i++ {
+		ns.Set(fmt.Sprintf("key-%d", i), i)
+	}
+	lo, hi := ttlSpread(cache, mockTime.Now())
+	if lo < 5*time.Second || hi > 15*time.Second || hi-lo < 5*time.Second {
+		t.Fatalf("namespace TTLs not jittered within +/-50%%: [%v, %v]", lo, hi)
+	}
+}
+
+func TestTTLJitter_Validate(t *testing.T) {
+	for _, jitter := range []float64{-0.1, 1, 2, math.NaN()} {
+		config := Config{TTLJitter: jitter}
+		_ = config.Validate()
+		if config.TTLJitter != 0 {
+			t.Errorf("TTLJitter %v kept as %v", jitter, config.TTLJitter)
+		}
+	}
+
+	// No TTL: nothing to jitter
+	cache := NewCache(Config{MaxSize: 10, TTLJitter: 0.2}).(*wtinyLFUCache)
+	defer cache.Close()
+	cache.Set("k", 1)
+	if _, hi := ttlSpread(cache, 0); hi != 0 {
+		t.Errorf("entry without TTL got deadline %v", hi)
+	}
+}
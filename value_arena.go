@@ -0,0 +1,212 @@
+// value_arena.go: pointer-free storage of encoded values (Config.ValueArena)
+//
+// With Config.ValueArena, encoded values are packed into shared
+// pointer-free slabs instead of one allocation each.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultValueArenaSlabSize is the default size of a value arena slab.
+const DefaultValueArenaSlabSize = 1 << 20
+
+// arenaCompactionHeadroom is the number of slabs the arena may grow beyond
+// twice its live bytes before a compaction is requested.
+const arenaCompactionHeadroom = 4
+
+// arenaSlab is a block of encoded values.
+type arenaSlab struct {
+	buf  []byte
+	used int64  // Bytes claimed (atomic; may exceed len(buf) once full)
+	gen  uint64 // Generation the slab was allocated in
+}
+
+// arenaValue is the stored form of a value encoded into the arena.
+type arenaValue struct {
+	slab *arenaSlab
+	off  uint32
+	n    uint32
+}
+
+// bytes returns the encoded value. It must not be modified.
+func (v arenaValue) bytes() []byte {
+	return v.slab.buf[v.off : v.off+v.n : v.off+v.n]
+}
+
+// valueArena allocates encoded values into slabs.
+type valueArena struct {
+	slabSize int
+	current  atomic.Pointer[arenaSlab]
+
+	mu  sync.Mutex // Serializes slab installation
+	gen uint64     // Current generation (under mu)
+
+	allocated   int64 // Bytes of the slabs allocated since the last compaction began (atomic)
+	live        int64 // Bytes of live values kept by the last compaction (atomic)
+	compactions int64 // Completed compactions (atomic)
+
+	compactRequest chan struct{} // Wakes the compactor (buffered, non-blocking sends)
+}
+
+// newValueArena returns the value arena of config, or nil if disabled.
+func newValueArena(config Config) *valueArena {
+	if !config.ValueArena || config.ValueCodec == nil {
+		return nil
+	}
+	slabSize := config.ValueArenaSlabSize
+	if slabSize <= 0 {
+		slabSize = DefaultValueArenaSlabSize
+	}
+	a := &valueArena{slabSize: slabSize, compactRequest: make(chan struct{}, 1)}
+	a.current.Store(a.newSlab())
+	return a
+}
+
+// newSlab allocates a slab of the current generation. The caller holds mu
+// or owns the arena.
+func (a *valueArena) newSlab() *arenaSlab {
+	atomic.AddInt64(&a.allocated, int64(a.slabSize))
+	return &arenaSlab{buf: make([]byte, a.slabSize), gen: a.gen}
+}
+
+// store copies data into the arena. ok is false for values too large to be
+// packed, which the caller stores as a separate allocation.
+func (a *valueArena) store(data []byte) (arenaValue, bool) {
+	n := int64(len(data))
+	if n > int64(a.slabSize/4) {
+		return arenaValue{}, false
+	}
+	for {
+		slab := a.current.Load()
+		end := atomic.AddInt64(&slab.used, n)
+		if end <= int64(len(slab.buf)) {
+			off := end - n
+			copy(slab.buf[off:end], data)
+			return arenaValue{slab: slab, off: uint32(off), n: uint32(n)}, true // #nosec G115 -- bounded by slabSize
+		}
+		a.roll(slab)
+	}
+}
+
+// roll replaces the full slab with a new one, unless another writer already
+// did, and requests a compaction once the arena has grown enough.
+func (a *valueArena) roll(full *arenaSlab) {
+	a.mu.Lock()
+	if a.current.Load() == full {
+		a.current.Store(a.newSlab())
+	}
+	a.mu.Unlock()
+
+	limit := 2*atomic.LoadInt64(&a.live) + arenaCompactionHeadroom*int64(a.slabSize)
+	if atomic.LoadInt64(&a.allocated) > limit {
+		select {
+		case a.compactRequest <- struct{}{}:
+		default: // Already requested
+		}
+	}
+}
+
+// newGeneration starts a compaction: later values go to a fresh slab of a
+// new generation. Returns that generation.
+func (a *valueArena) newGeneration() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.gen++
+	atomic.StoreInt64(&a.allocated, 0)
+	a.current.Store(a.newSlab())
+	return a.gen
+}
+
+// runArenaCompactor compacts the value arena on request until Close.
+func (c *wtinyLFUCache) runArenaCompactor() {
+	for {
+		select {
+		case <-c.stopArena:
+			return
+		case <-c.arena.compactRequest:
+			c.compactArena()
+		}
+	}
+}
+
+// compactArena copies the live arena values of older generations into a
+// new one, so the slabs holding only garbage become unreachable. Dead slots
+// still referencing old values are released (see compact.go). Returns the
+// number of values moved.
+func (c *wtinyLFUCache) compactArena() int {
+	c.arenaMu.Lock()
+	defer c.arenaMu.Unlock()
+	t := c.table.Load()
+	if t == nil {
+		return 0 // Closed
+	}
+	g := t.gen.Load()
+
+	gen := c.arena.newGeneration()
+	moved := 0
+	var live int64
+	for i := range t.entries {
+		if c.isClosed() {
+			return moved
+		}
+		entry := &t.entries[i]
+		state := atomic.LoadInt32(&entry.valid)
+		holder, _ := entry.value.Load().(*valueHolder)
+		if holder == nil {
+			continue
+		}
+		value, isArena := holder.load().(arenaValue)
+		if !isArena {
+			continue
+		}
+		switch {
+		case t.isFree(state):
+			if value.slab.gen < gen {
+				c.releaseDeadSlot(entry, state)
+			}
+		case state != g.live:
+			continue
+		case value.slab.gen >= gen:
+			live += int64(value.n)
+		case c.relocateArenaValue(entry, state, holder):
+			live += int64(value.n)
+			moved++
+		}
+	}
+	atomic.StoreInt64(&c.arena.live, live)
+	atomic.AddInt64(&c.arena.compactions, 1)
+	return moved
+}
+
+// relocateArenaValue copies the arena value of an entry in state live into
+// the current generation. Returns false if the entry changed before it could
+// be taken.
+func (c *wtinyLFUCache) relocateArenaValue(entry *entry, live int32, holder *valueHolder) bool {
+	if !atomic.CompareAndSwapInt32(&entry.valid, live, pendingOf(live)) {
+		return false
+	}
+	defer atomic.StoreInt32(&entry.valid, live)
+
+	// Re-check under exclusive ownership: the value may have been replaced
+	if current, _ := entry.value.Load().(*valueHolder); current != holder {
+		return false
+	}
+	value := holder.load().(arenaValue)
+	moved, _ := c.arena.store(value.bytes()) // Fits: it was packed before
+	entry.value.Store(newValueHolder(moved, holder.source(), holder.version(), holder.ttl()))
+	return true
+}
+
+// arenaDebugStats fills the value arena fields of stats.
+func (c *wtinyLFUCache) arenaDebugStats(stats *DebugStats) {
+	stats.ValueArenaBytes = atomic.LoadInt64(&c.arena.allocated) + atomic.LoadInt64(&c.arena.live)
+	stats.ValueArenaLiveBytes = atomic.LoadInt64(&c.arena.live)
+	stats.ValueArenaCompactions = uint64(atomic.LoadInt64(&c.arena.compactions)) // #nosec G115 - counter is always positive
+}
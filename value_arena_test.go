@@ -0,0 +1,138 @@
+// value_arena_test.go: tests for Config.ValueArena
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// storedValue returns the stored form of a live key.
+func storedValue(c *wtinyLFUCache, key string) interface{} {
+	for i := range c.table.Load().entries {
+		e := &c.table.Load().entries[i]
+		if e.valid == entryValid && e.loadKey() == key {
+			return holderValue(e)
+		}
+	}
+	return nil
+}
+
+func TestValueArena_PacksValues(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:            100,
+		ValueCodec:         newFlateCodec(1 << 30),
+		ValueArena:         true,
+		ValueArenaSlabSize: 4096,
+	}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("small", "value")
+	cache.Set("large", strings.Repeat("x", 2048))
+
+	if _, ok := storedValue(cache, "small").(arenaValue); !ok {
+		t.Fatalf("small value stored as %T, want arenaValue", storedValue(cache, "small"))
+	}
+	if _, ok := storedValue(cache, "large").(encodedValue); !ok {
+		t.Fatalf("value above a quarter slab stored as %T, want encodedValue", storedValue(cache, "large"))
+	}
+	if v, ok := cache.Get("small"); !ok || v != "value" {
+		t.Fatalf("Get(small) = %v, %v", v, ok)
+	}
+	if v, ok := cache.Get("large"); !ok || v != strings.Repeat("x", 2048) {
+		t.Fatal("large value not returned intact")
+	}
+}
+
+func TestValueArena_IgnoredWithoutCodec(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, ValueArena: true}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	if cache.arena != nil {
+		t.Fatal("ValueArena must be ignored without a ValueCodec")
+	}
+	cache.Set("k", 1)
+	if v, ok := cache.Get("k"); !ok || v != 1 {
+		t.Fatalf("Get = %v, %v", v, ok)
+	}
+}
+
+func TestValueArena_CompactReclaimsGarbage(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:            100,
+		ValueCodec:         newFlateCodec(1 << 30),
+		ValueArena:         true,
+		ValueArenaSlabSize: 1024,
+	}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	for round := 0; round < 50; round++ {
+		for i := 0; i < 20; i++ {
+			cache.Set("k"+strconv.Itoa(i), "v"+strconv.Itoa(round))
+		}
+	}
+	cache.Delete("k0")
+
+	report := cache.Compact()
+	if report.RelocatedValues == 0 {
+		t.Fatalf("Compact relocated nothing: %+v", report)
+	}
+	for i := 1; i < 20; i++ {
+		if v, ok := cache.Get("k" + strconv.Itoa(i)); !ok || v != "v49" {
+			t.Fatalf("k%d = %v, %v after compaction", i, v, ok)
+		}
+	}
+	if _, ok := cache.Get("k0"); ok {
+		t.Fatal("deleted key resurrected by compaction")
+	}
+
+	debug := cache.DebugStats()
+	if debug.ValueArenaCompactions == 0 || debug.ValueArenaLiveBytes == 0 {
+		t.Fatalf("unexpected arena stats %+v", debug)
+	}
+	if debug.ValueArenaBytes > 4*1024+debug.ValueArenaLiveBytes {
+		t.Fatalf("arena still holds %d bytes for %d live", debug.ValueArenaBytes, debug.ValueArenaLiveBytes)
+	}
+}
+
+func TestValueArena_BackgroundCompaction(t *testing.T) {
+	cache := NewCache(Config{
+		MaxSize:            200,
+		ValueCodec:         newFlateCodec(1 << 30),
+		ValueArena:         true,
+		ValueArenaSlabSize: 512,
+	}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				key := "k" + strconv.Itoa(i%50)
+				cache.Set(key, key)
+				if v, ok := cache.Get(key); ok && v != key {
+					t.Errorf("Get(%s) = %v", key, v)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for cache.DebugStats().ValueArenaCompactions == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if cache.DebugStats().ValueArenaCompactions == 0 {
+		t.Fatal("the arena never compacted in the background")
+	}
+}
@@ -0,0 +1,171 @@
+// value_codec.go: encoding (compression) of cached values
+//
+// With Config.ValueCodec every value is encoded on write and decoded on
+// read, transparently for callers.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "errors"
+
+// ValueCodec encodes cached values (see Config.ValueCodec). Implementations
+// must be safe for concurrent use.
+type ValueCodec interface {
+	// Encode returns the stored form of value.
+	Encode(value interface{}) ([]byte, error)
+
+	// Decode returns the value encoded in data. data must not be retained
+	// or modified.
+	Decode(data []byte) (interface{}, error)
+}
+
+// encodedValue is the stored form of a value encoded by Config.ValueCodec.
+type encodedValue []byte
+
+// encodeValue returns the form of value stored in the table. ok is false if
+// the codec failed.
+func (c *wtinyLFUCache) encodeValue(key string, value interface{}) (stored interface{}, ok bool) {
+	if c.valueCodec == nil {
+		return value, true
+	}
+	data, err := c.valueCodec.Encode(value)
+	if err != nil {
+		c.logger.Warn("balios: value encoding failed", "key", key, "error", err)
+		return nil, false
+	}
+	if c.arena != nil {
+		if packed, ok := c.arena.store(data); ok {
+			return packed, true
+		}
+	}
+	return encodedValue(data), true
+}
+
+// decoded returns the value stored as stored. Values not encoded by the
+// codec are returned as is, so decoding is idempotent. ok is false if the
+// codec failed.
+func (c *wtinyLFUCache) decoded(stored interface{}) (value interface{}, ok bool) {
+	var data []byte
+	switch v := stored.(type) {
+	case encodedValue:
+		data = v
+	case arenaValue:
+		data = v.bytes() // See value_arena.go
+	default:
+		return stored, true
+	}
+	value, err := c.valueCodec.Decode(data)
+	if err != nil {
+		c.logger.Error("balios: value decoding failed", "error", err)
+		return nil, false
+	}
+	return value, true
+}
+
+// decodedOrNil is decoded for paths reporting values rather than serving
+// them: a value that fails to decode is reported as nil.
+func (c *wtinyLFUCache) decodedOrNil(stored interface{}) interface{} {
+	value, _ := c.decoded(stored)
+	return value
+}
+
+// Framing of compressionCodec: a kind byte, a compression byte, the payload.
+const (
+	codecKindBytes  byte = 0 // []byte value
+	codecKindString byte = 1 // string value
+	codecKindGob    byte = 2 // Other value, gob-encoded
+
+	codecRaw        byte = 0 // Payload stored uncompressed
+	codecCompressed byte = 1 // Payload compressed
+
+	codecHeaderSize = 2
+
+	// DefaultCompressionMinSize is the default size below which the
+	// compression codecs store values uncompressed.
+	DefaultCompressionMinSize = 256
+)
+
+// errCorruptValue is returned when decoding data not produced by a
+// compressionCodec.
+var errCorruptValue = errors.New("balios: corrupt encoded value")
+
+// NewCompressionCodec returns a ValueCodec compressing values of at least
+// minSize bytes (DefaultCompressionMinSize if minSize <= 0) with a block
+// compressor. compress and decompress must be safe for concurrent use.
+// The github.com/agilira/balios/compress module provides Snappy and Zstd
+// codecs built with it.
+func NewCompressionCodec(compress func(src []byte) []byte, decompress func(src []byte) ([]byte, error), minSize int) ValueCodec {
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+	return compressionCodec{compress: compress, decompress: decompress, minSize: minSize}
+}
+
+// compressionCodec is a ValueCodec compressing values with a block
+// compressor (see NewCompressionCodec).
+type compressionCodec struct {
+	compress   func(src []byte) []byte
+	decompress func(src []byte) ([]byte, error)
+	minSize    int
+}
+
+// Encode frames and, above the minimum size, compresses value.
+func (c compressionCodec) Encode(value interface{}) ([]byte, error) {
+	var kind byte
+	var payload []byte
+	switch v := value.(type) {
+	case []byte:
+		kind, payload = codecKindBytes, v
+	case string:
+		kind, payload = codecKindString, []byte(v)
+	default:
+		data, err := GobCodec{}.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		kind, payload = codecKindGob, data
+	}
+
+	if len(payload) < c.minSize {
+		out := make([]byte, codecHeaderSize, codecHeaderSize+len(payload))
+		out[0], out[1] = kind, codecRaw
+		return append(out, payload...), nil
+	}
+	compressed := c.compress(payload)
+	out := make([]byte, codecHeaderSize, codecHeaderSize+len(compressed))
+	out[0], out[1] = kind, codecCompressed
+	return append(out, compressed...), nil
+}
+
+// Decode reverses Encode.
+func (c compressionCodec) Decode(data []byte) (interface{}, error) {
+	if len(data) < codecHeaderSize {
+		return nil, errCorruptValue
+	}
+	payload := data[codecHeaderSize:]
+	switch data[1] {
+	case codecRaw:
+		payload = append([]byte(nil), payload...) // Callers may modify []byte values
+	case codecCompressed:
+		var err error
+		if payload, err = c.decompress(payload); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errCorruptValue
+	}
+
+	switch data[0] {
+	case codecKindBytes:
+		return payload, nil
+	case codecKindString:
+		return string(payload), nil
+	case codecKindGob:
+		return GobCodec{}.Unmarshal(payload)
+	default:
+		return nil, errCorruptValue
+	}
+}
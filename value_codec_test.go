@@ -0,0 +1,212 @@
+// value_codec_test.go: tests for the encoding (compression) of cached values
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/gob"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newFlateCodec returns a compressionCodec using compress/flate, standing in
+// for the build-tagged codecs.
+func newFlateCodec(minSize int) compressionCodec {
+	return compressionCodec{
+		compress: func(src []byte) []byte {
+			var buf bytes.Buffer
+			w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+			_, _ = w.Write(src)
+			_ = w.Close()
+			return buf.Bytes()
+		},
+		decompress: func(src []byte) ([]byte, error) {
+			return io.ReadAll(flate.NewReader(bytes.NewReader(src)))
+		},
+		minSize: minSize,
+	}
+}
+
+// jsonBlob returns a compressible document of about n bytes.
+func jsonBlob(n int) string {
+	return `{"items":[` + strings.Repeat(`{"name":"balios","kind":"cache"},`, n/32) + `{}]}`
+}
+
+func TestCompressionCodec_RoundTrip(t *testing.T) {
+	codec := newFlateCodec(64)
+
+	type user struct{ Name string }
+	values := []interface{}{
+		[]byte(jsonBlob(4096)), // Compressed
+		jsonBlob(4096),         // Compressed string
+		[]byte("tiny"),         // Below minSize
+		"tiny",
+		42,
+		user{Name: "alice"}, // Gob-encoded
+	}
+	gob.Register(user{})
+
+	for _, value := range values {
+		data, err := codec.Encode(value)
+		if err != nil {
+			t.Fatalf("Encode(%T): %v", value, err)
+		}
+		got, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("Decode(%T): %v", value, err)
+		}
+		switch v := value.(type) {
+		case []byte:
+			if !bytes.Equal(got.([]byte), v) {
+				t.Errorf("[]byte round trip mismatch")
+			}
+		default:
+			if got != value {
+				t.Errorf("round trip of %T: got %v", value, got)
+			}
+		}
+	}
+
+	if data, _ := codec.Encode(jsonBlob(4096)); len(data) > 1024 {
+		t.Errorf("a repetitive 4 KiB document should compress, got %d bytes", len(data))
+	}
+	for _, corrupt := range [][]byte{nil, {9, 0}, {0, 9}, {9, 0, 'x'}} {
+		if _, err := codec.Decode(corrupt); err == nil {
+			t.Errorf("expected an error decoding %v", corrupt)
+		}
+	}
+}
+
+func TestNewCompressionCodec(t *testing.T) {
+	flate := newFlateCodec(0)
+	codec := NewCompressionCodec(flate.compress, flate.decompress, 0)
+
+	small, err := codec.Encode(strings.Repeat("a", DefaultCompressionMinSize-1))
+	if err != nil || small[1] != codecRaw {
+		t.Fatalf("values below DefaultCompressionMinSize must be stored raw (err %v)", err)
+	}
+	doc := jsonBlob(4096)
+	data, err := codec.Encode(doc)
+	if err != nil || data[1] != codecCompressed {
+		t.Fatalf("large values must be compressed (err %v)", err)
+	}
+	if got, err := codec.Decode(data); err != nil || got != doc {
+		t.Fatalf("round trip failed: %v", err)
+	}
+}
+
+func TestValueCodec_TransparentForCallers(t *testing.T) {
+	var mu sync.Mutex
+	var evicted []interface{}
+	cache := NewCache(Config{
+		MaxSize:    100,
+		ValueCodec: newFlateCodec(64),
+		OnEvict: func(_ string, value interface{}, _ EvictReason) {
+			mu.Lock()
+			evicted = append(evicted, value)
+			mu.Unlock()
+		},
+	}).(*wtinyLFUCache)
+	doc := jsonBlob(2048)
+
+	cache.Set("doc", doc)
+	if v, ok := cache.Get("doc"); !ok || v != doc {
+		t.Fatalf("Get should return the decoded value, got %T, %v", v, ok)
+	}
+	if !cache.CompareAndSwap("doc", doc, "v2") {
+		t.Error("CompareAndSwap should compare decoded values")
+	}
+	if previous, loaded := cache.Swap("doc", "v3"); !loaded || previous != "v2" {
+		t.Errorf("Swap should return the decoded previous value, got %v, %v", previous, loaded)
+	}
+	cache.Range(func(key string, value interface{}) bool {
+		if value != "v3" {
+			t.Errorf("Range should see decoded values, got %v", value)
+		}
+		return true
+	})
+	value, err := cache.GetOrLoad("loaded", func() (interface{}, error) { return doc, nil })
+	if err != nil || value != doc {
+		t.Errorf("GetOrLoad = %v, %v", value, err)
+	}
+	if v, _ := cache.Get("loaded"); v != doc {
+		t.Error("loaded values should round trip")
+	}
+
+	cache.Delete("doc")
+	mu.Lock()
+	defer mu.Unlock()
+	if len(evicted) != 3 || evicted[0] != doc || evicted[1] != "v2" || evicted[2] != "v3" {
+		t.Errorf("OnEvict should receive decoded values, got %v", evicted)
+	}
+}
+
+func TestValueCodec_WeighsEncodedSize(t *testing.T) {
+	doc := []byte(jsonBlob(64 << 10))
+
+	plain := NewBytesCache(Config{MaxSize: 100, MaxBytes: 1 << 20})
+	compressed := NewBytesCache(Config{MaxSize: 100, MaxBytes: 1 << 20, ValueCodec: newFlateCodec(64)})
+	plain.Set("doc", doc)
+	compressed.Set("doc", doc)
+
+	if plain.Bytes() != int64(len(doc)) {
+		t.Errorf("plain Bytes = %d, want %d", plain.Bytes(), len(doc))
+	}
+	if got := compressed.Bytes(); got == 0 || got > int64(len(doc))/10 {
+		t.Errorf("compressed values should be charged their encoded size, got %d for %d bytes", got, len(doc))
+	}
+	if v, ok := compressed.Get("doc"); !ok || !bytes.Equal(v, doc) {
+		t.Error("BytesCache should return the decoded bytes")
+	}
+
+	// Without a Weigher, an encoded entry weighs its encoded length
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 1 << 20, ValueCodec: newFlateCodec(64)})
+	cache.Set("doc", doc)
+	if w := cache.Stats().Weight; w != compressed.Bytes() {
+		t.Errorf("expected the encoded length as weight, got %d", w)
+	}
+}
+
+// failingCodec fails to encode values marked "bad" and to decode anything
+// once broken is set.
+type failingCodec struct{ broken bool }
+
+func (c *failingCodec) Encode(value interface{}) ([]byte, error) {
+	if value == "bad" {
+		return nil, errors.New("unsupported value")
+	}
+	return []byte(value.(string)), nil
+}
+
+func (c *failingCodec) Decode(data []byte) (interface{}, error) {
+	if c.broken {
+		return nil, errors.New("corrupt")
+	}
+	return string(data), nil
+}
+
+func TestValueCodec_Errors(t *testing.T) {
+	codec := &failingCodec{}
+	cache := NewCache(Config{MaxSize: 100, ValueCodec: codec}).(*wtinyLFUCache)
+
+	if cache.Set("k", "bad") {
+		t.Error("a value that fails to encode must not be stored")
+	}
+	if cache.CompareAndSwap("k", nil, "bad") {
+		t.Error("CompareAndSwap must fail when encoding fails")
+	}
+
+	cache.Set("k", "good")
+	codec.broken = true
+	if _, ok := cache.Get("k"); ok {
+		t.Error("a value that fails to decode should be a miss")
+	}
+}
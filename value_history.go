@@ -0,0 +1,168 @@
+// value_history.go: bounded per-key history of replaced values
+//
+// With Config.ValueHistory = N, the cache remembers the last N values of
+// each key; GetPrevious returns the most recent one.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// historyShards is the number of lock stripes of the value history.
+const historyShards = 64
+
+// historyShard holds the versions of the keys hashed to it.
+type historyShard struct {
+	mu       sync.Mutex
+	versions map[string][]interface{} // key -> previous values, newest first
+}
+
+// valueHistory keeps the last depth replaced values of each key.
+type valueHistory struct {
+	shards     [historyShards]historyShard
+	depth      int
+	count      int64 // Keys with a history, including removed ones
+	pruneAbove int64
+	pruning    int32
+}
+
+// newValueHistory returns nil when the history is disabled.
+func newValueHistory(config Config) *valueHistory {
+	if config.ValueHistory <= 0 {
+		return nil
+	}
+	h := &valueHistory{depth: config.ValueHistory, pruneAbove: int64(2 * config.MaxSize)}
+	for i := range h.shards {
+		h.shards[i].versions = make(map[string][]interface{})
+	}
+	return h
+}
+
+func (h *valueHistory) shard(key string) *historyShard {
+	return &h.shards[stringHash(key)%historyShards]
+}
+
+// record pushes previous as the newest version of key. It returns true when
+// the history has grown enough to need pruning.
+func (h *valueHistory) record(key string, previous interface{}) bool {
+	s := h.shard(key)
+	s.mu.Lock()
+	versions, exists := s.versions[key]
+	if len(versions) < h.depth {
+		versions = append(versions, nil)
+	}
+	copy(versions[1:], versions)
+	versions[0] = previous
+	s.versions[key] = versions
+	s.mu.Unlock()
+
+	return !exists && atomic.AddInt64(&h.count, 1) > h.pruneAbove
+}
+
+// get returns a copy of the versions of key, newest first.
+func (h *valueHistory) get(key string) []interface{} {
+	s := h.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := s.versions[key]
+	if len(versions) == 0 {
+		return nil
+	}
+	return append([]interface{}(nil), versions...)
+}
+
+// prune drops the history of keys for which present returns false. Only one
+// goroutine prunes at a time; others proceed without waiting.
+func (h *valueHistory) prune(present func(string) bool) {
+	if !atomic.CompareAndSwapInt32(&h.pruning, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&h.pruning, 0)
+
+	for i := range h.shards {
+		s := &h.shards[i]
+		s.mu.Lock()
+		for key := range s.versions {
+			if !present(key) {
+				delete(s.versions, key)
+				atomic.AddInt64(&h.count, -1)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (h *valueHistory) reset() {
+	for i := range h.shards {
+		s := &h.shards[i]
+		s.mu.Lock()
+		s.versions = make(map[string][]interface{})
+		s.mu.Unlock()
+	}
+	atomic.StoreInt64(&h.count, 0)
+}
+
+// takePrevious returns the current value of an entry owned by a writer that
+// is about to replace it, recording it in the value history when enabled.
+func (c *wtinyLFUCache) takePrevious(entry *entry, key string) interface{} {
+	previous := c.decodedOrNil(holderValue(entry))
+	if c.history != nil && c.history.record(key, previous) {
+		// key itself is pending (owned by the caller), so findEntry misses it
+		c.history.prune(func(k string) bool { return k == key || c.findEntry(k, c.hashKey(k)) != nil })
+	}
+	return previous
+}
+
+// GetPrevious returns the value key held before its last replacement.
+// Requires Config.ValueHistory; found is false if the key was never
+// overwritten (or its history was dropped after the key left the cache).
+func (c *wtinyLFUCache) GetPrevious(key string) (value interface{}, found bool) {
+	versions := c.History(key)
+	if len(versions) == 0 {
+		return nil, false
+	}
+	return versions[0], true
+}
+
+// History returns up to Config.ValueHistory values key held before its
+// current one, newest first (nil without history).
+func (c *wtinyLFUCache) History(key string) []interface{} {
+	if c.history == nil || key == "" {
+		return nil
+	}
+	return c.history.get(key)
+}
+
+// GetPrevious returns the value key held before its last replacement.
+// found is false if there is none or it is not a V.
+func (c *GenericCache[K, V]) GetPrevious(key K) (value V, found bool) {
+	prev, found := c.inner.GetPrevious(keyToString(key))
+	if !found {
+		var zero V
+		return zero, false
+	}
+	value, found = prev.(V)
+	return value, found
+}
+
+// History returns the values key held before its current one, newest first.
+// Values that are not a V are skipped.
+func (c *GenericCache[K, V]) History(key K) []V {
+	versions := c.inner.History(keyToString(key))
+	if versions == nil {
+		return nil
+	}
+	result := make([]V, 0, len(versions))
+	for _, v := range versions {
+		if typed, ok := v.(V); ok {
+			result = append(result, typed)
+		}
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+// value_history_test.go: tests for GetPrevious and History
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestValueHistory_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+	cache.Set("k", 2)
+	if _, ok := cache.GetPrevious("k"); ok {
+		t.Error("history must be empty when disabled")
+	}
+	if h := cache.History("k"); h != nil {
+		t.Errorf("expected nil history, got %v", h)
+	}
+}
+
+func TestValueHistory_GetPrevious(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, ValueHistory: 3}).(*wtinyLFUCache)
+
+	cache.Set("cfg", "v1")
+	if _, ok := cache.GetPrevious("cfg"); ok {
+		t.Error("a key written once has no previous value")
+	}
+
+	cache.Set("cfg", "v2")
+	cache.Swap("cfg", "v3")
+	cache.CompareAndSwap("cfg", "v3", "v4")
+	cache.CompareAndSwap("cfg", "nope", "v5") // Not applied: not a version
+	cache.Set("cfg", "v6")
+
+	if prev, ok := cache.GetPrevious("cfg"); !ok || prev != "v4" {
+		t.Fatalf("expected v4, got %v, %v", prev, ok)
+	}
+	got := fmt.Sprint(cache.History("cfg"))
+	if got != "[v4 v3 v2]" {
+		t.Errorf("expected the last 3 versions newest first, got %s", got)
+	}
+}
+
+func TestValueHistory_Rollback(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, ValueHistory: 1}).(*wtinyLFUCache)
+	cache.Set("flags", "good")
+	cache.Set("flags", "bad")
+
+	prev, ok := cache.GetPrevious("flags")
+	if !ok {
+		t.Fatal("expected a previous value")
+	}
+	cache.Set("flags", prev)
+	if v, _ := cache.Get("flags"); v != "good" {
+		t.Errorf("expected rollback to good, got %v", v)
+	}
+	if prev, _ := cache.GetPrevious("flags"); prev != "bad" {
+		t.Errorf("the rollback itself is a replacement, got previous %v", prev)
+	}
+}
+
+func TestValueHistory_DeleteAndClear(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, ValueHistory: 2}).(*wtinyLFUCache)
+	cache.Set("k", 1)
+	cache.Set("k", 2)
+	cache.Delete("k")
+	if prev, ok := cache.GetPrevious("k"); !ok || prev != 1 {
+		t.Errorf("history must outlive the deletion until pruned, got %v, %v", prev, ok)
+	}
+
+	cache.Clear()
+	if _, ok := cache.GetPrevious("k"); ok {
+		t.Error("Clear must drop the history")
+	}
+}
+
+func TestValueHistory_Pruned(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 10, ValueHistory: 1}).(*wtinyLFUCache)
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("k%d", i)
+		cache.Set(key, 1)
+		cache.Set(key, 2)
+	}
+
+	if n := atomic.LoadInt64(&cache.history.count); n > 2*10+1 {
+		t.Errorf("history must be pruned to the live keys, holds %d keys", n)
+	}
+	// The key being updated survives the prune it triggers. Its first value
+	// may be evicted by its own insertion: retry with another key if so
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("last%d", i)
+		if cache.Set(key, 1); !cache.Has(key) {
+			continue
+		}
+		cache.Set(key, 2)
+		if prev, ok := cache.GetPrevious(key); !ok || prev != 1 {
+			t.Errorf("live key lost its history: %v, %v", prev, ok)
+		}
+		return
+	}
+	t.Fatal("no key survived its insertion")
+}
+
+func TestValueHistory_ConcurrentOrder(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, ValueHistory: 1000}).(*wtinyLFUCache)
+	cache.Set("k", -1)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				cache.Set("k", g*100+i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	history := cache.History("k")
+	if len(history) != 400 {
+		t.Fatalf("expected 400 versions, got %d", len(history))
+	}
+	// Per writer, versions must appear in write order (newest first)
+	last := map[int]int{0: 1 << 30, 1: 1 << 30, 2: 1 << 30, 3: 1 << 30}
+	for _, v := range history {
+		n := v.(int)
+		if n < 0 {
+			continue
+		}
+		if g := n / 100; n >= last[g] {
+			t.Fatalf("writer %d versions out of order: %d after %d", g, n, last[g])
+		} else {
+			last[g] = n
+		}
+	}
+}
+
+func TestValueHistory_Generic(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, ValueHistory: 2})
+	cache.Set("n", 1)
+	cache.Set("n", 2)
+	cache.Set("n", 3)
+	if prev, ok := cache.GetPrevious("n"); !ok || prev != 2 {
+		t.Errorf("expected 2, got %d, %v", prev, ok)
+	}
+	if h := cache.History("n"); len(h) != 2 || h[0] != 2 || h[1] != 1 {
+		t.Errorf("expected [2 1], got %v", h)
+	}
+}
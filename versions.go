@@ -0,0 +1,167 @@
+// versions.go: entry versions for optimistic concurrency (Config.EntryVersions)
+//
+// With Config.EntryVersions every write stamps the entry with a version,
+// and SetIfVersion stores only if the version is unchanged.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import "context"
+
+// nextVersion returns the version of a new write, or 0 without
+// Config.EntryVersions.
+func (c *wtinyLFUCache) nextVersion() uint64 {
+	if !c.versioned {
+		return 0
+	}
+	return c.writeVersion.Add(1)
+}
+
+// holderVersion returns the version of the value held by entry.
+func holderVersion(entry *entry) uint64 {
+	if holder, ok := entry.value.Load().(*valueHolder); ok && holder != nil {
+		return holder.version()
+	}
+	return 0
+}
+
+// VersionOf returns the version of the live entry of key, without side
+// effects on statistics or the frequency sketch. found is false if the key
+// is absent or expired. Versions are 0 without Config.EntryVersions.
+func (c *wtinyLFUCache) VersionOf(key string) (version uint64, found bool) {
+	if key == "" {
+		return 0, false
+	}
+	ttlNow := c.ttlClock(c.timeProvider.Now())
+	entry := c.findEntry(key, c.hashKey(key))
+	if entry == nil || c.isExpired(entry, ttlNow) {
+		return 0, false
+	}
+	holder, ok := entry.value.Load().(*valueHolder)
+	if !ok || holder == nil || !c.isLive(entry) {
+		return 0, false
+	}
+	return holder.version(), true
+}
+
+// GetVersion returns the value of key together with its version, to be
+// passed to SetIfVersion. It counts as a Get in the statistics.
+//
+// Example:
+//
+//	cfg, version, found := cache.GetVersion("config:svc")
+//	if !found {
+//	    version = 0 // SetIfVersion then only inserts
+//	}
+//	if _, ok := cache.SetIfVersion("config:svc", merge(cfg, patch), version); !ok {
+//	    // Another writer got there first: read again and retry
+//	}
+func (c *wtinyLFUCache) GetVersion(key string) (value interface{}, version uint64, found bool) {
+	if key == "" || c.isClosed() {
+		return nil, 0, false
+	}
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	keyHash := c.hashKey(key)
+	c.incrementFrequency(keyHash)
+
+	if entry := c.findEntry(key, keyHash); entry != nil && !c.isExpired(entry, c.ttlClock(now)) {
+		holder, ok := entry.value.Load().(*valueHolder)
+		if ok && holder != nil && c.isLive(entry) {
+			value, version, found = c.decodedOrNil(holder.load()), holder.version(), true
+		}
+	}
+	if !found {
+		c.classifyMiss(keyHash)
+	}
+	c.recordGet(context.Background(), key, start, found)
+	return value, version, found
+}
+
+// SetIfVersion stores value for key only if the live entry of key carries
+// version, as returned by GetVersion or a previous SetIfVersion; version 0
+// stores value only if key is absent or expired. Returns the version of the
+// stored value and true, or false if the version did not match, the value
+// cannot be stored (see SetE) or Config.EntryVersions is not set.
+func (c *wtinyLFUCache) SetIfVersion(key string, value interface{}, version uint64) (newVersion uint64, ok bool) {
+	if !c.versioned {
+		return 0, false
+	}
+	stored, err := c.prepareSet("SetIfVersion", key, value)
+	if err != nil {
+		return 0, false
+	}
+	var weight int32
+	if c.maxWeight > 0 {
+		weight, _ = c.weigh(key, stored)
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now)
+	keyHash := c.hashKey(key)
+	c.incrementFrequency(keyHash)
+
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return 0, false // Closed
+	}
+	defer t.leave(keyHash)
+	entry, idx, existing, oldState := c.claimKey(t, key, keyHash, ttlNow)
+	if entry == nil {
+		return 0, false
+	}
+	if !existing {
+		if version != 0 {
+			c.releaseClaim(entry, false)
+			return 0, false
+		}
+		return c.insertClaimed(t, entry, idx, oldState, key, keyHash, value, stored, weight, start, ttlNow), true
+	}
+	if holderVersion(entry) != version {
+		c.releaseClaim(entry, true)
+		return 0, false
+	}
+
+	_, newVersion = c.replaceValue(t, entry, key, stored, weight, c.entryExpireAt(ttlNow))
+	if c.maxWeight > 0 {
+		c.enforceMaxWeight(t)
+	}
+	if c.memory != nil {
+		c.recordMemory(key, stored)
+	}
+	c.recordSetMetrics(key, start)
+	if c.propagate {
+		c.propagateWrite(key, value, c.ttlNanos)
+	}
+	return newVersion, true
+}
+
+// VersionOf returns the version of the live entry of key (see
+// Cache.VersionOf).
+func (c *GenericCache[K, V]) VersionOf(key K) (version uint64, found bool) {
+	return c.inner.VersionOf(keyToString(key))
+}
+
+// GetVersion returns the value of key together with its version (see
+// Cache.GetVersion).
+func (c *GenericCache[K, V]) GetVersion(key K) (value V, version uint64, found bool) {
+	val, version, found := c.inner.GetVersion(keyToString(key))
+	if !found {
+		return value, 0, false
+	}
+	typed, ok := val.(V)
+	if !ok {
+		return value, 0, false
+	}
+	return typed, version, true
+}
+
+// SetIfVersion stores value for key only if its entry still carries version
+// (see Cache.SetIfVersion).
+func (c *GenericCache[K, V]) SetIfVersion(key K, value V, version uint64) (newVersion uint64, ok bool) {
+	return c.inner.SetIfVersion(keyToString(key), value, version)
+}
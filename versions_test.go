@@ -0,0 +1,166 @@
+// versions_test.go: tests for entry versions and SetIfVersion
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestVersions_LostUpdate(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, EntryVersions: true}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	if _, ok := cache.SetIfVersion("cfg", "v1", 0); !ok {
+		t.Fatal("version 0 must insert an absent key")
+	}
+	if _, ok := cache.SetIfVersion("cfg", "again", 0); ok {
+		t.Fatal("version 0 must not overwrite a present key")
+	}
+
+	// Two writers read the same version; the second one must be rejected,
+	// even though it read an equal value
+	_, read, _ := cache.GetVersion("cfg")
+	first, ok := cache.SetIfVersion("cfg", "writer A", read)
+	if !ok || first <= read {
+		t.Fatalf("SetIfVersion = %d, %v, want a version above %d", first, ok, read)
+	}
+	if _, ok := cache.SetIfVersion("cfg", "writer B", read); ok {
+		t.Fatal("a stale version must be rejected")
+	}
+	if v, version, _ := cache.GetVersion("cfg"); v != "writer A" || version != first {
+		t.Fatalf("GetVersion = %v, %d, want writer A, %d", v, version, first)
+	}
+
+	// Every write stamps a new version, including plain Sets
+	cache.Set("cfg", "plain")
+	if version, found := cache.VersionOf("cfg"); !found || version <= first {
+		t.Fatalf("VersionOf = %d, %v, want a version above %d after Set", version, found, first)
+	}
+}
+
+func TestVersions_DeleteAndReinsert(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, EntryVersions: true}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	before, _ := cache.VersionOf("k")
+	cache.Delete("k")
+	if _, found := cache.VersionOf("k"); found {
+		t.Fatal("VersionOf must miss a deleted key")
+	}
+	if _, ok := cache.SetIfVersion("k", 2, before); ok {
+		t.Fatal("a version read before Delete must not match")
+	}
+	cache.Set("k", 3)
+	if after, _ := cache.VersionOf("k"); after <= before {
+		t.Fatalf("version after reinsert = %d, want above %d", after, before)
+	}
+}
+
+func TestVersions_Concurrent(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, EntryVersions: true}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("counter", 0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; {
+				v, version, _ := cache.GetVersion("counter")
+				if _, ok := cache.SetIfVersion("counter", v.(int)+1, version); ok {
+					i++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := cache.Get("counter"); v != 800 {
+		t.Fatalf("counter = %v, want 800 (lost updates)", v)
+	}
+}
+
+func TestVersions_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Set("k", "v")
+	if version, found := cache.VersionOf("k"); !found || version != 0 {
+		t.Fatalf("VersionOf = %d, %v, want 0 without EntryVersions", version, found)
+	}
+	if _, ok := cache.SetIfVersion("k", "w", 0); ok {
+		t.Fatal("SetIfVersion must fail without EntryVersions")
+	}
+	if _, ok := cache.SetIfVersion("absent", "w", 0); ok {
+		t.Fatal("SetIfVersion must fail without EntryVersions")
+	}
+}
+
+func TestVersions_Generic(t *testing.T) {
+	cache := NewGenericCache[string, int](Config{MaxSize: 100, EntryVersions: true})
+	defer cache.Close()
+
+	version, ok := cache.SetIfVersion("k", 1, 0)
+	if !ok {
+		t.Fatal("SetIfVersion must insert an absent key")
+	}
+	if v, got, found := cache.GetVersion("k"); !found || v != 1 || got != version {
+		t.Fatalf("GetVersion = %d, %d, %v, want 1, %d", v, got, found, version)
+	}
+	if _, ok := cache.SetIfVersion("k", 2, version+1); ok {
+		t.Fatal("a wrong version must be rejected")
+	}
+}
+
+// BenchmarkVersions_Set compares writes without entry tags, the default, with
+// versioned and source-tagged ones: untagged writes must keep allocating a
+// bare valueHolder.
+func BenchmarkVersions_Set(b *testing.B) {
+	b.Run("Off", func(b *testing.B) {
+		cache := NewCache(Config{MaxSize: 10000})
+		defer cache.Close()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cache.Set("key", i)
+		}
+	})
+	b.Run("EntryVersions", func(b *testing.B) {
+		cache := NewCache(Config{MaxSize: 10000, EntryVersions: true})
+		defer cache.Close()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cache.Set("key", i)
+		}
+	})
+	b.Run("SetWithSource", func(b *testing.B) {
+		cache := NewCache(Config{MaxSize: 10000}).(*wtinyLFUCache)
+		defer cache.Close()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cache.SetWithSource("key", i, "bench")
+		}
+	})
+}
+
+// BenchmarkVersions_Get measures hits on untagged and versioned entries.
+func BenchmarkVersions_Get(b *testing.B) {
+	for _, versioned := range []bool{false, true} {
+		b.Run("EntryVersions="+strconv.FormatBool(versioned), func(b *testing.B) {
+			cache := NewCache(Config{MaxSize: 10000, EntryVersions: versioned})
+			defer cache.Close()
+			cache.Set("key", 42)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Get("key")
+			}
+		})
+	}
+}
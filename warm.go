@@ -0,0 +1,106 @@
+// warm.go: Warm and WarmSeq, bulk preloading at startup
+//
+// Warm fills the free capacity without touching the sketch or admission,
+// and stops once the cache is full.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"iter"
+	"maps"
+)
+
+// Warm preloads entries into the free capacity of the cache, bypassing
+// admission and the frequency sketch. Keys already cached are skipped and
+// the load stops when the cache is full. Entries get Config.TTL. Returns the
+// number of entries stored.
+func (c *wtinyLFUCache) Warm(entries map[string]interface{}) int {
+	return c.WarmSeq(maps.All(entries))
+}
+
+// WarmSeq is Warm for a streaming source: it stops iterating entries once
+// the cache is full.
+func (c *wtinyLFUCache) WarmSeq(entries iter.Seq2[string, interface{}]) int {
+	warmed := 0
+	for key, value := range entries {
+		if c.isClosed() || c.entryCount() >= c.capacity() {
+			break
+		}
+		if c.warmEntry(key, value) {
+			warmed++
+		}
+	}
+	return warmed
+}
+
+// warmEntry stores one entry of Warm. Returns false if key is already
+// cached or the value cannot be stored.
+func (c *wtinyLFUCache) warmEntry(key string, value interface{}) bool {
+	stored, err := c.prepareSet("Warm", key, value)
+	if err != nil {
+		return false
+	}
+	var weight int32
+	if c.maxWeight > 0 {
+		weight, _ = c.weigh(key, stored)
+		if c.totalWeight()+int64(weight) > c.maxWeight {
+			return false // No room left for this value; a lighter one may fit
+		}
+	}
+
+	now := c.timeProvider.Now()
+	start := c.latencyStart(now)
+	ttlNow := c.ttlClock(now)
+	keyHash := c.hashKey(key)
+
+	t := c.writeTable(keyHash)
+	if t == nil {
+		return false // Closed
+	}
+	defer t.leave(keyHash)
+	entry, _, existing, oldState := c.claimKey(t, key, keyHash, ttlNow)
+	if entry == nil {
+		return false
+	}
+	if existing {
+		c.releaseClaim(entry, true)
+		return false
+	}
+
+	c.populateEntry(t, entry, key, keyHash, stored, "", c.entryExpireAt(ttlNow), 0, weight, oldState)
+	c.recordSetMetrics(key, start)
+	if t.size() > c.capacity() {
+		// Concurrent writers filled the cache meanwhile: evict without
+		// consulting the AdmissionPolicy
+		c.evictOne(t)
+	}
+	if c.namespaces != nil {
+		c.indexNamespace(key)
+	}
+	if c.memory != nil {
+		c.recordMemory(key, stored)
+	}
+	return true
+}
+
+// Warm preloads entries into the free capacity of the cache (see
+// Cache.Warm). Returns the number of entries stored.
+func (c *GenericCache[K, V]) Warm(entries map[K]V) int {
+	return c.WarmSeq(maps.All(entries))
+}
+
+// WarmSeq is Warm for a streaming source: it stops iterating entries once
+// the cache is full.
+func (c *GenericCache[K, V]) WarmSeq(entries iter.Seq2[K, V]) int {
+	return c.inner.WarmSeq(func(yield func(string, interface{}) bool) {
+		for key, value := range entries {
+			if !yield(keyToString(key), value) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// warm_test.go: tests for Warm and WarmSeq
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWarm_StopsAtCapacity(t *testing.T) {
+	// An admission policy refusing every new key does not apply to Warm
+	refuseAll := AdmissionFunc(func(candidate, victim EvictionCandidate) bool { return false })
+	cache := NewCache(Config{MaxSize: 50, AdmissionPolicy: refuseAll}).(*wtinyLFUCache)
+	defer cache.Close()
+	cache.Set("live", "new")
+
+	entries := map[string]interface{}{"live": "old", "": "empty"}
+	for i := 0; i < 100; i++ {
+		entries[fmt.Sprintf("key-%d", i)] = i
+	}
+	warmed := cache.Warm(entries)
+
+	if warmed != 49 || cache.Len() != 50 {
+		t.Fatalf("Warm stored %d, Len %d; want 49 and 50", warmed, cache.Len())
+	}
+	if v, _ := cache.Get("live"); v != "new" {
+		t.Errorf("Warm overwrote a cached key: %v", v)
+	}
+	if stats := cache.Stats(); stats.Evictions != 0 {
+		t.Errorf("Warm evicted %d entries", stats.Evictions)
+	}
+}
+
+func TestWarm_SketchUntouched(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	cache.Warm(map[string]interface{}{"k": 1})
+	if freq := cache.table.Load().sketch.estimate(cache.hashKey("k")); freq != 0 {
+		t.Errorf("warmed key has frequency %d", freq)
+	}
+}
+
+func TestWarmSeq_StopsPulling(t *testing.T) {
+	cache := NewGenericCache[int, string](Config{MaxSize: 10})
+	defer cache.Close()
+
+	pulled := 0
+	warmed := cache.WarmSeq(func(yield func(int, string) bool) {
+		for i := 0; i < 1000; i++ {
+			pulled++
+			if !yield(i, fmt.Sprint(i)) {
+				return
+			}
+		}
+	})
+	if warmed != 10 || pulled > 11 {
+		t.Fatalf("warmed %d, pulled %d entries", warmed, pulled)
+	}
+	if v, found := cache.Get(3); !found || v != "3" {
+		t.Errorf("Get(3) = %v, %v", v, found)
+	}
+}
+
+func TestWarm_MaxWeight(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 10, Weigher: byteWeigher}).(*wtinyLFUCache)
+	defer cache.Close()
+
+	warmed := cache.Warm(map[string]interface{}{
+		"a": []byte("12345678"),
+		"b": []byte("12345678"),
+		"c": []byte("1"),
+	})
+	if stats := cache.Stats(); stats.Weight > 10 || stats.Evictions != 0 {
+		t.Fatalf("weight %d, evictions %d after warming %d entries", stats.Weight, stats.Evictions, warmed)
+	}
+	if warmed != 2 || !cache.Has("c") {
+		t.Errorf("expected one heavy value and c to be warmed, got %d", warmed)
+	}
+}
@@ -0,0 +1,138 @@
+// weight.go: weight-based capacity
+//
+// With Config.MaxWeight, entries are charged Config.Weigher and evicted
+// while the total exceeds MaxWeight.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// WeigherFor adapts a typed weigher for use as Config.Weigher with a
+// GenericCache[K, V]. Keys are passed in their string form. Values of a type
+// other than V weigh 1.
+//
+// Example:
+//
+//	cfg := balios.Config{
+//	    MaxSize:   100_000,
+//	    MaxWeight: 512 << 20, // 512 MiB
+//	    Weigher:   balios.WeigherFor(func(key string, page []byte) int64 { return int64(len(page)) }),
+//	}
+//	pages := balios.NewGenericCache[string, []byte](cfg)
+func WeigherFor[V any](weigher func(key string, value V) int64) func(key string, value interface{}) int64 {
+	return func(key string, value interface{}) int64 {
+		if v, ok := value.(V); ok {
+			return weigher(key, v)
+		}
+		return 1
+	}
+}
+
+// weigh returns the clamped weight of a write and whether it fits MaxWeight.
+func (c *wtinyLFUCache) weigh(key string, value interface{}) (int32, bool) {
+	w := int64(1)
+	switch v := value.(type) {
+	case encodedValue:
+		// Encoded values are weighed in their stored form (see value_codec.go)
+		w = int64(len(v))
+		if c.weigher != nil {
+			w = c.weigher(key, []byte(v))
+		}
+	case arenaValue:
+		w = int64(v.n)
+		if c.weigher != nil {
+			w = c.weigher(key, v.bytes())
+		}
+	default:
+		if c.weigher != nil {
+			w = c.weigher(key, value)
+		}
+	}
+	switch {
+	case w < 0:
+		w = 0
+	case w > math.MaxInt32:
+		w = math.MaxInt32
+	}
+	return int32(w), w <= c.maxWeight // #nosec G115 -- clamped to int32 range above
+}
+
+// chargeWeight sets the weight of an entry owned by the caller (entryPending)
+// and updates the total of g, the generation the entry counts in (nil if it
+// has been cleared).
+func (c *wtinyLFUCache) chargeWeight(g *generation, entry *entry, weight int32) {
+	old := atomic.SwapInt32(&entry.weight, weight)
+	if delta := int64(weight) - int64(old); delta != 0 && g != nil {
+		atomic.AddInt64(&g.weight, delta)
+	}
+}
+
+// removeValid moves a valid entry of t to entryDeleted, releasing its size and
+// weight and reporting it to Config.OnEvict (see on_evict.go) with reason.
+// Returns false if the entry was not valid (another goroutine owns it, or
+// Clear dropped it).
+func (c *wtinyLFUCache) removeValid(t *cacheTable, entry *entry, reason EvictReason) bool {
+	g := t.gen.Load()
+	notify := c.onEvict != nil || (reason == ReasonExpired && c.onExpire != nil) || c.events.wants(eventOf(reason)) ||
+		(reason == ReasonDeleted && c.tombstoneTTLNanos > 0)
+	if c.maxWeight == 0 && !notify && !c.trackMissReasons {
+		if !atomic.CompareAndSwapInt32(&entry.valid, g.live, entryDeleted) {
+			return false
+		}
+		atomic.AddInt64(&g.size, -1)
+		return true
+	}
+	if !atomic.CompareAndSwapInt32(&entry.valid, g.live, pendingOf(g.live)) {
+		return false
+	}
+	c.removeAcquired(t, entry, reason)
+	return true
+}
+
+// removeAcquired completes the removal of an entry of t owned by the caller
+// (entryPending) and moves it to entryDeleted (see removeValid).
+func (c *wtinyLFUCache) removeAcquired(t *cacheTable, entry *entry, reason EvictReason) {
+	g := t.owner(entry)
+	if c.maxWeight > 0 {
+		c.chargeWeight(g, entry, 0)
+	}
+	// Writers of the key wait for a pending entry of its hash (see
+	// awaitRelease): unlink the hash first, so a listener writing the key
+	// does not wait for the removal that is calling it
+	keyHash := atomic.SwapUint64(&entry.keyHash, 0)
+	if c.onEvict != nil || (reason == ReasonExpired && c.onExpire != nil) {
+		removed := takeEvicted(entry)
+		c.notifyEvict(removed.key, removed.value, reason)
+	}
+	if reason == ReasonDeleted && c.tombstoneTTLNanos > 0 {
+		c.storeTombstone(entry)
+	}
+	if c.trackMissReasons {
+		c.recordDeparture(keyHash, reason)
+	}
+	if typ := eventOf(reason); c.events.wants(typ) {
+		c.publishEvent(typ, entry.loadKey(), keyHash)
+	}
+	atomic.StoreInt32(&entry.valid, entryDeleted)
+	if g != nil {
+		atomic.AddInt64(&g.size, -1)
+	}
+}
+
+// enforceMaxWeight evicts entries of t until the total weight fits MaxWeight.
+// Bounded by the number of live entries, so it terminates even if concurrent
+// writers keep adding weight.
+func (c *wtinyLFUCache) enforceMaxWeight(t *cacheTable) {
+	for budget := t.size(); budget >= 0 && atomic.LoadInt64(&t.gen.Load().weight) > c.maxWeight; budget-- {
+		if !c.evictOne(t) {
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// weight_test.go: tests for weight-based capacity
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func byteWeigher(key string, value interface{}) int64 {
+	if b, ok := value.([]byte); ok {
+		return int64(len(b))
+	}
+	return 1
+}
+
+// liveWeight sums the weights of the live entries.
+func liveWeight(c *wtinyLFUCache) int64 {
+	var total int64
+	for i := range c.table.Load().entries {
+		if e := &c.table.Load().entries[i]; c.isLive(e) {
+			total += int64(atomic.LoadInt32(&e.weight))
+		}
+	}
+	return total
+}
+
+func TestWeight_Accounting(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 1000, Weigher: byteWeigher}).(*wtinyLFUCache)
+
+	cache.Set("a", make([]byte, 100))
+	cache.Set("b", make([]byte, 50))
+	if w := cache.Stats().Weight; w != 150 {
+		t.Fatalf("expected weight 150 after inserts, got %d", w)
+	}
+
+	cache.Set("a", make([]byte, 10))
+	if w := cache.Stats().Weight; w != 60 {
+		t.Fatalf("expected weight 60 after update, got %d", w)
+	}
+
+	cache.Swap("b", make([]byte, 20))
+	cache.CompareAndSwap("missing", nil, make([]byte, 5))
+	if w := cache.Stats().Weight; w != 30 {
+		t.Fatalf("expected weight 30 after Swap, got %d", w)
+	}
+
+	cache.Delete("a")
+	if w := cache.Stats().Weight; w != 20 {
+		t.Fatalf("expected weight 20 after Delete, got %d", w)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats.Weight != 0 || stats.MaxWeight != 1000 {
+		t.Fatalf("expected weight 0/1000 after Clear, got %d/%d", stats.Weight, stats.MaxWeight)
+	}
+}
+
+func TestWeight_EvictsByWeight(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 1000, MaxWeight: 1000, Weigher: byteWeigher})
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), make([]byte, 100))
+	}
+
+	stats := cache.Stats()
+	if stats.Weight > 1000 {
+		t.Errorf("weight %d exceeds MaxWeight", stats.Weight)
+	}
+	if stats.Size > 10 {
+		t.Errorf("at most 10 entries of weight 100 fit, got %d", stats.Size)
+	}
+	if stats.Evictions == 0 {
+		t.Error("expected weight-driven evictions")
+	}
+	if live := liveWeight(cache.(*wtinyLFUCache)); live != stats.Weight {
+		t.Errorf("live weight %d does not match Stats().Weight %d", live, stats.Weight)
+	}
+}
+
+func TestWeight_RejectsOversizedValue(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 100, Weigher: byteWeigher}).(*wtinyLFUCache)
+	cache.Set("small", make([]byte, 10))
+
+	if cache.Set("huge", make([]byte, 101)) {
+		t.Error("a value heavier than MaxWeight must be rejected")
+	}
+	if cache.CompareAndSwap("small", nil, make([]byte, 101)) {
+		t.Error("CompareAndSwap must reject a value heavier than MaxWeight")
+	}
+	if prev, loaded := cache.Swap("small", make([]byte, 101)); !loaded || len(prev.([]byte)) != 10 {
+		t.Error("Swap with an oversized value must leave the current value in place")
+	}
+	if v, ok := cache.Get("small"); !ok || len(v.([]byte)) != 10 {
+		t.Error("existing entries must survive an oversized write")
+	}
+	if w := cache.Stats().Weight; w != 10 {
+		t.Errorf("expected weight 10, got %d", w)
+	}
+}
+
+func TestWeight_DefaultWeigher(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, MaxWeight: 5})
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	if stats := cache.Stats(); stats.Weight > 5 || stats.Size > 5 {
+		t.Errorf("without a Weigher every entry weighs 1: weight=%d size=%d", stats.Weight, stats.Size)
+	}
+}
+
+func TestWeight_Disabled(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 100, Weigher: byteWeigher})
+	cache.Set("a", make([]byte, 1000))
+	if stats := cache.Stats(); stats.Weight != 0 || stats.MaxWeight != 0 {
+		t.Errorf("weights must not be tracked without MaxWeight: %+v", stats)
+	}
+}
+
+func TestWeigherFor_Generic(t *testing.T) {
+	cache := NewGenericCache[string, string](Config{
+		MaxSize:   100,
+		MaxWeight: 100,
+		Weigher:   WeigherFor(func(key string, value string) int64 { return int64(len(value)) }),
+	})
+	cache.Set("a", "0123456789")
+	cache.Set("b", "01234")
+	if w := cache.Stats().Weight; w != 15 {
+		t.Errorf("expected weight 15, got %d", w)
+	}
+}
+
+func TestWeight_ConcurrentConsistency(t *testing.T) {
+	cache := NewCache(Config{MaxSize: 256, MaxWeight: 4096, Weigher: byteWeigher}).(*wtinyLFUCache)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := fmt.Sprintf("k%d", (g*31+i)%300)
+				switch i % 4 {
+				case 0, 1:
+					cache.Set(key, make([]byte, i%64))
+				case 2:
+					cache.Swap(key, make([]byte, i%32))
+				case 3:
+					cache.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	if live := liveWeight(cache); live != stats.Weight {
+		t.Errorf("live weight %d does not match Stats().Weight %d", live, stats.Weight)
+	}
+	if stats.Weight > stats.MaxWeight {
+		t.Errorf("weight %d exceeds MaxWeight %d after writers settled", stats.Weight, stats.MaxWeight)
+	}
+}
@@ -0,0 +1,340 @@
+// write_behind.go: asynchronous persistence of writes (Config.WriteBehindStore)
+//
+// With Config.WriteBehindStore, writes are queued and flushed to the Store
+// in batches by background workers.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Write-behind defaults.
+const (
+	// DefaultWriteBehindQueueSize is the default bound of the write-behind
+	// queue, in distinct keys.
+	DefaultWriteBehindQueueSize = 10_000
+
+	// DefaultWriteBehindBatchSize is the default number of keys per Store call.
+	DefaultWriteBehindBatchSize = 100
+
+	// DefaultWriteBehindInterval is the default longest delay of a queued key.
+	DefaultWriteBehindInterval = 100 * time.Millisecond
+
+	// DefaultWriteBehindRetries is the default number of retries of a failed batch.
+	DefaultWriteBehindRetries = 3
+
+	// writeBehindBackoff is the delay before the first retry; it doubles at
+	// every further retry.
+	writeBehindBackoff = 10 * time.Millisecond
+)
+
+// Store is the backing store of a write-behind cache (see
+// Config.WriteBehindStore). Implementations must be safe for concurrent use
+// when Config.WriteBehindWorkers > 1.
+type Store interface {
+	// WriteBatch persists writes: each one stores Value for Key, or
+	// removes Key if Deleted. A batch holds each key at most once. An error
+	// fails the whole batch, which is retried as a whole, so writes must be
+	// idempotent.
+	WriteBatch(ctx context.Context, writes []StoreWrite) error
+}
+
+// StoreWrite is a write flushed to a Store.
+type StoreWrite struct {
+	Key     string
+	Value   interface{}
+	Deleted bool
+}
+
+// WriteBehindPolicy selects what a write does when the write-behind queue
+// is full.
+type WriteBehindPolicy int
+
+const (
+	// WriteBehindDrop drops the write (it is cached but never flushed) and
+	// counts it in CacheStats.WriteBehindDropped.
+	WriteBehindDrop WriteBehindPolicy = iota
+
+	// WriteBehindBlock blocks the writer until a flush makes room.
+	WriteBehindBlock
+)
+
+// WriteBehindRecorder is an optional MetricsCollector extension. Collectors
+// implementing it are notified of every write-behind flush.
+type WriteBehindRecorder interface {
+	// RecordWriteBehindFlush records a batch of writes flushed to the Store
+	// (failed after every retry if err is not nil), and the number of keys
+	// still queued.
+	RecordWriteBehindFlush(writes int, queueDepth int, err error)
+}
+
+// writeBehind is the queue and the workers of a write-behind cache.
+type writeBehind struct {
+	store     Store
+	queueSize int
+	batchSize int
+	interval  time.Duration
+	retries   int
+	policy    WriteBehindPolicy
+
+	mu       sync.Mutex
+	ready    *sync.Cond // Signals workers: a key was queued or the queue closed
+	room     *sync.Cond // Signals blocked writers: keys were taken or the queue closed
+	pending  map[string]StoreWrite
+	order    []string            // Queued keys, oldest first
+	inflight map[string]struct{} // Keys of the batches being flushed
+	oldest   time.Time
+	closed   bool
+
+	flushed atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+}
+
+// newWriteBehind returns the write-behind queue of config, or nil without a
+// WriteBehindStore.
+func newWriteBehind(config Config) *writeBehind {
+	if config.WriteBehindStore == nil {
+		return nil
+	}
+	w := &writeBehind{
+		store:     config.WriteBehindStore,
+		queueSize: config.WriteBehindQueueSize,
+		batchSize: config.WriteBehindBatchSize,
+		interval:  config.WriteBehindInterval,
+		retries:   max(config.WriteBehindRetries, 0),
+		policy:    config.WriteBehindPolicy,
+		pending:   make(map[string]StoreWrite),
+		inflight:  make(map[string]struct{}),
+	}
+	w.ready = sync.NewCond(&w.mu)
+	w.room = sync.NewCond(&w.mu)
+	return w
+}
+
+// enqueue queues a write of key, replacing a queued write of the same key.
+// Returns false if the write was dropped.
+func (w *writeBehind) enqueue(write StoreWrite) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, queued := w.pending[write.Key]; queued {
+		w.pending[write.Key] = write
+		return true
+	}
+	for len(w.pending) >= w.queueSize && !w.closed {
+		if w.policy != WriteBehindBlock {
+			w.dropped.Add(1)
+			return false
+		}
+		w.room.Wait()
+	}
+	if w.closed {
+		w.dropped.Add(1)
+		return false
+	}
+	if len(w.order) == 0 {
+		w.oldest = time.Now()
+	}
+	w.pending[write.Key] = write
+	w.order = append(w.order, write.Key)
+	w.ready.Signal()
+	return true
+}
+
+// depth returns the number of queued keys.
+func (w *writeBehind) depth() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// resetCounters zeroes the flushed, failed and dropped counters.
+func (w *writeBehind) resetCounters() {
+	w.flushed.Store(0)
+	w.failed.Store(0)
+	w.dropped.Store(0)
+}
+
+// close stops accepting writes and wakes the workers, which flush what is
+// left and exit.
+func (w *writeBehind) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	w.ready.Broadcast()
+	w.room.Broadcast()
+}
+
+// nextBatch waits for a batch to flush: a full one, one whose oldest key has
+// waited for the interval, or whatever is left once closed. Keys flushed by
+// another worker stay queued until done is called for their batch. Returns
+// nil when the queue is closed and empty.
+func (w *writeBehind) nextBatch() []StoreWrite {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for {
+		if len(w.order) > 0 && (w.closed || len(w.order) >= w.batchSize || time.Since(w.oldest) >= w.interval) {
+			if w.hasFlushable() {
+				break
+			}
+			w.ready.Wait() // Every queued key is being flushed: wait for done
+			continue
+		}
+		if w.closed {
+			return nil
+		}
+		if len(w.order) == 0 {
+			w.ready.Wait()
+			continue
+		}
+		// Partial batch: sleep until its deadline, unless more keys arrive
+		wait := w.interval - time.Since(w.oldest)
+		timer := time.AfterFunc(wait, w.ready.Broadcast)
+		w.ready.Wait()
+		timer.Stop()
+	}
+
+	batch := make([]StoreWrite, 0, min(len(w.order), w.batchSize))
+	remaining := 0
+	for _, key := range w.order {
+		if _, flushing := w.inflight[key]; flushing || len(batch) == w.batchSize {
+			w.order[remaining] = key
+			remaining++
+			continue
+		}
+		batch = append(batch, w.pending[key])
+		delete(w.pending, key)
+		w.inflight[key] = struct{}{}
+	}
+	clear(w.order[remaining:])
+	w.order = w.order[:remaining]
+	if remaining > 0 {
+		w.oldest = time.Now() // Approximate: the remaining keys have waited at most this long
+	}
+	w.room.Broadcast()
+	return batch
+}
+
+// hasFlushable reports whether a queued key is not being flushed.
+func (w *writeBehind) hasFlushable() bool {
+	if len(w.inflight) == 0 {
+		return true
+	}
+	for _, key := range w.order {
+		if _, flushing := w.inflight[key]; !flushing {
+			return true
+		}
+	}
+	return false
+}
+
+// done ends the flush of batch, releasing its keys to the other workers.
+func (w *writeBehind) done(batch []StoreWrite) {
+	w.mu.Lock()
+	for _, write := range batch {
+		delete(w.inflight, write.Key)
+	}
+	held := len(w.order) > 0
+	w.mu.Unlock()
+	if held {
+		w.ready.Broadcast()
+	}
+}
+
+// flush writes batch to the Store, retrying failures with backoff.
+func (w *writeBehind) flush(batch []StoreWrite) error {
+	var err error
+	backoff := writeBehindBackoff
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = w.writeBatch(batch); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// writeBatch makes one Store call, turning a panic into an error.
+func (w *writeBehind) writeBatch(batch []StoreWrite) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewErrPanicRecovered("WriteBatch", r)
+		}
+	}()
+	return w.store.WriteBatch(context.Background(), batch)
+}
+
+// runWriteBehind flushes batches until the queue is closed and drained.
+func (c *wtinyLFUCache) runWriteBehind() {
+	w := c.writeBehind
+	for {
+		batch := w.nextBatch()
+		if batch == nil {
+			return
+		}
+		err := w.flush(batch)
+		w.done(batch)
+		if err != nil {
+			w.failed.Add(int64(len(batch)))
+			c.logger.Error("balios: write-behind flush failed", "writes", len(batch), "error", err)
+		} else {
+			w.flushed.Add(int64(len(batch)))
+		}
+		if c.writeBehindRecorder != nil {
+			c.writeBehindRecorder.RecordWriteBehindFlush(len(batch), w.depth(), err)
+		}
+	}
+}
+
+// queueWrite queues the write of a stored value for the Store.
+func (c *wtinyLFUCache) queueWrite(key string, value interface{}) {
+	c.writeBehind.enqueue(StoreWrite{Key: key, Value: value})
+}
+
+// queueDelete queues the deletion of key from the Store.
+func (c *wtinyLFUCache) queueDelete(key string) {
+	c.writeBehind.enqueue(StoreWrite{Key: key, Deleted: true})
+}
+
+// readWriteBehind fills the write-behind fields of stats.
+func (c *wtinyLFUCache) readWriteBehind(stats *CacheStats) {
+	if c.writeBehind == nil {
+		return
+	}
+	w := c.writeBehind
+	stats.WriteBehindQueued = uint64(w.depth())         // #nosec G115 - lengths are never negative
+	stats.WriteBehindFlushed = uint64(w.flushed.Load()) // #nosec G115 - stats counters are always positive
+	stats.WriteBehindFailed = uint64(w.failed.Load())   // #nosec G115 - stats counters are always positive
+	stats.WriteBehindDropped = uint64(w.dropped.Load()) // #nosec G115 - stats counters are always positive
+}
+
+// writeBehindRecorderOf returns the WriteBehindRecorder of a collector built
+// by newGuardedMetricsCollector, or nil when the collector does not implement it.
+func writeBehindRecorderOf(collector MetricsCollector) WriteBehindRecorder {
+	if g, ok := collector.(*guardedMetricsCollector); ok && g.writeBehind != nil {
+		return g
+	}
+	return nil
+}
+
+// RecordWriteBehindFlush forwards to the wrapped collector unless it has been disabled.
+func (g *guardedMetricsCollector) RecordWriteBehindFlush(writes int, queueDepth int, err error) {
+	if g.writeBehind == nil || g.isDisabled() {
+		return
+	}
+	defer g.recoverPanic("RecordWriteBehindFlush")
+	g.writeBehind.RecordWriteBehindFlush(writes, queueDepth, err)
+}
@@ -0,0 +1,304 @@
+// write_behind_test.go: tests for Config.WriteBehindStore
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira fragment
+// SPDX-License-Identifier: MPL-2.0
+
+package balios
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingStore is a Store recording its batches, failing the first
+// failures calls and blocking while gate is not nil and open.
+type recordingStore struct {
+	mu       sync.Mutex
+	batches  [][]StoreWrite
+	data     map[string]interface{}
+	failures int
+	gate     chan struct{}
+}
+
+func newRecordingStore() *recordingStore {
+	return &recordingStore{data: make(map[string]interface{})}
+}
+
+func (s *recordingStore) WriteBatch(_ context.Context, writes []StoreWrite) error {
+	if s.gate != nil {
+		<-s.gate
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("store unavailable")
+	}
+	s.batches = append(s.batches, append([]StoreWrite(nil), writes...))
+	for _, w := range writes {
+		if w.Deleted {
+			delete(s.data, w.Key)
+		} else {
+			s.data[w.Key] = w.Value
+		}
+	}
+	return nil
+}
+
+func (s *recordingStore) snapshot() (map[string]interface{}, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make(map[string]interface{}, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+	return data, len(s.batches)
+}
+
+// writeBehindRecorder records write-behind flushes.
+type writeBehindRecorder struct {
+	NoOpMetricsCollector
+	mu      sync.Mutex
+	flushes int
+	writes  int
+	failed  int
+}
+
+func (r *writeBehindRecorder) RecordWriteBehindFlush(writes int, _ int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushes++
+	r.writes += writes
+	if err != nil {
+		r.failed += writes
+	}
+}
+
+func TestWriteBehind_FlushesAndCoalesces(t *testing.T) {
+	store := newRecordingStore()
+	recorder := &writeBehindRecorder{}
+	cache := NewCache(Config{
+		MaxSize:              100,
+		WriteBehindStore:     store,
+		WriteBehindBatchSize: 10,
+		WriteBehindInterval:  time.Hour, // Only full batches and Close flush
+		MetricsCollector:     recorder,
+	})
+
+	for i := 0; i < 100; i++ {
+		cache.Set("counter", i) // Coalesced into one queued write
+	}
+	cache.Set("gone", 1)
+	cache.Delete("gone")
+	if _, batches := store.snapshot(); batches != 0 {
+		t.Fatal("a partial batch must wait for WriteBehindInterval")
+	}
+	if queued := cache.Stats().WriteBehindQueued; queued != 2 {
+		t.Fatalf("WriteBehindQueued = %d, want 2", queued)
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, batches := store.snapshot()
+	if batches != 1 || len(data) != 1 || data["counter"] != 99 {
+		t.Fatalf("Close must flush the latest values in one batch, got %v in %d batches", data, batches)
+	}
+	if recorder.flushes != 1 || recorder.writes != 2 {
+		t.Fatalf("recorder saw %d flushes of %d writes, want 1 of 2", recorder.flushes, recorder.writes)
+	}
+}
+
+func TestWriteBehind_BatchesAndInterval(t *testing.T) {
+	store := newRecordingStore()
+	cache := NewCache(Config{
+		MaxSize:              1000,
+		WriteBehindStore:     store,
+		WriteBehindBatchSize: 10,
+		WriteBehindInterval:  10 * time.Millisecond,
+	})
+	defer cache.Close()
+
+	for i := 0; i < 25; i++ {
+		cache.Set("k"+strconv.Itoa(i), i)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.Stats().WriteBehindFlushed < 25 {
+		if time.Now().After(deadline) {
+			t.Fatalf("writes not flushed: %+v", cache.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, batch := range store.batches {
+		if len(batch) > 10 {
+			t.Fatalf("batch of %d writes exceeds WriteBehindBatchSize", len(batch))
+		}
+	}
+}
+
+func TestWriteBehind_Retry(t *testing.T) {
+	store := newRecordingStore()
+	store.failures = 2
+	cache := NewCache(Config{MaxSize: 100, WriteBehindStore: store, WriteBehindRetries: 2})
+	cache.Set("k", "v")
+	_ = cache.Close()
+	if data, _ := store.snapshot(); data["k"] != "v" {
+		t.Fatal("a batch must be retried after failures")
+	}
+
+	store = newRecordingStore()
+	store.failures = 10
+	recorder := &writeBehindRecorder{}
+	cache = NewCache(Config{MaxSize: 100, WriteBehindStore: store, WriteBehindRetries: -1, MetricsCollector: recorder})
+	cache.Set("k", "v")
+	_ = cache.Close()
+	if recorder.failed != 1 {
+		t.Fatalf("expected one failed write without retries, got %d", recorder.failed)
+	}
+	if store.failures != 9 {
+		t.Fatalf("WriteBatch called %d times, want 1", 10-store.failures)
+	}
+}
+
+func TestWriteBehind_Policies(t *testing.T) {
+	store := newRecordingStore()
+	store.gate = make(chan struct{})
+	cache := NewCache(Config{
+		MaxSize:              100,
+		WriteBehindStore:     store,
+		WriteBehindQueueSize: 2,
+		WriteBehindBatchSize: 1,
+	})
+	cache.Set("a", 1) // Taken by the worker, blocked in the Store
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.Stats().WriteBehindQueued != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker did not take the first write")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.Set("d", 4) // Queue full: dropped
+	cache.Set("b", 5) // Already queued: coalesced, not dropped
+	if stats := cache.Stats(); stats.WriteBehindDropped != 1 || !cache.Has("d") {
+		t.Fatalf("expected d cached but dropped from the queue, got %+v", stats)
+	}
+	close(store.gate)
+	_ = cache.Close()
+	if data, _ := store.snapshot(); len(data) != 3 || data["b"] != 5 {
+		t.Fatalf("unexpected store contents %v", data)
+	}
+
+	store = newRecordingStore()
+	store.gate = make(chan struct{})
+	cache = NewCache(Config{
+		MaxSize:              100,
+		WriteBehindStore:     store,
+		WriteBehindQueueSize: 1,
+		WriteBehindBatchSize: 1,
+		WriteBehindPolicy:    WriteBehindBlock,
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			cache.Set("k"+strconv.Itoa(i), i)
+		}
+	}()
+	select {
+	case <-done:
+		t.Fatal("writes must block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(store.gate)
+	<-done
+	dropped := cache.Stats().WriteBehindDropped
+	_ = cache.Close()
+	if data, _ := store.snapshot(); len(data) != 5 || dropped != 0 {
+		t.Fatalf("WriteBehindBlock must not lose writes, got %v", data)
+	}
+}
+
+// overlapStore is a Store whose first call blocks until release is closed,
+// counting the keys written by two calls at once.
+type overlapStore struct {
+	*recordingStore
+	release chan struct{}
+	calls   atomic.Int32
+
+	mu       sync.Mutex
+	writing  map[string]bool
+	overlaps int
+}
+
+func (s *overlapStore) WriteBatch(ctx context.Context, writes []StoreWrite) error {
+	s.mark(writes, true)
+	defer s.mark(writes, false)
+	if s.calls.Add(1) == 1 {
+		<-s.release
+	}
+	return s.recordingStore.WriteBatch(ctx, writes)
+}
+
+func (s *overlapStore) mark(writes []StoreWrite, writing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range writes {
+		if writing && s.writing[w.Key] {
+			s.overlaps++
+		}
+		s.writing[w.Key] = writing
+	}
+}
+
+func TestWriteBehind_WorkersKeepKeyOrder(t *testing.T) {
+	store := &overlapStore{
+		recordingStore: newRecordingStore(),
+		release:        make(chan struct{}),
+		writing:        make(map[string]bool),
+	}
+	cache := NewCache(Config{
+		MaxSize:              100,
+		WriteBehindStore:     store,
+		WriteBehindBatchSize: 1,
+		WriteBehindInterval:  time.Millisecond,
+		WriteBehindWorkers:   2,
+	})
+	cache.Set("k", 1) // Taken by a worker, blocked in the Store
+	deadline := time.Now().Add(5 * time.Second)
+	for store.calls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no worker took the first write")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cache.Set("k", 2) // Must wait for the flush of 1
+	cache.Set("x", 3) // Flushed by the other worker meanwhile
+	for {
+		if data, _ := store.snapshot(); data["x"] == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the other worker did not flush x")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(store.release)
+	_ = cache.Close()
+
+	if data, _ := store.snapshot(); data["k"] != 2 {
+		t.Fatalf("store holds k = %v, want the last write 2", data["k"])
+	}
+	if store.overlaps != 0 {
+		t.Fatalf("%d keys were flushed by two batches at once", store.overlaps)
+	}
+}